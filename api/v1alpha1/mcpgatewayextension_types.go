@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -11,6 +12,14 @@ import (
 // +kubebuilder:validation:Enum=Enabled;Disabled
 type HTTPRouteManagementPolicy string
 
+// DeploymentStrategyType defines how the broker-router deployment is rolled out
+// +kubebuilder:validation:Enum=Recreate;RollingUpdate
+type DeploymentStrategyType string
+
+// SessionStoreType defines the backend used to share broker sessions
+// +kubebuilder:validation:Enum=Redis
+type SessionStoreType string
+
 // KeyGenerationPolicy defines whether the operator generates an ECDSA P-256 key pair
 // +kubebuilder:validation:Enum=Enabled;Disabled
 type KeyGenerationPolicy string
@@ -18,24 +27,42 @@ type KeyGenerationPolicy string
 const (
 	// ConditionTypeReady signals if a resource is ready
 	ConditionTypeReady = "Ready"
+	// ConditionTypeAccepted signals if the controller accepted a resource and wrote its config
+	ConditionTypeAccepted = "Accepted"
 	// ConditionReasonSuccess is the success reason users see
 	ConditionReasonSuccess = "ValidMCPGatewayExtension"
 	// ConditionReasonInvalid is the reason seen when invalid configuration occurs
 	ConditionReasonInvalid = "InvalidMCPGatewayExtension"
 	// ConditionReasonRefGrantRequired is the reason users will see when a ReferenceGrant is missing
 	ConditionReasonRefGrantRequired = "ReferenceGrantRequired"
+	// ConditionReasonNoMatchingListener is the reason when the target Gateway has no listener MCP traffic can be served on
+	ConditionReasonNoMatchingListener = "NoMatchingListener"
 	// ConditionReasonDeploymentNotReady is the reason when the broker-router deployment is not ready
 	ConditionReasonDeploymentNotReady = "DeploymentNotReady"
+	// ConditionReasonImagePullFailed is the reason when a broker-router pod cannot pull its image
+	ConditionReasonImagePullFailed = "ImagePullFailed"
+	// ConditionReasonEnvoyFilterNotAccepted is the reason when istio has not accepted the generated EnvoyFilter
+	ConditionReasonEnvoyFilterNotAccepted = "EnvoyFilterNotAccepted"
 
 	// ConditionReasonSecretNotFound is the reason when the trusted headers secret is missing
 	ConditionReasonSecretNotFound = "SecretNotFound"
 	// ConditionReasonSecretInvalid is the reason when the secret lacks the required key
 	ConditionReasonSecretInvalid = "SecretInvalid"
+	// ConditionReasonConfigSecretConflict is the reason when the broker config secret is controlled by another object
+	ConditionReasonConfigSecretConflict = "ConfigSecretConflict"
 	// HTTPRouteManagementEnabled means the operator creates and manages the HTTPRoute
 	HTTPRouteManagementEnabled HTTPRouteManagementPolicy = "Enabled"
 	// HTTPRouteManagementDisabled means the operator does not create an HTTPRoute
 	HTTPRouteManagementDisabled HTTPRouteManagementPolicy = "Disabled"
 
+	// DeploymentStrategyRecreate stops the existing broker-router pod before starting a new one
+	DeploymentStrategyRecreate DeploymentStrategyType = "Recreate"
+	// DeploymentStrategyRollingUpdate starts a new broker-router pod before stopping the existing one
+	DeploymentStrategyRollingUpdate DeploymentStrategyType = "RollingUpdate"
+
+	// SessionStoreRedis stores broker sessions in Redis
+	SessionStoreRedis SessionStoreType = "Redis"
+
 	// KeyGenerationEnabled means the operator generates an ECDSA P-256 key pair
 	KeyGenerationEnabled KeyGenerationPolicy = "Enabled"
 	// KeyGenerationDisabled means the operator does not generate keys
@@ -58,6 +85,16 @@ type MCPGatewayExtensionSpec struct {
 	// +optional
 	PrivateHost string `json:"privateHost,omitempty"`
 
+	// BrokerPort is the port the broker serves MCP clients on. The broker-router Deployment, Service and
+	// managed HTTPRoute all use this port. It must not clash with the broker's gRPC (50051), config (8181) or metrics
+	// (8082) ports.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:validation:XValidation:rule="self != 50051 && self != 8181 && self != 8082",message="brokerPort must not clash with the broker gRPC, config or metrics ports"
+	// +kubebuilder:default=8080
+	BrokerPort *int32 `json:"brokerPort,omitempty"`
+
 	// BackendPingIntervalSeconds specifies how often the broker pings upstream MCP servers.
 	// +optional
 	// +kubebuilder:validation:Minimum=10
@@ -65,17 +102,112 @@ type MCPGatewayExtensionSpec struct {
 	// +kubebuilder:default=60
 	BackendPingIntervalSeconds *int32 `json:"backendPingIntervalSeconds,omitempty"`
 
+	// ToolCallConcurrencyPerServer limits the number of concurrent tool calls routed to each upstream MCP server.
+	// Calls over the limit wait and are granted round robin across sessions so one session cannot monopolize a server.
+	// When unset tool calls are not limited.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	ToolCallConcurrencyPerServer *int32 `json:"toolCallConcurrencyPerServer,omitempty"`
+
+	// ToolCallRetries is how many times envoy retries a tool call after a connection failure or reset.
+	// Only tools marked idempotent in their tool override, or annotated readOnlyHint or idempotentHint, are retried.
+	// When unset tool calls are not retried.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	ToolCallRetries *int32 `json:"toolCallRetries,omitempty"`
+
+	// ExtProcMessageTimeout is how long envoy waits for the router to process each request or response, for example "30s".
+	// Raise it when a single request carries a large body or the router is slow to respond under load.
+	// If not specified, envoy waits 10s.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="extProcMessageTimeout must be at least 1s"
+	ExtProcMessageTimeout *metav1.Duration `json:"extProcMessageTimeout,omitempty"`
+
+	// ExtProcGRPC configures the gRPC connection envoy opens to the router for external processing.
+	// +optional
+	ExtProcGRPC *ExtProcGRPCConfig `json:"extProcGRPC,omitempty"`
+
+	// Instructions are returned to every client in the MCP initialize result to guide how the gateway's tools are used.
+	// +optional
+	// +kubebuilder:validation:MaxLength=8192
+	Instructions string `json:"instructions,omitempty"`
+
+	// MaxTotalTools caps the total number of tools the gateway serves across all upstream MCP servers.
+	// Servers are admitted first come first served. A server whose tools would take the catalog over the cap
+	// is held back with a CatalogFull reason; tools of servers already registered are never evicted.
+	// When unset the catalog is not limited.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxTotalTools *int32 `json:"maxTotalTools,omitempty"`
+
 	// TrustedHeadersKey configures trusted-header key pair for JWT-based tool filtering.
 	// When set, the public key secret is wired into the broker deployment.
 	// +optional
 	TrustedHeadersKey *TrustedHeadersKey `json:"trustedHeadersKey,omitempty"`
 
+	// BrokerTLS configures the broker to terminate TLS on its public listener itself rather than relying
+	// on the Gateway alone. When set, the certificate secret is mounted into the broker deployment.
+	// +optional
+	BrokerTLS *BrokerTLS `json:"brokerTLS,omitempty"`
+
 	// HTTPRouteManagement controls whether the operator manages the gateway HTTPRoute.
 	// Enabled: creates and manages the HTTPRoute (default).
 	// Disabled: does not create an HTTPRoute.
 	// +optional
 	// +kubebuilder:default=Enabled
 	HTTPRouteManagement HTTPRouteManagementPolicy `json:"httpRouteManagement,omitempty"`
+
+	// DeploymentStrategy controls how the broker-router deployment is rolled out.
+	// Recreate avoids two broker pods splitting in-memory sessions during a rollout.
+	// RollingUpdate avoids downtime and is safe when sessions are held in a shared cache.
+	// When unset, RollingUpdate is used if a sessionStore or a cache connection string is configured
+	// on the broker-router deployment, otherwise Recreate.
+	// +optional
+	DeploymentStrategy DeploymentStrategyType `json:"deploymentStrategy,omitempty"`
+
+	// SessionStore configures a store shared by the broker-router replicas so that MCP sessions
+	// survive restarts and can be served by any replica.
+	// +optional
+	SessionStore *SessionStore `json:"sessionStore,omitempty"`
+
+	// Replicas is the number of broker-router pods. Without a sessionStore each replica holds its own
+	// in-memory sessions, so a client may need to re-initialize when its requests reach a different replica.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// PodTemplate customizes the broker-router pod, for example to set resource limits or to meet
+	// the Pod Security Standards of the namespace.
+	// +optional
+	PodTemplate *BrokerPodTemplate `json:"podTemplate,omitempty"`
+}
+
+// BrokerPodTemplate customizes the broker-router pod and container.
+type BrokerPodTemplate struct {
+	// Resources are the compute resource requests and limits of the broker-router container.
+	// When unset no requests or limits are set.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// SecurityContext replaces the default security context of the broker-router container. By default the
+	// container runs as user and group 65532 without privilege escalation or capabilities and with the
+	// RuntimeDefault seccomp profile, and the pod fsGroup is 65532 so the mounted secrets can be read.
+	// This meets the restricted Pod Security Standard. When set, the pod fsGroup is left unset.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// ImagePullPolicy of the broker-router container.
+	// +optional
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +kubebuilder:default=IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// NodeSelector constrains the broker-router pod to nodes with matching labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 }
 
 // TrustedHeadersKey configures trusted-header key pair for JWT-based tool filtering.
@@ -102,8 +234,72 @@ type TrustedHeadersKey struct {
 	Generate KeyGenerationPolicy `json:"generate,omitempty"`
 }
 
+// BrokerTLS configures TLS on the broker's public listener.
+type BrokerTLS struct {
+	// SecretName is the name of a kubernetes.io/tls secret in the MCPGatewayExtension namespace.
+	// The secret must have the data entries "tls.crt" and "tls.key" containing the PEM-encoded
+	// certificate and private key served by the broker.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+
+	// MinVersion is the minimum TLS version the broker accepts.
+	// +optional
+	// +kubebuilder:validation:Enum="1.2";"1.3"
+	// +kubebuilder:default="1.2"
+	MinVersion string `json:"minVersion,omitempty"`
+
+	// CipherSuites limits the cipher suites the broker accepts for TLS 1.2, using the IANA names
+	// such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. TLS 1.3 cipher suites are not configurable.
+	// When unset the Go defaults are used.
+	// +optional
+	// +listType=set
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+}
+
+// ExtProcGRPCConfig configures the gRPC connection from envoy to the router.
+type ExtProcGRPCConfig struct {
+	// MaxMessageSize is the largest gRPC message in bytes envoy accepts from the router, and the router accepts from
+	// envoy. A larger message ends the stream with a RESOURCE_EXHAUSTED error. Raise it when request bodies or tool
+	// schemas are very large. If not specified, envoy does not limit the messages and the router accepts up to 4MiB.
+	// +optional
+	// +kubebuilder:validation:Minimum=1048576
+	MaxMessageSize *int32 `json:"maxMessageSize,omitempty"`
+
+	// KeepaliveInterval is how often envoy sends an HTTP/2 PING on an idle connection to the router, for example "30s",
+	// so a connection dropped without a reset is detected before requests are sent on it.
+	// If not specified, 30s.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="keepaliveInterval must be at least 1s"
+	KeepaliveInterval *metav1.Duration `json:"keepaliveInterval,omitempty"`
+
+	// KeepaliveTimeout is how long envoy waits for a PING to be acknowledged before it closes the connection.
+	// If not specified, 10s.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="keepaliveTimeout must be at least 1s"
+	KeepaliveTimeout *metav1.Duration `json:"keepaliveTimeout,omitempty"`
+}
+
+// SessionStore configures the shared store for broker sessions.
+type SessionStore struct {
+	// Type is the session store backend.
+	// +required
+	Type SessionStoreType `json:"type"`
+
+	// SecretName is the name of a secret in the MCPGatewayExtension namespace. The secret must have
+	// a data entry with key "connectionString" containing the connection string,
+	// for example redis://<user>:<pass>@redis:6379/<db>.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
+
 // MCPGatewayExtensionStatus defines the observed state of MCPGatewayExtension.
 type MCPGatewayExtensionStatus struct {
+	// ObservedGeneration is the generation of the MCPGatewayExtension last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Conditions represent the current state of the MCPGatewayExtension.
 	// The Ready condition indicates whether the broker-router deployment is running
 	// and the EnvoyFilter has been successfully applied to the target Gateway.
@@ -111,11 +307,18 @@ type MCPGatewayExtensionStatus struct {
 	// +listMapKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// BrokerVersion is the build version reported by the running broker-router.
+	// It can be used to verify that an image change has rolled out.
+	// +optional
+	BrokerVersion string `json:"brokerVersion,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status",description="Ready status"
+// +kubebuilder:printcolumn:name="Generation",type="integer",JSONPath=".metadata.generation",priority=1
+// +kubebuilder:printcolumn:name="Observed",type="integer",JSONPath=".status.observedGeneration",description="Generation last processed by the controller",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // MCPGatewayExtension extends a Gateway API Gateway to handle the Model Context Protocol (MCP).
@@ -186,8 +389,9 @@ func init() {
 	SchemeBuilder.Register(&MCPGatewayExtension{}, &MCPGatewayExtensionList{})
 }
 
-// SetReadyCondition sets the Ready condition on the MCPGatewayExtension status
+// SetReadyCondition sets the Ready condition and observed generation on the MCPGatewayExtension status
 func (m *MCPGatewayExtension) SetReadyCondition(status metav1.ConditionStatus, reason, message string) {
+	m.Status.ObservedGeneration = m.Generation
 	meta.SetStatusCondition(&m.Status.Conditions, metav1.Condition{
 		Type:               ConditionTypeReady,
 		Status:             status,
@@ -209,6 +413,17 @@ func (m *MCPGatewayExtension) InternalHost(port uint32) string {
 	return fmt.Sprintf(m.Spec.TargetRef.Name+"-istio."+gatewayNamespace+".svc.cluster.local:%v", port)
 }
 
+// DefaultBrokerPort is the port the broker serves MCP clients on when BrokerPort is not set
+const DefaultBrokerPort int32 = 8080
+
+// GetBrokerPort returns the port the broker serves MCP clients on
+func (m *MCPGatewayExtension) GetBrokerPort() int32 {
+	if m.Spec.BrokerPort != nil {
+		return *m.Spec.BrokerPort
+	}
+	return DefaultBrokerPort
+}
+
 // HTTPRouteDisabled returns true if HTTPRouteManagement is set to Disabled
 func (m *MCPGatewayExtension) HTTPRouteDisabled() bool {
 	return m.Spec.HTTPRouteManagement == HTTPRouteManagementDisabled
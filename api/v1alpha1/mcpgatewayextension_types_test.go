@@ -97,3 +97,15 @@ func TestMCPGatewayExtension_HTTPRouteManagementDisabled(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPGatewayExtension_GetBrokerPort(t *testing.T) {
+	ext := &MCPGatewayExtension{}
+	if got := ext.GetBrokerPort(); got != DefaultBrokerPort {
+		t.Errorf("GetBrokerPort() = %v, want %v", got, DefaultBrokerPort)
+	}
+	port := int32(9443)
+	ext.Spec.BrokerPort = &port
+	if got := ext.GetBrokerPort(); got != 9443 {
+		t.Errorf("GetBrokerPort() = %v, want 9443", got)
+	}
+}
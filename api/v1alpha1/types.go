@@ -8,11 +8,14 @@ import (
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=mcpsr
 // +kubebuilder:printcolumn:name="Prefix",type="string",JSONPath=".spec.toolPrefix",description="Tool prefix for federation"
-// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.targetRef.name",description="Target HTTPRoute.  MCP Gateway only supports routes with a single BackendRef"
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.targetRef.name",description="Target HTTPRoute or Service"
 // +kubebuilder:printcolumn:name="Path",type="string",JSONPath=".spec.path",description="MCP endpoint path"
+// +kubebuilder:printcolumn:name="Accepted",type="string",JSONPath=".status.conditions[?(@.type=='Accepted')].status",description="Accepted status"
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status",description="Ready status"
 // +kubebuilder:printcolumn:name="Tools",type="integer",JSONPath=".status.discoveredTools",description="Number of discovered tools"
 // +kubebuilder:printcolumn:name="Credentials",type="string",JSONPath=".spec.credentialRef.name"
+// +kubebuilder:printcolumn:name="Generation",type="integer",JSONPath=".metadata.generation",priority=1
+// +kubebuilder:printcolumn:name="Observed",type="integer",JSONPath=".status.observedGeneration",description="Generation last processed by the controller",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // MCPServerRegistration defines a collection of MCP (Model Context Protocol) servers to be aggregated by the gateway.
@@ -27,45 +30,190 @@ type MCPServerRegistration struct {
 
 // MCPServerRegistrationSpec defines the desired state of MCPServerRegistration.
 // It specifies which HTTPRoutes point to MCP servers and how their tools should be federated.
+// +kubebuilder:validation:XValidation:rule="!has(self.hostname) || self.targetRef.kind == 'HTTPRoute'",message="hostname is only supported for an HTTPRoute target"
 type MCPServerRegistrationSpec struct {
-	// TargetRef specifies an HTTPRoute that points to a backend MCP server.
+	// TargetRef specifies an HTTPRoute that points to a backend MCP server, or the Service of the MCP server itself.
 	// The referenced HTTPRoute should have a backend service that implements the MCP protocol.
 	// The controller will discover the backend service from this HTTPRoute and configure
 	// the broker to federate tools from that MCP server.
+	// A Service target skips the HTTPRoute and is federated by the MCPGatewayExtensions in the registration's namespace.
 	TargetRef TargetReference `json:"targetRef"`
 
 	// ToolPrefix is the prefix to add to all federated tools from referenced servers.
 	// This helps avoid naming conflicts when aggregating tools from multiple sources.
 	// For example, if two servers both provide a 'search' tool, prefixes like 'server1_' and 'server2_' ensure they can coexist as 'server1_search' and 'server2_search'.
+	// It may only contain the characters MCP allows in a tool name: letters, digits, '_', '-' and '.'. The characters
+	// are only checked when the prefix is set or changed, so a registration created before the check can still be updated.
 	// +optional
+	// +kubebuilder:validation:MaxLength=127
+	// +kubebuilder:validation:XValidation:rule="(oldSelf.hasValue() && self == oldSelf.value()) || self.matches('^[A-Za-z0-9_.-]*$')",message="toolPrefix may only contain letters, digits, '_', '-' and '.'",optionalOldSelf=true
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf || oldSelf == ''",message="toolPrefix is immutable once set"
 	ToolPrefix string `json:"toolPrefix,omitempty"`
 
+	// ToolNameTemplate renders the name each federated tool is served under, for names a static prefix can't produce
+	// such as a suffix. {tool} is replaced with the upstream tool name and must appear once. {prefix} is replaced with
+	// the ToolPrefix and {server} with the name of the MCPServerRegistration. For example '{tool}_{server}' serves the
+	// 'search' tool of the 'docs' registration as 'search_docs'. If not specified, tools are served as '{prefix}{tool}'.
+	// +optional
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:Pattern=`^[^{}]*(\{(prefix|server)\}[^{}]*)*\{tool\}[^{}]*(\{(prefix|server)\}[^{}]*)*$`
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf || oldSelf == ''",message="toolNameTemplate is immutable once set"
+	ToolNameTemplate string `json:"toolNameTemplate,omitempty"`
+
 	// Path specifies the URL path where the MCP server endpoint is exposed.
 	// If not specified, defaults to "/mcp".
 	// This allows connecting to MCP servers that use custom paths like "/v1/mcp" or "/api/mcp".
+	// It must begin with "/" and must not contain a query, fragment or whitespace.
 	// +optional
 	// +kubebuilder:default="/mcp"
+	// +kubebuilder:validation:Pattern=`^/[^?#\s]*$`
 	Path string `json:"path,omitempty"`
 
+	// BackendRefName selects the backendRef of the targeted HTTPRoute that serves the MCP server, for routes with
+	// more than one rule or backendRef. Without it the backend is chosen from the rules that most specifically match Path.
+	// When more than one backend still matches the registration is not ready with a BackendRefAmbiguous reason.
+	// +optional
+	BackendRefName string `json:"backendRefName,omitempty"`
+
+	// Hostname selects the hostname of the targeted HTTPRoute that tool calls are routed with, for routes that serve
+	// the MCP server under more than one hostname. It must be one of the HTTPRoute hostnames.
+	// If not specified, the first hostname of the HTTPRoute is used.
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	Hostname string `json:"hostname,omitempty"`
+
+	// HealthPath is an HTTP path on the MCP server, such as "/healthz", that the broker polls for liveness between
+	// full MCP validations. A response other than 2xx marks the server unavailable with a HealthCheckFailed reason.
+	// The MCP ping and handshake are then only used for less frequent deeper checks.
+	// If not specified, the broker pings the server with MCP on every check.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	HealthPath string `json:"healthPath,omitempty"`
+
+	// HealthCheckInterval is how often the broker checks the MCP server, for example "30s" or "5m".
+	// It overrides the backendPingIntervalSeconds of the MCPGatewayExtension for this server, so slow external
+	// servers can be checked less often than fast internal ones.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="healthCheckInterval must be at least 1s"
+	HealthCheckInterval *metav1.Duration `json:"healthCheckInterval,omitempty"`
+
+	// DrainTimeout is how long deleting the MCPServerRegistration waits for tool calls in flight to the MCP server
+	// to complete before the server is removed from the broker. The server's tools are no longer listed while it drains.
+	// If not specified, the server is removed straight away.
+	// +optional
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+
+	// CallTimeout bounds each tool call to the MCP server, for example "2m". The broker's own requests to the server,
+	// such as initialize and tools/list, are bounded by it too. A timeout hint in a tool's meta still takes precedence.
+	// If not specified, the router's tool call timeout is used.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="callTimeout must be at least 1s"
+	CallTimeout *metav1.Duration `json:"callTimeout,omitempty"`
+
 	// CredentialRef references a Secret containing authentication credentials for the MCP server.
 	// The Secret should contain a key with the authentication token or credentials.
 	// The controller will aggregate these credentials and make them available to the broker via environment variables following the pattern: KAGENTI_{MCP_NAME}_CRED
 	// +optional
 	CredentialRef *SecretReference `json:"credentialRef,omitempty"`
+
+	// TLS configures how the broker verifies and authenticates to an MCP server served over https.
+	// The CA and client certificate are read from Secrets in the same namespace as the MCPServerRegistration.
+	// If not specified, the broker verifies the server certificate against the system roots.
+	// +optional
+	TLS *UpstreamTLS `json:"tls,omitempty"`
+
+	// Categories are labels applied to every tool from this MCP server, for example to group tools by function.
+	// The broker surfaces them in the tool meta so clients can render a categorised catalog.
+	// +optional
+	// +listType=set
+	Categories []string `json:"categories,omitempty"`
+
+	// Priority decides which server's tool is registered when tools from two servers have the same name.
+	// The tool from the server with the higher priority is registered and the other is shadowed.
+	// Between servers with equal priority the server created first wins, and servers created in the same second
+	// report a conflict. Defaults to 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// UnavailablePolicy decides what happens to the server's tools while the broker cannot reach it.
+	// RemoveTools removes the tools until the server is reachable again, notifying clients that the tool list changed.
+	// KeepTools keeps the tools listed and fails calls to them with an upstream unavailable error.
+	// +optional
+	// +kubebuilder:default=RemoveTools
+	UnavailablePolicy UnavailablePolicy `json:"unavailablePolicy,omitempty"`
+
+	// ToolOverrides customise how individual tools discovered from the MCP server are presented to clients.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ToolOverrides []ToolOverride `json:"toolOverrides,omitempty"`
+
+	// ToolAliases maps upstream tool names, without any tool prefix, to the names they are served under.
+	// An aliased tool is served under its alias exactly, in place of the toolPrefix or toolNameTemplate, so a single
+	// colliding tool can be renamed without prefixing every tool of the server. Aliases must be unique and only use
+	// the characters MCP allows in a tool name. An alias should not match the served name of another tool of the server.
+	// +optional
+	ToolAliases map[string]string `json:"toolAliases,omitempty"`
+
+	// Enabled takes the MCP server out of rotation when false. Its tools are removed from the gateway while the
+	// MCPServerRegistration and its config entry are kept, so it can be enabled again without being recreated.
+	// Defaults to true.
+	// +optional
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
 }
 
-// TargetReference identifies an HTTPRoute that points to MCP servers.
+// UnavailablePolicy defines what happens to a server's tools while the broker cannot reach it
+// +kubebuilder:validation:Enum=RemoveTools;KeepTools
+type UnavailablePolicy string
+
+const (
+	// UnavailablePolicyRemoveTools removes the tools of an unreachable server
+	UnavailablePolicyRemoveTools UnavailablePolicy = "RemoveTools"
+	// UnavailablePolicyKeepTools keeps the tools of an unreachable server listed and fails calls to them
+	UnavailablePolicyKeepTools UnavailablePolicy = "KeepTools"
+)
+
+// ToolOverride customises a single tool discovered from the MCP server.
+type ToolOverride struct {
+	// Name is the name of the tool as exposed by the upstream MCP server, without any tool prefix.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Deprecated marks the tool as deprecated. A deprecation note is appended to the tool description
+	// and the tool meta is annotated so clients can warn users. The tool remains callable until it is removed.
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	// DeprecationMessage is added to the deprecation note, for example to name a replacement tool.
+	// Only used when deprecated is true.
+	// +optional
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
+
+	// Categories are labels applied to this tool in addition to the server categories.
+	// +optional
+	// +listType=set
+	Categories []string `json:"categories,omitempty"`
+
+	// Idempotent marks the tool as safe to retry after a connection failure when tool call retries are enabled.
+	// When unset the readOnlyHint and idempotentHint annotations advertised by the upstream tool are used.
+	// +optional
+	Idempotent *bool `json:"idempotent,omitempty"`
+}
+
+// TargetReference identifies an HTTPRoute that points to MCP servers, or a Service of an MCP server.
 // It follows Gateway API patterns for cross-resource references.
+// +kubebuilder:validation:XValidation:rule="self.kind == 'Service' ? size(self.group) == 0 : self.group == 'gateway.networking.k8s.io'",message="group must be empty for a Service and gateway.networking.k8s.io for an HTTPRoute"
+// +kubebuilder:validation:XValidation:rule="!has(self.port) || self.kind == 'Service'",message="port is only supported for a Service target"
 type TargetReference struct {
-	// Group is the group of the target resource.
+	// Group is the group of the target resource. It is empty for a Service.
 	// +kubebuilder:default=gateway.networking.k8s.io
-	// +kubebuilder:validation:Enum=gateway.networking.k8s.io
+	// +kubebuilder:validation:Enum="";gateway.networking.k8s.io
 	Group string `json:"group"`
 
 	// Kind is the kind of the target resource.
 	// +kubebuilder:default=HTTPRoute
-	// +kubebuilder:validation:Enum=HTTPRoute
+	// +kubebuilder:validation:Enum=HTTPRoute;Service
 	Kind string `json:"kind"`
 
 	// Name is the name of the target resource.
@@ -75,6 +223,12 @@ type TargetReference struct {
 	// Namespace of the target resource (optional, defaults to same namespace)
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
+
+	// Port is the port of the target Service. Required when the Service has more than one port.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
 }
 
 // SecretReference identifies a Secret containing credentials for MCP server authentication.
@@ -87,11 +241,56 @@ type SecretReference struct {
 	// +kubebuilder:default=token
 	// +optional
 	Key string `json:"key,omitempty"`
+
+	// Namespace is the namespace of the Secret. If not specified, the MCPServerRegistration's namespace is used.
+	// A Secret in another namespace requires a ReferenceGrant in that namespace allowing MCPServerRegistrations
+	// from the MCPServerRegistration's namespace to reference it.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// UpstreamTLS configures TLS for the broker's connection to an MCP server.
+type UpstreamTLS struct {
+	// CASecretRef references a Secret containing a PEM bundle of the certificate authorities trusted to sign the
+	// MCP server's certificate. If not specified, the system roots are used.
+	// +optional
+	CASecretRef *CASecretReference `json:"caSecretRef,omitempty"`
+
+	// ClientCertificateSecretRef references a kubernetes.io/tls Secret whose tls.crt and tls.key are presented to
+	// the MCP server for mutual TLS.
+	// +optional
+	ClientCertificateSecretRef *ClientCertificateSecretReference `json:"clientCertificateSecretRef,omitempty"`
+
+	// InsecureSkipVerify disables verification of the MCP server's certificate. Only use it for testing.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// CASecretReference identifies a Secret containing trusted certificate authorities.
+type CASecretReference struct {
+	// Name is the name of the Secret resource.
+	Name string `json:"name"`
+
+	// Key is the key within the Secret that contains the PEM encoded certificates.
+	// If not specified, defaults to "ca.crt".
+	// +kubebuilder:default=ca.crt
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// ClientCertificateSecretReference identifies a kubernetes.io/tls Secret containing a client certificate and key.
+type ClientCertificateSecretReference struct {
+	// Name is the name of the Secret resource.
+	Name string `json:"name"`
 }
 
 // MCPServerRegistrationStatus represents the observed state of the MCPServerRegistration resource.
 // It contains conditions that indicate whether the referenced servers have been successfully discovered and are ready for use.
 type MCPServerRegistrationStatus struct {
+	// ObservedGeneration is the generation of the MCPServerRegistration last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Conditions represent the latest available observations of the MCPServerRegistration's state.
 	// Common conditions include 'Ready' to indicate if all referenced servers are accessible.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -99,6 +298,46 @@ type MCPServerRegistrationStatus struct {
 	// DiscoveredTools is the number of tools discovered from this MCPServerRegistration
 	// +optional
 	DiscoveredTools int `json:"discoveredTools,omitempty"`
+
+	// ConflictingTools are the tools the broker rejected because an MCP server of equal priority serves a tool with
+	// the same name. Set a distinct tool prefix to resolve the conflict.
+	// +optional
+	// +listType=set
+	ConflictingTools []string `json:"conflictingTools,omitempty"`
+
+	// ProtocolVersion is the MCP protocol version the MCP server advertised during initialize, including a version
+	// the broker rejected as unsupported.
+	// +optional
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+
+	// ConsecutiveFailures is the number of status checks in a row that found the MCP server failing. It is reset when
+	// the server becomes ready, the spec changes or the force-sync annotation changes. After enough failures the server is checked less often and the
+	// Ready condition reports a Backoff reason.
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// ForceSync is the value of the mcp.kagenti.com/force-sync annotation last handled. A different annotation value
+	// resets ConsecutiveFailures and makes the broker validate the MCP server again.
+	// +optional
+	ForceSync string `json:"forceSync,omitempty"`
+
+	// ConfigNamespaces are the namespaces whose broker config this MCPServerRegistration has been written to.
+	// Config is removed from namespaces that are no longer valid, for example when an MCPGatewayExtension is deleted.
+	// +optional
+	// +listType=set
+	ConfigNamespaces []string `json:"configNamespaces,omitempty"`
+
+	// ServerID is the ID of the server last written to the broker config. It changes when the target, hostname or
+	// tool prefix of the MCPServerRegistration changes, and the new config then replaces the previous server's.
+	// +optional
+	ServerID string `json:"serverID,omitempty"`
+
+	// VirtualServers are the MCPVirtualServers, as namespace/name, that list this MCPServerRegistration in
+	// spec.servers or reference a tool matching its tool prefix. A registration without a tool prefix may serve any
+	// tool so every MCPVirtualServer with spec.tools is listed. Check these before deleting or changing the registration.
+	// +optional
+	// +listType=set
+	VirtualServers []string `json:"virtualServers,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -112,7 +351,12 @@ type MCPServerRegistrationList struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Namespaced,shortName=mcpvs
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Tools",type="integer",JSONPath=".spec.tools.length()"
+// +kubebuilder:printcolumn:name="Resolved",type="integer",JSONPath=".status.resolvedTools"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status",description="Ready status"
+// +kubebuilder:printcolumn:name="Generation",type="integer",JSONPath=".metadata.generation",priority=1
+// +kubebuilder:printcolumn:name="Observed",type="integer",JSONPath=".status.observedGeneration",description="Generation last processed by the controller",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // MCPVirtualServer defines a virtual server that exposes a specific set of tools.
@@ -122,11 +366,13 @@ type MCPVirtualServer struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec MCPVirtualServerSpec `json:"spec,omitempty"`
+	Spec   MCPVirtualServerSpec   `json:"spec,omitempty"`
+	Status MCPVirtualServerStatus `json:"status,omitempty"`
 }
 
 // MCPVirtualServerSpec defines the desired state of MCPVirtualServer.
 // It specifies which tools should be exposed by this virtual server.
+// +kubebuilder:validation:XValidation:rule="(has(self.tools) && size(self.tools) > 0) || (has(self.servers) && size(self.servers) > 0)",message="at least one of tools or servers must be set"
 type MCPVirtualServerSpec struct {
 	// Description provides a human-readable description of this virtual server's purpose.
 	// +optional
@@ -134,8 +380,69 @@ type MCPVirtualServerSpec struct {
 
 	// Tools specifies the list of tool names to expose through this virtual server.
 	// These tools must be available from the underlying MCP servers configured in the system.
-	// +kubebuilder:validation:MinItems=1
-	Tools []string `json:"tools"`
+	// A name may contain * to match any sequence of characters, for example "weather_*", so tools added to an
+	// MCP server later are exposed without changing the virtual server.
+	// +optional
+	Tools []string `json:"tools,omitempty"`
+
+	// Servers exposes tools scoped to a specific MCPServerRegistration, for example every tool of one
+	// registration and two tools of another. A tool is exposed if it is listed in tools or selected here.
+	// +optional
+	Servers []MCPVirtualServerServer `json:"servers,omitempty"`
+}
+
+// MCPVirtualServerServer selects the tools of one MCPServerRegistration exposed by a virtual server.
+type MCPVirtualServerServer struct {
+	// Name is the name of the MCPServerRegistration.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the MCPServerRegistration. Defaults to the namespace of the MCPVirtualServer.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Tools are the tools of the server to expose, named as the server serves them without the
+	// MCPServerRegistration's tool prefix. A name may contain * as in spec.tools. Every tool of the server is
+	// exposed when empty.
+	// +optional
+	Tools []string `json:"tools,omitempty"`
+}
+
+// RegistrationName returns the namespace/name of the referenced MCPServerRegistration, defaulting the namespace to
+// the namespace of the virtual server
+func (s MCPVirtualServerServer) RegistrationName(virtualServerNamespace string) string {
+	namespace := s.Namespace
+	if namespace == "" {
+		namespace = virtualServerNamespace
+	}
+	return namespace + "/" + s.Name
+}
+
+// MCPVirtualServerStatus represents the observed state of the MCPVirtualServer resource.
+// Tools are resolved by matching their name against the tool prefix of Ready MCPServerRegistrations.
+type MCPVirtualServerStatus struct {
+	// ObservedGeneration is the generation of the MCPVirtualServer last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the MCPVirtualServer's state.
+	// The 'Ready' condition is true when at least one of its tools or servers resolves to a Ready MCPServerRegistration.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ResolvedTools is the number of tools in spec.tools served by a Ready MCPServerRegistration.
+	// +optional
+	ResolvedTools int `json:"resolvedTools,omitempty"`
+
+	// UnresolvedTools are the tools in spec.tools that no Ready MCPServerRegistration serves.
+	// +optional
+	// +listType=set
+	UnresolvedTools []string `json:"unresolvedTools,omitempty"`
+
+	// UnresolvedServers are the MCPServerRegistrations in spec.servers, as namespace/name, that do not exist or
+	// are not Ready. Their tools are exposed once they become Ready.
+	// +optional
+	// +listType=set
+	UnresolvedServers []string `json:"unresolvedServers,omitempty"`
 }
 
 // +kubebuilder:object:root=true
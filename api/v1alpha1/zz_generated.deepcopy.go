@@ -5,10 +5,123 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerPodTemplate) DeepCopyInto(out *BrokerPodTemplate) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerPodTemplate.
+func (in *BrokerPodTemplate) DeepCopy() *BrokerPodTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerPodTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerTLS) DeepCopyInto(out *BrokerTLS) {
+	*out = *in
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerTLS.
+func (in *BrokerTLS) DeepCopy() *BrokerTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CASecretReference) DeepCopyInto(out *CASecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CASecretReference.
+func (in *CASecretReference) DeepCopy() *CASecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CASecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientCertificateSecretReference) DeepCopyInto(out *ClientCertificateSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientCertificateSecretReference.
+func (in *ClientCertificateSecretReference) DeepCopy() *ClientCertificateSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientCertificateSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtProcGRPCConfig) DeepCopyInto(out *ExtProcGRPCConfig) {
+	*out = *in
+	if in.MaxMessageSize != nil {
+		in, out := &in.MaxMessageSize, &out.MaxMessageSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepaliveInterval != nil {
+		in, out := &in.KeepaliveInterval, &out.KeepaliveInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.KeepaliveTimeout != nil {
+		in, out := &in.KeepaliveTimeout, &out.KeepaliveTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtProcGRPCConfig.
+func (in *ExtProcGRPCConfig) DeepCopy() *ExtProcGRPCConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtProcGRPCConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ListenerConfig) DeepCopyInto(out *ListenerConfig) {
 	*out = *in
@@ -87,16 +200,66 @@ func (in *MCPGatewayExtensionList) DeepCopyObject() runtime.Object {
 func (in *MCPGatewayExtensionSpec) DeepCopyInto(out *MCPGatewayExtensionSpec) {
 	*out = *in
 	out.TargetRef = in.TargetRef
+	if in.BrokerPort != nil {
+		in, out := &in.BrokerPort, &out.BrokerPort
+		*out = new(int32)
+		**out = **in
+	}
 	if in.BackendPingIntervalSeconds != nil {
 		in, out := &in.BackendPingIntervalSeconds, &out.BackendPingIntervalSeconds
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ToolCallConcurrencyPerServer != nil {
+		in, out := &in.ToolCallConcurrencyPerServer, &out.ToolCallConcurrencyPerServer
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ToolCallRetries != nil {
+		in, out := &in.ToolCallRetries, &out.ToolCallRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExtProcMessageTimeout != nil {
+		in, out := &in.ExtProcMessageTimeout, &out.ExtProcMessageTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ExtProcGRPC != nil {
+		in, out := &in.ExtProcGRPC, &out.ExtProcGRPC
+		*out = new(ExtProcGRPCConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxTotalTools != nil {
+		in, out := &in.MaxTotalTools, &out.MaxTotalTools
+		*out = new(int32)
+		**out = **in
+	}
 	if in.TrustedHeadersKey != nil {
 		in, out := &in.TrustedHeadersKey, &out.TrustedHeadersKey
 		*out = new(TrustedHeadersKey)
 		**out = **in
 	}
+	if in.BrokerTLS != nil {
+		in, out := &in.BrokerTLS, &out.BrokerTLS
+		*out = new(BrokerTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SessionStore != nil {
+		in, out := &in.SessionStore, &out.SessionStore
+		*out = new(SessionStore)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(BrokerPodTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPGatewayExtensionSpec.
@@ -208,12 +371,56 @@ func (in *MCPServerRegistrationList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MCPServerRegistrationSpec) DeepCopyInto(out *MCPServerRegistrationSpec) {
 	*out = *in
-	out.TargetRef = in.TargetRef
+	in.TargetRef.DeepCopyInto(&out.TargetRef)
+	if in.HealthCheckInterval != nil {
+		in, out := &in.HealthCheckInterval, &out.HealthCheckInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DrainTimeout != nil {
+		in, out := &in.DrainTimeout, &out.DrainTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CallTimeout != nil {
+		in, out := &in.CallTimeout, &out.CallTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	if in.CredentialRef != nil {
 		in, out := &in.CredentialRef, &out.CredentialRef
 		*out = new(SecretReference)
 		**out = **in
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(UpstreamTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Categories != nil {
+		in, out := &in.Categories, &out.Categories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ToolOverrides != nil {
+		in, out := &in.ToolOverrides, &out.ToolOverrides
+		*out = make([]ToolOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ToolAliases != nil {
+		in, out := &in.ToolAliases, &out.ToolAliases
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPServerRegistrationSpec.
@@ -236,6 +443,21 @@ func (in *MCPServerRegistrationStatus) DeepCopyInto(out *MCPServerRegistrationSt
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConflictingTools != nil {
+		in, out := &in.ConflictingTools, &out.ConflictingTools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigNamespaces != nil {
+		in, out := &in.ConfigNamespaces, &out.ConfigNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VirtualServers != nil {
+		in, out := &in.VirtualServers, &out.VirtualServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPServerRegistrationStatus.
@@ -254,6 +476,7 @@ func (in *MCPVirtualServer) DeepCopyInto(out *MCPVirtualServer) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPVirtualServer.
@@ -306,6 +529,26 @@ func (in *MCPVirtualServerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPVirtualServerServer) DeepCopyInto(out *MCPVirtualServerServer) {
+	*out = *in
+	if in.Tools != nil {
+		in, out := &in.Tools, &out.Tools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPVirtualServerServer.
+func (in *MCPVirtualServerServer) DeepCopy() *MCPVirtualServerServer {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPVirtualServerServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MCPVirtualServerSpec) DeepCopyInto(out *MCPVirtualServerSpec) {
 	*out = *in
@@ -314,6 +557,13 @@ func (in *MCPVirtualServerSpec) DeepCopyInto(out *MCPVirtualServerSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]MCPVirtualServerServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPVirtualServerSpec.
@@ -326,6 +576,38 @@ func (in *MCPVirtualServerSpec) DeepCopy() *MCPVirtualServerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPVirtualServerStatus) DeepCopyInto(out *MCPVirtualServerStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnresolvedTools != nil {
+		in, out := &in.UnresolvedTools, &out.UnresolvedTools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnresolvedServers != nil {
+		in, out := &in.UnresolvedServers, &out.UnresolvedServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPVirtualServerStatus.
+func (in *MCPVirtualServerStatus) DeepCopy() *MCPVirtualServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPVirtualServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretReference) DeepCopyInto(out *SecretReference) {
 	*out = *in
@@ -341,9 +623,29 @@ func (in *SecretReference) DeepCopy() *SecretReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionStore) DeepCopyInto(out *SessionStore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionStore.
+func (in *SessionStore) DeepCopy() *SessionStore {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetReference) DeepCopyInto(out *TargetReference) {
 	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetReference.
@@ -356,6 +658,31 @@ func (in *TargetReference) DeepCopy() *TargetReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ToolOverride) DeepCopyInto(out *ToolOverride) {
+	*out = *in
+	if in.Categories != nil {
+		in, out := &in.Categories, &out.Categories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Idempotent != nil {
+		in, out := &in.Idempotent, &out.Idempotent
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolOverride.
+func (in *ToolOverride) DeepCopy() *ToolOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ToolOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrustedHeadersKey) DeepCopyInto(out *TrustedHeadersKey) {
 	*out = *in
@@ -370,3 +697,28 @@ func (in *TrustedHeadersKey) DeepCopy() *TrustedHeadersKey {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamTLS) DeepCopyInto(out *UpstreamTLS) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(CASecretReference)
+		**out = **in
+	}
+	if in.ClientCertificateSecretRef != nil {
+		in, out := &in.ClientCertificateSecretRef, &out.ClientCertificateSecretRef
+		*out = new(ClientCertificateSecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamTLS.
+func (in *UpstreamTLS) DeepCopy() *UpstreamTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamTLS)
+	in.DeepCopyInto(out)
+	return out
+}
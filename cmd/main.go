@@ -19,25 +19,44 @@ This package contains the main of the mcp controller
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	goenv "github.com/caitlinelfring/go-env-default"
 	istionetv1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/buildinfo"
 	"github.com/Kuadrant/mcp-gateway/internal/config"
 	"github.com/Kuadrant/mcp-gateway/internal/controller"
+	mcpotel "github.com/Kuadrant/mcp-gateway/internal/otel"
+)
+
+// set at build time with -ldflags
+var (
+	version = "dev"
+	gitSHA  = "unknown"
+	dirty   = ""
 )
 
 func init() {
@@ -50,8 +69,52 @@ func init() {
 func main() {
 	var loglevel int
 	var logFormat string
+	var brokerMetrics bool
+	var brokerMetricsInterval time.Duration
+	var validationTimeout time.Duration
+	var validationRetries int
+	var validationRetryInterval time.Duration
+	var validationDeadline time.Duration
+	var validationGrace time.Duration
+	var configLoadTimeout time.Duration
+	var statusCoalesceWindow time.Duration
+	var statusRefreshInterval time.Duration
+	var failureBackoffThreshold int
+	var failureBackoffInterval time.Duration
+	var slowReconcileThreshold time.Duration
+	var credentialSecretSelector string
+	var credentialSecretLabel string
+	var cacheCredentialSecretsOnly bool
+	var enableWebhooks bool
+	var webhookCertDir string
+	var dryRun bool
+	var leaderElect bool
+	var leaderElectionNamespace string
+	var otlpTracesEndpoint string
 	flag.IntVar(&loglevel, "log-level", int(slog.LevelInfo), "log level: 0=info, 8=error, -4=debug")
 	flag.StringVar(&logFormat, "log-format", "txt", "log format: txt or json")
+	flag.BoolVar(&brokerMetrics, "broker-metrics", false, "scrape broker status and re-export per-server metrics on the controller metrics endpoint")
+	flag.DurationVar(&brokerMetricsInterval, "broker-metrics-interval", controller.DefaultBrokerMetricsInterval, "how often to scrape broker status when --broker-metrics is set")
+	flag.DurationVar(&validationTimeout, "broker-validation-timeout", controller.DefaultValidationTimeout, "timeout for each broker status request used to set registration readiness")
+	flag.IntVar(&validationRetries, "broker-validation-retries", 0, "number of times a failed broker status request is retried")
+	flag.DurationVar(&validationRetryInterval, "broker-validation-retry-interval", controller.DefaultValidationRetryInterval, "wait between broker status request retries")
+	flag.DurationVar(&validationDeadline, "broker-validation-deadline", controller.DefaultValidationDeadline, "limit on fetching broker status across every broker endpoint and retry")
+	flag.DurationVar(&validationGrace, "broker-validation-grace", 30*time.Second, "how long registrations keep their last known status while broker status requests time out. 0 disables")
+	flag.DurationVar(&configLoadTimeout, "config-load-timeout", 2*time.Minute, "how long a registration waits for the broker to load its config before reporting ConfigLoadTimeout. 0 disables")
+	flag.DurationVar(&statusCoalesceWindow, "status-coalesce-window", 5*time.Second, "minimum time between registration status writes that do not change readiness, such as tool count changes. 0 disables")
+	flag.DurationVar(&statusRefreshInterval, "status-refresh-interval", time.Minute, "how often ready registrations poll the broker so their status, such as the tool count, follows backend changes without a resource change. 0 disables")
+	flag.IntVar(&failureBackoffThreshold, "failure-backoff-threshold", 5, "number of status checks in a row that must find an MCP server failing before it is only checked every --failure-backoff-interval and reported with a Backoff reason. 0 disables")
+	flag.DurationVar(&failureBackoffInterval, "failure-backoff-interval", 10*time.Minute, "how often registrations whose MCP server keeps failing check the broker again")
+	flag.DurationVar(&slowReconcileThreshold, "slow-reconcile-threshold", 0, "record reconcile durations as metrics and warn when a reconcile takes longer than this. 0 disables")
+	flag.StringVar(&credentialSecretLabel, "credential-secret-label", controller.DefaultCredentialLabel.String(), "label, as key=value, that Secrets referenced by MCPServerRegistrations for credentials or TLS must carry")
+	flag.StringVar(&credentialSecretSelector, "credential-secret-selector", "", "label selector that credential Secrets must also match to trigger MCPServerRegistration reconciles, for example mcp.kuadrant.io/registration=true. Empty matches all credential Secrets")
+	flag.BoolVar(&cacheCredentialSecretsOnly, "cache-credential-secrets-only", false, "only cache Secrets carrying the --credential-secret-label label to reduce memory use in clusters with many Secrets. Config secrets are then read from the API server")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "serve the MCPServerRegistration validating webhook on :9443. Requires a serving certificate in --webhook-cert-dir and the webhook configuration from config/webhook")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs", "directory holding tls.crt and tls.key for the webhook server")
+	flag.BoolVar(&dryRun, "dry-run", false, "log the config secret changes the controller would make instead of writing them, and send every other write, such as status updates, to the API server as a dry run so nothing in the cluster is changed")
+	flag.BoolVar(&leaderElect, "leader-elect", false, "elect a leader with a Lease so only one of several controller replicas reconciles at a time")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "", "namespace of the leader election Lease. Defaults to the namespace the controller runs in")
+	flag.StringVar(&otlpTracesEndpoint, "otlp-traces-endpoint", "", "OTLP endpoint to export reconcile traces to, for example http://otel-collector:4318 or rpc://otel-collector:4317. Empty disables tracing unless OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT is set")
 	flag.Parse()
 
 	loggerOpts := &slog.HandlerOptions{}
@@ -74,33 +137,80 @@ func main() {
 	}
 
 	ctrl.SetLogger(logr.FromSlogHandler(slogger.Handler()))
-	slogger.Info("Controller starting (health: :8081, metrics: :8082)...")
+	slogger.Info("Controller starting (health: :8081, metrics: :8082)...", "version", version, "gitSHA", gitSHA+dirty)
 	ctx := ctrl.SetupSignalHandler()
+	if tracesEndpoint := mcpotel.ResolveTracesEndpoint(otlpTracesEndpoint); tracesEndpoint != "" {
+		tracingShutdown, err := mcpotel.SetupTracing(ctx, otlpTracesEndpoint, "mcp-gateway-controller", gitSHA, dirty, version)
+		if err != nil {
+			panic("invalid --otlp-traces-endpoint : " + err.Error())
+		}
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tracingShutdown(shutdownCtx); err != nil {
+				slogger.Error("failed to flush traces", "error", err)
+			}
+		}()
+		slogger.Info("OpenTelemetry tracing enabled", "endpoint", tracesEndpoint)
+	}
+	credentialLabel, err := controller.ParseCredentialLabel(credentialSecretLabel)
+	if err != nil {
+		panic("invalid --credential-secret-label : " + err.Error())
+	}
+
+	var webhookServer webhook.Server
+	if enableWebhooks {
+		webhookServer = webhook.NewServer(webhook.Options{Port: 9443, CertDir: webhookCertDir})
+	}
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme.Scheme,
-		Metrics:                metricsserver.Options{BindAddress: ":8082"},
-		LeaderElection:         false,
-		HealthProbeBindAddress: ":8081",
-		//TODO look at adding this type of filtering
-		// Cache: cache.Options{
-		// 	ByObject: map[client.Object]cache.ByObject{
-		// 		&v1.Secret{}: {
-		// 			Label: labels.SelectorFromSet(labels.Set{
-		// 				"mcp.kuadrant.io/credential": "true",
-		// 			}),
-		// 		},
-		// 	},
-		// },
+		Scheme:        scheme.Scheme,
+		WebhookServer: webhookServer,
+		// a dry run client validates writes with the API server without persisting them
+		Client: client.Options{DryRun: &dryRun},
+		Metrics: metricsserver.Options{
+			BindAddress: ":8082",
+			ExtraHandlers: map[string]http.Handler{
+				buildinfo.Path: buildinfo.Handler(buildinfo.Info{Version: version, GitSHA: gitSHA + dirty}),
+			},
+		},
+		LeaderElection:          leaderElect,
+		LeaderElectionID:        controller.LeaderElectionID,
+		LeaderElectionNamespace: leaderElectionNamespace,
+		// the next leader can take over as soon as this replica stops instead of waiting for the lease to expire
+		LeaderElectionReleaseOnCancel: true,
+		HealthProbeBindAddress:        ":8081",
+		Cache:                         cacheOptions(cacheCredentialSecretsOnly, credentialLabel),
 	})
 	if err != nil {
 		panic("unable to start manager : " + err.Error())
 	}
 
-	configReaderWriter := config.SecretReaderWriter{
+	secretReaderWriter := &config.SecretReaderWriter{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Logger: slogger,
 	}
+	var configSecretCache cache.Cache
+	if cacheCredentialSecretsOnly {
+		// config secrets are not labeled as credentials so they are missing from the cache
+		secretReaderWriter.Reader = mgr.GetAPIReader()
+		configSecretCache, err = newConfigSecretCache(mgr)
+		if err != nil {
+			panic("unable to start manager : " + err.Error())
+		}
+	}
+	var configReaderWriter interface {
+		controller.MCPServerConfigReaderWriter
+		controller.ConfigWriterDeleter
+		controller.VirtualServerConfigReaderWriter
+	} = secretReaderWriter
+	recorder := mgr.GetEventRecorder("mcp-gateway-controller")
+	if dryRun {
+		slogger.Info("dry run: config secrets, status and other resources will not be changed")
+		configReaderWriter = &config.DryRunReaderWriter{Reader: mgr.GetAPIReader(), Logger: slogger}
+		// events are not written through the dry run client so they are disabled
+		recorder = nil
+	}
 
 	mcpExtFinderValidator := &controller.MCPGatewayExtensionValidator{
 		Client:          mgr.GetClient(),
@@ -108,12 +218,47 @@ func main() {
 		Logger:          slogger,
 	}
 
+	var reconcileTiming *controller.ReconcileTiming
+	if slowReconcileThreshold > 0 {
+		reconcileTiming = &controller.ReconcileTiming{Threshold: slowReconcileThreshold, Logger: slogger}
+	}
+
+	var credentialSelector labels.Selector
+	if credentialSecretSelector != "" {
+		credentialSelector, err = labels.Parse(credentialSecretSelector)
+		if err != nil {
+			panic("invalid --credential-secret-selector : " + err.Error())
+		}
+	}
+
+	serverValidator := controller.NewServerValidator(mgr.GetClient(),
+		controller.WithValidationTimeout(validationTimeout),
+		controller.WithValidationRetries(validationRetries, validationRetryInterval),
+		controller.WithValidationDeadline(validationDeadline),
+	)
+
+	// the MCPGatewayExtension controller tells the MCPServerRegistration controller when an extension's cleanup is done.
+	// buffered so deleting several extensions at once doesn't block their reconciles on the registration controller
+	extensionDeleted := make(chan event.GenericEvent, 16)
 	if err = (&controller.MCPReconciler{
-		Client:                mgr.GetClient(),
-		Scheme:                mgr.GetScheme(),
-		DirectAPIReader:       mgr.GetAPIReader(),
-		ConfigReaderWriter:    &configReaderWriter,
-		MCPExtFinderValidator: mcpExtFinderValidator,
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		DirectAPIReader:          mgr.GetAPIReader(),
+		ConfigReaderWriter:       configReaderWriter,
+		MCPExtFinderValidator:    mcpExtFinderValidator,
+		StatusFetcher:            serverValidator,
+		ValidationGrace:          validationGrace,
+		ConfigLoadTimeout:        configLoadTimeout,
+		StatusCoalesceWindow:     statusCoalesceWindow,
+		StatusRefreshInterval:    statusRefreshInterval,
+		FailureBackoffThreshold:  failureBackoffThreshold,
+		FailureBackoffInterval:   failureBackoffInterval,
+		CredentialLabel:          credentialLabel,
+		CredentialSecretSelector: credentialSelector,
+		ReconcileTiming:          reconcileTiming,
+		Recorder:                 recorder,
+		ExtensionDeleted:         extensionDeleted,
+		ConfigSecretCache:        configSecretCache,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		panic("unable to start manager : " + err.Error())
 	}
@@ -124,9 +269,13 @@ func main() {
 		Client:                mgr.GetClient(),
 		Scheme:                mgr.GetScheme(),
 		DirectAPIReader:       mgr.GetAPIReader(),
-		ConfigWriterDeleter:   &configReaderWriter,
+		ConfigWriterDeleter:   configReaderWriter,
 		MCPExtFinderValidator: mcpExtFinderValidator,
 		BrokerRouterImage:     brokerRouterImage,
+		BrokerVersionFetcher:  serverValidator,
+		ReconcileTiming:       reconcileTiming,
+		ExtensionDeleted:      extensionDeleted,
+		ConfigSecretCache:     configSecretCache,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		panic("unable to start manager : " + err.Error())
 	}
@@ -135,11 +284,32 @@ func main() {
 		Client:             mgr.GetClient(),
 		Scheme:             mgr.GetScheme(),
 		DirectAPIReader:    mgr.GetAPIReader(),
-		ConfigReaderWriter: &configReaderWriter,
+		ConfigReaderWriter: configReaderWriter,
+		ReconcileTiming:    reconcileTiming,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		panic("unable to start manager : " + err.Error())
 	}
 
+	if enableWebhooks {
+		if err := (&controller.MCPServerRegistrationValidator{
+			Reader:          mgr.GetAPIReader(),
+			CredentialLabel: credentialLabel,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			panic("unable to set up MCPServerRegistration webhook : " + err.Error())
+		}
+	}
+
+	if brokerMetrics {
+		if err := mgr.Add(&controller.BrokerMetricsExporter{
+			Client:   mgr.GetClient(),
+			Fetcher:  serverValidator,
+			Interval: brokerMetricsInterval,
+			Logger:   slogger,
+		}); err != nil {
+			panic("unable to start manager : " + err.Error())
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		panic("unable to start manager : " + err.Error())
 	}
@@ -151,3 +321,41 @@ func main() {
 		panic("unable to start manager : " + err.Error())
 	}
 }
+
+// cacheOptions restricts the ConfigMap informer to the ConfigMaps the controller manages, and the Secret informer to
+// credential secrets when credentialSecretsOnly is set
+func cacheOptions(credentialSecretsOnly bool, credentialLabel controller.CredentialLabel) cache.Options {
+	byObject := map[client.Object]cache.ByObject{
+		// the broker CA is the only ConfigMap read so the informer doesn't need every ConfigMap in the cluster
+		&corev1.ConfigMap{}: {
+			Label: controller.ManagedLabelSelector(),
+		},
+	}
+	if credentialSecretsOnly {
+		byObject[&corev1.Secret{}] = cache.ByObject{
+			Label: labels.SelectorFromSet(labels.Set{credentialLabel.Key: credentialLabel.Value}),
+		}
+	}
+	return cache.Options{ByObject: byObject}
+}
+
+// newConfigSecretCache returns a cache of the config secrets, added to the manager, so their deletion is still watched
+// when the manager's cache only holds credential secrets
+func newConfigSecretCache(mgr ctrl.Manager) (cache.Cache, error) {
+	configSecretCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme: mgr.GetScheme(),
+		Mapper: mgr.GetRESTMapper(),
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Secret{}: {
+				Label: labels.SelectorFromSet(labels.Set{config.AggregatedSecretLabel: "true"}),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config secret cache: %w", err)
+	}
+	if err := mgr.Add(configSecretCache); err != nil {
+		return nil, fmt.Errorf("failed to add config secret cache: %w", err)
+	}
+	return configSecretCache, nil
+}
@@ -11,11 +11,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
 	"github.com/Kuadrant/mcp-gateway/internal/broker"
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/buildinfo"
 	"github.com/Kuadrant/mcp-gateway/internal/clients"
 	config "github.com/Kuadrant/mcp-gateway/internal/config"
 	mcpRouter "github.com/Kuadrant/mcp-gateway/internal/mcp-router"
@@ -24,7 +29,9 @@ import (
 	goenv "github.com/caitlinelfring/go-env-default"
 	extProcV3 "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	"github.com/fsnotify/fsnotify"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -55,6 +62,7 @@ func init() {
 var (
 	mcpRouterAddrFlag         string
 	mcpBrokerAddrFlag         string
+	metricsAddrFlag           string
 	mcpRoutePublicHost        string
 	mcpRoutePrivateHost       string
 	mcpRouterKey              string
@@ -62,11 +70,38 @@ var (
 	mcpConfigFile             string
 	jwtSigningKeyFlag         string
 	sessionDurationInMins     int64
+	sessionResumptionSecs     int64
 	brokerWriteTimeoutSecs    int64
 	managerTickerIntervalSecs int64
+	startupGraceSecs          int64
+	unavailableGraceSecs      int64
+	connectRetryAttempts      int
+	connectRetryBaseDelayMs   int64
+	connectRetryMaxDelayMs    int64
+	acceptedProtocolVersions  string
+	toolCallConcurrency       int
+	maxTotalTools             int
+	toolsPageSize             int
+	grpcMaxMessageSize        int
+	instructions              string
+	toolCallRetries           int
+	toolCallTimeoutSecs       int64
+	routeTimeoutSecs          int64
+	serverAvailabilityMeta    bool
 	loglevel                  int
 	logFormat                 string
 	enforceToolFilteringFlag  bool
+	authAPIKeysFlag           string
+	adminAPIKeysFlag          string
+	authAPIKeyHeaderFlag      string
+	authJWTPublicKeyFlag      string
+	exposeUpstreamLatency     bool
+	debugEndpoints            bool
+	stateHandoffFile          string
+	tlsCertFile               string
+	tlsKeyFile                string
+	tlsMinVersion             string
+	tlsCipherSuites           string
 )
 
 func main() {
@@ -83,6 +118,12 @@ func main() {
 		"0.0.0.0:8080",
 		"The public address for MCP broker",
 	)
+	flag.StringVar(
+		&metricsAddrFlag,
+		"metrics-address",
+		"0.0.0.0:8082",
+		"The internal address the broker serves Prometheus metrics on at /metrics. It is not routed through the gateway",
+	)
 	flag.StringVar(
 		&mcpRoutePublicHost,
 		"mcp-gateway-public-host",
@@ -127,11 +168,50 @@ func main() {
 		"redis based cache connection string redis://<user>:<pass>@localhost:6379/<db> (env: CACHE_CONNECTION_STRING). If not set defaults to  in memory storage",
 	)
 	flag.StringVar(&logFormat, "log-format", "txt", "switch to json logs with --log-format=json")
+	flag.StringVar(&stateHandoffFile, "state-handoff-file", "", "path on a volume shared between broker pods. On shutdown the in memory sessions are written to it, encrypted with a key derived from the session signing key, and on startup they are restored from it so sessions survive a controlled rollout. Only used with the in memory session cache. Default empty (disabled).")
 
 	flag.Int64Var(&sessionDurationInMins, "session-length", 60*24, "default session length with the gateway in minutes. Default 24h")
+	flag.Int64Var(&sessionResumptionSecs, "session-resumption-window", 0, "seconds after its last request that a client re-initializing with its previous Mcp-Session-Id resumes that session and its backend sessions rather than getting a new one. Default 0 (disabled).")
 	flag.Int64Var(&brokerWriteTimeoutSecs, "mcp-broker-write-timeout", 0, "HTTP write timeout in seconds for the broker. Default 0 (disabled) for SSE notification support. Set > 0 to enable timeout.")
 	flag.Int64Var(&managerTickerIntervalSecs, "mcp-check-interval", 60, "interval in seconds for MCP manager backend health checks. Default 60 seconds.")
+	flag.Int64Var(&startupGraceSecs, "startup-grace", 0, "seconds to defer client tools/list responses after start until at least one upstream MCP server has synced. Default 0 (disabled).")
+	flag.IntVar(&connectRetryAttempts, "mcp-connect-retry-attempts", 1, "number of attempts to connect to an upstream MCP server on each check before it is reported failed. Default 1 (no retries).")
+	flag.Int64Var(&connectRetryBaseDelayMs, "mcp-connect-retry-base-delay", 500, "delay in milliseconds before the first connect retry. The delay doubles after each retry. Default 500.")
+	flag.Int64Var(&connectRetryMaxDelayMs, "mcp-connect-retry-max-delay", 5000, "maximum delay in milliseconds between connect retries. Default 5000.")
+	flag.Int64Var(&unavailableGraceSecs, "unavailable-grace", 0, "seconds an upstream MCP server may be unreachable before its tools are removed, so brief network blips do not flap the tool list. Default 0 (remove on the first failed check).")
+	flag.StringVar(&acceptedProtocolVersions, "accepted-protocol-versions", strings.Join(mcp.ValidProtocolVersions, ","), "comma separated MCP protocol versions accepted from upstream MCP servers during initialize")
 	flag.BoolVar(&enforceToolFilteringFlag, "enforce-tool-filtering", false, "when enabled an x-authorized-tools header will be needed to return any tools")
+	flag.IntVar(&toolCallConcurrency, "tool-call-concurrency", 0, "maximum concurrent tool calls routed to each upstream MCP server. Waiting calls are shared fairly across sessions. Default 0 (unlimited).")
+	flag.StringVar(&instructions, "instructions", "", "instructions returned to clients on initialize to guide how the gateway's tools are used")
+	flag.IntVar(&maxTotalTools, "max-total-tools", 0, "maximum number of tools served across all upstream MCP servers. A server whose tools would exceed it is held back with reason CatalogFull. Default 0 (unlimited).")
+	flag.IntVar(&grpcMaxMessageSize, "grpc-max-message-size", 0, "largest ext_proc gRPC message in bytes the router accepts from envoy. Default 0 (the gRPC default of 4MiB).")
+	flag.IntVar(&toolsPageSize, "tools-page-size", 0, "maximum number of tools returned by each tools/list request. Clients fetch further pages with the returned cursor. Default 0 (all tools in one response).")
+	flag.IntVar(&toolCallRetries, "tool-call-retries", 0, "number of times a tool call is retried after a connection failure. Only tools marked idempotent in their tool override or annotated readOnlyHint or idempotentHint are retried. Default 0 (disabled).")
+	flag.Int64Var(&toolCallTimeoutSecs, "tool-call-timeout", 0, "default timeout in seconds for tool calls to upstream MCP servers. A tool advertising a kuadrant/timeout hint in its _meta uses the hint instead. Default 0 (the gateway route timeout applies).")
+	flag.Int64Var(&routeTimeoutSecs, "route-timeout", 0, "timeout in seconds of the gateway route tool calls are sent over. Tool call timeouts, including kuadrant/timeout hints advertised by upstream tools, are clamped to it so a call is never held open longer than the route allows. Default 0 (not clamped).")
+	flag.BoolVar(&serverAvailabilityMeta, "list-tools-server-availability", false, "when enabled tools/list responses include a kuadrant/unavailableServers _meta field naming upstream MCP servers that are not ready")
+	flag.BoolVar(&exposeUpstreamLatency, "expose-upstream-latency", false, "when enabled tool call responses include an x-mcp-upstream-latency-ms header with the time taken for the upstream MCP server to respond")
+	flag.BoolVar(&debugEndpoints, "debug-endpoints", false, "when enabled the broker serves GET /debug/servers listing the status of every upstream MCP server")
+	flag.StringVar(&authAPIKeysFlag,
+		"auth-api-keys",
+		goenv.GetDefault("BROKER_AUTH_API_KEYS", ""),
+		"comma separated API keys accepted on the public /mcp endpoint (env: BROKER_AUTH_API_KEYS). If neither API keys nor a JWT public key are set the broker does not authenticate requests",
+	)
+	flag.StringVar(&adminAPIKeysFlag,
+		"admin-api-keys",
+		goenv.GetDefault("BROKER_ADMIN_API_KEYS", ""),
+		"comma separated API keys accepted on the broker admin endpoints, read from the --auth-api-key-header header (env: BROKER_ADMIN_API_KEYS). The admin endpoints are not served unless set",
+	)
+	flag.StringVar(&authAPIKeyHeaderFlag, "auth-api-key-header", broker.DefaultAPIKeyHeader, "header the API key is read from on the public /mcp endpoint")
+	flag.StringVar(&authJWTPublicKeyFlag,
+		"auth-jwt-public-key",
+		goenv.GetDefault("BROKER_AUTH_JWT_PUBLIC_KEY", ""),
+		"PEM encoded ECDSA public key used to validate ES256 bearer tokens on the public /mcp endpoint (env: BROKER_AUTH_JWT_PUBLIC_KEY)",
+	)
+	flag.StringVar(&tlsCertFile, "tls-cert-file", "", "PEM encoded certificate served on the public broker address. When set with --tls-key-file the public listener terminates TLS")
+	flag.StringVar(&tlsKeyFile, "tls-key-file", "", "PEM encoded private key for --tls-cert-file")
+	flag.StringVar(&tlsMinVersion, "tls-min-version", "1.2", "minimum TLS version accepted on the public broker address, 1.2 or 1.3")
+	flag.StringVar(&tlsCipherSuites, "tls-cipher-suites", "", "comma separated IANA names of the cipher suites accepted for TLS 1.2 on the public broker address. Default empty (Go defaults)")
 	flag.Parse()
 
 	loggerOpts := &slog.HandlerOptions{}
@@ -186,11 +266,60 @@ func main() {
 	if err != nil {
 		panic("failed to setup jwt manager " + err.Error())
 	}
+	if sessionResumptionSecs > 0 {
+		logger.Info("session resumption enabled", "window", time.Duration(sessionResumptionSecs)*time.Second)
+		jwtmgr.EnableResumption(sessionCache, time.Duration(sessionResumptionSecs)*time.Second)
+	}
 	jwtSessionMgr = jwtmgr
 
 	managerTickerInterval := time.Duration(managerTickerIntervalSecs) * time.Second
-	brokerServer, mcpBroker, mcpServer := setUpBroker(mcpBrokerAddrFlag, enforceToolFilteringFlag, jwtSessionMgr, brokerWriteTimeoutSecs, managerTickerInterval)
+	startupGrace := time.Duration(startupGraceSecs) * time.Second
+	protocolVersions := splitList(acceptedProtocolVersions)
+	for _, version := range protocolVersions {
+		if !slices.Contains(mcp.ValidProtocolVersions, version) {
+			panic(fmt.Sprintf("flag accepted-protocol-versions contains unsupported version %q. Supported versions are %v", version, mcp.ValidProtocolVersions))
+		}
+	}
+	authMiddleware := &broker.AuthMiddleware{
+		Logger:       logger.With("component", "auth"),
+		APIKeyHeader: authAPIKeyHeaderFlag,
+		APIKeys:      splitList(authAPIKeysFlag),
+		JWTPublicKey: authJWTPublicKeyFlag,
+	}
+	if authMiddleware.Enabled() {
+		logger.Info("broker authentication enabled on /mcp", "api keys", len(authMiddleware.APIKeys), "jwt", authMiddleware.JWTPublicKey != "")
+	}
+	// admin endpoints expose other clients' sessions so they only accept their own keys, never a client's credentials
+	adminAuthMiddleware := &broker.AuthMiddleware{
+		Logger:       logger.With("component", "admin-auth"),
+		APIKeyHeader: authAPIKeyHeaderFlag,
+		APIKeys:      splitList(adminAPIKeysFlag),
+	}
+	brokerServer, mcpBroker, mcpServer := setUpBroker(mcpBrokerAddrFlag, enforceToolFilteringFlag, jwtSessionMgr, brokerWriteTimeoutSecs, managerTickerInterval, startupGrace, protocolVersions, authMiddleware, adminAuthMiddleware)
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		panic("--tls-cert-file and --tls-key-file must be set together")
+	}
+	if tlsCertFile != "" {
+		tlsConfig, err := broker.NewTLSConfig(tlsMinVersion, splitList(tlsCipherSuites))
+		if err != nil {
+			panic("invalid broker tls configuration: " + err.Error())
+		}
+		brokerServer.TLSConfig = tlsConfig
+		logger.Info("broker public listener serving TLS", "min version", tlsMinVersion)
+	}
 	routerGRPCServer, router := setUpRouter(mcpBroker, logger, jwtSessionMgr, sessionCache)
+	if stateHandoffFile != "" && cacheConnectionStringFlag != "" {
+		logger.Warn("state handoff file ignored as sessions are shared through the external session cache")
+		stateHandoffFile = ""
+	}
+	if stateHandoffFile != "" {
+		restored, err := broker.RestoreState(stateHandoffFile, jwtSigningKeyFlag, mcpBroker, sessionCache)
+		if err != nil {
+			logger.Error("failed to restore broker state", "file", stateHandoffFile, "error", err)
+		} else if restored {
+			logger.Info("restored broker state from previous broker", "file", stateHandoffFile)
+		}
+	}
 	mcpConfig.RegisterObserver(router)
 	mcpConfig.RegisterObserver(mcpBroker)
 	if mcpRoutePublicHost == "" {
@@ -219,7 +348,7 @@ func main() {
 		mcpConfig.Notify(ctx)
 	})
 	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 	grpcAddr := mcpRouterAddrFlag
 	lc := net.ListenConfig{}
@@ -233,9 +362,27 @@ func main() {
 		log.Fatal(routerGRPCServer.Serve(lis))
 	}()
 
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsServer := &http.Server{
+		Addr:              metricsAddrFlag,
+		Handler:           metricsMux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logger.Info("[http] starting metrics (internal)", "listening", metricsServer.Addr)
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("[http] Cannot start metrics server: %v", err)
+		}
+	}()
+
 	go func() {
 		logger.Info("[http] starting MCP Broker (public)", "listening", brokerServer.Addr)
-		if err := brokerServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		serve := brokerServer.ListenAndServe
+		if tlsCertFile != "" {
+			serve = func() error { return brokerServer.ListenAndServeTLS(tlsCertFile, tlsKeyFile) }
+		}
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("[http] Cannot start public broker: %v", err)
 		}
 	}()
@@ -254,14 +401,25 @@ func main() {
 	if err := brokerServer.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("HTTP shutdown error: %v", err)
 	}
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("metrics server shutdown error", "error", err)
+	}
 	if err := mcpServer.Shutdown(shutdownCtx); err != nil {
 		log.Printf("MCP shutdown error: %v; ignoring", err)
 	}
 
+	if stateHandoffFile != "" {
+		if err := broker.SaveState(stateHandoffFile, jwtSigningKeyFlag, mcpBroker, sessionCache); err != nil {
+			logger.Error("failed to save broker state", "file", stateHandoffFile, "error", err)
+		} else {
+			logger.Info("saved broker state for the next broker", "file", stateHandoffFile)
+		}
+	}
+
 	routerGRPCServer.GracefulStop()
 }
 
-func setUpBroker(address string, toolFiltering bool, sessionManager *session.JWTManager, writeTimeoutSecs int64, managerTickerInterval time.Duration) (*http.Server, broker.MCPBroker, *server.StreamableHTTPServer) {
+func setUpBroker(address string, toolFiltering bool, sessionManager *session.JWTManager, writeTimeoutSecs int64, managerTickerInterval time.Duration, startupGrace time.Duration, acceptedProtocolVersions []string, authMiddleware *broker.AuthMiddleware, adminAuthMiddleware *broker.AuthMiddleware) (*http.Server, broker.MCPBroker, *server.StreamableHTTPServer) {
 
 	mux := http.NewServeMux()
 
@@ -292,6 +450,18 @@ func setUpBroker(address string, toolFiltering bool, sessionManager *session.JWT
 		broker.WithEnforceToolFilter(toolFiltering),
 		broker.WithTrustedHeadersPublicKey(os.Getenv("TRUSTED_HEADER_PUBLIC_KEY")),
 		broker.WithManagerTickerInterval(managerTickerInterval),
+		broker.WithStartupGrace(startupGrace),
+		broker.WithAcceptedProtocolVersions(acceptedProtocolVersions),
+		broker.WithServerAvailabilityMeta(serverAvailabilityMeta),
+		broker.WithMaxTotalTools(maxTotalTools),
+		broker.WithToolsPageSize(toolsPageSize),
+		broker.WithInstructions(instructions),
+		broker.WithUnavailableGrace(time.Duration(unavailableGraceSecs)*time.Second),
+		broker.WithConnectRetry(upstream.ConnectRetry{
+			Attempts:  connectRetryAttempts,
+			BaseDelay: time.Duration(connectRetryBaseDelayMs) * time.Millisecond,
+			MaxDelay:  time.Duration(connectRetryMaxDelayMs) * time.Millisecond,
+		}),
 	)
 
 	var streamableHTTPServer = server.NewStreamableHTTPServer(
@@ -303,7 +473,7 @@ func setUpBroker(address string, toolFiltering bool, sessionManager *session.JWT
 		streamableHTTPServer = server.NewStreamableHTTPServer(
 			mcpBroker.MCPServer(),
 			server.WithStreamableHTTPServer(httpSrv),
-			server.WithSessionIdManager(sessionManager),
+			server.WithSessionIdManagerResolver(sessionManager),
 		)
 	}
 
@@ -313,25 +483,56 @@ func setUpBroker(address string, toolFiltering bool, sessionManager *session.JWT
 		w.WriteHeader(http.StatusOK)
 	})
 
+	mux.HandleFunc(buildinfo.Path, buildinfo.Handler(buildinfo.Info{Version: version, GitSHA: gitSHA + dirty}))
 	mux.HandleFunc("/status", mcpBroker.HandleStatusRequest)
 	mux.HandleFunc("/status/", mcpBroker.HandleStatusRequest)
-	mux.Handle("/mcp", streamableHTTPServer)
+	mux.HandleFunc("GET /readyz", mcpBroker.HandleReadyRequest)
+	mux.HandleFunc("GET /readyz/upstreams", mcpBroker.HandleUpstreamReadinessRequest)
+	mux.Handle("/mcp", authMiddleware.Wrap(streamableHTTPServer))
+	if adminAuthMiddleware.Enabled() {
+		// the admin view of a session's tools is only exposed when the broker can authenticate admins
+		mux.Handle("GET /admin/sessions/{id}/tools", adminAuthMiddleware.Wrap(http.HandlerFunc(mcpBroker.HandleSessionToolsRequest)))
+	}
+	if debugEndpoints {
+		mux.Handle("GET /debug/servers", authMiddleware.Wrap(http.HandlerFunc(mcpBroker.HandleDebugServersRequest)))
+	}
 
 	return httpSrv, mcpBroker, streamableHTTPServer
 }
 
+// splitList splits a comma separated list, ignoring empty entries
+func splitList(list string) []string {
+	var out []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func setUpRouter(broker broker.MCPBroker, logger *slog.Logger, jwtManager *session.JWTManager, sessionCache *session.Cache) (*grpc.Server, *mcpRouter.ExtProcServer) {
 
-	grpcSrv := grpc.NewServer()
+	var grpcOptions []grpc.ServerOption
+	if grpcMaxMessageSize > 0 {
+		grpcOptions = append(grpcOptions, grpc.MaxRecvMsgSize(grpcMaxMessageSize))
+	}
+	grpcSrv := grpc.NewServer(grpcOptions...)
 	// Create the ExtProcServer instance
 	server := &mcpRouter.ExtProcServer{
-		RoutingConfig: mcpConfig,
-		Logger:        logger.With("component", "router"),
-		JWTManager:    jwtManager,
-		InitForClient: clients.Initialize,
-		SessionCache:  sessionCache,
-		Broker:        broker, // TODO we shouldn't need a handle to broker in the router
-
+		RoutingConfig:         mcpConfig,
+		Logger:                logger.With("component", "router"),
+		JWTManager:            jwtManager,
+		InitForClient:         clients.Initialize,
+		SessionCache:          sessionCache,
+		Broker:                broker, // TODO we shouldn't need a handle to broker in the router
+		ExposeUpstreamLatency: exposeUpstreamLatency,
+		ToolCallRetries:       toolCallRetries,
+		ToolCallTimeout:       time.Duration(toolCallTimeoutSecs) * time.Second,
+		RouteTimeout:          time.Duration(routeTimeoutSecs) * time.Second,
+	}
+	if toolCallConcurrency > 0 {
+		server.ToolCallScheduler = mcpRouter.NewFairScheduler(toolCallConcurrency)
 	}
 
 	extProcV3.RegisterExternalProcessorServer(grpcSrv, server)
@@ -0,0 +1,98 @@
+// main implements a CLI that prints the MCP servers the controller wrote to the config secret of a namespace.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+)
+
+// redacted replaces credentials, client keys and header values in the printed config
+const redacted = "REDACTED"
+
+// dumpOptions select the config secret and how its servers are printed
+type dumpOptions struct {
+	namespace   string
+	output      string
+	onlyEnabled bool
+}
+
+func main() {
+	opts := dumpOptions{}
+	flag.StringVar(&opts.namespace, "namespace", config.DefaultNamespaceName.Namespace, "namespace of the mcp-gateway-config secret")
+	flag.StringVar(&opts.output, "output", "yaml", "output format, yaml or json")
+	flag.BoolVar(&opts.onlyEnabled, "only-enabled", false, "only print enabled servers")
+	flag.Parse()
+
+	k8sClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create client: %v\n", err)
+		os.Exit(1)
+	}
+	if err := dumpConfig(context.Background(), k8sClient, os.Stdout, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// dumpConfig writes the servers in the config secret of the namespace to out. Credentials, client keys and header
+// values are redacted
+func dumpConfig(ctx context.Context, reader client.Reader, out io.Writer, opts dumpOptions) error {
+	brokerConfig, err := config.ReadBrokerConfig(ctx, reader, config.NamespaceName(opts.namespace))
+	if err != nil {
+		return err
+	}
+	servers := []config.MCPServer{}
+	for _, server := range brokerConfig.Servers {
+		if opts.onlyEnabled && !server.Enabled {
+			continue
+		}
+		servers = append(servers, redact(server))
+	}
+
+	var data []byte
+	switch opts.output {
+	case "yaml":
+		data, err = yaml.Marshal(servers)
+	case "json":
+		data, err = json.MarshalIndent(servers, "", "  ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("unsupported output %q, use yaml or json", opts.output)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal servers: %w", err)
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// redact returns the server without its credential, TLS client key or header values. Headers are copied from the
+// filters of the server's HTTPRoute and may carry tokens
+func redact(server config.MCPServer) config.MCPServer {
+	if server.Credential != "" {
+		server.Credential = redacted
+	}
+	if len(server.Headers) > 0 {
+		headers := make(map[string]string, len(server.Headers))
+		for name := range server.Headers {
+			headers[name] = redacted
+		}
+		server.Headers = headers
+	}
+	if server.TLS != nil && server.TLS.ClientKey != "" {
+		tlsConfig := *server.TLS
+		tlsConfig.ClientKey = redacted
+		server.TLS = &tlsConfig
+	}
+	return server
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+)
+
+func TestDumpConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	brokerConfig := config.BrokerConfig{Servers: []config.MCPServer{
+		{Name: "team-a/weather", URL: "http://weather.team-a.svc.cluster.local:9090/mcp", ToolPrefix: "weather_", Enabled: true, Credential: "Bearer secret",
+			Headers: map[string]string{"x-api-key": "header-secret"}},
+		{Name: "team-a/search", URL: "https://search.example.com/mcp", Enabled: false, TLS: &config.TLSConfig{ClientCert: "cert", ClientKey: "key"}},
+	}}
+	configYAML, err := yaml.Marshal(brokerConfig)
+	require.NoError(t, err)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mcp-gateway-config", Namespace: "team-a"},
+		Data:       map[string][]byte{"config.yaml": configYAML},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	t.Run("yaml", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, dumpConfig(context.Background(), k8sClient, out, dumpOptions{namespace: "team-a", output: "yaml"}))
+		servers := []config.MCPServer{}
+		require.NoError(t, yaml.Unmarshal(out.Bytes(), &servers))
+		require.Len(t, servers, 2)
+		require.Equal(t, "team-a/weather", servers[0].Name)
+		require.Equal(t, "weather_", servers[0].ToolPrefix)
+		require.Equal(t, redacted, servers[0].Credential)
+		require.Equal(t, "cert", servers[1].TLS.ClientCert)
+		require.Equal(t, redacted, servers[1].TLS.ClientKey)
+		require.Equal(t, map[string]string{"x-api-key": redacted}, servers[0].Headers)
+		require.NotContains(t, out.String(), "Bearer secret")
+		require.NotContains(t, out.String(), "header-secret")
+	})
+
+	t.Run("json only enabled", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, dumpConfig(context.Background(), k8sClient, out, dumpOptions{namespace: "team-a", output: "json", onlyEnabled: true}))
+		servers := []config.MCPServer{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &servers))
+		require.Len(t, servers, 1)
+		require.Equal(t, "team-a/weather", servers[0].Name)
+	})
+
+	t.Run("unsupported output", func(t *testing.T) {
+		err := dumpConfig(context.Background(), k8sClient, &bytes.Buffer{}, dumpOptions{namespace: "team-a", output: "table"})
+		require.ErrorContains(t, err, `unsupported output "table"`)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		err := dumpConfig(context.Background(), k8sClient, &bytes.Buffer{}, dumpOptions{namespace: "team-b", output: "yaml"})
+		require.True(t, apierrors.IsNotFound(err))
+	})
+}
@@ -0,0 +1,75 @@
+package broker
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// DefaultAPIKeyHeader is the header checked for an API key when no header is configured
+const DefaultAPIKeyHeader = "x-api-key"
+
+// AuthMiddleware rejects requests to the broker's public endpoint that do not carry a configured API key or a
+// bearer JWT signed by the configured key. It is a defense in depth check and does not replace gateway auth
+type AuthMiddleware struct {
+	Logger *slog.Logger
+	// APIKeyHeader is the header an API key is read from. Defaults to DefaultAPIKeyHeader
+	APIKeyHeader string
+	// APIKeys are the accepted API keys
+	APIKeys []string
+	// JWTPublicKey is a PEM encoded ECDSA public key used to validate ES256 bearer tokens in the Authorization header
+	JWTPublicKey string
+}
+
+// Enabled returns true when at least one API key or a JWT public key is configured
+func (a *AuthMiddleware) Enabled() bool {
+	return a != nil && (len(a.APIKeys) > 0 || a.JWTPublicKey != "")
+}
+
+// Wrap returns a handler that authenticates requests before passing them to next. If the middleware is not enabled next is returned unchanged
+func (a *AuthMiddleware) Wrap(next http.Handler) http.Handler {
+	if !a.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.authenticated(r) {
+			a.Logger.Debug("rejecting unauthenticated request", "path", r.URL.Path, "remote", r.RemoteAddr)
+			if a.JWTPublicKey != "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *AuthMiddleware) authenticated(r *http.Request) bool {
+	if key := r.Header.Get(a.apiKeyHeader()); key != "" {
+		for _, valid := range a.APIKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(valid)) == 1 {
+				return true
+			}
+		}
+	}
+	if a.JWTPublicKey == "" {
+		return false
+	}
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return false
+	}
+	if _, err := validateJWTHeader(token, a.JWTPublicKey); err != nil {
+		a.Logger.Debug("invalid bearer token", "error", err)
+		return false
+	}
+	return true
+}
+
+func (a *AuthMiddleware) apiKeyHeader() string {
+	if a.APIKeyHeader == "" {
+		return DefaultAPIKeyHeader
+	}
+	return a.APIKeyHeader
+}
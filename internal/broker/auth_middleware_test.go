@@ -0,0 +1,91 @@
+package broker
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name           string
+		middleware     *AuthMiddleware
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "disabled middleware allows unauthenticated requests",
+			middleware:     &AuthMiddleware{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "nil middleware allows unauthenticated requests",
+			middleware:     nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing api key is rejected",
+			middleware:     &AuthMiddleware{APIKeys: []string{"secret"}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong api key is rejected",
+			middleware:     &AuthMiddleware{APIKeys: []string{"secret"}},
+			headers:        map[string]string{DefaultAPIKeyHeader: "guess"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "valid api key is accepted",
+			middleware:     &AuthMiddleware{APIKeys: []string{"other", "secret"}},
+			headers:        map[string]string{DefaultAPIKeyHeader: "secret"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "api key in custom header is accepted",
+			middleware:     &AuthMiddleware{APIKeyHeader: "x-broker-key", APIKeys: []string{"secret"}},
+			headers:        map[string]string{"x-broker-key": "secret"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing bearer token is rejected",
+			middleware:     &AuthMiddleware{JWTPublicKey: testPublicKey},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "invalid bearer token is rejected",
+			middleware:     &AuthMiddleware{JWTPublicKey: testPublicKey},
+			headers:        map[string]string{"Authorization": "Bearer not-a-jwt"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "valid bearer token is accepted",
+			middleware:     &AuthMiddleware{JWTPublicKey: testPublicKey},
+			headers:        map[string]string{"Authorization": "Bearer " + createTestJWT(t, map[string][]string{})},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.middleware != nil {
+				tc.middleware.Logger = slog.Default()
+			}
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			tc.middleware.Wrap(next).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
@@ -24,6 +25,15 @@ type MCPBroker interface {
 	// Returns tool annotations for a given tool name
 	ToolAnnotations(serverID config.UpstreamMCPID, tool string) (mcp.ToolAnnotation, bool)
 
+	// ToolTimeout returns the timeout for calls to a tool, using the duration hint advertised by the tool when present otherwise fallback
+	ToolTimeout(serverID config.UpstreamMCPID, tool string, fallback time.Duration) time.Duration
+
+	// UpstreamUnavailable checks if the upstream MCP server is currently unreachable
+	UpstreamUnavailable(serverID config.UpstreamMCPID) bool
+
+	// ToolCallStarted records a tool call routed to the upstream MCP server. The returned func must be called once the call completes
+	ToolCallStarted(serverID config.UpstreamMCPID) func()
+
 	// Returns server info for a given tool name
 	GetServerInfo(tool string) (*config.MCPServer, error)
 
@@ -39,9 +49,30 @@ type MCPBroker interface {
 	// ValidateAllServers performs comprehensive validation of all registered servers and returns status
 	ValidateAllServers() StatusResponse
 
+	// ValidateServer returns the status of the registered server with the id, and false if there is none
+	ValidateServer(id config.UpstreamMCPID) (upstream.ServerValidationStatus, bool)
+
 	// HandleStatusRequest handles HTTP status endpoint requests
 	HandleStatusRequest(w http.ResponseWriter, r *http.Request)
 
+	// HandleSessionToolsRequest handles admin requests for the effective tool list of a client session
+	HandleSessionToolsRequest(w http.ResponseWriter, r *http.Request)
+
+	// HandleDebugServersRequest handles debug requests for the status of every upstream MCP server
+	HandleDebugServersRequest(w http.ResponseWriter, r *http.Request)
+
+	// HandleReadyRequest handles readiness probe requests, reflecting whether the config has been loaded
+	HandleReadyRequest(w http.ResponseWriter, r *http.Request)
+
+	// HandleUpstreamReadinessRequest handles requests for the readiness of the upstream MCP servers
+	HandleUpstreamReadinessRequest(w http.ResponseWriter, r *http.Request)
+
+	// SessionFilters returns the filter headers of every client session that has listed tools
+	SessionFilters() map[string]http.Header
+
+	// RestoreSessionFilters records the filter headers of client sessions that listed tools with another broker
+	RestoreSessionFilters(filters map[string]http.Header)
+
 	// Shutdown closes any resources associated with this Broker
 	Shutdown(ctx context.Context) error
 
@@ -71,6 +102,44 @@ type mcpBrokerImpl struct {
 
 	// managerTickerInterval is the interval for MCP manager backend health checks
 	managerTickerInterval time.Duration
+
+	// acceptedProtocolVersions limits the protocol versions accepted from upstream MCP servers. Empty accepts any version the client supports
+	acceptedProtocolVersions []string
+
+	// instructions are returned to clients on initialize to guide how the gateway's tools are used
+	instructions string
+
+	// unavailableGrace is how long an upstream may be unreachable before its tools are removed
+	unavailableGrace time.Duration
+
+	// connectRetry controls how failed connection attempts to an upstream are retried
+	connectRetry upstream.ConnectRetry
+
+	// catalogLimit if set caps the total number of tools served across all upstream MCP servers
+	catalogLimit *upstream.CatalogLimit
+
+	// serverAvailabilityMeta if set adds the upstream servers that are not ready to the tools/list result meta
+	serverAvailabilityMeta bool
+
+	// startupGrace is how long client tools/list requests are deferred after start waiting for the first upstream sync
+	startupGrace time.Duration
+	// startupDeadline is the point after which tools/list requests are no longer deferred
+	startupDeadline time.Time
+	// synced is closed once an upstream has synced or there are no upstreams to sync
+	synced     chan struct{}
+	syncedOnce sync.Once
+
+	// sessionFilters holds the filter headers each client session last listed tools with keyed by session id
+	sessionFilters sync.Map
+
+	// toolCalls tracks the tool calls in flight to each upstream so a server being removed can drain
+	toolCalls toolCallTracker
+
+	// configLoaded is set once the first config has been applied
+	configLoaded atomic.Bool
+
+	// toolsPageSize if set limits the number of tools returned by each tools/list request
+	toolsPageSize int
 }
 
 // this ensures that mcpBrokerImpl implements the MCPBroker interface
@@ -97,6 +166,60 @@ func WithManagerTickerInterval(interval time.Duration) func(mb *mcpBrokerImpl) {
 	}
 }
 
+// WithAcceptedProtocolVersions limits the protocol versions the broker accepts from upstream MCP servers during initialize
+func WithAcceptedProtocolVersions(versions []string) func(mb *mcpBrokerImpl) {
+	return func(mb *mcpBrokerImpl) {
+		mb.acceptedProtocolVersions = versions
+	}
+}
+
+// WithInstructions sets the instructions returned to clients on initialize
+func WithInstructions(instructions string) func(mb *mcpBrokerImpl) {
+	return func(mb *mcpBrokerImpl) {
+		mb.instructions = instructions
+	}
+}
+
+// WithUnavailableGrace keeps the tools of an unreachable upstream until it has been unreachable for longer than grace.
+// A grace of 0 removes tools on the first failed check
+func WithUnavailableGrace(grace time.Duration) func(mb *mcpBrokerImpl) {
+	return func(mb *mcpBrokerImpl) {
+		mb.unavailableGrace = grace
+	}
+}
+
+// WithConnectRetry retries failed connection attempts to upstream MCP servers with exponential backoff before they are
+// reported failed
+func WithConnectRetry(retry upstream.ConnectRetry) func(mb *mcpBrokerImpl) {
+	return func(mb *mcpBrokerImpl) {
+		mb.connectRetry = retry
+	}
+}
+
+// WithMaxTotalTools caps the total number of tools served across all upstream MCP servers. A max of 0 disables the cap
+func WithMaxTotalTools(maxTools int) func(mb *mcpBrokerImpl) {
+	return func(mb *mcpBrokerImpl) {
+		if maxTools > 0 {
+			mb.catalogLimit = upstream.NewCatalogLimit(maxTools)
+		}
+	}
+}
+
+// WithServerAvailabilityMeta adds the upstream servers that are not ready to the tools/list result meta. It is opt in as strict clients may reject unknown meta fields
+func WithServerAvailabilityMeta(enabled bool) func(mb *mcpBrokerImpl) {
+	return func(mb *mcpBrokerImpl) {
+		mb.serverAvailabilityMeta = enabled
+	}
+}
+
+// WithStartupGrace defers client tools/list requests for up to grace after start until at least one upstream
+// has synced, so clients don't cache an empty catalog on a cold start. A grace of 0 disables this
+func WithStartupGrace(grace time.Duration) func(mb *mcpBrokerImpl) {
+	return func(mb *mcpBrokerImpl) {
+		mb.startupGrace = grace
+	}
+}
+
 // NewBroker creates a new MCPBroker accepts optional config functions such as WithEnforceToolFilter
 func NewBroker(logger *slog.Logger, opts ...func(*mcpBrokerImpl)) MCPBroker {
 	mcpBkr := &mcpBrokerImpl{
@@ -104,11 +227,13 @@ func NewBroker(logger *slog.Logger, opts ...func(*mcpBrokerImpl)) MCPBroker {
 		logger:                logger,
 		virtualServers:        map[string]*config.VirtualServer{},
 		managerTickerInterval: time.Second * 60,
+		synced:                make(chan struct{}),
 	}
 
 	for _, option := range opts {
 		option(mcpBkr)
 	}
+	mcpBkr.startupDeadline = time.Now().Add(mcpBkr.startupGrace)
 
 	hooks := &server.Hooks{}
 
@@ -121,6 +246,7 @@ func NewBroker(logger *slog.Logger, opts ...func(*mcpBrokerImpl)) MCPBroker {
 
 	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
 		slog.Info("Broker: Gateway client session unregister ", "gatewaySessionID", session.SessionID())
+		mcpBkr.sessionFilters.Delete(session.SessionID())
 	})
 
 	hooks.AddBeforeAny(func(_ context.Context, _ any, method mcp.MCPMethod, _ any) {
@@ -131,8 +257,21 @@ func NewBroker(logger *slog.Logger, opts ...func(*mcpBrokerImpl)) MCPBroker {
 		slog.Info("MCP server error", "method", method, "error", err)
 	})
 
+	hooks.AddBeforeListTools(func(ctx context.Context, _ any, _ *mcp.ListToolsRequest) {
+		mcpBkr.waitForStartupSync(ctx)
+	})
+
 	hooks.AddAfterListTools(func(ctx context.Context, id any, message *mcp.ListToolsRequest, result *mcp.ListToolsResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			mcpBkr.recordSessionFilters(session.SessionID(), message.Header)
+		}
 		mcpBkr.FilterTools(ctx, id, message, result)
+		if mcpBkr.toolsPageSize > 0 {
+			result.Tools, result.NextCursor = paginateTools(result.Tools, message.Params.Cursor, mcpBkr.toolsPageSize)
+		}
+		if mcpBkr.serverAvailabilityMeta {
+			mcpBkr.addServerAvailability(result)
+		}
 	})
 
 	mcpBkr.listeningMCPServer = server.NewMCPServer(
@@ -140,6 +279,7 @@ func NewBroker(logger *slog.Logger, opts ...func(*mcpBrokerImpl)) MCPBroker {
 		"0.0.1",
 		server.WithHooks(hooks),
 		server.WithToolCapabilities(true),
+		server.WithInstructions(mcpBkr.instructions),
 	)
 	return mcpBkr
 }
@@ -152,7 +292,7 @@ func (m *mcpBrokerImpl) OnConfigChange(ctx context.Context, conf *config.MCPServ
 
 	for serverID := range m.mcpServers {
 		if !slices.ContainsFunc(conf.Servers, func(s *config.MCPServer) bool {
-			return serverID == s.ID()
+			return serverID == s.ID() && s.Enabled
 		}) {
 			m.logger.Info("un-register upstream server", "server id", serverID)
 			if man, ok := m.mcpServers[serverID]; ok {
@@ -162,24 +302,45 @@ func (m *mcpBrokerImpl) OnConfigChange(ctx context.Context, conf *config.MCPServ
 			}
 		}
 	}
+	if !slices.ContainsFunc(conf.Servers, func(s *config.MCPServer) bool { return s.Enabled }) {
+		// nothing to wait for
+		m.markSynced()
+	}
 	// ensure new servers registered
 
 	for _, mcpServer := range conf.Servers {
+		if !mcpServer.Enabled {
+			// disabled servers keep their config entry but are not served
+			continue
+		}
 		man, ok := m.mcpServers[mcpServer.ID()]
 		if ok {
 			m.logger.Info("Server is registered", "mcpID", mcpServer.ID())
 			// already have a manger
-			if mcpServer.ConfigChanged(man.MCP.GetConfig()) {
+			existing := man.MCP.GetConfig()
+			if mcpServer.ConfigChanged(existing) {
 				// todo prob could look at just updating the config
 				m.logger.Info("Server Config Changed removing manager", "mcpID", mcpServer.ID())
 				man.Stop()
 				delete(m.mcpServers, mcpServer.ID())
+			} else {
+				if mcpServer.Credential != existing.Credential {
+					// a rotated credential reconnects without withdrawing the server's tools
+					m.logger.Info("Server credential changed, reconnecting", "mcpID", mcpServer.ID())
+					man.UpdateCredential(mcpServer.Credential)
+				}
+				man.SetConfigGeneration(mcpServer.Generation)
 			}
 		}
 		// check if we need to setup a new manager
 		if _, ok := m.mcpServers[mcpServer.ID()]; !ok {
 			m.logger.Info("starting new manager", "server id", mcpServer.ID())
 			manager := upstream.NewUpstreamMCPManager(upstream.NewUpstreamMCP(mcpServer), m.listeningMCPServer, m.logger.With("sub-component", "mcp-manager"), m.managerTickerInterval)
+			manager.OnSynced(m.markSynced)
+			manager.SetAcceptedProtocolVersions(m.acceptedProtocolVersions)
+			manager.SetCatalogLimit(m.catalogLimit)
+			manager.SetUnavailableGrace(m.unavailableGrace)
+			manager.SetConnectRetry(m.connectRetry)
 			m.mcpServers[mcpServer.ID()] = manager
 			go func() {
 				m.logger.Info("Starting manager for", "mcpID", mcpServer.ID())
@@ -187,15 +348,47 @@ func (m *mcpBrokerImpl) OnConfigChange(ctx context.Context, conf *config.MCPServ
 			}()
 		}
 	}
+	// draining servers keep their managers so calls in flight complete but their tools are no longer listed
+	m.toolCalls.setDraining(conf.Servers)
 	// register virtual servers
 	m.vsLock.Lock()
 	for _, vs := range conf.VirtualServers {
 		m.virtualServers[vs.Name] = vs
 	}
 	m.vsLock.Unlock()
+	m.configLoaded.Store(true)
 	m.logger.Debug("Broker OnConfigChange done", "Total managers for upstream mcp servers", len(m.mcpServers), "total servers", len(conf.Servers))
 }
 
+// markSynced records that the broker has completed its first sync
+func (m *mcpBrokerImpl) markSynced() {
+	m.syncedOnce.Do(func() {
+		close(m.synced)
+	})
+}
+
+// waitForStartupSync blocks until the first sync completes, the startup grace expires or the request is cancelled
+func (m *mcpBrokerImpl) waitForStartupSync(ctx context.Context) {
+	remaining := time.Until(m.startupDeadline)
+	if remaining <= 0 {
+		return
+	}
+	select {
+	case <-m.synced:
+		return
+	default:
+	}
+	m.logger.Debug("deferring tools/list until first upstream sync", "remaining grace", remaining)
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case <-m.synced:
+	case <-timer.C:
+		m.logger.Info("startup grace expired before any upstream synced, serving current tools")
+	case <-ctx.Done():
+	}
+}
+
 func (m *mcpBrokerImpl) RegisteredMCPServers() map[config.UpstreamMCPID]*upstream.MCPManager {
 	m.mcpLock.RLock()
 	defer m.mcpLock.RUnlock()
@@ -229,12 +422,43 @@ func (m *mcpBrokerImpl) ToolAnnotations(serverID config.UpstreamMCPID, tool stri
 	return mcp.ToolAnnotation{}, false
 }
 
+// ToolTimeout implements MCPBroker by deferring to the manager of the upstream serving the tool
+func (m *mcpBrokerImpl) ToolTimeout(serverID config.UpstreamMCPID, tool string, fallback time.Duration) time.Duration {
+	m.mcpLock.RLock()
+	defer m.mcpLock.RUnlock()
+
+	upstream, ok := m.mcpServers[serverID]
+	if !ok {
+		return fallback
+	}
+	return upstream.ToolTimeout(tool, fallback)
+}
+
+// UpstreamUnavailable implements MCPBroker by checking if the manager of the upstream reports it unreachable
+func (m *mcpBrokerImpl) UpstreamUnavailable(serverID config.UpstreamMCPID) bool {
+	m.mcpLock.RLock()
+	defer m.mcpLock.RUnlock()
+
+	upstream, ok := m.mcpServers[serverID]
+	return ok && upstream.Unavailable()
+}
+
 // GetServerInfo implements MCPBroker by providing a lookup of the server that implements a tool.
 func (m *mcpBrokerImpl) GetServerInfo(tool string) (*config.MCPServer, error) {
 	// Avoid race with OnConfigChange()
 	m.mcpLock.RLock()
 	defer m.mcpLock.RUnlock()
 
+	// prefer the server the gateway serves the tool from as a lower priority server may not yet know it was taken over
+	if gatewayTool := m.listeningMCPServer.GetTool(tool); gatewayTool != nil {
+		if serverID, ok := upstream.ToolServerID(gatewayTool.Tool); ok {
+			if owner, ok := m.mcpServers[serverID]; ok && owner.GetServedManagedTool(tool) != nil {
+				retval := owner.MCP.GetConfig()
+				return &retval, nil
+			}
+		}
+	}
+
 	for _, upstream := range m.mcpServers {
 		t := upstream.GetServedManagedTool(tool)
 		if t != nil {
@@ -294,8 +518,9 @@ func (m *mcpBrokerImpl) ValidateAllServers() StatusResponse {
 	m.logger.Debug("ValidateAllServers: checking servers", "# servers", len(m.mcpServers))
 
 	for _, upstream := range m.RegisteredMCPServers() {
-		status := upstream.GetStatus()
+		status := m.serverStatus(upstream)
 		response.Servers = append(response.Servers, status)
+		response.ToolConflicts += len(status.ConflictingTools) + len(status.ShadowedTools)
 
 		if !status.Ready {
 			response.UnHealthyServers++
@@ -313,3 +538,22 @@ func (m *mcpBrokerImpl) ValidateAllServers() StatusResponse {
 
 	return response
 }
+
+// ValidateServer returns the status of the registered server with the id, and false if there is none
+func (m *mcpBrokerImpl) ValidateServer(id config.UpstreamMCPID) (upstream.ServerValidationStatus, bool) {
+	m.mcpLock.RLock()
+	manager, ok := m.mcpServers[id]
+	m.mcpLock.RUnlock()
+	if !ok {
+		return upstream.ServerValidationStatus{}, false
+	}
+	return m.serverStatus(manager), true
+}
+
+// serverStatus returns the status of the upstream server including the tool calls still in flight to it
+func (m *mcpBrokerImpl) serverStatus(manager *upstream.MCPManager) upstream.ServerValidationStatus {
+	status := manager.GetStatus()
+	status.Draining = m.toolCalls.isDraining(manager.MCP.ID())
+	status.InFlightToolCalls = m.toolCalls.inFlightCalls(manager.MCP.ID())
+	return status
+}
@@ -68,6 +68,7 @@ func TestOnConfigChange(t *testing.T) {
 		Name:       "test1",
 		URL:        MCPAddr,
 		ToolPrefix: "_test1",
+		Enabled:    true,
 	}
 	virtualServer1 := &config.VirtualServer{
 		Name:  "test/test",
@@ -109,6 +110,78 @@ func TestOnConfigChange(t *testing.T) {
 	_ = b.Shutdown(context.Background())
 }
 
+func TestOnConfigChange_CredentialRotation(t *testing.T) {
+	b := NewBroker(logger)
+	server1 := &config.MCPServer{
+		Name:       "test1",
+		URL:        MCPAddr,
+		ToolPrefix: "_test1",
+		Enabled:    true,
+		Credential: "Bearer old",
+	}
+	b.OnConfigChange(context.TODO(), &config.MCPServersConfig{Servers: []*config.MCPServer{server1}})
+	manager := b.RegisteredMCPServers()[server1.ID()]
+	require.NotNil(t, manager)
+
+	rotated := *server1
+	rotated.Credential = "Bearer new"
+	b.OnConfigChange(context.TODO(), &config.MCPServersConfig{Servers: []*config.MCPServer{&rotated}})
+
+	// the running manager is kept and reconnects with the new credential
+	require.Same(t, manager, b.RegisteredMCPServers()[server1.ID()])
+	require.Equal(t, "Bearer new", manager.MCP.GetConfig().Credential)
+
+	_ = b.Shutdown(context.Background())
+}
+
+func TestOnConfigChange_Generation(t *testing.T) {
+	b := NewBroker(logger)
+	server1 := &config.MCPServer{
+		Name:       "test1",
+		URL:        MCPAddr,
+		ToolPrefix: "_test1",
+		Enabled:    true,
+		Generation: 1,
+	}
+	b.OnConfigChange(context.TODO(), &config.MCPServersConfig{Servers: []*config.MCPServer{server1}})
+	manager := b.RegisteredMCPServers()[server1.ID()]
+	require.NotNil(t, manager)
+	require.Equal(t, int64(1), manager.GetStatus().ConfigGeneration)
+
+	updated := *server1
+	updated.Generation = 2
+	b.OnConfigChange(context.TODO(), &config.MCPServersConfig{Servers: []*config.MCPServer{&updated}})
+
+	// a new generation that doesn't change the server keeps the manager and is reported in status
+	require.Same(t, manager, b.RegisteredMCPServers()[server1.ID()])
+	require.Equal(t, int64(2), manager.GetStatus().ConfigGeneration)
+
+	_ = b.Shutdown(context.Background())
+}
+
+func TestOnConfigChange_Disabled(t *testing.T) {
+	b := NewBroker(logger)
+	server1 := &config.MCPServer{
+		Name:       "test1",
+		URL:        MCPAddr,
+		ToolPrefix: "_test1",
+		Enabled:    true,
+	}
+	b.OnConfigChange(context.TODO(), &config.MCPServersConfig{Servers: []*config.MCPServer{server1}})
+	require.Contains(t, b.RegisteredMCPServers(), server1.ID())
+
+	// a disabled server keeps its config entry but is no longer served
+	disabled := *server1
+	disabled.Enabled = false
+	b.OnConfigChange(context.TODO(), &config.MCPServersConfig{Servers: []*config.MCPServer{&disabled}})
+	require.NotContains(t, b.RegisteredMCPServers(), server1.ID())
+
+	b.OnConfigChange(context.TODO(), &config.MCPServersConfig{Servers: []*config.MCPServer{server1}})
+	require.Contains(t, b.RegisteredMCPServers(), server1.ID())
+
+	_ = b.Shutdown(context.Background())
+}
+
 var _ http.ResponseWriter = &simpleResponseWriter{}
 
 type simpleResponseWriter struct {
@@ -290,3 +363,78 @@ func TestToolAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestStartupGraceDefersListTools(t *testing.T) {
+	listTools := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+
+	listToolsAsync := func(b MCPBroker) chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			_ = b.MCPServer().HandleMessage(context.Background(), listTools)
+			close(done)
+		}()
+		return done
+	}
+
+	t.Run("deferred until first sync", func(t *testing.T) {
+		b := NewBroker(logger, WithStartupGrace(time.Minute))
+		done := listToolsAsync(b)
+		select {
+		case <-done:
+			t.Fatalf("expected tools/list to be deferred before first sync")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		b.(*mcpBrokerImpl).markSynced()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("expected tools/list to complete after first sync")
+		}
+	})
+
+	t.Run("served when grace expires", func(t *testing.T) {
+		b := NewBroker(logger, WithStartupGrace(200*time.Millisecond))
+		select {
+		case <-listToolsAsync(b):
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected tools/list to complete after grace expired")
+		}
+	})
+
+	t.Run("not deferred when there are no upstreams", func(t *testing.T) {
+		b := NewBroker(logger, WithStartupGrace(time.Minute))
+		b.OnConfigChange(context.Background(), &config.MCPServersConfig{})
+		select {
+		case <-listToolsAsync(b):
+		case <-time.After(time.Second):
+			t.Fatalf("expected tools/list to complete with no upstreams configured")
+		}
+	})
+
+	t.Run("not deferred when disabled", func(t *testing.T) {
+		b := NewBroker(logger)
+		select {
+		case <-listToolsAsync(b):
+		case <-time.After(time.Second):
+			t.Fatalf("expected tools/list to complete with no startup grace")
+		}
+	})
+}
+
+func TestInitializeInstructions(t *testing.T) {
+	initialize := func(b MCPBroker) mcp.InitializeResult {
+		t.Helper()
+		message := b.MCPServer().HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+		response, ok := message.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected a response, got %#v", message)
+		result, ok := response.Result.(mcp.InitializeResult)
+		require.True(t, ok, "expected an initialize result, got %#v", response.Result)
+		return result
+	}
+
+	require.Empty(t, initialize(NewBroker(logger)).Instructions)
+
+	instructions := "Prefer the weather_ tools for forecasts. Calendar tools require user confirmation."
+	require.Equal(t, instructions, initialize(NewBroker(logger, WithInstructions(instructions))).Instructions)
+}
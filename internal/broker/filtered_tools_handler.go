@@ -11,6 +11,7 @@ import (
 	"slices"
 
 	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
 	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -24,19 +25,31 @@ const allowedToolsClaimKey = "allowed-tools"
 // Priority: x-authorized-tools JWT filtering, then x-mcp-virtualserver filtering.
 func (broker *mcpBrokerImpl) FilterTools(_ context.Context, _ any, mcpReq *mcp.ListToolsRequest, mcpRes *mcp.ListToolsResult) {
 	broker.logger.Info("FilterTools called", "input_tools_count", len(mcpRes.Tools))
-	tools := mcpRes.Tools
+	mcpRes.Tools, _ = broker.filterTools(mcpReq.Header, mcpRes.Tools)
+}
+
+// filterTools applies the header based filters to tools and returns the filtered tools along with the filters that applied
+func (broker *mcpBrokerImpl) filterTools(headers http.Header, tools []mcp.Tool) ([]mcp.Tool, []AppliedFilter) {
 	emptyTools := []mcp.Tool{}
-	if len(mcpRes.Tools) == 0 {
-		mcpRes.Tools = emptyTools
-		return
+	if len(tools) == 0 {
+		return emptyTools, nil
 	}
+	var applied []AppliedFilter
 
 	// step 1: apply x-authorized-tools filtering (JWT-based)
-	tools = broker.applyAuthorizedToolsFilter(mcpReq.Header, tools)
+	tools, filter := broker.applyAuthorizedToolsFilter(headers, tools)
+	if filter != nil {
+		applied = append(applied, *filter)
+	}
 	broker.logger.Debug("FilterTools authorized tools result", "output_tools_count", len(tools))
 
 	// step 2: apply virtual server filtering
-	tools = broker.applyVirtualServerFilter(mcpReq.Header, tools)
+	tools, filter = broker.applyVirtualServerFilter(headers, tools)
+	if filter != nil {
+		applied = append(applied, *filter)
+	}
+	// step 3: remove the tools of servers draining before removal. This needs the gateway meta so comes first
+	tools = broker.removeDrainingTools(tools)
 	// filter out any gateway specific meta data we are storing internally before sending to clients
 	tools = broker.removeGatewayMeta(tools)
 	broker.logger.Debug("FilterTools virtual server result", "output_tools_count", len(tools))
@@ -45,7 +58,7 @@ func (broker *mcpBrokerImpl) FilterTools(_ context.Context, _ any, mcpReq *mcp.L
 	if tools == nil {
 		tools = emptyTools
 	}
-	mcpRes.Tools = tools
+	return tools, applied
 }
 
 func (broker *mcpBrokerImpl) removeGatewayMeta(tools []mcp.Tool) []mcp.Tool {
@@ -61,24 +74,26 @@ func (broker *mcpBrokerImpl) removeGatewayMeta(tools []mcp.Tool) []mcp.Tool {
 // applyAuthorizedToolsFilter filters tools based on x-authorized-tools JWT header.
 // Returns original tools if header not present and enforcement is off.
 // Returns empty slice if header validation fails or enforcement is on without header.
-func (broker *mcpBrokerImpl) applyAuthorizedToolsFilter(headers http.Header, tools []mcp.Tool) []mcp.Tool {
+// The returned filter is nil when no filtering was applied.
+func (broker *mcpBrokerImpl) applyAuthorizedToolsFilter(headers http.Header, tools []mcp.Tool) ([]mcp.Tool, *AppliedFilter) {
 	headerValues, present := headers[authorizedToolsHeader]
 
 	if !present {
 		broker.logger.Debug("no x-authorized-tools header", "enforced", broker.enforceToolFilter)
 		if broker.enforceToolFilter {
-			return []mcp.Tool{}
+			return []mcp.Tool{}, &AppliedFilter{Name: authorizedToolsFilterName, Detail: "header missing and tool filtering is enforced"}
 		}
-		return tools
+		return tools, nil
 	}
 
 	allowedTools, err := broker.parseAuthorizedToolsJWT(headerValues)
 	if err != nil {
 		broker.logger.Error("failed to parse x-authorized-tools header", "error", err)
-		return []mcp.Tool{}
+		return []mcp.Tool{}, &AppliedFilter{Name: authorizedToolsFilterName, Detail: "header is invalid"}
 	}
 
-	return broker.filterToolsByServerMap(allowedTools)
+	// only the number of servers is reported as the claim contents are sensitive
+	return broker.filterToolsByServerMap(allowedTools), &AppliedFilter{Name: authorizedToolsFilterName, Detail: fmt.Sprintf("allows tools from %d servers", len(allowedTools))}
 }
 
 // parseAuthorizedToolsJWT validates and extracts allowed tools from the JWT header.
@@ -154,7 +169,7 @@ func (broker *mcpBrokerImpl) filterToolsByServerMap(allowedTools map[string][]st
 			broker.logger.Debug("checking access", "tool", tool.Name, "against", toolNames)
 			if slices.Contains(toolNames, tool.Name) {
 				broker.logger.Debug("access granted", "tool", tool.Name)
-				tool.Name = fmt.Sprintf("%s%s", upstream.MCP.GetPrefix(), tool.Name)
+				tool.Name = upstream.MCP.ServedToolName(tool.Name)
 				filtered = append(filtered, tool)
 			}
 		}
@@ -164,10 +179,11 @@ func (broker *mcpBrokerImpl) filterToolsByServerMap(allowedTools map[string][]st
 }
 
 // applyVirtualServerFilter filters tools to only those specified in the virtual server.
-func (broker *mcpBrokerImpl) applyVirtualServerFilter(headers http.Header, tools []mcp.Tool) []mcp.Tool {
+// The returned filter is nil when no filtering was applied.
+func (broker *mcpBrokerImpl) applyVirtualServerFilter(headers http.Header, tools []mcp.Tool) ([]mcp.Tool, *AppliedFilter) {
 	headerValues, ok := headers[virtualMCPHeader]
 	if !ok || len(headerValues) != 1 {
-		return tools
+		return tools, nil
 	}
 
 	virtualServerID := headerValues[0]
@@ -176,23 +192,47 @@ func (broker *mcpBrokerImpl) applyVirtualServerFilter(headers http.Header, tools
 	vs, err := broker.GetVirtualSeverByHeader(virtualServerID)
 	if err != nil {
 		broker.logger.Error("failed to get virtual server", "error", err)
-		return tools
-	}
-
-	// build a set of allowed tool names for O(1) lookup
-	filteredSet := make(map[string]struct{}, len(vs.Tools))
-	for _, name := range vs.Tools {
-		filteredSet[name] = struct{}{}
+		return tools, nil
 	}
 
+	// the virtual server's tools may be patterns such as weather_* so tools added upstream are included
 	var filtered []mcp.Tool
 	for _, tool := range tools {
-		if _, inFilter := filteredSet[tool.Name]; inFilter {
+		if vs.Allows(tool.Name) || broker.virtualServerScopeAllows(&vs, tool) {
 			filtered = append(filtered, tool)
 		}
 	}
 
-	return filtered
+	return filtered, &AppliedFilter{Name: virtualServerFilterName, Detail: virtualServerID}
+}
+
+// virtualServerScopeAllows checks if the virtual server selects the tool through the server it is served from
+func (broker *mcpBrokerImpl) virtualServerScopeAllows(vs *config.VirtualServer, tool mcp.Tool) bool {
+	if len(vs.Servers) == 0 {
+		return false
+	}
+	manager := broker.toolServer(tool)
+	if manager == nil {
+		return false
+	}
+	upstreamName, _ := manager.MCP.UpstreamToolName(tool.Name)
+	return vs.AllowsServerTool(manager.MCPName(), upstreamName)
+}
+
+// toolServer returns the manager of the upstream server the tool is served from. Tools filtered by x-authorized-tools
+// do not carry the gateway meta so they are looked up by their served name
+func (broker *mcpBrokerImpl) toolServer(tool mcp.Tool) *upstream.MCPManager {
+	broker.mcpLock.RLock()
+	defer broker.mcpLock.RUnlock()
+	if serverID, ok := upstream.ToolServerID(tool); ok {
+		return broker.mcpServers[serverID]
+	}
+	for _, manager := range broker.mcpServers {
+		if manager.GetServedManagedTool(tool.Name) != nil {
+			return manager
+		}
+	}
+	return nil
 }
 
 // validateJWTHeader validates the JWT header using ES256 algorithm.
@@ -7,6 +7,7 @@ import (
 	"encoding/pem"
 	"log/slog"
 	"net/http"
+	"slices"
 	"testing"
 
 	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
@@ -236,6 +237,38 @@ func TestVirtualServerFiltering(t *testing.T) {
 			VirtualServerID: "mcp-test/empty-vs",
 			ExpectedTools:   []string{},
 		},
+		{
+			Name: "filters tools by prefix pattern",
+			InputTools: &mcp.ListToolsResult{Tools: []mcp.Tool{
+				{Name: "server1_weather_today"},
+				{Name: "server1_weather_week"},
+				{Name: "server1_tool1"},
+				{Name: "server2_tool1"},
+			}},
+			VirtualServers: map[string]*config.VirtualServer{
+				"mcp-test/weather-vs": {
+					Name:  "mcp-test/weather-vs",
+					Tools: []string{"server1_weather_*", "server2_tool1"},
+				},
+			},
+			VirtualServerID: "mcp-test/weather-vs",
+			ExpectedTools:   []string{"server1_weather_today", "server1_weather_week", "server2_tool1"},
+		},
+		{
+			Name: "returns empty when no tool matches pattern",
+			InputTools: &mcp.ListToolsResult{Tools: []mcp.Tool{
+				{Name: "server1_tool1"},
+				{Name: "server2_tool1"},
+			}},
+			VirtualServers: map[string]*config.VirtualServer{
+				"mcp-test/weather-vs": {
+					Name:  "mcp-test/weather-vs",
+					Tools: []string{"server1_weather_*"},
+				},
+			},
+			VirtualServerID: "mcp-test/weather-vs",
+			ExpectedTools:   []string{},
+		},
 		{
 			Name: "returns all tools when virtual server not found",
 			InputTools: &mcp.ListToolsResult{Tools: []mcp.Tool{
@@ -487,3 +520,100 @@ func TestCombinedAuthorizedToolsAndVirtualServer(t *testing.T) {
 		})
 	}
 }
+
+func TestVirtualServerServerScoping(t *testing.T) {
+	weather := createTestManager(t, "team-a/weather", "w_", []mcp.Tool{{Name: "forecast"}, {Name: "alerts"}})
+	news := createTestManager(t, "team-b/news", "n_", []mcp.Tool{{Name: "headlines"}, {Name: "search_archive"}, {Name: "sports"}})
+	mcpServers := map[config.UpstreamMCPID]*upstream.MCPManager{
+		weather.MCP.ID(): weather,
+		news.MCP.ID():    news,
+	}
+
+	testCases := []struct {
+		Name             string
+		VirtualServer    *config.VirtualServer
+		AllowedToolsList map[string][]string
+		ExpectedTools    []string
+	}{
+		{
+			Name: "all tools of one server and some tools of another",
+			VirtualServer: &config.VirtualServer{Servers: []config.VirtualServerScope{
+				{Name: "team-a/weather"},
+				{Name: "team-b/news", Tools: []string{"headlines", "search_*"}},
+			}},
+			ExpectedTools: []string{"w_forecast", "w_alerts", "n_headlines", "n_search_archive"},
+		},
+		{
+			Name: "server tools are named without the tool prefix",
+			VirtualServer: &config.VirtualServer{Servers: []config.VirtualServerScope{
+				{Name: "team-b/news", Tools: []string{"n_headlines"}},
+			}},
+			ExpectedTools: []string{},
+		},
+		{
+			Name: "flat tools and server scoped tools are combined",
+			VirtualServer: &config.VirtualServer{
+				Tools:   []string{"n_sports"},
+				Servers: []config.VirtualServerScope{{Name: "team-a/weather", Tools: []string{"alerts"}}},
+			},
+			ExpectedTools: []string{"n_sports", "w_alerts"},
+		},
+		{
+			Name: "unknown server selects nothing",
+			VirtualServer: &config.VirtualServer{Servers: []config.VirtualServerScope{
+				{Name: "team-c/travel"},
+			}},
+			ExpectedTools: []string{},
+		},
+		{
+			Name: "intersects with x-authorized-tools",
+			VirtualServer: &config.VirtualServer{Servers: []config.VirtualServerScope{
+				{Name: "team-a/weather"},
+			}},
+			AllowedToolsList: map[string][]string{
+				"team-a/weather": {"forecast"},
+				"team-b/news":    {"headlines"},
+			},
+			ExpectedTools: []string{"w_forecast"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.VirtualServer.Name = "mcp-test/composed"
+			mcpBroker := &mcpBrokerImpl{
+				trustedHeadersPublicKey: testPublicKey,
+				mcpServers:              mcpServers,
+				virtualServers:          map[string]*config.VirtualServer{tc.VirtualServer.Name: tc.VirtualServer},
+				logger:                  slog.Default(),
+			}
+
+			// tools are listed as the gateway serves them, carrying the ID of their server
+			inputTools := &mcp.ListToolsResult{Tools: []mcp.Tool{}}
+			for _, manager := range []*upstream.MCPManager{weather, news} {
+				for _, tool := range manager.GetManagedTools() {
+					inputTools.Tools = append(inputTools.Tools, mcp.Tool{
+						Name: manager.MCP.GetPrefix() + tool.Name,
+						Meta: &mcp.Meta{AdditionalFields: map[string]any{"kuadrant/id": string(manager.MCP.ID())}},
+					})
+				}
+			}
+
+			request := &mcp.ListToolsRequest{Header: http.Header{}}
+			request.Header[virtualMCPHeader] = []string{tc.VirtualServer.Name}
+			if tc.AllowedToolsList != nil {
+				request.Header[authorizedToolsHeader] = []string{createTestJWT(t, tc.AllowedToolsList)}
+			}
+
+			mcpBroker.FilterTools(context.TODO(), 1, request, inputTools)
+
+			names := []string{}
+			for _, tool := range inputTools.Tools {
+				names = append(names, tool.Name)
+			}
+			if !slices.Equal(slices.Sorted(slices.Values(names)), slices.Sorted(slices.Values(tc.ExpectedTools))) {
+				t.Fatalf("expected tools %v but got %v", tc.ExpectedTools, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package broker
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithToolsPageSize limits the number of tools returned by each tools/list request, returning a cursor clients
+// pass back to fetch the next page. A size of 0 returns every tool in one response
+func WithToolsPageSize(size int) func(mb *mcpBrokerImpl) {
+	return func(mb *mcpBrokerImpl) {
+		mb.toolsPageSize = max(size, 0)
+	}
+}
+
+// paginateTools returns the page of tools following the cursor, ordered by name, and the cursor for the page after it.
+// The cursor is the base64 encoded name of the last tool served, the same encoding mcp-go uses, as mcp-go has already
+// dropped the tools up to the cursor before the list hooks run. Resuming after a name rather than an offset keeps pages
+// stable when upstream servers add or remove tools between requests. The returned cursor is empty on the last page
+func paginateTools(tools []mcp.Tool, cursor mcp.Cursor, pageSize int) ([]mcp.Tool, mcp.Cursor) {
+	sorted := slices.Clone(tools)
+	slices.SortFunc(sorted, func(a, b mcp.Tool) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	start := 0
+	if cursor != "" {
+		// an invalid cursor is rejected by mcp-go before the hooks run so this starts from the beginning defensively
+		if after, err := base64.StdEncoding.DecodeString(string(cursor)); err == nil {
+			start, _ = slices.BinarySearchFunc(sorted, string(after), func(t mcp.Tool, name string) int {
+				if t.Name <= name {
+					return -1
+				}
+				return 1
+			})
+		}
+	}
+	if start+pageSize >= len(sorted) {
+		return sorted[start:], ""
+	}
+	page := sorted[start : start+pageSize]
+	return page, mcp.Cursor(base64.StdEncoding.EncodeToString([]byte(page[len(page)-1].Name)))
+}
@@ -0,0 +1,131 @@
+package broker
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func toolsNamed(names ...string) []mcp.Tool {
+	tools := make([]mcp.Tool, 0, len(names))
+	for _, name := range names {
+		tools = append(tools, mcp.Tool{Name: name})
+	}
+	return tools
+}
+
+func toolNames(tools []mcp.Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func cursorAfter(name string) mcp.Cursor {
+	return mcp.Cursor(base64.StdEncoding.EncodeToString([]byte(name)))
+}
+
+func TestPaginateTools(t *testing.T) {
+	testCases := []struct {
+		name           string
+		tools          []mcp.Tool
+		cursor         mcp.Cursor
+		pageSize       int
+		expectedNames  []string
+		expectedCursor mcp.Cursor
+	}{
+		{
+			name:           "first page",
+			tools:          toolsNamed("a", "b", "c", "d", "e"),
+			pageSize:       2,
+			expectedNames:  []string{"a", "b"},
+			expectedCursor: cursorAfter("b"),
+		},
+		{
+			name:           "middle page",
+			tools:          toolsNamed("a", "b", "c", "d", "e"),
+			cursor:         cursorAfter("b"),
+			pageSize:       2,
+			expectedNames:  []string{"c", "d"},
+			expectedCursor: cursorAfter("d"),
+		},
+		{
+			name:          "last partial page has no cursor",
+			tools:         toolsNamed("a", "b", "c", "d", "e"),
+			cursor:        cursorAfter("d"),
+			pageSize:      2,
+			expectedNames: []string{"e"},
+		},
+		{
+			name:          "last full page has no cursor",
+			tools:         toolsNamed("a", "b", "c", "d"),
+			cursor:        cursorAfter("b"),
+			pageSize:      2,
+			expectedNames: []string{"c", "d"},
+		},
+		{
+			name:          "page larger than the list",
+			tools:         toolsNamed("a", "b"),
+			pageSize:      5,
+			expectedNames: []string{"a", "b"},
+		},
+		{
+			name:          "empty list",
+			pageSize:      2,
+			expectedNames: []string{},
+		},
+		{
+			name:          "cursor past the end",
+			tools:         toolsNamed("a", "b"),
+			cursor:        cursorAfter("z"),
+			pageSize:      2,
+			expectedNames: []string{},
+		},
+		{
+			name:           "cursor naming a removed tool resumes after it",
+			tools:          toolsNamed("a", "b", "d", "e", "f"),
+			cursor:         cursorAfter("c"),
+			pageSize:       2,
+			expectedNames:  []string{"d", "e"},
+			expectedCursor: cursorAfter("e"),
+		},
+		{
+			name:           "invalid cursor starts from the beginning",
+			tools:          toolsNamed("a", "b", "c"),
+			cursor:         "not base64!",
+			pageSize:       2,
+			expectedNames:  []string{"a", "b"},
+			expectedCursor: cursorAfter("b"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, next := paginateTools(tc.tools, tc.cursor, tc.pageSize)
+			require.Equal(t, tc.expectedNames, toolNames(page))
+			require.Equal(t, tc.expectedCursor, next)
+		})
+	}
+}
+
+func TestPaginateToolsStableOrdering(t *testing.T) {
+	unsorted := toolsNamed("server2_b", "server1_c", "server2_a", "server1_a", "server1_b")
+	reordered := toolsNamed("server1_b", "server2_a", "server1_a", "server2_b", "server1_c")
+
+	var pages []string
+	var cursor mcp.Cursor
+	for i, tools := range [][]mcp.Tool{unsorted, reordered, unsorted} {
+		page, next := paginateTools(tools, cursor, 2)
+		pages = append(pages, toolNames(page)...)
+		cursor = next
+		if i < 2 {
+			require.NotEmpty(t, cursor)
+		}
+	}
+	require.Empty(t, cursor)
+	require.Equal(t, []string{"server1_a", "server1_b", "server1_c", "server2_a", "server2_b"}, pages)
+	require.Equal(t, "server2_b", unsorted[0].Name, "input should not be reordered")
+}
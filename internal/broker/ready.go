@@ -0,0 +1,89 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+)
+
+const (
+	// ReadinessReady is reported once every upstream has been attempted and at least one is ready, or there are none
+	ReadinessReady = "Ready"
+	// ReadinessNotReady is reported until the config is loaded and every upstream has been attempted
+	ReadinessNotReady = "NotReady"
+	// ReadinessDegraded is reported when every upstream has been attempted and none is ready
+	ReadinessDegraded = "Degraded"
+)
+
+// ReadinessResponse is the body of the broker upstream readiness endpoint
+type ReadinessResponse struct {
+	Status           string `json:"status"`
+	Message          string `json:"message,omitempty"`
+	TotalServers     int    `json:"totalServers"`
+	AttemptedServers int    `json:"attemptedServers"`
+	ReadyServers     int    `json:"readyServers"`
+}
+
+// readiness derives the broker readiness from the status of its upstreams. The broker is not ready until its config
+// is loaded and each upstream has been attempted, so a starting broker is not sent clients it can serve no tools to.
+// A degraded broker stays ready: the upstreams fail on every broker alike, and the controller only reads the status
+// of ready brokers so it could no longer report why the upstreams fail
+func readiness(configLoaded bool, statuses []upstream.ServerValidationStatus) (int, ReadinessResponse) {
+	response := ReadinessResponse{TotalServers: len(statuses)}
+	for _, status := range statuses {
+		if status.LastValidated.IsZero() {
+			continue
+		}
+		response.AttemptedServers++
+		if status.Ready {
+			response.ReadyServers++
+		}
+	}
+	switch {
+	case !configLoaded:
+		response.Status = ReadinessNotReady
+		response.Message = "waiting for the config to load"
+		return http.StatusServiceUnavailable, response
+	case response.AttemptedServers < response.TotalServers:
+		response.Status = ReadinessNotReady
+		response.Message = fmt.Sprintf("waiting for %d of %d upstream servers to be attempted",
+			response.TotalServers-response.AttemptedServers, response.TotalServers)
+		return http.StatusServiceUnavailable, response
+	case response.TotalServers > 0 && response.ReadyServers == 0:
+		response.Status = ReadinessDegraded
+		response.Message = "no upstream server is ready"
+		return http.StatusOK, response
+	}
+	response.Status = ReadinessReady
+	return http.StatusOK, response
+}
+
+// HandleReadyRequest writes whether the broker has loaded its config. It backs the pod readiness probe so it does not
+// wait on the upstreams, a broker waiting on one slow upstream can already serve the tools of the others
+func (m *mcpBrokerImpl) HandleReadyRequest(w http.ResponseWriter, _ *http.Request) {
+	if !m.configLoaded.Load() {
+		http.Error(w, "waiting for the config to load", http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
+
+// HandleUpstreamReadinessRequest writes the readiness of the broker upstreams. It responds 503 until the config is
+// loaded and every upstream has been attempted
+func (m *mcpBrokerImpl) HandleUpstreamReadinessRequest(w http.ResponseWriter, _ *http.Request) {
+	m.mcpLock.RLock()
+	statuses := make([]upstream.ServerValidationStatus, 0, len(m.mcpServers))
+	for _, manager := range m.mcpServers {
+		statuses = append(statuses, manager.GetStatus())
+	}
+	m.mcpLock.RUnlock()
+
+	statusCode, response := readiness(m.configLoaded.Load(), statuses)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		m.logger.Error("failed to encode readiness response", "error", err)
+	}
+}
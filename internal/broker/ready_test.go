@@ -0,0 +1,129 @@
+package broker
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleUpstreamReadinessRequest(t *testing.T) {
+	attempted := time.Now()
+	tests := []struct {
+		name           string
+		configLoaded   bool
+		statuses       []upstream.ServerValidationStatus
+		expectedCode   int
+		expectedStatus string
+		expectedReady  int
+	}{
+		{
+			name:           "config not loaded",
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: ReadinessNotReady,
+		},
+		{
+			name:           "no servers configured",
+			configLoaded:   true,
+			expectedCode:   http.StatusOK,
+			expectedStatus: ReadinessReady,
+		},
+		{
+			name:         "server not attempted yet",
+			configLoaded: true,
+			statuses: []upstream.ServerValidationStatus{
+				{Name: "weather", LastValidated: attempted, Ready: true},
+				{Name: "docs"},
+			},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: ReadinessNotReady,
+			expectedReady:  1,
+		},
+		{
+			name:         "all servers failing",
+			configLoaded: true,
+			statuses: []upstream.ServerValidationStatus{
+				{Name: "weather", LastValidated: attempted, Message: "connection refused"},
+				{Name: "docs", LastValidated: attempted, Reason: upstream.ReasonProtocolMismatch},
+			},
+			expectedCode:   http.StatusOK,
+			expectedStatus: ReadinessDegraded,
+		},
+		{
+			name:         "some servers ready",
+			configLoaded: true,
+			statuses: []upstream.ServerValidationStatus{
+				{Name: "weather", LastValidated: attempted, Ready: true},
+				{Name: "docs", LastValidated: attempted, Message: "connection refused"},
+			},
+			expectedCode:   http.StatusOK,
+			expectedStatus: ReadinessReady,
+			expectedReady:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpBroker := NewBroker(slog.New(slog.NewTextHandler(os.Stdout, nil)))
+			brokerImpl := mcpBroker.(*mcpBrokerImpl)
+			brokerImpl.configLoaded.Store(tt.configLoaded)
+			for _, status := range tt.statuses {
+				manager := upstream.NewUpstreamMCPManager(upstream.NewUpstreamMCP(&config.MCPServer{
+					Name: status.Name,
+					URL:  "http://" + status.Name + ".local/mcp",
+				}), nil, slog.Default(), 0)
+				manager.SetStatusForTesting(status)
+				brokerImpl.mcpServers[manager.MCP.ID()] = manager
+			}
+
+			w := httptest.NewRecorder()
+			mcpBroker.HandleUpstreamReadinessRequest(w, httptest.NewRequest(http.MethodGet, "/readyz/upstreams", nil))
+			require.Equal(t, tt.expectedCode, w.Code)
+			var response ReadinessResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			require.Equal(t, tt.expectedStatus, response.Status)
+			require.Equal(t, len(tt.statuses), response.TotalServers)
+			require.Equal(t, tt.expectedReady, response.ReadyServers)
+		})
+	}
+}
+
+func TestOnConfigChangeMarksConfigLoaded(t *testing.T) {
+	mcpBroker := NewBroker(slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	w := httptest.NewRecorder()
+	mcpBroker.HandleReadyRequest(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	mcpBroker.OnConfigChange(t.Context(), &config.MCPServersConfig{})
+	w = httptest.NewRecorder()
+	mcpBroker.HandleReadyRequest(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHandleReadyRequestIgnoresUpstreams(t *testing.T) {
+	mcpBroker := NewBroker(slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	brokerImpl := mcpBroker.(*mcpBrokerImpl)
+	brokerImpl.configLoaded.Store(true)
+	// an upstream not attempted yet keeps the upstream readiness at 503 but not the readiness probe
+	manager := upstream.NewUpstreamMCPManager(upstream.NewUpstreamMCP(&config.MCPServer{
+		Name: "weather",
+		URL:  "http://weather.local/mcp",
+	}), nil, slog.Default(), 0)
+	manager.SetStatusForTesting(upstream.ServerValidationStatus{Name: "weather"})
+	brokerImpl.mcpServers[manager.MCP.ID()] = manager
+
+	w := httptest.NewRecorder()
+	mcpBroker.HandleReadyRequest(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	mcpBroker.HandleUpstreamReadinessRequest(w, httptest.NewRequest(http.MethodGet, "/readyz/upstreams", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,49 @@
+package broker
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// unavailableServersMetaKey is the tools/list result meta field listing upstream servers whose tools are missing from the result
+const unavailableServersMetaKey = "kuadrant/unavailableServers"
+
+// UnavailableServer identifies an upstream MCP server that is not ready and so contributes no tools
+type UnavailableServer struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// addServerAvailability records the upstream servers that are not ready in the tools/list result meta so clients
+// can tell a backend is unavailable rather than silently missing its tools
+func (m *mcpBrokerImpl) addServerAvailability(result *mcp.ListToolsResult) {
+	unavailable := m.unavailableServers()
+	if len(unavailable) == 0 {
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = &mcp.Meta{}
+	}
+	if result.Meta.AdditionalFields == nil {
+		result.Meta.AdditionalFields = map[string]any{}
+	}
+	result.Meta.AdditionalFields[unavailableServersMetaKey] = unavailable
+}
+
+func (m *mcpBrokerImpl) unavailableServers() []UnavailableServer {
+	m.mcpLock.RLock()
+	defer m.mcpLock.RUnlock()
+
+	var unavailable []UnavailableServer
+	for _, manager := range m.mcpServers {
+		if status := manager.GetStatus(); !status.Ready {
+			unavailable = append(unavailable, UnavailableServer{Name: manager.MCPName(), Reason: status.Reason})
+		}
+	}
+	slices.SortFunc(unavailable, func(a, b UnavailableServer) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return unavailable
+}
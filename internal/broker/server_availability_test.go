@@ -0,0 +1,66 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListToolsServerAvailabilityMeta(t *testing.T) {
+	listTools := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+
+	testCases := []struct {
+		name                string
+		enabled             bool
+		expectedUnavailable []UnavailableServer
+	}{
+		{
+			name:                "enabled reports the down server",
+			enabled:             true,
+			expectedUnavailable: []UnavailableServer{{Name: "down", Reason: upstream.ReasonProtocolMismatch}},
+		},
+		{
+			name:    "disabled leaves meta unset",
+			enabled: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBroker(logger, WithServerAvailabilityMeta(tc.enabled)).(*mcpBrokerImpl)
+			healthy := createTestManagerForStatus(t, "healthy", []mcp.Tool{{Name: "healthy_tool"}})
+			healthy.SetStatusForTesting(upstream.ServerValidationStatus{Name: "healthy", Ready: true})
+			down := createTestManagerForStatus(t, "down", nil)
+			down.SetStatusForTesting(upstream.ServerValidationStatus{Name: "down", Ready: false, Reason: upstream.ReasonProtocolMismatch})
+			b.mcpServers[config.UpstreamMCPID("healthy:test_:http://test.local/mcp")] = healthy
+			b.mcpServers[config.UpstreamMCPID("down:test_:http://test.local/mcp")] = down
+			b.markSynced()
+
+			res := b.MCPServer().HandleMessage(context.Background(), listTools)
+			raw, err := json.Marshal(res)
+			require.NoError(t, err)
+
+			var resp struct {
+				Result struct {
+					Meta map[string]json.RawMessage `json:"_meta"`
+				} `json:"result"`
+			}
+			require.NoError(t, json.Unmarshal(raw, &resp))
+
+			metaValue, found := resp.Result.Meta[unavailableServersMetaKey]
+			if tc.expectedUnavailable == nil {
+				require.False(t, found, "expected no %s meta, got %s", unavailableServersMetaKey, raw)
+				return
+			}
+			require.True(t, found, "expected %s meta in %s", unavailableServersMetaKey, raw)
+			var unavailable []UnavailableServer
+			require.NoError(t, json.Unmarshal(metaValue, &unavailable))
+			require.Equal(t, tc.expectedUnavailable, unavailable)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package broker
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	authorizedToolsFilterName = "authorizedTools"
+	virtualServerFilterName   = "virtualServer"
+)
+
+// AppliedFilter describes a filter that reduced the tools a session sees. Detail never includes token claims
+type AppliedFilter struct {
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SessionToolsResponse is the effective tool list for a client session as returned by the admin endpoint
+type SessionToolsResponse struct {
+	SessionID string          `json:"sessionId"`
+	Tools     []string        `json:"tools"`
+	Filters   []AppliedFilter `json:"filters"`
+}
+
+// recordSessionFilters keeps the filter headers a session last listed tools with so its view can be rebuilt later
+func (m *mcpBrokerImpl) recordSessionFilters(sessionID string, headers http.Header) {
+	if sessionID == "" {
+		return
+	}
+	filterHeaders := http.Header{}
+	for _, key := range []string{authorizedToolsHeader, virtualMCPHeader} {
+		if values, ok := headers[key]; ok {
+			filterHeaders[key] = slices.Clone(values)
+		}
+	}
+	m.sessionFilters.Store(sessionID, filterHeaders)
+}
+
+// SessionFilters returns the filter headers of every client session that has listed tools
+func (m *mcpBrokerImpl) SessionFilters() map[string]http.Header {
+	filters := map[string]http.Header{}
+	m.sessionFilters.Range(func(key, value any) bool {
+		filters[key.(string)] = value.(http.Header).Clone()
+		return true
+	})
+	return filters
+}
+
+// RestoreSessionFilters records the filter headers of client sessions that listed tools with another broker
+func (m *mcpBrokerImpl) RestoreSessionFilters(filters map[string]http.Header) {
+	for sessionID, headers := range filters {
+		m.sessionFilters.Store(sessionID, headers.Clone())
+	}
+}
+
+// SessionTools returns the tools the session would currently get from tools/list. It returns false if the session
+// has not listed tools
+func (m *mcpBrokerImpl) SessionTools(sessionID string) (SessionToolsResponse, bool) {
+	value, ok := m.sessionFilters.Load(sessionID)
+	if !ok {
+		return SessionToolsResponse{}, false
+	}
+
+	serverTools := m.listeningMCPServer.ListTools()
+	tools := make([]mcp.Tool, 0, len(serverTools))
+	for _, serverTool := range serverTools {
+		tools = append(tools, serverTool.Tool)
+	}
+	slices.SortFunc(tools, func(a, b mcp.Tool) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	filtered, applied := m.filterTools(value.(http.Header), tools)
+	response := SessionToolsResponse{
+		SessionID: sessionID,
+		Tools:     make([]string, 0, len(filtered)),
+		Filters:   applied,
+	}
+	for _, tool := range filtered {
+		response.Tools = append(response.Tools, tool.Name)
+	}
+	if response.Filters == nil {
+		response.Filters = []AppliedFilter{}
+	}
+	return response, true
+}
+
+// HandleSessionToolsRequest handles admin requests for the effective tool list of the session named by the id path value
+func (m *mcpBrokerImpl) HandleSessionToolsRequest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	response, ok := m.SessionTools(r.PathValue("id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "session not found or has not listed tools"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		m.logger.Error("failed to encode session tools response", "error", err)
+	}
+}
@@ -0,0 +1,99 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionToolsMatchesClientView(t *testing.T) {
+	b := NewBroker(logger).(*mcpBrokerImpl)
+	b.markSynced()
+	noop := func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	for _, name := range []string{"weather_forecast", "weather_alerts", "calendar_events"} {
+		b.MCPServer().AddTool(mcp.Tool{Name: name}, noop)
+	}
+	b.virtualServers["default/weather"] = &config.VirtualServer{Name: "default/weather", Tools: []string{"weather_forecast", "calendar_events"}}
+
+	srv := httptest.NewServer(server.NewStreamableHTTPServer(b.MCPServer()))
+	defer srv.Close()
+
+	post := func(sessionID, body string) *http.Response {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(virtualMCPHeader, "default/weather")
+		if sessionID != "" {
+			req.Header.Set(server.HeaderKeySessionID, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	initResp := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	_ = initResp.Body.Close()
+	sessionID := initResp.Header.Get(server.HeaderKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	_, ok := b.SessionTools(sessionID)
+	require.False(t, ok, "session should be unknown until it lists tools")
+
+	listResp := post(sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	defer func() { _ = listResp.Body.Close() }()
+	var listed struct {
+		Result mcp.ListToolsResult `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(listResp.Body).Decode(&listed))
+	var clientTools []string
+	for _, tool := range listed.Result.Tools {
+		clientTools = append(clientTools, tool.Name)
+	}
+	require.ElementsMatch(t, []string{"weather_forecast", "calendar_events"}, clientTools)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin/sessions/"+sessionID+"/tools", nil)
+	req.SetPathValue("id", sessionID)
+	b.HandleSessionToolsRequest(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var view SessionToolsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &view))
+	require.Equal(t, sessionID, view.SessionID)
+	require.ElementsMatch(t, clientTools, view.Tools)
+	require.Equal(t, []AppliedFilter{{Name: virtualServerFilterName, Detail: "default/weather"}}, view.Filters)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/admin/sessions/unknown/tools", nil)
+	req.SetPathValue("id", "unknown")
+	b.HandleSessionToolsRequest(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSessionToolsRedactsAuthorizedToolsClaims(t *testing.T) {
+	b := NewBroker(logger, WithTrustedHeadersPublicKey(testPublicKey)).(*mcpBrokerImpl)
+	token := createTestJWT(t, map[string][]string{"secret-server": {"secret_tool"}})
+	b.recordSessionFilters("session", http.Header{authorizedToolsHeader: {token}})
+	b.MCPServer().AddTool(mcp.Tool{Name: "other_tool"}, func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, nil
+	})
+
+	view, ok := b.SessionTools("session")
+	require.True(t, ok)
+	require.Empty(t, view.Tools)
+	raw, err := json.Marshal(view)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "secret")
+	require.NotContains(t, string(raw), token)
+	require.Equal(t, []AppliedFilter{{Name: authorizedToolsFilterName, Detail: "allows tools from 1 servers"}}, view.Filters)
+}
@@ -0,0 +1,112 @@
+package broker
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/Kuadrant/mcp-gateway/internal/session"
+)
+
+// State is the in memory session state an outgoing broker hands to its replacement during a controlled rollout so
+// client sessions survive the upgrade without an external session store
+type State struct {
+	// Sessions are the upstream MCP server sessions of each gateway session
+	Sessions session.Snapshot `json:"sessions"`
+	// SessionFilters are the filter headers each gateway session last listed tools with
+	SessionFilters map[string]http.Header `json:"sessionFilters,omitempty"`
+}
+
+// stateCipher returns the AES-GCM cipher the state file is encrypted with. The key is derived from the session signing
+// key, which the outgoing and incoming broker share so the incoming broker accepts the sessions it restores
+func stateCipher(signingKey string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(signingKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SaveState writes the session state of the broker and cache to path, encrypted with a key derived from the session
+// signing key as the gateway session ids are the JWTs clients present. The file is replaced atomically so a broker
+// starting up never reads a partial write
+func SaveState(path string, signingKey string, broker MCPBroker, cache *session.Cache) error {
+	snapshot, err := cache.Snapshot()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(State{Sessions: snapshot, SessionFilters: broker.SessionFilters()})
+	if err != nil {
+		return fmt.Errorf("failed to encode broker state: %w", err)
+	}
+	aead, err := stateCipher(signingKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt broker state: %w", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to encrypt broker state: %w", err)
+	}
+	data = aead.Seal(nonce, nonce, data, nil)
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write broker state: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write broker state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write broker state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write broker state: %w", err)
+	}
+	return nil
+}
+
+// RestoreState loads the session state written by SaveState into the broker and cache and removes the file so the
+// state is only restored once. It returns false if there is no state to restore. State written with a different
+// signing key can't be decrypted and is returned as an error
+func RestoreState(path string, signingKey string, broker MCPBroker, cache *session.Cache) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read broker state: %w", err)
+	}
+	aead, err := stateCipher(signingKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt broker state: %w", err)
+	}
+	if len(data) < aead.NonceSize() {
+		return false, fmt.Errorf("failed to decrypt broker state: file is truncated")
+	}
+	data, err = aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt broker state, it may have been written with a different signing key: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return false, fmt.Errorf("failed to decode broker state: %w", err)
+	}
+	if err := cache.Restore(state.Sessions); err != nil {
+		return false, err
+	}
+	broker.RestoreSessionFilters(state.SessionFilters)
+	if err := os.Remove(path); err != nil {
+		return true, fmt.Errorf("failed to remove restored broker state: %w", err)
+	}
+	return true, nil
+}
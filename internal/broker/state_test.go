@@ -0,0 +1,76 @@
+package broker
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+	"github.com/Kuadrant/mcp-gateway/internal/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndRestoreState(t *testing.T) {
+	ctx := context.Background()
+	noop := func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	newBroker := func() *mcpBrokerImpl {
+		b := NewBroker(logger).(*mcpBrokerImpl)
+		for _, name := range []string{"weather_forecast", "weather_alerts", "calendar_events"} {
+			b.MCPServer().AddTool(mcp.Tool{Name: name}, noop)
+		}
+		b.virtualServers["default/weather"] = &config.VirtualServer{Name: "default/weather", Tools: []string{"weather_forecast", "calendar_events"}}
+		return b
+	}
+
+	outgoing := newBroker()
+	outgoingCache, err := session.NewCache(ctx)
+	require.NoError(t, err)
+	_, err = outgoingCache.AddSession(ctx, "gateway-session", "weather", "upstream-session")
+	require.NoError(t, err)
+	require.NoError(t, outgoingCache.MarkResumable(ctx, "gateway-session", time.Minute))
+	outgoing.recordSessionFilters("gateway-session", http.Header{virtualMCPHeader: []string{"default/weather"}})
+	want, ok := outgoing.SessionTools("gateway-session")
+	require.True(t, ok)
+
+	path := filepath.Join(t.TempDir(), "broker-state.json")
+	require.NoError(t, SaveState(path, "signing-key", outgoing, outgoingCache))
+
+	// the session ids are the JWTs clients present so they are not written in the clear
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "gateway-session")
+	require.NotContains(t, string(data), "upstream-session")
+
+	// a broker with a different signing key can't read the state
+	_, err = RestoreState(path, "other-key", newBroker(), outgoingCache)
+	require.ErrorContains(t, err, "failed to decrypt broker state")
+
+	incoming := newBroker()
+	incomingCache, err := session.NewCache(ctx)
+	require.NoError(t, err)
+	restored, err := RestoreState(path, "signing-key", incoming, incomingCache)
+	require.NoError(t, err)
+	require.True(t, restored)
+
+	sessions, err := incomingCache.GetSession(ctx, "gateway-session")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"weather": "upstream-session"}, sessions)
+	resumable, err := incomingCache.Resumable(ctx, "gateway-session")
+	require.NoError(t, err)
+	require.True(t, resumable)
+	got, ok := incoming.SessionTools("gateway-session")
+	require.True(t, ok)
+	require.Equal(t, want, got)
+	require.ElementsMatch(t, []string{"weather_forecast", "calendar_events"}, got.Tools)
+
+	// the state is consumed so a later restart does not restore it again
+	restored, err = RestoreState(path, "signing-key", incoming, incomingCache)
+	require.NoError(t, err)
+	require.False(t, restored)
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
 )
 
 // ServerValidationStatus contains the validation status of a single MCP server
@@ -99,6 +101,10 @@ func (h *StatusHandler) setResponseHeaders(w http.ResponseWriter, _ *http.Reques
 
 func (h *StatusHandler) handleGetStatus(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	if serverID := r.URL.Query().Get("id"); serverID != "" {
+		h.handleSingleServerByID(w, serverID)
+		return
+	}
 	// Parse URL path to check for specific server request
 	path := strings.TrimPrefix(r.URL.Path, "/status")
 	if path != "" && path != "/" {
@@ -136,6 +142,16 @@ func (h *StatusHandler) handleSingleServerByName(_ context.Context, w http.Respo
 	h.sendJSONResponse(w, http.StatusOK, serverStatus)
 }
 
+// handleSingleServerByID writes the status of the server with the id, as reported in the id field of the status
+func (h *StatusHandler) handleSingleServerByID(w http.ResponseWriter, serverID string) {
+	serverStatus, ok := h.broker.ValidateServer(config.UpstreamMCPID(serverID))
+	if !ok {
+		h.sendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Server with id '%s' not found", serverID))
+		return
+	}
+	h.sendJSONResponse(w, http.StatusOK, serverStatus)
+}
+
 func (h *StatusHandler) sendJSONResponse(w http.ResponseWriter, statusCode int, data any) {
 	w.WriteHeader(statusCode)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
@@ -147,3 +163,18 @@ func (h *StatusHandler) sendErrorResponse(w http.ResponseWriter, statusCode int,
 	response := map[string]string{"error": message}
 	h.sendJSONResponse(w, statusCode, response)
 }
+
+// HandleDebugServersRequest writes the status of every upstream MCP server sorted by name, indented so it can be read
+// when diagnosing a server the gateway doesn't serve yet
+func (m *mcpBrokerImpl) HandleDebugServersRequest(w http.ResponseWriter, _ *http.Request) {
+	servers := m.ValidateAllServers().Servers
+	slices.SortFunc(servers, func(a, b upstream.ServerValidationStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(servers); err != nil {
+		m.logger.Error("failed to encode debug servers response", "error", err)
+	}
+}
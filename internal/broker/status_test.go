@@ -6,8 +6,10 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
 	"github.com/Kuadrant/mcp-gateway/internal/config"
@@ -67,6 +69,34 @@ func TestStatusHandlerGetSingleServer(t *testing.T) {
 	require.Equal(t, 200, res.StatusCode)
 }
 
+func TestStatusHandlerGetSingleServerByID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mcpBroker := NewBroker(logger)
+	sh := NewStatusHandler(mcpBroker, *logger)
+
+	const serverID = "team-a/weather:weather_:weather.local"
+	w := httptest.NewRecorder()
+	sh.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status?id="+url.QueryEscape(serverID), nil))
+	require.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	brokerImpl, ok := mcpBroker.(*mcpBrokerImpl)
+	require.True(t, ok)
+	manager := createTestManagerForStatus(t, "team-a/weather", []mcp.Tool{{Name: "forecast"}})
+	manager.SetStatusForTesting(upstream.ServerValidationStatus{ID: serverID, Name: "team-a/weather", Ready: true, TotalTools: 1})
+	brokerImpl.mcpServers[serverID] = manager
+	brokerImpl.mcpServers["team-b/time:time_:time.local"] = createTestManagerForStatus(t, "team-b/time", nil)
+
+	w = httptest.NewRecorder()
+	sh.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status?id="+url.QueryEscape(serverID), nil))
+	res := w.Result()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var status upstream.ServerValidationStatus
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&status))
+	require.Equal(t, serverID, status.ID)
+	require.True(t, status.Ready)
+	require.Equal(t, 1, status.TotalTools)
+}
+
 func TestStatusHandlerGetAll(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	mcpBroker := NewBroker(logger)
@@ -90,3 +120,42 @@ func TestStatusHandlerGetAll(t *testing.T) {
 	err = json.Unmarshal(data, &m)
 	require.NoError(t, err)
 }
+
+func TestHandleDebugServersRequest(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	brokerImpl := NewBroker(logger).(*mcpBrokerImpl)
+
+	w := httptest.NewRecorder()
+	brokerImpl.HandleDebugServersRequest(w, httptest.NewRequest(http.MethodGet, "/debug/servers", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, "[]", w.Body.String())
+
+	validated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, name := range []string{"team-b/weather", "team-a/calendar"} {
+		manager := createTestManagerForStatus(t, name, []mcp.Tool{{Name: "dummyTool"}})
+		manager.SetStatusForTesting(upstream.ServerValidationStatus{
+			Name:          name,
+			Message:       "connection refused",
+			Reason:        "ConnectionFailed",
+			TotalTools:    2,
+			LastValidated: validated,
+		})
+		brokerImpl.mcpServers[config.UpstreamMCPID(name)] = manager
+	}
+
+	w = httptest.NewRecorder()
+	brokerImpl.HandleDebugServersRequest(w, httptest.NewRequest(http.MethodGet, "/debug/servers", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var servers []map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &servers))
+	require.Len(t, servers, 2)
+	require.Equal(t, "team-a/calendar", servers[0]["name"])
+	require.Equal(t, "team-b/weather", servers[1]["name"])
+	require.Equal(t, false, servers[0]["ready"])
+	require.Equal(t, "connection refused", servers[0]["message"])
+	require.Equal(t, "ConnectionFailed", servers[0]["reason"])
+	require.InDelta(t, 2, servers[0]["totalTools"], 0)
+	require.Equal(t, "2026-01-02T03:04:05Z", servers[0]["lastValidated"])
+}
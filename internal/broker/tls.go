@@ -0,0 +1,39 @@
+package broker
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// tlsVersions are the minimum TLS versions the public listener can be configured with
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewTLSConfig returns the TLS config for the public listener. minVersion is "1.2" or "1.3" and cipherSuites are IANA
+// cipher suite names applied to TLS 1.2. Insecure cipher suites are rejected
+func NewTLSConfig(minVersion string, cipherSuites []string) (*tls.Config, error) {
+	version, ok := tlsVersions[minVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported minimum TLS version %q, expected 1.2 or 1.3", minVersion)
+	}
+	config := &tls.Config{MinVersion: version}
+	for _, name := range cipherSuites {
+		id, ok := cipherSuiteID(name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS cipher suite %q", name)
+		}
+		config.CipherSuites = append(config.CipherSuites, id)
+	}
+	return config, nil
+}
+
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
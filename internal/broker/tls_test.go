@@ -0,0 +1,27 @@
+package broker
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	config, err := NewTLSConfig("1.2", nil)
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	require.Empty(t, config.CipherSuites)
+
+	config, err = NewTLSConfig("1.3", []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"})
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	require.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, config.CipherSuites)
+
+	_, err = NewTLSConfig("1.1", nil)
+	require.ErrorContains(t, err, "unsupported minimum TLS version")
+
+	// insecure cipher suites are not accepted
+	_, err = NewTLSConfig("1.2", []string{"TLS_RSA_WITH_RC4_128_SHA"})
+	require.ErrorContains(t, err, "unsupported TLS cipher suite")
+}
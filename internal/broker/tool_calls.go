@@ -0,0 +1,82 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolCallTracker counts the tool calls in flight to each upstream MCP server and records which servers are draining.
+// The zero value is ready to use
+type toolCallTracker struct {
+	mu       sync.Mutex
+	inFlight map[config.UpstreamMCPID]int
+	draining map[config.UpstreamMCPID]bool
+}
+
+// start records a call to the server and returns a func that records its completion. The func may be called more than once
+func (t *toolCallTracker) start(serverID config.UpstreamMCPID) func() {
+	t.mu.Lock()
+	if t.inFlight == nil {
+		t.inFlight = map[config.UpstreamMCPID]int{}
+	}
+	t.inFlight[serverID]++
+	t.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.inFlight[serverID]--
+			if t.inFlight[serverID] <= 0 {
+				delete(t.inFlight, serverID)
+			}
+		})
+	}
+}
+
+func (t *toolCallTracker) inFlightCalls(serverID config.UpstreamMCPID) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.inFlight[serverID]
+}
+
+// setDraining replaces the set of draining servers with those marked as draining in the config
+func (t *toolCallTracker) setDraining(servers []*config.MCPServer) {
+	draining := map[config.UpstreamMCPID]bool{}
+	for _, mcpServer := range servers {
+		if mcpServer.Draining {
+			draining[mcpServer.ID()] = true
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.draining = draining
+}
+
+func (t *toolCallTracker) isDraining(serverID config.UpstreamMCPID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining[serverID]
+}
+
+// ToolCallStarted records a tool call routed to the upstream MCP server. The returned func must be called once the call completes
+func (m *mcpBrokerImpl) ToolCallStarted(serverID config.UpstreamMCPID) func() {
+	return m.toolCalls.start(serverID)
+}
+
+// removeDrainingTools removes the tools of draining servers so clients stop discovering them while calls already in
+// flight complete
+func (m *mcpBrokerImpl) removeDrainingTools(tools []mcp.Tool) []mcp.Tool {
+	var listed []mcp.Tool
+	for _, tool := range tools {
+		if serverID, ok := upstream.ToolServerID(tool); ok && m.toolCalls.isDraining(serverID) {
+			continue
+		}
+		listed = append(listed, tool)
+	}
+	return listed
+}
@@ -0,0 +1,69 @@
+package broker
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCallTracker(t *testing.T) {
+	var tracker toolCallTracker
+	serverID := config.UpstreamMCPID("weather:weather_:weather.mcp.local")
+
+	first := tracker.start(serverID)
+	second := tracker.start(serverID)
+	require.Equal(t, 2, tracker.inFlightCalls(serverID))
+
+	first()
+	// completing a call more than once only counts it once
+	first()
+	require.Equal(t, 1, tracker.inFlightCalls(serverID))
+	second()
+	require.Zero(t, tracker.inFlightCalls(serverID))
+}
+
+func TestValidateAllServers_Draining(t *testing.T) {
+	mcpBroker := NewBroker(slog.Default()).(*mcpBrokerImpl)
+	draining := &config.MCPServer{Name: "dummyServer", ToolPrefix: "test_", Draining: true}
+	mcpBroker.mcpServers[draining.ID()] = createTestManagerForStatus(t, "dummyServer", []mcp.Tool{{Name: "dummyTool"}})
+	mcpBroker.toolCalls.setDraining([]*config.MCPServer{draining})
+
+	endCall := mcpBroker.ToolCallStarted(draining.ID())
+	status := mcpBroker.ValidateAllServers()
+	require.Len(t, status.Servers, 1)
+	require.True(t, status.Servers[0].Draining)
+	require.Equal(t, 1, status.Servers[0].InFlightToolCalls)
+
+	endCall()
+	status = mcpBroker.ValidateAllServers()
+	require.Zero(t, status.Servers[0].InFlightToolCalls)
+}
+
+func TestFilterTools_Draining(t *testing.T) {
+	servedFrom := func(name, serverID string) mcp.Tool {
+		return mcp.Tool{Name: name, Meta: &mcp.Meta{AdditionalFields: map[string]any{"kuadrant/id": serverID}}}
+	}
+	draining := &config.MCPServer{Name: "old", ToolPrefix: "old_", Draining: true}
+	serving := &config.MCPServer{Name: "new", ToolPrefix: "new_"}
+	mcpBroker := &mcpBrokerImpl{logger: slog.Default()}
+	mcpBroker.toolCalls.setDraining([]*config.MCPServer{draining, serving})
+
+	tools, _ := mcpBroker.filterTools(http.Header{}, []mcp.Tool{
+		servedFrom("old_search", string(draining.ID())),
+		servedFrom("new_search", string(serving.ID())),
+	})
+	require.Len(t, tools, 1)
+	require.Equal(t, "new_search", tools[0].Name)
+
+	// the tools are listed again if the server stops draining
+	mcpBroker.toolCalls.setDraining([]*config.MCPServer{serving})
+	tools, _ = mcpBroker.filterTools(http.Header{}, []mcp.Tool{
+		servedFrom("old_search", string(draining.ID())),
+		servedFrom("new_search", string(serving.ID())),
+	})
+	require.Len(t, tools, 2)
+}
@@ -0,0 +1,90 @@
+package upstream
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ReasonCatalogFull is reported when registering the upstream's tools would take the gateway over its catalog limit
+const ReasonCatalogFull = "CatalogFull"
+
+// CatalogLimit caps the total number of tools the gateway serves across all upstream MCP servers. It is shared by
+// every manager. Servers are admitted first come first served: an upstream whose new tools would take the catalog
+// over the limit is held back and tools already registered by other upstreams are never evicted to make room. Room
+// that frees up goes to the held back upstreams in name order, so which of them is admitted does not depend on
+// which checks first
+type CatalogLimit struct {
+	// mu is held while a manager checks the catalog size and updates the gateway so concurrent syncs cannot both
+	// claim the remaining room
+	mu       sync.Mutex
+	maxTools int
+	// heldBack is how many tools each held back upstream would add to the catalog, by upstream name
+	heldBack map[string]int
+}
+
+// NewCatalogLimit creates a CatalogLimit allowing at most maxTools tools across all upstream MCP servers
+func NewCatalogLimit(maxTools int) *CatalogLimit {
+	return &CatalogLimit{maxTools: maxTools, heldBack: map[string]int{}}
+}
+
+// lock holds the limit until the returned function is called. It is safe to call on a nil limit
+func (cl *CatalogLimit) lock() func() {
+	if cl == nil {
+		return func() {}
+	}
+	cl.mu.Lock()
+	return cl.mu.Unlock
+}
+
+// admit checks the catalog stays within the limit after the named upstream removes and adds tools. Tools taking over a
+// tool of the same name do not grow the catalog and an update that does not grow the catalog is always admitted. An
+// update that fits is still held back when the room is needed by a held back upstream whose name sorts first. The
+// limit must be held
+func (cl *CatalogLimit) admit(gatewayServer ToolsAdderDeleter, name string, toAdd []server.ServerTool, removing int) error {
+	if cl == nil {
+		return nil
+	}
+	gatewayServerTools := gatewayServer.ListTools()
+	adding := 0
+	for _, tool := range toAdd {
+		if _, ok := gatewayServerTools[tool.Tool.Name]; !ok {
+			adding++
+		}
+	}
+	if adding <= removing {
+		delete(cl.heldBack, name)
+		return nil
+	}
+	growth := adding - removing
+	total := len(gatewayServerTools) + growth
+	if total > cl.maxTools {
+		cl.heldBack[name] = growth
+		return fmt.Errorf("catalog full: adding %d tools would take the gateway to %d tools, above the limit of %d", adding, total, cl.maxTools)
+	}
+	room := cl.maxTools - len(gatewayServerTools)
+	for _, other := range slices.Sorted(maps.Keys(cl.heldBack)) {
+		if other >= name {
+			break
+		}
+		if otherGrowth := cl.heldBack[other]; otherGrowth <= room && otherGrowth+growth > room {
+			cl.heldBack[name] = growth
+			return fmt.Errorf("catalog full: the room for %d tools left below the limit of %d is held for %s, a held back server whose name sorts first", room, cl.maxTools, other)
+		}
+	}
+	delete(cl.heldBack, name)
+	return nil
+}
+
+// forget drops the named upstream from the held back upstreams once its manager stops
+func (cl *CatalogLimit) forget(name string) {
+	if cl == nil {
+		return
+	}
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	delete(cl.heldBack, name)
+}
@@ -5,16 +5,22 @@ package upstream
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"reflect"
+	"net/http"
+	"net/url"
 	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/config"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ToolsAdderDeleter defines the interface for interacting with the gateway directly
@@ -32,8 +38,43 @@ type ToolsAdderDeleter interface {
 const (
 	notificationToolsListChanged = "notifications/tools/list_changed"
 	gatewayServerID              = "kuadrant/id"
+	// gatewayServerPriority is set in the tool meta to the priority of the server when it is not the default
+	gatewayServerPriority = "kuadrant/priority"
+	// gatewayServerCreationTimestamp is set in the tool meta to the creation time of the server when it is known
+	gatewayServerCreationTimestamp = "kuadrant/creationTimestamp"
+	// toolDeprecated is set in the tool meta when the tool has been marked deprecated
+	toolDeprecated = "kuadrant/deprecated"
+	// toolDeprecationMessage is set in the tool meta when a deprecated tool has a deprecation message
+	toolDeprecationMessage = "kuadrant/deprecationMessage"
+	// toolCategories is set in the tool meta to the categories configured for the tool
+	toolCategories = "kuadrant/categories"
+	// toolTimeoutHint can be set in the tool meta by an upstream to advertise how long calls to the tool may take,
+	// either as a duration string such as "5m" or as a number of seconds
+	toolTimeoutHint = "kuadrant/timeout"
 )
 
+// ReasonProtocolViolation is reported when an upstream has been quarantined for repeatedly violating the MCP protocol
+const ReasonProtocolViolation = "ProtocolViolation"
+
+const (
+	// ReasonProtocolMismatch is reported when the upstream negotiated a protocol version the broker does not support
+	ReasonProtocolMismatch = "ProtocolMismatch"
+	// ReasonCapabilityMismatch is reported when the upstream rejects the client capabilities offered by the broker
+	ReasonCapabilityMismatch = "CapabilityMismatch"
+	// ReasonToolConflict is reported when a server of equal priority already serves a tool with the same name
+	ReasonToolConflict = "ToolConflict"
+	// ReasonHealthCheckFailed is reported when the health path of the upstream did not return a 2xx response
+	ReasonHealthCheckFailed = "HealthCheckFailed"
+	// ReasonTransportMismatch is reported when the upstream rejected the Streamable HTTP transport used by the broker
+	ReasonTransportMismatch = "TransportMismatch"
+)
+
+// DefaultProtocolViolationThreshold is the number of consecutive malformed responses before an upstream is quarantined
+const DefaultProtocolViolationThreshold = 3
+
+// errProtocolViolation indicates the upstream returned a response that does not conform to the MCP protocol
+var errProtocolViolation = errors.New("mcp protocol violation")
+
 type eventType int
 
 const (
@@ -49,6 +90,43 @@ type ServerValidationStatus struct {
 	Message       string    `json:"message"`
 	Ready         bool      `json:"ready"`
 	TotalTools    int       `json:"totalTools"`
+	// Reason is a machine readable reason for a not ready status. Empty when there is no specific reason
+	Reason string `json:"reason,omitempty"`
+	// ProtocolVersion is the protocol version negotiated with the upstream during initialize
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	// ShadowedTools are the tools not registered because a higher priority server, or an older server of equal priority,
+	// serves a tool with the same name
+	ShadowedTools []string `json:"shadowedTools,omitempty"`
+	// ConflictingTools are the tools rejected because a server of equal priority, created at the same time or at an
+	// unknown time, serves a tool with the same name
+	ConflictingTools []string `json:"conflictingTools,omitempty"`
+	// ConflictingServers are the ids of the servers serving the shadowed or conflicting tools
+	ConflictingServers []string `json:"conflictingServers,omitempty"`
+	// Draining is true while the server's tools are not listed so calls in flight can complete before it is removed
+	Draining bool `json:"draining,omitempty"`
+	// InFlightToolCalls is the number of tool calls routed to the server through this broker that have not completed
+	InFlightToolCalls int `json:"inFlightToolCalls,omitempty"`
+	// ConfigGeneration is the generation of the server config the broker has loaded. 0 when the config has no generation
+	ConfigGeneration int64 `json:"configGeneration,omitempty"`
+	// ConfigLoaded is when the broker loaded the config generation
+	ConfigLoaded time.Time `json:"configLoaded,omitzero"`
+}
+
+// toolConflictError reports tools rejected because a server of equal priority serves a tool with the same name, or
+// because more than one tool of the server is served under the same name
+type toolConflictError struct {
+	tools      []string
+	servers    []string
+	sameServer bool
+}
+
+func (e *toolConflictError) Error() string {
+	if e.sameServer {
+		return fmt.Sprintf("conflicting tools discovered. tools %s are each served for more than one upstream tool, check the tool aliases",
+			strings.Join(e.tools, ", "))
+	}
+	return fmt.Sprintf("conflicting tools discovered. tools %s conflict with servers %s of equal priority",
+		strings.Join(e.tools, ", "), strings.Join(e.servers, ", "))
 }
 
 // MCP defines the interface for the manager to interact with an MCP server
@@ -56,14 +134,18 @@ type MCP interface {
 	GetName() string
 	SupportsToolsListChanged() bool
 	GetConfig() config.MCPServer
+	SetCredential(credential string)
 	ID() config.UpstreamMCPID
 	GetPrefix() string
+	ServedToolName(tool string) string
+	UpstreamToolName(served string) (string, bool)
 	Connect(context.Context, func()) error
 	Disconnect() error
 	ListTools(context.Context, mcp.ListToolsRequest) (*mcp.ListToolsResult, error)
 	OnNotification(func(notification mcp.JSONRPCNotification))
 	OnConnectionLost(func(err error))
 	Ping(context.Context) error
+	ProtocolInfo() *mcp.InitializeResult
 }
 
 // MCPManager manages a single backend MCPServer for the broker. It does not act on behalf of clients. It is the only thing that should be connecting to the MCP Server for the broker. It handles tools updates, disconnection, notifications, liveness checks and updating the status for the MCP server. It is responsible for adding and removing tools to the broker. It is intended to be long lived and have 1:1 relationship with a backend MCP server.
@@ -82,6 +164,9 @@ type MCPManager struct {
 	toolsMap map[string]mcp.Tool
 	//servedToolsMap is a map of the served tools names (including prefix if any)
 	servedToolsMap map[string]mcp.Tool
+	// shadowedTools are the served names of tools not registered because a higher priority or, at equal priority, an
+	// older server serves them
+	shadowedTools []string
 	// toolsLock protects tools, serverTools
 	toolsLock sync.RWMutex
 
@@ -90,20 +175,95 @@ type MCPManager struct {
 	stopOnce sync.Once     // ensures Stop() is only executed once
 	done     chan struct{} // triggers the exit of the select and routine
 	status   ServerValidationStatus
+
+	// protocolViolations counts consecutive malformed responses from the upstream
+	protocolViolations int
+	// protocolViolationThreshold is the number of consecutive violations before the upstream is quarantined
+	protocolViolationThreshold int
+	// quarantined is set when the upstream's tools have been withdrawn due to repeated protocol violations.
+	// a quarantined upstream is re-probed on every tick and released after a well formed response
+	quarantined bool
+
+	// onSynced is called after each successful sync of tools with the gateway
+	onSynced func()
+
+	// acceptedProtocolVersions limits the protocol versions accepted from the upstream. When empty any version the client can negotiate is accepted
+	acceptedProtocolVersions []string
+
+	// catalogLimit if set caps the total number of tools served by the gateway across all managers sharing it
+	catalogLimit *CatalogLimit
+
+	// unavailableGrace is how long the upstream may be unreachable before its tools are removed from the gateway
+	unavailableGrace time.Duration
+	// unreachableSince is when the upstream first failed to connect or ping. Zero while it is reachable
+	unreachableSince time.Time
+	// unavailable is set while the upstream is unreachable and can be read while the manager is running
+	unavailable atomic.Bool
+
+	// lastDeepCheck is when the upstream last passed a full MCP connect and ping
+	lastDeepCheck time.Time
+	// deepCheckInterval is how often an upstream with a health path is checked with MCP rather than its health path
+	deepCheckInterval time.Duration
+	// healthClient polls the health path of the upstream
+	healthClient *http.Client
+
+	// connectRetry controls how failed connection attempts are retried before the upstream is reported failed
+	connectRetry ConnectRetry
+
+	// credential is the credential the current connection was established with
+	credential string
+	// credentialChanged triggers a check as soon as the credential is rotated rather than on the next tick
+	credentialChanged chan struct{}
+
+	// loadedConfig is the generation of the server config the manager is running with
+	loadedConfig atomic.Pointer[loadedConfig]
+}
+
+// loadedConfig records a generation of the server config and when the broker loaded it
+type loadedConfig struct {
+	generation int64
+	loaded     time.Time
+}
+
+// ConnectRetry configures the exponential backoff between attempts to connect to an upstream
+type ConnectRetry struct {
+	// Attempts is the number of connection attempts made before the upstream is reported failed. 1 or less disables retries
+	Attempts int
+	// BaseDelay is the delay before the first retry. It doubles after each retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries. 0 leaves the delay uncapped
+	MaxDelay time.Duration
 }
 
 // DefaultTickerInterval is the default interval for backend health checks
 const DefaultTickerInterval = time.Minute * 1
 
+// DefaultDeepCheckInterval is the default interval between full MCP checks of an upstream with a health path
+const DefaultDeepCheckInterval = time.Minute * 5
+
+// healthCheckTimeout bounds a single poll of an upstream health path
+const healthCheckTimeout = time.Second * 5
+
 // NewUpstreamMCPManager creates a new MCPManager for managing a single upstream MCP server.
 // The addTools and removeTools callbacks are used to update the gateway's tool registry.
-// The tickerInterval controls how often the manager checks backend health (use 0 for default).
+// The tickerInterval controls how often the manager checks backend health (use 0 for default). The health check
+// interval of the upstream's config overrides it.
 func NewUpstreamMCPManager(upstream MCP, gatewaySever ToolsAdderDeleter, logger *slog.Logger, tickerInterval time.Duration) *MCPManager {
+	cfg := upstream.GetConfig()
+	if interval, err := cfg.CheckInterval(); err != nil {
+		logger.Warn("ignoring health check interval", "upstream mcp server", upstream.ID(), "error", err)
+	} else if interval > 0 {
+		tickerInterval = interval
+	}
 	if tickerInterval <= 0 {
 		tickerInterval = DefaultTickerInterval
 	}
-
-	return &MCPManager{
+	healthClient := &http.Client{Timeout: healthCheckTimeout}
+	// an invalid tls config is reported when the upstream connects
+	if upstreamTransport, err := cfg.Transport(); err == nil && upstreamTransport != nil {
+		healthClient.Transport = upstreamTransport
+	}
+	man := &MCPManager{
 		MCP:            upstream,
 		gatewayServer:  gatewaySever,
 		tickerInterval: tickerInterval,
@@ -113,6 +273,66 @@ func NewUpstreamMCPManager(upstream MCP, gatewaySever ToolsAdderDeleter, logger
 		toolsMap:       map[string]mcp.Tool{},
 		servedToolsMap: map[string]mcp.Tool{},
 		serverTools:    []server.ServerTool{},
+
+		credential:        cfg.Credential,
+		credentialChanged: make(chan struct{}, 1),
+
+		protocolViolationThreshold: DefaultProtocolViolationThreshold,
+		deepCheckInterval:          DefaultDeepCheckInterval,
+		healthClient:               healthClient,
+	}
+	man.loadedConfig.Store(&loadedConfig{generation: cfg.Generation, loaded: time.Now()})
+	man.claimMetrics()
+	return man
+}
+
+// SetConfigGeneration records that the broker has loaded a new generation of the server config that did not need
+// the manager to be replaced
+func (man *MCPManager) SetConfigGeneration(generation int64) {
+	if current := man.loadedConfig.Load(); current != nil && current.generation == generation {
+		return
+	}
+	man.loadedConfig.Store(&loadedConfig{generation: generation, loaded: time.Now()})
+}
+
+// OnSynced registers a callback that is called after each successful sync of tools with the gateway.
+// It must be set before Start is called
+func (man *MCPManager) OnSynced(fn func()) {
+	man.onSynced = fn
+}
+
+// SetAcceptedProtocolVersions limits the protocol versions the manager accepts from the upstream during initialize.
+// It must be set before Start is called
+func (man *MCPManager) SetAcceptedProtocolVersions(versions []string) {
+	man.acceptedProtocolVersions = versions
+}
+
+// SetCatalogLimit caps the total number of tools served by the gateway across all managers sharing the limit.
+// It must be set before Start is called
+func (man *MCPManager) SetCatalogLimit(limit *CatalogLimit) {
+	man.catalogLimit = limit
+}
+
+// SetUnavailableGrace keeps the upstream's tools in the gateway until it has been unreachable for longer than grace,
+// so a brief network blip does not withdraw tools only for them to be added back on the next check.
+// It must be set before Start is called
+func (man *MCPManager) SetUnavailableGrace(grace time.Duration) {
+	man.unavailableGrace = grace
+}
+
+// SetConnectRetry retries failed connection attempts with exponential backoff so an upstream that recovers within the
+// backoff window is never reported failed. It must be set before Start is called
+func (man *MCPManager) SetConnectRetry(retry ConnectRetry) {
+	man.connectRetry = retry
+}
+
+// UpdateCredential rotates the credential used to authenticate with the upstream. The manager reconnects with the
+// new credential on its next check, which is triggered straight away when the manager is running
+func (man *MCPManager) UpdateCredential(credential string) {
+	man.MCP.SetCredential(credential)
+	select {
+	case man.credentialChanged <- struct{}{}:
+	default:
 	}
 }
 
@@ -136,6 +356,9 @@ func (man *MCPManager) Start(ctx context.Context) {
 		case <-man.ticker.C:
 			man.logger.Debug("health check tick", "upstream mcp server", man.MCP.ID())
 			man.manage(ctx, eventTypeTimer)
+		case <-man.credentialChanged:
+			man.logger.Debug("credential changed", "upstream mcp server", man.MCP.ID())
+			man.manage(ctx, eventTypeTimer)
 		case <-man.done:
 			man.logger.Debug("shutting down manager", "upstream mcp server", man.MCP.ID())
 			return
@@ -150,10 +373,12 @@ func (man *MCPManager) Stop() {
 	man.stopOnce.Do(func() {
 		man.ticker.Stop()
 		man.removeAllTools()
+		man.catalogLimit.forget(man.MCP.GetName())
 		if err := man.MCP.Disconnect(); err != nil {
 			man.logger.Error("failed to disconnect during stop", "upstream mcp server", man.MCP.ID(), "error", err)
 		}
 		close(man.done)
+		man.deleteMetrics()
 		man.logger.Debug("manager stopped", "upstream mcp server", man.MCP.ID())
 	})
 }
@@ -180,14 +405,43 @@ func (man *MCPManager) registerCallbacks(ctx context.Context) func() {
 func (man *MCPManager) manage(ctx context.Context, event eventType) {
 	man.logger.Debug("managing connection", "upstream mcp server", man.MCP.ID(), "event type", event)
 	var numberOfTools = 0
+	rotated := man.disconnectIfCredentialRotated()
+	// between full MCP checks an upstream with a health path is only polled over HTTP
+	if event == eventTypeTimer && !rotated && man.healthPathCheckDue() {
+		if err := man.checkHealthPath(ctx); err != nil {
+			err = fmt.Errorf("upstream mcp failed health check server %s removing tools : %w", man.MCP.ID(), err)
+			man.logger.Error("health check failed", "upstream mcp server", man.MCP.ID(), "error", err)
+			man.removeUnreachableTools()
+			_ = man.MCP.Disconnect()
+			man.setStatus(err, numberOfTools)
+			man.status.Reason = ReasonHealthCheckFailed
+			return
+		}
+		man.logger.Debug("health check passed", "upstream mcp server", man.MCP.ID())
+		return
+	}
 	// during connect the client will validate the protocol. So we don't have a separate validate requirement currently. If a client already exists it will be re-used.
 	man.logger.Debug("attempting to connect", "upstream mcp server", man.MCP.ID())
-	if err := man.MCP.Connect(ctx, man.registerCallbacks(ctx)); err != nil {
+	if err := man.connect(ctx); err != nil {
 		err = fmt.Errorf("failed to connect to upstream mcp %s removing tools : %w", man.MCP.ID(), err)
-		man.removeAllTools()
+		reason := handshakeFailureReason(err)
+		if reason == "" {
+			man.removeUnreachableTools()
+		} else {
+			man.removeAllTools()
+		}
 		// we call disconnect here as we may have connected but failed to initialize
 		_ = man.MCP.Disconnect()
 		man.setStatus(err, numberOfTools)
+		man.status.Reason = reason
+		return
+	}
+	if err := man.validateProtocolVersion(); err != nil {
+		err = fmt.Errorf("failed to connect to upstream mcp %s removing tools : %w", man.MCP.ID(), err)
+		man.removeAllTools()
+		_ = man.MCP.Disconnect()
+		man.setStatus(err, numberOfTools)
+		man.status.Reason = ReasonProtocolMismatch
 		return
 	}
 	// there may be an active client so we also ping
@@ -195,13 +449,19 @@ func (man *MCPManager) manage(ctx context.Context, event eventType) {
 		// if we fail to ping we disconnect to ensure a fresh connection next time around
 		err = fmt.Errorf("upstream mcp failed to ping server %s removing tools : %w", man.MCP.ID(), err)
 		man.logger.Error("ping failed", "upstream mcp server", man.MCP.ID(), "error", err)
-		man.removeAllTools()
+		man.removeUnreachableTools()
 		_ = man.MCP.Disconnect()
 		man.setStatus(err, numberOfTools)
 		return
 	}
+	// always fetch after an outage as the tools kept during the unavailable grace may have changed. A new credential
+	// may also be granted a different set of tools
+	recovered := !man.unreachableSince.IsZero()
+	man.unreachableSince = time.Time{}
+	man.unavailable.Store(false)
+	man.lastDeepCheck = time.Now()
 
-	if !man.shouldFetchTools(event) {
+	if !recovered && !rotated && !man.shouldFetchTools(event) {
 		man.logger.Debug("not fetching tools", "event", event, "upstream mcp server", man.MCP.ID(), "waiting for notification", notificationToolsListChanged)
 		return
 	}
@@ -211,50 +471,157 @@ func (man *MCPManager) manage(ctx context.Context, event eventType) {
 	if err != nil {
 		err = fmt.Errorf("upstream mcp failed to list tools server %s : %w", man.MCP.ID(), err)
 		man.logger.Error("failed to list tools", "upstream mcp server", man.MCP.ID(), "error", err)
+		if isProtocolViolation(err) {
+			man.recordProtocolViolation(err)
+			return
+		}
+		man.setStatus(err, numberOfTools)
+		return
+	}
+	if man.quarantined {
+		man.logger.Info("upstream returned a well formed response, releasing from quarantine", "upstream mcp server", man.MCP.ID())
+	}
+	man.protocolViolations = 0
+	man.quarantined = false
+	if conflicts := man.servedNameConflicts(fetched); len(conflicts) > 0 {
+		err := fmt.Errorf("upstream mcp failed to add tools to gateway %s : %w", man.MCP.ID(),
+			&toolConflictError{tools: conflicts, servers: []string{string(man.MCP.ID())}, sameServer: true})
+		man.logger.Error("tool conflict detected", "upstream mcp server", man.MCP.ID(), "error", err)
 		man.setStatus(err, numberOfTools)
 		return
 	}
 	// always compare the tools without prefix
-	toAdd, toRemove := man.diffTools(current, fetched)
-	if err := man.findToolConflicts(toAdd); err != nil {
+	toAdd, removed := man.diffTools(current, fetched)
+	man.logToolDiff(toAdd, removed)
+	// only remove tools this server owns so a tool taken over by a higher priority server is left in place
+	toRemove := man.ownedToolNames(removed)
+	// tools kept from the last sync that this server does not own are contested again so they are registered once
+	// the server shadowing them no longer offers them
+	toAdd = append(toAdd, man.unownedTools(current, fetched)...)
+	toAdd, shadowed, err := man.resolveToolConflicts(toAdd)
+	if err != nil {
 		err = fmt.Errorf("upstream mcp failed to add tools to gateway %s : %w", man.MCP.ID(), err)
 		man.logger.Error("tool conflict detected", "upstream mcp server", man.MCP.ID(), "error", err)
 		man.setStatus(err, numberOfTools)
 		return
 	}
+	unlockCatalog := man.catalogLimit.lock()
+	if err := man.catalogLimit.admit(man.gatewayServer, man.MCP.GetName(), toAdd, len(toRemove)); err != nil {
+		unlockCatalog()
+		err = fmt.Errorf("upstream mcp failed to add tools to gateway %s : %w", man.MCP.ID(), err)
+		man.logger.Error("catalog limit reached", "upstream mcp server", man.MCP.ID(), "error", err)
+		man.setStatus(err, numberOfTools)
+		man.status.Reason = ReasonCatalogFull
+		return
+	}
 	man.toolsLock.Lock()
 	man.tools = fetched
+	man.recordToolCount()
 	numberOfTools = len(fetched)
+	// serverTools will have the prefix if one is set
+	man.updateGatewayTools(toAdd, toRemove)
+
+	// rebuild our internal tools
+	man.serverTools = slices.DeleteFunc(man.serverTools, func(tool server.ServerTool) bool {
+		return slices.Contains(removed, tool.Tool.Name) || slices.Contains(shadowed, tool.Tool.Name) ||
+			slices.ContainsFunc(toAdd, func(added server.ServerTool) bool { return added.Tool.Name == tool.Tool.Name })
+	})
+
+	man.serverTools = append(man.serverTools, toAdd...)
+	man.shadowedTools = shadowed
 	// set a tools map for quick look up by other functions
 	man.toolsMap = map[string]mcp.Tool{}
 	man.servedToolsMap = map[string]mcp.Tool{}
-	// we always use any prefix here as it is what the client will call
+	// we always use the served name here as it is what the client will call
 	for _, newTool := range fetched {
 		man.toolsMap[newTool.Name] = newTool
-		toolName := prefixedName(man.MCP.GetPrefix(), newTool.Name)
-		man.servedToolsMap[toolName] = newTool
+		toolName := man.MCP.ServedToolName(newTool.Name)
+		if slices.ContainsFunc(man.serverTools, func(tool server.ServerTool) bool { return tool.Tool.Name == toolName }) {
+			man.servedToolsMap[toolName] = newTool
+		}
 	}
-	// serverTools will have the prefix if one is set
-	man.logger.Debug("updating gateway tools", "upstream mcp server", man.MCP.ID(), "adding", len(toAdd), "removing", len(toRemove))
-	if len(toRemove) > 0 {
-		man.gatewayServer.DeleteTools(toRemove...)
+	man.logger.Debug("internal tools", "upstream mcp server", man.MCP.ID(), "total", len(man.serverTools))
+	man.toolsLock.Unlock()
+	unlockCatalog()
+	man.setStatus(nil, numberOfTools)
+	if man.onSynced != nil {
+		man.onSynced()
 	}
+}
+
+// updateGatewayTools applies the tools added and removed in a manage cycle to the gateway. New tools are added before
+// removed ones are deleted so a concurrent tools/list never sees fewer tools than before or after the update. Tools
+// that are unchanged are in neither list so they are never removed and re-added and keep their existing handler.
+// toolsLock must be held so the gateway update and the manager's own copy of its tools change together
+func (man *MCPManager) updateGatewayTools(toAdd []server.ServerTool, toRemove []string) {
+	man.logger.Debug("updating gateway tools", "upstream mcp server", man.MCP.ID(), "adding", len(toAdd), "removing", len(toRemove))
 	if len(toAdd) > 0 {
 		man.gatewayServer.AddTools(toAdd...)
 	}
+	if len(toRemove) > 0 {
+		man.gatewayServer.DeleteTools(toRemove...)
+	}
+}
 
-	// rebuild our internal tools
-	man.serverTools = slices.DeleteFunc(man.serverTools, func(tool server.ServerTool) bool {
-		return slices.Contains(toRemove, tool.Tool.Name)
-	})
+// connect connects to the upstream, retrying failed attempts with exponential backoff. Handshake failures are not
+// retried as they do not recover without a change to the upstream. Retries stop when the context is cancelled or the
+// manager is stopped
+func (man *MCPManager) connect(ctx context.Context) error {
+	delay := man.connectRetry.BaseDelay
+	for attempt := 1; ; attempt++ {
+		upstreamConnectAttempts.With(man.metricLabels()).Inc()
+		spanCtx, span := man.startSpan(ctx, "upstream.Connect")
+		span.SetAttributes(attribute.Int("mcp.connect.attempt", attempt))
+		err := man.MCP.Connect(spanCtx, man.registerCallbacks(ctx))
+		endSpan(span, err)
+		if err != nil {
+			upstreamConnectFailures.With(man.metricLabels()).Inc()
+		}
+		if err == nil || attempt >= man.connectRetry.Attempts || handshakeFailureReason(err) != "" {
+			return err
+		}
+		// disconnect so the next attempt starts with a fresh client
+		_ = man.MCP.Disconnect()
+		man.logger.Debug("connect failed, retrying", "upstream mcp server", man.MCP.ID(), "attempt", attempt, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-man.done:
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+		if man.connectRetry.MaxDelay > 0 && delay > man.connectRetry.MaxDelay {
+			delay = man.connectRetry.MaxDelay
+		}
+	}
+}
 
-	man.serverTools = append(man.serverTools, toAdd...)
-	man.logger.Debug("internal tools", "upstream mcp server", man.MCP.ID(), "total", len(man.serverTools))
-	man.toolsLock.Unlock()
-	man.setStatus(nil, numberOfTools)
+// disconnectIfCredentialRotated drops the connection when the credential has changed since it was established, so the
+// next connect authenticates with the new credential rather than keeping the stale session
+func (man *MCPManager) disconnectIfCredentialRotated() bool {
+	credential := man.MCP.GetConfig().Credential
+	if credential == man.credential {
+		return false
+	}
+	man.logger.Info("credential changed, reconnecting", "upstream mcp server", man.MCP.ID())
+	_ = man.MCP.Disconnect()
+	man.credential = credential
+	return true
 }
 
 func (man *MCPManager) shouldFetchTools(event eventType) bool {
+	// always re-probe a quarantined server to detect recovery
+	if man.quarantined {
+		return true
+	}
+	// re-fetch while tools are shadowed or have been taken over so they are registered once the other server goes away
+	if man.hasUnownedTools() {
+		return true
+	}
 	// fetch if no support for tools list change notifications
 	if !man.MCP.SupportsToolsListChanged() {
 		return true
@@ -267,58 +634,339 @@ func (man *MCPManager) shouldFetchTools(event eventType) bool {
 	return event == eventTypeTimer && len(man.serverTools) == 0
 }
 
+// healthPathCheckDue checks if only the health path of the upstream needs to be polled as it is ready and passed a full
+// MCP check within the deep check interval
+func (man *MCPManager) healthPathCheckDue() bool {
+	return man.MCP.GetConfig().HealthPath != "" &&
+		man.status.Ready &&
+		time.Since(man.lastDeepCheck) < man.deepCheckInterval &&
+		!man.shouldFetchTools(eventTypeTimer)
+}
+
+// checkHealthPath polls the health path on the host of the upstream URL. Any response other than 2xx is an error
+func (man *MCPManager) checkHealthPath(ctx context.Context) error {
+	cfg := man.MCP.GetConfig()
+	healthURL, err := url.Parse(cfg.ConnectURL())
+	if err != nil {
+		return fmt.Errorf("invalid upstream url %s : %w", cfg.ConnectURL(), err)
+	}
+	healthURL.Path = cfg.HealthPath
+	healthURL.RawQuery = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health check request : %w", err)
+	}
+	resp, err := man.healthClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("health path %s returned status %d", cfg.HealthPath, resp.StatusCode)
+	}
+	return nil
+}
+
+// Unavailable checks if the upstream is currently unreachable
+func (man *MCPManager) Unavailable() bool {
+	return man.unavailable.Load()
+}
+
 // GetStatus returns the current status of the MCP Server
 // no locking is done here as it is expected to be called multiple times
 func (man *MCPManager) GetStatus() ServerValidationStatus {
-	return man.status
+	status := man.status
+	if loaded := man.loadedConfig.Load(); loaded != nil {
+		status.ConfigGeneration = loaded.generation
+		status.ConfigLoaded = loaded.loaded
+	}
+	return status
 }
 
 func (man *MCPManager) setStatus(err error, toolCount int) {
 	man.status.ID = string(man.MCP.ID())
 	man.status.LastValidated = time.Now()
 	man.status.Name = man.MCPName()
+	man.status.Reason = ""
+	man.status.ProtocolVersion = ""
+	man.status.ShadowedTools = nil
+	man.status.ConflictingTools = nil
+	man.status.ConflictingServers = nil
 	if err != nil {
 		man.status.Message = err.Error()
 		man.status.Ready = false
+		var conflictErr *toolConflictError
+		if errors.As(err, &conflictErr) {
+			man.status.Reason = ReasonToolConflict
+			man.status.ConflictingTools = conflictErr.tools
+			man.status.ConflictingServers = conflictErr.servers
+		}
+		// report the version the upstream advertised so operators can see why it was rejected
+		var versionErr mcp.UnsupportedProtocolVersionError
+		if errors.As(err, &versionErr) {
+			man.status.ProtocolVersion = versionErr.Version
+		}
 		return
 	}
 	man.status.TotalTools = toolCount
 	man.status.Ready = true
 	man.status.Message = fmt.Sprintf("server added successfully. Total tools added %d", len(man.serverTools))
+	if len(man.shadowedTools) > 0 {
+		man.status.ShadowedTools = slices.Clone(man.shadowedTools)
+		man.status.ConflictingServers = man.toolOwners(man.shadowedTools)
+		man.status.Message = fmt.Sprintf("%s. Tools shadowed by higher priority or older servers %v", man.status.Message, man.shadowedTools)
+	}
+	if info := man.MCP.ProtocolInfo(); info != nil {
+		man.status.ProtocolVersion = info.ProtocolVersion
+		man.status.Message = fmt.Sprintf("%s. Protocol version %s", man.status.Message, info.ProtocolVersion)
+	}
 }
 
-func (man *MCPManager) findToolConflicts(mcpTools []server.ServerTool) error {
+// validateProtocolVersion checks the version negotiated during initialize is one of the accepted protocol versions
+func (man *MCPManager) validateProtocolVersion() error {
+	if len(man.acceptedProtocolVersions) == 0 {
+		return nil
+	}
+	info := man.MCP.ProtocolInfo()
+	if info == nil {
+		return nil
+	}
+	if !slices.Contains(man.acceptedProtocolVersions, info.ProtocolVersion) {
+		return fmt.Errorf("protocol version not in accepted versions %v : %w", man.acceptedProtocolVersions, mcp.UnsupportedProtocolVersionError{Version: info.ProtocolVersion})
+	}
+	return nil
+}
+
+// recordProtocolViolation counts a malformed response and quarantines the upstream once the threshold is reached.
+// a quarantined upstream has its tools withdrawn from the gateway until it returns a well formed response
+func (man *MCPManager) recordProtocolViolation(err error) {
+	man.protocolViolations++
+	if man.protocolViolations < man.protocolViolationThreshold {
+		man.setStatus(err, 0)
+		return
+	}
+	if !man.quarantined {
+		man.logger.Error("quarantining upstream after repeated protocol violations", "upstream mcp server", man.MCP.ID(), "violations", man.protocolViolations)
+		man.quarantined = true
+	}
+	man.removeAllTools()
+	man.setStatus(fmt.Errorf("quarantined after %d consecutive protocol violations: %w", man.protocolViolations, err), 0)
+	man.status.Reason = ReasonProtocolViolation
+}
+
+// handshakeFailureReason returns the not ready reason for a failed initialize handshake, or empty if the failure was not a mismatch
+func handshakeFailureReason(err error) string {
+	var capErr *CapabilityMismatchError
+	var transportErr *TransportMismatchError
+	switch {
+	case errors.As(err, &capErr):
+		return ReasonCapabilityMismatch
+	case errors.As(err, &transportErr):
+		return ReasonTransportMismatch
+	case errors.Is(err, mcp.UnsupportedProtocolVersionError{}):
+		return ReasonProtocolMismatch
+	}
+	return ""
+}
+
+// isProtocolViolation checks if the error is caused by a malformed response rather than connectivity
+func isProtocolViolation(err error) bool {
+	if errors.Is(err, errProtocolViolation) || errors.Is(err, mcp.ErrParseError) || errors.Is(err, mcp.ErrInvalidRequest) {
+		return true
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
+
+// validateListToolsResult checks the tools/list response has the fields required by the MCP spec
+func validateListToolsResult(res *mcp.ListToolsResult) error {
+	if res == nil {
+		return fmt.Errorf("%w: empty tools/list result", errProtocolViolation)
+	}
+	seen := make(map[string]struct{}, len(res.Tools))
+	for i, tool := range res.Tools {
+		if tool.Name == "" {
+			return fmt.Errorf("%w: tool at index %d has no name", errProtocolViolation, i)
+		}
+		if _, ok := seen[tool.Name]; ok {
+			return fmt.Errorf("%w: duplicate tool name %q", errProtocolViolation, tool.Name)
+		}
+		seen[tool.Name] = struct{}{}
+	}
+	return nil
+}
+
+// resolveToolConflicts splits the tools into those to register and the names of those shadowed by a tool of the same
+// name from a higher priority server. A tool registered by a lower priority server is taken over. Between servers of
+// equal priority the server created first wins in the same way, and a tool is only a conflict when the creation times
+// are equal or not known
+func (man *MCPManager) resolveToolConflicts(mcpTools []server.ServerTool) ([]server.ServerTool, []string, error) {
 	gatewayServerTools := man.gatewayServer.ListTools()
-	var conflictingToolNames []string
+	conf := man.MCP.GetConfig()
+	priority, created := conf.Priority, conf.CreationTimestamp
+	admitted := make([]server.ServerTool, 0, len(mcpTools))
+	var shadowedToolNames, conflictingToolNames, conflictingServers []string
 	for _, tool := range mcpTools {
-		for existingToolName, existingToolInfo := range gatewayServerTools {
-			existingTool := existingToolInfo.Tool
-			// TODO revisit as this is in the tool definition
-			existingToolID, ok := existingTool.Meta.AdditionalFields[gatewayServerID]
-			if !ok {
-				// should never happen as we are adding every time
-				man.logger.Error("unable to check conflict, tool id is missing", "upstream mcp server", man.MCP.ID())
-				continue
+		existingToolInfo, ok := gatewayServerTools[tool.Tool.GetName()]
+		if !ok {
+			admitted = append(admitted, tool)
+			continue
+		}
+		toolID, ok := ToolServerID(existingToolInfo.Tool)
+		if !ok {
+			// should never happen as we are adding every time
+			man.logger.Error("unable to check conflict, tool id is missing", "upstream mcp server", man.MCP.ID())
+			admitted = append(admitted, tool)
+			continue
+		}
+		existingPriority := toolServerPriority(existingToolInfo.Tool)
+		existingCreated := toolServerCreationTimestamp(existingToolInfo.Tool)
+		creationKnown := created != 0 && existingCreated != 0
+		switch {
+		case toolID == man.MCP.ID():
+			admitted = append(admitted, tool)
+		case priority > existingPriority:
+			man.logger.Debug("tool diff", "upstream mcp server", man.MCP.ID(), "action", "take over", "tool", tool.Tool.GetName(), "reason", "higher priority", "conflicting server", toolID)
+			admitted = append(admitted, tool)
+		case priority < existingPriority:
+			man.logger.Debug("tool diff", "upstream mcp server", man.MCP.ID(), "action", "shadow", "tool", tool.Tool.GetName(), "reason", "lower priority", "conflicting server", toolID)
+			shadowedToolNames = append(shadowedToolNames, tool.Tool.GetName())
+		case creationKnown && created < existingCreated:
+			man.logger.Debug("tool diff", "upstream mcp server", man.MCP.ID(), "action", "take over", "tool", tool.Tool.GetName(), "reason", "created earlier", "conflicting server", toolID)
+			admitted = append(admitted, tool)
+		case creationKnown && created > existingCreated:
+			man.logger.Debug("tool diff", "upstream mcp server", man.MCP.ID(), "action", "shadow", "tool", tool.Tool.GetName(), "reason", "created later", "conflicting server", toolID)
+			shadowedToolNames = append(shadowedToolNames, tool.Tool.GetName())
+		default:
+			man.logger.Debug("tool diff", "upstream mcp server", man.MCP.ID(), "action", "reject", "tool", tool.Tool.GetName(), "reason", "conflict", "conflicting server", toolID)
+			conflictingToolNames = append(conflictingToolNames, tool.Tool.GetName())
+			if !slices.Contains(conflictingServers, string(toolID)) {
+				conflictingServers = append(conflictingServers, string(toolID))
+			}
+		}
+	}
+	if len(conflictingToolNames) > 0 {
+		slices.Sort(conflictingToolNames)
+		slices.Sort(conflictingServers)
+		return nil, nil, &toolConflictError{tools: conflictingToolNames, servers: conflictingServers}
+	}
+	slices.Sort(shadowedToolNames)
+	return admitted, shadowedToolNames, nil
+}
+
+// servedNameConflicts returns the served names rendered for more than one upstream tool, such as an alias equal to the
+// templated name of another tool
+func (man *MCPManager) servedNameConflicts(tools []mcp.Tool) []string {
+	upstreamNames := make(map[string]string, len(tools))
+	var conflicts []string
+	for _, tool := range tools {
+		served := man.MCP.ServedToolName(tool.Name)
+		if upstream, ok := upstreamNames[served]; ok && upstream != tool.Name && !slices.Contains(conflicts, served) {
+			conflicts = append(conflicts, served)
+		}
+		upstreamNames[served] = tool.Name
+	}
+	slices.Sort(conflicts)
+	return conflicts
+}
+
+// ownedToolNames returns the names of the tools the gateway currently serves from this server
+func (man *MCPManager) ownedToolNames(names []string) []string {
+	gatewayServerTools := man.gatewayServer.ListTools()
+	owned := make([]string, 0, len(names))
+	for _, name := range names {
+		if tool, ok := gatewayServerTools[name]; ok {
+			if toolID, ok := ToolServerID(tool.Tool); ok && toolID == man.MCP.ID() {
+				owned = append(owned, name)
 			}
-			toolID, is := existingToolID.(string)
-			if !is {
-				// also should never happen
-				man.logger.Error("unable to check conflict, tool id is not a string", "upstream mcp server", man.MCP.ID(), "type", reflect.TypeOf(existingToolID))
+		}
+	}
+	return owned
+}
+
+// unownedTools returns the tools kept from the last sync that the gateway does not serve from this server, either
+// because they were shadowed or because a higher priority server has since taken them over
+func (man *MCPManager) unownedTools(current, fetched []mcp.Tool) []server.ServerTool {
+	gatewayServerTools := man.gatewayServer.ListTools()
+	kept := make(map[string]bool, len(current))
+	for _, tool := range current {
+		kept[tool.Name] = true
+	}
+	var unowned []server.ServerTool
+	for _, tool := range fetched {
+		if !kept[tool.Name] {
+			continue
+		}
+		if existing, ok := gatewayServerTools[man.MCP.ServedToolName(tool.Name)]; ok {
+			if toolID, ok := ToolServerID(existing.Tool); ok && toolID == man.MCP.ID() {
 				continue
 			}
+		}
+		unowned = append(unowned, man.toolToServerTool(tool))
+	}
+	return unowned
+}
 
-			if existingToolName == tool.Tool.GetName() && toolID != string(man.MCP.ID()) {
-				man.logger.Debug("tool name conflict found", "upstream mcp server", man.MCP.ID(), "existing", existingToolName, "new", tool.Tool.GetName(), "conflicting server", toolID)
-				conflictingToolNames = append(conflictingToolNames, tool.Tool.GetName())
+// hasUnownedTools checks if any tools are shadowed or have been taken over by another server since the last sync
+func (man *MCPManager) hasUnownedTools() bool {
+	man.toolsLock.RLock()
+	defer man.toolsLock.RUnlock()
+	if len(man.shadowedTools) > 0 {
+		return true
+	}
+	gatewayServerTools := man.gatewayServer.ListTools()
+	for _, tool := range man.serverTools {
+		if existing, ok := gatewayServerTools[tool.Tool.Name]; ok {
+			if toolID, ok := ToolServerID(existing.Tool); ok && toolID != man.MCP.ID() {
+				return true
 			}
+		}
+	}
+	return false
+}
 
+// toolOwners returns the sorted ids of the servers the gateway serves the named tools from
+func (man *MCPManager) toolOwners(names []string) []string {
+	gatewayServerTools := man.gatewayServer.ListTools()
+	var owners []string
+	for _, name := range names {
+		if tool, ok := gatewayServerTools[name]; ok {
+			if toolID, ok := ToolServerID(tool.Tool); ok && !slices.Contains(owners, string(toolID)) {
+				owners = append(owners, string(toolID))
+			}
 		}
 	}
-	if len(conflictingToolNames) > 0 {
-		return fmt.Errorf("conflicting tools discovered. conflicting tool names %v", conflictingToolNames)
+	slices.Sort(owners)
+	return owners
+}
+
+// ToolServerID returns the id of the upstream MCP server a gateway tool is served from
+func ToolServerID(tool mcp.Tool) (config.UpstreamMCPID, bool) {
+	if tool.Meta == nil {
+		return "", false
 	}
+	toolID, ok := tool.Meta.AdditionalFields[gatewayServerID].(string)
+	return config.UpstreamMCPID(toolID), ok
+}
 
-	return nil
+// toolServerPriority returns the priority of the upstream MCP server a gateway tool is served from
+func toolServerPriority(tool mcp.Tool) int32 {
+	if tool.Meta == nil {
+		return 0
+	}
+	priority, _ := tool.Meta.AdditionalFields[gatewayServerPriority].(int32)
+	return priority
+}
+
+// toolServerCreationTimestamp returns the creation time of the upstream MCP server a gateway tool is served from, or
+// zero when it is not known
+func toolServerCreationTimestamp(tool mcp.Tool) int64 {
+	if tool.Meta == nil {
+		return 0
+	}
+	created, _ := tool.Meta.AdditionalFields[gatewayServerCreationTimestamp].(int64)
+	return created
 }
 
 // getTools return the existing, and new tools
@@ -327,10 +975,22 @@ func (man *MCPManager) getTools(ctx context.Context) ([]mcp.Tool, []mcp.Tool, er
 	tools := make([]mcp.Tool, len(man.tools))
 	copy(tools, man.tools)
 	man.toolsLock.RUnlock()
-	res, err := man.MCP.ListTools(ctx, mcp.ListToolsRequest{})
+	// an upstream that doesn't advertise the tools capability, such as one only serving prompts or resources, has no
+	// tools to list
+	if info := man.MCP.ProtocolInfo(); info != nil && info.Capabilities.Tools == nil {
+		return tools, []mcp.Tool{}, nil
+	}
+	start := time.Now()
+	spanCtx, span := man.startSpan(ctx, "upstream.ListTools")
+	res, err := man.MCP.ListTools(spanCtx, mcp.ListToolsRequest{})
+	endSpan(span, err)
+	upstreamListToolsDuration.With(man.metricLabels()).Observe(time.Since(start).Seconds())
 	if err != nil {
 		return tools, tools, fmt.Errorf("failed to get tools: %w", err)
 	}
+	if err := validateListToolsResult(res); err != nil {
+		return tools, tools, err
+	}
 	return tools, res.Tools, nil
 }
 
@@ -356,6 +1016,38 @@ func (man *MCPManager) GetServedManagedTool(toolName string) *mcp.Tool {
 	return nil
 }
 
+// ToolTimeout returns the timeout for calls to the served tool. The duration hint in the tool meta is used when
+// present and valid, otherwise fallback
+func (man *MCPManager) ToolTimeout(toolName string, fallback time.Duration) time.Duration {
+	tool := man.GetServedManagedTool(toolName)
+	if tool == nil || tool.Meta == nil {
+		return fallback
+	}
+	hint, ok := tool.Meta.AdditionalFields[toolTimeoutHint]
+	if !ok {
+		return fallback
+	}
+	var timeout time.Duration
+	switch v := hint.(type) {
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			man.logger.Debug("ignoring invalid tool timeout hint", "tool", toolName, "hint", v, "error", err)
+			return fallback
+		}
+		timeout = parsed
+	case float64:
+		timeout = time.Duration(v * float64(time.Second))
+	default:
+		man.logger.Debug("ignoring tool timeout hint of unexpected type", "tool", toolName, "hint", hint)
+		return fallback
+	}
+	if timeout <= 0 {
+		return fallback
+	}
+	return timeout
+}
+
 // SetToolsForTesting sets the tools directly for testing purposes.
 // This bypasses the normal tool discovery flow and should only be used in tests.
 // TODO look to remove the need for this
@@ -363,10 +1055,11 @@ func (man *MCPManager) SetToolsForTesting(tools []mcp.Tool) {
 	man.toolsLock.Lock()
 	defer man.toolsLock.Unlock()
 	man.tools = tools
+	man.recordToolCount()
 	// set a tools map for quick look up by other functions
 	for _, newTool := range tools {
 		man.toolsMap[newTool.Name] = newTool
-		man.servedToolsMap[prefixedName(man.MCP.GetPrefix(), newTool.Name)] = newTool
+		man.servedToolsMap[man.MCP.ServedToolName(newTool.Name)] = newTool
 	}
 }
 
@@ -376,6 +1069,24 @@ func (man *MCPManager) SetStatusForTesting(status ServerValidationStatus) {
 	man.status = status
 }
 
+// removeUnreachableTools removes all tools once the upstream has been unreachable for longer than the unavailable grace.
+// Tools are kept if the server's unavailable policy is KeepTools
+func (man *MCPManager) removeUnreachableTools() {
+	if man.unreachableSince.IsZero() {
+		man.unreachableSince = time.Now()
+	}
+	man.unavailable.Store(true)
+	if cfg := man.MCP.GetConfig(); cfg.KeepToolsWhenUnavailable() {
+		man.logger.Info("upstream unreachable, keeping tools as set by the unavailable policy", "upstream mcp server", man.MCP.ID(), "unreachable since", man.unreachableSince)
+		return
+	}
+	if man.unavailableGrace > 0 && time.Since(man.unreachableSince) < man.unavailableGrace {
+		man.logger.Info("upstream unreachable, keeping tools during unavailable grace", "upstream mcp server", man.MCP.ID(), "unreachable since", man.unreachableSince, "grace", man.unavailableGrace)
+		return
+	}
+	man.removeAllTools()
+}
+
 func (man *MCPManager) removeAllTools() {
 	man.toolsLock.Lock()
 	defer man.toolsLock.Unlock()
@@ -385,17 +1096,39 @@ func (man *MCPManager) removeAllTools() {
 		man.logger.Debug("removing tool from server ", "upstream mcp server", man.MCP.ID(), "tool", tool.Tool.Name)
 		toolsToRemove = append(toolsToRemove, tool.Tool.Name)
 	}
+	// leave tools a higher priority server has taken over in place
+	toolsToRemove = man.ownedToolNames(toolsToRemove)
 	man.serverTools = []server.ServerTool{}
 	man.tools = []mcp.Tool{}
+	man.recordToolCount()
+	man.shadowedTools = nil
 	man.gatewayServer.DeleteTools(toolsToRemove...)
 	man.logger.Debug("removed all tools", "upstream mcp server", man.MCP.ID(), "count", len(toolsToRemove))
 }
 
 func (man *MCPManager) toolToServerTool(newTool mcp.Tool) server.ServerTool {
-	newTool.Name = prefixedName(man.MCP.GetPrefix(), newTool.Name)
-	newTool.Meta = mcp.NewMetaFromMap(map[string]any{
+	meta := map[string]any{
 		gatewayServerID: string(man.MCP.ID()),
-	})
+	}
+	conf := man.MCP.GetConfig()
+	if conf.Priority != 0 {
+		meta[gatewayServerPriority] = conf.Priority
+	}
+	if conf.CreationTimestamp != 0 {
+		meta[gatewayServerCreationTimestamp] = conf.CreationTimestamp
+	}
+	if categories := conf.ToolCategories(newTool.Name); len(categories) > 0 {
+		meta[toolCategories] = categories
+	}
+	if override, ok := conf.ToolOverride(newTool.Name); ok && override.Deprecated {
+		newTool.Description = deprecatedDescription(newTool.Description, override.DeprecationMessage)
+		meta[toolDeprecated] = true
+		if override.DeprecationMessage != "" {
+			meta[toolDeprecationMessage] = override.DeprecationMessage
+		}
+	}
+	newTool.Name = man.MCP.ServedToolName(newTool.Name)
+	newTool.Meta = mcp.NewMetaFromMap(meta)
 	return server.ServerTool{
 		Tool: newTool,
 		Handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -404,6 +1137,18 @@ func (man *MCPManager) toolToServerTool(newTool mcp.Tool) server.ServerTool {
 	}
 }
 
+// logToolDiff records each tool added or removed in a manage cycle and why, to help explain tool changes seen by clients
+func (man *MCPManager) logToolDiff(toAdd []server.ServerTool, toRemove []string) {
+	for _, tool := range toAdd {
+		man.logger.Debug("tool diff", "upstream mcp server", man.MCP.ID(), "action", "add", "tool", tool.Tool.Name, "reason", "new backend tool")
+	}
+	for _, toolName := range toRemove {
+		man.logger.Debug("tool diff", "upstream mcp server", man.MCP.ID(), "action", "remove", "tool", toolName, "reason", "removed backend tool")
+	}
+}
+
+// diffTools returns the tools to add and the served names of the tools to remove. Tools in both sets are left out of
+// both so they stay registered with the gateway untouched
 func (man *MCPManager) diffTools(oldTools, newTools []mcp.Tool) ([]server.ServerTool, []string) {
 	oldToolMap := make(map[string]mcp.Tool)
 	for _, oldTool := range oldTools {
@@ -427,16 +1172,21 @@ func (man *MCPManager) diffTools(oldTools, newTools []mcp.Tool) ([]server.Server
 	for _, oldTool := range oldToolMap {
 		_, ok := newToolMap[oldTool.Name]
 		if !ok {
-			removedTools = append(removedTools, prefixedName(man.MCP.GetPrefix(), oldTool.Name))
+			removedTools = append(removedTools, man.MCP.ServedToolName(oldTool.Name))
 		}
 	}
 
 	return addedTools, removedTools
 }
 
-func prefixedName(toolPrefix, tool string) string {
-	if toolPrefix == "" {
-		return tool
+// deprecatedDescription appends a deprecation note to a tool description
+func deprecatedDescription(description, message string) string {
+	note := "DEPRECATED: this tool will be removed in a future release."
+	if message != "" {
+		note = fmt.Sprintf("%s %s", note, message)
+	}
+	if description == "" {
+		return note
 	}
-	return fmt.Sprintf("%s%s", toolPrefix, tool)
+	return fmt.Sprintf("%s\n\n%s", description, note)
 }
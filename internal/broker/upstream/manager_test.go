@@ -1,18 +1,27 @@
 package upstream
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/config"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // MockMCP implements the MCP interface for testing
@@ -22,12 +31,18 @@ type MockMCP struct {
 	id              config.UpstreamMCPID
 	cfg             *config.MCPServer
 	connectErr      error
+	connectFailures int
+	connectCalls    int
+	disconnectCalls int
 	pingErr         error
+	pingCalls       int
 	tools           []mcp.Tool
 	listToolsErr    error
 	protocolVersion string
 	hasToolsCap     bool
-	connected       bool
+	// noToolsCap leaves the tools capability out of the initialize result
+	noToolsCap bool
+	connected  bool
 }
 
 func (m *MockMCP) GetName() string {
@@ -38,6 +53,10 @@ func (m *MockMCP) GetConfig() config.MCPServer {
 	return *m.cfg
 }
 
+func (m *MockMCP) SetCredential(credential string) {
+	m.cfg.Credential = credential
+}
+
 func (m *MockMCP) ID() config.UpstreamMCPID {
 	return m.id
 }
@@ -46,8 +65,18 @@ func (m *MockMCP) GetPrefix() string {
 	return m.prefix
 }
 
+func (m *MockMCP) ServedToolName(tool string) string {
+	return m.cfg.ServedToolName(tool)
+}
+
+func (m *MockMCP) UpstreamToolName(served string) (string, bool) {
+	return m.cfg.UpstreamToolName(served)
+}
+
 func (m *MockMCP) Connect(_ context.Context, onConnected func()) error {
-	if m.connectErr != nil {
+	m.connectCalls++
+	// connectErr is returned on every attempt unless connectFailures limits it to the first attempts
+	if m.connectErr != nil && (m.connectFailures == 0 || m.connectCalls <= m.connectFailures) {
 		return m.connectErr
 	}
 	m.connected = true
@@ -58,10 +87,11 @@ func (m *MockMCP) Connect(_ context.Context, onConnected func()) error {
 }
 
 func (m *MockMCP) SupportsToolsListChanged() bool {
-	return m.hasToolsCap
+	return m.hasToolsCap && !m.noToolsCap
 }
 
 func (m *MockMCP) Disconnect() error {
+	m.disconnectCalls++
 	m.connected = false
 	return nil
 }
@@ -78,6 +108,7 @@ func (m *MockMCP) OnNotification(_ func(notification mcp.JSONRPCNotification)) {
 func (m *MockMCP) OnConnectionLost(_ func(err error)) {}
 
 func (m *MockMCP) Ping(_ context.Context) error {
+	m.pingCalls++
 	return m.pingErr
 }
 
@@ -86,10 +117,10 @@ func (m *MockMCP) ProtocolInfo() *mcp.InitializeResult {
 		ProtocolVersion: m.protocolVersion,
 		Capabilities:    mcp.ServerCapabilities{},
 	}
-	if m.hasToolsCap {
+	if !m.noToolsCap {
 		result.Capabilities.Tools = &struct {
 			ListChanged bool `json:"listChanged,omitempty"`
-		}{}
+		}{ListChanged: m.hasToolsCap}
 	}
 	return result
 }
@@ -145,6 +176,7 @@ func TestNewUpstreamMCPManager(t *testing.T) {
 	testCases := []struct {
 		name             string
 		interval         time.Duration
+		serverInterval   string
 		expectedInterval time.Duration
 	}{
 		{
@@ -162,11 +194,29 @@ func TestNewUpstreamMCPManager(t *testing.T) {
 			interval:         -1,
 			expectedInterval: DefaultTickerInterval,
 		},
+		{
+			name:             "per-server interval overrides the broker interval",
+			interval:         time.Second * 30,
+			serverInterval:   "5m",
+			expectedInterval: time.Minute * 5,
+		},
+		{
+			name:             "per-server interval overrides the default",
+			serverInterval:   "10s",
+			expectedInterval: time.Second * 10,
+		},
+		{
+			name:             "invalid per-server interval is ignored",
+			interval:         time.Second * 30,
+			serverInterval:   "often",
+			expectedInterval: time.Second * 30,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mock := newMockMCP(tc.name, "")
+			mock.cfg.HealthCheckInterval = tc.serverInterval
 			gateway := newMockToolsAdderDeleter()
 			manager := NewUpstreamMCPManager(mock, gateway, logger, tc.interval)
 			assert.Equal(t, tc.expectedInterval, manager.tickerInterval)
@@ -204,6 +254,26 @@ func TestMCPManager_GetStatus(t *testing.T) {
 	assert.Equal(t, expectedStatus.TotalTools, status.TotalTools)
 }
 
+func TestMCPManager_ConfigGeneration(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mock := newMockMCP("test-server", "test_")
+	mock.cfg.Generation = 3
+	manager := NewUpstreamMCPManager(mock, nil, logger, 0)
+
+	status := manager.GetStatus()
+	assert.Equal(t, int64(3), status.ConfigGeneration)
+	assert.False(t, status.ConfigLoaded.IsZero())
+
+	// loading the same generation again keeps when it was first loaded
+	loaded := status.ConfigLoaded
+	manager.SetConfigGeneration(3)
+	assert.Equal(t, loaded, manager.GetStatus().ConfigLoaded)
+
+	manager.SetConfigGeneration(4)
+	assert.Equal(t, int64(4), manager.GetStatus().ConfigGeneration)
+	assert.False(t, manager.GetStatus().ConfigLoaded.Before(loaded))
+}
+
 func TestMCPManager_GetManagedTools(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	mock := newMockMCP("test-server", "test_")
@@ -243,6 +313,41 @@ func TestMCPManager_GetManagedTools_ReturnsCopy(t *testing.T) {
 	assert.Equal(t, "tool1", original[0].Name)
 }
 
+func TestMCPManager_ToolsMetric(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mock := newMockMCP("metrics-server", "metrics_")
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+	labels := prometheus.Labels{"server": "metrics-server", "prefix": "metrics_"}
+
+	manager.SetToolsForTesting([]mcp.Tool{{Name: "tool1"}, {Name: "tool2"}})
+	assert.Equal(t, float64(2), testutil.ToFloat64(upstreamTools.With(labels)))
+
+	manager.SetToolsForTesting([]mcp.Tool{{Name: "tool1"}})
+	assert.Equal(t, float64(1), testutil.ToFloat64(upstreamTools.With(labels)))
+
+	// the series is removed once the manager is stopped
+	manager.Stop()
+	assert.False(t, upstreamTools.Delete(labels))
+}
+
+func TestMCPManager_DeleteMetrics_Replaced(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	gateway := newMockToolsAdderDeleter()
+	labels := prometheus.Labels{"server": "replaced-server", "prefix": "replaced_"}
+	previous := NewUpstreamMCPManager(newMockMCP("replaced-server", "replaced_"), gateway, logger, 0)
+	previous.SetToolsForTesting([]mcp.Tool{{Name: "tool1"}})
+
+	// a replacement with the same labels owns the series so stopping the previous manager leaves them in place
+	replacement := NewUpstreamMCPManager(newMockMCP("replaced-server", "replaced_"), gateway, logger, 0)
+	replacement.SetToolsForTesting([]mcp.Tool{{Name: "tool1"}, {Name: "tool2"}})
+	previous.Stop()
+	assert.Equal(t, float64(2), testutil.ToFloat64(upstreamTools.With(labels)))
+
+	replacement.Stop()
+	assert.False(t, upstreamTools.Delete(labels))
+}
+
 func TestMCPManager_GetServedManagedTool(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
@@ -297,6 +402,42 @@ func TestMCPManager_GetServedManagedTool(t *testing.T) {
 	}
 }
 
+func TestMCPManager_ToolTimeout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	const serverDefault = 30 * time.Second
+	withHint := func(name string, hint any) mcp.Tool {
+		return mcp.Tool{Name: name, Meta: &mcp.Meta{AdditionalFields: map[string]any{toolTimeoutHint: hint}}}
+	}
+
+	mock := newMockMCP("test-server", "prefix_")
+	manager := NewUpstreamMCPManager(mock, newMockToolsAdderDeleter(), logger, 0)
+	manager.SetToolsForTesting([]mcp.Tool{
+		withHint("long_running", "5m"),
+		withHint("seconds", float64(90)),
+		withHint("invalid", "soon"),
+		withHint("negative", "-1s"),
+		{Name: "plain"},
+	})
+
+	testCases := []struct {
+		name     string
+		tool     string
+		expected time.Duration
+	}{
+		{name: "duration hint overrides the server default", tool: "prefix_long_running", expected: 5 * time.Minute},
+		{name: "hint in seconds", tool: "prefix_seconds", expected: 90 * time.Second},
+		{name: "invalid hint uses the server default", tool: "prefix_invalid", expected: serverDefault},
+		{name: "negative hint uses the server default", tool: "prefix_negative", expected: serverDefault},
+		{name: "no hint uses the server default", tool: "prefix_plain", expected: serverDefault},
+		{name: "unknown tool uses the server default", tool: "prefix_unknown", expected: serverDefault},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, manager.ToolTimeout(tc.tool, serverDefault))
+		})
+	}
+}
+
 func TestMCPManager_setStatus(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
@@ -345,10 +486,11 @@ func TestMCPManager_setStatus(t *testing.T) {
 	}
 }
 
-func TestPrefixedName(t *testing.T) {
+func TestMCPManager_ServedToolName(t *testing.T) {
 	testCases := []struct {
 		name     string
 		prefix   string
+		template string
 		toolName string
 		expected string
 	}{
@@ -370,12 +512,21 @@ func TestPrefixedName(t *testing.T) {
 			toolName: "mytool",
 			expected: "my_prefix_mytool",
 		},
+		{
+			name:     "template with suffix",
+			prefix:   "server",
+			template: "{tool}_{prefix}",
+			toolName: "tool",
+			expected: "tool_server",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := prefixedName(tc.prefix, tc.toolName)
-			assert.Equal(t, tc.expected, result)
+			mock := newMockMCP("test-server", tc.prefix)
+			mock.cfg.ToolNameTemplate = tc.template
+			manager := NewUpstreamMCPManager(mock, nil, slog.New(slog.DiscardHandler), 0)
+			assert.Equal(t, tc.expected, manager.MCP.ServedToolName(tc.toolName))
 		})
 	}
 }
@@ -395,6 +546,10 @@ func TestMCPManager_toolToServerTool(t *testing.T) {
 	assert.Equal(t, "prefix_mytool", serverTool.Tool.Name)
 	assert.Equal(t, "A test tool", serverTool.Tool.Description)
 
+	// an alias replaces the prefixed name
+	mock.cfg.ToolAliases = map[string]string{"mytool": "renamed"}
+	assert.Equal(t, "renamed", manager.toolToServerTool(tool).Tool.Name)
+
 	// check that meta has id field
 	id, ok := serverTool.Tool.Meta.AdditionalFields[gatewayServerID]
 	assert.True(t, ok)
@@ -407,6 +562,247 @@ func TestMCPManager_toolToServerTool(t *testing.T) {
 	assert.True(t, result.IsError)
 }
 
+func TestMCPManager_manage_ToolNameTemplate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "weather")
+	mock.cfg.ToolNameTemplate = "{tool}_{prefix}"
+	mock.tools = []mcp.Tool{{Name: "forecast"}, {Name: "alerts"}}
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	manager.manage(context.Background(), eventTypeTimer)
+
+	require.Contains(t, gateway.tools, "forecast_weather")
+	require.Contains(t, gateway.tools, "alerts_weather")
+	require.NotContains(t, gateway.tools, "weatherforecast")
+
+	// the served name maps back to the upstream tool so calls are routed with the upstream name
+	tool := manager.GetServedManagedTool("forecast_weather")
+	require.NotNil(t, tool)
+	assert.Equal(t, "forecast", tool.Name)
+	upstreamName, ok := manager.MCP.UpstreamToolName("forecast_weather")
+	require.True(t, ok)
+	assert.Equal(t, tool.Name, upstreamName)
+
+	// removing a tool upstream removes its served name from the gateway
+	mock.tools = []mcp.Tool{{Name: "forecast"}}
+	manager.manage(context.Background(), eventTypeNotification)
+	assert.NotContains(t, gateway.tools, "alerts_weather")
+	assert.Contains(t, gateway.tools, "forecast_weather")
+}
+
+func TestMCPManager_manage_ToolAliases(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "")
+	mock.cfg.ToolAliases = map[string]string{"time": "clock_time"}
+	mock.tools = []mcp.Tool{{Name: "time"}, {Name: "forecast"}}
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	manager.manage(context.Background(), eventTypeTimer)
+
+	// only the aliased tool is renamed, the others keep the served name from the prefix
+	require.Contains(t, gateway.tools, "clock_time")
+	require.Contains(t, gateway.tools, "forecast")
+	require.NotContains(t, gateway.tools, "time")
+
+	// calls to the alias are routed to the upstream tool
+	tool := manager.GetServedManagedTool("clock_time")
+	require.NotNil(t, tool)
+	assert.Equal(t, "time", tool.Name)
+	upstreamName, ok := manager.MCP.UpstreamToolName("clock_time")
+	require.True(t, ok)
+	assert.Equal(t, "time", upstreamName)
+	assert.Nil(t, manager.GetServedManagedTool("time"))
+
+	// removing the aliased tool upstream removes the alias from the gateway
+	mock.tools = []mcp.Tool{{Name: "forecast"}}
+	manager.manage(context.Background(), eventTypeNotification)
+	assert.NotContains(t, gateway.tools, "clock_time")
+	assert.Contains(t, gateway.tools, "forecast")
+}
+
+func TestMCPManager_manage_ToolAliasConflict(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "")
+	// the alias of time is the served name of the clock_time tool
+	mock.cfg.ToolAliases = map[string]string{"time": "clock_time"}
+	mock.tools = []mcp.Tool{{Name: "time"}, {Name: "clock_time"}, {Name: "forecast"}}
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	manager.manage(context.Background(), eventTypeTimer)
+
+	status := manager.GetStatus()
+	assert.False(t, status.Ready)
+	assert.Equal(t, ReasonToolConflict, status.Reason)
+	assert.Equal(t, []string{"clock_time"}, status.ConflictingTools)
+	assert.Equal(t, []string{string(mock.ID())}, status.ConflictingServers)
+	assert.Contains(t, status.Message, "check the tool aliases")
+	assert.Empty(t, gateway.tools)
+}
+
+func TestMCPManager_manage_DeprecatedToolOverride(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "test_")
+	mock.tools = []mcp.Tool{
+		{Name: "old_search", Description: "Search things"},
+		{Name: "search", Description: "Search things better"},
+	}
+	mock.cfg.ToolOverrides = []config.ToolOverride{
+		{Name: "old_search", Deprecated: true, DeprecationMessage: "Use test_search instead."},
+	}
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	manager.manage(context.Background(), eventTypeTimer)
+
+	require.Contains(t, gateway.tools, "test_old_search")
+	deprecated := gateway.tools["test_old_search"].Tool
+	assert.Equal(t, "Search things\n\nDEPRECATED: this tool will be removed in a future release. Use test_search instead.", deprecated.Description)
+	assert.Equal(t, true, deprecated.Meta.AdditionalFields[toolDeprecated])
+	assert.Equal(t, "Use test_search instead.", deprecated.Meta.AdditionalFields[toolDeprecationMessage])
+	assert.Equal(t, string(mock.id), deprecated.Meta.AdditionalFields[gatewayServerID])
+
+	// the deprecated tool remains callable and is still served
+	assert.NotNil(t, manager.GetServedManagedTool("test_old_search"))
+
+	require.Contains(t, gateway.tools, "test_search")
+	current := gateway.tools["test_search"].Tool
+	assert.Equal(t, "Search things better", current.Description)
+	assert.NotContains(t, current.Meta.AdditionalFields, toolDeprecated)
+}
+
+func TestMCPManager_manage_ToolCategories(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "test_")
+	mock.tools = []mcp.Tool{{Name: "search"}, {Name: "delete"}}
+	mock.cfg.Categories = []string{"documents"}
+	mock.cfg.ToolOverrides = []config.ToolOverride{
+		{Name: "delete", Categories: []string{"admin", "documents"}},
+	}
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	manager.manage(context.Background(), eventTypeTimer)
+
+	require.Contains(t, gateway.tools, "test_search")
+	assert.Equal(t, []string{"documents"}, gateway.tools["test_search"].Tool.Meta.AdditionalFields[toolCategories])
+	require.Contains(t, gateway.tools, "test_delete")
+	assert.Equal(t, []string{"documents", "admin"}, gateway.tools["test_delete"].Tool.Meta.AdditionalFields[toolCategories])
+}
+
+func TestMCPManager_manage_CredentialRotation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "test_")
+	mock.cfg.Credential = "Bearer old"
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	manager.manage(context.Background(), eventTypeTimer)
+	require.Equal(t, 0, mock.disconnectCalls)
+	require.Contains(t, gateway.tools, "test_mock_tool")
+
+	// an unchanged credential keeps the connection
+	manager.manage(context.Background(), eventTypeTimer)
+	require.Equal(t, 0, mock.disconnectCalls)
+
+	mock.cfg.Credential = "Bearer new"
+	mock.tools = []mcp.Tool{{Name: "mock_tool"}, {Name: "admin_tool"}}
+	connectCalls := mock.connectCalls
+	manager.manage(context.Background(), eventTypeTimer)
+
+	assert.Equal(t, 1, mock.disconnectCalls, "expected the stale session to be dropped")
+	assert.Equal(t, connectCalls+1, mock.connectCalls, "expected a reconnect with the new credential")
+	assert.True(t, mock.connected)
+	// tools are fetched again as the new credential may be granted different tools
+	assert.Contains(t, gateway.tools, "test_admin_tool")
+	assert.Equal(t, 0, gateway.delCalls)
+	assert.True(t, manager.GetStatus().Ready)
+
+	manager.manage(context.Background(), eventTypeTimer)
+	assert.Equal(t, 1, mock.disconnectCalls)
+}
+
+func TestMCPManager_UpdateCredential(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "test_")
+	manager := NewUpstreamMCPManager(mock, newMockToolsAdderDeleter(), logger, 0)
+
+	manager.UpdateCredential("Bearer new")
+	manager.UpdateCredential("Bearer newer")
+
+	assert.Equal(t, "Bearer newer", mock.GetConfig().Credential)
+	// repeated rotations before the manager checks are collapsed into one check
+	assert.Len(t, manager.credentialChanged, 1)
+}
+
+// countingGateway is a real gateway server that counts how often each tool is added
+type countingGateway struct {
+	*server.MCPServer
+	mu    sync.Mutex
+	added map[string]int
+}
+
+func (g *countingGateway) AddTools(tools ...server.ServerTool) {
+	g.mu.Lock()
+	for _, tool := range tools {
+		g.added[tool.Tool.Name]++
+	}
+	g.mu.Unlock()
+	g.MCPServer.AddTools(tools...)
+}
+
+func TestMCPManager_manage_UnchangedToolsStayListed(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "test_")
+	mock.tools = []mcp.Tool{{Name: "stable"}, {Name: "churn_0"}}
+	gateway := &countingGateway{
+		MCPServer: server.NewMCPServer("gateway", "0.0.1", server.WithToolCapabilities(true)),
+		added:     map[string]int{},
+	}
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+	manager.manage(context.Background(), eventTypeNotification)
+	require.Contains(t, gateway.ListTools(), "test_stable")
+
+	// list the gateway's tools in a tight loop while the upstream's other tool changes on every sync
+	started := make(chan struct{})
+	stop := make(chan struct{})
+	listed := make(chan int)
+	var missing atomic.Int32
+	go func() {
+		lists := 0
+		for {
+			if lists == 1 {
+				close(started)
+			}
+			select {
+			case <-stop:
+				listed <- lists
+				return
+			default:
+			}
+			if _, ok := gateway.ListTools()["test_stable"]; !ok {
+				missing.Add(1)
+			}
+			lists++
+		}
+	}()
+	<-started
+	for i := range 200 {
+		mock.tools = []mcp.Tool{{Name: "stable"}, {Name: fmt.Sprintf("churn_%d", i+1)}}
+		manager.manage(context.Background(), eventTypeNotification)
+	}
+	close(stop)
+	require.Positive(t, <-listed)
+
+	assert.Zero(t, missing.Load(), "unchanged tool was missing from tools/list during a sync")
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	assert.Equal(t, 1, gateway.added["test_stable"], "unchanged tool should keep the handler it was first added with")
+	assert.Len(t, gateway.ListTools(), 2)
+}
+
 func TestMCPManager_Stop_Idempotent(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	mock := newMockMCP("test", "")
@@ -436,6 +832,164 @@ func TestMCPManager_manage_ConnectError(t *testing.T) {
 	assert.Contains(t, status.Message, "connection refused")
 }
 
+func TestMCPManager_manage_ConnectRetry(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	retry := ConnectRetry{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+	t.Run("recovers within the backoff window", func(t *testing.T) {
+		mock := newMockMCP("test-server", "test_")
+		mock.connectErr = fmt.Errorf("connection refused")
+		mock.connectFailures = 2
+		gateway := newMockToolsAdderDeleter()
+		manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+		manager.SetConnectRetry(retry)
+
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.Equal(t, 3, mock.connectCalls)
+		assert.True(t, manager.GetStatus().Ready)
+		assert.Contains(t, gateway.tools, "test_mock_tool")
+	})
+
+	t.Run("fails once attempts are exhausted", func(t *testing.T) {
+		mock := newMockMCP("test-server", "test_")
+		mock.connectErr = fmt.Errorf("connection refused")
+		mock.connectFailures = 3
+		gateway := newMockToolsAdderDeleter()
+		manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+		manager.SetConnectRetry(retry)
+
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.Equal(t, 3, mock.connectCalls)
+		assert.False(t, manager.GetStatus().Ready)
+		assert.Contains(t, manager.GetStatus().Message, "connection refused")
+	})
+
+	t.Run("handshake failures are not retried", func(t *testing.T) {
+		mock := newMockMCP("test-server", "test_")
+		mock.connectErr = &CapabilityMismatchError{Err: fmt.Errorf("%w: sampling capability required", mcp.ErrInvalidParams)}
+		gateway := newMockToolsAdderDeleter()
+		manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+		manager.SetConnectRetry(retry)
+
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.Equal(t, 1, mock.connectCalls)
+		assert.Equal(t, ReasonCapabilityMismatch, manager.GetStatus().Reason)
+	})
+
+	t.Run("stops retrying when the context is cancelled", func(t *testing.T) {
+		mock := newMockMCP("test-server", "test_")
+		mock.connectErr = fmt.Errorf("connection refused")
+		gateway := newMockToolsAdderDeleter()
+		manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+		manager.SetConnectRetry(ConnectRetry{Attempts: 5, BaseDelay: time.Hour})
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		manager.manage(ctx, eventTypeTimer)
+		assert.Equal(t, 1, mock.connectCalls)
+		assert.False(t, manager.GetStatus().Ready)
+	})
+}
+
+func TestMCPManager_manage_HandshakeMismatch(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		connectErr              error
+		expectedReason          string
+		expectedMessage         string
+		expectedProtocolVersion string
+	}{
+		{
+			name:            "rejected client capabilities",
+			connectErr:      fmt.Errorf("failed to initialize client for upstream test : %w", &CapabilityMismatchError{Err: fmt.Errorf("%w: sampling capability required", mcp.ErrInvalidParams)}),
+			expectedReason:  ReasonCapabilityMismatch,
+			expectedMessage: "sampling capability required",
+		},
+		{
+			name:                    "unsupported protocol version",
+			connectErr:              fmt.Errorf("failed to initialize client for upstream test : %w", mcp.UnsupportedProtocolVersionError{Version: "2021-11-05"}),
+			expectedReason:          ReasonProtocolMismatch,
+			expectedMessage:         "unsupported protocol version",
+			expectedProtocolVersion: "2021-11-05",
+		},
+		{
+			name:            "connectivity",
+			connectErr:      fmt.Errorf("connection refused"),
+			expectedReason:  "",
+			expectedMessage: "connection refused",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+			mock := newMockMCP("test-server", "test_")
+			mock.connectErr = tc.connectErr
+			manager := NewUpstreamMCPManager(mock, newMockToolsAdderDeleter(), logger, 0)
+
+			manager.manage(context.Background(), eventTypeTimer)
+
+			status := manager.GetStatus()
+			assert.False(t, status.Ready)
+			assert.Equal(t, tc.expectedReason, status.Reason)
+			assert.Contains(t, status.Message, tc.expectedMessage)
+			assert.Equal(t, tc.expectedProtocolVersion, status.ProtocolVersion)
+		})
+	}
+}
+
+func TestMCPManager_manage_AcceptedProtocolVersions(t *testing.T) {
+	accepted := []string{"2025-06-18", "2025-03-26"}
+	testCases := []struct {
+		name            string
+		protocolVersion string
+		expectReady     bool
+		expectedReason  string
+	}{
+		{
+			name:            "latest accepted version",
+			protocolVersion: "2025-06-18",
+			expectReady:     true,
+		},
+		{
+			name:            "older accepted version",
+			protocolVersion: "2025-03-26",
+			expectReady:     true,
+		},
+		{
+			name:            "version outside accepted set",
+			protocolVersion: "2024-11-05",
+			expectReady:     false,
+			expectedReason:  ReasonProtocolMismatch,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+			mock := newMockMCP("test-server", "test_")
+			mock.protocolVersion = tc.protocolVersion
+			gateway := newMockToolsAdderDeleter()
+			manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+			manager.SetAcceptedProtocolVersions(accepted)
+
+			manager.manage(context.Background(), eventTypeTimer)
+
+			status := manager.GetStatus()
+			assert.Equal(t, tc.expectReady, status.Ready)
+			assert.Equal(t, tc.expectedReason, status.Reason)
+			// the advertised version is reported whether or not it is accepted
+			assert.Equal(t, tc.protocolVersion, status.ProtocolVersion)
+			if tc.expectReady {
+				assert.Contains(t, status.Message, tc.protocolVersion)
+				assert.Len(t, gateway.tools, 1)
+				return
+			}
+			assert.Contains(t, status.Message, "unsupported protocol version")
+			assert.Empty(t, gateway.tools)
+			assert.False(t, mock.connected)
+		})
+	}
+}
+
 func TestMCPManager_manage_PingError(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	mock := newMockMCP("test-server", "test_")
@@ -450,6 +1004,156 @@ func TestMCPManager_manage_PingError(t *testing.T) {
 	assert.Contains(t, status.Message, "ping")
 }
 
+func TestMCPManager_manage_UnavailableGrace(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	const grace = time.Minute
+
+	t.Run("brief outage keeps tools", func(t *testing.T) {
+		mock := newMockMCP("test-server", "test_")
+		gateway := newMockToolsAdderDeleter()
+		manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+		manager.SetUnavailableGrace(grace)
+		manager.manage(context.Background(), eventTypeTimer)
+		require.Contains(t, gateway.tools, "test_mock_tool")
+
+		mock.pingErr = fmt.Errorf("connection refused")
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.False(t, manager.GetStatus().Ready)
+		assert.Contains(t, gateway.tools, "test_mock_tool")
+		assert.Equal(t, 0, gateway.delCalls)
+
+		mock.pingErr = nil
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.True(t, manager.GetStatus().Ready)
+		assert.Contains(t, gateway.tools, "test_mock_tool")
+		assert.Equal(t, 0, gateway.delCalls)
+		assert.True(t, manager.unreachableSince.IsZero())
+	})
+
+	t.Run("outage past the grace removes tools", func(t *testing.T) {
+		mock := newMockMCP("test-server", "test_")
+		gateway := newMockToolsAdderDeleter()
+		manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+		manager.SetUnavailableGrace(grace)
+		manager.manage(context.Background(), eventTypeTimer)
+		require.Contains(t, gateway.tools, "test_mock_tool")
+
+		mock.connectErr = fmt.Errorf("connection refused")
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.Contains(t, gateway.tools, "test_mock_tool")
+
+		// the server has now been unreachable for longer than the grace
+		manager.unreachableSince = time.Now().Add(-2 * grace)
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.False(t, manager.GetStatus().Ready)
+		assert.NotContains(t, gateway.tools, "test_mock_tool")
+	})
+}
+
+func TestMCPManager_manage_UnavailablePolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("default policy removes tools", func(t *testing.T) {
+		mock := newMockMCP("test-server", "test_")
+		gateway := newMockToolsAdderDeleter()
+		manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+		manager.manage(context.Background(), eventTypeTimer)
+		require.Contains(t, gateway.tools, "test_mock_tool")
+
+		mock.pingErr = fmt.Errorf("connection refused")
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.True(t, manager.Unavailable())
+		assert.NotContains(t, gateway.tools, "test_mock_tool")
+	})
+
+	t.Run("keep tools policy keeps tools while unavailable", func(t *testing.T) {
+		mock := newMockMCP("test-server", "test_")
+		mock.cfg.UnavailablePolicy = config.UnavailablePolicyKeepTools
+		gateway := newMockToolsAdderDeleter()
+		manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+		manager.manage(context.Background(), eventTypeTimer)
+		require.Contains(t, gateway.tools, "test_mock_tool")
+		assert.False(t, manager.Unavailable())
+
+		mock.pingErr = fmt.Errorf("connection refused")
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.False(t, manager.GetStatus().Ready)
+		assert.True(t, manager.Unavailable())
+		assert.Contains(t, gateway.tools, "test_mock_tool")
+		assert.Equal(t, 0, gateway.delCalls)
+
+		mock.pingErr = nil
+		manager.manage(context.Background(), eventTypeTimer)
+		assert.True(t, manager.GetStatus().Ready)
+		assert.False(t, manager.Unavailable())
+		assert.Contains(t, gateway.tools, "test_mock_tool")
+	})
+}
+
+func TestMCPManager_manage_HealthPath(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	var healthy atomic.Bool
+	healthy.Store(true)
+	healthChecks := atomic.Int32{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		healthChecks.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	mock := newMockMCP("test-server", "test_")
+	mock.cfg.URL = backend.URL + "/mcp"
+	mock.cfg.HealthPath = "/healthz"
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	// the first check is a full MCP check
+	manager.manage(context.Background(), eventTypeTimer)
+	require.True(t, manager.GetStatus().Ready)
+	require.Contains(t, gateway.tools, "test_mock_tool")
+	require.Equal(t, 1, mock.pingCalls)
+	require.Equal(t, int32(0), healthChecks.Load())
+
+	// between full checks only the health path is polled
+	manager.manage(context.Background(), eventTypeTimer)
+	assert.True(t, manager.GetStatus().Ready)
+	assert.Equal(t, 1, mock.pingCalls)
+	assert.Equal(t, int32(1), healthChecks.Load())
+
+	// a failing health path marks the server unavailable with a distinct reason
+	healthy.Store(false)
+	manager.manage(context.Background(), eventTypeTimer)
+	status := manager.GetStatus()
+	assert.False(t, status.Ready)
+	assert.Equal(t, ReasonHealthCheckFailed, status.Reason)
+	assert.Contains(t, status.Message, "health path /healthz returned status 503")
+	assert.NotContains(t, gateway.tools, "test_mock_tool")
+	assert.Equal(t, 1, mock.pingCalls)
+
+	// recovery is confirmed with a full MCP check
+	healthy.Store(true)
+	manager.manage(context.Background(), eventTypeTimer)
+	assert.True(t, manager.GetStatus().Ready)
+	assert.Empty(t, manager.GetStatus().Reason)
+	assert.Contains(t, gateway.tools, "test_mock_tool")
+	assert.Equal(t, 2, mock.pingCalls)
+	assert.Equal(t, int32(2), healthChecks.Load())
+
+	// once the deep check interval has passed the server is checked with MCP again
+	manager.lastDeepCheck = time.Now().Add(-2 * DefaultDeepCheckInterval)
+	manager.manage(context.Background(), eventTypeTimer)
+	assert.Equal(t, 3, mock.pingCalls)
+	assert.Equal(t, int32(2), healthChecks.Load())
+}
+
 func TestMCPManager_manage_ListToolsError(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	mock := newMockMCP("test-server", "test_")
@@ -465,6 +1169,106 @@ func TestMCPManager_manage_ListToolsError(t *testing.T) {
 	assert.Contains(t, status.Message, "list tools")
 }
 
+func TestMCPManager_manage_QuarantineOnProtocolViolations(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "test_")
+	mock.tools = []mcp.Tool{{Name: "tool1"}}
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	manager.manage(context.Background(), eventTypeTimer)
+	assert.True(t, manager.GetStatus().Ready)
+	assert.Contains(t, gateway.tools, "test_tool1")
+
+	// malformed responses below the threshold keep the existing tools
+	mock.tools = []mcp.Tool{{Name: ""}}
+	for i := 1; i < DefaultProtocolViolationThreshold; i++ {
+		manager.manage(context.Background(), eventTypeNotification)
+		status := manager.GetStatus()
+		assert.False(t, status.Ready)
+		assert.Empty(t, status.Reason)
+		assert.Contains(t, gateway.tools, "test_tool1")
+	}
+
+	// reaching the threshold quarantines the server
+	mock.tools = nil
+	mock.listToolsErr = fmt.Errorf("failed to unmarshal response: %w", &json.SyntaxError{Offset: 1})
+	manager.manage(context.Background(), eventTypeNotification)
+	status := manager.GetStatus()
+	assert.False(t, status.Ready)
+	assert.Equal(t, ReasonProtocolViolation, status.Reason)
+	assert.Contains(t, status.Message, "quarantined")
+	assert.Empty(t, gateway.tools)
+	assert.True(t, manager.shouldFetchTools(eventTypeTimer))
+
+	// a well formed response on re-probe releases the server
+	mock.listToolsErr = nil
+	mock.tools = []mcp.Tool{{Name: "tool1"}}
+	manager.manage(context.Background(), eventTypeTimer)
+	status = manager.GetStatus()
+	assert.True(t, status.Ready)
+	assert.Empty(t, status.Reason)
+	assert.Contains(t, gateway.tools, "test_tool1")
+}
+
+func TestMCPManager_manage_ConnectivityErrorsDoNotQuarantine(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	mock := newMockMCP("test-server", "test_")
+	mock.hasToolsCap = false
+	mock.listToolsErr = fmt.Errorf("connection reset by peer")
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	for i := 0; i < DefaultProtocolViolationThreshold+1; i++ {
+		manager.manage(context.Background(), eventTypeTimer)
+	}
+	assert.Empty(t, manager.GetStatus().Reason)
+	assert.False(t, manager.quarantined)
+}
+
+func TestValidateListToolsResult(t *testing.T) {
+	testCases := []struct {
+		name    string
+		result  *mcp.ListToolsResult
+		wantErr bool
+	}{
+		{name: "valid", result: &mcp.ListToolsResult{Tools: []mcp.Tool{{Name: "a"}, {Name: "b"}}}},
+		{name: "no tools", result: &mcp.ListToolsResult{}},
+		{name: "nil result", result: nil, wantErr: true},
+		{name: "missing name", result: &mcp.ListToolsResult{Tools: []mcp.Tool{{Name: "a"}, {}}}, wantErr: true},
+		{name: "duplicate name", result: &mcp.ListToolsResult{Tools: []mcp.Tool{{Name: "a"}, {Name: "a"}}}, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateListToolsResult(tc.result)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, errProtocolViolation)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMCPManager_manage_NoToolsCapability(t *testing.T) {
+	// a server only serving prompts answers tools/list with an error, so the broker must not ask it for tools
+	srv := server.NewTestStreamableHTTPServer(server.NewMCPServer("prompts-server", "0.0.1", server.WithPromptCapabilities(true)))
+	t.Cleanup(srv.Close)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	up := NewUpstreamMCP(&config.MCPServer{Name: "prompts-server", URL: srv.URL + "/mcp"})
+	gateway := newMockToolsAdderDeleter()
+	manager := NewUpstreamMCPManager(up, gateway, logger, 0)
+	t.Cleanup(func() { _ = up.Disconnect() })
+
+	manager.manage(context.Background(), eventTypeTimer)
+
+	status := manager.GetStatus()
+	assert.True(t, status.Ready, status.Message)
+	assert.Empty(t, status.Reason)
+	assert.Equal(t, 0, status.TotalTools)
+	assert.Empty(t, gateway.tools)
+}
+
 func TestMCPManager_manage_Success(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	mock := newMockMCP("test-server", "test_")
@@ -475,12 +1279,15 @@ func TestMCPManager_manage_Success(t *testing.T) {
 	mock.hasToolsCap = false // ensure we list tools every time
 	gateway := newMockToolsAdderDeleter()
 	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+	synced := false
+	manager.OnSynced(func() { synced = true })
 
 	manager.manage(context.Background(), eventTypeTimer)
 
 	status := manager.GetStatus()
 	assert.True(t, status.Ready)
 	assert.Equal(t, 2, status.TotalTools)
+	assert.True(t, synced)
 
 	// tools should be added to gateway
 	assert.Len(t, gateway.tools, 2)
@@ -488,6 +1295,288 @@ func TestMCPManager_manage_Success(t *testing.T) {
 	assert.Contains(t, gateway.tools, "test_tool2")
 }
 
+func TestMCPManager_manage_CatalogLimit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	gateway := newMockToolsAdderDeleter()
+	limit := NewCatalogLimit(3)
+
+	first := newMockMCP("first", "first_")
+	first.tools = []mcp.Tool{{Name: "tool1"}, {Name: "tool2"}}
+	first.hasToolsCap = false
+	firstManager := NewUpstreamMCPManager(first, gateway, logger, 0)
+	firstManager.SetCatalogLimit(limit)
+
+	newest := newMockMCP("newest", "newest_")
+	newest.tools = []mcp.Tool{{Name: "tool1"}, {Name: "tool2"}}
+	newest.hasToolsCap = false
+	newestManager := NewUpstreamMCPManager(newest, gateway, logger, 0)
+	newestManager.SetCatalogLimit(limit)
+
+	firstManager.manage(context.Background(), eventTypeTimer)
+	require.True(t, firstManager.GetStatus().Ready)
+
+	// the newest server would take the catalog to 4 tools so it is held back and the first server keeps its tools
+	newestManager.manage(context.Background(), eventTypeTimer)
+	status := newestManager.GetStatus()
+	assert.False(t, status.Ready)
+	assert.Equal(t, ReasonCatalogFull, status.Reason)
+	assert.Contains(t, status.Message, "limit of 3")
+	assert.Len(t, gateway.tools, 2)
+	assert.Contains(t, gateway.tools, "first_tool1")
+	assert.Contains(t, gateway.tools, "first_tool2")
+	assert.Empty(t, newestManager.GetManagedTools())
+
+	// once the first server frees up room the newest server is admitted on its next check
+	first.tools = []mcp.Tool{{Name: "tool1"}}
+	firstManager.manage(context.Background(), eventTypeTimer)
+	newestManager.manage(context.Background(), eventTypeTimer)
+	status = newestManager.GetStatus()
+	assert.True(t, status.Ready)
+	assert.Empty(t, status.Reason)
+	assert.Len(t, gateway.tools, 3)
+	assert.Contains(t, gateway.tools, "newest_tool2")
+}
+
+func TestMCPManager_manage_CatalogLimitOrder(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	gateway := newMockToolsAdderDeleter()
+	limit := NewCatalogLimit(3)
+
+	newManager := func(name string, tools ...mcp.Tool) (*MockMCP, *MCPManager) {
+		upstream := newMockMCP(name, name+"_")
+		upstream.tools = tools
+		upstream.hasToolsCap = false
+		manager := NewUpstreamMCPManager(upstream, gateway, logger, 0)
+		manager.SetCatalogLimit(limit)
+		return upstream, manager
+	}
+	first, firstManager := newManager("first", mcp.Tool{Name: "tool1"}, mcp.Tool{Name: "tool2"})
+	_, zetaManager := newManager("zeta", mcp.Tool{Name: "tool1"}, mcp.Tool{Name: "tool2"})
+	_, alphaManager := newManager("alpha", mcp.Tool{Name: "tool1"}, mcp.Tool{Name: "tool2"})
+
+	firstManager.manage(context.Background(), eventTypeTimer)
+	require.True(t, firstManager.GetStatus().Ready)
+	zetaManager.manage(context.Background(), eventTypeTimer)
+	alphaManager.manage(context.Background(), eventTypeTimer)
+	require.Equal(t, ReasonCatalogFull, zetaManager.GetStatus().Reason)
+	require.Equal(t, ReasonCatalogFull, alphaManager.GetStatus().Reason)
+
+	// the first server frees room for one of the held back servers. zeta checks first but the room goes to alpha
+	first.tools = nil
+	firstManager.manage(context.Background(), eventTypeTimer)
+	zetaManager.manage(context.Background(), eventTypeTimer)
+	status := zetaManager.GetStatus()
+	assert.False(t, status.Ready)
+	assert.Equal(t, ReasonCatalogFull, status.Reason)
+	assert.Contains(t, status.Message, "held for alpha")
+	assert.Empty(t, zetaManager.GetManagedTools())
+
+	alphaManager.manage(context.Background(), eventTypeTimer)
+	assert.True(t, alphaManager.GetStatus().Ready)
+	assert.Contains(t, gateway.tools, "alpha_tool1")
+	assert.Contains(t, gateway.tools, "alpha_tool2")
+
+	// once alpha is admitted zeta no longer fits on its own
+	zetaManager.manage(context.Background(), eventTypeTimer)
+	status = zetaManager.GetStatus()
+	assert.Equal(t, ReasonCatalogFull, status.Reason)
+	assert.Contains(t, status.Message, "limit of 3")
+
+	// stopping alpha removes its tools so zeta is admitted
+	alphaManager.Stop()
+	zetaManager.manage(context.Background(), eventTypeTimer)
+	assert.True(t, zetaManager.GetStatus().Ready)
+}
+
+func TestMCPManager_manage_ConflictPriority(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	gateway := newMockToolsAdderDeleter()
+
+	low := newMockMCP("low", "")
+	low.tools = []mcp.Tool{{Name: "search"}, {Name: "low_only"}}
+	low.hasToolsCap = false
+	lowManager := NewUpstreamMCPManager(low, gateway, logger, 0)
+
+	high := newMockMCP("high", "")
+	high.cfg.Priority = 10
+	high.tools = []mcp.Tool{{Name: "search"}}
+	high.hasToolsCap = false
+	highManager := NewUpstreamMCPManager(high, gateway, logger, 0)
+
+	// the lower priority server registers first and the higher priority server takes its tool over
+	lowManager.manage(context.Background(), eventTypeTimer)
+	highManager.manage(context.Background(), eventTypeTimer)
+	require.True(t, highManager.GetStatus().Ready)
+	owner, ok := ToolServerID(gateway.tools["search"].Tool)
+	require.True(t, ok)
+	assert.Equal(t, high.ID(), owner)
+	assert.NotNil(t, highManager.GetServedManagedTool("search"))
+
+	// the lower priority server reports the tool as shadowed rather than failing and keeps its other tools
+	lowManager.manage(context.Background(), eventTypeTimer)
+	status := lowManager.GetStatus()
+	assert.True(t, status.Ready)
+	assert.Equal(t, []string{"search"}, status.ShadowedTools)
+	assert.Equal(t, []string{string(high.ID())}, status.ConflictingServers)
+	assert.Contains(t, status.Message, "shadowed")
+	assert.Nil(t, lowManager.GetServedManagedTool("search"))
+	assert.NotNil(t, lowManager.GetServedManagedTool("low_only"))
+	owner, _ = ToolServerID(gateway.tools["search"].Tool)
+	assert.Equal(t, high.ID(), owner)
+
+	// stopping the higher priority server leaves room for the shadowed tool on the next sync
+	highManager.Stop()
+	assert.NotContains(t, gateway.tools, "search")
+	lowManager.manage(context.Background(), eventTypeTimer)
+	status = lowManager.GetStatus()
+	assert.True(t, status.Ready)
+	assert.Empty(t, status.ShadowedTools)
+	assert.Empty(t, status.ConflictingServers)
+	owner, _ = ToolServerID(gateway.tools["search"].Tool)
+	assert.Equal(t, low.ID(), owner)
+	assert.NotNil(t, lowManager.GetServedManagedTool("search"))
+}
+
+func TestMCPManager_manage_ConflictEqualPriority(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	gateway := newMockToolsAdderDeleter()
+
+	first := newMockMCP("first", "")
+	first.tools = []mcp.Tool{{Name: "search"}}
+	firstManager := NewUpstreamMCPManager(first, gateway, logger, 0)
+	second := newMockMCP("second", "")
+	second.tools = []mcp.Tool{{Name: "search"}}
+	secondManager := NewUpstreamMCPManager(second, gateway, logger, 0)
+
+	firstManager.manage(context.Background(), eventTypeTimer)
+	secondManager.manage(context.Background(), eventTypeTimer)
+
+	status := secondManager.GetStatus()
+	assert.False(t, status.Ready)
+	assert.Contains(t, status.Message, "conflicting tools discovered")
+	assert.Contains(t, status.Message, "tools search conflict with servers "+string(first.ID()))
+	assert.Equal(t, ReasonToolConflict, status.Reason)
+	assert.Equal(t, []string{"search"}, status.ConflictingTools)
+	assert.Equal(t, []string{string(first.ID())}, status.ConflictingServers)
+	owner, _ := ToolServerID(gateway.tools["search"].Tool)
+	assert.Equal(t, first.ID(), owner)
+}
+
+func TestMCPManager_manage_ConflictCreationTimestamp(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	gateway := newMockToolsAdderDeleter()
+
+	older := newMockMCP("older", "")
+	older.cfg.CreationTimestamp = 1767225600
+	older.tools = []mcp.Tool{{Name: "search"}}
+	older.hasToolsCap = false
+	olderManager := NewUpstreamMCPManager(older, gateway, logger, 0)
+
+	newer := newMockMCP("newer", "")
+	newer.cfg.CreationTimestamp = 1767225660
+	newer.tools = []mcp.Tool{{Name: "search"}, {Name: "newer_only"}}
+	newer.hasToolsCap = false
+	newerManager := NewUpstreamMCPManager(newer, gateway, logger, 0)
+
+	// the newer server registers first, such as after a broker restart, and the older server of equal priority takes
+	// its tool over rather than reporting a conflict
+	newerManager.manage(context.Background(), eventTypeTimer)
+	olderManager.manage(context.Background(), eventTypeTimer)
+	require.True(t, olderManager.GetStatus().Ready)
+	assert.Empty(t, olderManager.GetStatus().ConflictingTools)
+	owner, ok := ToolServerID(gateway.tools["search"].Tool)
+	require.True(t, ok)
+	assert.Equal(t, older.ID(), owner)
+
+	// the newer server reports the tool as shadowed by the older server and keeps its other tools
+	newerManager.manage(context.Background(), eventTypeTimer)
+	status := newerManager.GetStatus()
+	assert.True(t, status.Ready)
+	assert.Equal(t, []string{"search"}, status.ShadowedTools)
+	assert.Equal(t, []string{string(older.ID())}, status.ConflictingServers)
+	assert.Contains(t, status.Message, "shadowed")
+	assert.NotNil(t, newerManager.GetServedManagedTool("newer_only"))
+
+	// a higher priority takes precedence over the creation time
+	newer.cfg.Priority = 10
+	newerManager.manage(context.Background(), eventTypeTimer)
+	owner, _ = ToolServerID(gateway.tools["search"].Tool)
+	assert.Equal(t, newer.ID(), owner)
+	assert.Empty(t, newerManager.GetStatus().ShadowedTools)
+}
+
+func TestMCPManager_manage_ConflictSameCreationTimestamp(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	gateway := newMockToolsAdderDeleter()
+
+	first := newMockMCP("first", "")
+	first.cfg.CreationTimestamp = 1767225600
+	first.tools = []mcp.Tool{{Name: "search"}}
+	firstManager := NewUpstreamMCPManager(first, gateway, logger, 0)
+	second := newMockMCP("second", "")
+	second.cfg.CreationTimestamp = 1767225600
+	second.tools = []mcp.Tool{{Name: "search"}}
+	secondManager := NewUpstreamMCPManager(second, gateway, logger, 0)
+
+	// servers created in the same second cannot be ordered so they conflict
+	firstManager.manage(context.Background(), eventTypeTimer)
+	secondManager.manage(context.Background(), eventTypeTimer)
+	status := secondManager.GetStatus()
+	assert.False(t, status.Ready)
+	assert.Equal(t, ReasonToolConflict, status.Reason)
+	assert.Equal(t, []string{"search"}, status.ConflictingTools)
+	owner, _ := ToolServerID(gateway.tools["search"].Tool)
+	assert.Equal(t, first.ID(), owner)
+}
+
+func TestMCPManager_manage_LogsToolDiff(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mock := newMockMCP("test-server", "test_")
+	mock.tools = []mcp.Tool{{Name: "tool1"}, {Name: "tool2"}}
+	mock.hasToolsCap = false
+	gateway := newMockToolsAdderDeleter()
+	gateway.tools["test_conflict"] = &server.ServerTool{Tool: mcp.Tool{
+		Name: "test_conflict",
+		Meta: mcp.NewMetaFromMap(map[string]any{gatewayServerID: "other-server"}),
+	}}
+	manager := NewUpstreamMCPManager(mock, gateway, logger, 0)
+
+	manager.manage(context.Background(), eventTypeTimer)
+	buf.Reset()
+
+	// tool1 removed from the backend, tool3 added
+	mock.tools = []mcp.Tool{{Name: "tool2"}, {Name: "tool3"}}
+	manager.manage(context.Background(), eventTypeTimer)
+	assert.ElementsMatch(t, []string{
+		"add test_tool3 new backend tool",
+		"remove test_tool1 removed backend tool",
+	}, toolDiffEntries(t, &buf))
+
+	buf.Reset()
+	mock.tools = []mcp.Tool{{Name: "tool2"}, {Name: "tool3"}, {Name: "conflict"}}
+	manager.manage(context.Background(), eventTypeTimer)
+	assert.ElementsMatch(t, []string{
+		"add test_conflict new backend tool",
+		"reject test_conflict conflict",
+	}, toolDiffEntries(t, &buf))
+}
+
+// toolDiffEntries returns the "action tool reason" of each tool diff log entry
+func toolDiffEntries(t *testing.T, buf *bytes.Buffer) []string {
+	t.Helper()
+	var entries []string
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		entry := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] != "tool diff" {
+			continue
+		}
+		entries = append(entries, fmt.Sprintf("%s %s %s", entry["action"], entry["tool"], entry["reason"]))
+	}
+	return entries
+}
+
 func TestDiffTools(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	mock := newMockMCP("test-server", "test_")
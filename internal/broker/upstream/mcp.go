@@ -2,7 +2,11 @@ package upstream
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"net/http"
+	"slices"
 	"sync"
 
 	"github.com/Kuadrant/mcp-gateway/internal/config"
@@ -29,10 +33,12 @@ func NewUpstreamMCP(config *config.MCPServer) *MCPServer {
 	up := &MCPServer{
 		MCPServer: config,
 	}
-	up.headers = map[string]string{
-		"user-agent":        "mcp-broker",
-		"gateway-server-id": string(up.ID()),
+	up.headers = maps.Clone(up.Headers)
+	if up.headers == nil {
+		up.headers = map[string]string{}
 	}
+	up.headers["user-agent"] = "mcp-broker"
+	up.headers["gateway-server-id"] = string(up.ID())
 	if up.Credential != "" {
 		up.headers["Authorization"] = up.Credential
 	}
@@ -41,15 +47,65 @@ func NewUpstreamMCP(config *config.MCPServer) *MCPServer {
 
 // GetConfig return the config for the backend mcp server
 func (up *MCPServer) GetConfig() config.MCPServer {
+	up.clientMu.RLock()
+	defer up.clientMu.RUnlock()
 	// return a copy rather than the original
 	return config.MCPServer{
-		Name:       up.Name,
-		URL:        up.URL,
-		ToolPrefix: up.ToolPrefix,
-		Enabled:    up.Enabled,
-		Hostname:   up.Hostname,
-		Credential: up.Credential,
+		Name:                up.Name,
+		URL:                 up.URL,
+		BrokerURL:           up.BrokerURL,
+		ToolPrefix:          up.ToolPrefix,
+		ToolNameTemplate:    up.ToolNameTemplate,
+		Enabled:             up.Enabled,
+		Hostname:            up.Hostname,
+		Auth:                cloneAuth(up.Auth),
+		Credential:          up.Credential,
+		Categories:          slices.Clone(up.Categories),
+		ToolOverrides:       slices.Clone(up.ToolOverrides),
+		ToolAliases:         maps.Clone(up.ToolAliases),
+		Priority:            up.Priority,
+		UnavailablePolicy:   up.UnavailablePolicy,
+		HealthPath:          up.HealthPath,
+		HealthCheckInterval: up.HealthCheckInterval,
+		CallTimeout:         up.CallTimeout,
+		Draining:            up.Draining,
+		TLS:                 cloneTLS(up.TLS),
+		Protocol:            up.Protocol,
+		Headers:             maps.Clone(up.Headers),
+		Generation:          up.Generation,
+		CreationTimestamp:   up.CreationTimestamp,
+		ForceSync:           up.ForceSync,
+	}
+}
+
+func cloneAuth(auth *config.AuthConfig) *config.AuthConfig {
+	if auth == nil {
+		return nil
+	}
+	clone := *auth
+	return &clone
+}
+
+func cloneTLS(tlsConfig *config.TLSConfig) *config.TLSConfig {
+	if tlsConfig == nil {
+		return nil
 	}
+	clone := *tlsConfig
+	return &clone
+}
+
+// SetCredential replaces the credential sent to the upstream. It takes effect on the next connection
+func (up *MCPServer) SetCredential(credential string) {
+	up.clientMu.Lock()
+	defer up.clientMu.Unlock()
+	up.Credential = credential
+	// replace rather than modify the headers as an existing client may still be reading them
+	headers := maps.Clone(up.headers)
+	delete(headers, "Authorization")
+	if credential != "" {
+		headers["Authorization"] = credential
+	}
+	up.headers = headers
 }
 
 // ProtocolInfo returns the initialize result with the protocol information stored in it
@@ -69,12 +125,101 @@ func (up *MCPServer) GetName() string {
 
 // SupportsToolsListChanged validates the mcp server supports tools/list_changed notifications
 func (up *MCPServer) SupportsToolsListChanged() bool {
-	if up.init == nil {
+	if up.init == nil || up.init.Capabilities.Tools == nil {
 		return false
 	}
 	return up.init.Capabilities.Tools.ListChanged
 }
 
+// CapabilityMismatchError is returned when the upstream rejects the initialize params, as it does when it requires a
+// client capability the broker doesn't offer. Err holds the upstream's reason
+type CapabilityMismatchError struct {
+	Err error
+}
+
+func (e *CapabilityMismatchError) Error() string {
+	return fmt.Sprintf("upstream rejected the client capabilities offered by the broker: %v", e.Err)
+}
+
+func (e *CapabilityMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// TransportMismatchError is returned when the upstream rejects the Streamable HTTP transport, such as a server that
+// only speaks the older HTTP with SSE transport
+type TransportMismatchError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *TransportMismatchError) Error() string {
+	return fmt.Sprintf("upstream rejected the streamable-http transport (status %d), tool calls are routed over Streamable HTTP so a server only speaking HTTP with SSE is not supported: %v",
+		e.StatusCode, e.Err)
+}
+
+func (e *TransportMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// statusRecorder records the status code of the last response to each HTTP method sent to the upstream so a
+// failed connection can be diagnosed
+type statusRecorder struct {
+	next   http.RoundTripper
+	mu     sync.Mutex
+	status map[string]int
+}
+
+func (r *statusRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err == nil {
+		r.mu.Lock()
+		r.status[req.Method] = resp.StatusCode
+		r.mu.Unlock()
+	}
+	return resp, err
+}
+
+func (r *statusRecorder) lastStatus(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status[method]
+}
+
+// newClient creates the streamable HTTP client for the upstream. Responses are recorded by the returned
+// statusRecorder
+func (up *MCPServer) newClient(headers map[string]string) (*client.Client, *statusRecorder, error) {
+	upstreamTransport, err := up.Transport()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid tls config for upstream %s: %w", up.ID(), err)
+	}
+	recorder := &statusRecorder{next: http.DefaultTransport, status: map[string]int{}}
+	if upstreamTransport != nil {
+		recorder.next = upstreamTransport
+	}
+	httpClient := &http.Client{Transport: recorder}
+
+	mcpClient, err := client.NewStreamableHttpClient(up.ConnectURL(),
+		transport.WithContinuousListening(),
+		transport.WithHTTPHeaders(headers),
+		transport.WithHTTPBasicClient(httpClient),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return mcpClient, recorder, nil
+}
+
+// transportMismatch wraps err in a TransportMismatchError when the upstream rejected the POST opening the Streamable
+// HTTP session as a method or content type it does not accept, as an HTTP with SSE server does. Other failures,
+// such as timeouts, are returned as is
+func transportMismatch(recorder *statusRecorder, err error) error {
+	status := recorder.lastStatus(http.MethodPost)
+	if status != http.StatusMethodNotAllowed && status != http.StatusUnsupportedMediaType {
+		return err
+	}
+	return &TransportMismatchError{StatusCode: status, Err: err}
+}
+
 // Connect establishes a connection to the upstream MCP server. It creates a
 // streamable HTTP client, starts it for continuous listening, and performs
 // the MCP initialization handshake. If already connected, this is a no-op.
@@ -87,16 +232,12 @@ func (up *MCPServer) Connect(ctx context.Context, onConnection func()) error {
 		//if we already have a valid connection nothing to do
 		return nil
 	}
+	headers := up.headers
 	up.clientMu.RUnlock()
 
-	options := []transport.StreamableHTTPCOption{
-		transport.WithContinuousListening(),
-		transport.WithHTTPHeaders(up.headers),
-	}
-
-	httpClient, err := client.NewStreamableHttpClient(up.URL, options...)
+	httpClient, recorder, err := up.newClient(headers)
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+		return err
 	}
 
 	up.clientMu.Lock()
@@ -109,9 +250,11 @@ func (up *MCPServer) Connect(ctx context.Context, onConnection func()) error {
 	// Start the client before initialize to listen for notifications
 	err = httpClient.Start(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to start streamable client: %w", err)
+		return fmt.Errorf("failed to start streamable client: %w", transportMismatch(recorder, err))
 	}
-	initResp, err := httpClient.Initialize(ctx, mcp.InitializeRequest{
+	initCtx, cancel := up.requestContext(ctx)
+	defer cancel()
+	initResp, err := httpClient.Initialize(initCtx, mcp.InitializeRequest{
 		Params: mcp.InitializeParams{
 			ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
 			Capabilities: mcp.ClientCapabilities{
@@ -127,8 +270,11 @@ func (up *MCPServer) Connect(ctx context.Context, onConnection func()) error {
 			},
 		},
 	})
+	if errors.Is(err, mcp.ErrInvalidParams) {
+		return fmt.Errorf("failed to initialize client for upstream %s : %w", up.ID(), &CapabilityMismatchError{Err: err})
+	}
 	if err != nil {
-		return fmt.Errorf("failed to initialize client for upstream %s : %w", up.ID(), err)
+		return fmt.Errorf("failed to initialize client for upstream %s : %w", up.ID(), transportMismatch(recorder, err))
 	}
 	// whenever we do an init store the response and session id for validation a future use
 	up.init = initResp
@@ -180,6 +326,8 @@ func (up *MCPServer) Ping(ctx context.Context) error {
 	if up.client == nil {
 		return fmt.Errorf("client not connected")
 	}
+	ctx, cancel := up.requestContext(ctx)
+	defer cancel()
 	return up.client.Ping(ctx)
 }
 
@@ -191,5 +339,16 @@ func (up *MCPServer) ListTools(ctx context.Context, req mcp.ListToolsRequest) (*
 	if up.client == nil {
 		return nil, fmt.Errorf("client not connected")
 	}
+	ctx, cancel := up.requestContext(ctx)
+	defer cancel()
 	return up.client.ListTools(ctx, req)
 }
+
+// requestContext bounds a request to the upstream by its call timeout. Without a valid timeout ctx is used as is
+func (up *MCPServer) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout, err := up.RequestTimeout()
+	if err != nil || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
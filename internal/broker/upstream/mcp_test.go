@@ -1,9 +1,24 @@
 package upstream
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/config"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/require"
 )
 
@@ -11,11 +26,289 @@ func TestNewUpstreamMCP(t *testing.T) {
 	testServer := config.MCPServer{
 		Name:       "test-server",
 		URL:        "http://localhost:8088/mcp",
+		BrokerURL:  "http://localhost:8088/v1/mcp",
 		ToolPrefix: "",
 		Enabled:    true,
 		Hostname:   "dummy",
+		Categories: []string{"search"},
+		ToolOverrides: []config.ToolOverride{
+			{Name: "old_tool", Deprecated: true},
+		},
+		Priority:            10,
+		UnavailablePolicy:   config.UnavailablePolicyKeepTools,
+		HealthPath:          "/healthz",
+		HealthCheckInterval: "30s",
+		TLS:                 &config.TLSConfig{CACert: "ca", InsecureSkipVerify: true},
+		Protocol:            config.ProtocolH2C,
+		Headers:             map[string]string{"x-tenant": "team-a", "user-agent": "route"},
+		CreationTimestamp:   1767225600,
 	}
 	up := NewUpstreamMCP(&testServer)
 	require.NotNil(t, up)
 	require.Equal(t, testServer, up.GetConfig())
+	// headers from the route are sent alongside the broker's own, which take precedence
+	require.Equal(t, "team-a", up.headers["x-tenant"])
+	require.Equal(t, "mcp-broker", up.headers["user-agent"])
+}
+
+func TestMCPServer_GetConfigRoundTrip(t *testing.T) {
+	testServer := config.MCPServer{
+		Name:                "test-server",
+		URL:                 "http://localhost:8088/mcp",
+		Hostname:            "test.mcp.local",
+		ToolPrefix:          "test_",
+		Auth:                &config.AuthConfig{Type: "bearer", Token: "token", Username: "user", Password: "password"},
+		Credential:          "Bearer token",
+		Enabled:             true,
+		Categories:          []string{"search"},
+		ToolOverrides:       []config.ToolOverride{{Name: "old_tool", Deprecated: true}},
+		Priority:            10,
+		ToolAliases:         map[string]string{"search": "find"},
+		ToolNameTemplate:    "{prefix}{tool}",
+		UnavailablePolicy:   config.UnavailablePolicyKeepTools,
+		HealthPath:          "/healthz",
+		HealthCheckInterval: "30s",
+		CallTimeout:         "2m",
+		Draining:            true,
+		TLS:                 &config.TLSConfig{CACert: "ca", ClientCert: "cert", ClientKey: "key", InsecureSkipVerify: true},
+		Headers:             map[string]string{"x-tenant": "team-a"},
+		BrokerURL:           "http://localhost:8088/v1/mcp",
+		Protocol:            config.ProtocolH2C,
+		Generation:          3,
+		CreationTimestamp:   1767225600,
+		ForceSync:           "2026-10-15T12:00:00Z",
+	}
+	// every field is set so a field GetConfig doesn't copy fails the comparison
+	fields := reflect.ValueOf(testServer)
+	for i := range fields.NumField() {
+		require.False(t, fields.Field(i).IsZero(), "field %s is not set", fields.Type().Field(i).Name)
+	}
+
+	up := NewUpstreamMCP(&testServer)
+	got := up.GetConfig()
+	require.Equal(t, testServer, got)
+
+	// the copy doesn't share references with the upstream
+	got.Auth.Token = "changed"
+	got.TLS.CACert = "changed"
+	got.Headers["x-tenant"] = "changed"
+	got.ToolAliases["search"] = "changed"
+	require.Equal(t, testServer, up.GetConfig())
+}
+
+func TestMCPServer_SetCredential(t *testing.T) {
+	up := NewUpstreamMCP(&config.MCPServer{Name: "test-server", Credential: "Bearer old"})
+	headers := up.headers
+
+	up.SetCredential("Bearer new")
+	require.Equal(t, "Bearer new", up.GetConfig().Credential)
+	require.Equal(t, "Bearer new", up.headers["Authorization"])
+	// headers handed to an existing client are left untouched
+	require.Equal(t, "Bearer old", headers["Authorization"])
+
+	up.SetCredential("")
+	require.NotContains(t, up.headers, "Authorization")
+}
+
+// newTLSMCPServer starts an MCP server over https. Clients must present a certificate signed by clientCAs if set
+func newTLSMCPServer(t *testing.T, clientCAs *x509.CertPool) (*httptest.Server, string) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(server.NewStreamableHTTPServer(server.NewMCPServer("tls-server", "0.0.1", server.WithToolCapabilities(true))))
+	if clientCAs != nil {
+		srv.TLS = &tls.Config{ClientCAs: clientCAs, ClientAuth: tls.RequireAndVerifyClientCert, MinVersion: tls.VersionTLS12}
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	return srv, string(caCert)
+}
+
+// newClientCertificate creates a self signed client certificate and returns it with its key as PEM
+func newClientCertificate(t *testing.T) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mcp-broker"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return cert,
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestMCPServer_ConnectTLS(t *testing.T) {
+	connect := func(t *testing.T, url string, tlsConfig *config.TLSConfig) error {
+		t.Helper()
+		up := NewUpstreamMCP(&config.MCPServer{Name: "tls-server", URL: url + "/mcp", TLS: tlsConfig})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := up.Connect(ctx, func() {})
+		_ = up.Disconnect()
+		return err
+	}
+
+	t.Run("CA only", func(t *testing.T) {
+		srv, caCert := newTLSMCPServer(t, nil)
+		require.Error(t, connect(t, srv.URL, nil), "the test server certificate is not in the system roots")
+		require.NoError(t, connect(t, srv.URL, &config.TLSConfig{CACert: caCert}))
+	})
+
+	t.Run("insecure skip verify", func(t *testing.T) {
+		srv, _ := newTLSMCPServer(t, nil)
+		require.NoError(t, connect(t, srv.URL, &config.TLSConfig{InsecureSkipVerify: true}))
+	})
+
+	t.Run("mTLS", func(t *testing.T) {
+		clientCert, certPEM, keyPEM := newClientCertificate(t)
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(clientCert)
+		srv, caCert := newTLSMCPServer(t, clientCAs)
+		require.Error(t, connect(t, srv.URL, &config.TLSConfig{CACert: caCert}), "the server requires a client certificate")
+		require.NoError(t, connect(t, srv.URL, &config.TLSConfig{CACert: caCert, ClientCert: certPEM, ClientKey: keyPEM}))
+	})
+
+	t.Run("invalid CA", func(t *testing.T) {
+		srv, _ := newTLSMCPServer(t, nil)
+		err := connect(t, srv.URL, &config.TLSConfig{CACert: "not a certificate"})
+		require.ErrorContains(t, err, "invalid tls config")
+	})
+}
+
+func TestMCPServer_ConnectH2C(t *testing.T) {
+	// the server only accepts HTTP/2 without TLS
+	srv := httptest.NewUnstartedServer(server.NewStreamableHTTPServer(server.NewMCPServer("h2c-server", "0.0.1", server.WithToolCapabilities(true))))
+	srv.Config.Protocols = &http.Protocols{}
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	connect := func(t *testing.T, protocol string) error {
+		t.Helper()
+		up := NewUpstreamMCP(&config.MCPServer{Name: "h2c-server", URL: srv.URL + "/mcp", Protocol: protocol})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := up.Connect(ctx, func() {})
+		_ = up.Disconnect()
+		return err
+	}
+
+	require.Error(t, connect(t, ""), "the server does not accept HTTP/1.1")
+	require.NoError(t, connect(t, config.ProtocolH2C))
+}
+
+func TestMCPServer_ConnectCallTimeout(t *testing.T) {
+	// the server never answers so only the call timeout ends the initialize request
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	up := NewUpstreamMCP(&config.MCPServer{Name: "stalled-server", URL: srv.URL + "/mcp", CallTimeout: "100ms"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	err := up.Connect(ctx, func() {})
+	_ = up.Disconnect()
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestMCPServer_ConnectTransport(t *testing.T) {
+	mcpServer := server.NewMCPServer("transport-server", "0.0.1", server.WithToolCapabilities(true))
+	sseSrv := server.NewTestServer(mcpServer)
+	t.Cleanup(sseSrv.Close)
+	streamableSrv := server.NewTestStreamableHTTPServer(mcpServer)
+	t.Cleanup(streamableSrv.Close)
+
+	connect := func(t *testing.T, url string) error {
+		t.Helper()
+		up := NewUpstreamMCP(&config.MCPServer{Name: "transport-server", URL: url})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := up.Connect(ctx, func() {})
+		_ = up.Disconnect()
+		return err
+	}
+
+	require.NoError(t, connect(t, streamableSrv.URL+"/mcp"))
+
+	var mismatch *TransportMismatchError
+	err := connect(t, sseSrv.URL+"/sse")
+	require.ErrorAs(t, err, &mismatch, "an SSE server rejects the POST of the streamable HTTP transport")
+	require.Equal(t, http.StatusMethodNotAllowed, mismatch.StatusCode)
+	require.Equal(t, ReasonTransportMismatch, handshakeFailureReason(err))
+	require.Contains(t, err.Error(), "not supported")
+}
+
+func TestTransportMismatch(t *testing.T) {
+	cause := errors.New("request failed")
+	for _, status := range []int{http.StatusMethodNotAllowed, http.StatusUnsupportedMediaType} {
+		recorder := &statusRecorder{status: map[string]int{http.MethodPost: status}}
+		var mismatch *TransportMismatchError
+		require.ErrorAs(t, transportMismatch(recorder, cause), &mismatch)
+		require.ErrorIs(t, mismatch, cause)
+	}
+	// a server that timed out or failed for another reason does not speak another transport
+	for _, status := range []int{0, http.StatusOK, http.StatusUnauthorized, http.StatusInternalServerError} {
+		recorder := &statusRecorder{status: map[string]int{http.MethodPost: status}}
+		require.Equal(t, cause, transportMismatch(recorder, cause))
+	}
+	// only the POST opening the session is classified, the GET listening for notifications may be refused
+	recorder := &statusRecorder{status: map[string]int{http.MethodGet: http.StatusMethodNotAllowed}}
+	require.Equal(t, cause, transportMismatch(recorder, cause))
+}
+
+func TestMCPServer_ConnectCapabilities(t *testing.T) {
+	t.Run("a server without tools connects", func(t *testing.T) {
+		srv := server.NewTestStreamableHTTPServer(server.NewMCPServer("prompts-server", "0.0.1", server.WithPromptCapabilities(true)))
+		t.Cleanup(srv.Close)
+
+		up := NewUpstreamMCP(&config.MCPServer{Name: "prompts-server", URL: srv.URL + "/mcp"})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, up.Connect(ctx, func() {}))
+		t.Cleanup(func() { _ = up.Disconnect() })
+		require.Nil(t, up.ProtocolInfo().Capabilities.Tools)
+		require.False(t, up.SupportsToolsListChanged())
+	})
+
+	t.Run("a server rejecting the client capabilities is a capability mismatch", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"client must support sampling"}}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		up := NewUpstreamMCP(&config.MCPServer{Name: "sampling-server", URL: srv.URL + "/mcp"})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := up.Connect(ctx, func() {})
+		_ = up.Disconnect()
+		var mismatch *CapabilityMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		require.Equal(t, ReasonCapabilityMismatch, handshakeFailureReason(err))
+		require.Contains(t, err.Error(), "client must support sampling")
+	})
 }
@@ -0,0 +1,73 @@
+package upstream
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	upstreamConnectAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_upstream_connect_attempts_total",
+		Help: "Number of attempts by the broker to connect to an upstream MCP server",
+	}, []string{"server", "prefix"})
+	upstreamConnectFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_upstream_connect_failures_total",
+		Help: "Number of failed attempts by the broker to connect to an upstream MCP server",
+	}, []string{"server", "prefix"})
+	upstreamListToolsDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcp_upstream_list_tools_duration_seconds",
+		Help:    "Latency of tools/list requests from the broker to an upstream MCP server",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"server", "prefix"})
+	upstreamTools = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcp_upstream_tools_total",
+		Help: "Number of tools currently managed by the broker for an upstream MCP server",
+	}, []string{"server", "prefix"})
+
+	// metricOwners records the manager that owns the series of each server and prefix, so a manager replaced by one
+	// with the same labels, for example after a URL change, does not delete the series of its replacement when stopped
+	metricOwners sync.Map
+)
+
+// register the upstream metrics when the package is loaded so they are exported before the first upstream is managed
+func init() {
+	prometheus.MustRegister(upstreamConnectAttempts, upstreamConnectFailures, upstreamListToolsDuration, upstreamTools)
+}
+
+// metricLabels returns the label values identifying the managed upstream
+func (man *MCPManager) metricLabels() prometheus.Labels {
+	return prometheus.Labels{"server": man.MCP.GetName(), "prefix": man.MCP.GetPrefix()}
+}
+
+// metricOwnerKey identifies the series of the managed upstream in metricOwners
+func (man *MCPManager) metricOwnerKey() string {
+	return man.MCP.GetName() + "/" + man.MCP.GetPrefix()
+}
+
+// claimMetrics makes the manager the owner of the series of its upstream
+func (man *MCPManager) claimMetrics() {
+	metricOwners.Store(man.metricOwnerKey(), man)
+}
+
+// recordToolCount sets the managed tool gauge unless a newer manager for the same server and prefix owns it. Callers
+// must hold the tools lock
+func (man *MCPManager) recordToolCount() {
+	if owner, _ := metricOwners.Load(man.metricOwnerKey()); owner != man {
+		return
+	}
+	upstreamTools.With(man.metricLabels()).Set(float64(len(man.tools)))
+}
+
+// deleteMetrics removes the series of a manager that is no longer running, unless a newer manager for the same server
+// and prefix now owns them
+func (man *MCPManager) deleteMetrics() {
+	if !metricOwners.CompareAndDelete(man.metricOwnerKey(), man) {
+		return
+	}
+	labels := man.metricLabels()
+	upstreamConnectAttempts.Delete(labels)
+	upstreamConnectFailures.Delete(labels)
+	upstreamListToolsDuration.Delete(labels)
+	upstreamTools.Delete(labels)
+}
@@ -0,0 +1,34 @@
+// Package buildinfo serves the build version of the running binary
+package buildinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Path is the path the version endpoint is served on
+const Path = "/version"
+
+// Info is the build version of a running component
+type Info struct {
+	Version string `json:"version"`
+	GitSHA  string `json:"gitSHA"`
+}
+
+// String returns the version and commit, for example v0.5.0 (abc1234)
+func (i Info) String() string {
+	return fmt.Sprintf("%s (%s)", i.Version, i.GitSHA)
+}
+
+// Handler serves info as JSON
+func Handler(info Info) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(info)
+	}
+}
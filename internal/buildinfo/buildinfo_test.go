@@ -0,0 +1,28 @@
+package buildinfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	info := Info{Version: "v0.5.0", GitSHA: "abc1234"}
+
+	rec := httptest.NewRecorder()
+	Handler(info).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var got Info
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	require.Equal(t, info, got)
+	require.Equal(t, "v0.5.0 (abc1234)", got.String())
+
+	rec = httptest.NewRecorder()
+	Handler(info).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -27,9 +27,11 @@
 package config
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -38,6 +40,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/yaml"
 )
 
@@ -46,10 +49,21 @@ import (
 // using optimistic locking with automatic retry on conflicts.
 type SecretReaderWriter struct {
 	Client client.Client
+	// Reader reads the config secrets when set. It must be an uncached reader when the client cache only holds
+	// labeled secrets, as the config secrets are not labeled
+	Reader client.Reader
 	Scheme *runtime.Scheme
 	Logger *slog.Logger
 }
 
+// reader returns the reader for config secrets, falling back to the client
+func (srw *SecretReaderWriter) reader() client.Reader {
+	if srw.Reader != nil {
+		return srw.Reader
+	}
+	return srw.Client
+}
+
 // DefaultNamespaceName is the default location for the MCP Gateway config secret.
 var DefaultNamespaceName = types.NamespacedName{Namespace: "mcp-system", Name: "mcp-gateway-config"}
 
@@ -59,6 +73,9 @@ func NamespaceName(ns string) types.NamespacedName {
 	return types.NamespacedName{Namespace: ns, Name: "mcp-gateway-config"}
 }
 
+// AggregatedSecretLabel marks secrets that hold aggregated broker config
+const AggregatedSecretLabel = "mcp.kuadrant.io/aggregated"
+
 const (
 	// configFileName is the key in the Secret's data map containing the YAML config.
 	configFileName = "config.yaml"
@@ -87,6 +104,20 @@ func (srw *SecretReaderWriter) WriteVirtualServerConfig(ctx context.Context, vir
 	})
 }
 
+// ReadBrokerConfig reads and parses the config secret without creating it. A missing secret is returned as a
+// NotFound error
+func ReadBrokerConfig(ctx context.Context, reader client.Reader, namespaceName types.NamespacedName) (*BrokerConfig, error) {
+	configSecret := &corev1.Secret{}
+	if err := reader.Get(ctx, namespaceName, configSecret); err != nil {
+		return nil, fmt.Errorf("failed to read config secret: %w", err)
+	}
+	brokerConfig := &BrokerConfig{}
+	if err := yaml.Unmarshal(configSecret.Data[configFileName], brokerConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal broker config: %w", err)
+	}
+	return brokerConfig, nil
+}
+
 // readOrCreateConfigSecret reads the config secret or creates it if it doesn't exist.
 // It returns the parsed BrokerConfig and the Secret object (for subsequent updates).
 //
@@ -99,7 +130,7 @@ func (srw *SecretReaderWriter) WriteVirtualServerConfig(ctx context.Context, vir
 func (srw *SecretReaderWriter) readOrCreateConfigSecret(ctx context.Context, namespaceName types.NamespacedName) (*BrokerConfig, *corev1.Secret, error) {
 	srw.Logger.Info("SecretReaderWriter readOrCreateConfigSecret")
 	configSecret := &corev1.Secret{}
-	err := srw.Client.Get(ctx, namespaceName, configSecret)
+	err := srw.reader().Get(ctx, namespaceName, configSecret)
 	if err != nil {
 		if !errors.IsNotFound(err) {
 			return nil, nil, fmt.Errorf("failed to read config secret: %w", err)
@@ -110,8 +141,8 @@ func (srw *SecretReaderWriter) readOrCreateConfigSecret(ctx context.Context, nam
 				Name:      namespaceName.Name,
 				Namespace: namespaceName.Namespace,
 				Labels: map[string]string{
-					"app":                        "mcp-gateway",
-					"mcp.kuadrant.io/aggregated": "true",
+					"app":                 "mcp-gateway",
+					AggregatedSecretLabel: "true",
 				},
 			},
 			StringData: map[string]string{
@@ -123,7 +154,7 @@ func (srw *SecretReaderWriter) readOrCreateConfigSecret(ctx context.Context, nam
 				return nil, nil, fmt.Errorf("failed to create config secret: %w", err)
 			}
 			// re-fetch if already exists
-			if err := srw.Client.Get(ctx, namespaceName, configSecret); err != nil {
+			if err := srw.reader().Get(ctx, namespaceName, configSecret); err != nil {
 				return nil, nil, fmt.Errorf("failed to get config secret after create: %w", err)
 			}
 		}
@@ -168,6 +199,11 @@ func (srw *SecretReaderWriter) UpsertMCPServer(ctx context.Context, server MCPSe
 		for i, existing := range existingConfig.Servers {
 
 			if existing.Name == server.Name {
+				// skip update so the broker doesn't reload an unchanged config
+				if !DiffMCPServers(existing, server) {
+					srw.Logger.Debug("SecretReaderWriter mcpserver unchanged", "secret", namespaceName, "name", server.Name)
+					return nil
+				}
 				existingConfig.Servers[i] = server
 				found = true
 				break
@@ -187,6 +223,44 @@ func (srw *SecretReaderWriter) UpsertMCPServer(ctx context.Context, server MCPSe
 	})
 }
 
+// DiffMCPServers checks if the desired server differs from the existing server entry as written to the config secret.
+// Entries are compared as YAML so an empty list and an omitted one are equal. The generation is not part of the
+// comparison, so a registration change that doesn't change its config doesn't make the broker reload
+func DiffMCPServers(existing, desired MCPServer) bool {
+	existing.Generation, desired.Generation = 0, 0
+	existingYAML, err := yaml.Marshal(existing)
+	if err != nil {
+		return true
+	}
+	desiredYAML, err := yaml.Marshal(desired)
+	if err != nil {
+		return true
+	}
+	return !bytes.Equal(existingYAML, desiredYAML)
+}
+
+// ReadMCPServer returns the server entry written to the config secret, or nil when the secret or the entry doesn't
+// exist. The entry's generation is the generation of the last registration change that changed its config
+func (srw *SecretReaderWriter) ReadMCPServer(ctx context.Context, serverName string, namespaceName types.NamespacedName) (*MCPServer, error) {
+	existingConfig, err := ReadBrokerConfig(ctx, srw.reader(), namespaceName)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read mcpserver failed: %w", err)
+	}
+	return findMCPServer(existingConfig, serverName), nil
+}
+
+// findMCPServer returns the server entry with the given name, or nil when there is none
+func findMCPServer(brokerConfig *BrokerConfig, serverName string) *MCPServer {
+	i := slices.IndexFunc(brokerConfig.Servers, func(server MCPServer) bool { return server.Name == serverName })
+	if i < 0 {
+		return nil
+	}
+	return &brokerConfig.Servers[i]
+}
+
 // RemoveMCPServer removes a single MCPServer by name from all config secrets cluster-wide.
 // It finds all secrets with the "mcp.kuadrant.io/aggregated": "true" label and removes
 // the server from each. If the server doesn't exist in a secret, that secret is skipped.
@@ -195,8 +269,8 @@ func (srw *SecretReaderWriter) RemoveMCPServer(ctx context.Context, serverName s
 	// list all aggregated config
 	srw.Logger.Info("SecretReaderWriter RemoveMCPServer")
 	secretList := &corev1.SecretList{}
-	if err := srw.Client.List(ctx, secretList, client.MatchingLabels{
-		"mcp.kuadrant.io/aggregated": "true",
+	if err := srw.reader().List(ctx, secretList, client.MatchingLabels{
+		AggregatedSecretLabel: "true",
 	}); err != nil {
 		return fmt.Errorf("remove mcpserver failed to list config secrets: %w", err)
 	}
@@ -204,45 +278,95 @@ func (srw *SecretReaderWriter) RemoveMCPServer(ctx context.Context, serverName s
 	var lastErr error
 	for _, secret := range secretList.Items {
 		namespaceName := types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}
-		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
-			existingConfig, backingSecret, err := srw.readOrCreateConfigSecret(ctx, namespaceName)
-			if err != nil {
-				return fmt.Errorf("remove mcpserver failed to read config secret: %w", err)
-			}
+		if err := srw.removeServerFromSecret(ctx, serverName, namespaceName); err != nil {
+			lastErr = err
+			srw.Logger.Error("failed to remove server from config secret",
+				"error", err, "serverName", serverName, "namespace", secret.Namespace)
+		}
+	}
 
-			// check if server exists in this config
-			found := false
-			filtered := make([]MCPServer, 0, len(existingConfig.Servers))
-			for _, existing := range existingConfig.Servers {
-				if existing.Name == serverName {
-					found = true
-				} else {
-					filtered = append(filtered, existing)
-				}
-			}
+	return lastErr
+}
 
-			// skip update if server wasn't in this config
-			if !found {
-				return nil
-			}
+// RemoveMCPServerFromNamespace removes a single MCPServer by name from the config secret in one namespace.
+// If the secret or the server doesn't exist, this is a no-op and returns nil.
+func (srw *SecretReaderWriter) RemoveMCPServerFromNamespace(ctx context.Context, serverName string, namespaceName types.NamespacedName) error {
+	srw.Logger.Info("SecretReaderWriter RemoveMCPServerFromNamespace", "secret", namespaceName, "name", serverName)
+	if err := srw.reader().Get(ctx, namespaceName, &corev1.Secret{}); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("remove mcpserver failed to get config secret: %w", err)
+	}
+	return srw.removeServerFromSecret(ctx, serverName, namespaceName)
+}
+
+// MarkMCPServerDraining marks a single MCPServer by name as draining in the config secret in one namespace so the
+// broker stops listing its tools but keeps routing calls to it. If the secret or the server doesn't exist, this is a
+// no-op and returns nil.
+func (srw *SecretReaderWriter) MarkMCPServerDraining(ctx context.Context, serverName string, namespaceName types.NamespacedName) error {
+	srw.Logger.Info("SecretReaderWriter MarkMCPServerDraining", "secret", namespaceName, "name", serverName)
+	if err := srw.reader().Get(ctx, namespaceName, &corev1.Secret{}); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("mark mcpserver draining failed to get config secret: %w", err)
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		existingConfig, backingSecret, err := srw.readOrCreateConfigSecret(ctx, namespaceName)
+		if err != nil {
+			return fmt.Errorf("mark mcpserver draining failed to read config secret: %w", err)
+		}
+
+		i := slices.IndexFunc(existingConfig.Servers, func(existing MCPServer) bool { return existing.Name == serverName })
+		// skip update if server isn't in this config or is already draining
+		if i < 0 || existingConfig.Servers[i].Draining {
+			return nil
+		}
+		existingConfig.Servers[i].Draining = true
 
-			existingConfig.Servers = filtered
-			updated, err := yaml.Marshal(existingConfig)
-			if err != nil {
-				return fmt.Errorf("remove mcpserver failed to marshal config: %w", err)
+		updated, err := yaml.Marshal(existingConfig)
+		if err != nil {
+			return fmt.Errorf("mark mcpserver draining failed to marshal config: %w", err)
+		}
+		backingSecret.StringData[configFileName] = string(updated)
+		return srw.Client.Update(ctx, backingSecret)
+	})
+}
+
+// removeServerFromSecret removes the named server from the config secret, retrying on conflict
+func (srw *SecretReaderWriter) removeServerFromSecret(ctx context.Context, serverName string, namespaceName types.NamespacedName) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		existingConfig, backingSecret, err := srw.readOrCreateConfigSecret(ctx, namespaceName)
+		if err != nil {
+			return fmt.Errorf("remove mcpserver failed to read config secret: %w", err)
+		}
+
+		// check if server exists in this config
+		found := false
+		filtered := make([]MCPServer, 0, len(existingConfig.Servers))
+		for _, existing := range existingConfig.Servers {
+			if existing.Name == serverName {
+				found = true
+			} else {
+				filtered = append(filtered, existing)
 			}
+		}
 
-			backingSecret.StringData[configFileName] = string(updated)
-			return srw.Client.Update(ctx, backingSecret)
-		})
+		// skip update if server wasn't in this config
+		if !found {
+			return nil
+		}
+
+		existingConfig.Servers = filtered
+		updated, err := yaml.Marshal(existingConfig)
 		if err != nil {
-			lastErr = err
-			srw.Logger.Error("failed to remove server from config secret",
-				"error", err, "serverName", serverName, "namespace", secret.Namespace)
+			return fmt.Errorf("remove mcpserver failed to marshal config: %w", err)
 		}
-	}
 
-	return lastErr
+		backingSecret.StringData[configFileName] = string(updated)
+		return srw.Client.Update(ctx, backingSecret)
+	})
 }
 
 // DeleteConfig deletes the entire config secret. If the secret doesn't exist,
@@ -250,7 +374,7 @@ func (srw *SecretReaderWriter) RemoveMCPServer(ctx context.Context, serverName s
 func (srw *SecretReaderWriter) DeleteConfig(ctx context.Context, namespaceName types.NamespacedName) error {
 	srw.Logger.Debug("deleting config", "namespacename", namespaceName)
 	configSecret := &corev1.Secret{}
-	err := srw.Client.Get(ctx, namespaceName, configSecret)
+	err := srw.reader().Get(ctx, namespaceName, configSecret)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil
@@ -266,11 +390,24 @@ func (srw *SecretReaderWriter) DeleteConfig(ctx context.Context, namespaceName t
 	return nil
 }
 
-// EnsureConfigExists creates the config secret if it doesn't exist.
-// If the secret already exists, this is a no-op.
-func (srw *SecretReaderWriter) EnsureConfigExists(ctx context.Context, namespaceName types.NamespacedName) error {
-	_, _, err := srw.readOrCreateConfigSecret(ctx, namespaceName)
-	return err
+// EnsureConfigExists creates the config secret if it doesn't exist and sets owner as its
+// controller so the secret is garbage collected with the owner. If the secret already exists
+// and is controlled by owner, this is a no-op. A nil owner leaves ownership untouched.
+func (srw *SecretReaderWriter) EnsureConfigExists(ctx context.Context, namespaceName types.NamespacedName, owner client.Object) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		_, backingSecret, err := srw.readOrCreateConfigSecret(ctx, namespaceName)
+		if err != nil {
+			return err
+		}
+		if owner == nil || v1.IsControlledBy(backingSecret, owner) {
+			return nil
+		}
+		if err := controllerutil.SetControllerReference(owner, backingSecret, srw.Scheme); err != nil {
+			return fmt.Errorf("failed to set owner on config secret: %w", err)
+		}
+		srw.Logger.Info("SecretReaderWriter setting config secret owner", "secret", namespaceName, "owner", owner.GetName())
+		return srw.Client.Update(ctx, backingSecret)
+	})
 }
 
 // WriteEmptyConfig overwrites the config secret with an empty configuration.
@@ -2,13 +2,19 @@ package config
 
 import (
 	"context"
+	goerrors "errors"
 	"log/slog"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/yaml"
 )
 
@@ -22,12 +28,39 @@ func newTestSecretReaderWriter(t *testing.T) *SecretReaderWriter {
 		Build()
 	logger := slog.New(slog.DiscardHandler)
 	return &SecretReaderWriter{
-		Client: fakeClient,
+		Client: interceptor.NewClient(fakeClient, stringDataToData),
 		Scheme: scheme,
 		Logger: logger,
 	}
 }
 
+// stringDataToData merges the StringData of a written secret into its Data as the API server does. The fake client
+// stores StringData as is
+var stringDataToData = interceptor.Funcs{
+	Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+		mergeStringData(obj)
+		return c.Create(ctx, obj, opts...)
+	},
+	Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+		mergeStringData(obj)
+		return c.Update(ctx, obj, opts...)
+	},
+}
+
+func mergeStringData(obj client.Object) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || secret.StringData == nil {
+		return
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	for key, value := range secret.StringData {
+		secret.Data[key] = []byte(value)
+	}
+	secret.StringData = nil
+}
+
 func TestUpsertMCPServer(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -79,12 +112,8 @@ func TestUpsertMCPServer(t *testing.T) {
 				t.Fatalf("failed to get secret: %v", err)
 			}
 
-			configData := secret.StringData[configFileName]
-			if configData == "" {
-				configData = string(secret.Data[configFileName])
-			}
 			var config BrokerConfig
-			if err := yaml.Unmarshal([]byte(configData), &config); err != nil {
+			if err := yaml.Unmarshal(secret.Data[configFileName], &config); err != nil {
 				t.Fatalf("failed to unmarshal config: %v", err)
 			}
 
@@ -107,6 +136,152 @@ func TestUpsertMCPServer(t *testing.T) {
 	}
 }
 
+func TestUpsertMCPServer_Unchanged(t *testing.T) {
+	srw := newTestSecretReaderWriter(t)
+	updates := 0
+	srw.Client = interceptor.NewClient(srw.Client.(client.WithWatch), interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			updates++
+			return c.Update(ctx, obj, opts...)
+		},
+	})
+	ctx := context.Background()
+	namespaceName := types.NamespacedName{Namespace: "test-ns", Name: "mcp-gateway-config"}
+	server := MCPServer{Name: "test-server", URL: "http://test.local:8080/mcp", ToolPrefix: "test_", Enabled: true}
+
+	if err := srw.UpsertMCPServer(ctx, server, namespaceName); err != nil {
+		t.Fatalf("UpsertMCPServer failed: %v", err)
+	}
+	if updates != 1 {
+		t.Fatalf("expected 1 update adding the server, got %d", updates)
+	}
+
+	// an empty list is written the same as an omitted one
+	server.Categories = []string{}
+	if err := srw.UpsertMCPServer(ctx, server, namespaceName); err != nil {
+		t.Fatalf("UpsertMCPServer failed: %v", err)
+	}
+	if updates != 1 {
+		t.Fatalf("expected no update for an unchanged server, got %d updates", updates)
+	}
+
+	// a registration change that doesn't change the config keeps the generation of the entry as written
+	server.Generation = 2
+	if err := srw.UpsertMCPServer(ctx, server, namespaceName); err != nil {
+		t.Fatalf("UpsertMCPServer failed: %v", err)
+	}
+	if updates != 1 {
+		t.Fatalf("expected no update for a changed generation only, got %d updates", updates)
+	}
+	written, err := srw.ReadMCPServer(ctx, server.Name, namespaceName)
+	if err != nil {
+		t.Fatalf("ReadMCPServer failed: %v", err)
+	}
+	if written.Generation != 0 {
+		t.Fatalf("expected the written generation to be kept, got %d", written.Generation)
+	}
+
+	server.ToolPrefix = "new_"
+	if err := srw.UpsertMCPServer(ctx, server, namespaceName); err != nil {
+		t.Fatalf("UpsertMCPServer failed: %v", err)
+	}
+	if updates != 2 {
+		t.Fatalf("expected an update for a changed server, got %d updates", updates)
+	}
+	written, err = srw.ReadMCPServer(ctx, server.Name, namespaceName)
+	if err != nil {
+		t.Fatalf("ReadMCPServer failed: %v", err)
+	}
+	if written.Generation != 2 {
+		t.Fatalf("expected generation 2 to be written with the change, got %d", written.Generation)
+	}
+}
+
+func TestReadMCPServer_Missing(t *testing.T) {
+	srw := newTestSecretReaderWriter(t)
+	ctx := context.Background()
+	namespaceName := types.NamespacedName{Namespace: "test-ns", Name: "mcp-gateway-config"}
+	server, err := srw.ReadMCPServer(ctx, "test-server", namespaceName)
+	if err != nil {
+		t.Fatalf("ReadMCPServer failed: %v", err)
+	}
+	if server != nil {
+		t.Fatalf("expected no server without a config secret, got %+v", server)
+	}
+
+	if err := srw.UpsertMCPServer(ctx, MCPServer{Name: "other-server", URL: "http://other.local/mcp"}, namespaceName); err != nil {
+		t.Fatalf("UpsertMCPServer failed: %v", err)
+	}
+	server, err = srw.ReadMCPServer(ctx, "test-server", namespaceName)
+	if err != nil {
+		t.Fatalf("ReadMCPServer failed: %v", err)
+	}
+	if server != nil {
+		t.Fatalf("expected no server missing from the config, got %+v", server)
+	}
+}
+
+func TestUpsertMCPServer_Reader(t *testing.T) {
+	srw := newTestSecretReaderWriter(t)
+	ctx := context.Background()
+	namespaceName := types.NamespacedName{Namespace: "test-ns", Name: "mcp-gateway-config"}
+	if err := srw.UpsertMCPServer(ctx, MCPServer{Name: "server1", URL: "http://s1.local/mcp", Enabled: true}, namespaceName); err != nil {
+		t.Fatalf("UpsertMCPServer failed: %v", err)
+	}
+
+	// a client whose cache only holds labeled secrets does not see the config secret
+	srw.Reader = srw.Client
+	srw.Client = interceptor.NewClient(srw.Client.(client.WithWatch), interceptor.Funcs{
+		Get: func(_ context.Context, _ client.WithWatch, key client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+			return errors.NewNotFound(corev1.Resource("secrets"), key.Name)
+		},
+		List: func(_ context.Context, _ client.WithWatch, _ client.ObjectList, _ ...client.ListOption) error {
+			return nil
+		},
+	})
+
+	if err := srw.UpsertMCPServer(ctx, MCPServer{Name: "server2", URL: "http://s2.local/mcp", Enabled: true}, namespaceName); err != nil {
+		t.Fatalf("UpsertMCPServer failed: %v", err)
+	}
+	if err := srw.RemoveMCPServer(ctx, "server1"); err != nil {
+		t.Fatalf("RemoveMCPServer failed: %v", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := srw.Reader.Get(ctx, namespaceName, secret); err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	var config BrokerConfig
+	if err := yaml.Unmarshal(secret.Data[configFileName], &config); err != nil {
+		t.Fatalf("failed to unmarshal config: %v", err)
+	}
+	if len(config.Servers) != 1 || config.Servers[0].Name != "server2" {
+		t.Fatalf("expected only server2 in the config, got %+v", config.Servers)
+	}
+}
+
+func TestDiffMCPServers(t *testing.T) {
+	existing := MCPServer{Name: "test-server", URL: "http://test.local:8080/mcp", Categories: []string{"search"}}
+	if DiffMCPServers(existing, existing) {
+		t.Error("expected identical servers not to differ")
+	}
+	desired := existing
+	desired.TLS = &TLSConfig{InsecureSkipVerify: true}
+	if !DiffMCPServers(existing, desired) {
+		t.Error("expected servers with different tls config to differ")
+	}
+	desired = existing
+	desired.Draining = true
+	if !DiffMCPServers(existing, desired) {
+		t.Error("expected a draining server to differ")
+	}
+	desired = existing
+	desired.Generation = 3
+	if DiffMCPServers(existing, desired) {
+		t.Error("expected servers differing only in generation not to differ")
+	}
+}
+
 func TestRemoveMCPServer_RemovesFromConfig(t *testing.T) {
 	srw := newTestSecretReaderWriter(t)
 	ctx := context.Background()
@@ -133,12 +308,8 @@ func TestRemoveMCPServer_RemovesFromConfig(t *testing.T) {
 		t.Fatalf("failed to get secret: %v", err)
 	}
 
-	configData := secret.StringData[configFileName]
-	if configData == "" {
-		configData = string(secret.Data[configFileName])
-	}
 	var config BrokerConfig
-	if err := yaml.Unmarshal([]byte(configData), &config); err != nil {
+	if err := yaml.Unmarshal(secret.Data[configFileName], &config); err != nil {
 		t.Fatalf("failed to unmarshal config: %v", err)
 	}
 
@@ -150,12 +321,93 @@ func TestRemoveMCPServer_RemovesFromConfig(t *testing.T) {
 	}
 }
 
+func TestRemoveMCPServerFromNamespace(t *testing.T) {
+	srw := newTestSecretReaderWriter(t)
+	ctx := context.Background()
+	staleNamespace := types.NamespacedName{Namespace: "stale-ns", Name: "mcp-gateway-config"}
+	currentNamespace := types.NamespacedName{Namespace: "current-ns", Name: "mcp-gateway-config"}
+
+	server1 := MCPServer{Name: "server1", URL: "http://s1.local/mcp", Enabled: true}
+	for _, namespaceName := range []types.NamespacedName{staleNamespace, currentNamespace} {
+		if err := srw.UpsertMCPServer(ctx, server1, namespaceName); err != nil {
+			t.Fatalf("UpsertMCPServer failed: %v", err)
+		}
+	}
+
+	if err := srw.RemoveMCPServerFromNamespace(ctx, "server1", staleNamespace); err != nil {
+		t.Fatalf("RemoveMCPServerFromNamespace failed: %v", err)
+	}
+
+	serverCount := func(namespaceName types.NamespacedName) int {
+		secret := &corev1.Secret{}
+		if err := srw.Client.Get(ctx, namespaceName, secret); err != nil {
+			t.Fatalf("failed to get secret: %v", err)
+		}
+		var config BrokerConfig
+		if err := yaml.Unmarshal(secret.Data[configFileName], &config); err != nil {
+			t.Fatalf("failed to unmarshal config: %v", err)
+		}
+		return len(config.Servers)
+	}
+	if count := serverCount(staleNamespace); count != 0 {
+		t.Fatalf("expected server to be removed from stale namespace, got %d servers", count)
+	}
+	if count := serverCount(currentNamespace); count != 1 {
+		t.Fatalf("expected server to remain in current namespace, got %d servers", count)
+	}
+
+	// a missing secret is not recreated
+	missing := types.NamespacedName{Namespace: "missing-ns", Name: "mcp-gateway-config"}
+	if err := srw.RemoveMCPServerFromNamespace(ctx, "server1", missing); err != nil {
+		t.Fatalf("RemoveMCPServerFromNamespace failed for missing secret: %v", err)
+	}
+	if err := srw.Client.Get(ctx, missing, &corev1.Secret{}); err == nil {
+		t.Fatal("expected missing secret not to be created")
+	}
+}
+
+func TestMarkMCPServerDraining(t *testing.T) {
+	srw := newTestSecretReaderWriter(t)
+	ctx := context.Background()
+	namespaceName := types.NamespacedName{Namespace: "mcp-system", Name: "mcp-gateway-config"}
+
+	for _, server := range []MCPServer{{Name: "server1", URL: "http://s1.local/mcp"}, {Name: "server2", URL: "http://s2.local/mcp"}} {
+		if err := srw.UpsertMCPServer(ctx, server, namespaceName); err != nil {
+			t.Fatalf("UpsertMCPServer failed: %v", err)
+		}
+	}
+	if err := srw.MarkMCPServerDraining(ctx, "server1", namespaceName); err != nil {
+		t.Fatalf("MarkMCPServerDraining failed: %v", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := srw.Client.Get(ctx, namespaceName, secret); err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	var config BrokerConfig
+	if err := yaml.Unmarshal(secret.Data[configFileName], &config); err != nil {
+		t.Fatalf("failed to unmarshal config: %v", err)
+	}
+	if len(config.Servers) != 2 || !config.Servers[0].Draining || config.Servers[1].Draining {
+		t.Fatalf("expected only server1 to be draining, got %+v", config.Servers)
+	}
+
+	// a missing secret is not created
+	missing := types.NamespacedName{Namespace: "missing-ns", Name: "mcp-gateway-config"}
+	if err := srw.MarkMCPServerDraining(ctx, "server1", missing); err != nil {
+		t.Fatalf("MarkMCPServerDraining failed for missing secret: %v", err)
+	}
+	if err := srw.Client.Get(ctx, missing, &corev1.Secret{}); err == nil {
+		t.Fatal("expected missing secret not to be created")
+	}
+}
+
 func TestEnsureConfigExists_CreatesSecretIfNotExists(t *testing.T) {
 	srw := newTestSecretReaderWriter(t)
 	ctx := context.Background()
 	namespaceName := types.NamespacedName{Namespace: "test-ns", Name: "mcp-gateway-config"}
 
-	if err := srw.EnsureConfigExists(ctx, namespaceName); err != nil {
+	if err := srw.EnsureConfigExists(ctx, namespaceName, nil); err != nil {
 		t.Fatalf("EnsureConfigExists failed: %v", err)
 	}
 
@@ -171,6 +423,56 @@ func TestEnsureConfigExists_CreatesSecretIfNotExists(t *testing.T) {
 	}
 }
 
+func TestEnsureConfigExists_SetsOwnerReference(t *testing.T) {
+	srw := newTestSecretReaderWriter(t)
+	ctx := context.Background()
+	namespaceName := types.NamespacedName{Namespace: "test-ns", Name: "mcp-gateway-config"}
+	owner := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "test-ns", UID: "owner-uid"},
+	}
+
+	// create without an owner first to cover secrets from before ownership was set
+	if err := srw.EnsureConfigExists(ctx, namespaceName, nil); err != nil {
+		t.Fatalf("EnsureConfigExists failed: %v", err)
+	}
+	for range 2 {
+		if err := srw.EnsureConfigExists(ctx, namespaceName, owner); err != nil {
+			t.Fatalf("EnsureConfigExists failed: %v", err)
+		}
+	}
+
+	secret := &corev1.Secret{}
+	if err := srw.Client.Get(ctx, namespaceName, secret); err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	if !metav1.IsControlledBy(secret, owner) {
+		t.Fatalf("expected secret to be controlled by owner, got owner references %v", secret.OwnerReferences)
+	}
+	if len(secret.OwnerReferences) != 1 {
+		t.Fatalf("expected 1 owner reference, got %d", len(secret.OwnerReferences))
+	}
+}
+
+func TestEnsureConfigExists_AlreadyOwned(t *testing.T) {
+	srw := newTestSecretReaderWriter(t)
+	ctx := context.Background()
+	namespaceName := types.NamespacedName{Namespace: "test-ns", Name: "mcp-gateway-config"}
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "test-ns", UID: "owner-uid"}}
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test-ns", UID: "other-uid"}}
+
+	if err := srw.EnsureConfigExists(ctx, namespaceName, other); err != nil {
+		t.Fatalf("EnsureConfigExists failed: %v", err)
+	}
+	err := srw.EnsureConfigExists(ctx, namespaceName, owner)
+	var alreadyOwned *controllerutil.AlreadyOwnedError
+	if !goerrors.As(err, &alreadyOwned) {
+		t.Fatalf("expected an AlreadyOwnedError, got %v", err)
+	}
+	if alreadyOwned.Owner.Name != "other" {
+		t.Fatalf("expected the secret to be owned by other, got %s", alreadyOwned.Owner.Name)
+	}
+}
+
 func TestDeleteConfig(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -199,7 +501,7 @@ func TestDeleteConfig(t *testing.T) {
 			namespaceName := types.NamespacedName{Namespace: "test-ns", Name: tc.secretName}
 
 			if tc.createFirst {
-				if err := srw.EnsureConfigExists(ctx, namespaceName); err != nil {
+				if err := srw.EnsureConfigExists(ctx, namespaceName, nil); err != nil {
 					t.Fatalf("EnsureConfigExists failed: %v", err)
 				}
 			}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DryRunReaderWriter logs the config changes the controllers would make instead of writing them. It implements the
+// same writes as SecretReaderWriter and reads the existing config secrets so each log says what would change
+type DryRunReaderWriter struct {
+	Reader client.Reader
+	Logger *slog.Logger
+}
+
+// readConfig reads the config secret without creating it. A missing secret is an empty config
+func (drw *DryRunReaderWriter) readConfig(ctx context.Context, namespaceName types.NamespacedName) (*BrokerConfig, error) {
+	existingConfig, err := ReadBrokerConfig(ctx, drw.Reader, namespaceName)
+	if errors.IsNotFound(err) {
+		return &BrokerConfig{}, nil
+	}
+	return existingConfig, err
+}
+
+// UpsertMCPServer logs whether the server would be added to or updated in the config secret
+func (drw *DryRunReaderWriter) UpsertMCPServer(ctx context.Context, server MCPServer, namespaceName types.NamespacedName) error {
+	existingConfig, err := drw.readConfig(ctx, namespaceName)
+	if err != nil {
+		return fmt.Errorf("dry run upsert mcpserver: %w", err)
+	}
+	change := "add"
+	if i := slices.IndexFunc(existingConfig.Servers, func(existing MCPServer) bool { return existing.Name == server.Name }); i >= 0 {
+		change = "update"
+		if !DiffMCPServers(existingConfig.Servers[i], server) {
+			change = "none"
+		}
+	}
+	drw.Logger.Info("dry run: not writing mcpserver to config secret", "secret", namespaceName, "name", server.Name,
+		"url", server.URL, "change", change)
+	return nil
+}
+
+// ReadMCPServer returns the server entry in the config secret, or nil when the secret or the entry doesn't exist
+func (drw *DryRunReaderWriter) ReadMCPServer(ctx context.Context, serverName string, namespaceName types.NamespacedName) (*MCPServer, error) {
+	existingConfig, err := drw.readConfig(ctx, namespaceName)
+	if err != nil {
+		return nil, fmt.Errorf("dry run read mcpserver: %w", err)
+	}
+	return findMCPServer(existingConfig, serverName), nil
+}
+
+// RemoveMCPServer logs the server that would be removed from all config secrets
+func (drw *DryRunReaderWriter) RemoveMCPServer(_ context.Context, serverName string) error {
+	drw.Logger.Info("dry run: not removing mcpserver from config secrets", "name", serverName)
+	return nil
+}
+
+// RemoveMCPServerFromNamespace logs the server that would be removed from the config secret in one namespace
+func (drw *DryRunReaderWriter) RemoveMCPServerFromNamespace(_ context.Context, serverName string, namespaceName types.NamespacedName) error {
+	drw.Logger.Info("dry run: not removing mcpserver from config secret", "secret", namespaceName, "name", serverName)
+	return nil
+}
+
+// MarkMCPServerDraining logs the server that would be marked draining
+func (drw *DryRunReaderWriter) MarkMCPServerDraining(_ context.Context, serverName string, namespaceName types.NamespacedName) error {
+	drw.Logger.Info("dry run: not marking mcpserver draining in config secret", "secret", namespaceName, "name", serverName)
+	return nil
+}
+
+// WriteVirtualServerConfig logs the virtual servers that would be written
+func (drw *DryRunReaderWriter) WriteVirtualServerConfig(_ context.Context, virtualServers []VirtualServerConfig, namespaceName types.NamespacedName) error {
+	drw.Logger.Info("dry run: not writing virtual servers to config secret", "secret", namespaceName, "total", len(virtualServers))
+	return nil
+}
+
+// DeleteConfig logs the config secret that would be deleted
+func (drw *DryRunReaderWriter) DeleteConfig(_ context.Context, namespaceName types.NamespacedName) error {
+	drw.Logger.Info("dry run: not deleting config secret", "secret", namespaceName)
+	return nil
+}
+
+// EnsureConfigExists logs the config secret that would be created or given an owner
+func (drw *DryRunReaderWriter) EnsureConfigExists(_ context.Context, namespaceName types.NamespacedName, _ client.Object) error {
+	drw.Logger.Info("dry run: not ensuring config secret exists", "secret", namespaceName)
+	return nil
+}
+
+// WriteEmptyConfig logs the config secret that would be emptied
+func (drw *DryRunReaderWriter) WriteEmptyConfig(_ context.Context, namespaceName types.NamespacedName) error {
+	drw.Logger.Info("dry run: not writing empty config secret", "secret", namespaceName)
+	return nil
+}
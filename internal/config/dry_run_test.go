@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
+)
+
+func TestDryRunReaderWriter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add corev1 to scheme: %v", err)
+	}
+	namespaceName := NamespaceName("test-ns")
+	existing := MCPServer{Name: "existing", URL: "http://existing.local/mcp"}
+	configYAML, err := yaml.Marshal(BrokerConfig{Servers: []MCPServer{existing}})
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+	configSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: namespaceName.Name, Namespace: namespaceName.Namespace},
+		Data:       map[string][]byte{configFileName: configYAML},
+	}
+	writes := 0
+	fakeClient := interceptor.NewClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(configSecret).Build(), interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			writes++
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			writes++
+			return c.Update(ctx, obj, opts...)
+		},
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			writes++
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+	var logs bytes.Buffer
+	drw := &DryRunReaderWriter{Reader: fakeClient, Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	ctx := context.Background()
+
+	changed := existing
+	changed.ToolPrefix = "new_"
+	for _, write := range []func() error{
+		func() error { return drw.UpsertMCPServer(ctx, MCPServer{Name: "added"}, namespaceName) },
+		func() error { return drw.UpsertMCPServer(ctx, changed, namespaceName) },
+		func() error { return drw.UpsertMCPServer(ctx, existing, namespaceName) },
+		func() error { return drw.UpsertMCPServer(ctx, MCPServer{Name: "added"}, NamespaceName("missing-ns")) },
+		func() error { return drw.RemoveMCPServer(ctx, "existing") },
+		func() error { return drw.RemoveMCPServerFromNamespace(ctx, "existing", namespaceName) },
+		func() error { return drw.MarkMCPServerDraining(ctx, "existing", namespaceName) },
+		func() error {
+			return drw.WriteVirtualServerConfig(ctx, []VirtualServerConfig{{Name: "test-ns/vs"}}, namespaceName)
+		},
+		func() error { return drw.EnsureConfigExists(ctx, namespaceName, nil) },
+		func() error { return drw.WriteEmptyConfig(ctx, namespaceName) },
+		func() error { return drw.DeleteConfig(ctx, namespaceName) },
+	} {
+		if err := write(); err != nil {
+			t.Fatalf("dry run write failed: %v", err)
+		}
+	}
+
+	if writes != 0 {
+		t.Fatalf("expected no writes in dry run, got %d", writes)
+	}
+	secret := &corev1.Secret{}
+	if err := fakeClient.Get(ctx, namespaceName, secret); err != nil {
+		t.Fatalf("failed to get config secret: %v", err)
+	}
+	if !bytes.Equal(configYAML, secret.Data[configFileName]) {
+		t.Errorf("expected config secret to be unchanged, got %s", secret.Data[configFileName])
+	}
+	for _, expected := range []string{"name=added url=\"\" change=add", "name=existing url=http://existing.local/mcp change=update",
+		"name=existing url=http://existing.local/mcp change=none", "dry run: not deleting config secret"} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("expected logs to contain %q, got:\n%s", expected, logs.String())
+		}
+	}
+}
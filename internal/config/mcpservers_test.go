@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -156,6 +157,27 @@ func TestMCPServer_ConfigChanged(t *testing.T) {
 			},
 			expectChanged: false,
 		},
+		{
+			name:          "force sync changed",
+			current:       &MCPServer{Name: "server1", ToolPrefix: "s1_", ForceSync: "2026-10-15T12:00:00Z"},
+			existing:      MCPServer{Name: "server1", ToolPrefix: "s1_"},
+			expectChanged: true,
+		},
+		{
+			name: "draining keeps the connection",
+			current: &MCPServer{
+				Name:       "server1",
+				ToolPrefix: "s1_",
+				Hostname:   "server1.local",
+				Draining:   true,
+			},
+			existing: MCPServer{
+				Name:       "server1",
+				ToolPrefix: "s1_",
+				Hostname:   "server1.local",
+			},
+			expectChanged: false,
+		},
 		{
 			name: "name changed",
 			current: &MCPServer{
@@ -205,7 +227,7 @@ func TestMCPServer_ConfigChanged(t *testing.T) {
 			expectChanged: true,
 		},
 		{
-			name: "credential changed",
+			name: "credential changed does not trigger change",
 			current: &MCPServer{
 				Name:       "server1",
 				ToolPrefix: "s1_",
@@ -218,7 +240,7 @@ func TestMCPServer_ConfigChanged(t *testing.T) {
 				Hostname:   "server1.local",
 				Credential: "CRED_VAR",
 			},
-			expectChanged: true,
+			expectChanged: false,
 		},
 		{
 			name: "URL changed does not trigger change",
@@ -256,6 +278,168 @@ func TestMCPServer_ConfigChanged(t *testing.T) {
 			},
 			expectChanged: false,
 		},
+		{
+			name: "tool overrides changed",
+			current: &MCPServer{
+				Name:          "server1",
+				ToolPrefix:    "s1_",
+				ToolOverrides: []ToolOverride{{Name: "search", Deprecated: true}},
+			},
+			existing: MCPServer{
+				Name:       "server1",
+				ToolPrefix: "s1_",
+			},
+			expectChanged: true,
+		},
+		{
+			name: "categories changed",
+			current: &MCPServer{
+				Name:       "server1",
+				Categories: []string{"search"},
+			},
+			existing: MCPServer{
+				Name: "server1",
+			},
+			expectChanged: true,
+		},
+		{
+			name: "tool override categories changed",
+			current: &MCPServer{
+				Name:          "server1",
+				ToolOverrides: []ToolOverride{{Name: "search", Categories: []string{"admin"}}},
+			},
+			existing: MCPServer{
+				Name:          "server1",
+				ToolOverrides: []ToolOverride{{Name: "search"}},
+			},
+			expectChanged: true,
+		},
+		{
+			name: "priority changed",
+			current: &MCPServer{
+				Name:     "server1",
+				Priority: 10,
+			},
+			existing: MCPServer{
+				Name: "server1",
+			},
+			expectChanged: true,
+		},
+		{
+			name: "unavailable policy changed",
+			current: &MCPServer{
+				Name:              "server1",
+				UnavailablePolicy: UnavailablePolicyKeepTools,
+			},
+			existing: MCPServer{
+				Name: "server1",
+			},
+			expectChanged: true,
+		},
+		{
+			name: "health path changed",
+			current: &MCPServer{
+				Name:       "server1",
+				HealthPath: "/healthz",
+			},
+			existing: MCPServer{
+				Name: "server1",
+			},
+			expectChanged: true,
+		},
+		{
+			name: "tls config changed",
+			current: &MCPServer{
+				Name: "server1",
+				TLS:  &TLSConfig{CACert: "new"},
+			},
+			existing: MCPServer{
+				Name: "server1",
+				TLS:  &TLSConfig{CACert: "old"},
+			},
+			expectChanged: true,
+		},
+		{
+			name: "tls config unchanged",
+			current: &MCPServer{
+				Name: "server1",
+				TLS:  &TLSConfig{CACert: "ca", InsecureSkipVerify: true},
+			},
+			existing: MCPServer{
+				Name: "server1",
+				TLS:  &TLSConfig{CACert: "ca", InsecureSkipVerify: true},
+			},
+			expectChanged: false,
+		},
+		{
+			name: "protocol changed",
+			current: &MCPServer{
+				Name:     "server1",
+				Protocol: ProtocolH2C,
+			},
+			existing: MCPServer{
+				Name: "server1",
+			},
+			expectChanged: true,
+		},
+		{
+			name: "broker url changed",
+			current: &MCPServer{
+				Name:      "server1",
+				BrokerURL: "http://server1.local/v2/mcp",
+			},
+			existing: MCPServer{
+				Name:      "server1",
+				BrokerURL: "http://server1.local/v1/mcp",
+			},
+			expectChanged: true,
+		},
+		{
+			name: "headers changed",
+			current: &MCPServer{
+				Name:    "server1",
+				Headers: map[string]string{"x-tenant": "team-b"},
+			},
+			existing: MCPServer{
+				Name:    "server1",
+				Headers: map[string]string{"x-tenant": "team-a"},
+			},
+			expectChanged: true,
+		},
+		{
+			name: "health check interval changed",
+			current: &MCPServer{
+				Name:                "server1",
+				HealthCheckInterval: "5m",
+			},
+			existing: MCPServer{
+				Name: "server1",
+			},
+			expectChanged: true,
+		},
+		{
+			name: "creation timestamp changed",
+			current: &MCPServer{
+				Name:              "server1",
+				CreationTimestamp: 1767225600,
+			},
+			existing: MCPServer{
+				Name: "server1",
+			},
+			expectChanged: true,
+		},
+		{
+			name: "call timeout changed",
+			current: &MCPServer{
+				Name:        "server1",
+				CallTimeout: "2m0s",
+			},
+			existing: MCPServer{
+				Name:        "server1",
+				CallTimeout: "30s",
+			},
+			expectChanged: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -266,6 +450,201 @@ func TestMCPServer_ConfigChanged(t *testing.T) {
 	}
 }
 
+func TestMCPServer_ConfigChanged_ToolNameTemplate(t *testing.T) {
+	current := &MCPServer{Name: "server1", ToolPrefix: "s1_", ToolNameTemplate: "{tool}_weather"}
+	require.True(t, current.ConfigChanged(MCPServer{Name: "server1", ToolPrefix: "s1_"}))
+	require.False(t, current.ConfigChanged(*current))
+}
+
+func TestMCPServer_ServedToolName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		server   MCPServer
+		upstream string
+		served   string
+		prefix   string
+	}{
+		{
+			name:     "no prefix or template",
+			server:   MCPServer{},
+			upstream: "forecast",
+			served:   "forecast",
+		},
+		{
+			name:     "prefix without template",
+			server:   MCPServer{ToolPrefix: "weather_"},
+			upstream: "forecast",
+			served:   "weather_forecast",
+			prefix:   "weather_",
+		},
+		{
+			name:     "suffix template",
+			server:   MCPServer{ToolNameTemplate: "{tool}_weather"},
+			upstream: "forecast",
+			served:   "forecast_weather",
+		},
+		{
+			name:     "template referencing the prefix",
+			server:   MCPServer{ToolPrefix: "weather", ToolNameTemplate: "{prefix}-{tool}-v1"},
+			upstream: "forecast",
+			served:   "weather-forecast-v1",
+			prefix:   "weather-",
+		},
+		{
+			name:     "template ignores the prefix unless referenced",
+			server:   MCPServer{ToolPrefix: "weather_", ToolNameTemplate: "{tool}"},
+			upstream: "forecast",
+			served:   "forecast",
+		},
+		{
+			name:     "template without a tool placeholder is a prefix",
+			server:   MCPServer{ToolNameTemplate: "weather."},
+			upstream: "forecast",
+			served:   "weather.forecast",
+			prefix:   "weather.",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.served, tc.server.ServedToolName(tc.upstream))
+			require.Equal(t, tc.prefix, tc.server.ServedToolNamePrefix())
+			upstream, ok := tc.server.UpstreamToolName(tc.served)
+			require.True(t, ok)
+			require.Equal(t, tc.upstream, upstream)
+		})
+	}
+}
+
+func TestMCPServer_UpstreamToolName_NotRendered(t *testing.T) {
+	server := MCPServer{ToolPrefix: "weather", ToolNameTemplate: "{prefix}_{tool}_v1"}
+	for _, served := range []string{"forecast", "weather_forecast", "forecast_v1", "weather__v1"} {
+		upstream, ok := server.UpstreamToolName(served)
+		require.False(t, ok, served)
+		require.Equal(t, served, upstream)
+	}
+}
+
+func TestMCPServer_ToolAliases(t *testing.T) {
+	server := MCPServer{ToolPrefix: "weather_", ToolAliases: map[string]string{"time": "weather_clock"}}
+
+	require.Equal(t, "weather_clock", server.ServedToolName("time"))
+	require.Equal(t, "weather_forecast", server.ServedToolName("forecast"))
+
+	upstream, ok := server.UpstreamToolName("weather_clock")
+	require.True(t, ok)
+	require.Equal(t, "time", upstream)
+	upstream, ok = server.UpstreamToolName("weather_forecast")
+	require.True(t, ok)
+	require.Equal(t, "forecast", upstream)
+
+	// the templated name of an aliased tool is not served so it doesn't map back to the tool
+	upstream, ok = server.UpstreamToolName("weather_time")
+	require.False(t, ok)
+	require.Equal(t, "weather_time", upstream)
+
+	require.True(t, server.ConfigChanged(MCPServer{ToolPrefix: "weather_"}))
+	require.True(t, server.ConfigChanged(MCPServer{ToolPrefix: "weather_", ToolAliases: map[string]string{"time": "clock"}}))
+	require.False(t, server.ConfigChanged(MCPServer{ToolPrefix: "weather_", ToolAliases: map[string]string{"time": "weather_clock"}}))
+}
+
+func TestMCPServer_CheckInterval(t *testing.T) {
+	interval, err := (&MCPServer{}).CheckInterval()
+	require.NoError(t, err)
+	require.Zero(t, interval)
+
+	interval, err = (&MCPServer{HealthCheckInterval: "1m30s"}).CheckInterval()
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Second, interval)
+
+	_, err = (&MCPServer{HealthCheckInterval: "often"}).CheckInterval()
+	require.Error(t, err)
+}
+
+func TestMCPServer_RequestTimeout(t *testing.T) {
+	timeout, err := (&MCPServer{}).RequestTimeout()
+	require.NoError(t, err)
+	require.Zero(t, timeout)
+
+	timeout, err = (&MCPServer{CallTimeout: "2m"}).RequestTimeout()
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Minute, timeout)
+
+	_, err = (&MCPServer{CallTimeout: "forever"}).RequestTimeout()
+	require.Error(t, err)
+}
+
+func TestTLSConfig_Transport(t *testing.T) {
+	transport, err := (&TLSConfig{InsecureSkipVerify: true}).Transport()
+	require.NoError(t, err)
+	require.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	require.Nil(t, transport.TLSClientConfig.RootCAs)
+
+	_, err = (&TLSConfig{CACert: "not a certificate"}).Transport()
+	require.ErrorContains(t, err, "no certificates found")
+
+	_, err = (&TLSConfig{ClientCert: "not a certificate", ClientKey: "not a key"}).Transport()
+	require.ErrorContains(t, err, "invalid client certificate")
+}
+
+func TestMCPServer_Transport(t *testing.T) {
+	transport, err := (&MCPServer{}).Transport()
+	require.NoError(t, err)
+	require.Nil(t, transport)
+
+	transport, err = (&MCPServer{Protocol: ProtocolH2C}).Transport()
+	require.NoError(t, err)
+	require.True(t, transport.Protocols.UnencryptedHTTP2())
+	require.False(t, transport.Protocols.HTTP1())
+
+	transport, err = (&MCPServer{TLS: &TLSConfig{InsecureSkipVerify: true}}).Transport()
+	require.NoError(t, err)
+	require.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+}
+
+func TestMatchToolPattern(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		toolName string
+		expected bool
+	}{
+		{pattern: "server1_weather", toolName: "server1_weather", expected: true},
+		{pattern: "server1_weather", toolName: "server1_weather_today", expected: false},
+		{pattern: "server1_weather_*", toolName: "server1_weather_today", expected: true},
+		{pattern: "server1_weather_*", toolName: "server1_weather_", expected: true},
+		{pattern: "server1_weather_*", toolName: "server2_weather_today", expected: false},
+		{pattern: "*_today", toolName: "server1_weather_today", expected: true},
+		{pattern: "server1_*_today", toolName: "server1_weather_today", expected: true},
+		{pattern: "server1_*_today", toolName: "server1_weather_week", expected: false},
+		{pattern: "*a*a", toolName: "a", expected: false},
+		{pattern: "*", toolName: "anything", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.toolName, func(t *testing.T) {
+			require.Equal(t, tc.expected, MatchToolPattern(tc.pattern, tc.toolName))
+		})
+	}
+}
+
+func TestVirtualServer_Allows(t *testing.T) {
+	vs := &VirtualServer{Name: "mcp-test/vs", Tools: []string{"server1_tool1", "server2_*"}}
+	require.True(t, vs.Allows("server1_tool1"))
+	require.True(t, vs.Allows("server2_tool1"))
+	require.False(t, vs.Allows("server1_tool2"))
+}
+
+func TestVirtualServer_AllowsServerTool(t *testing.T) {
+	vs := &VirtualServer{Name: "mcp-test/vs", Servers: []VirtualServerScope{
+		{Name: "team-a/weather"},
+		{Name: "team-b/news", Tools: []string{"headlines", "search_*"}},
+	}}
+	require.True(t, vs.AllowsServerTool("team-a/weather", "forecast"))
+	require.True(t, vs.AllowsServerTool("team-b/news", "headlines"))
+	require.True(t, vs.AllowsServerTool("team-b/news", "search_archive"))
+	require.False(t, vs.AllowsServerTool("team-b/news", "sports"))
+	require.False(t, vs.AllowsServerTool("team-c/travel", "forecast"))
+}
+
 func TestMCPServersConfig_GetServerConfigByName(t *testing.T) {
 	servers := []*MCPServer{
 		{Name: "server1", URL: "http://server1/mcp"},
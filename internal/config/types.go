@@ -3,9 +3,18 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"maps"
+	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"sync"
+	"time"
+
+	"k8s.io/utils/ptr"
 )
 
 // UpstreamMCPID is used as type for identifying individual upstreams
@@ -57,13 +66,143 @@ func (config *MCPServersConfig) GetServerConfigByName(serverName string) (*MCPSe
 
 // MCPServer represents a server
 type MCPServer struct {
-	Name       string      `json:"name"                 yaml:"name"`
-	URL        string      `json:"url"                  yaml:"url"`
-	Hostname   string      `json:"hostname,omitempty"   yaml:"hostname,omitempty"`
-	ToolPrefix string      `json:"toolPrefix,omitempty" yaml:"toolPrefix,omitempty"`
-	Auth       *AuthConfig `json:"auth,omitempty"       yaml:"auth,omitempty"`
-	Credential string      `json:"credential,omitempty" yaml:"credential,omitempty"`
-	Enabled    bool        `json:"enabled"              yaml:"enabled"`
+	Name          string         `json:"name"                    yaml:"name"`
+	URL           string         `json:"url"                     yaml:"url"`
+	Hostname      string         `json:"hostname,omitempty"      yaml:"hostname,omitempty"`
+	ToolPrefix    string         `json:"toolPrefix,omitempty"    yaml:"toolPrefix,omitempty"`
+	Auth          *AuthConfig    `json:"auth,omitempty"          yaml:"auth,omitempty"`
+	Credential    string         `json:"credential,omitempty"    yaml:"credential,omitempty"`
+	Enabled       bool           `json:"enabled"                 yaml:"enabled"`
+	Categories    []string       `json:"categories,omitempty"    yaml:"categories,omitempty"`
+	ToolOverrides []ToolOverride `json:"toolOverrides,omitempty" yaml:"toolOverrides,omitempty"`
+	Priority      int32          `json:"priority,omitempty"      yaml:"priority,omitempty"`
+	// ToolAliases maps upstream tool names to the name each is served under, in place of the tool name template
+	ToolAliases map[string]string `json:"toolAliases,omitempty" yaml:"toolAliases,omitempty"`
+	// ToolNameTemplate renders the name each tool is served under, such as "{tool}_weather". It may reference
+	// {prefix} for the ToolPrefix and must reference {tool} for the upstream tool name. Empty serves "{prefix}{tool}"
+	ToolNameTemplate string `json:"toolNameTemplate,omitempty" yaml:"toolNameTemplate,omitempty"`
+	// UnavailablePolicy decides what happens to the server's tools while it is unreachable. Empty removes them
+	UnavailablePolicy string `json:"unavailablePolicy,omitempty" yaml:"unavailablePolicy,omitempty"`
+	// HealthPath is an HTTP path on the server polled for liveness between full MCP validations. Empty uses MCP ping
+	HealthPath string `json:"healthPath,omitempty" yaml:"healthPath,omitempty"`
+	// HealthCheckInterval is how often the server is checked, such as "30s". Empty uses the broker's check interval
+	HealthCheckInterval string `json:"healthCheckInterval,omitempty" yaml:"healthCheckInterval,omitempty"`
+	// CallTimeout bounds tool calls and the broker's requests to the server, such as "2m". Empty uses the router's tool call timeout
+	CallTimeout string `json:"callTimeout,omitempty" yaml:"callTimeout,omitempty"`
+	// Draining withholds the server's tools from listing while it stays registered so calls in flight can complete
+	Draining bool `json:"draining,omitempty" yaml:"draining,omitempty"`
+	// TLS configures the connection to a server served over https. Nil uses the system roots
+	TLS *TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// Headers are sent with every request to the server, from the RequestHeaderModifier filters of its HTTPRoute
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// BrokerURL is the URL the broker connects to when the HTTPRoute rewrites requests to the server. URL keeps the
+	// path the route matches, which tool calls routed through the gateway are sent to. Empty connects to URL
+	BrokerURL string `json:"brokerURL,omitempty" yaml:"brokerURL,omitempty"`
+	// Protocol is the HTTP protocol spoken by a server served over http. ProtocolH2C uses HTTP/2 without TLS. Empty uses HTTP/1.1
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	// Generation is the generation of the MCPServerRegistration the entry was last changed by. The broker reports the
+	// generation it has loaded in its status so the controller can tell when a change has been picked up
+	Generation int64 `json:"generation,omitempty" yaml:"generation,omitempty"`
+	// CreationTimestamp is the Unix time in seconds the MCPServerRegistration was created. Between servers of equal
+	// priority the tool of the server created first is registered. Zero leaves tools of equal priority in conflict
+	CreationTimestamp int64 `json:"creationTimestamp,omitempty" yaml:"creationTimestamp,omitempty"`
+	// ForceSync is the force-sync annotation value of the MCPServerRegistration. A change restarts the server's
+	// manager so the broker connects and validates the server again
+	ForceSync string `json:"forceSync,omitempty" yaml:"forceSync,omitempty"`
+}
+
+// TLSConfig configures how the broker verifies and authenticates to an upstream served over https
+type TLSConfig struct {
+	// CACert is a PEM bundle of the certificate authorities trusted to sign the server certificate. Empty uses the system roots
+	CACert string `json:"caCert,omitempty"             yaml:"caCert,omitempty"`
+	// ClientCert and ClientKey are the PEM certificate and key presented to the server for mutual TLS
+	ClientCert         string `json:"clientCert,omitempty"         yaml:"clientCert,omitempty"`
+	ClientKey          string `json:"clientKey,omitempty"          yaml:"clientKey,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+}
+
+// Transport returns an HTTP transport that connects to the upstream with the TLS config
+func (tlsConfig *TLSConfig) Transport() (*http.Transport, error) {
+	clientConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: tlsConfig.InsecureSkipVerify, //nolint:gosec // opted into per server
+	}
+	if tlsConfig.CACert != "" {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(tlsConfig.CACert)) {
+			return nil, fmt.Errorf("no certificates found in CA bundle")
+		}
+		clientConfig.RootCAs = roots
+	}
+	if tlsConfig.ClientCert != "" || tlsConfig.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(tlsConfig.ClientCert), []byte(tlsConfig.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		clientConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = clientConfig
+	return transport, nil
+}
+
+// ProtocolH2C connects to a server with HTTP/2 without TLS, for servers with an h2c or grpc appProtocol
+const ProtocolH2C = "h2c"
+
+// Transport returns the HTTP transport for connecting to the server. It is nil when the default transport is used
+func (mcpServer *MCPServer) Transport() (*http.Transport, error) {
+	if mcpServer.TLS != nil {
+		return mcpServer.TLS.Transport()
+	}
+	if mcpServer.Protocol == ProtocolH2C {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Protocols = &http.Protocols{}
+		transport.Protocols.SetUnencryptedHTTP2(true)
+		return transport, nil
+	}
+	return nil, nil
+}
+
+// UnavailablePolicyKeepTools keeps a server's tools listed while it is unreachable and fails calls to them
+const UnavailablePolicyKeepTools = "KeepTools"
+
+// CheckInterval returns how often the server is checked. 0 means the broker's check interval is used
+func (mcpServer *MCPServer) CheckInterval() (time.Duration, error) {
+	if mcpServer.HealthCheckInterval == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(mcpServer.HealthCheckInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid healthCheckInterval %q: %w", mcpServer.HealthCheckInterval, err)
+	}
+	return interval, nil
+}
+
+// RequestTimeout parses the CallTimeout of the server. Zero means no timeout was set
+func (mcpServer *MCPServer) RequestTimeout() (time.Duration, error) {
+	if mcpServer.CallTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(mcpServer.CallTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid callTimeout %q: %w", mcpServer.CallTimeout, err)
+	}
+	return timeout, nil
+}
+
+// KeepToolsWhenUnavailable checks if the server's tools stay listed while it is unreachable
+func (mcpServer *MCPServer) KeepToolsWhenUnavailable() bool {
+	return mcpServer.UnavailablePolicy == UnavailablePolicyKeepTools
+}
+
+// ToolOverride customises how a single upstream tool is presented to clients
+type ToolOverride struct {
+	// Name is the upstream tool name without any prefix
+	Name               string   `json:"name"                         yaml:"name"`
+	Deprecated         bool     `json:"deprecated,omitempty"         yaml:"deprecated,omitempty"`
+	DeprecationMessage string   `json:"deprecationMessage,omitempty" yaml:"deprecationMessage,omitempty"`
+	Categories         []string `json:"categories,omitempty"         yaml:"categories,omitempty"`
+	Idempotent         *bool    `json:"idempotent,omitempty"         yaml:"idempotent,omitempty"`
 }
 
 // ID returns a unique id for the a registered server
@@ -72,12 +211,124 @@ func (mcpServer *MCPServer) ID() UpstreamMCPID {
 }
 
 // ConfigChanged checks if a server's config has changed in a way that will affect the gateway.
-// This means having a different name, prefix, hostname, or credential variable.
+// This means having a different name, prefix, tool name template, tool aliases, hostname, broker URL, categories, tool overrides, priority, creation timestamp, force sync, unavailable policy,
+// health path, health check interval, call timeout, TLS config, protocol or headers. A changed credential is rotated by the running manager instead, and a draining
+// server keeps its manager so calls in flight complete.
 func (mcpServer *MCPServer) ConfigChanged(existingConfig MCPServer) bool {
 	return existingConfig.Name != mcpServer.Name ||
 		existingConfig.ToolPrefix != mcpServer.ToolPrefix ||
+		existingConfig.ToolNameTemplate != mcpServer.ToolNameTemplate ||
+		!maps.Equal(existingConfig.ToolAliases, mcpServer.ToolAliases) ||
 		existingConfig.Hostname != mcpServer.Hostname ||
-		existingConfig.Credential != mcpServer.Credential
+		existingConfig.BrokerURL != mcpServer.BrokerURL ||
+		existingConfig.Priority != mcpServer.Priority ||
+		existingConfig.CreationTimestamp != mcpServer.CreationTimestamp ||
+		existingConfig.ForceSync != mcpServer.ForceSync ||
+		existingConfig.UnavailablePolicy != mcpServer.UnavailablePolicy ||
+		existingConfig.HealthPath != mcpServer.HealthPath ||
+		existingConfig.HealthCheckInterval != mcpServer.HealthCheckInterval ||
+		existingConfig.CallTimeout != mcpServer.CallTimeout ||
+		!ptr.Equal(existingConfig.TLS, mcpServer.TLS) ||
+		existingConfig.Protocol != mcpServer.Protocol ||
+		!maps.Equal(existingConfig.Headers, mcpServer.Headers) ||
+		!slices.Equal(existingConfig.Categories, mcpServer.Categories) ||
+		!slices.EqualFunc(existingConfig.ToolOverrides, mcpServer.ToolOverrides, func(a, b ToolOverride) bool {
+			return a.Name == b.Name &&
+				a.Deprecated == b.Deprecated &&
+				a.DeprecationMessage == b.DeprecationMessage &&
+				slices.Equal(a.Categories, b.Categories) &&
+				ptr.Equal(a.Idempotent, b.Idempotent)
+		})
+}
+
+const (
+	// ToolNamePrefixPlaceholder is replaced with the tool prefix in a tool name template
+	ToolNamePrefixPlaceholder = "{prefix}"
+	// ToolNameToolPlaceholder is replaced with the upstream tool name in a tool name template
+	ToolNameToolPlaceholder = "{tool}"
+	// ToolNameServerPlaceholder is replaced with the MCPServerRegistration name by the controller before the template is
+	// written to the config
+	ToolNameServerPlaceholder = "{server}"
+)
+
+// ServedToolName returns the name the gateway serves the upstream tool under, its alias if it has one
+func (mcpServer *MCPServer) ServedToolName(tool string) string {
+	if alias, ok := mcpServer.ToolAliases[tool]; ok {
+		return alias
+	}
+	before, after := mcpServer.toolNameAffixes()
+	return before + tool + after
+}
+
+// UpstreamToolName reverses ServedToolName, returning the upstream name of a served tool. The served name is returned
+// unchanged with false when it was not rendered for this server, including the templated name of an aliased tool
+func (mcpServer *MCPServer) UpstreamToolName(served string) (string, bool) {
+	for tool, alias := range mcpServer.ToolAliases {
+		if alias == served {
+			return tool, true
+		}
+	}
+	before, after := mcpServer.toolNameAffixes()
+	tool, ok := strings.CutPrefix(served, before)
+	if !ok {
+		return served, false
+	}
+	tool, ok = strings.CutSuffix(tool, after)
+	if !ok || tool == "" {
+		return served, false
+	}
+	if _, aliased := mcpServer.ToolAliases[tool]; aliased {
+		return served, false
+	}
+	return tool, true
+}
+
+// ServedToolNamePrefix returns the text every tool served from the server starts with
+func (mcpServer *MCPServer) ServedToolNamePrefix() string {
+	before, _ := mcpServer.toolNameAffixes()
+	return before
+}
+
+// toolNameAffixes returns the text the tool name template renders before and after the upstream tool name. A template
+// without {tool} is treated as a prefix
+func (mcpServer *MCPServer) toolNameAffixes() (string, string) {
+	if mcpServer.ToolNameTemplate == "" {
+		return mcpServer.ToolPrefix, ""
+	}
+	template := strings.ReplaceAll(mcpServer.ToolNameTemplate, ToolNamePrefixPlaceholder, mcpServer.ToolPrefix)
+	before, after, _ := strings.Cut(template, ToolNameToolPlaceholder)
+	return before, after
+}
+
+// ToolCategories returns the server categories followed by any categories from the tool override, without duplicates
+func (mcpServer *MCPServer) ToolCategories(toolName string) []string {
+	categories := slices.Clone(mcpServer.Categories)
+	if override, ok := mcpServer.ToolOverride(toolName); ok {
+		for _, category := range override.Categories {
+			if !slices.Contains(categories, category) {
+				categories = append(categories, category)
+			}
+		}
+	}
+	return categories
+}
+
+// ToolOverride returns the override for the named upstream tool if one is configured
+func (mcpServer *MCPServer) ToolOverride(toolName string) (ToolOverride, bool) {
+	for _, override := range mcpServer.ToolOverrides {
+		if override.Name == toolName {
+			return override, true
+		}
+	}
+	return ToolOverride{}, false
+}
+
+// ConnectURL returns the URL the broker connects to the server at
+func (mcpServer *MCPServer) ConnectURL() string {
+	if mcpServer.BrokerURL != "" {
+		return mcpServer.BrokerURL
+	}
+	return mcpServer.URL
 }
 
 // Path returns the path part of the mcp url
@@ -91,8 +342,58 @@ func (mcpServer *MCPServer) Path() (string, error) {
 
 // VirtualServer represents a virtual server configuration
 type VirtualServer struct {
-	Name  string
-	Tools []string
+	Name    string
+	Tools   []string
+	Servers []VirtualServerScope
+}
+
+// VirtualServerScope selects tools of one upstream server, by the server name and the tools it serves without its
+// tool prefix. Every tool of the server is selected when Tools is empty
+type VirtualServerScope struct {
+	Name  string   `json:"name"            yaml:"name"`
+	Tools []string `json:"tools,omitempty" yaml:"tools,omitempty"`
+}
+
+// Allows checks if the virtual server exposes the named tool. Its tools may be patterns, see MatchToolPattern
+func (vs *VirtualServer) Allows(toolName string) bool {
+	return slices.ContainsFunc(vs.Tools, func(pattern string) bool {
+		return MatchToolPattern(pattern, toolName)
+	})
+}
+
+// AllowsServerTool checks if the virtual server exposes a tool of the named server. The tool is named as the server
+// serves it, without the server's tool prefix
+func (vs *VirtualServer) AllowsServerTool(serverName, toolName string) bool {
+	return slices.ContainsFunc(vs.Servers, func(scope VirtualServerScope) bool {
+		if scope.Name != serverName {
+			return false
+		}
+		return len(scope.Tools) == 0 || slices.ContainsFunc(scope.Tools, func(pattern string) bool {
+			return MatchToolPattern(pattern, toolName)
+		})
+	})
+}
+
+// MatchToolPattern checks if a tool name matches a pattern in which * matches any sequence of characters, for example
+// "weather_*". A pattern without * must equal the tool name
+func MatchToolPattern(pattern, toolName string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == toolName
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(toolName, parts[0]) {
+		return false
+	}
+	rest := toolName[len(parts[0]):]
+	// match each literal between wildcards as early as possible to leave the most room for the rest
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return strings.HasSuffix(rest, parts[len(parts)-1])
 }
 
 // Observer provides an interface to implement in order to register as an Observer of config changes
@@ -116,6 +417,7 @@ type AuthConfig struct {
 
 // VirtualServerConfig represents virtual server config
 type VirtualServerConfig struct {
-	Name  string   `json:"name"  yaml:"name"`
-	Tools []string `json:"tools" yaml:"tools"`
+	Name    string               `json:"name"              yaml:"name"`
+	Tools   []string             `json:"tools"             yaml:"tools"`
+	Servers []VirtualServerScope `json:"servers,omitempty" yaml:"servers,omitempty"`
 }
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/broker"
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+)
+
+// DefaultBrokerMetricsInterval is how often broker status is scraped when re-exporting metrics
+const DefaultBrokerMetricsInterval = 30 * time.Second
+
+var (
+	brokerServerReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcp_gateway_broker_server_ready",
+		Help: "Whether an upstream MCP server is ready according to the broker (1 ready, 0 not ready)",
+	}, []string{"namespace", "server"})
+	brokerServerTools = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcp_gateway_broker_server_tools",
+		Help: "Number of tools the broker discovered for an upstream MCP server",
+	}, []string{"namespace", "server"})
+	brokerServers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcp_gateway_broker_servers",
+		Help: "Number of upstream MCP servers known to the broker by health",
+	}, []string{"namespace", "health"})
+	brokerToolConflicts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcp_gateway_broker_tool_conflicts",
+		Help: "Number of tool conflicts reported by the broker",
+	}, []string{"namespace"})
+	brokerScrapeUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcp_gateway_broker_scrape_up",
+		Help: "Whether the last scrape of the broker status succeeded (1 success, 0 failure)",
+	}, []string{"namespace"})
+
+	registerBrokerMetricsOnce sync.Once
+)
+
+func registerBrokerMetrics() {
+	registerBrokerMetricsOnce.Do(func() {
+		metrics.Registry.MustRegister(brokerServerReady, brokerServerTools, brokerServers, brokerToolConflicts, brokerScrapeUp)
+	})
+}
+
+// BrokerStatusFetcher fetches the aggregated server status from the broker in a namespace
+type BrokerStatusFetcher interface {
+	ValidateServers(ctx context.Context, namespace string) (*broker.StatusResponse, error)
+}
+
+// ServerStatusFetcher also fetches the status of a single server from the broker in a namespace, so reconciling one
+// registration doesn't fetch the status of every server
+type ServerStatusFetcher interface {
+	BrokerStatusFetcher
+	ValidateServer(ctx context.Context, namespace, serverID string) (*upstream.ServerValidationStatus, error)
+}
+
+// BrokerMetricsExporter periodically scrapes the broker status for each MCPGatewayExtension
+// namespace and re-exports it on the controller metrics endpoint
+type BrokerMetricsExporter struct {
+	Client   client.Client
+	Fetcher  BrokerStatusFetcher
+	Interval time.Duration
+	Logger   *slog.Logger
+
+	// exported holds the servers exported for each namespace by the previous scrape. it is only used from Start
+	exported map[string]map[string]struct{}
+}
+
+// Start implements manager.Runnable
+func (e *BrokerMetricsExporter) Start(ctx context.Context) error {
+	registerBrokerMetrics()
+	interval := e.Interval
+	if interval <= 0 {
+		interval = DefaultBrokerMetricsInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e.scrape(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. every replica exports metrics
+func (e *BrokerMetricsExporter) NeedLeaderElection() bool {
+	return false
+}
+
+func (e *BrokerMetricsExporter) scrape(ctx context.Context) {
+	extList := &mcpv1alpha1.MCPGatewayExtensionList{}
+	if err := e.Client.List(ctx, extList); err != nil {
+		e.Logger.Error("broker metrics: failed to list mcpgatewayextensions", "error", err)
+		return
+	}
+	namespaces := map[string]struct{}{}
+	for _, ext := range extList.Items {
+		if !ext.DeletionTimestamp.IsZero() {
+			continue
+		}
+		namespaces[ext.Namespace] = struct{}{}
+	}
+
+	// series are replaced in place rather than reset before the scrape, so a collection between the two never sees
+	// them missing. a namespace whose broker can't be reached keeps its last known series with scrape_up at 0
+	exported := make(map[string]map[string]struct{}, len(namespaces))
+	for namespace := range namespaces {
+		status, err := e.Fetcher.ValidateServers(ctx, namespace)
+		if err != nil {
+			e.Logger.Debug("broker metrics: failed to fetch broker status", "namespace", namespace, "error", err)
+			brokerScrapeUp.WithLabelValues(namespace).Set(0)
+			exported[namespace] = e.exported[namespace]
+			continue
+		}
+		brokerScrapeUp.WithLabelValues(namespace).Set(1)
+		servers := recordBrokerStatus(namespace, status)
+		for server := range e.exported[namespace] {
+			if _, ok := servers[server]; !ok {
+				brokerServerReady.DeleteLabelValues(namespace, server)
+				brokerServerTools.DeleteLabelValues(namespace, server)
+			}
+		}
+		exported[namespace] = servers
+	}
+
+	// stop exporting namespaces that no longer have an extension
+	for namespace := range e.exported {
+		if _, ok := namespaces[namespace]; ok {
+			continue
+		}
+		labels := prometheus.Labels{"namespace": namespace}
+		brokerServerReady.DeletePartialMatch(labels)
+		brokerServerTools.DeletePartialMatch(labels)
+		brokerServers.DeletePartialMatch(labels)
+		brokerToolConflicts.DeletePartialMatch(labels)
+		brokerScrapeUp.DeletePartialMatch(labels)
+	}
+	e.exported = exported
+}
+
+// recordBrokerStatus sets the series for the broker status in a namespace and returns the servers it exported
+func recordBrokerStatus(namespace string, status *broker.StatusResponse) map[string]struct{} {
+	servers := make(map[string]struct{}, len(status.Servers))
+	for _, server := range status.Servers {
+		servers[server.Name] = struct{}{}
+		ready := 0.0
+		if server.Ready {
+			ready = 1
+		}
+		brokerServerReady.WithLabelValues(namespace, server.Name).Set(ready)
+		brokerServerTools.WithLabelValues(namespace, server.Name).Set(float64(server.TotalTools))
+	}
+	brokerServers.WithLabelValues(namespace, "healthy").Set(float64(status.HealthyServers))
+	brokerServers.WithLabelValues(namespace, "unhealthy").Set(float64(status.UnHealthyServers))
+	brokerToolConflicts.WithLabelValues(namespace).Set(float64(status.ToolConflicts))
+	return servers
+}
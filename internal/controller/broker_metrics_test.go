@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/broker"
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+)
+
+// fakeBrokerFetcher serves status from a test http server rather than discovering broker endpoints
+type fakeBrokerFetcher struct {
+	validator *ServerValidator
+	url       string
+}
+
+func (f *fakeBrokerFetcher) ValidateServers(ctx context.Context, _ string) (*broker.StatusResponse, error) {
+	return f.validator.statusFromEndpoints(ctx, []string{f.url})
+}
+
+// ValidateServer picks the server out of the status of every server, which is all the test brokers serve
+func (f *fakeBrokerFetcher) ValidateServer(ctx context.Context, namespace, serverID string) (*upstream.ServerValidationStatus, error) {
+	status, err := f.ValidateServers(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return findServerStatus(status, serverID)
+}
+
+// findServerStatus returns the status of the server with the id as the broker /status?id= endpoint does
+func findServerStatus(status *broker.StatusResponse, serverID string) (*upstream.ServerValidationStatus, error) {
+	for _, server := range status.Servers {
+		if server.ID == serverID {
+			return &server, nil
+		}
+	}
+	return nil, errServerStatusNotFound
+}
+
+func TestBrokerMetricsExporter_Scrape(t *testing.T) {
+	servers := []upstream.ServerValidationStatus{
+		{Name: "team-a/weather", Ready: true, TotalTools: 3},
+		{Name: "team-b/time", Ready: false, TotalTools: 0},
+	}
+	fakeBroker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(broker.StatusResponse{
+			Servers:          servers,
+			TotalServers:     2,
+			HealthyServers:   1,
+			UnHealthyServers: 1,
+			ToolConflicts:    2,
+			Timestamp:        time.Now(),
+		})
+	}))
+	defer fakeBroker.Close()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	ext := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: "ext", Namespace: "mcp-system"},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ext).Build()
+
+	exporter := &BrokerMetricsExporter{
+		Client: k8sClient,
+		Fetcher: &fakeBrokerFetcher{
+			validator: &ServerValidator{httpClient: fakeBroker.Client()},
+			url:       fakeBroker.URL,
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	registerBrokerMetrics()
+	exporter.scrape(context.Background())
+
+	require.Equal(t, 1.0, testutil.ToFloat64(brokerScrapeUp.WithLabelValues("mcp-system")))
+	require.Equal(t, 1.0, testutil.ToFloat64(brokerServerReady.WithLabelValues("mcp-system", "team-a/weather")))
+	require.Equal(t, 0.0, testutil.ToFloat64(brokerServerReady.WithLabelValues("mcp-system", "team-b/time")))
+	require.Equal(t, 3.0, testutil.ToFloat64(brokerServerTools.WithLabelValues("mcp-system", "team-a/weather")))
+	require.Equal(t, 1.0, testutil.ToFloat64(brokerServers.WithLabelValues("mcp-system", "healthy")))
+	require.Equal(t, 1.0, testutil.ToFloat64(brokerServers.WithLabelValues("mcp-system", "unhealthy")))
+	require.Equal(t, 2.0, testutil.ToFloat64(brokerToolConflicts.WithLabelValues("mcp-system")))
+
+	// a server the broker no longer reports stops being exported
+	servers = servers[:1]
+	exporter.scrape(context.Background())
+	require.Equal(t, 1, testutil.CollectAndCount(brokerServerReady))
+	require.Equal(t, 1, testutil.CollectAndCount(brokerServerTools))
+
+	// broker unreachable keeps the last known server series and reports scrape failure
+	fakeBroker.Close()
+	exporter.scrape(context.Background())
+	require.Equal(t, 0.0, testutil.ToFloat64(brokerScrapeUp.WithLabelValues("mcp-system")))
+	require.Equal(t, 1.0, testutil.ToFloat64(brokerServerReady.WithLabelValues("mcp-system", "team-a/weather")))
+
+	// the series of a namespace without an extension are removed
+	require.NoError(t, k8sClient.Delete(context.Background(), ext))
+	exporter.scrape(context.Background())
+	require.Equal(t, 0, testutil.CollectAndCount(brokerServerReady))
+	require.Equal(t, 0, testutil.CollectAndCount(brokerScrapeUp))
+}
@@ -5,7 +5,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"maps"
 	"net"
+	"slices"
 	"strings"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
@@ -14,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/ptr"
@@ -30,10 +33,18 @@ const (
 	// DefaultBrokerRouterImage is the default image for the broker-router deployment
 	DefaultBrokerRouterImage = "ghcr.io/kuadrant/mcp-gateway:latest"
 
-	// broker-router ports
-	brokerHTTPPort   = 8080
-	brokerGRPCPort   = 50051
-	brokerConfigPort = 8181
+	// broker-router ports. The public port is set by the extension's brokerPort
+	brokerGRPCPort    = 50051
+	brokerConfigPort  = 8181
+	brokerMetricsPort = 8082
+
+	// brokerHTTPSPortName is the name of the broker's public port when it terminates TLS
+	brokerHTTPSPortName = "https"
+	// brokerTLSMountPath is where the broker TLS secret is mounted in the broker-router pod
+	brokerTLSMountPath = "/tls"
+
+	// brokerRouterUID is the non-root user and group the broker-router container runs as by default
+	brokerRouterUID = 65532
 )
 
 // flags that can be changed directly on the deployment without triggering an update
@@ -54,14 +65,47 @@ func brokerRouterLabels() map[string]string {
 func (r *MCPGatewayExtensionReconciler) buildBrokerRouterDeployment(mcpExt *mcpv1alpha1.MCPGatewayExtension, publicHost, internalHost string) *appsv1.Deployment {
 	labels := brokerRouterLabels()
 	replicas := int32(1)
+	if mcpExt.Spec.Replicas != nil {
+		replicas = *mcpExt.Spec.Replicas
+	}
 
-	command := []string{"./mcp_gateway", fmt.Sprintf("--mcp-broker-public-address=0.0.0.0:%d", brokerHTTPPort),
+	command := []string{"./mcp_gateway", fmt.Sprintf("--mcp-broker-public-address=0.0.0.0:%d", mcpExt.GetBrokerPort()),
 		"--mcp-gateway-private-host=" + internalHost,
 		"--mcp-gateway-config=/config/config.yaml"}
 	// only override the binary's default (60s) when explicitly set in spec
 	if mcpExt.Spec.BackendPingIntervalSeconds != nil {
 		command = append(command, fmt.Sprintf("--mcp-check-interval=%d", *mcpExt.Spec.BackendPingIntervalSeconds))
 	}
+	if mcpExt.Spec.ToolCallConcurrencyPerServer != nil {
+		command = append(command, fmt.Sprintf("--tool-call-concurrency=%d", *mcpExt.Spec.ToolCallConcurrencyPerServer))
+	}
+	if mcpExt.Spec.ToolCallRetries != nil {
+		command = append(command, fmt.Sprintf("--tool-call-retries=%d", *mcpExt.Spec.ToolCallRetries))
+	}
+	if mcpExt.Spec.Instructions != "" {
+		command = append(command, "--instructions="+mcpExt.Spec.Instructions)
+	}
+	if mcpExt.Spec.MaxTotalTools != nil {
+		command = append(command, fmt.Sprintf("--max-total-tools=%d", *mcpExt.Spec.MaxTotalTools))
+	}
+	// the router accepts messages from envoy as large as envoy accepts from the router
+	if grpcConfig := mcpExt.Spec.ExtProcGRPC; grpcConfig != nil && grpcConfig.MaxMessageSize != nil {
+		command = append(command, fmt.Sprintf("--grpc-max-message-size=%d", *grpcConfig.MaxMessageSize))
+	}
+	if mcpExt.Spec.BrokerTLS != nil {
+		command = append(command, "--tls-cert-file="+brokerTLSMountPath+"/"+corev1.TLSCertKey,
+			"--tls-key-file="+brokerTLSMountPath+"/"+corev1.TLSPrivateKeyKey)
+		if mcpExt.Spec.BrokerTLS.MinVersion != "" {
+			command = append(command, "--tls-min-version="+mcpExt.Spec.BrokerTLS.MinVersion)
+		}
+		if len(mcpExt.Spec.BrokerTLS.CipherSuites) > 0 {
+			command = append(command, "--tls-cipher-suites="+strings.Join(mcpExt.Spec.BrokerTLS.CipherSuites, ","))
+		}
+	}
+	if mcpExt.Spec.SessionStore != nil {
+		// expanded by kubernetes from the env var so the connection string is not stored in the deployment
+		command = append(command, "--cache-connection-string=$("+sessionStoreEnvVar+")")
+	}
 	command = append(command, "--mcp-gateway-public-host="+publicHost)
 	command = append(command, "--mcp-router-key="+routerKey(mcpExt))
 
@@ -79,6 +123,65 @@ func (r *MCPGatewayExtensionReconciler) buildBrokerRouterDeployment(mcpExt *mcpv
 			},
 		})
 	}
+	if mcpExt.Spec.SessionStore != nil {
+		envVars = append(envVars, corev1.EnvVar{
+			Name: sessionStoreEnvVar,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: mcpExt.Spec.SessionStore.SecretName,
+					},
+					Key: sessionStoreConnectionKey,
+				},
+			},
+		})
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "config-volume",
+			MountPath: "/config",
+			ReadOnly:  true,
+		},
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: "config-volume",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  "mcp-gateway-config",
+					DefaultMode: ptr.To(int32(420)), // 0644 octal
+				},
+			},
+		},
+	}
+	if mcpExt.Spec.BrokerTLS != nil {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "tls-volume",
+			MountPath: brokerTLSMountPath,
+			ReadOnly:  true,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: "tls-volume",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  mcpExt.Spec.BrokerTLS.SecretName,
+					DefaultMode: ptr.To(int32(256)), // 0400 octal
+				},
+			},
+		})
+	}
+
+	podTemplate := ptr.Deref(mcpExt.Spec.PodTemplate, mcpv1alpha1.BrokerPodTemplate{})
+	imagePullPolicy := corev1.PullIfNotPresent
+	if podTemplate.ImagePullPolicy != "" {
+		imagePullPolicy = podTemplate.ImagePullPolicy
+	}
+	var resources corev1.ResourceRequirements
+	if podTemplate.Resources != nil {
+		resources = *podTemplate.Resources.DeepCopy()
+	}
+	securityContext, podSecurityContext := brokerRouterSecurityContext(podTemplate.SecurityContext)
 
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -88,6 +191,7 @@ func (r *MCPGatewayExtensionReconciler) buildBrokerRouterDeployment(mcpExt *mcpv
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
+			Strategy: brokerDeploymentStrategy(mcpExt, nil),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
@@ -98,17 +202,21 @@ func (r *MCPGatewayExtensionReconciler) buildBrokerRouterDeployment(mcpExt *mcpv
 				Spec: corev1.PodSpec{
 					ServiceAccountName:           brokerRouterName,
 					AutomountServiceAccountToken: ptr.To(false),
+					NodeSelector:                 maps.Clone(podTemplate.NodeSelector),
+					SecurityContext:              podSecurityContext,
 					Containers: []corev1.Container{
 						{
 							Name:            brokerRouterName,
 							Image:           r.BrokerRouterImage,
-							ImagePullPolicy: corev1.PullIfNotPresent,
+							ImagePullPolicy: imagePullPolicy,
 							Command:         command,
 							Env:             envVars,
+							Resources:       resources,
+							SecurityContext: securityContext,
 							Ports: []corev1.ContainerPort{
 								{
-									Name:          "http",
-									ContainerPort: brokerHTTPPort,
+									Name:          brokerHTTPPortName(mcpExt),
+									ContainerPort: mcpExt.GetBrokerPort(),
 									Protocol:      corev1.ProtocolTCP,
 								},
 								{
@@ -121,33 +229,77 @@ func (r *MCPGatewayExtensionReconciler) buildBrokerRouterDeployment(mcpExt *mcpv
 									ContainerPort: brokerConfigPort,
 									Protocol:      corev1.ProtocolTCP,
 								},
-							},
-							VolumeMounts: []corev1.VolumeMount{
 								{
-									Name:      "config-volume",
-									MountPath: "/config",
-									ReadOnly:  true,
-								},
-							},
-						},
-					},
-					Volumes: []corev1.Volume{
-						{
-							Name: "config-volume",
-							VolumeSource: corev1.VolumeSource{
-								Secret: &corev1.SecretVolumeSource{
-									SecretName:  "mcp-gateway-config",
-									DefaultMode: ptr.To(int32(420)), // 0644 octal
+									Name:          "metrics",
+									ContainerPort: brokerMetricsPort,
+									Protocol:      corev1.ProtocolTCP,
 								},
 							},
+							VolumeMounts: volumeMounts,
 						},
 					},
+					Volumes: volumes,
 				},
 			},
 		},
 	}
 }
 
+// brokerRouterSecurityContext returns the container and pod security contexts of the broker-router. Without an
+// override the container runs as a non-root user with no privileges so the pod meets the restricted Pod Security
+// Standard, and the pod fsGroup lets that user read the mounted secrets
+func brokerRouterSecurityContext(override *corev1.SecurityContext) (*corev1.SecurityContext, *corev1.PodSecurityContext) {
+	if override != nil {
+		return override.DeepCopy(), nil
+	}
+	securityContext := &corev1.SecurityContext{
+		RunAsNonRoot:             ptr.To(true),
+		RunAsUser:                ptr.To(int64(brokerRouterUID)),
+		RunAsGroup:               ptr.To(int64(brokerRouterUID)),
+		AllowPrivilegeEscalation: ptr.To(false),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	return securityContext, &corev1.PodSecurityContext{FSGroup: ptr.To(int64(brokerRouterUID))}
+}
+
+// brokerDeploymentStrategy returns the rollout strategy for the broker-router deployment.
+// when not set in spec, RollingUpdate is only used if sessions are held in a shared store
+// so that two broker pods do not split in-memory sessions during a rollout
+func brokerDeploymentStrategy(mcpExt *mcpv1alpha1.MCPGatewayExtension, existing *appsv1.Deployment) appsv1.DeploymentStrategy {
+	strategyType := appsv1.RecreateDeploymentStrategyType
+	switch mcpExt.Spec.DeploymentStrategy {
+	case mcpv1alpha1.DeploymentStrategyRollingUpdate:
+		strategyType = appsv1.RollingUpdateDeploymentStrategyType
+	case mcpv1alpha1.DeploymentStrategyRecreate:
+	default:
+		if mcpExt.Spec.SessionStore != nil || (existing != nil && cacheConfigured(existing)) {
+			strategyType = appsv1.RollingUpdateDeploymentStrategyType
+		}
+	}
+	return appsv1.DeploymentStrategy{Type: strategyType}
+}
+
+// cacheConfigured checks if the broker-router deployment has a session cache configured
+func cacheConfigured(deployment *appsv1.Deployment) bool {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name != brokerRouterName {
+			continue
+		}
+		for _, arg := range container.Command {
+			if strings.HasPrefix(arg, "--cache-connection-string=") && arg != "--cache-connection-string=" {
+				return true
+			}
+		}
+		for _, env := range container.Env {
+			if env.Name == "CACHE_CONNECTION_STRING" && (env.Value != "" || env.ValueFrom != nil) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (r *MCPGatewayExtensionReconciler) buildBrokerRouterServiceAccount(mcpExt *mcpv1alpha1.MCPGatewayExtension) *corev1.ServiceAccount {
 	labels := brokerRouterLabels()
 	automount := false
@@ -177,9 +329,9 @@ func (r *MCPGatewayExtensionReconciler) buildBrokerRouterService(mcpExt *mcpv1al
 			},
 			Ports: []corev1.ServicePort{
 				{
-					Name:       "http",
-					Port:       brokerHTTPPort,
-					TargetPort: intstr.FromInt(brokerHTTPPort),
+					Name:       brokerHTTPPortName(mcpExt),
+					Port:       mcpExt.GetBrokerPort(),
+					TargetPort: intstr.FromInt32(mcpExt.GetBrokerPort()),
 					Protocol:   corev1.ProtocolTCP,
 				},
 				{
@@ -193,6 +345,15 @@ func (r *MCPGatewayExtensionReconciler) buildBrokerRouterService(mcpExt *mcpv1al
 	}
 }
 
+// brokerHTTPPortName names the broker's public port after the protocol it serves so the controller and mesh know
+// whether to speak TLS to it
+func brokerHTTPPortName(mcpExt *mcpv1alpha1.MCPGatewayExtension) string {
+	if mcpExt.Spec.BrokerTLS != nil {
+		return brokerHTTPSPortName
+	}
+	return "http"
+}
+
 // routerKey generates a deterministic key for hair-pinning requests based on the extension's UID
 func routerKey(mcpExt *mcpv1alpha1.MCPGatewayExtension) string {
 	hash := sha256.Sum256([]byte(mcpExt.UID))
@@ -209,17 +370,16 @@ func stripPort(host string) string {
 }
 
 // derivePublicHost determines the public host for the MCP Gateway.
-// priority: annotation override > listener hostname.
+// priority: spec.publicHost > listener hostname.
 // For wildcard hostnames (*.example.com), we use mcp.example.com as the default subdomain.
 // Any port suffix is stripped since HTTPRoute hostnames don't allow ports.
-func derivePublicHost(listenerConfig *mcpv1alpha1.ListenerConfig, annotationOverride string) (string, error) {
+func derivePublicHost(listenerConfig *mcpv1alpha1.ListenerConfig, publicHostOverride string) (string, error) {
 	var hostname string
-	// annotation takes precedence for backwards compatibility
-	if annotationOverride != "" {
-		if strings.Contains(annotationOverride, "://") {
-			return "", fmt.Errorf("invalid public host %q: must be a hostname, not a URL", annotationOverride)
+	if publicHostOverride != "" {
+		if strings.Contains(publicHostOverride, "://") {
+			return "", fmt.Errorf("invalid public host %q: must be a hostname, not a URL", publicHostOverride)
 		}
-		hostname = stripPort(annotationOverride)
+		hostname = stripPort(publicHostOverride)
 	} else if listenerConfig != nil && listenerConfig.Hostname != "" {
 		hostname = listenerConfig.Hostname
 		// handle wildcard hostnames: *.example.com -> mcp.example.com
@@ -236,7 +396,9 @@ func derivePublicHost(listenerConfig *mcpv1alpha1.ListenerConfig, annotationOver
 	return hostname, nil
 }
 
-func (r *MCPGatewayExtensionReconciler) reconcileBrokerRouter(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension, listenerConfig *mcpv1alpha1.ListenerConfig) (bool, error) {
+func (r *MCPGatewayExtensionReconciler) reconcileBrokerRouter(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension, listenerConfig *mcpv1alpha1.ListenerConfig) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "MCPGatewayExtension.reconcileBrokerRouter", "MCPGatewayExtension", client.ObjectKeyFromObject(mcpExt))
+	defer func() { endSpan(span, err) }()
 	// derive values from listener config before building resources
 	publicHost, err := derivePublicHost(listenerConfig, mcpExt.Spec.PublicHost)
 	if err != nil {
@@ -254,7 +416,7 @@ func (r *MCPGatewayExtensionReconciler) reconcileBrokerRouter(ctx context.Contex
 	if err := r.Get(ctx, client.ObjectKeyFromObject(serviceAccount), existingServiceAccount); err != nil {
 		if apierrors.IsNotFound(err) {
 			r.log.Info("creating broker-router service account", "namespace", mcpExt.Namespace)
-			if err := r.Create(ctx, serviceAccount); err != nil {
+			if err := r.Create(ctx, serviceAccount); client.IgnoreAlreadyExists(err) != nil {
 				return false, fmt.Errorf("failed to create service account: %w", err)
 			}
 		} else {
@@ -278,18 +440,24 @@ func (r *MCPGatewayExtensionReconciler) reconcileBrokerRouter(ctx context.Contex
 	if err := r.Get(ctx, client.ObjectKeyFromObject(deployment), existingDeployment); err != nil {
 		if apierrors.IsNotFound(err) {
 			r.log.Info("creating broker-router deployment", "namespace", mcpExt.Namespace)
-			if err := r.Create(ctx, deployment); err != nil {
+			// a previous leader may have created it before this replica's cache caught up
+			if err := r.Create(ctx, deployment); client.IgnoreAlreadyExists(err) != nil {
 				return false, fmt.Errorf("failed to create deployment: %w", err)
 			}
 			return false, nil // deployment just created, not ready yet
 		}
 		return false, fmt.Errorf("failed to get deployment: %w", err)
 	}
+	deployment.Spec.Strategy = brokerDeploymentStrategy(mcpExt, existingDeployment)
 
 	if needsUpdate, reason := deploymentNeedsUpdate(deployment, existingDeployment); needsUpdate {
 		r.log.Info("updating broker-router deployment", "namespace", mcpExt.Namespace, "reason", reason)
 		existingDeployment.Spec.Template.Spec.Containers = deployment.Spec.Template.Spec.Containers
 		existingDeployment.Spec.Template.Spec.Volumes = deployment.Spec.Template.Spec.Volumes
+		existingDeployment.Spec.Template.Spec.NodeSelector = deployment.Spec.Template.Spec.NodeSelector
+		existingDeployment.Spec.Template.Spec.SecurityContext = deployment.Spec.Template.Spec.SecurityContext
+		existingDeployment.Spec.Strategy = deployment.Spec.Strategy
+		existingDeployment.Spec.Replicas = deployment.Spec.Replicas
 		if err := r.Update(ctx, existingDeployment); err != nil {
 			return false, fmt.Errorf("failed to update deployment: %w", err)
 		}
@@ -306,7 +474,7 @@ func (r *MCPGatewayExtensionReconciler) reconcileBrokerRouter(ctx context.Contex
 	if err := r.Get(ctx, client.ObjectKeyFromObject(service), existingService); err != nil {
 		if apierrors.IsNotFound(err) {
 			r.log.Info("creating broker-router service", "namespace", mcpExt.Namespace)
-			if err := r.Create(ctx, service); err != nil {
+			if err := r.Create(ctx, service); client.IgnoreAlreadyExists(err) != nil {
 				return false, fmt.Errorf("failed to create service: %w", err)
 			}
 		} else {
@@ -332,7 +500,7 @@ func (r *MCPGatewayExtensionReconciler) reconcileBrokerRouter(ctx context.Contex
 		if err := r.Get(ctx, client.ObjectKeyFromObject(httpRoute), existingHTTPRoute); err != nil {
 			if apierrors.IsNotFound(err) {
 				r.log.Info("creating gateway httproute", "namespace", mcpExt.Namespace)
-				if err := r.Create(ctx, httpRoute); err != nil {
+				if err := r.Create(ctx, httpRoute); client.IgnoreAlreadyExists(err) != nil {
 					return false, fmt.Errorf("failed to create httproute: %w", err)
 				}
 			} else {
@@ -356,6 +524,39 @@ func (r *MCPGatewayExtensionReconciler) reconcileBrokerRouter(ctx context.Contex
 	return deploymentReady, nil
 }
 
+// imagePullFailureReasons are the container waiting reasons that mean the image cannot be pulled
+var imagePullFailureReasons = []string{"ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull"}
+
+// brokerRouterImagePullFailure returns a message naming the image if a broker-router pod is failing to pull its image.
+// Pods are read directly from the API server so the controller does not cache every pod in the cluster
+func (r *MCPGatewayExtensionReconciler) brokerRouterImagePullFailure(ctx context.Context, namespace string) (string, error) {
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: brokerRouterName, Namespace: namespace}, deployment); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	if deployment.Spec.Selector == nil {
+		return "", nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.DirectAPIReader.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+		return "", fmt.Errorf("failed to list broker-router pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			waiting := status.State.Waiting
+			if waiting == nil || !slices.Contains(imagePullFailureReasons, waiting.Reason) {
+				continue
+			}
+			message := fmt.Sprintf("broker-router image %q cannot be pulled: %s", status.Image, waiting.Reason)
+			if waiting.Message != "" {
+				message += ": " + waiting.Message
+			}
+			return message, nil
+		}
+	}
+	return "", nil
+}
+
 // serviceNeedsUpdate checks if the service needs to be updated
 // returns (needsUpdate, reason) where reason describes what changed
 func serviceNeedsUpdate(desired, existing *corev1.Service) (bool, string) {
@@ -387,6 +588,15 @@ func deploymentNeedsUpdate(desired, existing *appsv1.Deployment) (bool, string)
 	desiredContainer := desired.Spec.Template.Spec.Containers[0]
 	existingContainer := existing.Spec.Template.Spec.Containers[0]
 
+	// only the type is compared as the api server defaults the rolling update parameters
+	if desired.Spec.Strategy.Type != existing.Spec.Strategy.Type {
+		return true, fmt.Sprintf("strategy changed: %q -> %q", existing.Spec.Strategy.Type, desired.Spec.Strategy.Type)
+	}
+
+	if desiredReplicas, existingReplicas := ptr.Deref(desired.Spec.Replicas, 1), ptr.Deref(existing.Spec.Replicas, 1); desiredReplicas != existingReplicas {
+		return true, fmt.Sprintf("replicas changed: %d -> %d", existingReplicas, desiredReplicas)
+	}
+
 	if desiredContainer.Image != existingContainer.Image {
 		return true, fmt.Sprintf("image changed: %q -> %q", existingContainer.Image, desiredContainer.Image)
 	}
@@ -408,6 +618,26 @@ func deploymentNeedsUpdate(desired, existing *appsv1.Deployment) (bool, string)
 	if !equality.Semantic.DeepEqual(desiredContainer.Env, existingContainer.Env) {
 		return true, fmt.Sprintf("env changed: %+v -> %+v", existingContainer.Env, desiredContainer.Env)
 	}
+	if desiredContainer.ImagePullPolicy != existingContainer.ImagePullPolicy {
+		return true, fmt.Sprintf("imagePullPolicy changed: %q -> %q", existingContainer.ImagePullPolicy, desiredContainer.ImagePullPolicy)
+	}
+	if !equality.Semantic.DeepEqual(desiredContainer.Resources, existingContainer.Resources) {
+		return true, fmt.Sprintf("resources changed: %+v -> %+v", existingContainer.Resources, desiredContainer.Resources)
+	}
+	// the api server stores an unset security context as empty
+	desiredSecurityContext := ptr.Deref(desiredContainer.SecurityContext, corev1.SecurityContext{})
+	existingSecurityContext := ptr.Deref(existingContainer.SecurityContext, corev1.SecurityContext{})
+	if !equality.Semantic.DeepEqual(desiredSecurityContext, existingSecurityContext) {
+		return true, fmt.Sprintf("securityContext changed: %+v -> %+v", existingSecurityContext, desiredSecurityContext)
+	}
+	desiredPodSecurityContext := ptr.Deref(desired.Spec.Template.Spec.SecurityContext, corev1.PodSecurityContext{})
+	existingPodSecurityContext := ptr.Deref(existing.Spec.Template.Spec.SecurityContext, corev1.PodSecurityContext{})
+	if !equality.Semantic.DeepEqual(desiredPodSecurityContext, existingPodSecurityContext) {
+		return true, fmt.Sprintf("pod securityContext changed: %+v -> %+v", existingPodSecurityContext, desiredPodSecurityContext)
+	}
+	if !equality.Semantic.DeepEqual(desired.Spec.Template.Spec.NodeSelector, existing.Spec.Template.Spec.NodeSelector) {
+		return true, fmt.Sprintf("nodeSelector changed: %v -> %v", existing.Spec.Template.Spec.NodeSelector, desired.Spec.Template.Spec.NodeSelector)
+	}
 	return false, ""
 }
 
@@ -432,7 +662,7 @@ func (r *MCPGatewayExtensionReconciler) buildGatewayHTTPRoute(mcpExt *mcpv1alpha
 	labels := brokerRouterLabels()
 	pathType := gatewayv1.PathMatchPathPrefix
 	pathValue := "/mcp"
-	port := gatewayv1.PortNumber(brokerHTTPPort)
+	port := gatewayv1.PortNumber(mcpExt.GetBrokerPort())
 	gatewayNamespace := gatewayv1.Namespace(mcpExt.Spec.TargetRef.Namespace)
 	sectionName := gatewayv1.SectionName(mcpExt.Spec.TargetRef.SectionName)
 
@@ -0,0 +1,257 @@
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/broker"
+)
+
+const (
+	// brokerCAConfigMapName is the ConfigMap holding the CA that verifies the broker certificate
+	brokerCAConfigMapName = "mcp-gateway-broker-ca"
+	// brokerCAKey is the ConfigMap entry holding the CA, as expected by BackendTLSPolicy
+	brokerCAKey = "ca.crt"
+	// brokerTLSPolicyName is the BackendTLSPolicy telling the gateway to connect to the broker over TLS
+	brokerTLSPolicyName = "mcp-gateway-broker-tls"
+)
+
+// ManagedLabelSelector selects the resources the controller creates, such as the broker CA ConfigMap
+func ManagedLabelSelector() labels.Selector {
+	return labels.SelectorFromSet(labels.Set{labelManagedBy: labelManagedByValue})
+}
+
+// brokerServiceHost returns the hostname the broker certificate must name. The gateway and the controller both
+// verify the broker by its service name because pod ips change
+func brokerServiceHost(namespace string) string {
+	return fmt.Sprintf("%s.%s.svc", brokerRouterName, namespace)
+}
+
+// validateBrokerTLSSecret checks the secret has the certificate and private key entries and that the certificate
+// names the broker service
+func validateBrokerTLSSecret(secret *corev1.Secret, secretName string) *validationError {
+	cert, valErr := parseTLSSecretCertificate(secret, secretName)
+	if valErr != nil {
+		return valErr
+	}
+	host := brokerServiceHost(secret.Namespace)
+	if err := cert.VerifyHostname(host); err != nil {
+		return newValidationError(mcpv1alpha1.ConditionReasonSecretInvalid,
+			fmt.Sprintf("certificate in secret %s must name the broker service %s", secretName, host))
+	}
+	return nil
+}
+
+// parseTLSSecretCertificate checks the secret has the certificate and private key entries and returns the parsed
+// certificate
+func parseTLSSecretCertificate(secret *corev1.Secret, secretName string) (*x509.Certificate, *validationError) {
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return nil, newValidationError(mcpv1alpha1.ConditionReasonSecretInvalid,
+				fmt.Sprintf("secret %s is missing required data entry %q", secretName, key))
+		}
+	}
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return nil, newValidationError(mcpv1alpha1.ConditionReasonSecretInvalid,
+			fmt.Sprintf("secret %s entry %q is not a PEM certificate", secretName, corev1.TLSCertKey))
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, newValidationError(mcpv1alpha1.ConditionReasonSecretInvalid,
+			fmt.Sprintf("secret %s entry %q is not a valid certificate: %s", secretName, corev1.TLSCertKey, err))
+	}
+	return cert, nil
+}
+
+// brokerCA returns the CA that verifies the broker certificate, the secret's ca.crt when set, otherwise the
+// certificate itself for a self-signed certificate
+func brokerCA(secret *corev1.Secret) []byte {
+	if ca := secret.Data[brokerCAKey]; len(ca) > 0 {
+		return ca
+	}
+	return secret.Data[corev1.TLSCertKey]
+}
+
+// reconcileBrokerTLS validates the broker TLS settings and that the certificate secret exists so the broker pod does
+// not fail to start. The broker CA is published in a ConfigMap, and a BackendTLSPolicy makes the gateway verify the
+// broker against it. Both are removed when TLS is not configured
+func (r *MCPGatewayExtensionReconciler) reconcileBrokerTLS(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) error {
+	brokerTLS := mcpExt.Spec.BrokerTLS
+	if brokerTLS == nil {
+		return r.deleteBrokerTLSResources(ctx, mcpExt)
+	}
+
+	minVersion := brokerTLS.MinVersion
+	if minVersion == "" {
+		minVersion = "1.2"
+	}
+	if _, err := broker.NewTLSConfig(minVersion, brokerTLS.CipherSuites); err != nil {
+		return newValidationError(mcpv1alpha1.ConditionReasonInvalid, fmt.Sprintf("invalid brokerTLS: %s", err))
+	}
+
+	secret := &corev1.Secret{}
+	// use direct reader to avoid cache and informer setup for secrets
+	if err := r.DirectAPIReader.Get(ctx, client.ObjectKey{Name: brokerTLS.SecretName, Namespace: mcpExt.Namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return newValidationError(mcpv1alpha1.ConditionReasonSecretNotFound,
+				fmt.Sprintf("secret %s not found in namespace %s", brokerTLS.SecretName, mcpExt.Namespace))
+		}
+		return fmt.Errorf("failed to get broker tls secret: %w", err)
+	}
+
+	if valErr := validateBrokerTLSSecret(secret, brokerTLS.SecretName); valErr != nil {
+		return valErr
+	}
+
+	if err := r.reconcileBrokerCAConfigMap(ctx, mcpExt, brokerCA(secret)); err != nil {
+		return err
+	}
+	return r.reconcileBrokerTLSPolicy(ctx, mcpExt)
+}
+
+// reconcileBrokerCAConfigMap creates or updates the ConfigMap publishing the broker CA
+func (r *MCPGatewayExtensionReconciler) reconcileBrokerCAConfigMap(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension, ca []byte) error {
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      brokerCAConfigMapName,
+			Namespace: mcpExt.Namespace,
+			Labels:    brokerRouterLabels(),
+		},
+		Data: map[string]string{brokerCAKey: string(ca)},
+	}
+	if err := controllerutil.SetControllerReference(mcpExt, desired, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set controller reference on broker ca configmap: %w", err)
+	}
+
+	existing := &corev1.ConfigMap{}
+	if err := r.DirectAPIReader.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get broker ca configmap: %w", err)
+		}
+		r.log.Info("creating broker ca configmap", "namespace", desired.Namespace, "name", desired.Name)
+		if err := r.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create broker ca configmap: %w", err)
+		}
+		return nil
+	}
+	if !metav1.IsControlledBy(existing, mcpExt) {
+		return newValidationError(mcpv1alpha1.ConditionReasonInvalid,
+			fmt.Sprintf("configmap %s already exists and is not managed by this MCPGatewayExtension", brokerCAConfigMapName))
+	}
+	if equality.Semantic.DeepEqual(existing.Data, desired.Data) && equality.Semantic.DeepEqual(existing.Labels, desired.Labels) {
+		return nil
+	}
+	r.log.Info("updating broker ca configmap", "namespace", existing.Namespace, "name", existing.Name)
+	existing.Data = desired.Data
+	existing.Labels = desired.Labels
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update broker ca configmap: %w", err)
+	}
+	return nil
+}
+
+// reconcileBrokerTLSPolicy creates or updates the BackendTLSPolicy making the gateway connect to the broker's TLS
+// port over TLS, verified against the broker CA
+func (r *MCPGatewayExtensionReconciler) reconcileBrokerTLSPolicy(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) error {
+	desired := buildBrokerTLSPolicy(mcpExt)
+	if err := controllerutil.SetControllerReference(mcpExt, desired, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set controller reference on backendtlspolicy: %w", err)
+	}
+
+	existing := &gatewayv1.BackendTLSPolicy{}
+	if err := r.DirectAPIReader.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+		if meta.IsNoMatchError(err) {
+			return newValidationError(mcpv1alpha1.ConditionReasonInvalid,
+				"brokerTLS requires the Gateway API BackendTLSPolicy resource, which is not installed")
+		}
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get backendtlspolicy: %w", err)
+		}
+		r.log.Info("creating broker backendtlspolicy", "namespace", desired.Namespace, "name", desired.Name)
+		if err := r.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create backendtlspolicy: %w", err)
+		}
+		return nil
+	}
+	if !metav1.IsControlledBy(existing, mcpExt) {
+		return newValidationError(mcpv1alpha1.ConditionReasonInvalid,
+			fmt.Sprintf("backendtlspolicy %s already exists and is not managed by this MCPGatewayExtension", brokerTLSPolicyName))
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, desired.Spec) {
+		return nil
+	}
+	r.log.Info("updating broker backendtlspolicy", "namespace", existing.Namespace, "name", existing.Name)
+	existing.Spec = desired.Spec
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update backendtlspolicy: %w", err)
+	}
+	return nil
+}
+
+// buildBrokerTLSPolicy builds the BackendTLSPolicy for the broker service's TLS port
+func buildBrokerTLSPolicy(mcpExt *mcpv1alpha1.MCPGatewayExtension) *gatewayv1.BackendTLSPolicy {
+	return &gatewayv1.BackendTLSPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      brokerTLSPolicyName,
+			Namespace: mcpExt.Namespace,
+			Labels:    brokerRouterLabels(),
+		},
+		Spec: gatewayv1.BackendTLSPolicySpec{
+			TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+				{
+					LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+						Group: "",
+						Kind:  "Service",
+						Name:  brokerRouterName,
+					},
+					SectionName: ptr.To(gatewayv1.SectionName(brokerHTTPSPortName)),
+				},
+			},
+			Validation: gatewayv1.BackendTLSPolicyValidation{
+				CACertificateRefs: []gatewayv1.LocalObjectReference{
+					{Group: "", Kind: "ConfigMap", Name: brokerCAConfigMapName},
+				},
+				Hostname: gatewayv1.PreciseHostname(brokerServiceHost(mcpExt.Namespace)),
+			},
+		},
+	}
+}
+
+// deleteBrokerTLSResources deletes the broker CA ConfigMap and BackendTLSPolicy managed by the extension
+func (r *MCPGatewayExtensionReconciler) deleteBrokerTLSResources(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) error {
+	for _, obj := range []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: brokerCAConfigMapName, Namespace: mcpExt.Namespace}},
+		&gatewayv1.BackendTLSPolicy{ObjectMeta: metav1.ObjectMeta{Name: brokerTLSPolicyName, Namespace: mcpExt.Namespace}},
+	} {
+		if err := r.DirectAPIReader.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			// without the BackendTLSPolicy resource installed there is no policy to delete
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %s: %w", obj.GetName(), err)
+		}
+		if !metav1.IsControlledBy(obj, mcpExt) {
+			continue
+		}
+		r.log.Info("deleting broker tls resource", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s: %w", obj.GetName(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+func TestReconcileBrokerTLS(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	certPEM, keyPEM := selfSignedCertificate(t, "mcp-gateway.test-ns.svc")
+	otherCertPEM, otherKeyPEM := selfSignedCertificate(t, "broker.example.com")
+	valid := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker-cert", Namespace: "test-ns"},
+		Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+	}
+	noKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-key", Namespace: "test-ns"},
+		Data:       map[string][]byte{corev1.TLSCertKey: certPEM},
+	}
+	notPEM := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "not-pem", Namespace: "test-ns"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	wrongHost := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "wrong-host", Namespace: "test-ns"},
+		Data:       map[string][]byte{corev1.TLSCertKey: otherCertPEM, corev1.TLSPrivateKeyKey: otherKeyPEM},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(valid, noKey, notPEM, wrongHost).Build()
+	r := &MCPGatewayExtensionReconciler{Client: k8sClient, DirectAPIReader: k8sClient, Scheme: scheme, log: slog.Default()}
+
+	tests := []struct {
+		name       string
+		brokerTLS  *mcpv1alpha1.BrokerTLS
+		wantReason string
+	}{
+		{name: "not configured"},
+		{name: "valid secret", brokerTLS: &mcpv1alpha1.BrokerTLS{SecretName: "broker-cert"}},
+		{name: "missing secret", brokerTLS: &mcpv1alpha1.BrokerTLS{SecretName: "missing"}, wantReason: mcpv1alpha1.ConditionReasonSecretNotFound},
+		{name: "missing key entry", brokerTLS: &mcpv1alpha1.BrokerTLS{SecretName: "no-key"}, wantReason: mcpv1alpha1.ConditionReasonSecretInvalid},
+		{name: "certificate not PEM", brokerTLS: &mcpv1alpha1.BrokerTLS{SecretName: "not-pem"}, wantReason: mcpv1alpha1.ConditionReasonSecretInvalid},
+		{name: "certificate does not name the service", brokerTLS: &mcpv1alpha1.BrokerTLS{SecretName: "wrong-host"}, wantReason: mcpv1alpha1.ConditionReasonSecretInvalid},
+		{
+			name:       "unknown cipher suite",
+			brokerTLS:  &mcpv1alpha1.BrokerTLS{SecretName: "broker-cert", CipherSuites: []string{"NOT_A_SUITE"}},
+			wantReason: mcpv1alpha1.ConditionReasonInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ext", Namespace: "test-ns", UID: "ext-uid"},
+				Spec:       mcpv1alpha1.MCPGatewayExtensionSpec{BrokerTLS: tt.brokerTLS},
+			}
+			err := r.reconcileBrokerTLS(context.Background(), mcpExt)
+			if tt.wantReason == "" {
+				require.NoError(t, err)
+				return
+			}
+			var valErr *validationError
+			require.ErrorAs(t, err, &valErr)
+			require.Equal(t, tt.wantReason, valErr.reason)
+		})
+	}
+}
+
+func TestReconcileBrokerTLS_PublishesCAAndPolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	certPEM, keyPEM := selfSignedCertificate(t, "mcp-gateway.test-ns.svc")
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker-cert", Namespace: "test-ns"},
+		Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	r := &MCPGatewayExtensionReconciler{Client: k8sClient, DirectAPIReader: k8sClient, Scheme: scheme, log: slog.Default()}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ext", Namespace: "test-ns", UID: "ext-uid"},
+		Spec:       mcpv1alpha1.MCPGatewayExtensionSpec{BrokerTLS: &mcpv1alpha1.BrokerTLS{SecretName: "broker-cert"}},
+	}
+	caKey := client.ObjectKey{Name: brokerCAConfigMapName, Namespace: "test-ns"}
+	policyKey := client.ObjectKey{Name: brokerTLSPolicyName, Namespace: "test-ns"}
+
+	require.NoError(t, r.reconcileBrokerTLS(context.Background(), mcpExt))
+	ca := &corev1.ConfigMap{}
+	require.NoError(t, k8sClient.Get(context.Background(), caKey, ca))
+	require.True(t, metav1.IsControlledBy(ca, mcpExt))
+	// a self-signed certificate is its own CA
+	require.Equal(t, string(certPEM), ca.Data[brokerCAKey])
+	policy := &gatewayv1.BackendTLSPolicy{}
+	require.NoError(t, k8sClient.Get(context.Background(), policyKey, policy))
+	require.True(t, metav1.IsControlledBy(policy, mcpExt))
+	require.Equal(t, gatewayv1.ObjectName("mcp-gateway"), policy.Spec.TargetRefs[0].Name)
+	require.Equal(t, gatewayv1.SectionName("https"), *policy.Spec.TargetRefs[0].SectionName)
+	require.Equal(t, gatewayv1.ObjectName(brokerCAConfigMapName), policy.Spec.Validation.CACertificateRefs[0].Name)
+	require.Equal(t, gatewayv1.PreciseHostname("mcp-gateway.test-ns.svc"), policy.Spec.Validation.Hostname)
+
+	t.Run("publishes the ca entry when the secret has one", func(t *testing.T) {
+		secret.Data[brokerCAKey] = []byte("issuing ca")
+		require.NoError(t, k8sClient.Update(context.Background(), secret))
+		require.NoError(t, r.reconcileBrokerTLS(context.Background(), mcpExt))
+		require.NoError(t, k8sClient.Get(context.Background(), caKey, ca))
+		require.Equal(t, "issuing ca", ca.Data[brokerCAKey])
+	})
+
+	t.Run("deletes both when tls is disabled", func(t *testing.T) {
+		disabled := mcpExt.DeepCopy()
+		disabled.Spec.BrokerTLS = nil
+		require.NoError(t, r.reconcileBrokerTLS(context.Background(), disabled))
+		require.True(t, apierrors.IsNotFound(k8sClient.Get(context.Background(), caKey, &corev1.ConfigMap{})))
+		require.True(t, apierrors.IsNotFound(k8sClient.Get(context.Background(), policyKey, &gatewayv1.BackendTLSPolicy{})))
+	})
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// configWaitBaseDelay is the first requeue while waiting for the broker to load a registration's config
+	configWaitBaseDelay = 2 * time.Second
+	// configWaitMaxDelay caps the requeue while waiting for the broker to load a registration's config
+	configWaitMaxDelay = 30 * time.Second
+	// configWaitJitter is the fraction of the delay added at random so registrations don't poll the broker together
+	configWaitJitter = 0.25
+)
+
+// configWait records how long a registration has been waiting for the broker to load its config. A wait belongs to
+// one generation and server id of the registration, as a change writes new config the broker has yet to load
+type configWait struct {
+	started    time.Time
+	attempts   int
+	generation int64
+	serverID   string
+}
+
+// configWaitRequeue returns how long to wait before the next status check of a registration waiting on the broker.
+// The delay doubles with each attempt up to configWaitMaxDelay, plus up to configWaitJitter of itself at random
+func configWaitRequeue(attempt int) time.Duration {
+	delay := configWaitMaxDelay
+	if attempt < 16 {
+		delay = min(configWaitBaseDelay<<attempt, configWaitMaxDelay)
+	}
+	return delay + time.Duration(rand.Float64()*configWaitJitter*float64(delay)) //nolint:gosec // jitter is not security sensitive
+}
+
+// currentConfigWait returns the wait of the registration for its generation and server id, starting a new one when
+// there is none or the recorded wait is for an earlier generation or server id
+func (r *MCPReconciler) currentConfigWait(key types.NamespacedName, generation int64, serverID string) *configWait {
+	if value, ok := r.configWaits.Load(key); ok {
+		if wait := value.(*configWait); wait.generation == generation && wait.serverID == serverID {
+			return wait
+		}
+	}
+	wait := &configWait{started: time.Now(), generation: generation, serverID: serverID}
+	r.configWaits.Store(key, wait)
+	return wait
+}
+
+// nextConfigWaitRequeue records another attempt for the registration and returns the requeue for it
+func (r *MCPReconciler) nextConfigWaitRequeue(key types.NamespacedName, generation int64, serverID string) time.Duration {
+	wait := r.currentConfigWait(key, generation, serverID)
+	requeue := configWaitRequeue(wait.attempts)
+	wait.attempts++
+	return requeue
+}
+
+// restartConfigWait restarts the ConfigLoadTimeout clock of a registration the broker reports, keeping its requeue
+// backoff. If the broker later stops reporting the server it gets the full timeout to load it again
+func (r *MCPReconciler) restartConfigWait(key types.NamespacedName) {
+	if value, ok := r.configWaits.Load(key); ok {
+		restarted := *value.(*configWait)
+		restarted.started = time.Now()
+		r.configWaits.Store(key, &restarted)
+	}
+}
+
+// resetConfigWait forgets the wait of a registration so its requeue backoff and ConfigLoadTimeout both start again at
+// its next status check. The wait is removed rather than updated as the registration may be reconciling concurrently
+func (r *MCPReconciler) resetConfigWait(key types.NamespacedName) {
+	r.configWaits.Delete(key)
+}
+
+// configLoadTimedOut checks if the registration has waited longer than ConfigLoadTimeout for the broker to load its
+// generation and server id
+func (r *MCPReconciler) configLoadTimedOut(key types.NamespacedName, generation int64, serverID string) bool {
+	if r.ConfigLoadTimeout <= 0 {
+		return false
+	}
+	return time.Since(r.currentConfigWait(key, generation, serverID).started) > r.ConfigLoadTimeout
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+func TestConfigWaitRequeue(t *testing.T) {
+	maxJittered := time.Duration(float64(configWaitMaxDelay) * (1 + configWaitJitter))
+	for attempt := range 100 {
+		delay := min(configWaitBaseDelay<<min(attempt, 16), configWaitMaxDelay)
+		requeue := configWaitRequeue(attempt)
+		require.GreaterOrEqual(t, requeue, delay, "attempt %d", attempt)
+		require.LessOrEqual(t, requeue, time.Duration(float64(delay)*(1+configWaitJitter)), "attempt %d", attempt)
+		require.LessOrEqual(t, requeue, maxJittered, "attempt %d", attempt)
+	}
+	require.Less(t, configWaitRequeue(0), configWaitRequeue(3), "requeue should grow with attempts")
+
+	// registrations waiting together should not all requeue together
+	requeues := map[time.Duration]bool{}
+	for range 10 {
+		requeues[configWaitRequeue(2)] = true
+	}
+	require.Greater(t, len(requeues), 1)
+}
+
+func TestNextConfigWaitRequeue(t *testing.T) {
+	r := &MCPReconciler{}
+	key := client.ObjectKey{Namespace: "team-a", Name: "weather"}
+	first := r.nextConfigWaitRequeue(key, 1, "id")
+	require.GreaterOrEqual(t, first, configWaitBaseDelay)
+	require.Less(t, first, 2*configWaitBaseDelay)
+	second := r.nextConfigWaitRequeue(key, 1, "id")
+	require.GreaterOrEqual(t, second, 2*configWaitBaseDelay)
+	require.Less(t, second, 4*configWaitBaseDelay)
+
+	// other registrations start their own backoff
+	other := r.nextConfigWaitRequeue(client.ObjectKey{Namespace: "team-a", Name: "other"}, 1, "id")
+	require.Less(t, other, 2*configWaitBaseDelay)
+
+	// new config starts a new wait
+	value, _ := r.configWaits.Load(key)
+	value.(*configWait).started = time.Now().Add(-time.Hour)
+	require.Less(t, r.nextConfigWaitRequeue(key, 2, "id"), 2*configWaitBaseDelay, "a generation change should restart the backoff")
+	value, _ = r.configWaits.Load(key)
+	require.WithinDuration(t, time.Now(), value.(*configWait).started, time.Minute)
+	value.(*configWait).started = time.Now().Add(-time.Hour)
+	require.Less(t, r.nextConfigWaitRequeue(key, 2, "other-id"), 2*configWaitBaseDelay, "a server id change should restart the backoff")
+	value, _ = r.configWaits.Load(key)
+	require.WithinDuration(t, time.Now(), value.(*configWait).started, time.Minute)
+}
+
+func TestResetConfigWait(t *testing.T) {
+	r := &MCPReconciler{}
+	key := client.ObjectKey{Namespace: "team-a", Name: "weather"}
+	for range 5 {
+		r.nextConfigWaitRequeue(key, 1, "id")
+	}
+	value, _ := r.configWaits.Load(key)
+	value.(*configWait).started = time.Now().Add(-time.Hour)
+	require.GreaterOrEqual(t, r.nextConfigWaitRequeue(key, 1, "id"), configWaitMaxDelay)
+
+	r.resetConfigWait(key)
+	require.Less(t, r.nextConfigWaitRequeue(key, 1, "id"), 2*configWaitBaseDelay)
+	value, _ = r.configWaits.Load(key)
+	require.WithinDuration(t, time.Now(), value.(*configWait).started, time.Minute, "the config load timeout should count from the reset")
+}
+
+func TestRestartConfigWait(t *testing.T) {
+	r := &MCPReconciler{}
+	key := client.ObjectKey{Namespace: "team-a", Name: "weather"}
+
+	// registrations that are not waiting are left alone
+	r.restartConfigWait(key)
+	_, waiting := r.configWaits.Load(key)
+	require.False(t, waiting)
+
+	for range 5 {
+		r.nextConfigWaitRequeue(key, 1, "id")
+	}
+	value, _ := r.configWaits.Load(key)
+	value.(*configWait).started = time.Now().Add(-time.Hour)
+
+	r.restartConfigWait(key)
+	value, _ = r.configWaits.Load(key)
+	require.WithinDuration(t, time.Now(), value.(*configWait).started, time.Minute)
+	require.GreaterOrEqual(t, r.nextConfigWaitRequeue(key, 1, "id"), configWaitMaxDelay, "the backoff should be kept")
+}
+
+func TestExtensionBecameReady(t *testing.T) {
+	extension := func(status metav1.ConditionStatus) *mcpv1alpha1.MCPGatewayExtension {
+		ext := &mcpv1alpha1.MCPGatewayExtension{}
+		if status != "" {
+			ext.SetReadyCondition(status, mcpv1alpha1.ConditionReasonDeploymentNotReady, "")
+		}
+		return ext
+	}
+	require.True(t, extensionBecameReady(extension(metav1.ConditionFalse), extension(metav1.ConditionTrue)))
+	require.True(t, extensionBecameReady(extension(""), extension(metav1.ConditionTrue)))
+	require.False(t, extensionBecameReady(extension(metav1.ConditionTrue), extension(metav1.ConditionTrue)))
+	require.False(t, extensionBecameReady(extension(metav1.ConditionTrue), extension(metav1.ConditionFalse)))
+	require.False(t, extensionBecameReady(extension(metav1.ConditionFalse), extension(metav1.ConditionFalse)))
+}
+
+func TestSetMCPServerRegistrationStatus_ConfigLoadTimeout(t *testing.T) {
+	f := newBrokerStatusFixture(t, &mcpv1alpha1.MCPServerRegistration{ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a"}})
+	f.r.ConfigLoadTimeout = time.Minute
+	key := client.ObjectKeyFromObject(f.mcpsr)
+	readyReason := func(t *testing.T) string {
+		t.Helper()
+		require.ErrorIs(t, f.setStatus(t), errServerNotPresent)
+		return meta.FindStatusCondition(f.current(t).Status.Conditions, mcpv1alpha1.ConditionTypeReady).Reason
+	}
+
+	f.r.nextConfigWaitRequeue(key, 0, "id")
+	require.Equal(t, "NotReady", readyReason(t))
+
+	// waiting longer than the timeout surfaces it in status
+	value, _ := f.r.configWaits.Load(key)
+	value.(*configWait).started = time.Now().Add(-2 * time.Minute)
+	require.Equal(t, ReasonConfigLoadTimeout, readyReason(t))
+
+	// a new generation gets the full timeout
+	fresh := f.current(t)
+	fresh.Generation++
+	require.NoError(t, f.r.Update(context.Background(), fresh))
+	require.Equal(t, "NotReady", readyReason(t))
+}
+
+func TestSetMCPServerRegistrationStatus_ConfigLoadTimeoutBrokerUnreachable(t *testing.T) {
+	f := newBrokerStatusFixture(t, &mcpv1alpha1.MCPServerRegistration{ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a"}})
+	f.r.ConfigLoadTimeout = time.Minute
+	key := client.ObjectKeyFromObject(f.mcpsr)
+	f.r.nextConfigWaitRequeue(key, 0, "id")
+	value, _ := f.r.configWaits.Load(key)
+	value.(*configWait).started = time.Now().Add(-2 * time.Minute)
+
+	// the time the broker is unreachable doesn't count towards the timeout
+	reachable := f.r.StatusFetcher
+	closedBroker := httptest.NewServer(http.NotFoundHandler())
+	closedBroker.Close()
+	f.r.StatusFetcher = &fakeBrokerFetcher{validator: NewServerValidator(f.r.Client), url: closedBroker.URL}
+	require.ErrorIs(t, f.setStatus(t), ErrBrokerUnreachable)
+
+	f.r.StatusFetcher = reachable
+	require.ErrorIs(t, f.setStatus(t), errServerNotPresent)
+	require.Equal(t, "NotReady", meta.FindStatusCondition(f.current(t).Status.Conditions, mcpv1alpha1.ConditionTypeReady).Reason)
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// CredentialLabel is the label a Secret must carry to be used as a credential by an MCPServerRegistration
+type CredentialLabel struct {
+	Key   string
+	Value string
+}
+
+// DefaultCredentialLabel is the credential label used when none is configured
+var DefaultCredentialLabel = CredentialLabel{Key: CredentialSecretLabel, Value: CredentialSecretValue}
+
+// ParseCredentialLabel parses a credential label in the form key=value
+func ParseCredentialLabel(label string) (CredentialLabel, error) {
+	key, value, ok := strings.Cut(label, "=")
+	if !ok {
+		return CredentialLabel{}, fmt.Errorf("credential label %q must be in the form key=value", label)
+	}
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return CredentialLabel{}, fmt.Errorf("invalid credential label key %q: %s", key, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return CredentialLabel{}, fmt.Errorf("invalid credential label value %q: %s", value, strings.Join(errs, ", "))
+	}
+	return CredentialLabel{Key: key, Value: value}, nil
+}
+
+// String returns the label in the form key=value
+func (l CredentialLabel) String() string {
+	return l.Key + "=" + l.Value
+}
+
+// orDefault returns DefaultCredentialLabel for the zero value
+func (l CredentialLabel) orDefault() CredentialLabel {
+	if l.Key == "" {
+		return DefaultCredentialLabel
+	}
+	return l
+}
+
+// matches checks if the labels carry the credential label
+func (l CredentialLabel) matches(secretLabels map[string]string) bool {
+	l = l.orDefault()
+	value, ok := secretLabels[l.Key]
+	return ok && value == l.Value
+}
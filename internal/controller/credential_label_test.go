@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+func TestParseCredentialLabel(t *testing.T) {
+	label, err := ParseCredentialLabel("team-a.example.com/mcp-credential=enabled")
+	require.NoError(t, err)
+	require.Equal(t, CredentialLabel{Key: "team-a.example.com/mcp-credential", Value: "enabled"}, label)
+
+	label, err = ParseCredentialLabel(DefaultCredentialLabel.String())
+	require.NoError(t, err)
+	require.Equal(t, DefaultCredentialLabel, label)
+
+	_, err = ParseCredentialLabel("mcp.kuadrant.io/credential")
+	require.ErrorContains(t, err, "key=value")
+	_, err = ParseCredentialLabel("not a key=true")
+	require.ErrorContains(t, err, "invalid credential label key")
+	_, err = ParseCredentialLabel("mcp.kuadrant.io/credential=not a value")
+	require.ErrorContains(t, err, "invalid credential label value")
+}
+
+func TestReconcile_CustomCredentialLabel(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	custom := CredentialLabel{Key: "team-a.example.com/mcp-credential", Value: "enabled"}
+	customLabeled := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom", Namespace: "team-a", Labels: map[string]string{custom.Key: custom.Value}},
+		Data:       map[string][]byte{"token": []byte("Bearer custom")},
+	}
+	defaultLabeled := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "team-a", Labels: map[string]string{CredentialSecretLabel: CredentialSecretValue}},
+		Data:       map[string][]byte{"token": []byte("Bearer default")},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: "team-a"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Name: "http", Port: 9090}}},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(customLabeled, defaultLabeled, service).Build()
+	r := &MCPReconciler{Client: k8sClient, DirectAPIReader: k8sClient, Scheme: scheme, CredentialLabel: custom}
+	registration := func(secretName string) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "registration", Namespace: "team-a"},
+			Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+				TargetRef:     mcpv1alpha1.TargetReference{Kind: "Service", Name: "mcp-server"},
+				Path:          "/mcp",
+				CredentialRef: &mcpv1alpha1.SecretReference{Name: secretName, Key: "token"},
+			},
+		}
+	}
+
+	serverConfig, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("custom"))
+	require.NoError(t, err)
+	require.Equal(t, "Bearer custom", serverConfig.Credential)
+
+	_, err = r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("default"))
+	require.ErrorContains(t, err, "missing required label team-a.example.com/mcp-credential=enabled")
+
+	// only secrets with the custom label trigger reconciles
+	p := credentialSecretPredicate(custom, nil)
+	require.True(t, p.Create(event.CreateEvent{Object: customLabeled}))
+	require.False(t, p.Create(event.CreateEvent{Object: defaultLabeled}))
+}
@@ -1,12 +1,16 @@
 package controller
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -203,6 +207,64 @@ func TestDeploymentNeedsUpdate(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "strategy changed",
+			modify: func(d *appsv1.Deployment) {
+				d.Spec.Strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+			},
+			expected: true,
+		},
+		{
+			name: "replicas changed",
+			modify: func(d *appsv1.Deployment) {
+				d.Spec.Replicas = ptr.To(int32(3))
+			},
+			expected: true,
+		},
+		{
+			name: "image pull policy changed",
+			modify: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
+			},
+			expected: true,
+		},
+		{
+			name: "resources changed",
+			modify: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				}
+			},
+			expected: true,
+		},
+		{
+			name: "security context changed",
+			modify: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{RunAsNonRoot: ptr.To(true)}
+			},
+			expected: true,
+		},
+		{
+			name: "empty security context defaulted by the api server",
+			modify: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+			},
+			expected: false,
+		},
+		{
+			name: "pod security context changed",
+			modify: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{FSGroup: ptr.To(int64(1000))}
+			},
+			expected: true,
+		},
+		{
+			name: "node selector changed",
+			modify: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.NodeSelector = map[string]string{"kubernetes.io/os": "linux"}
+			},
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -219,6 +281,28 @@ func TestDeploymentNeedsUpdate(t *testing.T) {
 	}
 }
 
+func TestDeploymentNeedsUpdate_Replicas(t *testing.T) {
+	deployment := func(replicas *int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{
+				Replicas: replicas,
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: brokerRouterName, Image: "test-image:v1"}},
+					},
+				},
+			},
+		}
+	}
+
+	if needsUpdate, reason := deploymentNeedsUpdate(deployment(ptr.To(int32(2))), deployment(ptr.To(int32(1)))); !needsUpdate {
+		t.Errorf("expected update when spec replicas differ, reason: %s", reason)
+	}
+	if needsUpdate, reason := deploymentNeedsUpdate(deployment(ptr.To(int32(2))), deployment(ptr.To(int32(2)))); needsUpdate {
+		t.Errorf("expected no update when replicas match, reason: %s", reason)
+	}
+}
+
 func TestDeploymentNeedsUpdate_EmptyContainers(t *testing.T) {
 	desired := &appsv1.Deployment{
 		Spec: appsv1.DeploymentSpec{
@@ -414,6 +498,37 @@ func TestBuildBrokerRouterDeployment_PublicHost(t *testing.T) {
 	}
 }
 
+func TestDeploymentNeedsUpdate_PublicHost(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+		},
+	}
+	internalHost := "my-gateway-istio.gateway-system.svc.cluster.local:8080"
+
+	existing := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", internalHost)
+	if needsUpdate, reason := deploymentNeedsUpdate(r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", internalHost), existing); needsUpdate {
+		t.Errorf("expected no update for the same public host, reason: %s", reason)
+	}
+	needsUpdate, reason := deploymentNeedsUpdate(r.buildBrokerRouterDeployment(mcpExt, "changed.example.com", internalHost), existing)
+	if !needsUpdate {
+		t.Fatal("expected an update when the public host changes")
+	}
+	if !strings.Contains(reason, "--mcp-gateway-public-host=changed.example.com") {
+		t.Errorf("expected reason to name the new public host, got %q", reason)
+	}
+}
+
 func TestBuildBrokerRouterDeployment_InternalHost(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -538,6 +653,271 @@ func TestBuildBrokerRouterDeployment_PollInterval(t *testing.T) {
 	}
 }
 
+func TestBuildBrokerRouterDeployment_ToolCallConcurrency(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+		},
+	}
+
+	deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	for _, arg := range deployment.Spec.Template.Spec.Containers[0].Command {
+		if strings.HasPrefix(arg, "--tool-call-concurrency=") {
+			t.Errorf("expected no --tool-call-concurrency flag, but found %q", arg)
+		}
+	}
+
+	mcpExt.Spec.ToolCallConcurrencyPerServer = ptr.To(int32(5))
+	deployment = r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	if !slices.Contains(deployment.Spec.Template.Spec.Containers[0].Command, "--tool-call-concurrency=5") {
+		t.Errorf("expected --tool-call-concurrency=5 in command %v", deployment.Spec.Template.Spec.Containers[0].Command)
+	}
+}
+
+func TestBuildBrokerRouterDeployment_ToolCallRetries(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+		},
+	}
+
+	deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	for _, arg := range deployment.Spec.Template.Spec.Containers[0].Command {
+		if strings.HasPrefix(arg, "--tool-call-retries=") {
+			t.Errorf("expected no --tool-call-retries flag, but found %q", arg)
+		}
+	}
+
+	mcpExt.Spec.ToolCallRetries = ptr.To(int32(3))
+	deployment = r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	if !slices.Contains(deployment.Spec.Template.Spec.Containers[0].Command, "--tool-call-retries=3") {
+		t.Errorf("expected --tool-call-retries=3 in command %v", deployment.Spec.Template.Spec.Containers[0].Command)
+	}
+}
+
+func TestBuildBrokerRouterDeployment_MaxTotalTools(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+		},
+	}
+
+	deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	for _, arg := range deployment.Spec.Template.Spec.Containers[0].Command {
+		if strings.HasPrefix(arg, "--max-total-tools=") {
+			t.Errorf("expected no --max-total-tools flag, but found %q", arg)
+		}
+	}
+
+	mcpExt.Spec.MaxTotalTools = ptr.To(int32(200))
+	deployment = r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	if !slices.Contains(deployment.Spec.Template.Spec.Containers[0].Command, "--max-total-tools=200") {
+		t.Errorf("expected --max-total-tools=200 in command %v", deployment.Spec.Template.Spec.Containers[0].Command)
+	}
+}
+
+func TestBuildBrokerRouterDeployment_GRPCMaxMessageSize(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+			ExtProcGRPC: &mcpv1alpha1.ExtProcGRPCConfig{KeepaliveInterval: &metav1.Duration{Duration: time.Minute}},
+		},
+	}
+
+	deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	for _, arg := range deployment.Spec.Template.Spec.Containers[0].Command {
+		if strings.HasPrefix(arg, "--grpc-max-message-size=") {
+			t.Errorf("expected no --grpc-max-message-size flag, but found %q", arg)
+		}
+	}
+
+	mcpExt.Spec.ExtProcGRPC.MaxMessageSize = ptr.To(int32(8 * 1024 * 1024))
+	deployment = r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	if !slices.Contains(deployment.Spec.Template.Spec.Containers[0].Command, "--grpc-max-message-size=8388608") {
+		t.Errorf("expected --grpc-max-message-size=8388608 in command %v", deployment.Spec.Template.Spec.Containers[0].Command)
+	}
+}
+
+func TestBuildBrokerRouterDeployment_Instructions(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+		},
+	}
+
+	deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	for _, arg := range deployment.Spec.Template.Spec.Containers[0].Command {
+		if strings.HasPrefix(arg, "--instructions=") {
+			t.Errorf("expected no --instructions flag, but found %q", arg)
+		}
+	}
+
+	mcpExt.Spec.Instructions = "Use the weather tools for forecasts.\nAsk before booking."
+	deployment = r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	if !slices.Contains(deployment.Spec.Template.Spec.Containers[0].Command, "--instructions=Use the weather tools for forecasts.\nAsk before booking.") {
+		t.Errorf("expected the instructions flag in command %v", deployment.Spec.Template.Spec.Containers[0].Command)
+	}
+}
+
+func TestBrokerDeploymentStrategy(t *testing.T) {
+	withCommand := func(args ...string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: brokerRouterName, Command: append([]string{"./mcp_gateway"}, args...)},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		strategy     mcpv1alpha1.DeploymentStrategyType
+		sessionStore *mcpv1alpha1.SessionStore
+		existing     *appsv1.Deployment
+		want         appsv1.DeploymentStrategyType
+	}{
+		{
+			name: "defaults to recreate on create",
+			want: appsv1.RecreateDeploymentStrategyType,
+		},
+		{
+			name:     "defaults to recreate without cache",
+			existing: withCommand("--log-level=-4"),
+			want:     appsv1.RecreateDeploymentStrategyType,
+		},
+		{
+			name:     "defaults to rolling update with cache flag",
+			existing: withCommand("--cache-connection-string=redis://redis:6379"),
+			want:     appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			name:     "empty cache flag is not a cache",
+			existing: withCommand("--cache-connection-string="),
+			want:     appsv1.RecreateDeploymentStrategyType,
+		},
+		{
+			name:     "spec recreate overrides cache",
+			strategy: mcpv1alpha1.DeploymentStrategyRecreate,
+			existing: withCommand("--cache-connection-string=redis://redis:6379"),
+			want:     appsv1.RecreateDeploymentStrategyType,
+		},
+		{
+			name:     "spec rolling update without cache",
+			strategy: mcpv1alpha1.DeploymentStrategyRollingUpdate,
+			want:     appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			name:         "defaults to rolling update with session store",
+			sessionStore: &mcpv1alpha1.SessionStore{Type: mcpv1alpha1.SessionStoreRedis, SecretName: "redis"},
+			want:         appsv1.RollingUpdateDeploymentStrategyType,
+		},
+		{
+			name:         "spec recreate overrides session store",
+			strategy:     mcpv1alpha1.DeploymentStrategyRecreate,
+			sessionStore: &mcpv1alpha1.SessionStore{Type: mcpv1alpha1.SessionStoreRedis, SecretName: "redis"},
+			want:         appsv1.RecreateDeploymentStrategyType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+				Spec: mcpv1alpha1.MCPGatewayExtensionSpec{DeploymentStrategy: tt.strategy, SessionStore: tt.sessionStore},
+			}
+			got := brokerDeploymentStrategy(mcpExt, tt.existing)
+			if got.Type != tt.want {
+				t.Errorf("brokerDeploymentStrategy() = %q, want %q", got.Type, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildBrokerRouterDeployment_Strategy(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+		},
+	}
+
+	existing := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	if existing.Spec.Strategy.Type != appsv1.RecreateDeploymentStrategyType {
+		t.Fatalf("expected default strategy Recreate, got %q", existing.Spec.Strategy.Type)
+	}
+
+	mcpExt.Spec.DeploymentStrategy = mcpv1alpha1.DeploymentStrategyRollingUpdate
+	desired := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	if needsUpdate, reason := deploymentNeedsUpdate(desired, existing); !needsUpdate {
+		t.Errorf("expected strategy change to roll out the deployment")
+	} else if !strings.Contains(reason, "strategy changed") {
+		t.Errorf("expected strategy change reason, got %q", reason)
+	}
+}
+
 func TestBuildBrokerRouterDeployment_RouterKey(t *testing.T) {
 	r := &MCPGatewayExtensionReconciler{
 		BrokerRouterImage: "test-image:v1",
@@ -678,6 +1058,175 @@ func TestBuildBrokerRouterDeployment_TrustedHeadersKey(t *testing.T) {
 	}
 }
 
+func TestBuildBrokerRouterDeployment_Replicas(t *testing.T) {
+	tests := []struct {
+		name         string
+		replicas     *int32
+		wantReplicas int32
+	}{
+		{name: "defaults to one replica when not set", wantReplicas: 1},
+		{name: "replicas from spec", replicas: ptr.To(int32(3)), wantReplicas: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MCPGatewayExtensionReconciler{
+				BrokerRouterImage: "test-image:v1",
+			}
+			mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ext",
+					Namespace: "test-ns",
+				},
+				Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+					Replicas: tt.replicas,
+					TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+						Name:      "my-gateway",
+						Namespace: "gateway-system",
+					},
+				},
+			}
+
+			deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+			if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != tt.wantReplicas {
+				t.Errorf("expected %d replicas, got %v", tt.wantReplicas, deployment.Spec.Replicas)
+			}
+		})
+	}
+}
+
+func TestBuildBrokerRouterDeployment_PodTemplate(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+		},
+	}
+
+	// the defaults meet the restricted pod security standard
+	deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.ImagePullPolicy != corev1.PullIfNotPresent {
+		t.Errorf("expected image pull policy IfNotPresent, got %q", container.ImagePullPolicy)
+	}
+	securityContext := container.SecurityContext
+	if securityContext == nil {
+		t.Fatal("expected a default security context")
+	}
+	if !ptr.Deref(securityContext.RunAsNonRoot, false) || ptr.Deref(securityContext.RunAsUser, 0) == 0 {
+		t.Errorf("expected the container to run as a non-root user, got %+v", securityContext)
+	}
+	if ptr.Deref(securityContext.AllowPrivilegeEscalation, true) {
+		t.Error("expected privilege escalation to be disallowed")
+	}
+	if securityContext.Capabilities == nil || !slices.Equal(securityContext.Capabilities.Drop, []corev1.Capability{"ALL"}) {
+		t.Errorf("expected all capabilities to be dropped, got %+v", securityContext.Capabilities)
+	}
+	if securityContext.SeccompProfile == nil || securityContext.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Errorf("expected the RuntimeDefault seccomp profile, got %+v", securityContext.SeccompProfile)
+	}
+	podSecurityContext := deployment.Spec.Template.Spec.SecurityContext
+	if podSecurityContext == nil || ptr.Deref(podSecurityContext.FSGroup, 0) != ptr.Deref(securityContext.RunAsGroup, -1) {
+		t.Errorf("expected the pod fsGroup to match the container group, got %+v", podSecurityContext)
+	}
+	if len(container.Resources.Limits) != 0 || len(container.Resources.Requests) != 0 {
+		t.Errorf("expected no resources by default, got %+v", container.Resources)
+	}
+
+	mcpExt.Spec.PodTemplate = &mcpv1alpha1.BrokerPodTemplate{
+		Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+		SecurityContext: &corev1.SecurityContext{RunAsNonRoot: ptr.To(true)},
+		ImagePullPolicy: corev1.PullAlways,
+		NodeSelector:    map[string]string{"kubernetes.io/os": "linux"},
+	}
+	overridden := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	container = overridden.Spec.Template.Spec.Containers[0]
+	if container.ImagePullPolicy != corev1.PullAlways {
+		t.Errorf("expected image pull policy Always, got %q", container.ImagePullPolicy)
+	}
+	if !container.Resources.Limits.Memory().Equal(resource.MustParse("256Mi")) || !container.Resources.Requests.Cpu().Equal(resource.MustParse("100m")) {
+		t.Errorf("expected resources from the pod template, got %+v", container.Resources)
+	}
+	if container.SecurityContext == nil || container.SecurityContext.RunAsUser != nil || container.SecurityContext.Capabilities != nil {
+		t.Errorf("expected the security context to be replaced, got %+v", container.SecurityContext)
+	}
+	if overridden.Spec.Template.Spec.SecurityContext != nil {
+		t.Errorf("expected no pod fsGroup with a security context override, got %+v", overridden.Spec.Template.Spec.SecurityContext)
+	}
+	if overridden.Spec.Template.Spec.NodeSelector["kubernetes.io/os"] != "linux" {
+		t.Errorf("expected node selector from the pod template, got %v", overridden.Spec.Template.Spec.NodeSelector)
+	}
+	if needsUpdate, _ := deploymentNeedsUpdate(overridden, deployment); !needsUpdate {
+		t.Error("expected a pod template change to update the deployment")
+	}
+	if needsUpdate, reason := deploymentNeedsUpdate(overridden, overridden.DeepCopy()); needsUpdate {
+		t.Errorf("expected no update for an unchanged pod template, reason: %s", reason)
+	}
+}
+
+func TestBuildBrokerRouterDeployment_SessionStore(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+		},
+	}
+
+	deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 1 {
+		t.Errorf("expected 1 replica by default, got %v", deployment.Spec.Replicas)
+	}
+	if cacheConfigured(deployment) {
+		t.Error("expected no cache to be configured without a session store")
+	}
+
+	mcpExt.Spec.SessionStore = &mcpv1alpha1.SessionStore{Type: mcpv1alpha1.SessionStoreRedis, SecretName: "redis-connection"}
+	mcpExt.Spec.Replicas = ptr.To(int32(3))
+	deployment = r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	container := deployment.Spec.Template.Spec.Containers[0]
+
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 3 {
+		t.Errorf("expected 3 replicas, got %v", deployment.Spec.Replicas)
+	}
+	if !slices.Contains(container.Command, "--cache-connection-string=$(CACHE_CONNECTION_STRING)") {
+		t.Errorf("expected cache connection flag in command, got %v", container.Command)
+	}
+	if len(container.Env) != 1 {
+		t.Fatalf("expected 1 env var, got %d", len(container.Env))
+	}
+	env := container.Env[0]
+	if env.Name != "CACHE_CONNECTION_STRING" || env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil {
+		t.Fatalf("expected CACHE_CONNECTION_STRING from a secret, got %+v", env)
+	}
+	if env.ValueFrom.SecretKeyRef.Name != "redis-connection" || env.ValueFrom.SecretKeyRef.Key != "connectionString" {
+		t.Errorf("expected secret redis-connection key connectionString, got %+v", env.ValueFrom.SecretKeyRef)
+	}
+	if deployment.Spec.Strategy.Type != appsv1.RollingUpdateDeploymentStrategyType {
+		t.Errorf("expected rolling update with a session store, got %q", deployment.Spec.Strategy.Type)
+	}
+}
+
 func TestServiceAccountNeedsUpdate(t *testing.T) {
 	trueVal := true
 	falseVal := false
@@ -934,6 +1483,11 @@ func TestFindListenerConfig(t *testing.T) {
 					Port:     9090,
 					Protocol: gatewayv1.HTTPProtocolType,
 				},
+				{
+					Name:     "tcp",
+					Port:     9000,
+					Protocol: gatewayv1.TCPProtocolType,
+				},
 			},
 		},
 	}
@@ -971,6 +1525,11 @@ func TestFindListenerConfig(t *testing.T) {
 			sectionName: "nonexistent",
 			wantErr:     true,
 		},
+		{
+			name:        "returns error for listener with incompatible protocol",
+			sectionName: "tcp",
+			wantErr:     true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1281,3 +1840,129 @@ func TestHTTPRouteNeedsUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildBrokerRouterDeployment_BrokerTLS(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:      "my-gateway",
+				Namespace: "gateway-system",
+			},
+		},
+	}
+
+	deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	for _, arg := range deployment.Spec.Template.Spec.Containers[0].Command {
+		if strings.HasPrefix(arg, "--tls-") {
+			t.Errorf("expected no tls flags, but found %q", arg)
+		}
+	}
+	if len(deployment.Spec.Template.Spec.Volumes) != 1 {
+		t.Errorf("expected only the config volume, got %+v", deployment.Spec.Template.Spec.Volumes)
+	}
+
+	mcpExt.Spec.BrokerTLS = &mcpv1alpha1.BrokerTLS{
+		SecretName:   "broker-cert",
+		MinVersion:   "1.3",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+	}
+	deployment = r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+	container := deployment.Spec.Template.Spec.Containers[0]
+	for _, want := range []string{
+		"--tls-cert-file=/tls/tls.crt",
+		"--tls-key-file=/tls/tls.key",
+		"--tls-min-version=1.3",
+		"--tls-cipher-suites=TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	} {
+		if !slices.Contains(container.Command, want) {
+			t.Errorf("expected %q in command %v", want, container.Command)
+		}
+	}
+	if container.Ports[0].Name != "https" {
+		t.Errorf("expected the public port to be named https, got %q", container.Ports[0].Name)
+	}
+
+	var mounted bool
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == "tls-volume" && mount.MountPath == "/tls" && mount.ReadOnly {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("expected the tls volume mounted read only at /tls, got %+v", container.VolumeMounts)
+	}
+	var secretName string
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == "tls-volume" && volume.Secret != nil {
+			secretName = volume.Secret.SecretName
+		}
+	}
+	if secretName != "broker-cert" {
+		t.Errorf("expected the tls volume to use secret broker-cert, got %q", secretName)
+	}
+
+	service := r.buildBrokerRouterService(mcpExt)
+	if service.Spec.Ports[0].Name != "https" {
+		t.Errorf("expected the service public port to be named https, got %q", service.Spec.Ports[0].Name)
+	}
+}
+
+func TestBuildBrokerRouterDeployment_BrokerPort(t *testing.T) {
+	r := &MCPGatewayExtensionReconciler{
+		BrokerRouterImage: "test-image:v1",
+	}
+	mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ext",
+			Namespace: "test-ns",
+		},
+		Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+			TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{
+				Name:        "my-gateway",
+				Namespace:   "gateway-system",
+				SectionName: "mcp",
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name       string
+		brokerPort *int32
+		wantPort   int32
+	}{
+		{name: "default port", wantPort: 8080},
+		{name: "custom port", brokerPort: ptr.To(int32(9443)), wantPort: 9443},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mcpExt.Spec.BrokerPort = tc.brokerPort
+
+			deployment := r.buildBrokerRouterDeployment(mcpExt, "mcp.example.com", mcpExt.InternalHost(8080))
+			container := deployment.Spec.Template.Spec.Containers[0]
+			wantFlag := fmt.Sprintf("--mcp-broker-public-address=0.0.0.0:%d", tc.wantPort)
+			if !slices.Contains(container.Command, wantFlag) {
+				t.Errorf("expected %q in command %v", wantFlag, container.Command)
+			}
+			if container.Ports[0].ContainerPort != tc.wantPort {
+				t.Errorf("expected container port %d, got %d", tc.wantPort, container.Ports[0].ContainerPort)
+			}
+
+			service := r.buildBrokerRouterService(mcpExt)
+			if service.Spec.Ports[0].Port != tc.wantPort || service.Spec.Ports[0].TargetPort.IntVal != tc.wantPort {
+				t.Errorf("expected service port %d, got %+v", tc.wantPort, service.Spec.Ports[0])
+			}
+
+			route := r.buildGatewayHTTPRoute(mcpExt, "mcp.example.com")
+			backendPort := route.Spec.Rules[0].BackendRefs[0].Port
+			if backendPort == nil || int32(*backendPort) != tc.wantPort {
+				t.Errorf("expected httproute backend port %d, got %v", tc.wantPort, backendPort)
+			}
+		})
+	}
+}
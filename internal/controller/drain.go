@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+)
+
+// drainPollInterval is how often the brokers are checked while a deleted registration's server drains
+const drainPollInterval = 2 * time.Second
+
+// ServerDrainStatus is the drain state of a server across the brokers in a namespace
+type ServerDrainStatus struct {
+	// Draining is true once every broker has stopped listing the server's tools
+	Draining bool
+	// InFlightToolCalls is the number of tool calls to the server that have not completed
+	InFlightToolCalls int
+}
+
+// BrokerDrainFetcher fetches the drain status of a server from the brokers in a namespace
+type BrokerDrainFetcher interface {
+	DrainStatus(ctx context.Context, namespace, serverID string) (ServerDrainStatus, error)
+}
+
+// drainServer keeps a deleted registration's server in the broker config until the tool calls in flight to it
+// complete or its drain timeout elapses. The server is marked draining so the brokers stop listing its tools.
+// It returns how long to wait before checking again, or 0 once the server can be removed
+func (r *MCPReconciler) drainServer(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (_ time.Duration, err error) {
+	ctx, span := startSpan(ctx, "MCPServerRegistration.drainServer", "MCPServerRegistration", client.ObjectKeyFromObject(mcpsr),
+		attribute.String("mcp.server.id", mcpsr.Status.ServerID))
+	defer func() { endSpan(span, err) }()
+	logger := logf.FromContext(ctx)
+	if mcpsr.Spec.DrainTimeout == nil || mcpsr.Spec.DrainTimeout.Duration <= 0 || mcpsr.Status.ServerID == "" {
+		return 0, nil
+	}
+	remaining := time.Until(mcpsr.DeletionTimestamp.Add(mcpsr.Spec.DrainTimeout.Duration))
+	if remaining <= 0 {
+		logger.Info("drain timeout elapsed, removing server", "mcpregistrationname", mcpsr.Name, "drainTimeout", mcpsr.Spec.DrainTimeout.Duration)
+		return 0, nil
+	}
+
+	for _, ns := range mcpsr.Status.ConfigNamespaces {
+		if err := r.ConfigReaderWriter.MarkMCPServerDraining(ctx, mcpServerName(mcpsr), config.NamespaceName(ns)); err != nil {
+			return 0, fmt.Errorf("failed to mark server draining %w", err)
+		}
+	}
+
+	fetcher := r.DrainFetcher
+	if fetcher == nil {
+		fetcher = NewServerValidator(r.Client)
+	}
+	for _, ns := range mcpsr.Status.ConfigNamespaces {
+		status, err := fetcher.DrainStatus(ctx, ns, mcpsr.Status.ServerID)
+		if err != nil {
+			logger.Info("unable to get drain status, checking again", "mcpregistrationname", mcpsr.Name, "namespace", ns, "error", err.Error())
+			return min(drainPollInterval, remaining), nil
+		}
+		if !status.Draining || status.InFlightToolCalls > 0 {
+			logger.Info("waiting for server to drain", "mcpregistrationname", mcpsr.Name, "namespace", ns,
+				"draining", status.Draining, "inFlightToolCalls", status.InFlightToolCalls)
+			return min(drainPollInterval, remaining), nil
+		}
+	}
+	logger.Info("server drained", "mcpregistrationname", mcpsr.Name)
+	return 0, nil
+}
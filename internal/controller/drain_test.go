@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/broker"
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+)
+
+// brokerEndpointSlice points the broker service in namespace at a test server
+func brokerEndpointSlice(t *testing.T, name, namespace, serverURL string) *discoveryv1.EndpointSlice {
+	t.Helper()
+	host, port, err := net.SplitHostPort(serverURL[len("http://"):])
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app.kubernetes.io/name": "mcp-gateway"},
+		},
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses:  []string{host},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)},
+		}},
+		Ports: []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To(int32(portNumber))}},
+	}
+}
+
+func TestServerValidator_DrainStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, discoveryv1.AddToScheme(scheme))
+
+	brokerReporting := func(servers ...upstream.ServerValidationStatus) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(broker.StatusResponse{Servers: servers})
+		}))
+	}
+
+	t.Run("sums calls in flight across brokers", func(t *testing.T) {
+		first := brokerReporting(upstream.ServerValidationStatus{ID: "id", Draining: true, InFlightToolCalls: 1})
+		defer first.Close()
+		second := brokerReporting(
+			upstream.ServerValidationStatus{ID: "id", Draining: true, InFlightToolCalls: 2},
+			upstream.ServerValidationStatus{ID: "other", InFlightToolCalls: 5},
+		)
+		defer second.Close()
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			brokerEndpointSlice(t, "first", "mcp-system", first.URL),
+			brokerEndpointSlice(t, "second", "mcp-system", second.URL),
+		).Build()
+
+		status, err := NewServerValidator(k8sClient).DrainStatus(context.Background(), "mcp-system", "id")
+		require.NoError(t, err)
+		require.Equal(t, ServerDrainStatus{Draining: true, InFlightToolCalls: 3}, status)
+	})
+
+	t.Run("not draining until every broker is", func(t *testing.T) {
+		first := brokerReporting(upstream.ServerValidationStatus{ID: "id", Draining: true})
+		defer first.Close()
+		second := brokerReporting(upstream.ServerValidationStatus{ID: "id"})
+		defer second.Close()
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			brokerEndpointSlice(t, "first", "mcp-system", first.URL),
+			brokerEndpointSlice(t, "second", "mcp-system", second.URL),
+		).Build()
+
+		status, err := NewServerValidator(k8sClient).DrainStatus(context.Background(), "mcp-system", "id")
+		require.NoError(t, err)
+		require.False(t, status.Draining)
+	})
+
+	t.Run("nothing to drain without brokers", func(t *testing.T) {
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		status, err := NewServerValidator(k8sClient).DrainStatus(context.Background(), "mcp-system", "id")
+		require.NoError(t, err)
+		require.Equal(t, ServerDrainStatus{Draining: true}, status)
+	})
+}
+
+// recordingConfigWriter records the servers marked draining and removed from the config
+type recordingConfigWriter struct {
+	draining []string
+	removed  []string
+}
+
+func (w *recordingConfigWriter) UpsertMCPServer(_ context.Context, _ config.MCPServer, _ types.NamespacedName) error {
+	return nil
+}
+
+func (w *recordingConfigWriter) ReadMCPServer(_ context.Context, _ string, _ types.NamespacedName) (*config.MCPServer, error) {
+	return nil, nil
+}
+
+func (w *recordingConfigWriter) RemoveMCPServer(_ context.Context, serverName string) error {
+	w.removed = append(w.removed, serverName)
+	return nil
+}
+
+func (w *recordingConfigWriter) RemoveMCPServerFromNamespace(_ context.Context, _ string, _ types.NamespacedName) error {
+	return nil
+}
+
+func (w *recordingConfigWriter) MarkMCPServerDraining(_ context.Context, serverName string, namespaceName types.NamespacedName) error {
+	w.draining = append(w.draining, namespaceName.Namespace+"/"+serverName)
+	return nil
+}
+
+type fakeDrainFetcher struct {
+	status ServerDrainStatus
+}
+
+func (f *fakeDrainFetcher) DrainStatus(_ context.Context, _, _ string) (ServerDrainStatus, error) {
+	return f.status, nil
+}
+
+func TestReconcile_DrainTimeout(t *testing.T) {
+	newReconciler := func(t *testing.T, deletedAgo time.Duration, inFlight int) (*MCPReconciler, *recordingConfigWriter, *mcpv1alpha1.MCPServerRegistration) {
+		t.Helper()
+		scheme := runtime.NewScheme()
+		require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+		mcpsr := &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "weather",
+				Namespace:         "team-a",
+				Finalizers:        []string{mcpGatewayFinalizer},
+				DeletionTimestamp: ptr.To(metav1.NewTime(time.Now().Add(-deletedAgo))),
+			},
+			Spec: mcpv1alpha1.MCPServerRegistrationSpec{DrainTimeout: &metav1.Duration{Duration: time.Minute}},
+			Status: mcpv1alpha1.MCPServerRegistrationStatus{
+				ConfigNamespaces: []string{"mcp-system"},
+				ServerID:         "id",
+			},
+		}
+		writer := &recordingConfigWriter{}
+		r := &MCPReconciler{
+			Client:             fake.NewClientBuilder().WithScheme(scheme).WithObjects(mcpsr).Build(),
+			Scheme:             scheme,
+			ConfigReaderWriter: writer,
+			DrainFetcher:       &fakeDrainFetcher{status: ServerDrainStatus{Draining: true, InFlightToolCalls: inFlight}},
+		}
+		return r, writer, mcpsr
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "weather", Namespace: "team-a"}}
+
+	t.Run("waits for calls in flight then removes the drained server", func(t *testing.T) {
+		r, writer, mcpsr := newReconciler(t, 0, 1)
+		result, err := r.Reconcile(context.Background(), request)
+		require.NoError(t, err)
+		require.Positive(t, result.RequeueAfter)
+		require.Equal(t, []string{"mcp-system/" + mcpServerName(mcpsr)}, writer.draining)
+		require.Empty(t, writer.removed)
+
+		r.DrainFetcher = &fakeDrainFetcher{status: ServerDrainStatus{Draining: true}}
+		result, err = r.Reconcile(context.Background(), request)
+		require.NoError(t, err)
+		require.Zero(t, result.RequeueAfter)
+		require.Equal(t, []string{mcpServerName(mcpsr)}, writer.removed)
+		err = r.Get(context.Background(), client.ObjectKeyFromObject(mcpsr), &mcpv1alpha1.MCPServerRegistration{})
+		require.True(t, apierrors.IsNotFound(err), "registration should be deleted once its finalizer is removed")
+	})
+
+	t.Run("removes the server with calls in flight once the timeout elapses", func(t *testing.T) {
+		r, writer, mcpsr := newReconciler(t, 2*time.Minute, 1)
+		result, err := r.Reconcile(context.Background(), request)
+		require.NoError(t, err)
+		require.Zero(t, result.RequeueAfter)
+		require.Equal(t, []string{mcpServerName(mcpsr)}, writer.removed)
+	})
+}
@@ -1,12 +1,17 @@
 package controller
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	analysisv1alpha1 "istio.io/api/analysis/v1alpha1"
+	istiometav1alpha1 "istio.io/api/meta/v1alpha1"
 	istiov1alpha3 "istio.io/api/networking/v1alpha3"
 	istionetv1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
@@ -130,6 +135,148 @@ func TestManagedLabelsMatch(t *testing.T) {
 	}
 }
 
+func TestBuildEnvoyFilter_MessageTimeout(t *testing.T) {
+	gateway := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "gateway-system"}}
+	listenerConfig := &mcpv1alpha1.ListenerConfig{Port: 8080, Name: "mcp"}
+	tests := []struct {
+		name     string
+		timeout  *metav1.Duration
+		expected string
+	}{
+		{name: "default", expected: "10s"},
+		{name: "override", timeout: &metav1.Duration{Duration: 90 * time.Second}, expected: "90s"},
+		{name: "fractional override", timeout: &metav1.Duration{Duration: 1500 * time.Millisecond}, expected: "1.5s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: "ext", Namespace: "mcp-system"},
+				Spec:       mcpv1alpha1.MCPGatewayExtensionSpec{ExtProcMessageTimeout: tt.timeout},
+			}
+			envoyFilter, err := (&MCPGatewayExtensionReconciler{}).buildEnvoyFilter(mcpExt, gateway, listenerConfig)
+			if err != nil {
+				t.Fatalf("buildEnvoyFilter() error = %v", err)
+			}
+			typedConfig := envoyFilter.Spec.ConfigPatches[0].Patch.Value.Fields["typed_config"].GetStructValue()
+			if got := typedConfig.Fields["message_timeout"].GetStringValue(); got != tt.expected {
+				t.Errorf("message_timeout = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildEnvoyFilter_MutationRules(t *testing.T) {
+	gateway := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "gateway-system"}}
+	listenerConfig := &mcpv1alpha1.ListenerConfig{Port: 8080, Name: "mcp"}
+	tests := []struct {
+		name       string
+		retries    *int32
+		allowEnvoy bool
+	}{
+		{name: "retries disabled"},
+		{name: "retries enabled", retries: ptr.To(int32(2)), allowEnvoy: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: "ext", Namespace: "mcp-system"},
+				Spec:       mcpv1alpha1.MCPGatewayExtensionSpec{ToolCallRetries: tt.retries},
+			}
+			envoyFilter, err := (&MCPGatewayExtensionReconciler{}).buildEnvoyFilter(mcpExt, gateway, listenerConfig)
+			if err != nil {
+				t.Fatalf("buildEnvoyFilter() error = %v", err)
+			}
+			typedConfig := envoyFilter.Spec.ConfigPatches[0].Patch.Value.Fields["typed_config"].GetStructValue()
+			rules := typedConfig.Fields["mutation_rules"].GetStructValue()
+			if !rules.Fields["allow_all_routing"].GetBoolValue() {
+				t.Error("expected allow_all_routing to be set")
+			}
+			if _, got := rules.Fields["allow_envoy"]; got != tt.allowEnvoy {
+				t.Errorf("allow_envoy set = %v, expected %v", got, tt.allowEnvoy)
+			}
+		})
+	}
+}
+
+func TestBuildEnvoyFilter_GRPCSettings(t *testing.T) {
+	gateway := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "gateway-system"}}
+	listenerConfig := &mcpv1alpha1.ListenerConfig{Port: 8080, Name: "mcp"}
+	tests := []struct {
+		name              string
+		grpcConfig        *mcpv1alpha1.ExtProcGRPCConfig
+		maxMessageSize    float64
+		keepaliveInterval string
+		keepaliveTimeout  string
+	}{
+		{name: "default", keepaliveInterval: "30s", keepaliveTimeout: "10s"},
+		{
+			name: "override",
+			grpcConfig: &mcpv1alpha1.ExtProcGRPCConfig{
+				MaxMessageSize:    ptr.To(int32(128 * 1024 * 1024)),
+				KeepaliveInterval: &metav1.Duration{Duration: time.Minute},
+				KeepaliveTimeout:  &metav1.Duration{Duration: 2500 * time.Millisecond},
+			},
+			maxMessageSize:    128 * 1024 * 1024,
+			keepaliveInterval: "60s",
+			keepaliveTimeout:  "2.5s",
+		},
+		{
+			name:              "partial override",
+			grpcConfig:        &mcpv1alpha1.ExtProcGRPCConfig{MaxMessageSize: ptr.To(int32(8 * 1024 * 1024))},
+			maxMessageSize:    8 * 1024 * 1024,
+			keepaliveInterval: "30s",
+			keepaliveTimeout:  "10s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: "ext", Namespace: "mcp-system"},
+				Spec:       mcpv1alpha1.MCPGatewayExtensionSpec{ExtProcGRPC: tt.grpcConfig},
+			}
+			envoyFilter, err := (&MCPGatewayExtensionReconciler{}).buildEnvoyFilter(mcpExt, gateway, listenerConfig)
+			if err != nil {
+				t.Fatalf("buildEnvoyFilter() error = %v", err)
+			}
+			if len(envoyFilter.Spec.ConfigPatches) != 2 {
+				t.Fatalf("expected 2 config patches, got %d", len(envoyFilter.Spec.ConfigPatches))
+			}
+			typedConfig := envoyFilter.Spec.ConfigPatches[0].Patch.Value.Fields["typed_config"].GetStructValue()
+			envoyGRPC := typedConfig.Fields["grpc_service"].GetStructValue().Fields["envoy_grpc"].GetStructValue()
+			maxReceive, set := envoyGRPC.Fields["max_receive_message_length"]
+			if set != (tt.maxMessageSize > 0) {
+				t.Errorf("max_receive_message_length set = %v, expected only when configured", set)
+			}
+			if got := maxReceive.GetNumberValue(); got != tt.maxMessageSize {
+				t.Errorf("max_receive_message_length = %v, expected %v", got, tt.maxMessageSize)
+			}
+			routerCluster := envoyGRPC.Fields["cluster_name"].GetStringValue()
+
+			clusterPatch := envoyFilter.Spec.ConfigPatches[1]
+			if clusterPatch.ApplyTo != istiov1alpha3.EnvoyFilter_CLUSTER || clusterPatch.Patch.Operation != istiov1alpha3.EnvoyFilter_Patch_MERGE {
+				t.Fatalf("expected a cluster merge patch, got %v %v", clusterPatch.ApplyTo, clusterPatch.Patch.Operation)
+			}
+			if got := clusterPatch.Match.GetCluster().GetName(); got != routerCluster {
+				t.Errorf("cluster patch matches %q, expected the ext_proc cluster %q", got, routerCluster)
+			}
+			keepalive := clusterPatch.Patch.Value.Fields["typed_extension_protocol_options"].GetStructValue().
+				Fields["envoy.extensions.upstreams.http.v3.HttpProtocolOptions"].GetStructValue().
+				Fields["explicit_http_config"].GetStructValue().
+				Fields["http2_protocol_options"].GetStructValue().
+				Fields["connection_keepalive"].GetStructValue()
+			if got := keepalive.Fields["interval"].GetStringValue(); got != tt.keepaliveInterval {
+				t.Errorf("keepalive interval = %q, expected %q", got, tt.keepaliveInterval)
+			}
+			if got := keepalive.Fields["timeout"].GetStringValue(); got != tt.keepaliveTimeout {
+				t.Errorf("keepalive timeout = %q, expected %q", got, tt.keepaliveTimeout)
+			}
+		})
+	}
+}
+
 func TestEnvoyFilterNeedsUpdate(t *testing.T) {
 	baseEnvoyFilter := func() *istionetv1alpha3.EnvoyFilter {
 		return &istionetv1alpha3.EnvoyFilter{
@@ -267,3 +414,103 @@ func TestEnvoyFilterLabels_IstioRevInheritance(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvoyFilterAccepted(t *testing.T) {
+	tests := []struct {
+		name            string
+		generation      int64
+		status          *istiometav1alpha1.IstioStatus
+		expected        bool
+		messageContains string
+	}{
+		{
+			name:       "no status reported",
+			generation: 1,
+			status:     &istiometav1alpha1.IstioStatus{},
+			expected:   true,
+		},
+		{
+			name:       "reconciled condition true",
+			generation: 2,
+			status: &istiometav1alpha1.IstioStatus{
+				ObservedGeneration: 2,
+				Conditions: []*istiometav1alpha1.IstioCondition{
+					{Type: envoyFilterReconciledCondition, Status: "True"},
+				},
+			},
+			expected: true,
+		},
+		{
+			name:       "status for an older generation",
+			generation: 3,
+			status: &istiometav1alpha1.IstioStatus{
+				ObservedGeneration: 2,
+				Conditions: []*istiometav1alpha1.IstioCondition{
+					{Type: envoyFilterReconciledCondition, Status: "True"},
+				},
+			},
+			expected:        false,
+			messageContains: "generation 3",
+		},
+		{
+			name:       "reconciled condition false",
+			generation: 1,
+			status: &istiometav1alpha1.IstioStatus{
+				ObservedGeneration: 1,
+				Conditions: []*istiometav1alpha1.IstioCondition{
+					{Type: envoyFilterReconciledCondition, Status: "False", Reason: "Error", Message: "invalid patch"},
+				},
+			},
+			expected:        false,
+			messageContains: "Error invalid patch",
+		},
+		{
+			name:       "error validation message",
+			generation: 1,
+			status: &istiometav1alpha1.IstioStatus{
+				ObservedGeneration: 1,
+				ValidationMessages: []*analysisv1alpha1.AnalysisMessageBase{
+					{
+						Type:  &analysisv1alpha1.AnalysisMessageBase_Type{Name: "InvalidEnvoyFilter", Code: "IST0157"},
+						Level: analysisv1alpha1.AnalysisMessageBase_ERROR,
+					},
+				},
+			},
+			expected:        false,
+			messageContains: "IST0157 InvalidEnvoyFilter",
+		},
+		{
+			name:       "warning validation message is ignored",
+			generation: 1,
+			status: &istiometav1alpha1.IstioStatus{
+				ObservedGeneration: 1,
+				ValidationMessages: []*analysisv1alpha1.AnalysisMessageBase{
+					{
+						Type:  &analysisv1alpha1.AnalysisMessageBase_Type{Name: "EnvoyFilterUsesRelativeOperation", Code: "IST0151"},
+						Level: analysisv1alpha1.AnalysisMessageBase_WARNING,
+					},
+				},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envoyFilter := &istionetv1alpha3.EnvoyFilter{
+				ObjectMeta: metav1.ObjectMeta{Name: "mcp-ext-proc-default-gateway", Namespace: "gateway-system", Generation: tt.generation},
+			}
+			envoyFilter.Status.ObservedGeneration = tt.status.ObservedGeneration
+			envoyFilter.Status.Conditions = tt.status.Conditions
+			envoyFilter.Status.ValidationMessages = tt.status.ValidationMessages
+
+			accepted, message := envoyFilterAccepted(envoyFilter)
+			if accepted != tt.expected {
+				t.Errorf("envoyFilterAccepted() = %v, expected %v (message %q)", accepted, tt.expected, message)
+			}
+			if !strings.Contains(message, tt.messageContains) {
+				t.Errorf("envoyFilterAccepted() message = %q, expected it to contain %q", message, tt.messageContains)
+			}
+		})
+	}
+}
@@ -3,14 +3,24 @@ package controller
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"slices"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // HTTPRouteWrapper provides helper methods for inspecting HTTPRoute resources
 type HTTPRouteWrapper struct {
 	*gatewayv1.HTTPRoute
+	// selected is the backend reference serving the MCP server. The first backend reference is used until one is selected
+	selected *gatewayv1.HTTPBackendRef
+	// selectedRule is the index of the rule the selected backend reference is routed by
+	selectedRule int
+	// hostname is the hostname tool calls are routed with. The first hostname is used until one is selected
+	hostname string
 }
 
 // WrapHTTPRoute creates a new HTTPRouteWrapper
@@ -20,15 +30,9 @@ func WrapHTTPRoute(route *gatewayv1.HTTPRoute) *HTTPRouteWrapper {
 
 // Validate checks if the HTTPRoute has a valid structure for MCP processing
 func (w *HTTPRouteWrapper) Validate() error {
-	if len(w.Spec.Rules) == 0 || len(w.Spec.Rules[0].BackendRefs) == 0 {
+	if _, ok := w.firstBackendRef(); !ok {
 		return fmt.Errorf("HTTPRoute %s/%s has no backend references", w.Namespace, w.Name)
 	}
-	if len(w.Spec.Rules) > 1 {
-		return fmt.Errorf("HTTPRoute %s/%s has > 1 rule, which is unsupported", w.Namespace, w.Name)
-	}
-	if len(w.Spec.Rules[0].BackendRefs) > 1 {
-		return fmt.Errorf("HTTPRoute %s/%s has > 1 backend reference, which is unsupported", w.Namespace, w.Name)
-	}
 	if len(w.Spec.Hostnames) == 0 {
 		return fmt.Errorf("HTTPRoute %s/%s must have at least one hostname", w.Namespace, w.Name)
 	}
@@ -38,9 +42,217 @@ func (w *HTTPRouteWrapper) Validate() error {
 	return nil
 }
 
-// BackendRef returns the first backend reference
+// BackendRef returns the selected backend reference, or the first backend reference if none has been selected
 func (w *HTTPRouteWrapper) BackendRef() gatewayv1.HTTPBackendRef {
-	return w.Spec.Rules[0].BackendRefs[0]
+	if w.selected != nil {
+		return *w.selected
+	}
+	ref, _ := w.firstBackendRef()
+	return ref
+}
+
+func (w *HTTPRouteWrapper) firstBackendRef() (gatewayv1.HTTPBackendRef, bool) {
+	if rule := w.firstBackendRule(); rule >= 0 {
+		return w.Spec.Rules[rule].BackendRefs[0], true
+	}
+	return gatewayv1.HTTPBackendRef{}, false
+}
+
+func (w *HTTPRouteWrapper) firstBackendRule() int {
+	for i, rule := range w.Spec.Rules {
+		if len(rule.BackendRefs) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// SelectBackendRef selects the backend reference serving the MCP server. When the route has more than one backend the
+// backend references are narrowed to those named name, if set, and then to those of the rules that most specifically
+// match path, following the gateway's own path match precedence. Multiple rules referencing the same backend are not
+// ambiguous
+func (w *HTTPRouteWrapper) SelectBackendRef(path, name string) error {
+	var candidates []ruleBackendRef
+	for i, rule := range w.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			if name == "" || string(ref.Name) == name {
+				candidates = append(candidates, ruleBackendRef{rule: i, ref: ref})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("%w: HTTPRoute %s/%s has no backendRef named %s", errBackendRefNotFound, w.Namespace, w.Name, name)
+	}
+	if distinctBackendRefs(candidates) > 1 {
+		best := -1
+		var matched []ruleBackendRef
+		for _, candidate := range candidates {
+			score := pathMatchScore(w.Spec.Rules[candidate.rule].Matches, path)
+			switch {
+			case score < 0:
+			case score > best:
+				best = score
+				matched = []ruleBackendRef{candidate}
+			case score == best:
+				matched = append(matched, candidate)
+			}
+		}
+		if len(matched) == 0 {
+			return fmt.Errorf("%w: no rule of HTTPRoute %s/%s matches path %s", errBackendRefNotFound, w.Namespace, w.Name, path)
+		}
+		candidates = matched
+	}
+	if distinctBackendRefs(candidates) > 1 {
+		var rules []int
+		var backends []string
+		for _, candidate := range candidates {
+			if !slices.Contains(rules, candidate.rule) {
+				rules = append(rules, candidate.rule)
+			}
+			if !slices.Contains(backends, string(candidate.ref.Name)) {
+				backends = append(backends, string(candidate.ref.Name))
+			}
+		}
+		return fmt.Errorf("%w: rules %v of HTTPRoute %s/%s match path %s with backendRefs %v, set backendRefName to choose one",
+			errBackendRefAmbiguous, rules, w.Namespace, w.Name, path, backends)
+	}
+	// of the rules referencing the backend the one most specifically matching path routes the MCP traffic
+	selected := candidates[0]
+	best := pathMatchScore(w.Spec.Rules[selected.rule].Matches, path)
+	for _, candidate := range candidates[1:] {
+		if score := pathMatchScore(w.Spec.Rules[candidate.rule].Matches, path); score > best {
+			best, selected = score, candidate
+		}
+	}
+	w.selected = &selected.ref
+	w.selectedRule = selected.rule
+	return nil
+}
+
+// RequestFilters returns the filters applied to requests to the selected backend reference, the filters of its rule
+// followed by those of the backend reference
+func (w *HTTPRouteWrapper) RequestFilters() []gatewayv1.HTTPRouteFilter {
+	rule := w.selectedRule
+	if w.selected == nil {
+		rule = w.firstBackendRule()
+	}
+	if rule < 0 {
+		return nil
+	}
+	return slices.Concat(w.Spec.Rules[rule].Filters, w.BackendRef().Filters)
+}
+
+// RewritePath returns path as the selected backend reference receives it after a URLRewrite path modifier. A prefix
+// replacement replaces the longest prefix of the selected rule matching path
+func (w *HTTPRouteWrapper) RewritePath(path string, modifier *gatewayv1.HTTPPathModifier) string {
+	switch modifier.Type {
+	case gatewayv1.FullPathHTTPPathModifier:
+		if modifier.ReplaceFullPath != nil {
+			return *modifier.ReplaceFullPath
+		}
+	case gatewayv1.PrefixMatchHTTPPathModifier:
+		if modifier.ReplacePrefixMatch == nil {
+			return path
+		}
+		prefix := ""
+		rule := w.selectedRule
+		if w.selected == nil {
+			rule = w.firstBackendRule()
+		}
+		if rule >= 0 {
+			for _, match := range w.Spec.Rules[rule].Matches {
+				if match.Path == nil || match.Path.Value == nil || (match.Path.Type != nil && *match.Path.Type != gatewayv1.PathMatchPathPrefix) {
+					continue
+				}
+				value := strings.TrimSuffix(*match.Path.Value, "/")
+				if (path == value || strings.HasPrefix(path, value+"/")) && len(value) > len(prefix) {
+					prefix = value
+				}
+			}
+		}
+		rewritten := strings.TrimSuffix(*modifier.ReplacePrefixMatch, "/") + strings.TrimPrefix(path, prefix)
+		if rewritten == "" {
+			return "/"
+		}
+		return rewritten
+	}
+	return path
+}
+
+// ReferencesServiceIn checks if any backend reference of the route is a Service in namespace
+func (w *HTTPRouteWrapper) ReferencesServiceIn(namespace string) bool {
+	for _, rule := range w.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			kind := "Service"
+			if ref.Kind != nil {
+				kind = string(*ref.Kind)
+			}
+			refNamespace := w.Namespace
+			if ref.Namespace != nil {
+				refNamespace = string(*ref.Namespace)
+			}
+			if kind == "Service" && refNamespace == namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ruleBackendRef is a backend reference and the index of the rule it belongs to
+type ruleBackendRef struct {
+	rule int
+	ref  gatewayv1.HTTPBackendRef
+}
+
+// distinctBackendRefs counts the distinct backends referenced, ignoring weights and filters
+func distinctBackendRefs(candidates []ruleBackendRef) int {
+	var seen []gatewayv1.BackendObjectReference
+	for _, candidate := range candidates {
+		if !slices.ContainsFunc(seen, func(ref gatewayv1.BackendObjectReference) bool {
+			return equality.Semantic.DeepEqual(ref, candidate.ref.BackendObjectReference)
+		}) {
+			seen = append(seen, candidate.ref.BackendObjectReference)
+		}
+	}
+	return len(seen)
+}
+
+// pathMatchScore ranks how specifically a rule's matches route path. An exact match ranks highest, then the longest
+// matching prefix, then a regular expression. It is -1 when no match routes path. A rule without matches routes every
+// path as a "/" prefix would
+func pathMatchScore(matches []gatewayv1.HTTPRouteMatch, path string) int {
+	if len(matches) == 0 {
+		return 1
+	}
+	score := -1
+	for _, match := range matches {
+		matchType, value := gatewayv1.PathMatchPathPrefix, "/"
+		if match.Path != nil {
+			if match.Path.Type != nil {
+				matchType = *match.Path.Type
+			}
+			if match.Path.Value != nil {
+				value = *match.Path.Value
+			}
+		}
+		switch matchType {
+		case gatewayv1.PathMatchExact:
+			if value == path {
+				score = max(score, math.MaxInt)
+			}
+		case gatewayv1.PathMatchPathPrefix:
+			prefix := strings.TrimSuffix(value, "/")
+			if prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/") {
+				score = max(score, len(prefix)+1)
+			}
+		case gatewayv1.PathMatchRegularExpression:
+			if matched, err := regexp.MatchString("^(?:"+value+")$", path); err == nil && matched {
+				score = max(score, 0)
+			}
+		}
+	}
+	return score
 }
 
 // FirstHostname returns the first hostname
@@ -48,6 +260,26 @@ func (w *HTTPRouteWrapper) FirstHostname() string {
 	return string(w.Spec.Hostnames[0])
 }
 
+// SelectHostname selects the hostname tool calls are routed with. An empty hostname keeps the first hostname
+func (w *HTTPRouteWrapper) SelectHostname(hostname string) error {
+	if hostname == "" {
+		return nil
+	}
+	if !slices.Contains(w.Spec.Hostnames, gatewayv1.Hostname(hostname)) {
+		return fmt.Errorf("%w: HTTPRoute %s/%s has no hostname %s", errHostnameNotFound, w.Namespace, w.Name, hostname)
+	}
+	w.hostname = hostname
+	return nil
+}
+
+// RoutingHostname returns the selected hostname, or the first hostname if none has been selected
+func (w *HTTPRouteWrapper) RoutingHostname() string {
+	if w.hostname != "" {
+		return w.hostname
+	}
+	return w.FirstHostname()
+}
+
 // BackendKind returns the backend kind, defaulting to "Service"
 func (w *HTTPRouteWrapper) BackendKind() string {
 	if w.BackendRef().Kind != nil {
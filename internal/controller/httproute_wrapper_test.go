@@ -1,9 +1,11 @@
 package controller
 
 import (
+	"errors"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
@@ -57,7 +59,7 @@ func TestHTTPRouteWrapper_Validate(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "multiple rules unsupported",
+			name: "multiple rules",
 			route: &gatewayv1.HTTPRoute{
 				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
 				Spec: gatewayv1.HTTPRouteSpec{
@@ -68,7 +70,7 @@ func TestHTTPRouteWrapper_Validate(t *testing.T) {
 					},
 				},
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name: "no hostnames",
@@ -347,3 +349,259 @@ func TestHTTPRouteWrapper_UsesHTTPS(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPRouteWrapper_SelectBackendRef(t *testing.T) {
+	backendRef := func(name string) gatewayv1.HTTPBackendRef {
+		return gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(name)}}}
+	}
+	pathMatch := func(matchType gatewayv1.PathMatchType, value string) []gatewayv1.HTTPRouteMatch {
+		return []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: &matchType, Value: &value}}}
+	}
+	splitRules := []gatewayv1.HTTPRouteRule{
+		{Matches: pathMatch(gatewayv1.PathMatchPathPrefix, "/"), BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("web")}},
+		{Matches: pathMatch(gatewayv1.PathMatchPathPrefix, "/mcp"), BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("mcp-server")}},
+		{Matches: pathMatch(gatewayv1.PathMatchExact, "/v2/mcp"), BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("mcp-server-v2")}},
+		{Matches: pathMatch(gatewayv1.PathMatchPathPrefix, "/api"), BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("api-a"), backendRef("api-b")}},
+	}
+
+	tests := []struct {
+		name           string
+		rules          []gatewayv1.HTTPRouteRule
+		path           string
+		backendRefName string
+		wantBackend    string
+		wantErr        error
+	}{
+		{
+			name:        "single backend needs no selection",
+			rules:       []gatewayv1.HTTPRouteRule{{Matches: pathMatch(gatewayv1.PathMatchPathPrefix, "/other"), BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("mcp-server")}}},
+			path:        "/mcp",
+			wantBackend: "mcp-server",
+		},
+		{
+			name:        "longest prefix matching the path",
+			rules:       splitRules,
+			path:        "/mcp",
+			wantBackend: "mcp-server",
+		},
+		{
+			name:        "exact match takes precedence",
+			rules:       splitRules,
+			path:        "/v2/mcp",
+			wantBackend: "mcp-server-v2",
+		},
+		{
+			name:           "backend selected by name",
+			rules:          splitRules,
+			path:           "/api/mcp",
+			backendRefName: "api-b",
+			wantBackend:    "api-b",
+		},
+		{
+			name:    "more than one backend matches the path",
+			rules:   splitRules,
+			path:    "/api/mcp",
+			wantErr: errBackendRefAmbiguous,
+		},
+		{
+			name:           "no backend with the name",
+			rules:          splitRules,
+			path:           "/mcp",
+			backendRefName: "missing",
+			wantErr:        errBackendRefNotFound,
+		},
+		{
+			name: "no rule matches the path",
+			rules: []gatewayv1.HTTPRouteRule{
+				{Matches: pathMatch(gatewayv1.PathMatchPathPrefix, "/a"), BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("a")}},
+				{Matches: pathMatch(gatewayv1.PathMatchPathPrefix, "/b"), BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("b")}},
+			},
+			path:    "/mcp",
+			wantErr: errBackendRefNotFound,
+		},
+		{
+			name: "rules sharing a backend are not ambiguous",
+			rules: []gatewayv1.HTTPRouteRule{
+				{Matches: pathMatch(gatewayv1.PathMatchPathPrefix, "/mcp"), BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("mcp-server")}},
+				{Matches: pathMatch(gatewayv1.PathMatchRegularExpression, "/m.*"), BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("mcp-server")}},
+			},
+			path:        "/mcp",
+			wantBackend: "mcp-server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := WrapHTTPRoute(&gatewayv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: gatewayv1.HTTPRouteSpec{
+					Hostnames: []gatewayv1.Hostname{"example.com"},
+					Rules:     tt.rules,
+				},
+			})
+			err := w.SelectBackendRef(tt.path, tt.backendRefName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SelectBackendRef() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && w.BackendName() != tt.wantBackend {
+				t.Errorf("BackendName() = %v, want %v", w.BackendName(), tt.wantBackend)
+			}
+		})
+	}
+}
+
+func TestHTTPRouteWrapper_SelectHostname(t *testing.T) {
+	tests := []struct {
+		name         string
+		hostname     string
+		wantHostname string
+		wantErr      error
+	}{
+		{
+			name:         "first hostname when none is selected",
+			wantHostname: "mcp.svc.cluster.local",
+		},
+		{
+			name:         "selected hostname",
+			hostname:     "mcp.127-0-0-1.sslip.io",
+			wantHostname: "mcp.127-0-0-1.sslip.io",
+		},
+		{
+			name:     "hostname not on the route",
+			hostname: "other.example.com",
+			wantErr:  errHostnameNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := WrapHTTPRoute(&gatewayv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: gatewayv1.HTTPRouteSpec{
+					Hostnames: []gatewayv1.Hostname{"mcp.svc.cluster.local", "mcp.127-0-0-1.sslip.io"},
+				},
+			})
+			err := w.SelectHostname(tt.hostname)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SelectHostname() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && w.RoutingHostname() != tt.wantHostname {
+				t.Errorf("RoutingHostname() = %v, want %v", w.RoutingHostname(), tt.wantHostname)
+			}
+		})
+	}
+}
+
+func TestHTTPRouteWrapper_RequestFilters(t *testing.T) {
+	prefix := gatewayv1.PathMatchPathPrefix
+	headerFilter := func(name string) gatewayv1.HTTPRouteFilter {
+		return gatewayv1.HTTPRouteFilter{
+			Type:                  gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{Set: []gatewayv1.HTTPHeader{{Name: gatewayv1.HTTPHeaderName(name), Value: "true"}}},
+		}
+	}
+	backendRef := gatewayv1.HTTPBackendRef{
+		BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "mcp-server"}},
+		Filters:    []gatewayv1.HTTPRouteFilter{headerFilter("x-backend")},
+	}
+	w := WrapHTTPRoute(&gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			Hostnames: []gatewayv1.Hostname{"example.com"},
+			Rules: []gatewayv1.HTTPRouteRule{
+				{
+					Matches:     []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: &prefix, Value: ptr.To("/")}}},
+					Filters:     []gatewayv1.HTTPRouteFilter{headerFilter("x-root")},
+					BackendRefs: []gatewayv1.HTTPBackendRef{backendRef},
+				},
+				{
+					Matches:     []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: &prefix, Value: ptr.To("/mcp")}}},
+					Filters:     []gatewayv1.HTTPRouteFilter{headerFilter("x-mcp")},
+					BackendRefs: []gatewayv1.HTTPBackendRef{backendRef},
+				},
+			},
+		},
+	})
+
+	// the filters of the first rule are used until a backend is selected
+	filters := w.RequestFilters()
+	if len(filters) != 2 || filters[0].RequestHeaderModifier.Set[0].Name != "x-root" || filters[1].RequestHeaderModifier.Set[0].Name != "x-backend" {
+		t.Fatalf("RequestFilters() = %+v, want the first rule and backend filters", filters)
+	}
+
+	// the rule most specifically matching the path routes the MCP traffic
+	if err := w.SelectBackendRef("/mcp", ""); err != nil {
+		t.Fatalf("SelectBackendRef() error = %v", err)
+	}
+	filters = w.RequestFilters()
+	if len(filters) != 2 || filters[0].RequestHeaderModifier.Set[0].Name != "x-mcp" || filters[1].RequestHeaderModifier.Set[0].Name != "x-backend" {
+		t.Fatalf("RequestFilters() = %+v, want the /mcp rule and backend filters", filters)
+	}
+}
+
+func TestHTTPRouteWrapper_RewritePath(t *testing.T) {
+	prefix := gatewayv1.PathMatchPathPrefix
+	tests := []struct {
+		name     string
+		match    string
+		path     string
+		modifier gatewayv1.HTTPPathModifier
+		want     string
+	}{
+		{
+			name:     "full path",
+			match:    "/mcp",
+			path:     "/mcp",
+			modifier: gatewayv1.HTTPPathModifier{Type: gatewayv1.FullPathHTTPPathModifier, ReplaceFullPath: ptr.To("/v1/mcp")},
+			want:     "/v1/mcp",
+		},
+		{
+			name:     "prefix",
+			match:    "/mcp",
+			path:     "/mcp",
+			modifier: gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/v1/mcp")},
+			want:     "/v1/mcp",
+		},
+		{
+			name:     "prefix keeps the rest of the path",
+			match:    "/tools",
+			path:     "/tools/mcp",
+			modifier: gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/")},
+			want:     "/mcp",
+		},
+		{
+			name:     "prefix replaced with root",
+			match:    "/mcp",
+			path:     "/mcp",
+			modifier: gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/")},
+			want:     "/",
+		},
+		{
+			name:     "root prefix",
+			match:    "/",
+			path:     "/mcp",
+			modifier: gatewayv1.HTTPPathModifier{Type: gatewayv1.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/backend")},
+			want:     "/backend/mcp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := WrapHTTPRoute(&gatewayv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: gatewayv1.HTTPRouteSpec{
+					Hostnames: []gatewayv1.Hostname{"example.com"},
+					Rules: []gatewayv1.HTTPRouteRule{{
+						Matches: []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: &prefix, Value: ptr.To(tt.match)}}},
+						BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{
+							BackendObjectReference: gatewayv1.BackendObjectReference{Name: "mcp-server"},
+						}}},
+					}},
+				},
+			})
+			if got := w.RewritePath(tt.path, &tt.modifier); got != tt.want {
+				t.Errorf("RewritePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
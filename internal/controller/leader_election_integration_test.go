@@ -0,0 +1,54 @@
+//go:build integration
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+var _ = Describe("Leader election", func() {
+	It("should start the manager and acquire the lease", func() {
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme:                        scheme.Scheme,
+			Metrics:                       metricsserver.Options{BindAddress: "0"},
+			LeaderElection:                true,
+			LeaderElectionID:              LeaderElectionID,
+			LeaderElectionNamespace:       "default",
+			LeaderElectionReleaseOnCancel: true,
+			// keep the test fast, the defaults wait up to 15s to acquire the lease
+			LeaseDuration: ptr.To(2 * time.Second),
+			RenewDeadline: ptr.To(time.Second),
+			RetryPeriod:   ptr.To(200 * time.Millisecond),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		mgrCtx, cancelManager := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(mgr.Start(mgrCtx)).To(Succeed())
+		}()
+		DeferCleanup(func() {
+			cancelManager()
+			Eventually(done, testTimeout, testRetryInterval).Should(BeClosed())
+		})
+
+		Eventually(mgr.Elected(), testTimeout, testRetryInterval).Should(BeClosed())
+
+		lease := &coordinationv1.Lease{}
+		Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: LeaderElectionID, Namespace: "default"}, lease)).To(Succeed())
+		Expect(lease.Spec.HolderIdentity).NotTo(BeNil())
+		Expect(*lease.Spec.HolderIdentity).NotTo(BeEmpty())
+	})
+})
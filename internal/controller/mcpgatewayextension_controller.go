@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"maps"
+	"strconv"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -18,16 +20,22 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
 	"github.com/Kuadrant/mcp-gateway/internal/config"
 	"github.com/go-logr/logr"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
+	analysisv1alpha1 "istio.io/api/analysis/v1alpha1"
 	istiov1alpha3 "istio.io/api/networking/v1alpha3"
 	istionetv1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -50,8 +58,19 @@ const (
 	labelExtensionNamespace = "mcp.kuadrant.io/extension-namespace"
 	// used to ensure a specific control plane reconciles this resource based on the gateway value
 	labelIstioRev = "istio.io/rev"
+	// envoyFilterReconciledCondition is the istio status condition reporting whether the filter was applied
+	envoyFilterReconciledCondition = "Reconciled"
+	// defaultExtProcMessageTimeout is how long envoy waits on the router for each message when not overridden
+	defaultExtProcMessageTimeout = "10s"
+	// defaultExtProcKeepaliveInterval and defaultExtProcKeepaliveTimeout are the HTTP/2 keepalive of envoy's
+	// connection to the router when not overridden
+	defaultExtProcKeepaliveInterval = 30 * time.Second
+	defaultExtProcKeepaliveTimeout  = 10 * time.Second
 )
 
+// LeaderElectionID is the name of the Lease replicas of the controller use to elect a leader
+const LeaderElectionID = "mcp-gateway-controller.kuadrant.io"
+
 func envoyFilterLabels(mcpExt *mcpv1alpha1.MCPGatewayExtension, gateway *gatewayv1.Gateway) map[string]string {
 	// inherit istio.io/rev from gateway, default to "default" if not set
 	istioRev := "default"
@@ -96,10 +115,15 @@ func newValidationError(reason, message string) *validationError {
 // ConfigWriterDeleter writes and deletes config
 type ConfigWriterDeleter interface {
 	DeleteConfig(ctx context.Context, namespaceName types.NamespacedName) error
-	EnsureConfigExists(ctx context.Context, namespaceName types.NamespacedName) error
+	EnsureConfigExists(ctx context.Context, namespaceName types.NamespacedName, owner client.Object) error
 	WriteEmptyConfig(ctx context.Context, namespaceName types.NamespacedName) error
 }
 
+// BrokerVersionFetcher fetches the build version of the running broker-router
+type BrokerVersionFetcher interface {
+	BrokerVersion(ctx context.Context, namespace string) (string, error)
+}
+
 // MCPGatewayExtensionReconciler reconciles a MCPGatewayExtension object
 type MCPGatewayExtensionReconciler struct {
 	client.Client
@@ -109,6 +133,14 @@ type MCPGatewayExtensionReconciler struct {
 	ConfigWriterDeleter   ConfigWriterDeleter
 	MCPExtFinderValidator MCPGatewayExtensionFinderValidator
 	BrokerRouterImage     string
+	BrokerVersionFetcher  BrokerVersionFetcher
+	ReconcileTiming       *ReconcileTiming
+	// ExtensionDeleted receives each MCPGatewayExtension once its deletion cleanup is done, so the
+	// MCPServerRegistration controller can enqueue the registrations that wrote config to it. Nil sends nothing
+	ExtensionDeleted chan<- event.GenericEvent
+	// ConfigSecretCache watches the config secrets when they are filtered out of the manager's cache, as they are
+	// with --cache-credential-secrets-only. Nil watches them through the manager's cache
+	ConfigSecretCache cache.Cache
 }
 
 // +kubebuilder:rbac:groups=mcp.kagenti.com,resources=mcpgatewayextensions,verbs=get;list;watch;create;update;patch;delete
@@ -119,13 +151,19 @@ type MCPGatewayExtensionReconciler struct {
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=list;watch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.istio.io,resources=envoyfilters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=backendtlspolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reconciles an MCPGatewayExtension resource. Deploying and configuring a MCP Gateway instance configured to integrate and provide MCP functionality with the targeted gateway
-func (r *MCPGatewayExtensionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *MCPGatewayExtensionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := startSpan(ctx, "MCPGatewayExtension.Reconcile", "MCPGatewayExtension", req.NamespacedName)
+	defer func() { endSpan(span, err) }()
 	mcpExt := &mcpv1alpha1.MCPGatewayExtension{}
 	if err := r.Get(ctx, req.NamespacedName, mcpExt); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
@@ -165,7 +203,24 @@ func (r *MCPGatewayExtensionReconciler) handleDeletion(ctx context.Context, mcpE
 	}
 
 	controllerutil.RemoveFinalizer(mcpExt, mcpGatewayFinalizer)
-	return ctrl.Result{}, r.Update(ctx, mcpExt)
+	if err := r.Update(ctx, mcpExt); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.notifyDeleted(ctx, mcpExt)
+	return ctrl.Result{}, nil
+}
+
+// notifyDeleted sends the deleted extension on ExtensionDeleted. The registrations are enqueued by their extension
+// watch as soon as deletion starts, before the EnvoyFilter and config are cleaned up, so they are enqueued again
+// once the broker is gone for their status to report that no valid extension is configured
+func (r *MCPGatewayExtensionReconciler) notifyDeleted(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) {
+	if r.ExtensionDeleted == nil {
+		return
+	}
+	select {
+	case r.ExtensionDeleted <- event.GenericEvent{Object: mcpExt}:
+	case <-ctx.Done():
+	}
 }
 
 func (r *MCPGatewayExtensionReconciler) ensureFinalizer(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) (bool, error) {
@@ -204,7 +259,14 @@ func (r *MCPGatewayExtensionReconciler) reconcileActive(ctx context.Context, mcp
 		return ctrl.Result{}, err
 	}
 
-	if err := r.ConfigWriterDeleter.EnsureConfigExists(ctx, config.NamespaceName(mcpExt.Namespace)); err != nil {
+	if err := r.ConfigWriterDeleter.EnsureConfigExists(ctx, config.NamespaceName(mcpExt.Namespace), mcpExt); err != nil {
+		// retrying can't take the secret from its controller so report it rather than requeue
+		var alreadyOwned *controllerutil.AlreadyOwnedError
+		if errors.As(err, &alreadyOwned) {
+			message := fmt.Sprintf("config secret %s is controlled by %s %s, remove its controller owner reference or delete it",
+				config.NamespaceName(mcpExt.Namespace), alreadyOwned.Owner.Kind, alreadyOwned.Owner.Name)
+			return ctrl.Result{}, r.updateStatus(ctx, mcpExt, metav1.ConditionFalse, mcpv1alpha1.ConditionReasonConfigSecretConflict, message)
+		}
 		return ctrl.Result{}, err
 	}
 
@@ -216,6 +278,22 @@ func (r *MCPGatewayExtensionReconciler) reconcileActive(ctx context.Context, mcp
 		return ctrl.Result{}, err
 	}
 
+	if err := r.reconcileBrokerTLS(ctx, mcpExt); err != nil {
+		var valErr *validationError
+		if errors.As(err, &valErr) {
+			return ctrl.Result{}, r.updateStatus(ctx, mcpExt, metav1.ConditionFalse, valErr.reason, valErr.message)
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileSessionStore(ctx, mcpExt); err != nil {
+		var valErr *validationError
+		if errors.As(err, &valErr) {
+			return ctrl.Result{}, r.updateStatus(ctx, mcpExt, metav1.ConditionFalse, valErr.reason, valErr.message)
+		}
+		return ctrl.Result{}, err
+	}
+
 	deploymentReady, err := r.reconcileBrokerRouter(ctx, mcpExt, listenerConfig)
 	if err != nil {
 		var valErr *validationError
@@ -226,27 +304,64 @@ func (r *MCPGatewayExtensionReconciler) reconcileActive(ctx context.Context, mcp
 	}
 
 	if !deploymentReady {
-		if err := r.updateStatus(ctx, mcpExt, metav1.ConditionFalse, mcpv1alpha1.ConditionReasonDeploymentNotReady, "broker-router deployment is not ready"); err != nil {
+		reason, message := mcpv1alpha1.ConditionReasonDeploymentNotReady, "broker-router deployment is not ready"
+		if pullFailure, err := r.brokerRouterImagePullFailure(ctx, mcpExt.Namespace); err != nil {
+			r.log.Error("failed to check broker-router pods for image pull failures", "error", err)
+		} else if pullFailure != "" {
+			reason, message = mcpv1alpha1.ConditionReasonImagePullFailed, pullFailure
+		}
+		if err := r.updateStatus(ctx, mcpExt, metav1.ConditionFalse, reason, message); err != nil {
 			return ctrl.Result{}, err
 		}
 		// requeue to check deployment status again since Owns watch doesn't trigger on status-only changes
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
 
-	if err := r.reconcileEnvoyFilter(ctx, mcpExt, targetGateway, listenerConfig); err != nil {
+	envoyFilter, err := r.reconcileEnvoyFilter(ctx, mcpExt, targetGateway, listenerConfig)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// the EnvoyFilter watch triggers a new reconcile once istio reports on the filter
+	if accepted, message := envoyFilterAccepted(envoyFilter); !accepted {
+		return ctrl.Result{}, r.updateStatus(ctx, mcpExt, metav1.ConditionFalse, mcpv1alpha1.ConditionReasonEnvoyFilterNotAccepted, message)
+	}
+
 	// update Gateway listener status to indicate MCP Gateway is configured
 	if err := r.updateGatewayListenerStatus(ctx, mcpExt, targetGateway, listenerConfig); err != nil {
 		r.log.Error("failed to update gateway listener status, will retry", "error", err)
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
 
-	return ctrl.Result{}, r.updateStatus(ctx, mcpExt, metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "successfully verified and configured")
+	if err := r.updateStatus(ctx, mcpExt, metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "successfully verified and configured"); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.updateBrokerVersion(ctx, mcpExt)
 }
 
-func (r *MCPGatewayExtensionReconciler) validateGatewayTarget(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) (*gatewayv1.Gateway, *mcpv1alpha1.ListenerConfig, error) {
+// updateBrokerVersion records the version reported by the running broker-router in the status.
+// failing to reach the broker is not an error as the version is informational
+func (r *MCPGatewayExtensionReconciler) updateBrokerVersion(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) (err error) {
+	ctx, span := startSpan(ctx, "MCPGatewayExtension.brokerVersion", "MCPGatewayExtension", client.ObjectKeyFromObject(mcpExt))
+	defer func() { endSpan(span, err) }()
+	if r.BrokerVersionFetcher == nil {
+		return nil
+	}
+	brokerVersion, err := r.BrokerVersionFetcher.BrokerVersion(ctx, mcpExt.Namespace)
+	if err != nil {
+		r.log.Debug("failed to fetch broker version", "namespace", mcpExt.Namespace, "error", err)
+		return nil
+	}
+	if mcpExt.Status.BrokerVersion == brokerVersion {
+		return nil
+	}
+	mcpExt.Status.BrokerVersion = brokerVersion
+	return r.Status().Update(ctx, mcpExt)
+}
+
+func (r *MCPGatewayExtensionReconciler) validateGatewayTarget(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) (_ *gatewayv1.Gateway, _ *mcpv1alpha1.ListenerConfig, err error) {
+	ctx, span := startSpan(ctx, "MCPGatewayExtension.validateGatewayTarget", "MCPGatewayExtension", client.ObjectKeyFromObject(mcpExt))
+	defer func() { endSpan(span, err) }()
 	targetGateway, err := r.gatewayTarget(ctx, mcpExt.Spec.TargetRef)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -310,9 +425,19 @@ func (r *MCPGatewayExtensionReconciler) validateGatewayTarget(ctx context.Contex
 }
 
 // findListenerConfigByName finds listener configuration by name.
+// The listener must use a protocol the MCP EnvoyFilter can attach to, otherwise the filter would match nothing
 func findListenerConfigByName(gateway *gatewayv1.Gateway, sectionName string) (*mcpv1alpha1.ListenerConfig, error) {
+	if len(gateway.Spec.Listeners) == 0 {
+		return nil, newValidationError(mcpv1alpha1.ConditionReasonNoMatchingListener,
+			fmt.Sprintf("gateway %s/%s has no listeners", gateway.Namespace, gateway.Name))
+	}
 	for _, listener := range gateway.Spec.Listeners {
 		if string(listener.Name) == sectionName {
+			if !listenerSupportsMCP(listener) {
+				return nil, newValidationError(mcpv1alpha1.ConditionReasonNoMatchingListener,
+					fmt.Sprintf("listener %q on gateway %s/%s uses protocol %s, MCP requires HTTP or HTTPS",
+						sectionName, gateway.Namespace, gateway.Name, listener.Protocol))
+			}
 			hostname := ""
 			if listener.Hostname != nil {
 				hostname = string(*listener.Hostname)
@@ -329,6 +454,11 @@ func findListenerConfigByName(gateway *gatewayv1.Gateway, sectionName string) (*
 		fmt.Sprintf("listener %q not found on gateway %s/%s", sectionName, gateway.Namespace, gateway.Name))
 }
 
+// listenerSupportsMCP returns true when the listener terminates HTTP so the MCP ext_proc filter can be inserted in its filter chain
+func listenerSupportsMCP(listener gatewayv1.Listener) bool {
+	return listener.Protocol == gatewayv1.HTTPProtocolType || listener.Protocol == gatewayv1.HTTPSProtocolType
+}
+
 // listenerAllowsNamespace checks if the listener's allowedRoutes configuration permits
 // routes from the given namespace. This follows Gateway API semantics:
 // - "All": allows routes from all namespaces
@@ -455,11 +585,12 @@ func (r *MCPGatewayExtensionReconciler) updateStatus(ctx context.Context, mcpExt
 	if existing != nil {
 		existingCopy = *existing
 	}
+	observedGeneration := mcpExt.Status.ObservedGeneration
 
 	mcpExt.SetReadyCondition(status, reason, message)
 	updated := meta.FindStatusCondition(mcpExt.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
 
-	if existing != nil && equality.Semantic.DeepEqual(existingCopy, *updated) {
+	if existing != nil && equality.Semantic.DeepEqual(existingCopy, *updated) && observedGeneration == mcpExt.Status.ObservedGeneration {
 		return nil
 	}
 	return r.Status().Update(ctx, mcpExt)
@@ -699,17 +830,66 @@ func (r *MCPGatewayExtensionReconciler) enqueueMCPGatewayExtForReferenceGrant(ct
 	return requests
 }
 
+// extProcMessageTimeout returns how long envoy waits on the router for each message, 10s unless overridden
+func extProcMessageTimeout(mcpExt *mcpv1alpha1.MCPGatewayExtension) string {
+	if mcpExt.Spec.ExtProcMessageTimeout != nil && mcpExt.Spec.ExtProcMessageTimeout.Duration > 0 {
+		// envoy reads durations as decimal seconds
+		return envoyDuration(mcpExt.Spec.ExtProcMessageTimeout.Duration)
+	}
+	return defaultExtProcMessageTimeout
+}
+
+// envoyDuration formats a duration as the decimal seconds envoy reads
+func envoyDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// extProcGRPCSettings returns the max message size and keepalive of envoy's gRPC connection to the router,
+// using the defaults for the keepalive when not overridden. A zero max message size leaves envoy's default
+func extProcGRPCSettings(mcpExt *mcpv1alpha1.MCPGatewayExtension) (maxMessageSize int32, keepaliveInterval, keepaliveTimeout time.Duration) {
+	keepaliveInterval = defaultExtProcKeepaliveInterval
+	keepaliveTimeout = defaultExtProcKeepaliveTimeout
+	grpcConfig := mcpExt.Spec.ExtProcGRPC
+	if grpcConfig == nil {
+		return maxMessageSize, keepaliveInterval, keepaliveTimeout
+	}
+	if grpcConfig.MaxMessageSize != nil && *grpcConfig.MaxMessageSize > 0 {
+		maxMessageSize = *grpcConfig.MaxMessageSize
+	}
+	if grpcConfig.KeepaliveInterval != nil && grpcConfig.KeepaliveInterval.Duration > 0 {
+		keepaliveInterval = grpcConfig.KeepaliveInterval.Duration
+	}
+	if grpcConfig.KeepaliveTimeout != nil && grpcConfig.KeepaliveTimeout.Duration > 0 {
+		keepaliveTimeout = grpcConfig.KeepaliveTimeout.Duration
+	}
+	return maxMessageSize, keepaliveInterval, keepaliveTimeout
+}
+
+// extProcMutationRules returns the header mutations envoy accepts from the router. x-envoy headers are only allowed
+// when tool call retries are enabled, as the router then sets the retry headers on tool calls that are safe to retry
+func extProcMutationRules(mcpExt *mcpv1alpha1.MCPGatewayExtension) map[string]any {
+	rules := map[string]any{"allow_all_routing": true}
+	if mcpExt.Spec.ToolCallRetries != nil && *mcpExt.Spec.ToolCallRetries > 0 {
+		rules["allow_envoy"] = true
+	}
+	return rules
+}
+
 func (r *MCPGatewayExtensionReconciler) buildEnvoyFilter(mcpExt *mcpv1alpha1.MCPGatewayExtension, targetGateway *gatewayv1.Gateway, listenerConfig *mcpv1alpha1.ListenerConfig) (*istionetv1alpha3.EnvoyFilter, error) {
+	routerCluster := fmt.Sprintf("outbound|%d||%s.%s.svc.cluster.local", brokerGRPCPort, brokerRouterName, mcpExt.Namespace)
+	maxMessageSize, keepaliveInterval, keepaliveTimeout := extProcGRPCSettings(mcpExt)
+	envoyGRPC := map[string]any{"cluster_name": routerCluster}
+	if maxMessageSize > 0 {
+		envoyGRPC["max_receive_message_length"] = maxMessageSize
+	}
 	// build the ext_proc filter config as a structpb.Struct
 	extProcConfig, err := structpb.NewStruct(map[string]any{
 		"name": "envoy.filters.http.ext_proc",
 		"typed_config": map[string]any{
 			"@type":              "type.googleapis.com/envoy.extensions.filters.http.ext_proc.v3.ExternalProcessor",
 			"failure_mode_allow": false,
-			"mutation_rules": map[string]any{
-				"allow_all_routing": true,
-			},
-			"message_timeout": "10s",
+			"mutation_rules":     extProcMutationRules(mcpExt),
+			"message_timeout":    extProcMessageTimeout(mcpExt),
 			"processing_mode": map[string]any{
 				"request_header_mode":   "SEND",
 				"response_header_mode":  "SEND",
@@ -719,15 +899,32 @@ func (r *MCPGatewayExtensionReconciler) buildEnvoyFilter(mcpExt *mcpv1alpha1.MCP
 				"response_trailer_mode": "SKIP",
 			},
 			"grpc_service": map[string]any{
-				"envoy_grpc": map[string]any{
-					"cluster_name": fmt.Sprintf("outbound|%d||%s.%s.svc.cluster.local", brokerGRPCPort, brokerRouterName, mcpExt.Namespace),
-				},
+				"envoy_grpc": envoyGRPC,
 			},
 		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ext_proc config struct: %w", err)
 	}
+	// keepalive is set on the cluster of the router as envoy_grpc has no keepalive of its own
+	keepaliveConfig, err := structpb.NewStruct(map[string]any{
+		"typed_extension_protocol_options": map[string]any{
+			"envoy.extensions.upstreams.http.v3.HttpProtocolOptions": map[string]any{
+				"@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
+				"explicit_http_config": map[string]any{
+					"http2_protocol_options": map[string]any{
+						"connection_keepalive": map[string]any{
+							"interval": envoyDuration(keepaliveInterval),
+							"timeout":  envoyDuration(keepaliveTimeout),
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ext_proc keepalive config struct: %w", err)
+	}
 
 	envoyFilterName, _ := envoyFilterNameAndNamespace(mcpExt)
 
@@ -767,15 +964,31 @@ func (r *MCPGatewayExtensionReconciler) buildEnvoyFilter(mcpExt *mcpv1alpha1.MCP
 						Value:     extProcConfig,
 					},
 				},
+				{
+					ApplyTo: istiov1alpha3.EnvoyFilter_CLUSTER,
+					Match: &istiov1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+						Context: istiov1alpha3.EnvoyFilter_GATEWAY,
+						ObjectTypes: &istiov1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_Cluster{
+							Cluster: &istiov1alpha3.EnvoyFilter_ClusterMatch{Name: routerCluster},
+						},
+					},
+					Patch: &istiov1alpha3.EnvoyFilter_Patch{
+						Operation: istiov1alpha3.EnvoyFilter_Patch_MERGE,
+						Value:     keepaliveConfig,
+					},
+				},
 			},
 		},
 	}, nil
 }
 
-func (r *MCPGatewayExtensionReconciler) reconcileEnvoyFilter(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension, targetGateway *gatewayv1.Gateway, listenerConfig *mcpv1alpha1.ListenerConfig) error {
+// reconcileEnvoyFilter creates or updates the EnvoyFilter and returns the resulting object
+func (r *MCPGatewayExtensionReconciler) reconcileEnvoyFilter(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension, targetGateway *gatewayv1.Gateway, listenerConfig *mcpv1alpha1.ListenerConfig) (_ *istionetv1alpha3.EnvoyFilter, err error) {
+	ctx, span := startSpan(ctx, "MCPGatewayExtension.reconcileEnvoyFilter", "MCPGatewayExtension", client.ObjectKeyFromObject(mcpExt))
+	defer func() { endSpan(span, err) }()
 	envoyFilter, err := r.buildEnvoyFilter(mcpExt, targetGateway, listenerConfig)
 	if err != nil {
-		return fmt.Errorf("failed to build envoy filter: %w", err)
+		return nil, fmt.Errorf("failed to build envoy filter: %w", err)
 	}
 
 	existingEnvoyFilter := &istionetv1alpha3.EnvoyFilter{}
@@ -784,16 +997,16 @@ func (r *MCPGatewayExtensionReconciler) reconcileEnvoyFilter(ctx context.Context
 		if apierrors.IsNotFound(err) {
 			r.log.Info("creating envoy filter", "namespace", envoyFilter.Namespace, "name", envoyFilter.Name)
 			if err := r.Create(ctx, envoyFilter); err != nil {
-				return fmt.Errorf("failed to create envoy filter: %w", err)
+				return nil, fmt.Errorf("failed to create envoy filter: %w", err)
 			}
-			return nil
+			return envoyFilter, nil
 		}
-		return fmt.Errorf("failed to get envoy filter: %w", err)
+		return nil, fmt.Errorf("failed to get envoy filter: %w", err)
 	}
 
 	needsUpdate, reason := envoyFilterNeedsUpdate(envoyFilter, existingEnvoyFilter)
 	if !needsUpdate {
-		return nil
+		return existingEnvoyFilter, nil
 	}
 
 	// preserve user labels while ensuring our managed labels are set
@@ -805,7 +1018,35 @@ func (r *MCPGatewayExtensionReconciler) reconcileEnvoyFilter(ctx context.Context
 	envoyFilter.UID = existingEnvoyFilter.UID
 
 	r.log.Info("updating envoy filter", "namespace", envoyFilter.Namespace, "name", envoyFilter.Name, "reason", reason)
-	return r.Update(ctx, envoyFilter)
+	if err := r.Update(ctx, envoyFilter); err != nil {
+		return nil, err
+	}
+	return envoyFilter, nil
+}
+
+// envoyFilterAccepted reports whether istio has accepted the EnvoyFilter, with a message when it has not.
+// istio only writes status when status or analysis reporting is enabled, so a filter without status is
+// treated as accepted
+func envoyFilterAccepted(envoyFilter *istionetv1alpha3.EnvoyFilter) (bool, string) {
+	status := &envoyFilter.Status
+	if status.GetObservedGeneration() != 0 && status.GetObservedGeneration() < envoyFilter.Generation {
+		return false, fmt.Sprintf("waiting for istio to process EnvoyFilter %s/%s generation %d", envoyFilter.Namespace, envoyFilter.Name, envoyFilter.Generation)
+	}
+	var problems []string
+	for _, condition := range status.GetConditions() {
+		if condition.GetType() == envoyFilterReconciledCondition && condition.GetStatus() == string(metav1.ConditionFalse) {
+			problems = append(problems, strings.TrimSpace(condition.GetReason()+" "+condition.GetMessage()))
+		}
+	}
+	for _, msg := range status.GetValidationMessages() {
+		if msg.GetLevel() == analysisv1alpha1.AnalysisMessageBase_ERROR {
+			problems = append(problems, strings.TrimSpace(msg.GetType().GetCode()+" "+msg.GetType().GetName()))
+		}
+	}
+	if len(problems) > 0 {
+		return false, fmt.Sprintf("EnvoyFilter %s/%s not accepted by istio: %s", envoyFilter.Namespace, envoyFilter.Name, strings.Join(problems, "; "))
+	}
+	return true, ""
 }
 
 // envoyFilterNeedsUpdate checks if the EnvoyFilter needs to be updated by comparing specs and managed labels
@@ -832,7 +1073,9 @@ func managedLabelsDiff(existing, desired map[string]string) string {
 	return ""
 }
 
-func (r *MCPGatewayExtensionReconciler) deleteEnvoyFilter(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) error {
+func (r *MCPGatewayExtensionReconciler) deleteEnvoyFilter(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) (err error) {
+	ctx, span := startSpan(ctx, "MCPGatewayExtension.deleteEnvoyFilter", "MCPGatewayExtension", client.ObjectKeyFromObject(mcpExt))
+	defer func() { endSpan(span, err) }()
 	name, namespace := envoyFilterNameAndNamespace(mcpExt)
 	envoyFilter := &istionetv1alpha3.EnvoyFilter{}
 	if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, envoyFilter); err != nil {
@@ -893,8 +1136,8 @@ func (r *MCPGatewayExtensionReconciler) SetupWithManager(ctx context.Context, mg
 	// enqueue mcpgateway extensions when the gateway changes
 	// enqueue when reference grants change
 	// enqueue when envoy filter changes (cross-namespace, so we use Watches instead of Owns)
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&mcpv1alpha1.MCPGatewayExtension{}).
+	controller := ctrl.NewControllerManagedBy(mgr).
+		For(&mcpv1alpha1.MCPGatewayExtension{}, builder.WithPredicates(extensionChangedPredicate())).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.ServiceAccount{}).
@@ -902,6 +1145,21 @@ func (r *MCPGatewayExtensionReconciler) SetupWithManager(ctx context.Context, mg
 		Watches(&gatewayv1.Gateway{}, handler.EnqueueRequestsFromMapFunc(r.enqueueMCPGatewayExtForGateway)).
 		Watches(&gatewayv1beta1.ReferenceGrant{}, handler.EnqueueRequestsFromMapFunc(r.enqueueMCPGatewayExtForReferenceGrant)).
 		Watches(&istionetv1alpha3.EnvoyFilter{}, handler.EnqueueRequestsFromMapFunc(r.enqueueMCPGatewayExtForEnvoyFilter)).
-		Named("mcpgatewayextension").
-		Complete(r)
+		Named("mcpgatewayextension")
+	// restore the config secret if it is deleted while the extension exists
+	if r.ConfigSecretCache != nil {
+		controller = controller.WatchesRawSource(source.Kind[client.Object](r.ConfigSecretCache, &corev1.Secret{},
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &mcpv1alpha1.MCPGatewayExtension{}, handler.OnlyControllerOwner()),
+			configSecretDeletedPredicate()))
+	} else {
+		controller = controller.Owns(&corev1.Secret{}, builder.WithPredicates(configSecretDeletedPredicate()))
+	}
+
+	return controller.Complete(r.ReconcileTiming.Wrap("MCPGatewayExtension", r))
+}
+
+// extensionChangedPredicate passes spec, label and annotation changes. status only updates, such as recording the
+// observed generation, are dropped so the controller's own status writes don't requeue a no-op reconcile
+func extensionChangedPredicate() predicate.Predicate {
+	return predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
 }
@@ -10,14 +10,22 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	analysisv1alpha1 "istio.io/api/analysis/v1alpha1"
 	istionetv1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
@@ -25,6 +33,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
 )
 
 const (
@@ -169,7 +178,7 @@ func (m *mockConfigWriterDeleter) DeleteConfig(ctx context.Context, namespaceNam
 	return nil
 }
 
-func (m *mockConfigWriterDeleter) EnsureConfigExists(ctx context.Context, namespaceName types.NamespacedName) error {
+func (m *mockConfigWriterDeleter) EnsureConfigExists(ctx context.Context, namespaceName types.NamespacedName, owner client.Object) error {
 	return nil
 }
 
@@ -610,6 +619,60 @@ var _ = Describe("MCPGatewayExtension Controller", func() {
 		})
 	})
 
+	Context("When target Gateway has no listener compatible with MCP", func() {
+		const resourceName = "test-no-listener-resource"
+		const gatewayName = "test-no-listener-gateway"
+
+		ctx := context.Background()
+
+		mcpExtNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			// the Gateway API requires at least one listener so use a gateway whose only listener is TCP
+			gateway := createTestGateway(gatewayName, "default")
+			gateway.Spec.Listeners[0].Protocol = gatewayv1.TCPProtocolType
+			Expect(testK8sClient.Create(ctx, gateway)).To(Succeed())
+
+			ext := createTestMCPGatewayExtension(resourceName, "default", gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, ext)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			forceDeleteTestMCPGatewayExtension(ctx, resourceName, "default")
+			deleteTestGateway(ctx, gatewayName, "default")
+		})
+
+		It("should mark MCPGatewayExtension as not ready and not create an EnvoyFilter", func() {
+			reconciler := newTestReconciler()
+			waitForCacheSync(ctx, mcpExtNamespacedName)
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: mcpExtNamespacedName,
+				})
+				g.Expect(err).NotTo(HaveOccurred())
+
+				updated := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, mcpExtNamespacedName, updated)).To(Succeed())
+				condition := meta.FindStatusCondition(updated.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Reason).To(Equal(mcpv1alpha1.ConditionReasonNoMatchingListener))
+				g.Expect(condition.Message).To(ContainSubstring("MCP requires HTTP or HTTPS"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			envoyFilter := &istionetv1alpha3.EnvoyFilter{}
+			err := testK8sClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf("mcp-ext-proc-%s-gateway", "default"),
+				Namespace: "default",
+			}, envoyFilter)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
 	Context("When the target Gateway is deleted", func() {
 		const resourceName = "test-gateway-deleted-resource"
 		const gatewayName = "test-gateway-deleted-gateway"
@@ -819,6 +882,231 @@ var _ = Describe("MCPGatewayExtension Controller", func() {
 			Expect(service.OwnerReferences).To(HaveLen(1))
 			Expect(service.OwnerReferences[0].UID).To(Equal(mcpExt.UID))
 		})
+
+		It("should update the deployment command when only the public host changes", func() {
+			reconciler := newTestReconciler()
+			waitForCacheSync(ctx, mcpExtNamespacedName)
+			deploymentNN := types.NamespacedName{Name: brokerRouterName, Namespace: "default"}
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(testK8sClient.Get(ctx, deploymentNN, &appsv1.Deployment{})).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			mcpExt := &mcpv1alpha1.MCPGatewayExtension{}
+			Expect(testK8sClient.Get(ctx, mcpExtNamespacedName, mcpExt)).To(Succeed())
+			mcpExt.Spec.PublicHost = "mcp.changed.example.com"
+			Expect(testK8sClient.Update(ctx, mcpExt)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				deployment := &appsv1.Deployment{}
+				g.Expect(testK8sClient.Get(ctx, deploymentNN, deployment)).To(Succeed())
+				g.Expect(deployment.Spec.Template.Spec.Containers[0].Command).To(ContainElement("--mcp-gateway-public-host=mcp.changed.example.com"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		It("should report ImagePullFailed when a broker-router pod cannot pull its image", func() {
+			reconciler := newTestReconciler()
+			waitForCacheSync(ctx, mcpExtNamespacedName)
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: brokerRouterName, Namespace: "default"}, &appsv1.Deployment{})).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			// envtest runs no kubelet so simulate the pod it would have created
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "mcp-gateway-pull-failure", Namespace: "default", Labels: brokerRouterLabels()},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "mcp-gateway", Image: DefaultBrokerRouterImage}}},
+			}
+			Expect(testK8sClient.Create(ctx, pod)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(client.IgnoreNotFound(testK8sClient.Delete(ctx, pod))).To(Succeed())
+			})
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  "mcp-gateway",
+				Image: DefaultBrokerRouterImage,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "ImagePullBackOff",
+					Message: "Back-off pulling image",
+				}},
+			}}
+			Expect(testK8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				mcpExt := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, mcpExtNamespacedName, mcpExt)).To(Succeed())
+				condition := meta.FindStatusCondition(mcpExt.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Reason).To(Equal(mcpv1alpha1.ConditionReasonImagePullFailed))
+				g.Expect(condition.Message).To(ContainSubstring(DefaultBrokerRouterImage))
+				g.Expect(condition.Message).To(ContainSubstring("ImagePullBackOff"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When the config secret is deleted", func() {
+		const resourceName = "test-config-secret-resource"
+		const gatewayName = "test-config-secret-gateway"
+		const namespace = "config-secret-ns"
+
+		ctx := context.Background()
+
+		mcpExtNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: namespace,
+		}
+
+		BeforeEach(func() {
+			createTestNamespace(ctx, namespace)
+			gw := createTestGateway(gatewayName, namespace)
+			Expect(testK8sClient.Create(ctx, gw)).To(Succeed())
+			ext := createTestMCPGatewayExtension(resourceName, namespace, gatewayName, namespace)
+			Expect(testK8sClient.Create(ctx, ext)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			forceDeleteTestMCPGatewayExtension(ctx, resourceName, namespace)
+			deleteTestGateway(ctx, gatewayName, namespace)
+			secret := &corev1.Secret{}
+			if err := testK8sClient.Get(ctx, config.NamespaceName(namespace), secret); err == nil {
+				_ = testK8sClient.Delete(ctx, secret)
+			}
+		})
+
+		It("should own the config secret and restore it after deletion", func() {
+			reconciler := newTestReconciler()
+			reconciler.ConfigWriterDeleter = &config.SecretReaderWriter{
+				Client: testK8sClient,
+				Scheme: testK8sClient.Scheme(),
+				Logger: slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
+			waitForCacheSync(ctx, mcpExtNamespacedName)
+
+			mcpExt := &mcpv1alpha1.MCPGatewayExtension{}
+			Expect(testK8sClient.Get(ctx, mcpExtNamespacedName, mcpExt)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				secret := &corev1.Secret{}
+				g.Expect(testK8sClient.Get(ctx, config.NamespaceName(namespace), secret)).To(Succeed())
+				g.Expect(metav1.IsControlledBy(secret, mcpExt)).To(BeTrue())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			secret := &corev1.Secret{}
+			Expect(testK8sClient.Get(ctx, config.NamespaceName(namespace), secret)).To(Succeed())
+			Expect(testK8sClient.Delete(ctx, secret)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				restored := &corev1.Secret{}
+				g.Expect(testK8sClient.Get(ctx, config.NamespaceName(namespace), restored)).To(Succeed())
+				g.Expect(restored.UID).NotTo(Equal(secret.UID))
+				g.Expect(metav1.IsControlledBy(restored, mcpExt)).To(BeTrue())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		It("should restore the config secret when secrets are filtered out of the manager cache", func() {
+			// the manager cache only holds credential secrets, as with --cache-credential-secrets-only
+			secretLabel := labels.SelectorFromSet(labels.Set{CredentialSecretLabel: CredentialSecretValue})
+			mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+				Scheme:     scheme.Scheme,
+				Metrics:    metricsserver.Options{BindAddress: "0"},
+				Controller: ctrlconfig.Controller{SkipNameValidation: ptr.To(true)},
+				Cache:      cache.Options{ByObject: map[client.Object]cache.ByObject{&corev1.Secret{}: {Label: secretLabel}}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			configSecretCache, err := cache.New(cfg, cache.Options{
+				Scheme: scheme.Scheme,
+				Mapper: mgr.GetRESTMapper(),
+				ByObject: map[client.Object]cache.ByObject{
+					&corev1.Secret{}: {Label: labels.SelectorFromSet(labels.Set{config.AggregatedSecretLabel: "true"})},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mgr.Add(configSecretCache)).To(Succeed())
+			Expect((&MCPGatewayExtensionReconciler{
+				Client:          mgr.GetClient(),
+				Scheme:          mgr.GetScheme(),
+				DirectAPIReader: mgr.GetAPIReader(),
+				ConfigWriterDeleter: &config.SecretReaderWriter{
+					Client: mgr.GetClient(),
+					Reader: mgr.GetAPIReader(),
+					Scheme: mgr.GetScheme(),
+					Logger: slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+				},
+				MCPExtFinderValidator: &MCPGatewayExtensionValidator{Client: mgr.GetClient()},
+				BrokerRouterImage:     DefaultBrokerRouterImage,
+				ConfigSecretCache:     configSecretCache,
+			}).SetupWithManager(ctx, mgr)).To(Succeed())
+
+			mgrCtx, cancelManager := context.WithCancel(ctx)
+			DeferCleanup(cancelManager)
+			go func() {
+				defer GinkgoRecover()
+				Expect(mgr.Start(mgrCtx)).To(Succeed())
+			}()
+
+			secret := &corev1.Secret{}
+			Eventually(func(g Gomega) {
+				g.Expect(testK8sClient.Get(ctx, config.NamespaceName(namespace), secret)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+			Expect(testK8sClient.Delete(ctx, secret)).To(Succeed())
+
+			// restored by the delete watch without a manual reconcile
+			Eventually(func(g Gomega) {
+				restored := &corev1.Secret{}
+				g.Expect(testK8sClient.Get(ctx, config.NamespaceName(namespace), restored)).To(Succeed())
+				g.Expect(restored.UID).NotTo(Equal(secret.UID))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		It("should report a config secret controlled by another object without requeuing", func() {
+			reconciler := newTestReconciler()
+			reconciler.ConfigWriterDeleter = &config.SecretReaderWriter{
+				Client: testK8sClient,
+				Scheme: testK8sClient.Scheme(),
+				Logger: slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
+			waitForCacheSync(ctx, mcpExtNamespacedName)
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      config.NamespaceName(namespace).Name,
+					Namespace: namespace,
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "v1",
+						Kind:       "ConfigMap",
+						Name:       "other-owner",
+						UID:        "other-owner-uid",
+						Controller: ptr.To(true),
+					}},
+				},
+			}
+			Expect(testK8sClient.Create(ctx, secret)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result).To(Equal(reconcile.Result{}))
+				mcpExt := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, mcpExtNamespacedName, mcpExt)).To(Succeed())
+				condition := meta.FindStatusCondition(mcpExt.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Reason).To(Equal(mcpv1alpha1.ConditionReasonConfigSecretConflict))
+				g.Expect(condition.Message).To(ContainSubstring("ConfigMap other-owner"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
 	})
 
 	Context("When reconciling EnvoyFilter for cross-namespace Gateway", func() {
@@ -905,6 +1193,85 @@ var _ = Describe("MCPGatewayExtension Controller", func() {
 			}, testTimeout, testRetryInterval).Should(Succeed())
 		})
 
+		It("should not report Ready while istio rejects the EnvoyFilter", func() {
+			reconciler := newTestReconciler()
+			waitForCacheSync(ctx, mcpExtNamespacedName)
+
+			// reconcile until deployment is created
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				deployment := &appsv1.Deployment{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{
+					Name:      brokerRouterName,
+					Namespace: "default",
+				}, deployment)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			// simulate deployment readiness
+			setDeploymentStatus(ctx, "default", 1, 1)
+
+			// reconcile until the extension is ready with the EnvoyFilter in place
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				updated := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, mcpExtNamespacedName, updated)).To(Succeed())
+				condition := meta.FindStatusCondition(updated.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			// simulate istio rejecting the EnvoyFilter
+			envoyFilterKey := types.NamespacedName{
+				Name:      fmt.Sprintf("mcp-ext-proc-%s-gateway", "default"),
+				Namespace: gatewayNamespace,
+			}
+			Eventually(func(g Gomega) {
+				envoyFilter := &istionetv1alpha3.EnvoyFilter{}
+				g.Expect(testK8sClient.Get(ctx, envoyFilterKey, envoyFilter)).To(Succeed())
+				envoyFilter.Status.ObservedGeneration = envoyFilter.Generation
+				envoyFilter.Status.ValidationMessages = []*analysisv1alpha1.AnalysisMessageBase{
+					{
+						Type:  &analysisv1alpha1.AnalysisMessageBase_Type{Name: "InvalidEnvoyFilter", Code: "IST0157"},
+						Level: analysisv1alpha1.AnalysisMessageBase_ERROR,
+					},
+				}
+				g.Expect(testK8sClient.Status().Update(ctx, envoyFilter)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			// reconcile until the rejection is reported
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				updated := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, mcpExtNamespacedName, updated)).To(Succeed())
+				condition := meta.FindStatusCondition(updated.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Reason).To(Equal(mcpv1alpha1.ConditionReasonEnvoyFilterNotAccepted))
+				g.Expect(condition.Message).To(ContainSubstring("IST0157 InvalidEnvoyFilter"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			// once istio accepts the filter the extension becomes ready again
+			Eventually(func(g Gomega) {
+				envoyFilter := &istionetv1alpha3.EnvoyFilter{}
+				g.Expect(testK8sClient.Get(ctx, envoyFilterKey, envoyFilter)).To(Succeed())
+				envoyFilter.Status.ValidationMessages = nil
+				g.Expect(testK8sClient.Status().Update(ctx, envoyFilter)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpExtNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				updated := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, mcpExtNamespacedName, updated)).To(Succeed())
+				condition := meta.FindStatusCondition(updated.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Reason).To(Equal(mcpv1alpha1.ConditionReasonSuccess))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
 		It("should delete EnvoyFilter when MCPGatewayExtension is deleted", func() {
 			reconciler := newTestReconciler()
 			waitForCacheSync(ctx, mcpExtNamespacedName)
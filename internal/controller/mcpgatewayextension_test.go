@@ -1,11 +1,24 @@
 package controller
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"testing"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
@@ -261,3 +274,181 @@ func TestHTTPRouteAttachesToListener(t *testing.T) {
 		})
 	}
 }
+
+type fakeBrokerVersionFetcher struct {
+	version string
+	err     error
+}
+
+func (f *fakeBrokerVersionFetcher) BrokerVersion(_ context.Context, _ string) (string, error) {
+	return f.version, f.err
+}
+
+func TestMCPGatewayExtensionReconciler_updateBrokerVersion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+
+	testCases := []struct {
+		name            string
+		existing        string
+		fetcher         BrokerVersionFetcher
+		expectedVersion string
+	}{
+		{
+			name:            "records the broker version",
+			fetcher:         &fakeBrokerVersionFetcher{version: "v0.5.0 (abc1234)"},
+			expectedVersion: "v0.5.0 (abc1234)",
+		},
+		{
+			name:            "replaces the version after a rollout",
+			existing:        "v0.4.0 (0000000)",
+			fetcher:         &fakeBrokerVersionFetcher{version: "v0.5.0 (abc1234)"},
+			expectedVersion: "v0.5.0 (abc1234)",
+		},
+		{
+			name:            "keeps the last known version when the broker is unreachable",
+			existing:        "v0.4.0 (0000000)",
+			fetcher:         &fakeBrokerVersionFetcher{err: fmt.Errorf("no broker endpoints available")},
+			expectedVersion: "v0.4.0 (0000000)",
+		},
+		{
+			name:            "no fetcher configured",
+			existing:        "v0.4.0 (0000000)",
+			expectedVersion: "v0.4.0 (0000000)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ext := &mcpv1alpha1.MCPGatewayExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: "ext", Namespace: "mcp-system"},
+				Status:     mcpv1alpha1.MCPGatewayExtensionStatus{BrokerVersion: tc.existing},
+			}
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ext).WithStatusSubresource(ext).Build()
+			r := &MCPGatewayExtensionReconciler{
+				Client:               k8sClient,
+				BrokerVersionFetcher: tc.fetcher,
+				log:                  slog.Default(),
+			}
+
+			require.NoError(t, r.updateBrokerVersion(context.Background(), ext))
+
+			updated := &mcpv1alpha1.MCPGatewayExtension{}
+			require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ext), updated))
+			require.Equal(t, tc.expectedVersion, updated.Status.BrokerVersion)
+		})
+	}
+}
+
+func TestReconcileBrokerRouter_AlreadyCreatedByPreviousLeader(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+	ext := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: "ext", Namespace: "mcp-system", UID: "ext-uid"},
+	}
+	listenerConfig := &mcpv1alpha1.ListenerConfig{Name: "mcp", Port: 8080, Hostname: "mcp.example.com"}
+
+	// the previous leader creates the broker-router resources
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ext).Build()
+	previousLeader := &MCPGatewayExtensionReconciler{Client: k8sClient, Scheme: scheme, log: slog.Default()}
+	_, err := previousLeader.reconcileBrokerRouter(context.Background(), ext, listenerConfig)
+	require.NoError(t, err)
+
+	// a replica that just became leader may not yet see them in its cache
+	staleCache := interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*mcpv1alpha1.MCPGatewayExtension); ok {
+				return c.Get(ctx, key, obj, opts...)
+			}
+			return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+		},
+	}
+	newLeader := &MCPGatewayExtensionReconciler{Client: interceptor.NewClient(k8sClient, staleCache), Scheme: scheme, log: slog.Default()}
+	ready, err := newLeader.reconcileBrokerRouter(context.Background(), ext, listenerConfig)
+	require.NoError(t, err)
+	require.False(t, ready)
+}
+
+func TestMCPGatewayExtensionReconciler_updateStatus_ObservedGeneration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	ext := &mcpv1alpha1.MCPGatewayExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: "ext", Namespace: "mcp-system", Generation: 1},
+	}
+	writes := 0
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ext).WithStatusSubresource(ext).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				writes++
+				return c.SubResource(subResource).Update(ctx, obj, opts...)
+			},
+		}).Build()
+	r := &MCPGatewayExtensionReconciler{Client: k8sClient, log: slog.Default()}
+	current := func() *mcpv1alpha1.MCPGatewayExtension {
+		fresh := &mcpv1alpha1.MCPGatewayExtension{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ext), fresh))
+		return fresh
+	}
+
+	require.NoError(t, r.updateStatus(context.Background(), current(), metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready"))
+	require.Equal(t, int64(1), current().Status.ObservedGeneration)
+	require.NoError(t, r.updateStatus(context.Background(), current(), metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready"))
+	require.Equal(t, 1, writes)
+
+	// the same condition is written again once the spec has changed
+	changed := current()
+	changed.Generation = 2
+	require.NoError(t, r.updateStatus(context.Background(), changed, metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready"))
+	require.Equal(t, 2, writes)
+	require.Equal(t, int64(2), current().Status.ObservedGeneration)
+}
+
+func TestExtensionChangedPredicate(t *testing.T) {
+	extension := func(generation int64, annotations map[string]string, observedGeneration int64) *mcpv1alpha1.MCPGatewayExtension {
+		return &mcpv1alpha1.MCPGatewayExtension{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test",
+				Namespace:   "default",
+				Generation:  generation,
+				Annotations: annotations,
+			},
+			Status: mcpv1alpha1.MCPGatewayExtensionStatus{ObservedGeneration: observedGeneration},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		old      *mcpv1alpha1.MCPGatewayExtension
+		new      *mcpv1alpha1.MCPGatewayExtension
+		expected bool
+	}{
+		{
+			name:     "status only change",
+			old:      extension(2, nil, 1),
+			new:      extension(2, nil, 2),
+			expected: false,
+		},
+		{
+			name:     "generation changed",
+			old:      extension(1, nil, 1),
+			new:      extension(2, nil, 1),
+			expected: true,
+		},
+		{
+			name:     "annotation changed",
+			old:      extension(1, map[string]string{"example.com/note": "a"}, 1),
+			new:      extension(1, map[string]string{"example.com/note": "b"}, 1),
+			expected: true,
+		},
+	}
+
+	p := extensionChangedPredicate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, p.Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new}))
+		})
+	}
+}
@@ -4,46 +4,140 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
 	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
 	"github.com/Kuadrant/mcp-gateway/internal/config"
+	"go.opentelemetry.io/otel/attribute"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // errServerNotPresent indicates the MCP server config has not been loaded by the gateway yet
 var errServerNotPresent = errors.New("mcp server is not present in gateway yet")
 
+// errConfigStale indicates the gateway has loaded an older generation of the MCP server config than was written
+var errConfigStale = fmt.Errorf("%w with the latest config", errServerNotPresent)
+
+// errBackendReferenceNotPermitted indicates a cross-namespace backend reference has no ReferenceGrant allowing it
+var errBackendReferenceNotPermitted = errors.New("cross-namespace backend reference not permitted")
+
+// errCredentialReferenceNotPermitted indicates a cross-namespace credential reference has no ReferenceGrant allowing it
+var errCredentialReferenceNotPermitted = errors.New("cross-namespace credential reference not permitted")
+
+// errBackendRefNotFound indicates no backend reference of the HTTPRoute matches the registration's path and backendRefName
+var errBackendRefNotFound = errors.New("no matching backend reference")
+
+// errBackendRefAmbiguous indicates more than one backend reference of the HTTPRoute matches the registration's path and
+// backendRefName
+var errBackendRefAmbiguous = errors.New("ambiguous backend reference")
+
+// errUnsupportedFilter indicates the HTTPRoute has a filter changing requests to the MCP server that the broker can't follow
+var errUnsupportedFilter = errors.New("unsupported HTTPRoute filter")
+
+// errServerBackoff indicates the MCP server has failed enough status checks in a row to be checked less often
+var errServerBackoff = errors.New("mcp server is failing repeatedly")
+
+// errInvalidPath indicates the registration's path can't be used to build the MCP endpoint
+var errInvalidPath = errors.New("invalid path")
+
+// errInvalidToolName indicates the registration's tool prefix or tool name template produces tool names MCP clients
+// can't address
+var errInvalidToolName = errors.New("invalid tool name")
+
+// errHostnameNotFound indicates the HTTPRoute does not list the hostname selected by the registration
+var errHostnameNotFound = errors.New("hostname not found")
+
+// errStatusDeferred indicates a status change was not written because the status was written recently
+var errStatusDeferred = errors.New("status write deferred")
+
 const (
 
-	// CredentialSecretLabel is the required label for credential secrets
+	// CredentialSecretLabel is the default label key required on credential secrets
 	CredentialSecretLabel = "mcp.kuadrant.io/credential" //nolint:gosec // not a credential, just a label name
-	// CredentialSecretValue is the required value for credential secrets
+	// CredentialSecretValue is the default value of the label required on credential secrets
 	CredentialSecretValue = "true"
 	// HTTPRouteIndex used to find MCPServerRegistrations
 	HTTPRouteIndex = "spec.targetRef.httproute"
+	// ServiceIndex used to find MCPServerRegistrations targeting a Service
+	ServiceIndex = "spec.targetRef.service"
+	// CredentialSecretIndex used to find MCPServerRegistrations referencing a credential Secret
+	CredentialSecretIndex = "spec.credentialRef.secret"
 	// ProgrammedHTTPRouteIndex used to find programmed httproutes
 	ProgrammedHTTPRouteIndex = "status.hasProgrammedCondition"
+	// HTTPRouteBackendServiceIndex used to find the httproutes with a backendRef to a Service
+	HTTPRouteBackendServiceIndex = "spec.rules.backendRefs.service"
+	// AnnotationForceSync triggers a full re-registration and broker validation whenever its value changes. The broker
+	// restarts the server's manager and the consecutive failures of the registration, and any backoff, are reset
+	AnnotationForceSync = "mcp.kagenti.com/force-sync"
+	// defaultMCPPath is the path of the MCP endpoint when the registration doesn't set one
+	defaultMCPPath = "/mcp"
+	// mcpToolNameMaxLength is the longest tool name MCP allows
+	mcpToolNameMaxLength = 128
+	// ReasonBackendRefGrantRequired is reported when a cross-namespace backend Service has no ReferenceGrant allowing it
+	ReasonBackendRefGrantRequired = "BackendRefGrantRequired"
+	// ReasonCredentialRefGrantRequired is reported when a credential Secret in another namespace has no ReferenceGrant
+	// allowing it
+	ReasonCredentialRefGrantRequired = "CredentialRefGrantRequired"
+	// ReasonBackendRefNotFound is reported when no backend reference of the HTTPRoute, or port of the targeted Service,
+	// matches the registration
+	ReasonBackendRefNotFound = "BackendRefNotFound"
+	// ReasonBackendRefAmbiguous is reported when more than one backend reference of the HTTPRoute, or port of the targeted
+	// Service, matches the registration
+	ReasonBackendRefAmbiguous = "BackendRefAmbiguous"
+	// ReasonDisabled is reported when the registration is disabled with spec.enabled
+	ReasonDisabled = "Disabled"
+	// ReasonConfigLoadTimeout is reported when the broker has not loaded the registration's config within the
+	// ConfigLoadTimeout
+	ReasonConfigLoadTimeout = "ConfigLoadTimeout"
+	// ReasonHostnameNotFound is reported when the HTTPRoute does not list the hostname selected by the registration
+	ReasonHostnameNotFound = "HostnameNotFound"
+	// ReasonUnsupportedFilter is reported when the HTTPRoute has a filter changing requests to the MCP server that the
+	// broker can't follow, such as a RequestRedirect
+	ReasonUnsupportedFilter = "UnsupportedFilter"
+	// ReasonInvalidPath is reported when the path of the registration can't be used to build the MCP endpoint
+	ReasonInvalidPath = "InvalidPath"
+	// ReasonInvalidToolName is reported when the tool prefix or tool name template of the registration produces tool
+	// names with characters MCP doesn't allow
+	ReasonInvalidToolName = "InvalidToolName"
+	// ReasonBackoff is reported when the MCP server has failed FailureBackoffThreshold status checks in a row and is
+	// checked every FailureBackoffInterval until it recovers or the spec changes
+	ReasonBackoff = "Backoff"
+	// ReasonNoReadyEndpoints is reported when the Service targeted by the registration has no ready endpoints
+	ReasonNoReadyEndpoints = "NoReadyEndpoints"
+	// ReasonBrokerUnreachable is reported when no broker of the MCPGatewayExtension returned its status, so the
+	// readiness of the registration is unknown
+	ReasonBrokerUnreachable = "BrokerUnreachable"
 )
 
 // ServerInfo holds server information
@@ -55,43 +149,111 @@ type ServerInfo struct {
 	HTTPRouteName      string
 	HTTPRouteNamespace string
 	Credential         string
+	// Protocol is the HTTP protocol of the backend from its Service port appProtocol, such as config.ProtocolH2C
+	Protocol string
+	// Headers are added to requests to the backend by the HTTPRoute filters
+	Headers map[string]string
+	// BrokerEndpoint is the endpoint the broker connects to when the HTTPRoute filters rewrite the URL of requests to
+	// the backend. Endpoint keeps the path and host the route matches so tool calls routed through the gateway match it
+	BrokerEndpoint string
 }
 
 // MCPServerConfigReaderWriter adds and removes MCPServers to the config
 type MCPServerConfigReaderWriter interface {
 	UpsertMCPServer(ctx context.Context, server config.MCPServer, namespaceName types.NamespacedName) error
+	// ReadMCPServer returns the server entry written to the config secret in a namespace, or nil when there is none
+	ReadMCPServer(ctx context.Context, serverName string, namespaceName types.NamespacedName) (*config.MCPServer, error)
 	// RemoveMCPServer removes a server from all config secrets cluster-wide
 	RemoveMCPServer(ctx context.Context, serverName string) error
+	// RemoveMCPServerFromNamespace removes a server from the config secret in a single namespace
+	RemoveMCPServerFromNamespace(ctx context.Context, serverName string, namespaceName types.NamespacedName) error
+	// MarkMCPServerDraining marks a server as draining in the config secret in a single namespace
+	MarkMCPServerDraining(ctx context.Context, serverName string, namespaceName types.NamespacedName) error
 }
 
-// MCPReconciler reconciles both MCPServerRegistration and MCPVirtualServer resources
+// MCPReconciler reconciles MCPServerRegistration resources
 type MCPReconciler struct {
 	client.Client
 	Scheme                *runtime.Scheme
 	DirectAPIReader       client.Reader // uncached reader for fetching secrets
 	ConfigReaderWriter    MCPServerConfigReaderWriter
 	MCPExtFinderValidator MCPGatewayExtensionFinderValidator
+	// StatusFetcher fetches the broker status used to set registration readiness. Defaults to a ServerValidator
+	StatusFetcher ServerStatusFetcher
+	// DrainFetcher fetches the drain status of a deleted registration's server. Defaults to a ServerValidator
+	DrainFetcher BrokerDrainFetcher
+	// ValidationGrace is how long the last known status is kept while broker status requests time out
+	ValidationGrace time.Duration
+	// ConfigLoadTimeout is how long a registration waits for the broker to load its config before it reports
+	// ReasonConfigLoadTimeout. Zero never reports a timeout
+	ConfigLoadTimeout time.Duration
+	// CredentialLabel is the label credential Secrets must carry. The zero value uses DefaultCredentialLabel
+	CredentialLabel CredentialLabel
+	// CredentialSecretSelector further narrows which labeled credential Secrets trigger reconciles. Nil matches all
+	CredentialSecretSelector labels.Selector
+	ReconcileTiming          *ReconcileTiming
+
+	// StatusCoalesceWindow is the minimum time between status writes for a registration that do not change its
+	// readiness, such as tool count changes. Readiness changes are always written immediately. Zero disables coalescing
+	StatusCoalesceWindow time.Duration
+
+	// StatusRefreshInterval is how often a ready registration polls the broker again so its status, such as the tool
+	// count, follows backend changes that are not driven by a resource change. Zero disables the refresh
+	StatusRefreshInterval time.Duration
+
+	// FailureBackoffThreshold is the number of status checks in a row that must find the MCP server failing before
+	// it is checked every FailureBackoffInterval instead. Zero disables the backoff
+	FailureBackoffThreshold int
+
+	// FailureBackoffInterval is how often a registration whose MCP server keeps failing checks the broker again
+	FailureBackoffInterval time.Duration
+
+	// Recorder emits events on registrations such as tool conflicts. Nil disables events
+	Recorder events.EventRecorder
+
+	// ExtensionDeleted receives MCPGatewayExtensions whose deletion cleanup is done, from the MCPGatewayExtension
+	// controller. The registrations that wrote config to the extension are reconciled again. Nil is not watched
+	ExtensionDeleted <-chan event.GenericEvent
+
+	// ConfigSecretCache watches the config secrets when they are filtered out of the manager's cache, as they are
+	// with --cache-credential-secrets-only. Nil watches them through the manager's cache
+	ConfigSecretCache cache.Cache
+
+	// validationTimeouts records when broker status requests first timed out for a registration
+	validationTimeouts sync.Map
+	// statusWrites records when the status of a registration was last written
+	statusWrites sync.Map
+	// toolConflicts records the last tool conflicts the broker reported for a registration
+	toolConflicts sync.Map
+	// configWaits records registrations waiting for the broker to load their config
+	configWaits sync.Map
 }
 
 // +kubebuilder:rbac:groups=mcp.kagenti.com,resources=mcpserverregistrations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=mcp.kagenti.com,resources=mcpserverregistrations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=mcp.kagenti.com,resources=mcpvirtualservers,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
 // +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
-// TODO: consider making targetRef immutable since changing it is not currently handled
-
-// Reconcile reconciles both MCPServerRegistration and MCPVirtualServer resources
-func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+// Reconcile reconciles an MCPServerRegistration resource
+func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (result reconcile.Result, err error) {
+	ctx, span := startSpan(ctx, "MCPServerRegistration.Reconcile", "MCPServerRegistration", req.NamespacedName)
+	defer func() { endSpan(span, err) }()
 	logger := logf.FromContext(ctx).WithValues("resource", "mcpserverregistration")
 	logger.V(1).Info("Reconciling", "mcpregistrationname", req.Name, "namespace", req.Namespace)
 
 	mcpsr := &mcpv1alpha1.MCPServerRegistration{}
 	if err := r.Get(ctx, req.NamespacedName, mcpsr); err != nil {
+		if apierrors.IsNotFound(err) {
+			// a registration deleted without the finalizer never reaches the deletion branch
+			r.forgetRegistration(req.NamespacedName)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 	logger.V(1).Info("found", "mcpregistrationname", mcpsr.Name, "namespace", mcpsr.Namespace)
@@ -99,13 +261,23 @@ func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 	// handle deletion
 	if !mcpsr.DeletionTimestamp.IsZero() {
 		logger.Info("deleting", "mcpregistrationname", mcpsr.Name, "namespace", mcpsr.Namespace)
+		r.forgetRegistration(req.NamespacedName)
 		if controllerutil.ContainsFinalizer(mcpsr, mcpGatewayFinalizer) {
-			if err := r.ConfigReaderWriter.RemoveMCPServer(ctx, mcpServerName(mcpsr)); err != nil {
+			requeueAfter, err := r.drainServer(ctx, mcpsr)
+			if err != nil {
 				return ctrl.Result{}, err
 			}
-			if err := r.updateHTTPRouteStatus(ctx, mcpsr); err != nil {
+			if requeueAfter > 0 {
+				return ctrl.Result{RequeueAfter: requeueAfter}, nil
+			}
+			if err := r.ConfigReaderWriter.RemoveMCPServer(ctx, mcpServerName(mcpsr)); err != nil {
 				return ctrl.Result{}, err
 			}
+			if mcpsr.Spec.TargetRef.Kind == "HTTPRoute" {
+				if err := r.cleanupOrphanedHTTPRoutes(ctx); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
 			controllerutil.RemoveFinalizer(mcpsr, mcpGatewayFinalizer)
 			if err := r.Update(ctx, mcpsr); err != nil {
 				if apierrors.IsConflict(err) {
@@ -134,6 +306,23 @@ func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 	}
 	logger.Info("main reconcile logic starting for", "mcpregistrationname", mcpsr.Name)
 
+	// dependent virtual servers are surfaced whether or not the registration is ready so it can be changed safely
+	if err := r.updateVirtualServerReferences(ctx, mcpsr); err != nil {
+		if apierrors.IsConflict(err) {
+			logger.V(1).Info("conflict err requeuing to retry")
+			return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to update virtual server references %w", err)
+	}
+
+	if mcpsr.Spec.TargetRef.Kind == "Service" {
+		return r.reconcileServiceTarget(ctx, mcpsr)
+	}
+	// the target changed from a Service so the route managed for it is no longer needed
+	if err := r.deleteServiceTargetRoute(ctx, mcpsr); err != nil {
+		return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+	}
+
 	// get the HTTPRoute and gateway(s) this MCPServerRegistration targets
 	targetRoute, err := r.getTargetHTTPRoute(ctx, mcpsr)
 	if err != nil {
@@ -165,6 +354,12 @@ func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 	if len(validGateways) == 0 {
 		err := fmt.Errorf("no valid gateways for httproute")
 		logger.Error(err, "failed to find any valid gateways", "route", targetRoute)
+		if err := r.pruneStaleConfig(ctx, mcpsr, nil); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+		}
 		if err := r.updateStatus(ctx, mcpsr, false, err.Error(), 0); err != nil {
 			if apierrors.IsConflict(err) {
 				// don't log these as they are just noise
@@ -177,10 +372,12 @@ func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 	logger.Info("valid gateways discovered ", "total", len(validGateways), "mcpregistrationname", mcpsr.Name)
 	// check for valid MCPGatewayExtension
 	validNamespaces := []string{}
+	lookupFailed := false
 	for _, vg := range validGateways {
 		mcpGatewayExtensions, err := r.MCPExtFinderValidator.FindValidMCPGatewayExtsForGateway(ctx, vg)
 		if err != nil {
 			logger.Error(err, "failed to find valid mcpgatewayextension ", "gateway", vg, "mcpserverregistration", mcpsr)
+			lookupFailed = true
 			if err := r.updateStatus(ctx, mcpsr, false, err.Error(), 0); err != nil {
 				if apierrors.IsConflict(err) {
 					// don't log these as they are just noise
@@ -189,17 +386,6 @@ func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 				return ctrl.Result{}, fmt.Errorf("reconcile failed: status update failed %w", err)
 			}
 		}
-		if len(mcpGatewayExtensions) == 0 {
-			// this is not an error so we are going to exit
-			if err := r.updateStatus(ctx, mcpsr, false, "no valid mcpgatewayextensions configured", 0); err != nil {
-				if apierrors.IsConflict(err) {
-					// don't log these as they are just noise
-					return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
-				}
-				return ctrl.Result{}, err
-			}
-			return ctrl.Result{}, nil
-		}
 		for _, vext := range mcpGatewayExtensions {
 			// only include extensions whose listener matches the HTTPRoute
 			if !httpRouteAttachesToListener(targetRoute, vg, vext) {
@@ -211,7 +397,128 @@ func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 		}
 	}
 
+	if len(validNamespaces) == 0 {
+		// this is not an error so we are going to exit
+		if !lookupFailed {
+			if err := r.pruneStaleConfig(ctx, mcpsr, nil); err != nil {
+				if apierrors.IsConflict(err) {
+					return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+				}
+				return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+			}
+		}
+		if err := r.updateStatus(ctx, mcpsr, false, "no valid mcpgatewayextensions configured", 0); err != nil {
+			if apierrors.IsConflict(err) {
+				// don't log these as they are just noise
+				return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+			}
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	return r.syncMCPServerConfig(ctx, mcpsr, targetRoute, validNamespaces, lookupFailed)
+}
+
+// reconcileServiceTarget reconciles a registration that targets a Service directly. There is no HTTPRoute to find
+// gateways from so the config is written to the MCPGatewayExtension in the registration's namespace, and tool calls
+// are routed to the Service by an HTTPRoute the controller manages on that extension's listener
+func (r *MCPReconciler) reconcileServiceTarget(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (ctrl.Result, error) {
+	mcpExt, err := r.findServiceTargetExtension(ctx, mcpsr)
+	if err != nil {
+		if err := r.updateStatus(ctx, mcpsr, false, err.Error(), 0); err != nil {
+			if apierrors.IsConflict(err) {
+				// don't log these as they are just noise
+				return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("reconcile failed: status update failed %w", err)
+		}
+		return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+	}
+	if mcpExt == nil {
+		// this is not an error so we are going to exit
+		if err := r.deleteServiceTargetRoute(ctx, mcpsr); err != nil {
+			return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+		}
+		if err := r.pruneStaleConfig(ctx, mcpsr, nil); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+		}
+		if err := r.updateStatus(ctx, mcpsr, false, "no valid mcpgatewayextensions configured", 0); err != nil {
+			if apierrors.IsConflict(err) {
+				// don't log these as they are just noise
+				return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+			}
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	targetRoute, err := r.reconcileServiceTargetRoute(ctx, mcpsr, mcpExt)
+	if reason := backendRefFailureReason(err); reason != "" {
+		return r.withdrawConfig(ctx, mcpsr, reason, err)
+	}
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+		}
+		if err := r.updateStatus(ctx, mcpsr, false, err.Error(), 0); err != nil {
+			if apierrors.IsConflict(err) {
+				// don't log these as they are just noise
+				return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("reconcile failed: status update failed %w", err)
+		}
+		return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+	}
+	return r.syncMCPServerConfig(ctx, mcpsr, targetRoute, []string{mcpExt.Namespace}, false)
+}
+
+// findServiceTargetExtension returns the MCPGatewayExtension that federates a registration targeting a Service, or
+// nil when there is none in the registration's namespace
+func (r *MCPReconciler) findServiceTargetExtension(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (*mcpv1alpha1.MCPGatewayExtension, error) {
+	mcpGatewayExtList := &mcpv1alpha1.MCPGatewayExtensionList{}
+	if err := r.List(ctx, mcpGatewayExtList, client.InNamespace(mcpsr.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list mcpgatewayextensions %w", err)
+	}
+	// the config is per namespace so one extension is enough
+	for i := range mcpGatewayExtList.Items {
+		if mcpGatewayExtList.Items[i].DeletionTimestamp == nil {
+			return &mcpGatewayExtList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// withdrawConfig removes the registration's config until its backend can be resolved and sets the reason on its
+// status. Grant, route and registration changes trigger a reconcile
+func (r *MCPReconciler) withdrawConfig(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration, reason string, cause error) (ctrl.Result, error) {
+	if err := r.pruneStaleConfig(ctx, mcpsr, nil); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+	}
+	if err := r.updateStatusWithReason(ctx, mcpsr, false, reason, cause.Error(), 0); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("reconcile failed: status update failed %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// syncMCPServerConfig writes the registration's config to the valid extension namespaces, prunes it from any others
+// and sets the registration status from the broker
+func (r *MCPReconciler) syncMCPServerConfig(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration, targetRoute *gatewayv1.HTTPRoute, validNamespaces []string, lookupFailed bool) (result ctrl.Result, err error) {
+	ctx, span := startSpan(ctx, "MCPServerRegistration.syncConfig", "MCPServerRegistration", client.ObjectKeyFromObject(mcpsr))
+	defer func() { endSpan(span, err) }()
+	logger := logf.FromContext(ctx).WithValues("resource", "mcpserverregistration")
 	mcpServerconfig, err := r.buildMCPServerConfig(ctx, targetRoute, mcpsr)
+	if reason := backendRefFailureReason(err); reason != "" {
+		return r.withdrawConfig(ctx, mcpsr, reason, err)
+	}
 	if err != nil {
 		if err := r.updateStatus(ctx, mcpsr, false, err.Error(), 0); err != nil {
 			if apierrors.IsConflict(err) {
@@ -222,6 +529,7 @@ func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 		}
 		return reconcile.Result{}, fmt.Errorf("failed to reconcile %s %w", mcpsr.Name, err)
 	}
+	serverID := string(mcpServerconfig.ID())
 	for _, configNs := range validNamespaces {
 		if err := r.ConfigReaderWriter.UpsertMCPServer(ctx, *mcpServerconfig, config.NamespaceName(configNs)); err != nil {
 			if err := r.updateStatus(ctx, mcpsr, false, err.Error(), 0); err != nil {
@@ -234,15 +542,86 @@ func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 			return reconcile.Result{}, fmt.Errorf("failed to reconcile %s %w", mcpsr.Name, err)
 		}
 	}
+	if err := r.recordServerID(ctx, mcpsr, serverID); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+	}
+	// don't prune when an extension lookup failed as its namespace may still be valid
+	if !lookupFailed {
+		if err := r.pruneStaleConfig(ctx, mcpsr, validNamespaces); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+		}
+	}
+
+	if !mcpServerconfig.Enabled {
+		// the broker doesn't serve a disabled server so there is no status to poll for
+		r.stopStatusPolling(client.ObjectKeyFromObject(mcpsr))
+		if setReadyStatus(mcpsr, true, false, ReasonDisabled, "server is disabled, its tools are not served by the gateway", 0) {
+			if err := r.writeStatus(ctx, mcpsr); err != nil {
+				if apierrors.IsConflict(err) {
+					return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+				}
+				return ctrl.Result{}, fmt.Errorf("reconcile failed: status update failed %w", err)
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	hasEndpoints, err := r.targetServiceHasReadyEndpoints(ctx, mcpsr)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("reconcile failed %w", err)
+	}
+	if !hasEndpoints {
+		// the broker can't reach the server until a pod is ready. EndpointSlice changes trigger a reconcile
+		r.stopStatusPolling(client.ObjectKeyFromObject(mcpsr))
+		if setReadyStatus(mcpsr, true, false, ReasonNoReadyEndpoints, "backend has no ready endpoints", 0) {
+			if err := r.writeStatus(ctx, mcpsr); err != nil {
+				if apierrors.IsConflict(err) {
+					return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+				}
+				return ctrl.Result{}, fmt.Errorf("reconcile failed: status update failed %w", err)
+			}
+		}
+		return ctrl.Result{}, nil
+	}
 
 	// Everything is in place now so we will now poll the gateway to check the registration status of the mcpserver
 	// NOTE We loop here but there should only ever be one
 	for _, mcpExtensionNS := range validNamespaces {
-		if err := r.setMCPServerRegistrationStatus(ctx, mcpExtensionNS, mcpsr, string(mcpServerconfig.ID())); err != nil {
+		if err := r.setMCPServerRegistrationStatus(ctx, mcpExtensionNS, mcpsr, serverID); err != nil {
+			if errors.Is(err, errServerBackoff) {
+				logger.Info("server keeps failing, backing off status checks", "mcpserverregistration", mcpsr.Name,
+					"consecutiveFailures", mcpsr.Status.ConsecutiveFailures, "requeueAfter", r.FailureBackoffInterval)
+				r.stopStatusPolling(client.ObjectKeyFromObject(mcpsr))
+				return reconcile.Result{RequeueAfter: r.FailureBackoffInterval}, nil
+			}
 			if errors.Is(err, errServerNotPresent) {
-				logger.V(1).Info("config not loaded in gateway yet. Will retry status check", "mcpserverregistration", mcpsr.Name)
-				// no point hammering the gateway when we know we are waiting for the config to be loaded
-				return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+				// back off with jitter so registrations waiting on the same broker don't poll it together
+				requeueAfter := r.nextConfigWaitRequeue(client.ObjectKeyFromObject(mcpsr), mcpsr.Generation, serverID)
+				logger.V(1).Info("config not loaded in gateway yet. Will retry status check", "mcpserverregistration", mcpsr.Name,
+					"requeueAfter", requeueAfter)
+				return reconcile.Result{RequeueAfter: requeueAfter}, nil
+			}
+			if errors.Is(err, ErrValidationTimeout) {
+				requeueAfter := r.nextConfigWaitRequeue(client.ObjectKeyFromObject(mcpsr), mcpsr.Generation, serverID)
+				logger.V(1).Info("broker status request timed out. Will retry status check", "mcpserverregistration", mcpsr.Name,
+					"requeueAfter", requeueAfter)
+				return reconcile.Result{RequeueAfter: requeueAfter}, nil
+			}
+			if errors.Is(err, ErrBrokerUnreachable) {
+				requeueAfter := r.nextConfigWaitRequeue(client.ObjectKeyFromObject(mcpsr), mcpsr.Generation, serverID)
+				logger.Info("gateway broker unreachable. Will retry status check", "mcpserverregistration", mcpsr.Name,
+					"requeueAfter", requeueAfter, "error", err.Error())
+				return reconcile.Result{RequeueAfter: requeueAfter}, nil
+			}
+			if errors.Is(err, errStatusDeferred) {
+				logger.V(1).Info("status changed recently, deferring write", "mcpserverregistration", mcpsr.Name)
+				return reconcile.Result{RequeueAfter: r.StatusCoalesceWindow}, nil
 			}
 			logger.Error(err, "failed to set mcpserverregistration status", "mcpserverregistration", mcpsr.Name)
 			// TODO: handle persistent failures with specific error types
@@ -250,8 +629,9 @@ func (r *MCPReconciler) Reconcile(ctx context.Context, req reconcile.Request) (r
 		}
 	}
 
-	return reconcile.Result{}, nil
-
+	r.configWaits.Delete(client.ObjectKeyFromObject(mcpsr))
+	// backends can change their tools without any resource changing so keep polling the broker
+	return reconcile.Result{RequeueAfter: r.StatusRefreshInterval}, nil
 }
 
 // TODO: share this format with the broker package
@@ -294,7 +674,13 @@ func (r *MCPReconciler) findValidGatewaysForMCPServer(ctx context.Context, targe
 	return validGateways, nil
 }
 
+// getTargetHTTPRoute returns the HTTPRoute targeted by the registration. Only an HTTPRoute in the registration's
+// namespace can be targeted, so a registration can't publish or write status to a route of another namespace
 func (r *MCPReconciler) getTargetHTTPRoute(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (*gatewayv1.HTTPRoute, error) {
+	if mcpsr.Spec.TargetRef.Namespace != "" && mcpsr.Spec.TargetRef.Namespace != mcpsr.Namespace {
+		return nil, fmt.Errorf("targeted httproute %s/%s is not in the namespace of the registration",
+			mcpsr.Spec.TargetRef.Namespace, mcpsr.Spec.TargetRef.Name)
+	}
 	namespaceName := types.NamespacedName{Namespace: mcpsr.Namespace, Name: mcpsr.Spec.TargetRef.Name}
 	logger := logf.FromContext(ctx).WithValues("method", "getTargetHTTPRoute")
 	logger.V(1).Info("httproute target ", "namespacename ", namespaceName)
@@ -316,43 +702,98 @@ func (r *MCPReconciler) getTargetGatewaysFromParentRef(ctx context.Context, pare
 }
 
 // setMCPServerRegistrationStatus polls the broker to check registration status and updates the MCPServerRegistration status
-func (r *MCPReconciler) setMCPServerRegistrationStatus(ctx context.Context, mcpGatewayExtNS string, mcpsr *mcpv1alpha1.MCPServerRegistration, serverID string) error {
+func (r *MCPReconciler) setMCPServerRegistrationStatus(ctx context.Context, mcpGatewayExtNS string, mcpsr *mcpv1alpha1.MCPServerRegistration, serverID string) (err error) {
+	ctx, span := startSpan(ctx, "MCPServerRegistration.brokerStatus", "MCPServerRegistration", client.ObjectKeyFromObject(mcpsr),
+		attribute.String("mcp.server.id", serverID), attribute.String("mcp.extension.namespace", mcpGatewayExtNS))
+	defer func() { endSpan(span, statusCheckFailure(err)) }()
 	log := logf.FromContext(ctx)
 	log.V(1).Info("setMCPServerRegistrationStatus", "mcpregistrationname", mcpsr.Name, "valid gateway extension namespace", mcpGatewayExtNS)
 
-	validator := NewServerValidator(r.Client)
-	// TODO this currently lists all servers in the extension
-	statusResponse, err := validator.ValidateServers(ctx, mcpGatewayExtNS)
+	fetcher := r.StatusFetcher
+	if fetcher == nil {
+		fetcher = NewServerValidator(r.Client)
+	}
+	key := client.ObjectKeyFromObject(mcpsr)
+	serverStatus, err := fetcher.ValidateServer(ctx, mcpGatewayExtNS, serverID)
+	if errors.Is(err, errServerStatusNotFound) {
+		// the broker answered but has not loaded the server yet
+		serverStatus, err = &upstream.ServerValidationStatus{}, nil
+	}
 	if err != nil {
+		if errors.Is(err, ErrBrokerUnreachable) {
+			// the broker can't load config while it is unreachable, so it gets the full ConfigLoadTimeout once it is back
+			r.restartConfigWait(key)
+		}
+		if errors.Is(err, ErrValidationTimeout) && r.keepLastKnownStatus(key) {
+			// a slow broker doesn't tell us anything about the registration so don't flip readiness yet
+			log.Info("broker status request timed out, keeping last known status", "mcpregistrationname", mcpsr.Name, "error", err.Error())
+			return err
+		}
 		log.Error(err, "Failed to validate server status via broker")
+		if errors.Is(err, ErrBrokerUnreachable) {
+			// the config is written so the registration stays accepted, only its readiness is unknown
+			if setReadyStatus(mcpsr, true, false, ReasonBrokerUnreachable, err.Error(), 0) {
+				if err := r.writeStatus(ctx, mcpsr); err != nil {
+					log.Error(err, "Failed to update status")
+					return err
+				}
+			}
+			return err
+		}
 		ready, message := false, fmt.Sprintf("Validation failed: %v", err)
-		if err := r.updateStatus(ctx, mcpsr, ready, message, 0); err != nil {
+		if err := r.updateAcceptedStatus(ctx, mcpsr, ready, message, 0); err != nil {
 			log.Error(err, "Failed to update status")
 			return err
 		}
 		return err
 	}
 
-	var gatewayServerStatus upstream.ServerValidationStatus
-	for _, sr := range statusResponse.Servers {
-		log.Info("server response ", "id", sr.ID, "controller id ", serverID)
-		if sr.ID == serverID {
-			gatewayServerStatus = sr
-			break
-		}
-	}
+	r.validationTimeouts.Delete(key)
+
+	gatewayServerStatus := *serverStatus
 
 	log.Info("server status ", "mcpregistrationname", mcpsr.Name, "status", gatewayServerStatus)
 	// if there is an id that matches then the gateway is registering the mcp
 	if gatewayServerStatus.ID != "" {
-		if err := r.updateStatus(ctx, mcpsr, gatewayServerStatus.Ready, gatewayServerStatus.Message, gatewayServerStatus.TotalTools); err != nil {
-			log.Error(err, "Failed to update status")
+		r.restartConfigWait(key)
+		r.recordToolConflicts(mcpsr, gatewayServerStatus)
+		written := r.writtenConfigGeneration(ctx, mcpGatewayExtNS, mcpsr)
+		stale := brokerConfigStale(gatewayServerStatus, written)
+		if stale {
+			log.Info("broker has not loaded the latest config", "mcpregistrationname", mcpsr.Name,
+				"written", written, "loaded", gatewayServerStatus.ConfigGeneration)
+			gatewayServerStatus.Message = fmt.Sprintf("%s. Waiting for the broker to load config generation %d, it loaded generation %d at %s",
+				gatewayServerStatus.Message, written, gatewayServerStatus.ConfigGeneration,
+				gatewayServerStatus.ConfigLoaded.Format(time.RFC3339))
+		}
+		failures := consecutiveFailures(mcpsr, gatewayServerStatus, stale)
+		backoff := r.FailureBackoffThreshold > 0 && int(failures) >= r.FailureBackoffThreshold
+		if backoff {
+			gatewayServerStatus.Message = fmt.Sprintf("failed %d consecutive checks, checking every %s. Last failure (%s): %s",
+				failures, r.FailureBackoffInterval, gatewayServerStatus.Reason, gatewayServerStatus.Message)
+			gatewayServerStatus.Reason = ReasonBackoff
+		}
+		if err := r.updateStatusCoalesced(ctx, mcpsr, gatewayServerStatus, failures); err != nil {
+			if !errors.Is(err, errStatusDeferred) {
+				log.Error(err, "Failed to update status")
+				return err
+			}
+			// a server already backing off only changes its count, which is written at the next check
+			if backoff {
+				return errServerBackoff
+			}
 			return err
 		}
 
 		if err := r.updateHTTPRouteStatus(ctx, mcpsr); err != nil {
 			log.Error(err, "Failed to update HTTPRoute status")
 		}
+		if stale {
+			return errConfigStale
+		}
+		if backoff {
+			return errServerBackoff
+		}
 		if !gatewayServerStatus.Ready {
 			return errServerNotPresent
 		}
@@ -360,140 +801,804 @@ func (r *MCPReconciler) setMCPServerRegistrationStatus(ctx context.Context, mcpG
 		return nil
 	}
 	// otherwise it hasn't picked up the config yet
-
-	if err := r.updateStatus(ctx, mcpsr, gatewayServerStatus.Ready, errServerNotPresent.Error(), 0); err != nil {
+	if r.configLoadTimedOut(key, mcpsr.Generation, serverID) {
+		message := fmt.Sprintf("%s after %s", errServerNotPresent, r.ConfigLoadTimeout)
+		if setReadyStatus(mcpsr, true, false, ReasonConfigLoadTimeout, message, 0) {
+			if err := r.writeStatus(ctx, mcpsr); err != nil {
+				return err
+			}
+		}
+		return errServerNotPresent
+	}
+	if err := r.updateAcceptedStatus(ctx, mcpsr, gatewayServerStatus.Ready, errServerNotPresent.Error(), 0); err != nil {
 		return err
 	}
 
 	return errServerNotPresent
 }
 
-func (r *MCPReconciler) buildMCPServerConfig(ctx context.Context, targetRoute *gatewayv1.HTTPRoute, mcpsr *mcpv1alpha1.MCPServerRegistration) (*config.MCPServer, error) {
-	if mcpsr.DeletionTimestamp != nil {
-		// don't add deleting mcpserver
-		return nil, fmt.Errorf("cant generate config for deleting server %s/%s", mcpsr.Namespace, mcpsr.Name)
+// consecutiveFailures returns the number of status checks in a row that found the server failing, including this one.
+// The count restarts when the server is ready, the spec has changed or the force-sync annotation has changed. A check against an older config the broker
+// still has loaded doesn't count, and neither does one of the loaded config before the broker has connected with it
+func consecutiveFailures(mcpsr *mcpv1alpha1.MCPServerRegistration, serverStatus upstream.ServerValidationStatus, stale bool) int32 {
+	if serverStatus.Ready {
+		return 0
 	}
-	serverInfo, err := r.buildServerInfoFromHTTPRoute(ctx, targetRoute, mcpsr.Spec.Path)
-	if err != nil {
-		return nil, err
+	failures := mcpsr.Status.ConsecutiveFailures
+	if mcpsr.Status.ObservedGeneration != mcpsr.Generation || forceSyncPending(mcpsr) {
+		failures = 0
 	}
-
-	serverName := mcpServerName(mcpsr)
-	serverConfig := config.MCPServer{
-		Name:       serverName,
-		URL:        serverInfo.Endpoint,
-		Hostname:   serverInfo.Hostname,
-		ToolPrefix: mcpsr.Spec.ToolPrefix,
-		// TODO implement add to MCPServerRegistration CRD
-		Enabled: true,
+	if stale || !connectedWithLoadedConfig(serverStatus) {
+		return failures
 	}
+	return failures + 1
+}
 
-	// add credential env var if configured
-	if mcpsr.Spec.CredentialRef != nil {
-		secret := &corev1.Secret{}
-		err := r.DirectAPIReader.Get(ctx, types.NamespacedName{
-			Name:      mcpsr.Spec.CredentialRef.Name,
-			Namespace: mcpsr.Namespace,
-		}, secret)
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				return nil, fmt.Errorf("credential secret %s not found", mcpsr.Spec.CredentialRef.Name)
-			}
-			return nil, fmt.Errorf("failed to get credential secret: %w", err)
-		}
-
-		// check for required label
-		if secret.Labels == nil || secret.Labels[CredentialSecretLabel] != CredentialSecretValue {
-			return nil, fmt.Errorf("credential secret %s is missing required label %s=%s",
-				mcpsr.Spec.CredentialRef.Name, CredentialSecretLabel, CredentialSecretValue)
-		}
+// forceSyncPending checks if the force-sync annotation has changed since the registration's status was last written
+func forceSyncPending(mcpsr *mcpv1alpha1.MCPServerRegistration) bool {
+	return mcpsr.Status.ForceSync != mcpsr.Annotations[AnnotationForceSync]
+}
 
-		val, ok := secret.Data[mcpsr.Spec.CredentialRef.Key]
-		if !ok {
-			return nil, fmt.Errorf("credential secret %s missing key %s", mcpsr.Spec.CredentialRef.Name, mcpsr.Spec.CredentialRef.Key)
-		}
-		serverConfig.Credential = string(val)
+// connectedWithLoadedConfig checks if the broker has validated the server since it loaded the server config. Until
+// the initial connect with a new config the status is the one found with the previous config. A broker that does not
+// report when it loaded the config is always connected with it
+func connectedWithLoadedConfig(serverStatus upstream.ServerValidationStatus) bool {
+	return serverStatus.ConfigLoaded.IsZero() || !serverStatus.LastValidated.Before(serverStatus.ConfigLoaded)
+}
 
+// writtenConfigGeneration returns the generation recorded in the server entry of the config secret in a namespace.
+// The entry is only rewritten when its config changes, so this can be older than the registration's generation. The
+// registration's generation is used when the entry can't be read
+func (r *MCPReconciler) writtenConfigGeneration(ctx context.Context, configNS string, mcpsr *mcpv1alpha1.MCPServerRegistration) int64 {
+	if r.ConfigReaderWriter == nil {
+		return mcpsr.Generation
 	}
-	return &serverConfig, nil
+	server, err := r.ConfigReaderWriter.ReadMCPServer(ctx, mcpServerName(mcpsr), config.NamespaceName(configNS))
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "failed to read written config generation", "mcpregistrationname", mcpsr.Name)
+		return mcpsr.Generation
+	}
+	if server == nil || server.Generation == 0 {
+		return mcpsr.Generation
+	}
+	return server.Generation
 }
 
-func (r *MCPReconciler) buildServerInfoFromHTTPRoute(ctx context.Context, httpRoute *gatewayv1.HTTPRoute, path string) (*ServerInfo, error) {
-	route := WrapHTTPRoute(httpRoute)
+// brokerConfigStale checks if the broker has loaded an older generation of the server config than was written.
+// A broker that does not report a generation is never stale
+func brokerConfigStale(serverStatus upstream.ServerValidationStatus, generation int64) bool {
+	return serverStatus.ConfigGeneration != 0 && serverStatus.ConfigGeneration < generation
+}
 
-	if err := route.Validate(); err != nil {
-		return nil, err
+// pruneStaleConfig removes the server config from namespaces it was previously written to that are no longer valid
+// and records the current config namespaces in status
+func (r *MCPReconciler) pruneStaleConfig(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration, validNamespaces []string) error {
+	logger := logf.FromContext(ctx)
+	current := slices.Clone(validNamespaces)
+	slices.Sort(current)
+	current = slices.Compact(current)
+	if len(current) == 0 {
+		// no broker serves the registration so its status is no longer polled
+		r.stopStatusPolling(client.ObjectKeyFromObject(mcpsr))
 	}
-
-	var endpoint, routingHostname string
-
-	if route.IsHostnameBackend() {
-		logf.FromContext(ctx).V(1).Info("processing external service via Hostname backendRef", "host", route.BackendName())
-
-		if !isValidHostname(route.BackendName()) {
-			return nil, fmt.Errorf("invalid hostname in backendRef: %s", route.BackendName())
+	for _, ns := range mcpsr.Status.ConfigNamespaces {
+		if slices.Contains(current, ns) {
+			continue
 		}
-
-		port := "443"
-		if route.BackendPort() != nil {
-			port = fmt.Sprintf("%d", *route.BackendPort())
+		logger.Info("removing stale config", "mcpregistrationname", mcpsr.Name, "namespace", ns)
+		if err := r.ConfigReaderWriter.RemoveMCPServerFromNamespace(ctx, mcpServerName(mcpsr), config.NamespaceName(ns)); err != nil {
+			return fmt.Errorf("failed to remove stale config from namespace %s: %w", ns, err)
 		}
+	}
+	if slices.Equal(mcpsr.Status.ConfigNamespaces, current) {
+		return nil
+	}
+	mcpsr.Status.ConfigNamespaces = current
+	return r.Status().Update(ctx, mcpsr)
+}
 
-		endpoint = fmt.Sprintf("https://%s%s", net.JoinHostPort(route.BackendName(), port), path)
-		routingHostname = route.FirstHostname()
+// recordServerID records the ID of the server written to the broker config. The config entry is keyed by the
+// registration name so writing a new target replaces the previous one
+func (r *MCPReconciler) recordServerID(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration, serverID string) error {
+	if mcpsr.Status.ServerID == serverID {
+		return nil
+	}
+	mcpsr.Status.ServerID = serverID
+	return r.Status().Update(ctx, mcpsr)
+}
 
-	} else if route.IsServiceBackend() {
-		service := &corev1.Service{}
-		if err := r.Get(ctx, types.NamespacedName{
-			Name:      route.BackendName(),
-			Namespace: route.BackendNamespace(),
-		}, service); err != nil {
-			return nil, fmt.Errorf("failed to get service %s: %w", route.BackendName(), err)
-		}
+// updateVirtualServerReferences records the virtual servers that reference the registration's tools in status
+func (r *MCPReconciler) updateVirtualServerReferences(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) error {
+	virtualServers := &mcpv1alpha1.MCPVirtualServerList{}
+	if err := r.List(ctx, virtualServers); err != nil {
+		return err
+	}
+	current := virtualServersReferencingRegistration(mcpsr, virtualServers.Items)
+	if slices.Equal(mcpsr.Status.VirtualServers, current) {
+		return nil
+	}
+	mcpsr.Status.VirtualServers = current
+	return r.Status().Update(ctx, mcpsr)
+}
 
-		endpoint, routingHostname = r.buildServiceEndpoint(route, service, path)
+// virtualServersReferencingRegistration returns the sorted namespace/name of the virtual servers that list the
+// registration in spec.servers or have a tool matching the prefix of the registration's served tool names or one of
+// its tool aliases. A registration without a prefix may serve any tool so every virtual server with tools matches
+func virtualServersReferencingRegistration(mcpsr *mcpv1alpha1.MCPServerRegistration, virtualServers []mcpv1alpha1.MCPVirtualServer) []string {
+	var references []string
+	for _, mcpVS := range virtualServers {
+		if mcpVS.DeletionTimestamp != nil {
+			continue
+		}
+		if virtualServerReferencesRegistration(&mcpVS, mcpsr) {
+			references = append(references, fmt.Sprintf("%s/%s", mcpVS.Namespace, mcpVS.Name))
+		}
+	}
+	slices.Sort(references)
+	return references
+}
 
-	} else {
-		return nil, fmt.Errorf("unsupported backend reference kind: %s", route.BackendKind())
+// virtualServerReferencesRegistration reports whether the virtual server lists the registration in spec.servers, or
+// lists a tool served from it by its prefix or one of its aliases
+func virtualServerReferencesRegistration(mcpVS *mcpv1alpha1.MCPVirtualServer, mcpsr *mcpv1alpha1.MCPServerRegistration) bool {
+	if slices.ContainsFunc(mcpVS.Spec.Servers, func(server mcpv1alpha1.MCPVirtualServerServer) bool {
+		return server.RegistrationName(mcpVS.Namespace) == mcpServerName(mcpsr)
+	}) {
+		return true
 	}
+	prefix := servedToolNamePrefix(mcpsr)
+	return slices.ContainsFunc(mcpVS.Spec.Tools, func(tool string) bool {
+		return toolMatchesPrefix(tool, prefix) || slices.Contains(slices.Collect(maps.Values(mcpsr.Spec.ToolAliases)), tool)
+	})
+}
 
-	return &ServerInfo{
-		Endpoint:           endpoint,
-		Hostname:           routingHostname,
-		HTTPRouteName:      route.Name,
-		HTTPRouteNamespace: route.Namespace,
-		Credential:         "",
-	}, nil
+// stopStatusPolling forgets the broker status waits of a registration whose status is no longer polled, so polling
+// it again starts a fresh config wait and validation grace rather than one that has already elapsed
+func (r *MCPReconciler) stopStatusPolling(key types.NamespacedName) {
+	r.configWaits.Delete(key)
+	r.validationTimeouts.Delete(key)
 }
 
-// buildServiceEndpoint builds the endpoint URL and routing hostname for a Service backend
-func (r *MCPReconciler) buildServiceEndpoint(route *HTTPRouteWrapper, service *corev1.Service, path string) (endpoint, routingHostname string) {
-	isExternal := service.Spec.Type == corev1.ServiceTypeExternalName
+// forgetRegistration drops everything recorded in memory for a deleted registration
+func (r *MCPReconciler) forgetRegistration(key types.NamespacedName) {
+	r.stopStatusPolling(key)
+	r.statusWrites.Delete(key)
+	r.toolConflicts.Delete(key)
+}
 
-	var hostAndPort string
-	if isExternal {
-		hostAndPort = service.Spec.ExternalName
-	} else {
-		hostAndPort = fmt.Sprintf("%s.%s.svc.cluster.local", route.BackendName(), route.BackendNamespace())
+// keepLastKnownStatus reports whether broker status timeouts for a registration are still within the validation grace
+func (r *MCPReconciler) keepLastKnownStatus(key types.NamespacedName) bool {
+	if r.ValidationGrace <= 0 {
+		return false
 	}
+	firstTimeout, _ := r.validationTimeouts.LoadOrStore(key, time.Now())
+	return time.Since(firstTimeout.(time.Time)) < r.ValidationGrace
+}
 
-	if route.BackendPort() != nil {
-		hostAndPort = fmt.Sprintf("%s:%d", hostAndPort, *route.BackendPort())
-	}
+// registrationToolNameTemplate returns the tool name template of the registration with the registration name rendered
+func registrationToolNameTemplate(mcpsr *mcpv1alpha1.MCPServerRegistration) string {
+	return strings.ReplaceAll(mcpsr.Spec.ToolNameTemplate, config.ToolNameServerPlaceholder, mcpsr.Name)
+}
 
-	protocol := r.determineProtocol(route, service, isExternal)
-	endpoint = fmt.Sprintf("%s://%s%s", protocol, hostAndPort, path)
+// validateToolNames checks the tool prefix of the registration, the text its tool name template renders around
+// each upstream tool name and its tool aliases only use characters MCP allows in a tool name
+func validateToolNames(mcpsr *mcpv1alpha1.MCPServerRegistration) error {
+	if err := validateToolPrefix(mcpsr.Spec.ToolPrefix); err != nil {
+		return err
+	}
+	if err := validateToolAliases(mcpsr.Spec.ToolAliases); err != nil {
+		return err
+	}
+	if mcpsr.Spec.ToolNameTemplate == "" {
+		return nil
+	}
+	serverConfig := config.MCPServer{ToolPrefix: mcpsr.Spec.ToolPrefix, ToolNameTemplate: registrationToolNameTemplate(mcpsr)}
+	if c, ok := invalidToolNameChar(serverConfig.ServedToolName("")); ok {
+		return fmt.Errorf("%w: toolNameTemplate %q renders tool names containing %q, only letters, digits, _, - and . are allowed",
+			errInvalidToolName, mcpsr.Spec.ToolNameTemplate, c)
+	}
+	return nil
+}
+
+// validateToolPrefix checks the prefix only uses characters MCP allows in a tool name and leaves room for the
+// upstream tool name, so clients can call every tool served with it
+func validateToolPrefix(prefix string) error {
+	if c, ok := invalidToolNameChar(prefix); ok {
+		return fmt.Errorf("%w: toolPrefix %q contains %q, only letters, digits, _, - and . are allowed", errInvalidToolName, prefix, c)
+	}
+	if len(prefix) >= mcpToolNameMaxLength {
+		return fmt.Errorf("%w: toolPrefix %q must be shorter than %d characters", errInvalidToolName, prefix, mcpToolNameMaxLength)
+	}
+	return nil
+}
+
+// validateToolAliases checks each alias is a valid MCP tool name and that no two upstream tools share an alias, as
+// calls to the alias could only be routed to one of them
+func validateToolAliases(aliases map[string]string) error {
+	aliasedTools := map[string]string{}
+	for _, tool := range slices.Sorted(maps.Keys(aliases)) {
+		alias := aliases[tool]
+		if alias == "" {
+			return fmt.Errorf("%w: toolAliases alias for %q must not be empty", errInvalidToolName, tool)
+		}
+		if c, ok := invalidToolNameChar(alias); ok {
+			return fmt.Errorf("%w: toolAliases alias %q contains %q, only letters, digits, _, - and . are allowed", errInvalidToolName, alias, c)
+		}
+		if len(alias) > mcpToolNameMaxLength {
+			return fmt.Errorf("%w: toolAliases alias %q must be at most %d characters", errInvalidToolName, alias, mcpToolNameMaxLength)
+		}
+		if other, ok := aliasedTools[alias]; ok {
+			return fmt.Errorf("%w: toolAliases alias %q is used for both %q and %q", errInvalidToolName, alias, other, tool)
+		}
+		aliasedTools[alias] = tool
+	}
+	return nil
+}
+
+// invalidToolNameChar returns the first character of name that MCP doesn't allow in a tool name
+func invalidToolNameChar(name string) (rune, bool) {
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' && c != '-' && c != '.' {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// servedToolNamePrefix returns the text every tool served from the registration starts with, from its tool name
+// template or tool prefix
+func servedToolNamePrefix(mcpsr *mcpv1alpha1.MCPServerRegistration) string {
+	serverConfig := config.MCPServer{ToolPrefix: mcpsr.Spec.ToolPrefix, ToolNameTemplate: registrationToolNameTemplate(mcpsr)}
+	return serverConfig.ServedToolNamePrefix()
+}
+
+func (r *MCPReconciler) buildMCPServerConfig(ctx context.Context, targetRoute *gatewayv1.HTTPRoute, mcpsr *mcpv1alpha1.MCPServerRegistration) (*config.MCPServer, error) {
+	if mcpsr.DeletionTimestamp != nil {
+		// don't add deleting mcpserver
+		return nil, fmt.Errorf("cant generate config for deleting server %s/%s", mcpsr.Namespace, mcpsr.Name)
+	}
+	if err := validateToolNames(mcpsr); err != nil {
+		return nil, err
+	}
+	var serverInfo *ServerInfo
+	var err error
+	if mcpsr.Spec.TargetRef.Kind == "Service" {
+		serverInfo, err = r.buildServerInfoFromService(ctx, mcpsr, targetRoute)
+	} else {
+		serverInfo, err = r.buildServerInfoFromHTTPRoute(ctx, targetRoute, mcpsr.Spec.Path, mcpsr.Spec.BackendRefName, mcpsr.Spec.Hostname)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serverName := mcpServerName(mcpsr)
+	serverConfig := config.MCPServer{
+		Name:             serverName,
+		URL:              serverInfo.Endpoint,
+		BrokerURL:        serverInfo.BrokerEndpoint,
+		Hostname:         serverInfo.Hostname,
+		ToolPrefix:       mcpsr.Spec.ToolPrefix,
+		ToolNameTemplate: registrationToolNameTemplate(mcpsr),
+		Enabled:          registrationEnabled(mcpsr),
+		Categories:       mcpsr.Spec.Categories,
+		Priority:         mcpsr.Spec.Priority,
+		ToolAliases:      mcpsr.Spec.ToolAliases,
+		HealthPath:       mcpsr.Spec.HealthPath,
+		Generation:       mcpsr.Generation,
+		Protocol:         serverInfo.Protocol,
+		Headers:          serverInfo.Headers,
+		ForceSync:        mcpsr.Annotations[AnnotationForceSync],
+	}
+	if !mcpsr.CreationTimestamp.IsZero() {
+		serverConfig.CreationTimestamp = mcpsr.CreationTimestamp.Unix()
+	}
+	if mcpsr.Spec.UnavailablePolicy == mcpv1alpha1.UnavailablePolicyKeepTools {
+		serverConfig.UnavailablePolicy = config.UnavailablePolicyKeepTools
+	}
+	if mcpsr.Spec.HealthCheckInterval != nil {
+		serverConfig.HealthCheckInterval = mcpsr.Spec.HealthCheckInterval.Duration.String()
+	}
+	if mcpsr.Spec.CallTimeout != nil {
+		serverConfig.CallTimeout = mcpsr.Spec.CallTimeout.Duration.String()
+	}
+	for _, override := range mcpsr.Spec.ToolOverrides {
+		serverConfig.ToolOverrides = append(serverConfig.ToolOverrides, config.ToolOverride{
+			Name:               override.Name,
+			Deprecated:         override.Deprecated,
+			DeprecationMessage: override.DeprecationMessage,
+			Categories:         override.Categories,
+			Idempotent:         override.Idempotent,
+		})
+	}
+
+	// add credential env var if configured
+	if mcpsr.Spec.CredentialRef != nil {
+		secretNamespace := credentialSecretNamespace(mcpsr)
+		if secretNamespace != mcpsr.Namespace {
+			permitted, err := r.credentialReferencePermitted(ctx, mcpsr)
+			if err != nil {
+				return nil, err
+			}
+			if !permitted {
+				return nil, fmt.Errorf("%w: no ReferenceGrant in %s allows MCPServerRegistration %s/%s to reference Secret %s",
+					errCredentialReferenceNotPermitted, secretNamespace, mcpsr.Namespace, mcpsr.Name, mcpsr.Spec.CredentialRef.Name)
+			}
+		}
+		secret, err := r.getCredentialSecret(ctx, secretNamespace, mcpsr.Spec.CredentialRef.Name, mcpsr.Spec.CredentialRef.Key)
+		if err != nil {
+			return nil, err
+		}
+		serverConfig.Credential = string(secret.Data[mcpsr.Spec.CredentialRef.Key])
+	}
+
+	if mcpsr.Spec.TLS != nil {
+		if !strings.HasPrefix(serverConfig.URL, "https://") {
+			return nil, fmt.Errorf("tls is configured but the MCP server endpoint %s is not https", serverConfig.URL)
+		}
+		tlsConfig, err := r.buildUpstreamTLSConfig(ctx, mcpsr.Namespace, mcpsr.Spec.TLS)
+		if err != nil {
+			return nil, err
+		}
+		serverConfig.TLS = tlsConfig
+	}
+	return &serverConfig, nil
+}
+
+// buildUpstreamTLSConfig reads the CA and client certificate referenced by the registration's tls config
+func (r *MCPReconciler) buildUpstreamTLSConfig(ctx context.Context, namespace string, upstreamTLS *mcpv1alpha1.UpstreamTLS) (*config.TLSConfig, error) {
+	tlsConfig := &config.TLSConfig{InsecureSkipVerify: upstreamTLS.InsecureSkipVerify}
+	if ref := upstreamTLS.CASecretRef; ref != nil {
+		secret, err := r.getTLSSecret(ctx, namespace, ref.Name, ref.Key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.CACert = string(secret.Data[ref.Key])
+	}
+	if ref := upstreamTLS.ClientCertificateSecretRef; ref != nil {
+		secret, err := r.getTLSSecret(ctx, namespace, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, valErr := parseTLSSecretCertificate(secret, ref.Name); valErr != nil {
+			return nil, valErr
+		}
+		tlsConfig.ClientCert = string(secret.Data[corev1.TLSCertKey])
+		tlsConfig.ClientKey = string(secret.Data[corev1.TLSPrivateKeyKey])
+	}
+	// fail here rather than on every connection attempt of the broker
+	if _, err := tlsConfig.Transport(); err != nil {
+		return nil, fmt.Errorf("invalid tls config: %w", err)
+	}
+	return tlsConfig, nil
+}
+
+// getCredentialSecret gets a secret in the namespace and validates it as a credential secret holding the key
+func (r *MCPReconciler) getCredentialSecret(ctx context.Context, namespace, name, key string) (_ *corev1.Secret, err error) {
+	ctx, span := startSpan(ctx, "MCPServerRegistration.getCredentialSecret", "Secret", types.NamespacedName{Namespace: namespace, Name: name})
+	defer func() { endSpan(span, err) }()
+	return r.getLabelledSecret(ctx, "credential", namespace, name, key)
+}
+
+// getTLSSecret gets a secret in the namespace holding the CA or client certificate of the registration's tls config.
+// Like a credential secret it must carry the credential label so the controller sees its changes
+func (r *MCPReconciler) getTLSSecret(ctx context.Context, namespace, name string, keys ...string) (_ *corev1.Secret, err error) {
+	ctx, span := startSpan(ctx, "MCPServerRegistration.getTLSSecret", "Secret", types.NamespacedName{Namespace: namespace, Name: name})
+	defer func() { endSpan(span, err) }()
+	return r.getLabelledSecret(ctx, "tls", namespace, name, keys...)
+}
+
+// getLabelledSecret gets a secret in the namespace and validates it carries the credential label and each key. kind
+// names the secret in errors
+func (r *MCPReconciler) getLabelledSecret(ctx context.Context, kind, namespace, name string, keys ...string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := r.DirectAPIReader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%s secret %s not found", kind, name)
+		}
+		return nil, fmt.Errorf("failed to get %s secret: %w", kind, err)
+	}
+	if err := validateLabelledSecret(secret, kind, r.CredentialLabel, keys...); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// registrationEnabled checks if the registration's server should be served by the gateway
+func registrationEnabled(mcpsr *mcpv1alpha1.MCPServerRegistration) bool {
+	return mcpsr.Spec.Enabled == nil || *mcpsr.Spec.Enabled
+}
+
+// credentialSecretNamespace returns the namespace of the registration's credential secret
+func credentialSecretNamespace(mcpsr *mcpv1alpha1.MCPServerRegistration) string {
+	if mcpsr.Spec.CredentialRef == nil || mcpsr.Spec.CredentialRef.Namespace == "" {
+		return mcpsr.Namespace
+	}
+	return mcpsr.Spec.CredentialRef.Namespace
+}
+
+// credentialReferencePermitted checks for a ReferenceGrant in the secret namespace allowing the registration to
+// reference its credential Secret
+func (r *MCPReconciler) credentialReferencePermitted(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (bool, error) {
+	return hasReferenceGrant(ctx, r.Client,
+		grantReference{Group: mcpv1alpha1.GroupVersion.Group, Kind: "MCPServerRegistration", Namespace: mcpsr.Namespace},
+		grantReference{Group: "", Kind: "Secret", Namespace: credentialSecretNamespace(mcpsr), Name: mcpsr.Spec.CredentialRef.Name})
+}
+
+// validateCredentialSecret checks the credential secret carries the required label and the referenced key
+func validateCredentialSecret(secret *corev1.Secret, key string, label CredentialLabel) error {
+	return validateLabelledSecret(secret, "credential", label, key)
+}
+
+// validateLabelledSecret checks the secret carries the required label and each key. kind names the secret in errors
+func validateLabelledSecret(secret *corev1.Secret, kind string, label CredentialLabel, keys ...string) error {
+	if !label.matches(secret.Labels) {
+		return fmt.Errorf("%s secret %s is missing required label %s", kind, secret.Name, label.orDefault())
+	}
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; !ok {
+			return fmt.Errorf("%s secret %s missing key %s", kind, secret.Name, key)
+		}
+	}
+	return nil
+}
+
+func (r *MCPReconciler) buildServerInfoFromHTTPRoute(ctx context.Context, httpRoute *gatewayv1.HTTPRoute, path, backendRefName, hostname string) (*ServerInfo, error) {
+	path, err := mcpEndpointPath(path)
+	if err != nil {
+		return nil, err
+	}
+	route := WrapHTTPRoute(httpRoute)
+
+	if err := route.Validate(); err != nil {
+		return nil, err
+	}
+	if err := route.SelectBackendRef(path, backendRefName); err != nil {
+		return nil, err
+	}
+	if err := route.SelectHostname(hostname); err != nil {
+		return nil, err
+	}
+	if weight := route.BackendRef().Weight; weight != nil && *weight == 0 {
+		return nil, fmt.Errorf("%w: backendRef %s of HTTPRoute %s/%s has weight 0 so receives no traffic",
+			errBackendRefNotFound, route.BackendName(), route.Namespace, route.Name)
+	}
+	request, err := applyRequestFilters(route, path)
+	if err != nil {
+		return nil, err
+	}
+	// a rewrite can join a prefix ending in a slash to the rest of the path
+	brokerPath := collapseSlashes(request.path)
+
+	var endpoint, brokerEndpoint, routingHostname, protocol string
+
+	if route.IsHostnameBackend() {
+		logf.FromContext(ctx).V(1).Info("processing external service via Hostname backendRef", "host", route.BackendName())
+
+		if !isValidHostname(route.BackendName()) {
+			return nil, fmt.Errorf("invalid hostname in backendRef: %s", route.BackendName())
+		}
+
+		port := "443"
+		if route.BackendPort() != nil {
+			port = fmt.Sprintf("%d", *route.BackendPort())
+		}
+
+		host := route.BackendName()
+		endpoint = fmt.Sprintf("https://%s%s", net.JoinHostPort(host, port), path)
+		if request.hostname != "" {
+			host = request.hostname
+		}
+		brokerEndpoint = fmt.Sprintf("https://%s%s", net.JoinHostPort(host, port), brokerPath)
+		routingHostname = route.RoutingHostname()
+
+	} else if route.IsServiceBackend() {
+		if route.BackendNamespace() != route.Namespace {
+			permitted, err := r.backendReferencePermitted(ctx, route)
+			if err != nil {
+				return nil, err
+			}
+			if !permitted {
+				return nil, fmt.Errorf("%w: no ReferenceGrant in %s allows HTTPRoute %s/%s to reference Service %s",
+					errBackendReferenceNotPermitted, route.BackendNamespace(), route.Namespace, route.Name, route.BackendName())
+			}
+		}
+		service := &corev1.Service{}
+		if err := r.Get(ctx, types.NamespacedName{
+			Name:      route.BackendName(),
+			Namespace: route.BackendNamespace(),
+		}, service); err != nil {
+			return nil, fmt.Errorf("failed to get service %s: %w", route.BackendName(), err)
+		}
+
+		if request.hostname != "" && service.Spec.Type != corev1.ServiceTypeExternalName {
+			return nil, fmt.Errorf("%w: the URLRewrite hostname of HTTPRoute %s/%s is only supported for an ExternalName Service or Hostname backend",
+				errUnsupportedFilter, route.Namespace, route.Name)
+		}
+		endpoint, routingHostname = r.buildServiceEndpoint(route, service, path, "")
+		brokerEndpoint, _ = r.buildServiceEndpoint(route, service, brokerPath, request.hostname)
+		if strings.HasPrefix(endpoint, "http://") {
+			protocol = backendAppProtocol(route, service)
+		}
+
+	} else {
+		return nil, fmt.Errorf("unsupported backend reference kind: %s", route.BackendKind())
+	}
+	if brokerEndpoint == endpoint {
+		brokerEndpoint = ""
+	}
+
+	return &ServerInfo{
+		Endpoint:           endpoint,
+		BrokerEndpoint:     brokerEndpoint,
+		Hostname:           routingHostname,
+		HTTPRouteName:      route.Name,
+		HTTPRouteNamespace: route.Namespace,
+		Credential:         "",
+		Protocol:           protocol,
+		Headers:            request.headers,
+	}, nil
+}
+
+// buildServerInfoFromService builds the server info of a registration targeting a Service from the Service DNS name,
+// or the external name of an ExternalName Service, and the targeted port. Tool calls are routed by targetRoute, the
+// HTTPRoute the controller manages for the Service, so its hostname is the routing hostname
+func (r *MCPReconciler) buildServerInfoFromService(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration, targetRoute *gatewayv1.HTTPRoute) (*ServerInfo, error) {
+	path, err := mcpEndpointPath(mcpsr.Spec.Path)
+	if err != nil {
+		return nil, err
+	}
+	service, err := r.getTargetService(ctx, mcpsr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := targetServicePort(service, mcpsr.Spec.TargetRef.Port)
+	if err != nil {
+		return nil, err
+	}
+	if targetRoute == nil || len(targetRoute.Spec.Hostnames) == 0 {
+		return nil, fmt.Errorf("no httproute routes tool calls to service %s/%s", service.Namespace, service.Name)
+	}
 
+	host, err := serviceHost(service)
+	if err != nil {
+		return nil, err
+	}
+	scheme := "http"
+	var protocol string
+	if port != nil && port.AppProtocol != nil && strings.ToLower(*port.AppProtocol) == "https" {
+		scheme = "https"
+	} else if port != nil {
+		protocol = appProtocolHTTPVersion(port.AppProtocol)
+	}
+	hostAndPort := host
+	if port != nil {
+		hostAndPort = net.JoinHostPort(host, fmt.Sprintf("%d", port.Port))
+	}
+
+	return &ServerInfo{
+		Endpoint:           fmt.Sprintf("%s://%s%s", scheme, hostAndPort, path),
+		Hostname:           string(targetRoute.Spec.Hostnames[0]),
+		HTTPRouteName:      targetRoute.Name,
+		HTTPRouteNamespace: targetRoute.Namespace,
+		Protocol:           protocol,
+	}, nil
+}
+
+// getTargetService gets the Service targeted by the registration. A Service in another namespace must be granted to
+// HTTPRoutes in the registration's namespace, as the HTTPRoute routing tool calls to it is created there
+func (r *MCPReconciler) getTargetService(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (*corev1.Service, error) {
+	namespace := targetNamespace(mcpsr)
+	if namespace != mcpsr.Namespace {
+		permitted, err := hasReferenceGrant(ctx, r.Client,
+			grantReference{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: mcpsr.Namespace},
+			grantReference{Group: "", Kind: "Service", Namespace: namespace, Name: mcpsr.Spec.TargetRef.Name})
+		if err != nil {
+			return nil, err
+		}
+		if !permitted {
+			return nil, fmt.Errorf("%w: no ReferenceGrant in %s allows HTTPRoutes in %s to reference Service %s",
+				errBackendReferenceNotPermitted, namespace, mcpsr.Namespace, mcpsr.Spec.TargetRef.Name)
+		}
+	}
+	service := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Name: mcpsr.Spec.TargetRef.Name, Namespace: namespace}, service); err != nil {
+		return nil, fmt.Errorf("failed to get targeted service %s/%s: %w", namespace, mcpsr.Spec.TargetRef.Name, err)
+	}
+	return service, nil
+}
+
+// targetNamespace returns the namespace of the Service targeted by the registration
+func targetNamespace(mcpsr *mcpv1alpha1.MCPServerRegistration) string {
+	if mcpsr.Spec.TargetRef.Namespace != "" {
+		return mcpsr.Spec.TargetRef.Namespace
+	}
+	return mcpsr.Namespace
+}
+
+// serviceHost returns the host the broker connects to for a Service, the external name of an ExternalName Service
+// or the Service DNS name
+func serviceHost(service *corev1.Service) (string, error) {
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		if !isValidHostname(service.Spec.ExternalName) {
+			return "", fmt.Errorf("invalid external name in service %s: %s", service.Name, service.Spec.ExternalName)
+		}
+		return service.Spec.ExternalName, nil
+	}
+	return fmt.Sprintf("%s.%s.svc.cluster.local", service.Name, service.Namespace), nil
+}
+
+// targetServiceHasReadyEndpoints checks the Service serving the registration has at least one ready endpoint. For a
+// registration targeting an HTTPRoute that is the Service of its selected backendRef. A Hostname backendRef or an
+// ExternalName Service has no endpoints to check and always passes
+func (r *MCPReconciler) targetServiceHasReadyEndpoints(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (bool, error) {
+	serviceName, ok, err := r.targetBackendService(ctx, mcpsr)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return true, nil
+	}
+	service := &corev1.Service{}
+	if err := r.Get(ctx, serviceName, service); err != nil {
+		return false, fmt.Errorf("failed to get targeted service %s: %w", serviceName, err)
+	}
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		return true, nil
+	}
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+	if err := r.List(ctx, endpointSliceList, client.InNamespace(service.Namespace), client.MatchingLabels{
+		discoveryv1.LabelServiceName: service.Name,
+	}); err != nil {
+		return false, fmt.Errorf("failed to list endpoint slices for service %s: %w", service.Name, err)
+	}
+	for _, endpointSlice := range endpointSliceList.Items {
+		for _, endpoint := range endpointSlice.Endpoints {
+			// a nil ready condition is unknown and must be treated as ready
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// targetBackendService returns the Service serving the registration, the targeted Service or the Service backendRef of
+// the targeted HTTPRoute selected for the registration. False is returned for a Hostname backendRef
+func (r *MCPReconciler) targetBackendService(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (types.NamespacedName, bool, error) {
+	if mcpsr.Spec.TargetRef.Kind == "Service" {
+		return types.NamespacedName{Name: mcpsr.Spec.TargetRef.Name, Namespace: targetNamespace(mcpsr)}, true, nil
+	}
+	httpRoute, err := r.getTargetHTTPRoute(ctx, mcpsr)
+	if err != nil {
+		return types.NamespacedName{}, false, err
+	}
+	path, err := mcpEndpointPath(mcpsr.Spec.Path)
+	if err != nil {
+		return types.NamespacedName{}, false, err
+	}
+	route := WrapHTTPRoute(httpRoute)
+	if err := route.SelectBackendRef(path, mcpsr.Spec.BackendRefName); err != nil {
+		return types.NamespacedName{}, false, err
+	}
+	if !route.IsServiceBackend() {
+		return types.NamespacedName{}, false, nil
+	}
+	return types.NamespacedName{Name: route.BackendName(), Namespace: route.BackendNamespace()}, true, nil
+}
+
+// mcpEndpointPath validates the path of a registration for building its MCP endpoint. An empty path is the default
+// /mcp and repeated slashes are collapsed so the endpoint never has a double slash
+func mcpEndpointPath(path string) (string, error) {
+	if path == "" {
+		return defaultMCPPath, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("%w %q: must begin with /", errInvalidPath, path)
+	}
+	if strings.ContainsAny(path, "?#\\ \t\r\n") {
+		return "", fmt.Errorf("%w %q: must not contain a query, fragment, backslash or whitespace", errInvalidPath, path)
+	}
+	if _, err := url.ParseRequestURI(path); err != nil {
+		return "", fmt.Errorf("%w %q: %w", errInvalidPath, path, err)
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return "", fmt.Errorf("%w %q: must not contain . or .. segments", errInvalidPath, path)
+		}
+	}
+	return collapseSlashes(path), nil
+}
+
+// collapseSlashes replaces each run of slashes in path with a single slash
+func collapseSlashes(path string) string {
+	for strings.Contains(path, "//") {
+		path = strings.ReplaceAll(path, "//", "/")
+	}
+	return path
+}
+
+// targetServicePort returns the port of the Service matching the target port. Without a target port the Service's
+// only port is used. It returns nil for an ExternalName Service without ports and no target port
+func targetServicePort(service *corev1.Service, targetPort *int32) (*corev1.ServicePort, error) {
+	if targetPort != nil {
+		for i := range service.Spec.Ports {
+			if service.Spec.Ports[i].Port == *targetPort {
+				return &service.Spec.Ports[i], nil
+			}
+		}
+		if service.Spec.Type == corev1.ServiceTypeExternalName {
+			return &corev1.ServicePort{Port: *targetPort}, nil
+		}
+		return nil, fmt.Errorf("%w: service %s has no port %d", errBackendRefNotFound, service.Name, *targetPort)
+	}
+	switch len(service.Spec.Ports) {
+	case 0:
+		if service.Spec.Type == corev1.ServiceTypeExternalName {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: service %s has no ports", errBackendRefNotFound, service.Name)
+	case 1:
+		return &service.Spec.Ports[0], nil
+	}
+	return nil, fmt.Errorf("%w: service %s has more than one port, set targetRef.port", errBackendRefAmbiguous, service.Name)
+}
+
+// backendRefFailureReason returns the status reason for errors resolving the backend of the HTTPRoute, or the
+// credential of the registration, that need a change to the route, the registration or a ReferenceGrant to resolve.
+// It is empty for any other error
+func backendRefFailureReason(err error) string {
+	switch {
+	case errors.Is(err, errBackendReferenceNotPermitted):
+		return ReasonBackendRefGrantRequired
+	case errors.Is(err, errCredentialReferenceNotPermitted):
+		return ReasonCredentialRefGrantRequired
+	case errors.Is(err, errBackendRefNotFound):
+		return ReasonBackendRefNotFound
+	case errors.Is(err, errBackendRefAmbiguous):
+		return ReasonBackendRefAmbiguous
+	case errors.Is(err, errHostnameNotFound):
+		return ReasonHostnameNotFound
+	case errors.Is(err, errUnsupportedFilter):
+		return ReasonUnsupportedFilter
+	case errors.Is(err, errInvalidPath):
+		return ReasonInvalidPath
+	case errors.Is(err, errInvalidToolName):
+		return ReasonInvalidToolName
+	}
+	return ""
+}
+
+// backendReferencePermitted checks for a ReferenceGrant in the backend namespace allowing the HTTPRoute to reference the Service
+func (r *MCPReconciler) backendReferencePermitted(ctx context.Context, route *HTTPRouteWrapper) (bool, error) {
+	return hasReferenceGrant(ctx, r.Client,
+		grantReference{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: route.Namespace},
+		grantReference{Group: "", Kind: "Service", Namespace: route.BackendNamespace(), Name: route.BackendName()})
+}
+
+// buildServiceEndpoint builds the endpoint URL and routing hostname for a Service backend. The endpoint of an
+// ExternalName Service connects to rewriteHostname when set
+func (r *MCPReconciler) buildServiceEndpoint(route *HTTPRouteWrapper, service *corev1.Service, path, rewriteHostname string) (endpoint, routingHostname string) {
+	isExternal := service.Spec.Type == corev1.ServiceTypeExternalName
+
+	var hostAndPort string
 	if isExternal {
-		if idx := strings.LastIndex(hostAndPort, ":"); idx != -1 {
-			routingHostname = hostAndPort[:idx]
-		} else {
-			routingHostname = hostAndPort
+		routingHostname = service.Spec.ExternalName
+		hostAndPort = service.Spec.ExternalName
+		if rewriteHostname != "" {
+			hostAndPort = rewriteHostname
 		}
 	} else {
-		routingHostname = route.FirstHostname()
+		routingHostname = route.RoutingHostname()
+		hostAndPort = fmt.Sprintf("%s.%s.svc.cluster.local", route.BackendName(), route.BackendNamespace())
+	}
+
+	if route.BackendPort() != nil {
+		hostAndPort = fmt.Sprintf("%s:%d", hostAndPort, *route.BackendPort())
 	}
 
+	protocol := r.determineProtocol(route, service, isExternal)
+	endpoint = fmt.Sprintf("%s://%s%s", protocol, hostAndPort, path)
+
 	return endpoint, routingHostname
 }
 
@@ -517,6 +1622,89 @@ func (r *MCPReconciler) determineProtocol(route *HTTPRouteWrapper, service *core
 	return "http"
 }
 
+// routeRequest is how the HTTPRoute filters change requests to the selected backend
+type routeRequest struct {
+	// path is the path the backend receives
+	path string
+	// hostname is the hostname requests are rewritten to, empty when unchanged
+	hostname string
+	// headers are set on requests to the backend
+	headers map[string]string
+}
+
+// applyRequestFilters returns how the filters of the selected backend change requests to path so the broker can send
+// the same requests. Filters that only change responses or copies of requests are ignored. A redirect or extension
+// filter can't be followed by the broker, which connects to the backend directly, so is reported as unsupported
+func applyRequestFilters(route *HTTPRouteWrapper, path string) (routeRequest, error) {
+	request := routeRequest{path: path}
+	for _, filter := range route.RequestFilters() {
+		switch filter.Type {
+		case gatewayv1.HTTPRouteFilterURLRewrite:
+			if filter.URLRewrite == nil {
+				continue
+			}
+			if filter.URLRewrite.Path != nil {
+				request.path = route.RewritePath(request.path, filter.URLRewrite.Path)
+			}
+			if filter.URLRewrite.Hostname != nil {
+				request.hostname = string(*filter.URLRewrite.Hostname)
+			}
+		case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+			if filter.RequestHeaderModifier == nil {
+				continue
+			}
+			if request.headers == nil {
+				request.headers = map[string]string{}
+			}
+			for _, header := range filter.RequestHeaderModifier.Set {
+				request.headers[strings.ToLower(string(header.Name))] = header.Value
+			}
+			// an added header is appended to any value the header already has
+			for _, header := range filter.RequestHeaderModifier.Add {
+				name := strings.ToLower(string(header.Name))
+				if value, ok := request.headers[name]; ok {
+					request.headers[name] = value + "," + header.Value
+					continue
+				}
+				request.headers[name] = header.Value
+			}
+			for _, name := range filter.RequestHeaderModifier.Remove {
+				delete(request.headers, strings.ToLower(name))
+			}
+		case gatewayv1.HTTPRouteFilterRequestRedirect, gatewayv1.HTTPRouteFilterExtensionRef:
+			return routeRequest{}, fmt.Errorf("%w: HTTPRoute %s/%s has a %s filter for backendRef %s",
+				errUnsupportedFilter, route.Namespace, route.Name, filter.Type, route.BackendName())
+		}
+	}
+	if len(request.headers) == 0 {
+		request.headers = nil
+	}
+	return request, nil
+}
+
+// backendAppProtocol returns the HTTP protocol of the Service port referenced by the HTTPRoute backend
+func backendAppProtocol(route *HTTPRouteWrapper, service *corev1.Service) string {
+	for _, port := range service.Spec.Ports {
+		if route.BackendPort() != nil && port.Port == *route.BackendPort() {
+			return appProtocolHTTPVersion(port.AppProtocol)
+		}
+	}
+	return ""
+}
+
+// appProtocolHTTPVersion returns the HTTP protocol for a Service port appProtocol. h2c, kubernetes.io/h2c and grpc
+// backends speak HTTP/2 without TLS. It is empty for HTTP/1.1
+func appProtocolHTTPVersion(appProtocol *string) string {
+	if appProtocol == nil {
+		return ""
+	}
+	switch strings.ToLower(*appProtocol) {
+	case "h2c", "kubernetes.io/h2c", "grpc":
+		return config.ProtocolH2C
+	}
+	return ""
+}
+
 // isValidHostname validates the hostname to prevent path injection
 func isValidHostname(hostname string) bool {
 	if hostname == "" {
@@ -550,58 +1738,246 @@ func (r *MCPReconciler) updateHTTPRouteStatus(ctx context.Context, mcpsr *mcpv1a
 		if apierrors.IsNotFound(err) {
 			return nil
 		}
-		return fmt.Errorf("failed to get HTTPRoute: %w", err)
+		return fmt.Errorf("failed to get HTTPRoute: %w", err)
+	}
+
+	condition := metav1.Condition{
+		Type:               "Programmed",
+		ObservedGeneration: httpRoute.Generation,
+		LastTransitionTime: metav1.Now(),
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "InUseByMCPServerRegistration"
+	// We don't include the MCP Server in the status because >1 MCPServerRegistration may reference the same HTTPRoute
+	condition.Message = "HTTPRoute is referenced by at least one MCPServerRegistration"
+	// all parents are updated in a single write
+	var changed bool
+	for i := range httpRoute.Status.Parents {
+		changed = meta.SetStatusCondition(&httpRoute.Status.Parents[i].Conditions, condition) || changed
+	}
+	if !changed {
+		return nil
+	}
+	return r.Status().Update(ctx, httpRoute)
+}
+
+// cleanupOrphanedHTTPRoutes removes the Programmed condition from HTTPRoutes no longer referenced by a registration
+// that is not being deleted, so the condition stays in place until the last registration of a route is deleted
+func (r *MCPReconciler) cleanupOrphanedHTTPRoutes(ctx context.Context) error {
+	httpRoutes := &gatewayv1.HTTPRouteList{}
+	if err := r.List(ctx, httpRoutes, client.MatchingFields{ProgrammedHTTPRouteIndex: "true"}); err != nil {
+		return fmt.Errorf("failed to list programmed HTTPRoutes: %w", err)
+	}
+	for i := range httpRoutes.Items {
+		httpRoute := &httpRoutes.Items[i]
+		registrations := &mcpv1alpha1.MCPServerRegistrationList{}
+		if err := r.List(ctx, registrations, client.MatchingFields{HTTPRouteIndex: httpRouteIndexValue(httpRoute.Namespace, httpRoute.Name)}); err != nil {
+			return fmt.Errorf("failed to list MCPServerRegistrations for HTTPRoute: %w", err)
+		}
+		if slices.ContainsFunc(registrations.Items, func(mcpsr mcpv1alpha1.MCPServerRegistration) bool {
+			return mcpsr.DeletionTimestamp == nil
+		}) {
+			continue
+		}
+		var changed bool
+		for j := range httpRoute.Status.Parents {
+			changed = meta.RemoveStatusCondition(&httpRoute.Status.Parents[j].Conditions, "Programmed") || changed
+		}
+		if !changed {
+			continue
+		}
+		if err := r.Status().Update(ctx, httpRoute); err != nil {
+			return fmt.Errorf("failed to remove Programmed condition from HTTPRoute %s/%s: %w", httpRoute.Namespace, httpRoute.Name, err)
+		}
+	}
+	return nil
+}
+
+// updateStatus sets the status of a registration whose config has not been written, so it is neither accepted nor ready
+func (r *MCPReconciler) updateStatus(
+	ctx context.Context,
+	mcpsr *mcpv1alpha1.MCPServerRegistration,
+	ready bool,
+	message string,
+	toolCount int,
+) error {
+	return r.updateStatusWithReason(ctx, mcpsr, ready, "", message, toolCount)
+}
+
+// updateStatusWithReason sets the Accepted and Ready conditions of a registration whose config has not been written.
+// notReadyReason overrides the generic NotAccepted and NotReady reasons
+func (r *MCPReconciler) updateStatusWithReason(
+	ctx context.Context,
+	mcpsr *mcpv1alpha1.MCPServerRegistration,
+	ready bool,
+	notReadyReason string,
+	message string,
+	toolCount int,
+) error {
+	if !setReadyStatus(mcpsr, ready, ready, notReadyReason, message, toolCount) {
+		return nil
+	}
+	return r.writeStatus(ctx, mcpsr)
+}
+
+// updateAcceptedStatus sets the status of a registration whose config has been written. It is accepted and its
+// readiness follows the broker
+func (r *MCPReconciler) updateAcceptedStatus(
+	ctx context.Context,
+	mcpsr *mcpv1alpha1.MCPServerRegistration,
+	ready bool,
+	message string,
+	toolCount int,
+) error {
+	if !setReadyStatus(mcpsr, true, ready, "", message, toolCount) {
+		return nil
+	}
+	return r.writeStatus(ctx, mcpsr)
+}
+
+// updateStatusCoalesced is updateAcceptedStatus for the frequently polled broker status. A change that keeps the
+// status and reason of the conditions is written at most once per StatusCoalesceWindow, otherwise errStatusDeferred
+// is returned so the caller can requeue and write the latest status once the window has passed. The tools the broker
+// rejected as conflicting, the protocol version of the server and its consecutive failures are recorded in status
+func (r *MCPReconciler) updateStatusCoalesced(
+	ctx context.Context,
+	mcpsr *mcpv1alpha1.MCPServerRegistration,
+	serverStatus upstream.ServerValidationStatus,
+	consecutiveFailures int32,
+) error {
+	previous := conditionStates(mcpsr)
+	previousGeneration, previousForceSync := mcpsr.Status.ObservedGeneration, mcpsr.Status.ForceSync
+	statusChanged := setReadyStatusWithConflicts(mcpsr, true, serverStatus.Ready, serverStatus.Reason, serverStatus.Message,
+		serverStatus.TotalTools, serverStatus.ConflictingTools)
+	if mcpsr.Status.ProtocolVersion != serverStatus.ProtocolVersion {
+		mcpsr.Status.ProtocolVersion = serverStatus.ProtocolVersion
+		statusChanged = true
+	}
+	if mcpsr.Status.ConsecutiveFailures != consecutiveFailures {
+		mcpsr.Status.ConsecutiveFailures = consecutiveFailures
+		statusChanged = true
+	}
+	if !statusChanged {
+		return nil
+	}
+	transition := conditionStates(mcpsr) != previous || mcpsr.Status.ObservedGeneration != previousGeneration ||
+		mcpsr.Status.ForceSync != previousForceSync
+	if !transition && r.StatusCoalesceWindow > 0 {
+		if lastWrite, ok := r.statusWrites.Load(client.ObjectKeyFromObject(mcpsr)); ok && time.Since(lastWrite.(time.Time)) < r.StatusCoalesceWindow {
+			return errStatusDeferred
+		}
 	}
+	return r.writeStatus(ctx, mcpsr)
+}
 
-	condition := metav1.Condition{
-		Type:               "Programmed",
-		ObservedGeneration: httpRoute.Generation,
-		LastTransitionTime: metav1.Now(),
+// conditionStates returns the status and reason of the Accepted and Ready conditions, which are always written
+// straight away when they change
+func conditionStates(mcpsr *mcpv1alpha1.MCPServerRegistration) string {
+	var states []string
+	for _, conditionType := range []string{mcpv1alpha1.ConditionTypeAccepted, mcpv1alpha1.ConditionTypeReady} {
+		if condition := meta.FindStatusCondition(mcpsr.Status.Conditions, conditionType); condition != nil {
+			states = append(states, conditionType, string(condition.Status), condition.Reason)
+		}
 	}
+	return strings.Join(states, "/")
+}
 
-	condition.Status = metav1.ConditionTrue
-	condition.Reason = "InUseByMCPServerRegistration"
-	// We don't include the MCP Server in the status because >1 MCPServerRegistration may reference the same HTTPRoute
-	condition.Message = "HTTPRoute is referenced by at least one MCPServerRegistration"
-	var changed bool
-	for i := range httpRoute.Status.Parents {
-		if mcpsr.DeletionTimestamp != nil {
-			changed = meta.RemoveStatusCondition(&httpRoute.Status.Parents[i].Conditions, "Programmed")
-		} else {
-			changed = meta.SetStatusCondition(&httpRoute.Status.Parents[i].Conditions, condition)
-		}
-		if changed {
-			if err := r.Status().Update(ctx, httpRoute); err != nil {
-				return err
-			}
-		}
+func (r *MCPReconciler) writeStatus(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) error {
+	if err := r.Status().Update(ctx, mcpsr); err != nil {
+		return err
 	}
+	r.statusWrites.Store(client.ObjectKeyFromObject(mcpsr), time.Now())
 	return nil
+}
 
+// setReadyStatus sets the Accepted and Ready conditions, tool count and observed generation on the registration and
+// returns true if the status changed. A registration is accepted once its config has been written for the broker and
+// ready once the broker has discovered its tools. Conflicting tools are only known from the broker status, so they are
+// cleared
+func setReadyStatus(
+	mcpsr *mcpv1alpha1.MCPServerRegistration,
+	accepted bool,
+	ready bool,
+	notReadyReason string,
+	message string,
+	toolCount int,
+) bool {
+	return setReadyStatusWithConflicts(mcpsr, accepted, ready, notReadyReason, message, toolCount, nil)
 }
 
-func (r *MCPReconciler) updateStatus(
-	ctx context.Context,
+// setReadyStatusWithConflicts is setReadyStatus for a status reported by the broker, which also lists the tools it
+// rejected as conflicting
+func setReadyStatusWithConflicts(
 	mcpsr *mcpv1alpha1.MCPServerRegistration,
+	accepted bool,
 	ready bool,
+	notReadyReason string,
 	message string,
 	toolCount int,
-) error {
-	condition := metav1.Condition{
-		Type:               "Ready",
+	conflictingTools []string,
+) bool {
+	acceptedCondition := metav1.Condition{
+		Type:               mcpv1alpha1.ConditionTypeAccepted,
 		Status:             metav1.ConditionFalse,
-		Reason:             "NotReady",
+		ObservedGeneration: mcpsr.Generation,
+		Reason:             "NotAccepted",
 		Message:            message,
-		LastTransitionTime: metav1.Now(),
+	}
+	if notReadyReason != "" {
+		acceptedCondition.Reason = notReadyReason
+	}
+	if accepted {
+		acceptedCondition.Status = metav1.ConditionTrue
+		acceptedCondition.Reason = "Accepted"
+		acceptedCondition.Message = "server config written for the broker"
 	}
 
+	readyCondition := metav1.Condition{
+		Type:               mcpv1alpha1.ConditionTypeReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: mcpsr.Generation,
+		Reason:             "NotReady",
+		Message:            message,
+	}
+	if notReadyReason != "" {
+		readyCondition.Reason = notReadyReason
+	}
 	if ready {
-		condition.Status = metav1.ConditionTrue
-		condition.Reason = "Ready"
+		readyCondition.Status = metav1.ConditionTrue
+		readyCondition.Reason = "Ready"
+	}
+
+	statusChanged := setRegistrationCondition(mcpsr, acceptedCondition)
+	statusChanged = setRegistrationCondition(mcpsr, readyCondition) || statusChanged
+	if mcpsr.Status.DiscoveredTools != toolCount {
+		mcpsr.Status.DiscoveredTools = toolCount
+		statusChanged = true
+	}
+	if !slices.Equal(mcpsr.Status.ConflictingTools, conflictingTools) {
+		mcpsr.Status.ConflictingTools = slices.Clone(conflictingTools)
+		statusChanged = true
+	}
+	// a spec change is always written so users can tell the controller has processed it, and failures of the
+	// previous spec no longer count
+	if mcpsr.Status.ObservedGeneration != mcpsr.Generation {
+		mcpsr.Status.ObservedGeneration = mcpsr.Generation
+		mcpsr.Status.ConsecutiveFailures = 0
+		statusChanged = true
 	}
+	// a force sync is handled like a spec change
+	if forceSyncPending(mcpsr) {
+		mcpsr.Status.ForceSync = mcpsr.Annotations[AnnotationForceSync]
+		mcpsr.Status.ConsecutiveFailures = 0
+		statusChanged = true
+	}
+
+	return statusChanged
+}
 
-	statusChanged := false
-	found := false
+// setRegistrationCondition sets a condition on the registration and returns true if it changed
+func setRegistrationCondition(mcpsr *mcpv1alpha1.MCPServerRegistration, condition metav1.Condition) bool {
+	condition.LastTransitionTime = metav1.Now()
 	for i, cond := range mcpsr.Status.Conditions {
 		if cond.Type == condition.Type {
 			// only update LastTransitionTime if the STATUS actually changed (True->False or False->True)
@@ -610,30 +1986,14 @@ func (r *MCPReconciler) updateStatus(
 				// status hasn't changed, preserve existing LastTransitionTime
 				condition.LastTransitionTime = cond.LastTransitionTime
 			}
-			// check if anything actually changed
-			if cond.Status != condition.Status || cond.Reason != condition.Reason || cond.Message != condition.Message {
-				statusChanged = true
-			}
 			mcpsr.Status.Conditions[i] = condition
-			found = true
-			break
+			// check if anything actually changed
+			return cond.Status != condition.Status || cond.Reason != condition.Reason || cond.Message != condition.Message ||
+				cond.ObservedGeneration != condition.ObservedGeneration
 		}
 	}
-	if !found {
-		mcpsr.Status.Conditions = append(mcpsr.Status.Conditions, condition)
-		statusChanged = true
-	}
-	if mcpsr.Status.DiscoveredTools != toolCount {
-		mcpsr.Status.DiscoveredTools = toolCount
-		statusChanged = true
-	}
-
-	// only update if something actually changed
-	if !statusChanged {
-		return nil
-	}
-
-	return r.Status().Update(ctx, mcpsr)
+	mcpsr.Status.Conditions = append(mcpsr.Status.Conditions, condition)
+	return true
 }
 
 // SetupWithManager sets up the reconciler
@@ -642,33 +2002,125 @@ func (r *MCPReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager)
 		return fmt.Errorf("failed to setup required index from MCPServerRegistration to httproutes %w", err)
 	}
 
+	if err := setupIndexMCPRegistrationToService(ctx, mgr.GetFieldIndexer()); err != nil {
+		return fmt.Errorf("failed to setup required index from MCPServerRegistration to services %w", err)
+	}
+
+	if err := setupIndexMCPRegistrationToCredentialSecret(ctx, mgr.GetFieldIndexer()); err != nil {
+		return fmt.Errorf("failed to setup required index from MCPServerRegistration to credential secrets %w", err)
+	}
+
 	if err := setupIndexProgrammedHTTPRoutes(ctx, mgr.GetFieldIndexer()); err != nil {
 		return fmt.Errorf("failed to setup required index for programmed httproutes %w", err)
 	}
 
+	if err := setupIndexHTTPRouteToBackendService(ctx, mgr.GetFieldIndexer()); err != nil {
+		return fmt.Errorf("failed to setup required index from httproutes to backend services %w", err)
+	}
+
 	controller := ctrl.NewControllerManagedBy(mgr).
-		For(&mcpv1alpha1.MCPServerRegistration{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&mcpv1alpha1.MCPServerRegistration{}, builder.WithPredicates(registrationChangedPredicate())).
+		// the HTTPRoutes routing tool calls to Service targets
+		Owns(&gatewayv1.HTTPRoute{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Watches(
 			&gatewayv1.HTTPRoute{},
 			handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForHTTPRoute),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		Watches(
+			&corev1.Service{},
+			handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForService),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForEndpointSlice),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForSecret),
-			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
-				// TODO add a cache filter
-				secret := obj.(*corev1.Secret)
-				return secret.Labels != nil && secret.Labels[CredentialSecretLabel] == CredentialSecretValue
-			})),
+			builder.WithPredicates(credentialSecretPredicate(r.CredentialLabel, r.CredentialSecretSelector)),
 		).
 		Watches(
 			&mcpv1alpha1.MCPGatewayExtension{},
-			handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForMCPGatewayExtension),
+			r.mcpGatewayExtensionHandler(),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&gatewayv1beta1.ReferenceGrant{},
+			handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForReferenceGrant),
+		).
+		// virtual servers changing their tools change which registrations they depend on
+		Watches(
+			&mcpv1alpha1.MCPVirtualServer{},
+			handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForVirtualServer),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		)
+	if r.ExtensionDeleted != nil {
+		controller = controller.WatchesRawSource(r.extensionDeletedSource())
+	}
+	// the config secret is recreated empty when deleted so registrations need to write their config again
+	if r.ConfigSecretCache != nil {
+		controller = controller.WatchesRawSource(source.Kind[client.Object](r.ConfigSecretCache, &corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForConfigSecret), configSecretDeletedPredicate()))
+	} else {
+		controller = controller.Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForConfigSecret),
+			builder.WithPredicates(configSecretDeletedPredicate()),
 		)
+	}
+
+	return controller.Complete(r.ReconcileTiming.Wrap("MCPServerRegistration", r))
+}
+
+// extensionDeletedSource enqueues the registrations that wrote config to each extension received on ExtensionDeleted
+func (r *MCPReconciler) extensionDeletedSource() source.Source {
+	return source.Channel(r.ExtensionDeleted, handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForMCPGatewayExtension))
+}
+
+// configSecretDeletedPredicate only passes the deletion of config secrets written by the controller
+func configSecretDeletedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(_ event.CreateEvent) bool { return false },
+		UpdateFunc:  func(_ event.UpdateEvent) bool { return false },
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return e.Object.GetLabels()[config.AggregatedSecretLabel] == "true"
+		},
+	}
+}
+
+// credentialSecretPredicate passes Secrets with the credential label that also match the optional selector
+func credentialSecretPredicate(label CredentialLabel, selector labels.Selector) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		secretLabels := obj.GetLabels()
+		if !label.matches(secretLabels) {
+			return false
+		}
+		return selector == nil || selector.Matches(labels.Set(secretLabels))
+	})
+}
+
+// registrationChangedPredicate passes spec changes and changes to the force-sync annotation
+func registrationChangedPredicate() predicate.Predicate {
+	return predicate.Or(predicate.GenerationChangedPredicate{}, forceSyncAnnotationChangedPredicate())
+}
 
-	return controller.Complete(r)
+// forceSyncAnnotationChangedPredicate only passes updates where the force-sync annotation value changed
+func forceSyncAnnotationChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(_ event.CreateEvent) bool { return false },
+		DeleteFunc:  func(_ event.DeleteEvent) bool { return false },
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			return e.ObjectOld.GetAnnotations()[AnnotationForceSync] != e.ObjectNew.GetAnnotations()[AnnotationForceSync]
+		},
+	}
 }
 
 func httpRouteIndexValue(namespace, name string) string {
@@ -676,40 +2128,161 @@ func httpRouteIndexValue(namespace, name string) string {
 }
 
 func setupIndexProgrammedHTTPRoutes(ctx context.Context, indexer client.FieldIndexer) error {
-	if err := indexer.IndexField(ctx, &gatewayv1.HTTPRoute{}, ProgrammedHTTPRouteIndex, func(rawObj client.Object) []string {
-		httpRoute := rawObj.(*gatewayv1.HTTPRoute)
-		for _, parentStatus := range httpRoute.Status.Parents {
-			for _, condition := range parentStatus.Conditions {
-				if condition.Type == "Programmed" && condition.Status == metav1.ConditionTrue {
-					return []string{"true"}
-				}
+	if err := indexer.IndexField(ctx, &gatewayv1.HTTPRoute{}, ProgrammedHTTPRouteIndex, programmedHTTPRouteIndexValues); err != nil {
+		return err
+	}
+	return nil
+}
+
+// programmedHTTPRouteIndexValues returns "true" when a parent of the HTTPRoute has a true Programmed condition
+func programmedHTTPRouteIndexValues(rawObj client.Object) []string {
+	httpRoute := rawObj.(*gatewayv1.HTTPRoute)
+	for _, parentStatus := range httpRoute.Status.Parents {
+		for _, condition := range parentStatus.Conditions {
+			if condition.Type == "Programmed" && condition.Status == metav1.ConditionTrue {
+				return []string{"true"}
 			}
 		}
-		return []string{"false"}
-	}); err != nil {
+	}
+	return []string{"false"}
+}
+
+func setupIndexHTTPRouteToBackendService(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &gatewayv1.HTTPRoute{}, HTTPRouteBackendServiceIndex, httpRouteBackendServiceIndexValues)
+}
+
+// httpRouteBackendServiceIndexValues returns the namespace/name of each Service the HTTPRoute has a backendRef to
+func httpRouteBackendServiceIndexValues(rawObj client.Object) []string {
+	httpRoute := rawObj.(*gatewayv1.HTTPRoute)
+	values := []string{}
+	for _, rule := range httpRoute.Spec.Rules {
+		for _, backendRef := range rule.BackendRefs {
+			if backendRef.Group != nil && *backendRef.Group != "" {
+				continue
+			}
+			if backendRef.Kind != nil && *backendRef.Kind != "Service" {
+				continue
+			}
+			namespace := httpRoute.Namespace
+			if backendRef.Namespace != nil {
+				namespace = string(*backendRef.Namespace)
+			}
+			if value := serviceIndexValue(namespace, string(backendRef.Name)); !slices.Contains(values, value) {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func setupIndexMCPRegistrationToHTTPRoute(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &mcpv1alpha1.MCPServerRegistration{}, HTTPRouteIndex, httpRouteIndexValues); err != nil {
 		return err
 	}
 	return nil
 }
 
-func setupIndexMCPRegistrationToHTTPRoute(ctx context.Context, indexer client.FieldIndexer) error {
-	if err := indexer.IndexField(ctx, &mcpv1alpha1.MCPServerRegistration{}, HTTPRouteIndex, func(rawObj client.Object) []string {
-		mcpsr := rawObj.(*mcpv1alpha1.MCPServerRegistration)
-		targetRef := mcpsr.Spec.TargetRef
-		if targetRef.Kind == "HTTPRoute" {
-			namespace := targetRef.Namespace
-			if namespace == "" {
-				namespace = mcpsr.Namespace
-			}
-			return []string{httpRouteIndexValue(namespace, targetRef.Name)}
+// httpRouteIndexValues returns the namespace/name of the HTTPRoute targeted by the registration
+func httpRouteIndexValues(rawObj client.Object) []string {
+	mcpsr := rawObj.(*mcpv1alpha1.MCPServerRegistration)
+	targetRef := mcpsr.Spec.TargetRef
+	if targetRef.Kind == "HTTPRoute" {
+		namespace := targetRef.Namespace
+		if namespace == "" {
+			namespace = mcpsr.Namespace
 		}
-		return []string{}
-	}); err != nil {
+		return []string{httpRouteIndexValue(namespace, targetRef.Name)}
+	}
+	return []string{}
+}
+
+func setupIndexMCPRegistrationToService(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &mcpv1alpha1.MCPServerRegistration{}, ServiceIndex, serviceIndexValues); err != nil {
+		return err
+	}
+	return nil
+}
+
+// serviceIndexValues returns the namespace/name of the Service targeted by the registration
+func serviceIndexValues(rawObj client.Object) []string {
+	mcpsr := rawObj.(*mcpv1alpha1.MCPServerRegistration)
+	if mcpsr.Spec.TargetRef.Kind == "Service" {
+		return []string{serviceIndexValue(targetNamespace(mcpsr), mcpsr.Spec.TargetRef.Name)}
+	}
+	return []string{}
+}
+
+// serviceIndexValue returns the ServiceIndex key of the Service namespace/name
+func serviceIndexValue(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+func setupIndexMCPRegistrationToCredentialSecret(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &mcpv1alpha1.MCPServerRegistration{}, CredentialSecretIndex, credentialSecretIndexValues); err != nil {
 		return err
 	}
 	return nil
 }
 
+// credentialSecretIndexValues returns the namespace/name of the registration's credential secret
+func credentialSecretIndexValues(rawObj client.Object) []string {
+	mcpsr := rawObj.(*mcpv1alpha1.MCPServerRegistration)
+	if mcpsr.Spec.CredentialRef == nil {
+		return []string{}
+	}
+	return []string{credentialSecretIndexValue(credentialSecretNamespace(mcpsr), mcpsr.Spec.CredentialRef.Name)}
+}
+
+// credentialSecretIndexValue returns the CredentialSecretIndex key of the credential secret namespace/name
+func credentialSecretIndexValue(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// findMCPServerRegistrationsForService finds all MCPServerRegistrations that target the given Service
+func (r *MCPReconciler) findMCPServerRegistrationsForService(ctx context.Context, obj client.Object) []reconcile.Request {
+	service := obj.(*corev1.Service)
+	log := logf.FromContext(ctx).WithValues("Service", service.Name, "namespace", service.Namespace)
+
+	mcpsrList := &mcpv1alpha1.MCPServerRegistrationList{}
+	if err := r.List(ctx, mcpsrList, client.MatchingFields{ServiceIndex: serviceIndexValue(service.Namespace, service.Name)}); err != nil {
+		log.Error(err, "Failed to list MCPServerRegistrations using index")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, mcpsr := range mcpsrList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      mcpsr.Name,
+				Namespace: mcpsr.Namespace,
+			},
+		})
+	}
+	return requests
+}
+
+// findMCPServerRegistrationsForEndpointSlice finds all MCPServerRegistrations targeting the Service of the EndpointSlice,
+// or an HTTPRoute with a backendRef to it
+func (r *MCPReconciler) findMCPServerRegistrationsForEndpointSlice(ctx context.Context, obj client.Object) []reconcile.Request {
+	serviceName, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+	if !ok {
+		return nil
+	}
+	requests := r.findMCPServerRegistrationsForService(ctx, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: obj.GetNamespace()},
+	})
+	// registrations targeting an HTTPRoute check the endpoints of its backend Service too
+	httpRouteList := &gatewayv1.HTTPRouteList{}
+	if err := r.List(ctx, httpRouteList, client.MatchingFields{HTTPRouteBackendServiceIndex: serviceIndexValue(obj.GetNamespace(), serviceName)}); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list HTTPRoutes for Service", "service", serviceName, "namespace", obj.GetNamespace())
+		return requests
+	}
+	for i := range httpRouteList.Items {
+		requests = append(requests, r.findMCPServerRegistrationsForHTTPRoute(ctx, &httpRouteList.Items[i])...)
+	}
+	return requests
+}
+
 // findMCPServerRegistrationsForHTTPRoute finds all MCPServerRegistrations that reference the given HTTPRoute
 func (r *MCPReconciler) findMCPServerRegistrationsForHTTPRoute(ctx context.Context, obj client.Object) []reconcile.Request {
 	httpRoute := obj.(*gatewayv1.HTTPRoute)
@@ -738,6 +2311,92 @@ func (r *MCPReconciler) findMCPServerRegistrationsForHTTPRoute(ctx context.Conte
 	return requests
 }
 
+// findMCPServerRegistrationsForReferenceGrant finds MCPServerRegistrations whose HTTPRoute references a Service
+// in the grant's namespace from a namespace the grant allows, so backend reference validity is re-evaluated
+func (r *MCPReconciler) findMCPServerRegistrationsForReferenceGrant(ctx context.Context, obj client.Object) []reconcile.Request {
+	refGrant := obj.(*gatewayv1beta1.ReferenceGrant)
+	log := logf.FromContext(ctx).WithValues("ReferenceGrant", refGrant.Name, "namespace", refGrant.Namespace)
+
+	var requests []reconcile.Request
+	for _, from := range refGrant.Spec.From {
+		if string(from.Namespace) == refGrant.Namespace {
+			continue
+		}
+		if string(from.Group) == mcpv1alpha1.GroupVersion.Group && from.Kind == "MCPServerRegistration" {
+			requests = append(requests, r.findMCPServerRegistrationsWithCredentialIn(ctx, string(from.Namespace), refGrant.Namespace)...)
+			continue
+		}
+		if from.Group != gatewayv1.GroupName || from.Kind != "HTTPRoute" {
+			continue
+		}
+		requests = append(requests, r.findMCPServerRegistrationsWithServiceIn(ctx, string(from.Namespace), refGrant.Namespace)...)
+		httpRouteList := &gatewayv1.HTTPRouteList{}
+		if err := r.List(ctx, httpRouteList, client.InNamespace(string(from.Namespace))); err != nil {
+			log.Error(err, "Failed to list HTTPRoutes", "routeNamespace", from.Namespace)
+			continue
+		}
+		for i := range httpRouteList.Items {
+			route := WrapHTTPRoute(&httpRouteList.Items[i])
+			if route.Validate() != nil || !route.ReferencesServiceIn(refGrant.Namespace) {
+				continue
+			}
+			requests = append(requests, r.findMCPServerRegistrationsForHTTPRoute(ctx, &httpRouteList.Items[i])...)
+		}
+	}
+	return requests
+}
+
+// findMCPServerRegistrationsWithServiceIn finds MCPServerRegistrations in the namespace that target a Service in
+// serviceNamespace
+func (r *MCPReconciler) findMCPServerRegistrationsWithServiceIn(ctx context.Context, namespace, serviceNamespace string) []reconcile.Request {
+	mcpsrList := &mcpv1alpha1.MCPServerRegistrationList{}
+	if err := r.List(ctx, mcpsrList, client.InNamespace(namespace)); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list MCPServerRegistrations", "namespace", namespace)
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range mcpsrList.Items {
+		if mcpsrList.Items[i].Spec.TargetRef.Kind == "Service" && targetNamespace(&mcpsrList.Items[i]) == serviceNamespace {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&mcpsrList.Items[i])})
+		}
+	}
+	return requests
+}
+
+// findMCPServerRegistrationsWithCredentialIn finds MCPServerRegistrations in the namespace whose credential Secret is
+// in secretNamespace
+func (r *MCPReconciler) findMCPServerRegistrationsWithCredentialIn(ctx context.Context, namespace, secretNamespace string) []reconcile.Request {
+	mcpsrList := &mcpv1alpha1.MCPServerRegistrationList{}
+	if err := r.List(ctx, mcpsrList, client.InNamespace(namespace)); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list MCPServerRegistrations", "namespace", namespace)
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range mcpsrList.Items {
+		if mcpsrList.Items[i].Spec.CredentialRef != nil && credentialSecretNamespace(&mcpsrList.Items[i]) == secretNamespace {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&mcpsrList.Items[i])})
+		}
+	}
+	return requests
+}
+
+// findMCPServerRegistrationsForVirtualServer enqueues every MCPServerRegistration as the virtual server may have
+// started or stopped referencing any of their tools
+func (r *MCPReconciler) findMCPServerRegistrationsForVirtualServer(ctx context.Context, _ client.Object) []reconcile.Request {
+	registrations := &mcpv1alpha1.MCPServerRegistrationList{}
+	if err := r.List(ctx, registrations); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list MCPServerRegistrations")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(registrations.Items))
+	for _, mcpsr := range registrations.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: mcpsr.Name, Namespace: mcpsr.Namespace},
+		})
+	}
+	return requests
+}
+
 // findMCPServerRegistrationsForSecret finds MCPServerRegistrations referencing the given secret
 func (r *MCPReconciler) findMCPServerRegistrationsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
 	secret := obj.(*corev1.Secret)
@@ -753,7 +2412,7 @@ func (r *MCPReconciler) findMCPServerRegistrationsForSecret(ctx context.Context,
 	var requests []reconcile.Request
 	for _, mcpsr := range mcpsrList.Items {
 		// check if references this secret
-		if mcpsr.Spec.CredentialRef != nil && mcpsr.Spec.CredentialRef.Name == secret.Name {
+		if referencesSecret(&mcpsr, secret.Name) {
 			log.Info("findMCPServerRegistrationsForSecret", "requeue", mcpsr.Name)
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
@@ -764,19 +2423,114 @@ func (r *MCPReconciler) findMCPServerRegistrationsForSecret(ctx context.Context,
 		}
 	}
 
+	// credentials can also be referenced from other namespaces
+	crossNamespace := &mcpv1alpha1.MCPServerRegistrationList{}
+	if err := r.List(ctx, crossNamespace, client.MatchingFields{CredentialSecretIndex: credentialSecretIndexValue(secret.Namespace, secret.Name)}); err != nil {
+		log.Error(err, "Failed to list MCPServerRegistrations referencing credential secret")
+		return requests
+	}
+	for _, mcpsr := range crossNamespace.Items {
+		if mcpsr.Namespace == secret.Namespace {
+			continue
+		}
+		log.Info("findMCPServerRegistrationsForSecret", "requeue", mcpsr.Name, "registrationNamespace", mcpsr.Namespace)
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&mcpsr)})
+	}
+
 	// mcpvirtualservers don't have credentials
 
 	return requests
 }
 
+// referencesSecret checks if the registration reads its credential or tls config from the named secret in its own
+// namespace
+func referencesSecret(mcpsr *mcpv1alpha1.MCPServerRegistration, secretName string) bool {
+	if mcpsr.Spec.CredentialRef != nil && mcpsr.Spec.CredentialRef.Name == secretName && credentialSecretNamespace(mcpsr) == mcpsr.Namespace {
+		return true
+	}
+	if upstreamTLS := mcpsr.Spec.TLS; upstreamTLS != nil {
+		return (upstreamTLS.CASecretRef != nil && upstreamTLS.CASecretRef.Name == secretName) ||
+			(upstreamTLS.ClientCertificateSecretRef != nil && upstreamTLS.ClientCertificateSecretRef.Name == secretName)
+	}
+	return false
+}
+
+// findMCPServerRegistrationsForConfigSecret finds MCPServerRegistrations whose config is written to the given config secret
+func (r *MCPReconciler) findMCPServerRegistrationsForConfigSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	logger := logf.FromContext(ctx).WithValues("Secret", obj.GetName(), "namespace", obj.GetNamespace())
+	mcpExtList := &mcpv1alpha1.MCPGatewayExtensionList{}
+	if err := r.List(ctx, mcpExtList, client.InNamespace(obj.GetNamespace())); err != nil {
+		logger.Error(err, "Failed to list MCPGatewayExtensions for config secret")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range mcpExtList.Items {
+		requests = append(requests, r.findMCPServerRegistrationsForMCPGatewayExtension(ctx, &mcpExtList.Items[i])...)
+	}
+	return requests
+}
+
+// mcpGatewayExtensionHandler enqueues the MCPServerRegistrations that depend on a changed MCPGatewayExtension. When the
+// extension becomes Ready their config wait is reset first, so registrations that backed off while the broker wasn't
+// ready check it again within configWaitBaseDelay rather than at their next backed off poll, and get the full
+// ConfigLoadTimeout for the broker to load their config
+func (r *MCPReconciler) mcpGatewayExtensionHandler() handler.EventHandler {
+	enqueue := handler.EnqueueRequestsFromMapFunc(r.findMCPServerRegistrationsForMCPGatewayExtension)
+	return handler.Funcs{
+		CreateFunc: enqueue.Create,
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			if extensionBecameReady(e.ObjectOld, e.ObjectNew) {
+				for _, req := range r.findMCPServerRegistrationsForMCPGatewayExtension(ctx, e.ObjectNew) {
+					r.resetConfigWait(req.NamespacedName)
+				}
+			}
+			enqueue.Update(ctx, e, q)
+		},
+		DeleteFunc:  enqueue.Delete,
+		GenericFunc: enqueue.Generic,
+	}
+}
+
+// extensionBecameReady checks if an MCPGatewayExtension update sets its Ready condition true
+func extensionBecameReady(oldObj, newObj client.Object) bool {
+	oldExt, ok := oldObj.(*mcpv1alpha1.MCPGatewayExtension)
+	if !ok {
+		return false
+	}
+	newExt, ok := newObj.(*mcpv1alpha1.MCPGatewayExtension)
+	if !ok {
+		return false
+	}
+	return !meta.IsStatusConditionTrue(oldExt.Status.Conditions, mcpv1alpha1.ConditionTypeReady) &&
+		meta.IsStatusConditionTrue(newExt.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+}
+
 // findMCPServerRegistrationsForMCPGatewayExtension finds all MCPServerRegistrations whose HTTPRoutes
-// are attached to the Gateway targeted by the given MCPGatewayExtension. When an MCPGatewayExtension
+// are attached to the Gateway targeted by the given MCPGatewayExtension, and those targeting a Service
+// in the namespace of the MCPGatewayExtension. When an MCPGatewayExtension
 // changes (created, updated, deleted), the associated MCPServerRegistrations need to be reconciled
 // to ensure their config is written to the correct namespaces.
 func (r *MCPReconciler) findMCPServerRegistrationsForMCPGatewayExtension(ctx context.Context, obj client.Object) []reconcile.Request {
 	mcpExt := obj.(*mcpv1alpha1.MCPGatewayExtension)
 	logger := logf.FromContext(ctx).WithValues("MCPGatewayExtension", mcpExt.Name, "namespace", mcpExt.Namespace)
 
+	var requests []reconcile.Request
+	// registrations targeting a Service are federated by the extensions in their namespace
+	serviceTargets := &mcpv1alpha1.MCPServerRegistrationList{}
+	if err := r.List(ctx, serviceTargets, client.InNamespace(mcpExt.Namespace)); err != nil {
+		logger.Error(err, "Failed to list MCPServerRegistrations")
+	}
+	for _, mcpsr := range serviceTargets.Items {
+		if mcpsr.Spec.TargetRef.Kind == "Service" {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      mcpsr.Name,
+					Namespace: mcpsr.Namespace,
+				},
+			})
+		}
+	}
+
 	// get the gateway this extension targets
 	gatewayNamespace := mcpExt.Spec.TargetRef.Namespace
 	if gatewayNamespace == "" {
@@ -790,17 +2544,16 @@ func (r *MCPReconciler) findMCPServerRegistrationsForMCPGatewayExtension(ctx con
 		if !apierrors.IsNotFound(err) {
 			logger.Error(err, "Failed to get Gateway for MCPGatewayExtension")
 		}
-		return nil
+		return requests
 	}
 
 	// find all HTTPRoutes that have this gateway as a parent
 	httpRouteList := &gatewayv1.HTTPRouteList{}
 	if err := r.List(ctx, httpRouteList); err != nil {
 		logger.Error(err, "Failed to list HTTPRoutes")
-		return nil
+		return requests
 	}
 
-	var requests []reconcile.Request
 	for _, httpRoute := range httpRouteList.Items {
 		// check if this HTTPRoute references the gateway
 		for _, parentRef := range httpRoute.Spec.ParentRefs {
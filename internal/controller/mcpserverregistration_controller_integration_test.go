@@ -5,22 +5,32 @@ package controller
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"sigs.k8s.io/yaml"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/broker"
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
 	"github.com/Kuadrant/mcp-gateway/internal/config"
 )
 
@@ -43,11 +53,33 @@ func (m *mockMCPServerConfigReaderWriter) UpsertMCPServer(ctx context.Context, s
 	return nil
 }
 
+func (m *mockMCPServerConfigReaderWriter) ReadMCPServer(ctx context.Context, serverName string, namespaceName types.NamespacedName) (*config.MCPServer, error) {
+	server, ok := m.upsertedServers[fmt.Sprintf("%s/%s", namespaceName.Namespace, serverName)]
+	if !ok {
+		return nil, nil
+	}
+	return &server, nil
+}
+
 func (m *mockMCPServerConfigReaderWriter) RemoveMCPServer(ctx context.Context, serverName string) error {
 	m.removedServers = append(m.removedServers, serverName)
 	return nil
 }
 
+func (m *mockMCPServerConfigReaderWriter) RemoveMCPServerFromNamespace(ctx context.Context, serverName string, namespaceName types.NamespacedName) error {
+	delete(m.upsertedServers, fmt.Sprintf("%s/%s", namespaceName.Namespace, serverName))
+	return nil
+}
+
+func (m *mockMCPServerConfigReaderWriter) MarkMCPServerDraining(ctx context.Context, serverName string, namespaceName types.NamespacedName) error {
+	key := fmt.Sprintf("%s/%s", namespaceName.Namespace, serverName)
+	if server, ok := m.upsertedServers[key]; ok {
+		server.Draining = true
+		m.upsertedServers[key] = server
+	}
+	return nil
+}
+
 // createTestHTTPRoute creates an HTTPRoute for testing
 func createTestHTTPRoute(name, namespace, hostname, serviceName string, port int32, gatewayName, gatewayNamespace string) *gatewayv1.HTTPRoute {
 	return &gatewayv1.HTTPRoute{
@@ -184,7 +216,44 @@ func deleteTestService(ctx context.Context, name, namespace string) {
 	}
 }
 
+// toolCountFetcher reports every server written to the broker config as ready with the current tool count
+type toolCountFetcher struct {
+	configWriter *mockMCPServerConfigReaderWriter
+	toolCount    int
+}
+
+func (f *toolCountFetcher) ValidateServers(_ context.Context, _ string) (*broker.StatusResponse, error) {
+	response := &broker.StatusResponse{}
+	for _, server := range f.configWriter.upsertedServers {
+		response.Servers = append(response.Servers, upstream.ServerValidationStatus{
+			ID:         string(server.ID()),
+			Name:       server.Name,
+			Ready:      true,
+			TotalTools: f.toolCount,
+			Message:    "ready",
+		})
+	}
+	return response, nil
+}
+
+func (f *toolCountFetcher) ValidateServer(ctx context.Context, namespace, serverID string) (*upstream.ServerValidationStatus, error) {
+	response, _ := f.ValidateServers(ctx, namespace)
+	return findServerStatus(response, serverID)
+}
+
+// unreachableBrokerFetcher fails every status request as if the broker were down
+type unreachableBrokerFetcher struct{}
+
+func (f *unreachableBrokerFetcher) ValidateServers(_ context.Context, _ string) (*broker.StatusResponse, error) {
+	return nil, fmt.Errorf("failed to get status from any broker endpoint: %w", ErrBrokerUnreachable)
+}
+
+func (f *unreachableBrokerFetcher) ValidateServer(_ context.Context, _, _ string) (*upstream.ServerValidationStatus, error) {
+	return nil, fmt.Errorf("failed to get status from any broker endpoint: %w", ErrBrokerUnreachable)
+}
+
 // newMCPServerReconciler creates an MCPReconciler for testing
+
 func newMCPServerReconciler(configWriter *mockMCPServerConfigReaderWriter) *MCPReconciler {
 	return &MCPReconciler{
 		Client:             testIndexedClient,
@@ -326,12 +395,13 @@ var _ = Describe("MCPServerRegistration Controller", func() {
 		})
 	})
 
-	Context("When no valid MCPGatewayExtension exists", func() {
+	Context("When an MCPGatewayExtension is deleted", func() {
 		const (
-			resourceName  = "test-mcpsr-no-ext"
-			httpRouteName = "test-route-no-ext"
-			gatewayName   = "test-gw-no-ext"
-			serviceName   = "test-svc-no-ext"
+			resourceName  = "test-mcpsr-stale"
+			httpRouteName = "test-route-stale"
+			gatewayName   = "test-gw-stale"
+			serviceName   = "test-svc-stale"
+			extensionName = "test-ext-stale"
 		)
 
 		ctx := context.Background()
@@ -342,67 +412,144 @@ var _ = Describe("MCPServerRegistration Controller", func() {
 		}
 
 		BeforeEach(func() {
-			// create gateway (but no MCPGatewayExtension)
 			gw := createTestGateway(gatewayName, "default")
 			Expect(testK8sClient.Create(ctx, gw)).To(Succeed())
 
-			// create service
 			svc := createTestService(serviceName, "default", 8080)
 			Expect(testK8sClient.Create(ctx, svc)).To(Succeed())
 
-			// create HTTPRoute
-			httpRoute := createTestHTTPRoute(httpRouteName, "default", "test.mcp.local", serviceName, 8080, gatewayName, "default")
+			httpRoute := createTestHTTPRoute(httpRouteName, "default", "stale.mcp.local", serviceName, 8080, gatewayName, "default")
 			Expect(testK8sClient.Create(ctx, httpRoute)).To(Succeed())
 
-			// set HTTPRoute as accepted by gateway
 			Eventually(func(g Gomega) {
 				route := &gatewayv1.HTTPRoute{}
 				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: httpRouteName, Namespace: "default"}, route)).To(Succeed())
 				g.Expect(setHTTPRouteAcceptedStatus(ctx, route, gatewayName, "default")).To(Succeed())
 			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			mcpExt := createTestMCPGatewayExtension(extensionName, "default", gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, mcpExt)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				ext := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: extensionName, Namespace: "default"}, ext)).To(Succeed())
+				ext.SetReadyCondition(metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready")
+				g.Expect(testK8sClient.Status().Update(ctx, ext)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
 		})
 
 		AfterEach(func() {
 			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+			forceDeleteTestMCPGatewayExtension(ctx, extensionName, "default")
 			deleteTestHTTPRoute(ctx, httpRouteName, "default")
 			deleteTestService(ctx, serviceName, "default")
 			deleteTestGateway(ctx, gatewayName, "default")
 		})
 
-		It("should set status to NotReady when no MCPGatewayExtension exists", func() {
-			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "test_")
+		It("should remove the stale config from the extension namespace", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "stale_")
 			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
 
 			configWriter := newMockMCPServerConfigReaderWriter()
 			reconciler := newMCPServerReconciler(configWriter)
+			reconciler.MCPExtFinderValidator = &MCPGatewayExtensionValidator{
+				Client:          testIndexedClient,
+				DirectAPIReader: testK8sClient,
+				Logger:          slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
 			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+			configKey := fmt.Sprintf("default/%s", mcpServerName(mcpsr))
 
-			// reconcile multiple times to get past finalizer addition
-			for i := 0; i < 3; i++ {
-				_, _ = reconciler.Reconcile(ctx, reconcile.Request{
-					NamespacedName: mcpsrNamespacedName,
-				})
-				time.Sleep(100 * time.Millisecond)
+			// the broker is not running so status validation fails, the config is still written
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(configWriter.upsertedServers).To(HaveKey(configKey))
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				g.Expect(updated.Status.ConfigNamespaces).To(Equal([]string{"default"}))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			forceDeleteTestMCPGatewayExtension(ctx, extensionName, "default")
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(configWriter.upsertedServers).NotTo(HaveKey(configKey))
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				g.Expect(updated.Status.ConfigNamespaces).To(BeEmpty())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		It("should become NotReady once the extension's deletion cleanup is done", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "stale_")
+			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
+
+			configWriter := newMockMCPServerConfigReaderWriter()
+			reconciler := newMCPServerReconciler(configWriter)
+			reconciler.MCPExtFinderValidator = &MCPGatewayExtensionValidator{
+				Client:          testIndexedClient,
+				DirectAPIReader: testK8sClient,
+				Logger:          slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
 			}
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				g.Expect(updated.Status.ConfigNamespaces).To(Equal([]string{"default"}))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			extensionDeleted := make(chan event.GenericEvent, 1)
+			extReconciler := newTestReconciler()
+			extReconciler.ExtensionDeleted = extensionDeleted
+			extNamespacedName := types.NamespacedName{Name: extensionName, Namespace: "default"}
+			waitForCacheSync(ctx, extNamespacedName)
+			// the first reconcile adds the finalizer
+			_, err := extReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: extNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			ext := &mcpv1alpha1.MCPGatewayExtension{}
+			Expect(testK8sClient.Get(ctx, extNamespacedName, ext)).To(Succeed())
+			Expect(controllerutil.ContainsFinalizer(ext, mcpGatewayFinalizer)).To(BeTrue())
+			Expect(testK8sClient.Delete(ctx, ext)).To(Succeed())
+			Eventually(func(g Gomega) {
+				cached := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testIndexedClient.Get(ctx, extNamespacedName, cached)).To(Succeed())
+				g.Expect(cached.DeletionTimestamp).NotTo(BeNil())
+			}, testTimeout, testRetryInterval).Should(Succeed())
 
+			_, err = extReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: extNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			var deleted event.GenericEvent
+			Expect(extensionDeleted).To(Receive(&deleted))
+
+			requests := reconciler.findMCPServerRegistrationsForMCPGatewayExtension(ctx, deleted.Object)
+			Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: mcpsrNamespacedName}))
 			Eventually(func(g Gomega) {
+				for _, req := range requests {
+					_, err := reconciler.Reconcile(ctx, req)
+					g.Expect(err).NotTo(HaveOccurred())
+				}
 				updated := &mcpv1alpha1.MCPServerRegistration{}
 				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
 				cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
 				g.Expect(cond).NotTo(BeNil())
 				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
-				g.Expect(cond.Message).To(ContainSubstring("no valid mcpgatewayextensions"))
+				g.Expect(cond.Message).To(ContainSubstring("no valid mcpgatewayextensions configured"))
+				g.Expect(updated.Status.ConfigNamespaces).To(BeEmpty())
 			}, testTimeout, testRetryInterval).Should(Succeed())
-
-			// verify no config
-			Expect(configWriter.upsertedServers).To(BeEmpty())
 		})
 	})
 
-	Context("When HTTPRoute does not exist", func() {
+	Context("When an MCPGatewayExtension becomes Ready", func() {
 		const (
-			resourceName  = "test-mcpsr-no-route"
-			httpRouteName = "nonexistent-route"
+			resourceName  = "test-mcpsr-ext-ready"
+			httpRouteName = "test-route-ext-ready"
+			gatewayName   = "test-gw-ext-ready"
+			serviceName   = "test-svc-ext-ready"
+			extensionName = "test-ext-ext-ready"
 		)
 
 		ctx := context.Background()
@@ -411,43 +558,125 @@ var _ = Describe("MCPServerRegistration Controller", func() {
 			Name:      resourceName,
 			Namespace: "default",
 		}
+		extNamespacedName := types.NamespacedName{
+			Name:      extensionName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			gw := createTestGateway(gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, gw)).To(Succeed())
+
+			svc := createTestService(serviceName, "default", 8080)
+			Expect(testK8sClient.Create(ctx, svc)).To(Succeed())
+
+			httpRoute := createTestHTTPRoute(httpRouteName, "default", "ext-ready.mcp.local", serviceName, 8080, gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, httpRoute)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				route := &gatewayv1.HTTPRoute{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: httpRouteName, Namespace: "default"}, route)).To(Succeed())
+				g.Expect(setHTTPRouteAcceptedStatus(ctx, route, gatewayName, "default")).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			mcpExt := createTestMCPGatewayExtension(extensionName, "default", gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, mcpExt)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				ext := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, extNamespacedName, ext)).To(Succeed())
+				ext.SetReadyCondition(metav1.ConditionFalse, mcpv1alpha1.ConditionReasonDeploymentNotReady, "broker-router deployment is not ready")
+				g.Expect(testK8sClient.Status().Update(ctx, ext)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
 
 		AfterEach(func() {
 			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+			forceDeleteTestMCPGatewayExtension(ctx, extensionName, "default")
+			deleteTestHTTPRoute(ctx, httpRouteName, "default")
+			deleteTestService(ctx, serviceName, "default")
+			deleteTestGateway(ctx, gatewayName, "default")
 		})
 
-		It("should set status to NotReady when HTTPRoute does not exist", func() {
-			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "test_")
+		It("should promptly re-check the dependent registrations", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "ready_")
 			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
 
 			configWriter := newMockMCPServerConfigReaderWriter()
 			reconciler := newMCPServerReconciler(configWriter)
-			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
-
-			// reconcile multiple times to get past finalizer addition
-			for i := 0; i < 3; i++ {
-				_, _ = reconciler.Reconcile(ctx, reconcile.Request{
-					NamespacedName: mcpsrNamespacedName,
-				})
-				time.Sleep(100 * time.Millisecond)
+			reconciler.MCPExtFinderValidator = &MCPGatewayExtensionValidator{
+				Client:          testIndexedClient,
+				DirectAPIReader: testK8sClient,
+				Logger:          slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
 			}
+			// the broker has only just started so it can't report the server yet
+			reconciler.StatusFetcher = &unreachableBrokerFetcher{}
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
 
 			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
 				updated := &mcpv1alpha1.MCPServerRegistration{}
 				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
 				cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
 				g.Expect(cond).NotTo(BeNil())
-				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(cond.Message).To(ContainSubstring("no valid mcpgatewayextensions configured"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+			Expect(configWriter.upsertedServers).To(BeEmpty())
+
+			// the registration backed off while it waited on the broker earlier
+			for range 5 {
+				reconciler.nextConfigWaitRequeue(mcpsrNamespacedName, mcpsr.Generation, "")
+			}
+
+			notReady := &mcpv1alpha1.MCPGatewayExtension{}
+			Expect(testIndexedClient.Get(ctx, extNamespacedName, notReady)).To(Succeed())
+			Eventually(func(g Gomega) {
+				ext := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, extNamespacedName, ext)).To(Succeed())
+				ext.SetReadyCondition(metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready")
+				g.Expect(testK8sClient.Status().Update(ctx, ext)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+			ready := &mcpv1alpha1.MCPGatewayExtension{}
+			Eventually(func(g Gomega) {
+				g.Expect(testIndexedClient.Get(ctx, extNamespacedName, ready)).To(Succeed())
+				g.Expect(meta.IsStatusConditionTrue(ready.Status.Conditions, mcpv1alpha1.ConditionTypeReady)).To(BeTrue())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+			defer queue.ShutDown()
+			reconciler.mcpGatewayExtensionHandler().Update(ctx, event.UpdateEvent{ObjectOld: notReady, ObjectNew: ready}, queue)
+			_, waiting := reconciler.configWaits.Load(mcpsrNamespacedName)
+			Expect(waiting).To(BeFalse())
+			var requests []reconcile.Request
+			for queue.Len() > 0 {
+				req, _ := queue.Get()
+				queue.Done(req)
+				requests = append(requests, req)
+			}
+			Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: mcpsrNamespacedName}))
+
+			// the config is written and the broker checked again after the base delay rather than the backed off one
+			configKey := fmt.Sprintf("default/%s", mcpServerName(mcpsr))
+			Eventually(func(g Gomega) {
+				result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(configWriter.upsertedServers).To(HaveKey(configKey))
+				value, ok := reconciler.configWaits.Load(mcpsrNamespacedName)
+				g.Expect(ok).To(BeTrue())
+				g.Expect(value.(*configWait).attempts).To(Equal(1))
+				g.Expect(result.RequeueAfter).To(BeNumerically("<", 2*configWaitBaseDelay))
 			}, testTimeout, testRetryInterval).Should(Succeed())
 		})
 	})
 
-	Context("When HTTPRoute has no accepted gateways", func() {
+	Context("When the target HTTPRoute changes", func() {
 		const (
-			resourceName  = "test-mcpsr-not-accepted"
-			httpRouteName = "test-route-not-accepted"
-			gatewayName   = "test-gw-not-accepted"
-			serviceName   = "test-svc-not-accepted"
+			resourceName  = "test-mcpsr-retarget"
+			oldRouteName  = "test-route-retarget-old"
+			newRouteName  = "test-route-retarget-new"
+			gatewayName   = "test-gw-retarget"
+			serviceName   = "test-svc-retarget"
+			extensionName = "test-ext-retarget"
 		)
 
 		ctx := context.Background()
@@ -458,50 +687,1016 @@ var _ = Describe("MCPServerRegistration Controller", func() {
 		}
 
 		BeforeEach(func() {
-			// create gateway
 			gw := createTestGateway(gatewayName, "default")
 			Expect(testK8sClient.Create(ctx, gw)).To(Succeed())
 
-			// create service
 			svc := createTestService(serviceName, "default", 8080)
 			Expect(testK8sClient.Create(ctx, svc)).To(Succeed())
 
-			// create HTTPRoute (without setting accepted status)
-			httpRoute := createTestHTTPRoute(httpRouteName, "default", "test.mcp.local", serviceName, 8080, gatewayName, "default")
-			Expect(testK8sClient.Create(ctx, httpRoute)).To(Succeed())
+			for route, hostname := range map[string]string{oldRouteName: "old.mcp.local", newRouteName: "new.mcp.local"} {
+				httpRoute := createTestHTTPRoute(route, "default", hostname, serviceName, 8080, gatewayName, "default")
+				Expect(testK8sClient.Create(ctx, httpRoute)).To(Succeed())
+				Eventually(func(g Gomega) {
+					updated := &gatewayv1.HTTPRoute{}
+					g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: route, Namespace: "default"}, updated)).To(Succeed())
+					g.Expect(setHTTPRouteAcceptedStatus(ctx, updated, gatewayName, "default")).To(Succeed())
+				}, testTimeout, testRetryInterval).Should(Succeed())
+			}
+
+			mcpExt := createTestMCPGatewayExtension(extensionName, "default", gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, mcpExt)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				ext := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: extensionName, Namespace: "default"}, ext)).To(Succeed())
+				ext.SetReadyCondition(metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready")
+				g.Expect(testK8sClient.Status().Update(ctx, ext)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
 		})
 
 		AfterEach(func() {
 			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
-			deleteTestHTTPRoute(ctx, httpRouteName, "default")
+			forceDeleteTestMCPGatewayExtension(ctx, extensionName, "default")
+			deleteTestHTTPRoute(ctx, oldRouteName, "default")
+			deleteTestHTTPRoute(ctx, newRouteName, "default")
 			deleteTestService(ctx, serviceName, "default")
 			deleteTestGateway(ctx, gatewayName, "default")
+			secret := &corev1.Secret{}
+			if err := testK8sClient.Get(ctx, config.NamespaceName("default"), secret); err == nil {
+				_ = testK8sClient.Delete(ctx, secret)
+			}
 		})
 
-		It("should set status to NotReady when no gateways have accepted the HTTPRoute", func() {
-			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "test_")
+		It("should replace the config of the previous target", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", oldRouteName, "retarget_")
 			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
 
-			configWriter := newMockMCPServerConfigReaderWriter()
-			reconciler := newMCPServerReconciler(configWriter)
+			reconciler := newMCPServerReconciler(newMockMCPServerConfigReaderWriter())
+			reconciler.ConfigReaderWriter = &config.SecretReaderWriter{
+				Client: testK8sClient,
+				Scheme: testK8sClient.Scheme(),
+				Logger: slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
+			reconciler.MCPExtFinderValidator = &MCPGatewayExtensionValidator{
+				Client:          testIndexedClient,
+				DirectAPIReader: testK8sClient,
+				Logger:          slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
 			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
 
-			// reconcile multiple times to get past finalizer addition
-			for i := 0; i < 3; i++ {
-				_, _ = reconciler.Reconcile(ctx, reconcile.Request{
-					NamespacedName: mcpsrNamespacedName,
-				})
-				time.Sleep(100 * time.Millisecond)
+			// configuredServers returns the servers for this registration in the config secret
+			configuredServers := func(g Gomega) []config.MCPServer {
+				secret := &corev1.Secret{}
+				g.Expect(testK8sClient.Get(ctx, config.NamespaceName("default"), secret)).To(Succeed())
+				brokerConfig := &config.BrokerConfig{}
+				g.Expect(yaml.Unmarshal(secret.Data["config.yaml"], brokerConfig)).To(Succeed())
+				var servers []config.MCPServer
+				for _, server := range brokerConfig.Servers {
+					if server.Name == mcpServerName(mcpsr) {
+						servers = append(servers, server)
+					}
+				}
+				return servers
 			}
 
+			// the broker is not running so status validation fails, the config is still written
+			var previousID string
 			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				servers := configuredServers(g)
+				g.Expect(servers).To(HaveLen(1))
+				g.Expect(servers[0].Hostname).To(Equal("old.mcp.local"))
 				updated := &mcpv1alpha1.MCPServerRegistration{}
 				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
-				cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
-				g.Expect(cond).NotTo(BeNil())
-				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
-				g.Expect(cond.Message).To(ContainSubstring("no valid gateways"))
+				g.Expect(updated.Status.ServerID).To(Equal(string(servers[0].ID())))
+				previousID = updated.Status.ServerID
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				updated.Spec.TargetRef.Name = newRouteName
+				g.Expect(testK8sClient.Update(ctx, updated)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				cached := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testIndexedClient.Get(ctx, mcpsrNamespacedName, cached)).To(Succeed())
+				g.Expect(cached.Spec.TargetRef.Name).To(Equal(newRouteName))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				servers := configuredServers(g)
+				g.Expect(servers).To(HaveLen(1))
+				g.Expect(servers[0].Hostname).To(Equal("new.mcp.local"))
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				g.Expect(updated.Status.ServerID).To(Equal(string(servers[0].ID())))
+				g.Expect(updated.Status.ServerID).NotTo(Equal(previousID))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When the cache only holds credential secrets", func() {
+		const (
+			resourceName  = "test-mcpsr-labeled"
+			httpRouteName = "test-route-labeled"
+			gatewayName   = "test-gw-labeled"
+			serviceName   = "test-svc-labeled"
+			extensionName = "test-ext-labeled"
+			secretName    = "test-labeled-credential"
+		)
+
+		ctx := context.Background()
+
+		mcpsrNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			gw := createTestGateway(gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, gw)).To(Succeed())
+
+			svc := createTestService(serviceName, "default", 8080)
+			Expect(testK8sClient.Create(ctx, svc)).To(Succeed())
+
+			httpRoute := createTestHTTPRoute(httpRouteName, "default", "labeled.mcp.local", serviceName, 8080, gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, httpRoute)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				route := &gatewayv1.HTTPRoute{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: httpRouteName, Namespace: "default"}, route)).To(Succeed())
+				g.Expect(setHTTPRouteAcceptedStatus(ctx, route, gatewayName, "default")).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			mcpExt := createTestMCPGatewayExtension(extensionName, "default", gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, mcpExt)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				ext := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: extensionName, Namespace: "default"}, ext)).To(Succeed())
+				ext.SetReadyCondition(metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready")
+				g.Expect(testK8sClient.Status().Update(ctx, ext)).To(Succeed())
 			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			Expect(testK8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secretName,
+					Namespace: "default",
+					Labels:    map[string]string{CredentialSecretLabel: CredentialSecretValue},
+				},
+				Data: map[string][]byte{"token": []byte("first")},
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+			forceDeleteTestMCPGatewayExtension(ctx, extensionName, "default")
+			deleteTestHTTPRoute(ctx, httpRouteName, "default")
+			deleteTestService(ctx, serviceName, "default")
+			deleteTestGateway(ctx, gatewayName, "default")
+			for _, name := range []string{secretName, config.NamespaceName("default").Name} {
+				secret := &corev1.Secret{}
+				if err := testK8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, secret); err == nil {
+					_ = testK8sClient.Delete(ctx, secret)
+				}
+			}
 		})
+
+		It("should write the credential to the unlabeled config secret", func() {
+			// the same secret cache the controller uses with --cache-credential-secrets-only
+			secretCache, err := cache.New(cfg, cache.Options{
+				Scheme: testK8sClient.Scheme(),
+				ByObject: map[client.Object]cache.ByObject{
+					&corev1.Secret{}: {
+						Label: labels.SelectorFromSet(labels.Set{CredentialSecretLabel: CredentialSecretValue}),
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			cacheCtx, stopCache := context.WithCancel(ctx)
+			DeferCleanup(stopCache)
+			go func() {
+				defer GinkgoRecover()
+				Expect(secretCache.Start(cacheCtx)).To(Succeed())
+			}()
+			Expect(secretCache.WaitForCacheSync(ctx)).To(BeTrue())
+			cachedClient, err := client.New(cfg, client.Options{
+				Scheme: testK8sClient.Scheme(),
+				Cache:  &client.CacheOptions{Reader: secretCache},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			// the config secret already exists but is filtered out of the cache
+			Expect(testK8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      config.NamespaceName("default").Name,
+					Namespace: "default",
+					Labels:    map[string]string{config.AggregatedSecretLabel: "true"},
+				},
+				StringData: map[string]string{"config.yaml": "servers: []\nvirtualServers: []\n"},
+			})).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(cachedClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, &corev1.Secret{})).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+			err = cachedClient.Get(ctx, config.NamespaceName("default"), &corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "labeled_")
+			mcpsr.Spec.CredentialRef = &mcpv1alpha1.SecretReference{Name: secretName, Key: "token"}
+			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
+
+			reconciler := newMCPServerReconciler(newMockMCPServerConfigReaderWriter())
+			reconciler.ConfigReaderWriter = &config.SecretReaderWriter{
+				Client: cachedClient,
+				Reader: testK8sClient,
+				Scheme: testK8sClient.Scheme(),
+				Logger: slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
+			reconciler.MCPExtFinderValidator = &MCPGatewayExtensionValidator{
+				Client:          testIndexedClient,
+				DirectAPIReader: testK8sClient,
+				Logger:          slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+
+			// configuredCredential returns the credential of this registration in the config secret
+			configuredCredential := func(g Gomega) string {
+				secret := &corev1.Secret{}
+				g.Expect(testK8sClient.Get(ctx, config.NamespaceName("default"), secret)).To(Succeed())
+				brokerConfig := &config.BrokerConfig{}
+				g.Expect(yaml.Unmarshal(secret.Data["config.yaml"], brokerConfig)).To(Succeed())
+				for _, server := range brokerConfig.Servers {
+					if server.Name == mcpServerName(mcpsr) {
+						return server.Credential
+					}
+				}
+				return ""
+			}
+
+			// the broker is not running so status validation fails, the config is still written
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(configuredCredential(g)).To(Equal("first"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			// a rotated credential is seen by the filtered cache and written on the next reconcile
+			Eventually(func(g Gomega) {
+				secret := &corev1.Secret{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+				secret.Data["token"] = []byte("second")
+				g.Expect(testK8sClient.Update(ctx, secret)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				secret := &corev1.Secret{}
+				g.Expect(cachedClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: "default"}, secret)).To(Succeed())
+				g.Expect(string(secret.Data["token"])).To(Equal("second"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(configuredCredential(g)).To(Equal("second"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When a backend changes its tools without a resource change", func() {
+		const (
+			resourceName  = "test-mcpsr-refresh"
+			httpRouteName = "test-route-refresh"
+			gatewayName   = "test-gw-refresh"
+			serviceName   = "test-svc-refresh"
+			extensionName = "test-ext-refresh"
+		)
+
+		ctx := context.Background()
+
+		mcpsrNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			gw := createTestGateway(gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, gw)).To(Succeed())
+
+			svc := createTestService(serviceName, "default", 8080)
+			Expect(testK8sClient.Create(ctx, svc)).To(Succeed())
+
+			httpRoute := createTestHTTPRoute(httpRouteName, "default", "refresh.mcp.local", serviceName, 8080, gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, httpRoute)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				route := &gatewayv1.HTTPRoute{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: httpRouteName, Namespace: "default"}, route)).To(Succeed())
+				g.Expect(setHTTPRouteAcceptedStatus(ctx, route, gatewayName, "default")).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			mcpExt := createTestMCPGatewayExtension(extensionName, "default", gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, mcpExt)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				ext := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: extensionName, Namespace: "default"}, ext)).To(Succeed())
+				ext.SetReadyCondition(metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready")
+				g.Expect(testK8sClient.Status().Update(ctx, ext)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+			forceDeleteTestMCPGatewayExtension(ctx, extensionName, "default")
+			deleteTestHTTPRoute(ctx, httpRouteName, "default")
+			deleteTestService(ctx, serviceName, "default")
+			deleteTestGateway(ctx, gatewayName, "default")
+		})
+
+		It("should keep discoveredTools current by polling the broker", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "refresh_")
+			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
+
+			configWriter := newMockMCPServerConfigReaderWriter()
+			fetcher := &toolCountFetcher{configWriter: configWriter, toolCount: 3}
+			reconciler := newMCPServerReconciler(configWriter)
+			reconciler.MCPExtFinderValidator = &MCPGatewayExtensionValidator{
+				Client:          testIndexedClient,
+				DirectAPIReader: testK8sClient,
+				Logger:          slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
+			reconciler.StatusFetcher = fetcher
+			reconciler.StatusRefreshInterval = time.Minute
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+
+			discoveredTools := func(g Gomega) int {
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "Ready")).To(BeTrue())
+				return updated.Status.DiscoveredTools
+			}
+
+			// a ready registration is requeued so the broker is polled again without any resource change
+			Eventually(func(g Gomega) {
+				result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result.RequeueAfter).To(Equal(time.Minute))
+				g.Expect(discoveredTools(g)).To(Equal(3))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			// the backend adds tools, the refresh picks up the new count
+			fetcher.toolCount = 5
+			Eventually(func(g Gomega) {
+				result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result.RequeueAfter).To(Equal(time.Minute))
+				g.Expect(discoveredTools(g)).To(Equal(5))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		It("should be accepted but not ready while the broker is unreachable", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "refresh_")
+			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
+
+			configWriter := newMockMCPServerConfigReaderWriter()
+			reconciler := newMCPServerReconciler(configWriter)
+			reconciler.MCPExtFinderValidator = &MCPGatewayExtensionValidator{
+				Client:          testIndexedClient,
+				DirectAPIReader: testK8sClient,
+				Logger:          slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
+			reconciler.StatusFetcher = &unreachableBrokerFetcher{}
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(configWriter.upsertedServers).NotTo(BeEmpty())
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, mcpv1alpha1.ConditionTypeAccepted)).To(BeTrue())
+				ready := meta.FindStatusCondition(updated.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+				g.Expect(ready).NotTo(BeNil())
+				g.Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(ready.Reason).To(Equal(ReasonBrokerUnreachable))
+				g.Expect(ready.Message).To(ContainSubstring("broker unreachable"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When several registrations share an HTTPRoute", func() {
+		const (
+			firstName     = "test-mcpsr-shared-first"
+			secondName    = "test-mcpsr-shared-second"
+			httpRouteName = "test-route-shared"
+			gatewayName   = "test-gw-shared"
+			serviceName   = "test-svc-shared"
+			extensionName = "test-ext-shared"
+		)
+
+		ctx := context.Background()
+		routeNamespacedName := types.NamespacedName{Name: httpRouteName, Namespace: "default"}
+
+		BeforeEach(func() {
+			Expect(testK8sClient.Create(ctx, createTestGateway(gatewayName, "default"))).To(Succeed())
+			Expect(testK8sClient.Create(ctx, createTestService(serviceName, "default", 8080))).To(Succeed())
+			Expect(testK8sClient.Create(ctx, createTestHTTPRoute(httpRouteName, "default", "shared.mcp.local", serviceName, 8080, gatewayName, "default"))).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				route := &gatewayv1.HTTPRoute{}
+				g.Expect(testK8sClient.Get(ctx, routeNamespacedName, route)).To(Succeed())
+				g.Expect(setHTTPRouteAcceptedStatus(ctx, route, gatewayName, "default")).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			Expect(testK8sClient.Create(ctx, createTestMCPGatewayExtension(extensionName, "default", gatewayName, "default"))).To(Succeed())
+			Eventually(func(g Gomega) {
+				ext := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: extensionName, Namespace: "default"}, ext)).To(Succeed())
+				ext.SetReadyCondition(metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready")
+				g.Expect(testK8sClient.Status().Update(ctx, ext)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			forceDeleteTestMCPServerRegistration(ctx, firstName, "default")
+			forceDeleteTestMCPServerRegistration(ctx, secondName, "default")
+			forceDeleteTestMCPGatewayExtension(ctx, extensionName, "default")
+			deleteTestHTTPRoute(ctx, httpRouteName, "default")
+			deleteTestService(ctx, serviceName, "default")
+			deleteTestGateway(ctx, gatewayName, "default")
+		})
+
+		It("should remove the Programmed condition only after the last registration is deleted", func() {
+			configWriter := newMockMCPServerConfigReaderWriter()
+			reconciler := newMCPServerReconciler(configWriter)
+			reconciler.MCPExtFinderValidator = &MCPGatewayExtensionValidator{
+				Client:          testIndexedClient,
+				DirectAPIReader: testK8sClient,
+				Logger:          slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
+			reconciler.StatusFetcher = &toolCountFetcher{configWriter: configWriter, toolCount: 1}
+
+			routeProgrammed := func(g Gomega, c client.Client) bool {
+				route := &gatewayv1.HTTPRoute{}
+				g.Expect(c.Get(ctx, routeNamespacedName, route)).To(Succeed())
+				g.Expect(route.Status.Parents).NotTo(BeEmpty())
+				return meta.IsStatusConditionTrue(route.Status.Parents[0].Conditions, "Programmed")
+			}
+			deleteRegistration := func(name string) {
+				nn := types.NamespacedName{Name: name, Namespace: "default"}
+				resource := &mcpv1alpha1.MCPServerRegistration{}
+				Expect(testK8sClient.Get(ctx, nn, resource)).To(Succeed())
+				Expect(testK8sClient.Delete(ctx, resource)).To(Succeed())
+				Eventually(func(g Gomega) {
+					cached := &mcpv1alpha1.MCPServerRegistration{}
+					g.Expect(testIndexedClient.Get(ctx, nn, cached)).To(Succeed())
+					g.Expect(cached.DeletionTimestamp).NotTo(BeNil())
+				}, testTimeout, testRetryInterval).Should(Succeed())
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(func(g Gomega) {
+					g.Expect(errors.IsNotFound(testK8sClient.Get(ctx, nn, resource))).To(BeTrue())
+				}, testTimeout, testRetryInterval).Should(Succeed())
+			}
+
+			for _, name := range []string{firstName, secondName} {
+				Expect(testK8sClient.Create(ctx, createTestMCPServerRegistration(name, "default", httpRouteName, name+"_"))).To(Succeed())
+				nn := types.NamespacedName{Name: name, Namespace: "default"}
+				waitForMCPServerRegistrationCacheSync(ctx, nn)
+				Eventually(func(g Gomega) {
+					_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+					g.Expect(err).NotTo(HaveOccurred())
+					updated := &mcpv1alpha1.MCPServerRegistration{}
+					g.Expect(testK8sClient.Get(ctx, nn, updated)).To(Succeed())
+					g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "Ready")).To(BeTrue())
+				}, testTimeout, testRetryInterval).Should(Succeed())
+			}
+			// the cleanup lists programmed routes from the cache
+			Eventually(func(g Gomega) {
+				g.Expect(routeProgrammed(g, testIndexedClient)).To(BeTrue())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			deleteRegistration(firstName)
+			Consistently(func(g Gomega) {
+				g.Expect(routeProgrammed(g, testK8sClient)).To(BeTrue())
+			}, time.Second, testRetryInterval).Should(Succeed())
+
+			deleteRegistration(secondName)
+			Eventually(func(g Gomega) {
+				g.Expect(routeProgrammed(g, testK8sClient)).To(BeFalse())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When an MCPVirtualServer references the registration's tools", func() {
+		const (
+			resourceName      = "test-mcpsr-vs-ref"
+			virtualServerName = "test-vs-ref"
+		)
+
+		ctx := context.Background()
+
+		mcpsrNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		AfterEach(func() {
+			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+			resource := &mcpv1alpha1.MCPVirtualServer{}
+			if err := testK8sClient.Get(ctx, types.NamespacedName{Name: virtualServerName, Namespace: "default"}, resource); err == nil {
+				Expect(client.IgnoreNotFound(testK8sClient.Delete(ctx, resource))).To(Succeed())
+			}
+		})
+
+		It("should list the dependent virtual server in status", func() {
+			Expect(testK8sClient.Create(ctx, createTestMCPServerRegistration(resourceName, "default", "unused-route", "ref_"))).To(Succeed())
+			Expect(testK8sClient.Create(ctx, &mcpv1alpha1.MCPVirtualServer{
+				ObjectMeta: metav1.ObjectMeta{Name: virtualServerName, Namespace: "default"},
+				Spec:       mcpv1alpha1.MCPVirtualServerSpec{Tools: []string{"ref_lookup", "other_tool"}},
+			})).To(Succeed())
+
+			reconciler := newMCPServerReconciler(newMockMCPServerConfigReaderWriter())
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				g.Expect(updated.Status.VirtualServers).To(Equal([]string{"default/" + virtualServerName}))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			// the virtual server no longer references the registration's tools
+			Eventually(func(g Gomega) {
+				vs := &mcpv1alpha1.MCPVirtualServer{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: virtualServerName, Namespace: "default"}, vs)).To(Succeed())
+				vs.Spec.Tools = []string{"other_tool"}
+				g.Expect(testK8sClient.Update(ctx, vs)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				g.Expect(updated.Status.VirtualServers).To(BeEmpty())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When the backend Service is in another namespace", func() {
+		const (
+			resourceName     = "test-mcpsr-backend-grant"
+			httpRouteName    = "test-route-backend-grant"
+			gatewayName      = "test-gw-backend-grant"
+			serviceName      = "test-svc-backend-grant"
+			extensionName    = "test-ext-backend-grant"
+			grantName        = "test-backend-grant"
+			backendNamespace = "backend-grant-ns"
+		)
+
+		ctx := context.Background()
+
+		mcpsrNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		backendGrant := func() *gatewayv1beta1.ReferenceGrant {
+			return &gatewayv1beta1.ReferenceGrant{
+				ObjectMeta: metav1.ObjectMeta{Name: grantName, Namespace: backendNamespace},
+				Spec: gatewayv1beta1.ReferenceGrantSpec{
+					From: []gatewayv1beta1.ReferenceGrantFrom{
+						{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: "default"},
+					},
+					To: []gatewayv1beta1.ReferenceGrantTo{
+						{Group: "", Kind: "Service"},
+					},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			createTestNamespace(ctx, backendNamespace)
+
+			gw := createTestGateway(gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, gw)).To(Succeed())
+
+			svc := createTestService(serviceName, backendNamespace, 8080)
+			Expect(testK8sClient.Create(ctx, svc)).To(Succeed())
+
+			httpRoute := createTestHTTPRoute(httpRouteName, "default", "backend-grant.mcp.local", serviceName, 8080, gatewayName, "default")
+			httpRoute.Spec.Rules[0].BackendRefs[0].Namespace = ptr.To(gatewayv1.Namespace(backendNamespace))
+			Expect(testK8sClient.Create(ctx, httpRoute)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				route := &gatewayv1.HTTPRoute{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: httpRouteName, Namespace: "default"}, route)).To(Succeed())
+				g.Expect(setHTTPRouteAcceptedStatus(ctx, route, gatewayName, "default")).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			mcpExt := createTestMCPGatewayExtension(extensionName, "default", gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, mcpExt)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				ext := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: extensionName, Namespace: "default"}, ext)).To(Succeed())
+				ext.SetReadyCondition(metav1.ConditionTrue, mcpv1alpha1.ConditionReasonSuccess, "ready")
+				g.Expect(testK8sClient.Status().Update(ctx, ext)).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+			forceDeleteTestMCPGatewayExtension(ctx, extensionName, "default")
+			_ = client.IgnoreNotFound(deleteTestReferenceGrant(ctx, grantName, backendNamespace))
+			deleteTestHTTPRoute(ctx, httpRouteName, "default")
+			deleteTestService(ctx, serviceName, backendNamespace)
+			deleteTestGateway(ctx, gatewayName, "default")
+		})
+
+		It("should re-evaluate the backend reference when the ReferenceGrant changes", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "grant_")
+			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
+
+			configWriter := newMockMCPServerConfigReaderWriter()
+			reconciler := newMCPServerReconciler(configWriter)
+			reconciler.MCPExtFinderValidator = &MCPGatewayExtensionValidator{
+				Client:          testIndexedClient,
+				DirectAPIReader: testK8sClient,
+				Logger:          slog.New(slog.NewTextHandler(GinkgoWriter, nil)),
+			}
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+			configKey := fmt.Sprintf("default/%s", mcpServerName(mcpsr))
+
+			notPermitted := func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(configWriter.upsertedServers).NotTo(HaveKey(configKey))
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				condition := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Reason).To(Equal(ReasonBackendRefGrantRequired))
+				g.Expect(condition.Message).To(ContainSubstring("ReferenceGrant"))
+			}
+
+			By("rejecting the backend reference without a ReferenceGrant")
+			Eventually(notPermitted, testTimeout, testRetryInterval).Should(Succeed())
+
+			By("enqueueing the registration and writing its config once the ReferenceGrant exists")
+			Expect(testK8sClient.Create(ctx, backendGrant())).To(Succeed())
+			Eventually(func(g Gomega) {
+				grant := &gatewayv1beta1.ReferenceGrant{}
+				g.Expect(testIndexedClient.Get(ctx, types.NamespacedName{Name: grantName, Namespace: backendNamespace}, grant)).To(Succeed())
+				g.Expect(reconciler.findMCPServerRegistrationsForReferenceGrant(ctx, grant)).To(ContainElement(reconcile.Request{NamespacedName: mcpsrNamespacedName}))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(configWriter.upsertedServers).To(HaveKey(configKey))
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				condition := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Reason).NotTo(Equal(ReasonBackendRefGrantRequired))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			By("withdrawing the config when the ReferenceGrant is removed")
+			Expect(deleteTestReferenceGrant(ctx, grantName, backendNamespace)).To(Succeed())
+			Eventually(notPermitted, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When the targeted Service has no ready endpoints", func() {
+		const (
+			resourceName = "test-mcpsr-no-endpoints"
+			serviceName  = "test-svc-no-endpoints"
+			extName      = "test-ext-no-endpoints"
+			sliceName    = "test-svc-no-endpoints-abc12"
+		)
+
+		ctx := context.Background()
+
+		mcpsrNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			svc := createTestService(serviceName, "default", 8080)
+			Expect(testK8sClient.Create(ctx, svc)).To(Succeed())
+
+			mcpExt := createTestMCPGatewayExtension(extName, "default", "test-gw-no-endpoints", "default")
+			Expect(testK8sClient.Create(ctx, mcpExt)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+			forceDeleteTestMCPGatewayExtension(ctx, extName, "default")
+			_ = testK8sClient.Delete(ctx, &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{Name: sliceName, Namespace: "default"},
+			})
+			deleteTestService(ctx, serviceName, "default")
+		})
+
+		It("should not be ready until the Service has a ready endpoint", func() {
+			mcpsr := &mcpv1alpha1.MCPServerRegistration{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+					TargetRef:  mcpv1alpha1.TargetReference{Kind: "Service", Name: serviceName},
+					ToolPrefix: "endpoints_",
+					Path:       "/mcp",
+				},
+			}
+			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
+
+			configWriter := newMockMCPServerConfigReaderWriter()
+			reconciler := newMCPServerReconciler(configWriter)
+			reconciler.StatusFetcher = &toolCountFetcher{configWriter: configWriter, toolCount: 2}
+			reconciler.StatusRefreshInterval = time.Minute
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+
+			// the config is written so the broker picks the server up once a pod is ready
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				g.Expect(configWriter.upsertedServers).NotTo(BeEmpty())
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				ready := meta.FindStatusCondition(updated.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+				g.Expect(ready).NotTo(BeNil())
+				g.Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(ready.Reason).To(Equal(ReasonNoReadyEndpoints))
+				g.Expect(ready.Message).To(Equal("backend has no ready endpoints"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			endpointSlice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      sliceName,
+					Namespace: "default",
+					Labels:    map[string]string{discoveryv1.LabelServiceName: serviceName},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{{
+					Addresses:  []string{"10.0.0.10"},
+					Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)},
+				}},
+			}
+			Expect(testK8sClient.Create(ctx, endpointSlice)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: mcpsrNamespacedName})
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				g.Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, mcpv1alpha1.ConditionTypeReady)).To(BeTrue())
+				g.Expect(updated.Status.DiscoveredTools).To(Equal(2))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When no valid MCPGatewayExtension exists", func() {
+		const (
+			resourceName  = "test-mcpsr-no-ext"
+			httpRouteName = "test-route-no-ext"
+			gatewayName   = "test-gw-no-ext"
+			serviceName   = "test-svc-no-ext"
+		)
+
+		ctx := context.Background()
+
+		mcpsrNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			// create gateway (but no MCPGatewayExtension)
+			gw := createTestGateway(gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, gw)).To(Succeed())
+
+			// create service
+			svc := createTestService(serviceName, "default", 8080)
+			Expect(testK8sClient.Create(ctx, svc)).To(Succeed())
+
+			// create HTTPRoute
+			httpRoute := createTestHTTPRoute(httpRouteName, "default", "test.mcp.local", serviceName, 8080, gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, httpRoute)).To(Succeed())
+
+			// set HTTPRoute as accepted by gateway
+			Eventually(func(g Gomega) {
+				route := &gatewayv1.HTTPRoute{}
+				g.Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: httpRouteName, Namespace: "default"}, route)).To(Succeed())
+				g.Expect(setHTTPRouteAcceptedStatus(ctx, route, gatewayName, "default")).To(Succeed())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+			deleteTestHTTPRoute(ctx, httpRouteName, "default")
+			deleteTestService(ctx, serviceName, "default")
+			deleteTestGateway(ctx, gatewayName, "default")
+		})
+
+		It("should set status to NotReady when no MCPGatewayExtension exists", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "test_")
+			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
+
+			configWriter := newMockMCPServerConfigReaderWriter()
+			reconciler := newMCPServerReconciler(configWriter)
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+
+			// reconcile multiple times to get past finalizer addition
+			for i := 0; i < 3; i++ {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: mcpsrNamespacedName,
+				})
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			Eventually(func(g Gomega) {
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(cond.Message).To(ContainSubstring("no valid mcpgatewayextensions"))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			// verify no config
+			Expect(configWriter.upsertedServers).To(BeEmpty())
+		})
+	})
+
+	Context("When HTTPRoute does not exist", func() {
+		const (
+			resourceName  = "test-mcpsr-no-route"
+			httpRouteName = "nonexistent-route"
+		)
+
+		ctx := context.Background()
+
+		mcpsrNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		AfterEach(func() {
+			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+		})
+
+		It("should set status to NotReady when HTTPRoute does not exist", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "test_")
+			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
+
+			configWriter := newMockMCPServerConfigReaderWriter()
+			reconciler := newMCPServerReconciler(configWriter)
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+
+			// reconcile multiple times to get past finalizer addition
+			for i := 0; i < 3; i++ {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: mcpsrNamespacedName,
+				})
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			Eventually(func(g Gomega) {
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When HTTPRoute has no accepted gateways", func() {
+		const (
+			resourceName  = "test-mcpsr-not-accepted"
+			httpRouteName = "test-route-not-accepted"
+			gatewayName   = "test-gw-not-accepted"
+			serviceName   = "test-svc-not-accepted"
+		)
+
+		ctx := context.Background()
+
+		mcpsrNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			// create gateway
+			gw := createTestGateway(gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, gw)).To(Succeed())
+
+			// create service
+			svc := createTestService(serviceName, "default", 8080)
+			Expect(testK8sClient.Create(ctx, svc)).To(Succeed())
+
+			// create HTTPRoute (without setting accepted status)
+			httpRoute := createTestHTTPRoute(httpRouteName, "default", "test.mcp.local", serviceName, 8080, gatewayName, "default")
+			Expect(testK8sClient.Create(ctx, httpRoute)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+			deleteTestHTTPRoute(ctx, httpRouteName, "default")
+			deleteTestService(ctx, serviceName, "default")
+			deleteTestGateway(ctx, gatewayName, "default")
+		})
+
+		It("should set status to NotReady when no gateways have accepted the HTTPRoute", func() {
+			mcpsr := createTestMCPServerRegistration(resourceName, "default", httpRouteName, "test_")
+			Expect(testK8sClient.Create(ctx, mcpsr)).To(Succeed())
+
+			configWriter := newMockMCPServerConfigReaderWriter()
+			reconciler := newMCPServerReconciler(configWriter)
+			waitForMCPServerRegistrationCacheSync(ctx, mcpsrNamespacedName)
+
+			// reconcile multiple times to get past finalizer addition
+			for i := 0; i < 3; i++ {
+				_, _ = reconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: mcpsrNamespacedName,
+				})
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			Eventually(func(g Gomega) {
+				updated := &mcpv1alpha1.MCPServerRegistration{}
+				g.Expect(testK8sClient.Get(ctx, mcpsrNamespacedName, updated)).To(Succeed())
+				cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(cond.Message).To(ContainSubstring("no valid gateways"))
+				g.Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, mcpv1alpha1.ConditionTypeAccepted)).To(BeTrue())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+})
+
+var _ = Describe("MCPServerRegistration Webhook", func() {
+	const (
+		resourceName = "test-mcpsr-webhook"
+		secretName   = "test-webhook-credential"
+	)
+
+	ctx := context.Background()
+
+	createSecret := func(labels map[string]string, data map[string][]byte) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default", Labels: labels},
+			Data:       data,
+		}
+		Expect(testK8sClient.Create(ctx, secret)).To(Succeed())
+	}
+
+	registrationWithCredential := func() *mcpv1alpha1.MCPServerRegistration {
+		mcpsr := createTestMCPServerRegistration(resourceName, "default", "test-route-webhook", "test_")
+		mcpsr.Spec.CredentialRef = &mcpv1alpha1.SecretReference{Name: secretName, Key: "token"}
+		return mcpsr
+	}
+
+	AfterEach(func() {
+		forceDeleteTestMCPServerRegistration(ctx, resourceName, "default")
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"}}
+		Expect(client.IgnoreNotFound(testK8sClient.Delete(ctx, secret))).To(Succeed())
+	})
+
+	It("should reject a credential secret without the credential label", func() {
+		createSecret(nil, map[string][]byte{"token": []byte("secret")})
+
+		err := testK8sClient.Create(ctx, registrationWithCredential())
+		Expect(err).To(HaveOccurred())
+		Expect(errors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("missing required label"))
+	})
+
+	It("should reject a credential secret without the referenced key", func() {
+		createSecret(map[string]string{CredentialSecretLabel: CredentialSecretValue}, map[string][]byte{"other": []byte("secret")})
+
+		err := testK8sClient.Create(ctx, registrationWithCredential())
+		Expect(err).To(HaveOccurred())
+		Expect(errors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("missing key token"))
+	})
+
+	It("should admit a valid credential secret", func() {
+		createSecret(map[string]string{CredentialSecretLabel: CredentialSecretValue}, map[string][]byte{"token": []byte("secret")})
+
+		Expect(testK8sClient.Create(ctx, registrationWithCredential())).To(Succeed())
+	})
+
+	It("should admit a registration whose credential secret does not exist yet", func() {
+		Expect(testK8sClient.Create(ctx, registrationWithCredential())).To(Succeed())
+	})
+
+	It("should reject an update that points at an invalid credential secret", func() {
+		Expect(testK8sClient.Create(ctx, createTestMCPServerRegistration(resourceName, "default", "test-route-webhook", "test_"))).To(Succeed())
+		createSecret(nil, map[string][]byte{"token": []byte("secret")})
+
+		mcpsr := &mcpv1alpha1.MCPServerRegistration{}
+		Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: resourceName, Namespace: "default"}, mcpsr)).To(Succeed())
+		mcpsr.Spec.CredentialRef = &mcpv1alpha1.SecretReference{Name: secretName, Key: "token"}
+		err := testK8sClient.Update(ctx, mcpsr)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.IsInvalid(err)).To(BeTrue())
 	})
 })
@@ -1,6 +1,48 @@
 package controller
 
-import "testing"
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/broker"
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+)
 
 func TestIsValidHostname(t *testing.T) {
 	tests := []struct {
@@ -48,3 +90,1869 @@ func TestIsValidHostname(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistrationChangedPredicate(t *testing.T) {
+	registration := func(generation int64, annotations map[string]string) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test",
+				Namespace:   "default",
+				Generation:  generation,
+				Annotations: annotations,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		old      *mcpv1alpha1.MCPServerRegistration
+		new      *mcpv1alpha1.MCPServerRegistration
+		expected bool
+	}{
+		{
+			name:     "no change",
+			old:      registration(1, nil),
+			new:      registration(1, nil),
+			expected: false,
+		},
+		{
+			name:     "generation changed",
+			old:      registration(1, nil),
+			new:      registration(2, nil),
+			expected: true,
+		},
+		{
+			name:     "force-sync annotation added",
+			old:      registration(1, nil),
+			new:      registration(1, map[string]string{AnnotationForceSync: "1"}),
+			expected: true,
+		},
+		{
+			name:     "force-sync annotation value changed",
+			old:      registration(1, map[string]string{AnnotationForceSync: "1"}),
+			new:      registration(1, map[string]string{AnnotationForceSync: "2"}),
+			expected: true,
+		},
+		{
+			name:     "force-sync annotation unchanged",
+			old:      registration(1, map[string]string{AnnotationForceSync: "1", "other": "a"}),
+			new:      registration(1, map[string]string{AnnotationForceSync: "1", "other": "b"}),
+			expected: false,
+		},
+		{
+			name:     "unrelated annotation changed",
+			old:      registration(1, map[string]string{"example.com/note": "a"}),
+			new:      registration(1, map[string]string{"example.com/note": "b"}),
+			expected: false,
+		},
+	}
+
+	p := registrationChangedPredicate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new})
+			if got != tt.expected {
+				t.Errorf("Update() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCredentialSecretPredicate(t *testing.T) {
+	secret := func(secretLabels map[string]string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "default", Labels: secretLabels},
+		}
+	}
+	selector, err := labels.Parse("mcp.kuadrant.io/registration=true")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		selector labels.Selector
+		secret   *corev1.Secret
+		expected bool
+	}{
+		{
+			name:     "unlabeled secret",
+			secret:   secret(nil),
+			expected: false,
+		},
+		{
+			name:     "labeled secret without selector",
+			secret:   secret(map[string]string{CredentialSecretLabel: CredentialSecretValue}),
+			expected: true,
+		},
+		{
+			name:     "labeled secret excluded by selector",
+			selector: selector,
+			secret:   secret(map[string]string{CredentialSecretLabel: CredentialSecretValue}),
+			expected: false,
+		},
+		{
+			name:     "labeled secret matching selector",
+			selector: selector,
+			secret:   secret(map[string]string{CredentialSecretLabel: CredentialSecretValue, "mcp.kuadrant.io/registration": "true"}),
+			expected: true,
+		},
+		{
+			name:     "selector without credential label",
+			selector: selector,
+			secret:   secret(map[string]string{"mcp.kuadrant.io/registration": "true"}),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := credentialSecretPredicate(CredentialLabel{}, tt.selector)
+			updated := tt.secret.DeepCopy()
+			updated.Data = map[string][]byte{"token": []byte("rotated")}
+			require.Equal(t, tt.expected, p.Update(event.UpdateEvent{ObjectOld: tt.secret, ObjectNew: updated}))
+			require.Equal(t, tt.expected, p.Create(event.CreateEvent{Object: tt.secret}))
+		})
+	}
+}
+
+func TestSetMCPServerRegistrationStatus_SlowBroker(t *testing.T) {
+	slowBroker := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slowBroker.Close()
+
+	newReconciler := func(t *testing.T, grace time.Duration) (*MCPReconciler, *mcpv1alpha1.MCPServerRegistration) {
+		t.Helper()
+		scheme := runtime.NewScheme()
+		require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+		mcpsr := &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a"},
+			Status: mcpv1alpha1.MCPServerRegistrationStatus{
+				Conditions: []metav1.Condition{{
+					Type:               "Ready",
+					Status:             metav1.ConditionTrue,
+					Reason:             "Ready",
+					LastTransitionTime: metav1.Now(),
+				}},
+				DiscoveredTools: 3,
+			},
+		}
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(mcpsr).
+			WithStatusSubresource(mcpsr).
+			Build()
+		r := &MCPReconciler{
+			Client: fakeClient,
+			Scheme: scheme,
+			StatusFetcher: &fakeBrokerFetcher{
+				validator: NewServerValidator(fakeClient, WithValidationTimeout(20*time.Millisecond)),
+				url:       slowBroker.URL,
+			},
+			ValidationGrace: grace,
+		}
+		return r, mcpsr
+	}
+
+	readyStatus := func(t *testing.T, r *MCPReconciler) metav1.ConditionStatus {
+		t.Helper()
+		current := &mcpv1alpha1.MCPServerRegistration{}
+		require.NoError(t, r.Get(context.Background(), client.ObjectKey{Name: "weather", Namespace: "team-a"}, current))
+		return meta.FindStatusCondition(current.Status.Conditions, "Ready").Status
+	}
+
+	t.Run("transient timeout keeps last known readiness", func(t *testing.T) {
+		r, mcpsr := newReconciler(t, time.Minute)
+		err := r.setMCPServerRegistrationStatus(context.Background(), "mcp-system", mcpsr, "id")
+		require.ErrorIs(t, err, ErrValidationTimeout)
+		require.Equal(t, metav1.ConditionTrue, readyStatus(t, r))
+	})
+
+	t.Run("timeout beyond the grace flips readiness", func(t *testing.T) {
+		r, mcpsr := newReconciler(t, time.Minute)
+		r.validationTimeouts.Store(client.ObjectKeyFromObject(mcpsr), time.Now().Add(-2*time.Minute))
+		err := r.setMCPServerRegistrationStatus(context.Background(), "mcp-system", mcpsr, "id")
+		require.ErrorIs(t, err, ErrValidationTimeout)
+		require.Equal(t, metav1.ConditionFalse, readyStatus(t, r))
+		// the config is still written so the registration stays accepted while the broker is unreachable
+		current := &mcpv1alpha1.MCPServerRegistration{}
+		require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(mcpsr), current))
+		require.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, mcpv1alpha1.ConditionTypeAccepted))
+	})
+
+	t.Run("no grace flips readiness immediately", func(t *testing.T) {
+		r, mcpsr := newReconciler(t, 0)
+		err := r.setMCPServerRegistrationStatus(context.Background(), "mcp-system", mcpsr, "id")
+		require.ErrorIs(t, err, ErrValidationTimeout)
+		require.Equal(t, metav1.ConditionFalse, readyStatus(t, r))
+	})
+
+	t.Run("timeouts are forgotten once the status is no longer polled", func(t *testing.T) {
+		r, mcpsr := newReconciler(t, time.Minute)
+		key := client.ObjectKeyFromObject(mcpsr)
+		r.validationTimeouts.Store(key, time.Now().Add(-2*time.Minute))
+		require.NoError(t, r.pruneStaleConfig(context.Background(), mcpsr, nil))
+		_, ok := r.validationTimeouts.Load(key)
+		require.False(t, ok, "a registration served by no broker starts a fresh grace when it is polled again")
+
+		deleted := client.ObjectKey{Name: "deleted", Namespace: "team-a"}
+		r.validationTimeouts.Store(deleted, time.Now())
+		_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: deleted})
+		require.NoError(t, err)
+		_, ok = r.validationTimeouts.Load(deleted)
+		require.False(t, ok, "a deleted registration is forgotten")
+	})
+}
+
+// brokerStatusFixture serves the status of servers from a test broker to a reconciler whose client holds a
+// registration
+type brokerStatusFixture struct {
+	r     *MCPReconciler
+	mcpsr *mcpv1alpha1.MCPServerRegistration
+
+	mu      sync.Mutex
+	servers []upstream.ServerValidationStatus
+}
+
+func newBrokerStatusFixture(t *testing.T, mcpsr *mcpv1alpha1.MCPServerRegistration, servers ...upstream.ServerValidationStatus) *brokerStatusFixture {
+	t.Helper()
+	f := &brokerStatusFixture{mcpsr: mcpsr, servers: servers}
+	fakeBroker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(broker.StatusResponse{Servers: f.servers})
+	}))
+	t.Cleanup(fakeBroker.Close)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(mcpsr).
+		WithStatusSubresource(mcpsr).
+		Build()
+	f.r = &MCPReconciler{
+		Client:        fakeClient,
+		Scheme:        scheme,
+		StatusFetcher: &fakeBrokerFetcher{validator: NewServerValidator(fakeClient), url: fakeBroker.URL},
+	}
+	return f
+}
+
+// serve replaces the status the broker serves
+func (f *brokerStatusFixture) serve(servers ...upstream.ServerValidationStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.servers = servers
+}
+
+// current returns the registration as stored
+func (f *brokerStatusFixture) current(t *testing.T) *mcpv1alpha1.MCPServerRegistration {
+	t.Helper()
+	fresh := &mcpv1alpha1.MCPServerRegistration{}
+	require.NoError(t, f.r.Get(context.Background(), client.ObjectKeyFromObject(f.mcpsr), fresh))
+	return fresh
+}
+
+// setStatus sets the status of the stored registration from the broker
+func (f *brokerStatusFixture) setStatus(t *testing.T) error {
+	t.Helper()
+	return f.r.setMCPServerRegistrationStatus(context.Background(), "mcp-system", f.current(t), "id")
+}
+
+// stringDataToData merges the StringData of a written secret into its Data as the API server does. The fake client
+// stores StringData as is, and the config secret is only read from Data
+var stringDataToData = interceptor.Funcs{
+	Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+		mergeStringData(obj)
+		return c.Create(ctx, obj, opts...)
+	},
+	Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+		mergeStringData(obj)
+		return c.Update(ctx, obj, opts...)
+	},
+}
+
+func mergeStringData(obj client.Object) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || secret.StringData == nil {
+		return
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	for key, value := range secret.StringData {
+		secret.Data[key] = []byte(value)
+	}
+	secret.StringData = nil
+}
+
+func TestSetMCPServerRegistrationStatus_StaleConfig(t *testing.T) {
+	loaded := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	serverStatus := upstream.ServerValidationStatus{
+		ID: "id", Name: "team-a/weather", Ready: true, TotalTools: 2, Message: "server added successfully. Total tools added 2",
+		ConfigGeneration: 1, ConfigLoaded: loaded,
+	}
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a", Generation: 2}}
+	f := newBrokerStatusFixture(t, mcpsr, serverStatus)
+	configWriter := &config.SecretReaderWriter{
+		Client: interceptor.NewClient(f.r.Client.(client.WithWatch), stringDataToData),
+		Scheme: f.r.Scheme,
+		Logger: slog.New(slog.DiscardHandler),
+	}
+	written := config.MCPServer{Name: "team-a/weather", URL: "http://weather.team-a.svc:8080/mcp", Generation: 2}
+	require.NoError(t, configWriter.UpsertMCPServer(context.Background(), written, config.NamespaceName("mcp-system")))
+	f.r.ConfigReaderWriter = configWriter
+
+	// the broker has not loaded the generation written for the registration
+	fresh := f.current(t)
+	err := f.setStatus(t)
+	require.ErrorIs(t, err, errConfigStale)
+	require.ErrorIs(t, err, errServerNotPresent)
+	readyCondition := meta.FindStatusCondition(f.current(t).Status.Conditions, "Ready")
+	require.Contains(t, readyCondition.Message, fmt.Sprintf("Waiting for the broker to load config generation %d, it loaded generation 1 at 2026-01-02T03:04:05Z", fresh.Generation))
+
+	// the broker acknowledges the generation
+	serverStatus.ConfigGeneration = fresh.Generation
+	f.serve(serverStatus)
+	f.r.StatusCoalesceWindow = 0
+	require.NoError(t, f.setStatus(t))
+	readyCondition = meta.FindStatusCondition(f.current(t).Status.Conditions, "Ready")
+	require.NotContains(t, readyCondition.Message, "Waiting for the broker")
+
+	// a registration change that leaves the config unchanged isn't written so the broker has nothing to load
+	fresh = f.current(t)
+	fresh.Generation++
+	require.NoError(t, f.r.Update(context.Background(), fresh))
+	written.Generation = fresh.Generation
+	require.NoError(t, configWriter.UpsertMCPServer(context.Background(), written, config.NamespaceName("mcp-system")))
+	require.NoError(t, f.setStatus(t))
+	readyCondition = meta.FindStatusCondition(f.current(t).Status.Conditions, "Ready")
+	require.NotContains(t, readyCondition.Message, "Waiting for the broker")
+
+	// a broker that doesn't report generations is never stale
+	require.False(t, brokerConfigStale(upstream.ServerValidationStatus{}, 5))
+}
+
+func TestSetMCPServerRegistrationStatus_ToolConflict(t *testing.T) {
+	conflict := upstream.ServerValidationStatus{
+		ID:                 "id",
+		Name:               "team-a/weather",
+		Message:            "conflicting tools discovered. tools search, time conflict with servers other of equal priority",
+		Reason:             upstream.ReasonToolConflict,
+		ConflictingTools:   []string{"search", "time"},
+		ConflictingServers: []string{"other"},
+	}
+	f := newBrokerStatusFixture(t, &mcpv1alpha1.MCPServerRegistration{ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a"}}, conflict)
+
+	err := f.setStatus(t)
+	require.ErrorIs(t, err, errServerNotPresent)
+	written := f.current(t)
+	require.Equal(t, []string{"search", "time"}, written.Status.ConflictingTools)
+	readyCondition := meta.FindStatusCondition(written.Status.Conditions, "Ready")
+	require.Equal(t, upstream.ReasonToolConflict, readyCondition.Reason)
+	require.Contains(t, readyCondition.Message, "search, time")
+
+	// a status not reported by the broker, such as the server being disabled, doesn't keep stale conflicts
+	disabled := written.DeepCopy()
+	require.True(t, setReadyStatus(disabled, true, false, ReasonDisabled, "server is disabled", 0))
+	require.Empty(t, disabled.Status.ConflictingTools)
+
+	// a distinct tool prefix resolves the conflict
+	f.serve(upstream.ServerValidationStatus{ID: "id", Name: "team-a/weather", Ready: true, TotalTools: 2})
+	require.NoError(t, f.setStatus(t))
+	require.Empty(t, f.current(t).Status.ConflictingTools)
+}
+
+func TestSetMCPServerRegistrationStatus_ProtocolVersion(t *testing.T) {
+	f := newBrokerStatusFixture(t, &mcpv1alpha1.MCPServerRegistration{ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a"}},
+		upstream.ServerValidationStatus{ID: "id", Name: "team-a/weather", Ready: true, TotalTools: 2, ProtocolVersion: "2025-06-18"})
+
+	require.NoError(t, f.setStatus(t))
+	require.Equal(t, "2025-06-18", f.current(t).Status.ProtocolVersion)
+
+	// a version the broker rejects is still reported
+	f.serve(upstream.ServerValidationStatus{
+		ID:              "id",
+		Name:            "team-a/weather",
+		Reason:          upstream.ReasonProtocolMismatch,
+		Message:         "unsupported protocol version: 2021-11-05",
+		ProtocolVersion: "2021-11-05",
+	})
+	err := f.setStatus(t)
+	require.ErrorIs(t, err, errServerNotPresent)
+	written := f.current(t)
+	require.Equal(t, "2021-11-05", written.Status.ProtocolVersion)
+	require.Equal(t, upstream.ReasonProtocolMismatch, meta.FindStatusCondition(written.Status.Conditions, "Ready").Reason)
+}
+
+func TestSetMCPServerRegistrationStatus_FailureBackoff(t *testing.T) {
+	f := newBrokerStatusFixture(t, &mcpv1alpha1.MCPServerRegistration{ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a", Generation: 1}},
+		upstream.ServerValidationStatus{ID: "id", Name: "team-a/weather", Reason: "ConnectionFailed", Message: "connection refused"})
+	f.r.FailureBackoffThreshold = 3
+	f.r.FailureBackoffInterval = 10 * time.Minute
+
+	for i := int32(1); i < 3; i++ {
+		err := f.setStatus(t)
+		require.ErrorIs(t, err, errServerNotPresent)
+		require.NotErrorIs(t, err, errServerBackoff)
+		require.Equal(t, i, f.current(t).Status.ConsecutiveFailures)
+	}
+
+	// the third failure in a row backs off
+	err := f.setStatus(t)
+	require.ErrorIs(t, err, errServerBackoff)
+	written := f.current(t)
+	require.Equal(t, int32(3), written.Status.ConsecutiveFailures)
+	readyCondition := meta.FindStatusCondition(written.Status.Conditions, "Ready")
+	require.Equal(t, ReasonBackoff, readyCondition.Reason)
+	require.Contains(t, readyCondition.Message, "Last failure (ConnectionFailed): connection refused")
+
+	// a deferred write keeps backing off
+	f.r.StatusCoalesceWindow = time.Hour
+	require.ErrorIs(t, f.setStatus(t), errServerBackoff)
+	f.r.StatusCoalesceWindow = 0
+
+	// a spec change restarts the count
+	written.Generation = 2
+	require.NoError(t, f.r.Update(context.Background(), written))
+	err = f.setStatus(t)
+	require.NotErrorIs(t, err, errServerBackoff)
+	require.Equal(t, int32(1), f.current(t).Status.ConsecutiveFailures)
+
+	// a ready server clears the count
+	f.serve(upstream.ServerValidationStatus{ID: "id", Name: "team-a/weather", Ready: true, TotalTools: 2})
+	require.NoError(t, f.setStatus(t))
+	require.Zero(t, f.current(t).Status.ConsecutiveFailures)
+}
+
+func TestConsecutiveFailures(t *testing.T) {
+	failing := upstream.ServerValidationStatus{ID: "id"}
+	registration := func(generation, observed int64, failures int32) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Generation: generation},
+			Status:     mcpv1alpha1.MCPServerRegistrationStatus{ObservedGeneration: observed, ConsecutiveFailures: failures},
+		}
+	}
+
+	require.Equal(t, int32(3), consecutiveFailures(registration(1, 1, 2), failing, false))
+	require.Equal(t, int32(2), consecutiveFailures(registration(1, 1, 2), failing, true), "a stale config doesn't count")
+	require.Equal(t, int32(1), consecutiveFailures(registration(2, 1, 4), failing, false), "a spec change restarts the count")
+	require.Zero(t, consecutiveFailures(registration(1, 1, 4), upstream.ServerValidationStatus{ID: "id", Ready: true}, false))
+	forceSynced := registration(1, 1, 4)
+	forceSynced.Annotations = map[string]string{AnnotationForceSync: "1"}
+	require.Equal(t, int32(1), consecutiveFailures(forceSynced, failing, false), "a force sync restarts the count")
+	forceSynced.Status.ForceSync = "1"
+	require.Equal(t, int32(5), consecutiveFailures(forceSynced, failing, false), "a handled force sync keeps counting")
+
+	loaded := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	beforeConnect := upstream.ServerValidationStatus{ID: "id", ConfigGeneration: 1, ConfigLoaded: loaded, LastValidated: loaded.Add(-time.Minute)}
+	require.Equal(t, int32(2), consecutiveFailures(registration(1, 1, 2), beforeConnect, false),
+		"a check before the broker connected with the loaded config doesn't count")
+	afterConnect := beforeConnect
+	afterConnect.LastValidated = loaded.Add(time.Second)
+	require.Equal(t, int32(3), consecutiveFailures(registration(1, 1, 2), afterConnect, false))
+}
+
+func TestReconcile_DryRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	added := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "added", Namespace: "team-a"},
+		Spec:       mcpv1alpha1.MCPServerRegistrationSpec{TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: "mcp-server"}},
+	}
+	deleted := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "deleted",
+			Namespace:         "team-a",
+			Finalizers:        []string{mcpGatewayFinalizer},
+			DeletionTimestamp: ptr.To(metav1.Now()),
+		},
+		Spec: mcpv1alpha1.MCPServerRegistrationSpec{TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: "mcp-server"}},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(added, deleted).Build()
+	r := &MCPReconciler{
+		Client:             client.NewDryRunClient(k8sClient),
+		Scheme:             scheme,
+		ConfigReaderWriter: &config.DryRunReaderWriter{Reader: k8sClient, Logger: slog.New(slog.DiscardHandler)},
+	}
+
+	for _, mcpsr := range []*mcpv1alpha1.MCPServerRegistration{added, deleted} {
+		before := &mcpv1alpha1.MCPServerRegistration{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(mcpsr), before))
+		_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(mcpsr)})
+		require.NoError(t, err)
+		after := &mcpv1alpha1.MCPServerRegistration{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(mcpsr), after))
+		require.Equal(t, before, after, "a dry run reconcile should not change %s", mcpsr.Name)
+	}
+}
+
+func TestSetReadyStatus_ForceSync(t *testing.T) {
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1, Annotations: map[string]string{AnnotationForceSync: "1"}},
+		Status:     mcpv1alpha1.MCPServerRegistrationStatus{ObservedGeneration: 1, ConsecutiveFailures: 4},
+	}
+	require.True(t, setReadyStatus(mcpsr, true, false, ReasonBackoff, "failing", 0))
+	require.Equal(t, "1", mcpsr.Status.ForceSync)
+	require.Zero(t, mcpsr.Status.ConsecutiveFailures)
+
+	mcpsr.Status.ConsecutiveFailures = 2
+	require.False(t, setReadyStatus(mcpsr, true, false, ReasonBackoff, "failing", 0), "a handled force sync is not written again")
+	require.Equal(t, int32(2), mcpsr.Status.ConsecutiveFailures)
+}
+
+func TestSetReadyStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		accepted       bool
+		ready          bool
+		notReadyReason string
+		wantAccepted   metav1.ConditionStatus
+		wantReady      metav1.ConditionStatus
+		wantReason     string
+	}{
+		{
+			name:         "config not written",
+			wantAccepted: metav1.ConditionFalse,
+			wantReady:    metav1.ConditionFalse,
+			wantReason:   "NotReady",
+		},
+		{
+			name:           "config not written with reason",
+			notReadyReason: "RefNotPermitted",
+			wantAccepted:   metav1.ConditionFalse,
+			wantReady:      metav1.ConditionFalse,
+			wantReason:     "RefNotPermitted",
+		},
+		{
+			name:         "accepted but broker has not discovered tools",
+			accepted:     true,
+			wantAccepted: metav1.ConditionTrue,
+			wantReady:    metav1.ConditionFalse,
+			wantReason:   "NotReady",
+		},
+		{
+			name:         "accepted and ready",
+			accepted:     true,
+			ready:        true,
+			wantAccepted: metav1.ConditionTrue,
+			wantReady:    metav1.ConditionTrue,
+			wantReason:   "Ready",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpsr := &mcpv1alpha1.MCPServerRegistration{}
+			require.True(t, setReadyStatus(mcpsr, tt.accepted, tt.ready, tt.notReadyReason, "message", 0))
+			accepted := meta.FindStatusCondition(mcpsr.Status.Conditions, mcpv1alpha1.ConditionTypeAccepted)
+			require.NotNil(t, accepted)
+			require.Equal(t, tt.wantAccepted, accepted.Status)
+			ready := meta.FindStatusCondition(mcpsr.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+			require.NotNil(t, ready)
+			require.Equal(t, tt.wantReady, ready.Status)
+			require.Equal(t, tt.wantReason, ready.Reason)
+			// setting the same status again is not a change
+			require.False(t, setReadyStatus(mcpsr, tt.accepted, tt.ready, tt.notReadyReason, "message", 0))
+		})
+	}
+
+	t.Run("spec change advances the observed generation", func(t *testing.T) {
+		mcpsr := &mcpv1alpha1.MCPServerRegistration{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+		require.True(t, setReadyStatus(mcpsr, true, true, "", "message", 3))
+		require.Equal(t, int64(1), mcpsr.Status.ObservedGeneration)
+
+		mcpsr.Generation = 2
+		require.True(t, setReadyStatus(mcpsr, true, true, "", "message", 3))
+		require.Equal(t, int64(2), mcpsr.Status.ObservedGeneration)
+		for _, condition := range mcpsr.Status.Conditions {
+			require.Equal(t, int64(2), condition.ObservedGeneration)
+		}
+		require.False(t, setReadyStatus(mcpsr, true, true, "", "message", 3))
+	})
+}
+
+func TestUpdateStatusCoalesced_FanOut(t *testing.T) {
+	const registrations = 20
+	const refreshes = 5
+
+	newReconciler := func(t *testing.T, window time.Duration) (*MCPReconciler, []*mcpv1alpha1.MCPServerRegistration, *int) {
+		t.Helper()
+		scheme := runtime.NewScheme()
+		require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+		var objs []client.Object
+		var mcpsrs []*mcpv1alpha1.MCPServerRegistration
+		for i := range registrations {
+			mcpsr := &mcpv1alpha1.MCPServerRegistration{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("server-%d", i), Namespace: "team-a"},
+			}
+			objs = append(objs, mcpsr)
+			mcpsrs = append(mcpsrs, mcpsr)
+		}
+		writes := 0
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objs...).
+			WithStatusSubresource(objs...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					writes++
+					return c.SubResource(subResource).Update(ctx, obj, opts...)
+				},
+			}).
+			Build()
+		return &MCPReconciler{Client: fakeClient, Scheme: scheme, StatusCoalesceWindow: window}, mcpsrs, &writes
+	}
+
+	// each reconcile works on a freshly read registration
+	current := func(t *testing.T, r *MCPReconciler, mcpsr *mcpv1alpha1.MCPServerRegistration) *mcpv1alpha1.MCPServerRegistration {
+		t.Helper()
+		fresh := &mcpv1alpha1.MCPServerRegistration{}
+		require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(mcpsr), fresh))
+		return fresh
+	}
+
+	// each registration becomes ready then its tool count changes on every broker poll
+	fanOut := func(t *testing.T, r *MCPReconciler, mcpsrs []*mcpv1alpha1.MCPServerRegistration) {
+		t.Helper()
+		for refresh := range refreshes {
+			for _, mcpsr := range mcpsrs {
+				err := r.updateStatusCoalesced(context.Background(), current(t, r, mcpsr), upstream.ServerValidationStatus{Ready: true, Message: "ready", TotalTools: refresh + 1}, 0)
+				if err != nil {
+					require.ErrorIs(t, err, errStatusDeferred)
+				}
+			}
+		}
+	}
+
+	t.Run("without coalescing every change is written", func(t *testing.T) {
+		r, mcpsrs, writes := newReconciler(t, 0)
+		fanOut(t, r, mcpsrs)
+		require.Equal(t, registrations*refreshes, *writes)
+	})
+
+	t.Run("coalescing writes readiness immediately and the latest tool count after the window", func(t *testing.T) {
+		window := 100 * time.Millisecond
+		r, mcpsrs, writes := newReconciler(t, window)
+		fanOut(t, r, mcpsrs)
+		// only the transition to ready is written during the fan out
+		require.Equal(t, registrations, *writes)
+
+		time.Sleep(window)
+		// the requeued reconciles write the latest status
+		for _, mcpsr := range mcpsrs {
+			require.NoError(t, r.updateStatusCoalesced(context.Background(), current(t, r, mcpsr), upstream.ServerValidationStatus{Ready: true, Message: "ready", TotalTools: refreshes}, 0))
+		}
+		require.Equal(t, 2*registrations, *writes)
+
+		for _, mcpsr := range mcpsrs {
+			written := current(t, r, mcpsr)
+			require.Equal(t, refreshes, written.Status.DiscoveredTools)
+			require.True(t, meta.IsStatusConditionTrue(written.Status.Conditions, "Ready"))
+		}
+
+		// a readiness change is not deferred even inside the window
+		require.NoError(t, r.updateStatusCoalesced(context.Background(), current(t, r, mcpsrs[0]), upstream.ServerValidationStatus{Message: "gone"}, 0))
+		require.Equal(t, 2*registrations+1, *writes)
+	})
+}
+
+func TestVirtualServersReferencingRegistration(t *testing.T) {
+	virtualServer := func(namespace, name string, tools ...string) mcpv1alpha1.MCPVirtualServer {
+		return mcpv1alpha1.MCPVirtualServer{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       mcpv1alpha1.MCPVirtualServerSpec{Tools: tools},
+		}
+	}
+	deleting := virtualServer("team-a", "deleting", "weather_forecast")
+	deleting.DeletionTimestamp = ptr.To(metav1.Now())
+	// scoped references the registration by name rather than by a tool matching its prefix
+	scoped := virtualServer("team-b", "scoped")
+	scoped.Spec.Servers = []mcpv1alpha1.MCPVirtualServerServer{{Name: "calendar", Namespace: "team-a"}}
+	virtualServers := []mcpv1alpha1.MCPVirtualServer{
+		virtualServer("team-b", "travel", "weather_forecast", "flights_search"),
+		virtualServer("team-a", "news", "news_headlines"),
+		virtualServer("team-a", "daily", "news_headlines", "weather_alerts"),
+		deleting,
+		scoped,
+	}
+
+	testCases := []struct {
+		name         string
+		registration string
+		prefix       string
+		expected     []string
+	}{
+		{
+			name:     "virtual servers with a tool matching the prefix",
+			prefix:   "weather_",
+			expected: []string{"team-a/daily", "team-b/travel"},
+		},
+		{
+			name:     "no virtual server references the prefix",
+			prefix:   "calendar_",
+			expected: nil,
+		},
+		{
+			name:         "virtual server listing the registration in servers",
+			registration: "team-a/calendar",
+			prefix:       "calendar_",
+			expected:     []string{"team-b/scoped"},
+		},
+		{
+			name:     "registration without a prefix may serve any tool",
+			prefix:   "",
+			expected: []string{"team-a/daily", "team-a/news", "team-b/travel"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			namespace, name, _ := strings.Cut(tc.registration, "/")
+			mcpsr := &mcpv1alpha1.MCPServerRegistration{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       mcpv1alpha1.MCPServerRegistrationSpec{ToolPrefix: tc.prefix},
+			}
+			require.Equal(t, tc.expected, virtualServersReferencingRegistration(mcpsr, virtualServers))
+		})
+	}
+}
+
+func TestBuildServerInfoFromHTTPRoute_MultipleBackends(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	services := []client.Object{}
+	for _, name := range []string{"web", "mcp-server", "mcp-canary"} {
+		services = append(services, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"}})
+	}
+	r := &MCPReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(services...).Build(), Scheme: scheme}
+
+	prefix := gatewayv1.PathMatchPathPrefix
+	rule := func(path string, backends ...string) gatewayv1.HTTPRouteRule {
+		rule := gatewayv1.HTTPRouteRule{Matches: []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: &prefix, Value: ptr.To(path)}}}}
+		for _, backend := range backends {
+			rule.BackendRefs = append(rule.BackendRefs, gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+				BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(backend), Port: ptr.To(gatewayv1.PortNumber(8080))},
+			}})
+		}
+		return rule
+	}
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "split", Namespace: "team-a"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			Hostnames: []gatewayv1.Hostname{"split.mcp.local"},
+			Rules:     []gatewayv1.HTTPRouteRule{rule("/", "web"), rule("/mcp", "mcp-server", "mcp-canary")},
+		},
+	}
+
+	t.Run("single match", func(t *testing.T) {
+		info, err := r.buildServerInfoFromHTTPRoute(context.Background(), route, "/mcp", "mcp-canary", "")
+		require.NoError(t, err)
+		require.Equal(t, "http://mcp-canary.team-a.svc.cluster.local:8080/mcp", info.Endpoint)
+	})
+
+	t.Run("multiple matches", func(t *testing.T) {
+		_, err := r.buildServerInfoFromHTTPRoute(context.Background(), route, "/mcp", "", "")
+		require.Equal(t, ReasonBackendRefAmbiguous, backendRefFailureReason(err))
+		require.ErrorContains(t, err, "rules [1] of HTTPRoute team-a/split match path /mcp with backendRefs [mcp-server mcp-canary]")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		_, err := r.buildServerInfoFromHTTPRoute(context.Background(), route, "/mcp", "missing", "")
+		require.Equal(t, ReasonBackendRefNotFound, backendRefFailureReason(err))
+		require.ErrorContains(t, err, "has no backendRef named missing")
+	})
+
+	t.Run("selected hostname", func(t *testing.T) {
+		multiHost := route.DeepCopy()
+		multiHost.Spec.Hostnames = append(multiHost.Spec.Hostnames, "split.127-0-0-1.sslip.io")
+		info, err := r.buildServerInfoFromHTTPRoute(context.Background(), multiHost, "/mcp", "mcp-canary", "split.127-0-0-1.sslip.io")
+		require.NoError(t, err)
+		require.Equal(t, "split.127-0-0-1.sslip.io", info.Hostname)
+
+		info, err = r.buildServerInfoFromHTTPRoute(context.Background(), multiHost, "/mcp", "mcp-canary", "")
+		require.NoError(t, err)
+		require.Equal(t, "split.mcp.local", info.Hostname)
+	})
+
+	t.Run("hostname not on the route", func(t *testing.T) {
+		_, err := r.buildServerInfoFromHTTPRoute(context.Background(), route, "/mcp", "mcp-canary", "other.mcp.local")
+		require.Equal(t, ReasonHostnameNotFound, backendRefFailureReason(err))
+	})
+}
+
+func TestBuildServerInfoFromService(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	services := []client.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: "team-a"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Name: "http", Port: 9090}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "multi-port", Namespace: "team-a"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Name: "http", Port: 8080}, {Name: "metrics", Port: 9100}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "team-a"},
+			Spec: corev1.ServiceSpec{
+				Type:         corev1.ServiceTypeExternalName,
+				ExternalName: "mcp.example.com",
+				Ports:        []corev1.ServicePort{{Name: "https", Port: 443, AppProtocol: ptr.To("https")}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "h2c", Namespace: "team-a"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Name: "http2", Port: 9090, AppProtocol: ptr.To("kubernetes.io/h2c")}},
+			},
+		},
+	}
+	services = append(services,
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "team-b"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Name: "http", Port: 8080}},
+			},
+		},
+		&gatewayv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "allow-team-a", Namespace: "team-b"},
+			Spec: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: "team-a"}},
+				To:   []gatewayv1beta1.ReferenceGrantTo{{Group: "", Kind: "Service", Name: ptr.To(gatewayv1beta1.ObjectName("shared"))}},
+			},
+		},
+	)
+	require.NoError(t, gatewayv1beta1.Install(scheme))
+	r := &MCPReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(services...).Build(), Scheme: scheme}
+	targetRoute := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "registration-mcp-service", Namespace: "team-a"},
+		Spec:       gatewayv1.HTTPRouteSpec{Hostnames: []gatewayv1.Hostname{"mcp.127-0-0-1.sslip.io"}},
+	}
+	registration := func(service string, port *int32) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "registration", Namespace: "team-a"},
+			Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+				TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: service, Port: port},
+				Path:      "/mcp",
+			},
+		}
+	}
+
+	t.Run("cluster ip", func(t *testing.T) {
+		info, err := r.buildServerInfoFromService(context.Background(), registration("mcp-server", nil), targetRoute)
+		require.NoError(t, err)
+		require.Equal(t, "http://mcp-server.team-a.svc.cluster.local:9090/mcp", info.Endpoint)
+		require.Equal(t, "mcp.127-0-0-1.sslip.io", info.Hostname)
+		require.Equal(t, "registration-mcp-service", info.HTTPRouteName)
+		require.Empty(t, info.Protocol)
+	})
+
+	t.Run("h2c", func(t *testing.T) {
+		info, err := r.buildServerInfoFromService(context.Background(), registration("h2c", nil), targetRoute)
+		require.NoError(t, err)
+		require.Equal(t, "http://h2c.team-a.svc.cluster.local:9090/mcp", info.Endpoint)
+		require.Equal(t, config.ProtocolH2C, info.Protocol)
+	})
+
+	t.Run("external name", func(t *testing.T) {
+		info, err := r.buildServerInfoFromService(context.Background(), registration("external", nil), targetRoute)
+		require.NoError(t, err)
+		require.Equal(t, "https://mcp.example.com:443/mcp", info.Endpoint)
+		require.Equal(t, "mcp.127-0-0-1.sslip.io", info.Hostname)
+	})
+
+	t.Run("selected port", func(t *testing.T) {
+		info, err := r.buildServerInfoFromService(context.Background(), registration("multi-port", ptr.To(int32(8080))), targetRoute)
+		require.NoError(t, err)
+		require.Equal(t, "http://multi-port.team-a.svc.cluster.local:8080/mcp", info.Endpoint)
+	})
+
+	t.Run("multiple ports", func(t *testing.T) {
+		_, err := r.buildServerInfoFromService(context.Background(), registration("multi-port", nil), targetRoute)
+		require.Equal(t, ReasonBackendRefAmbiguous, backendRefFailureReason(err))
+	})
+
+	t.Run("missing port", func(t *testing.T) {
+		_, err := r.buildServerInfoFromService(context.Background(), registration("mcp-server", ptr.To(int32(8080))), targetRoute)
+		require.Equal(t, ReasonBackendRefNotFound, backendRefFailureReason(err))
+		require.ErrorContains(t, err, "service mcp-server has no port 8080")
+	})
+
+	t.Run("missing service", func(t *testing.T) {
+		_, err := r.buildServerInfoFromService(context.Background(), registration("missing", nil), targetRoute)
+		require.ErrorContains(t, err, "failed to get targeted service team-a/missing")
+	})
+
+	t.Run("granted service in another namespace", func(t *testing.T) {
+		mcpsr := registration("shared", nil)
+		mcpsr.Spec.TargetRef.Namespace = "team-b"
+		info, err := r.buildServerInfoFromService(context.Background(), mcpsr, targetRoute)
+		require.NoError(t, err)
+		require.Equal(t, "http://shared.team-b.svc.cluster.local:8080/mcp", info.Endpoint)
+	})
+
+	t.Run("ungranted service in another namespace", func(t *testing.T) {
+		mcpsr := registration("shared", nil)
+		mcpsr.Spec.TargetRef.Namespace = "team-b"
+		mcpsr.Namespace = "team-c"
+		_, err := r.buildServerInfoFromService(context.Background(), mcpsr, targetRoute)
+		require.Equal(t, ReasonBackendRefGrantRequired, backendRefFailureReason(err))
+	})
+
+	t.Run("no route", func(t *testing.T) {
+		_, err := r.buildServerInfoFromService(context.Background(), registration("mcp-server", nil), nil)
+		require.ErrorContains(t, err, "no httproute routes tool calls to service team-a/mcp-server")
+	})
+
+	t.Run("empty path", func(t *testing.T) {
+		mcpsr := registration("mcp-server", nil)
+		mcpsr.Spec.Path = ""
+		info, err := r.buildServerInfoFromService(context.Background(), mcpsr, targetRoute)
+		require.NoError(t, err)
+		require.Equal(t, "http://mcp-server.team-a.svc.cluster.local:9090/mcp", info.Endpoint)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		mcpsr := registration("mcp-server", nil)
+		mcpsr.Spec.Path = "mcp"
+		_, err := r.buildServerInfoFromService(context.Background(), mcpsr, targetRoute)
+		require.Equal(t, ReasonInvalidPath, backendRefFailureReason(err))
+	})
+}
+
+func TestMCPEndpointPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		errMsg   string
+	}{
+		{path: "", expected: "/mcp"},
+		{path: "/mcp", expected: "/mcp"},
+		{path: "/", expected: "/"},
+		{path: "/v1/mcp/", expected: "/v1/mcp/"},
+		{path: "//v1//mcp", expected: "/v1/mcp"},
+		{path: "mcp", errMsg: "must begin with /"},
+		{path: "/mcp?session=1", errMsg: "must not contain a query"},
+		{path: "/mcp#tools", errMsg: "must not contain a query"},
+		{path: "/my mcp", errMsg: "whitespace"},
+		{path: "/v1/../mcp", errMsg: "must not contain . or .. segments"},
+		{path: "/mcp%zz", errMsg: "invalid URL escape"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := mcpEndpointPath(tt.path)
+			if tt.errMsg != "" {
+				require.ErrorIs(t, err, errInvalidPath)
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, path)
+		})
+	}
+}
+
+func TestValidateToolPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		errMsg string
+	}{
+		{name: "empty", prefix: ""},
+		{name: "underscore", prefix: "weather_"},
+		{name: "dot and hyphen", prefix: "team-a.weather-"},
+		{name: "mixed case and digits", prefix: "Server2_"},
+		{name: "longest", prefix: strings.Repeat("a", mcpToolNameMaxLength-1)},
+		{name: "space", prefix: "my weather_", errMsg: "contains ' '"},
+		{name: "slash", prefix: "team/weather_", errMsg: "contains '/'"},
+		{name: "colon", prefix: "weather:", errMsg: "contains ':'"},
+		{name: "non ascii", prefix: "météo_", errMsg: "contains 'é'"},
+		{name: "too long", prefix: strings.Repeat("a", mcpToolNameMaxLength), errMsg: "must be shorter than 128 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateToolPrefix(tt.prefix)
+			if tt.errMsg != "" {
+				require.ErrorIs(t, err, errInvalidToolName)
+				require.ErrorContains(t, err, tt.errMsg)
+				require.Equal(t, ReasonInvalidToolName, backendRefFailureReason(err))
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBuildMCPServerConfig_InvalidToolName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "docs", Namespace: "team-a"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+	}).Build()
+	r := &MCPReconciler{Client: k8sClient, DirectAPIReader: k8sClient, Scheme: scheme}
+	registration := func(prefix, template string) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "docs", Namespace: "team-a"},
+			Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+				TargetRef:        mcpv1alpha1.TargetReference{Kind: "Service", Name: "docs"},
+				Path:             "/mcp",
+				ToolPrefix:       prefix,
+				ToolNameTemplate: template,
+			},
+		}
+	}
+
+	_, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("docs/", ""))
+	require.ErrorIs(t, err, errInvalidToolName)
+	require.ErrorContains(t, err, `toolPrefix "docs/" contains '/'`)
+
+	_, err = r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("v1", "{tool} ({server})"))
+	require.ErrorIs(t, err, errInvalidToolName)
+	require.ErrorContains(t, err, `toolNameTemplate "{tool} ({server})" renders tool names containing ' '`)
+
+	serverConfig, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("v1", "{tool}.{server}-{prefix}"))
+	require.NoError(t, err)
+	require.Equal(t, "search.docs-v1", serverConfig.ServedToolName("search"))
+
+	aliased := registration("v1_", "")
+	aliased.Spec.ToolAliases = map[string]string{"search": "find"}
+	serverConfig, err = r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, aliased)
+	require.NoError(t, err)
+	require.Equal(t, "find", serverConfig.ServedToolName("search"))
+	require.Equal(t, "v1_fetch", serverConfig.ServedToolName("fetch"))
+
+	aliased.Spec.ToolAliases = map[string]string{"search": "find", "lookup": "find"}
+	_, err = r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, aliased)
+	require.ErrorIs(t, err, errInvalidToolName)
+}
+
+func TestValidateToolAliases(t *testing.T) {
+	testCases := []struct {
+		name    string
+		aliases map[string]string
+		err     string
+	}{
+		{name: "no aliases"},
+		{name: "unique aliases", aliases: map[string]string{"time": "clock_time", "date": "clock_date"}},
+		{name: "empty alias", aliases: map[string]string{"time": ""}, err: `toolAliases alias for "time" must not be empty`},
+		{name: "invalid character", aliases: map[string]string{"time": "clock time"}, err: `toolAliases alias "clock time" contains ' '`},
+		{name: "too long", aliases: map[string]string{"time": strings.Repeat("a", mcpToolNameMaxLength+1)}, err: "must be at most 128 characters"},
+		{name: "colliding aliases", aliases: map[string]string{"time": "now", "date": "now"}, err: `toolAliases alias "now" is used for both "date" and "time"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateToolAliases(tc.aliases)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errInvalidToolName)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestBuildServerInfoFromHTTPRoute_AppProtocol(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	tests := []struct {
+		appProtocol *string
+		external    bool
+		endpoint    string
+		protocol    string
+	}{
+		{appProtocol: nil, endpoint: "http://mcp-server.team-a.svc.cluster.local:8080/mcp"},
+		{appProtocol: ptr.To("http"), endpoint: "http://mcp-server.team-a.svc.cluster.local:8080/mcp"},
+		{appProtocol: ptr.To("h2c"), endpoint: "http://mcp-server.team-a.svc.cluster.local:8080/mcp", protocol: config.ProtocolH2C},
+		{appProtocol: ptr.To("H2C"), endpoint: "http://mcp-server.team-a.svc.cluster.local:8080/mcp", protocol: config.ProtocolH2C},
+		{appProtocol: ptr.To("kubernetes.io/h2c"), endpoint: "http://mcp-server.team-a.svc.cluster.local:8080/mcp", protocol: config.ProtocolH2C},
+		{appProtocol: ptr.To("grpc"), endpoint: "http://mcp-server.team-a.svc.cluster.local:8080/mcp", protocol: config.ProtocolH2C},
+		{appProtocol: ptr.To("kubernetes.io/ws"), endpoint: "http://mcp-server.team-a.svc.cluster.local:8080/mcp"},
+		{appProtocol: ptr.To("h2c"), external: true, endpoint: "http://mcp.example.com:8080/mcp", protocol: config.ProtocolH2C},
+		{appProtocol: ptr.To("https"), external: true, endpoint: "https://mcp.example.com:8080/mcp"},
+	}
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "mcp", Namespace: "team-a"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			Hostnames: []gatewayv1.Hostname{"mcp.mcp.local"},
+			Rules: []gatewayv1.HTTPRouteRule{{BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{
+				BackendObjectReference: gatewayv1.BackendObjectReference{Name: "mcp-server", Port: ptr.To(gatewayv1.PortNumber(8080))},
+			}}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: "team-a"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Name: "mcp", Port: 8080, AppProtocol: tt.appProtocol}},
+			},
+		}
+		name := "none"
+		if tt.appProtocol != nil {
+			name = *tt.appProtocol
+		}
+		if tt.external {
+			service.Spec.Type = corev1.ServiceTypeExternalName
+			service.Spec.ExternalName = "mcp.example.com"
+			name = "external " + name
+		}
+		t.Run(name, func(t *testing.T) {
+			r := &MCPReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(service).Build(), Scheme: scheme}
+			info, err := r.buildServerInfoFromHTTPRoute(context.Background(), route, "/mcp", "", "")
+			require.NoError(t, err)
+			require.Equal(t, tt.endpoint, info.Endpoint)
+			require.Equal(t, tt.protocol, info.Protocol)
+		})
+	}
+}
+
+func TestBuildServerInfoFromHTTPRoute_Filters(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	services := []client.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: "team-a"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "tunnel", Namespace: "team-a"},
+			Spec: corev1.ServiceSpec{
+				Type:         corev1.ServiceTypeExternalName,
+				ExternalName: "tunnel.example.com",
+				Ports:        []corev1.ServicePort{{Name: "https", Port: 8080, AppProtocol: ptr.To("https")}},
+			},
+		},
+	}
+	r := &MCPReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(services...).Build(), Scheme: scheme}
+	prefix := gatewayv1.PathMatchPathPrefix
+	route := func(backend string, weight *int32, filters ...gatewayv1.HTTPRouteFilter) *gatewayv1.HTTPRoute {
+		return &gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcp", Namespace: "team-a"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				Hostnames: []gatewayv1.Hostname{"mcp.mcp.local"},
+				Rules: []gatewayv1.HTTPRouteRule{{
+					Matches: []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: &prefix, Value: ptr.To("/mcp")}}},
+					Filters: filters,
+					BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(backend), Port: ptr.To(gatewayv1.PortNumber(8080))},
+						Weight:                 weight,
+					}}},
+				}},
+			},
+		}
+	}
+	hostnameRewrite := gatewayv1.HTTPRouteFilter{
+		Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+		URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Hostname: ptr.To(gatewayv1.PreciseHostname("abc.ngrok.example"))},
+	}
+
+	t.Run("request headers", func(t *testing.T) {
+		info, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", nil, gatewayv1.HTTPRouteFilter{
+			Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set:    []gatewayv1.HTTPHeader{{Name: "X-Tenant", Value: "team-a"}},
+				Add:    []gatewayv1.HTTPHeader{{Name: "ngrok-skip-browser-warning", Value: "true"}, {Name: "x-removed", Value: "1"}},
+				Remove: []string{"X-Removed"},
+			},
+		}), "/mcp", "", "")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"x-tenant": "team-a", "ngrok-skip-browser-warning": "true"}, info.Headers)
+		require.Equal(t, "http://mcp-server.team-a.svc.cluster.local:8080/mcp", info.Endpoint)
+		require.Empty(t, info.BrokerEndpoint)
+	})
+
+	t.Run("added headers append to set headers", func(t *testing.T) {
+		info, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", nil, gatewayv1.HTTPRouteFilter{
+			Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+				Set: []gatewayv1.HTTPHeader{{Name: "X-Tenant", Value: "team-a"}},
+				Add: []gatewayv1.HTTPHeader{{Name: "x-tenant", Value: "team-b"}},
+			},
+		}), "/mcp", "", "")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"x-tenant": "team-a,team-b"}, info.Headers)
+	})
+
+	t.Run("path rewrite", func(t *testing.T) {
+		info, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", nil, gatewayv1.HTTPRouteFilter{
+			Type: gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Path: &gatewayv1.HTTPPathModifier{
+				Type:               gatewayv1.PrefixMatchHTTPPathModifier,
+				ReplacePrefixMatch: ptr.To("/v1/mcp"),
+			}},
+		}), "/mcp", "", "")
+		require.NoError(t, err)
+		// tool calls are routed with the path the route matches and rewritten by the gateway
+		require.Equal(t, "http://mcp-server.team-a.svc.cluster.local:8080/mcp", info.Endpoint)
+		require.Equal(t, "http://mcp-server.team-a.svc.cluster.local:8080/v1/mcp", info.BrokerEndpoint)
+		require.Nil(t, info.Headers)
+	})
+
+	t.Run("full path rewrite with a double slash", func(t *testing.T) {
+		info, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", nil, gatewayv1.HTTPRouteFilter{
+			Type: gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Path: &gatewayv1.HTTPPathModifier{
+				Type:            gatewayv1.FullPathHTTPPathModifier,
+				ReplaceFullPath: ptr.To("/v1//mcp"),
+			}},
+		}), "/mcp", "", "")
+		require.NoError(t, err)
+		require.Equal(t, "http://mcp-server.team-a.svc.cluster.local:8080/mcp", info.Endpoint)
+		require.Equal(t, "http://mcp-server.team-a.svc.cluster.local:8080/v1/mcp", info.BrokerEndpoint)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		_, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", nil), "/mcp?x=1", "", "")
+		require.Equal(t, ReasonInvalidPath, backendRefFailureReason(err))
+	})
+
+	t.Run("hostname rewrite of an ExternalName service", func(t *testing.T) {
+		info, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("tunnel", nil, hostnameRewrite), "/mcp", "", "")
+		require.NoError(t, err)
+		require.Equal(t, "https://tunnel.example.com:8080/mcp", info.Endpoint)
+		require.Equal(t, "https://abc.ngrok.example:8080/mcp", info.BrokerEndpoint)
+		require.Equal(t, "tunnel.example.com", info.Hostname)
+	})
+
+	t.Run("hostname rewrite of a cluster service", func(t *testing.T) {
+		_, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", nil, hostnameRewrite), "/mcp", "", "")
+		require.Equal(t, ReasonUnsupportedFilter, backendRefFailureReason(err))
+	})
+
+	t.Run("response filters are ignored", func(t *testing.T) {
+		info, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", nil, gatewayv1.HTTPRouteFilter{
+			Type:                   gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+			ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{Set: []gatewayv1.HTTPHeader{{Name: "Access-Control-Allow-Origin", Value: "*"}}},
+		}), "/mcp", "", "")
+		require.NoError(t, err)
+		require.Nil(t, info.Headers)
+	})
+
+	for _, filterType := range []gatewayv1.HTTPRouteFilterType{gatewayv1.HTTPRouteFilterRequestRedirect, gatewayv1.HTTPRouteFilterExtensionRef} {
+		t.Run(string(filterType), func(t *testing.T) {
+			_, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", nil, gatewayv1.HTTPRouteFilter{Type: filterType}), "/mcp", "", "")
+			require.Equal(t, ReasonUnsupportedFilter, backendRefFailureReason(err))
+			require.ErrorContains(t, err, fmt.Sprintf("HTTPRoute team-a/mcp has a %s filter for backendRef mcp-server", filterType))
+		})
+	}
+
+	t.Run("zero weight", func(t *testing.T) {
+		_, err := r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", ptr.To(int32(0))), "/mcp", "", "")
+		require.Equal(t, ReasonBackendRefNotFound, backendRefFailureReason(err))
+		require.ErrorContains(t, err, "has weight 0")
+
+		_, err = r.buildServerInfoFromHTTPRoute(context.Background(), route("mcp-server", ptr.To(int32(10))), "/mcp", "", "")
+		require.NoError(t, err)
+	})
+}
+
+// selfSignedCertificate returns a PEM certificate and key usable as a CA and as a client or serving certificate for
+// the dns names
+func selfSignedCertificate(t *testing.T, dnsNames ...string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mcp-broker"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		DNSNames:              dnsNames,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestSyncMCPServerConfig_Disabled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a"},
+		Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+			TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: "mcp-server"},
+			Path:      "/mcp",
+			Enabled:   ptr.To(false),
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: "team-a"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Name: "http", Port: 9090}}},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mcpsr, service).WithStatusSubresource(mcpsr).Build()
+	r := &MCPReconciler{
+		Client:             k8sClient,
+		DirectAPIReader:    k8sClient,
+		Scheme:             scheme,
+		ConfigReaderWriter: &config.SecretReaderWriter{Client: k8sClient, Scheme: scheme, Logger: slog.New(slog.DiscardHandler)},
+	}
+
+	// a disabled server keeps its config entry without waiting on the broker
+	result, err := r.syncMCPServerConfig(context.Background(), mcpsr, testServiceTargetRoute, []string{"mcp-system"}, false)
+	require.NoError(t, err)
+	require.Zero(t, result.RequeueAfter)
+
+	secret := &corev1.Secret{}
+	require.NoError(t, k8sClient.Get(context.Background(), config.DefaultNamespaceName, secret))
+	brokerConfig := &config.BrokerConfig{}
+	require.NoError(t, yaml.Unmarshal([]byte(secret.StringData["config.yaml"]), brokerConfig))
+	require.Len(t, brokerConfig.Servers, 1)
+	require.False(t, brokerConfig.Servers[0].Enabled)
+
+	fresh := &mcpv1alpha1.MCPServerRegistration{}
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(mcpsr), fresh))
+	readyCondition := meta.FindStatusCondition(fresh.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+	require.Equal(t, metav1.ConditionFalse, readyCondition.Status)
+	require.Equal(t, ReasonDisabled, readyCondition.Reason)
+	require.True(t, meta.IsStatusConditionTrue(fresh.Status.Conditions, mcpv1alpha1.ConditionTypeAccepted))
+}
+
+func TestBuildMCPServerConfig_CrossNamespaceCredential(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1beta1.Install(scheme))
+	credentialLabels := map[string]string{CredentialSecretLabel: CredentialSecretValue}
+	fromTeamA := gatewayv1beta1.ReferenceGrantFrom{Group: gatewayv1beta1.Group(mcpv1alpha1.GroupVersion.Group), Kind: "MCPServerRegistration", Namespace: "team-a"}
+	objects := []client.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: "team-a"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Name: "http", Port: 9090}}},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "credentials", Labels: credentialLabels},
+			Data:       map[string][]byte{"token": []byte("Bearer shared")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "private", Namespace: "credentials", Labels: credentialLabels},
+			Data:       map[string][]byte{"token": []byte("Bearer private")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "ungranted", Labels: credentialLabels},
+			Data:       map[string][]byte{"token": []byte("Bearer ungranted")},
+		},
+		referenceGrant("allow-team-a", "credentials", fromTeamA,
+			gatewayv1beta1.ReferenceGrantTo{Group: "", Kind: "Secret", Name: ptr.To(gatewayv1beta1.ObjectName("shared"))}),
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	r := &MCPReconciler{Client: k8sClient, DirectAPIReader: k8sClient, Scheme: scheme}
+	registration := func(credentialRef *mcpv1alpha1.SecretReference) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "registration", Namespace: "team-a"},
+			Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+				TargetRef:     mcpv1alpha1.TargetReference{Kind: "Service", Name: "mcp-server"},
+				Path:          "/mcp",
+				CredentialRef: credentialRef,
+			},
+		}
+	}
+
+	serverConfig, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute,
+		registration(&mcpv1alpha1.SecretReference{Name: "shared", Key: "token", Namespace: "credentials"}))
+	require.NoError(t, err)
+	require.Equal(t, "Bearer shared", serverConfig.Credential)
+
+	for _, denied := range []*mcpv1alpha1.SecretReference{
+		{Name: "private", Key: "token", Namespace: "credentials"},
+		{Name: "shared", Key: "token", Namespace: "ungranted"},
+	} {
+		_, err = r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration(denied))
+		require.ErrorIs(t, err, errCredentialReferenceNotPermitted)
+		require.Equal(t, ReasonCredentialRefGrantRequired, backendRefFailureReason(err))
+	}
+}
+
+func TestFindMCPServerRegistrationsForSecret_CrossNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	registration := func(namespace string, credentialRef *mcpv1alpha1.SecretReference) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "registration", Namespace: namespace},
+			Spec:       mcpv1alpha1.MCPServerRegistrationSpec{CredentialRef: credentialRef},
+		}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(
+			registration("credentials", &mcpv1alpha1.SecretReference{Name: "shared"}),
+			registration("team-a", &mcpv1alpha1.SecretReference{Name: "shared", Namespace: "credentials"}),
+			registration("team-b", &mcpv1alpha1.SecretReference{Name: "other", Namespace: "credentials"}),
+			registration("team-c", &mcpv1alpha1.SecretReference{Name: "shared"}),
+		).
+		WithIndex(&mcpv1alpha1.MCPServerRegistration{}, CredentialSecretIndex, credentialSecretIndexValues).
+		Build()
+	r := &MCPReconciler{Client: k8sClient, Scheme: scheme}
+
+	requests := r.findMCPServerRegistrationsForSecret(context.Background(),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "credentials"}})
+	require.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: client.ObjectKey{Name: "registration", Namespace: "credentials"}},
+		{NamespacedName: client.ObjectKey{Name: "registration", Namespace: "team-a"}},
+	}, requests)
+}
+
+func TestCleanupOrphanedHTTPRoutes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+	programmedRoute := func(name string) *gatewayv1.HTTPRoute {
+		return &gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"},
+			Status: gatewayv1.HTTPRouteStatus{RouteStatus: gatewayv1.RouteStatus{Parents: []gatewayv1.RouteParentStatus{{
+				Conditions: []metav1.Condition{
+					{Type: "Accepted", Status: metav1.ConditionTrue, Reason: "Accepted", LastTransitionTime: metav1.Now()},
+					{Type: "Programmed", Status: metav1.ConditionTrue, Reason: "InUseByMCPServerRegistration", LastTransitionTime: metav1.Now()},
+				},
+			}}}},
+		}
+	}
+	registration := func(name, route string, deleting bool) *mcpv1alpha1.MCPServerRegistration {
+		mcpsr := &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"},
+			Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+				TargetRef: mcpv1alpha1.TargetReference{Kind: "HTTPRoute", Name: route},
+			},
+		}
+		if deleting {
+			mcpsr.Finalizers = []string{mcpGatewayFinalizer}
+			mcpsr.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		return mcpsr
+	}
+	shared, orphaned := programmedRoute("shared"), programmedRoute("orphaned")
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(
+			shared, orphaned,
+			registration("first", "shared", true),
+			registration("second", "shared", false),
+			registration("only", "orphaned", true),
+		).
+		WithStatusSubresource(shared, orphaned).
+		WithIndex(&gatewayv1.HTTPRoute{}, ProgrammedHTTPRouteIndex, programmedHTTPRouteIndexValues).
+		WithIndex(&mcpv1alpha1.MCPServerRegistration{}, HTTPRouteIndex, httpRouteIndexValues).
+		Build()
+	r := &MCPReconciler{Client: k8sClient, Scheme: scheme}
+
+	require.NoError(t, r.cleanupOrphanedHTTPRoutes(context.Background()))
+
+	route := &gatewayv1.HTTPRoute{}
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(shared), route))
+	require.True(t, meta.IsStatusConditionTrue(route.Status.Parents[0].Conditions, "Programmed"))
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(orphaned), route))
+	require.Nil(t, meta.FindStatusCondition(route.Status.Parents[0].Conditions, "Programmed"))
+	require.True(t, meta.IsStatusConditionTrue(route.Status.Parents[0].Conditions, "Accepted"))
+}
+
+func TestBuildMCPServerConfig_ToolNameTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "docs", Namespace: "team-a"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+	}).Build()
+	r := &MCPReconciler{Client: k8sClient, DirectAPIReader: k8sClient, Scheme: scheme}
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "docs", Namespace: "team-a"},
+		Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+			TargetRef:        mcpv1alpha1.TargetReference{Kind: "Service", Name: "docs"},
+			Path:             "/mcp",
+			ToolPrefix:       "v1",
+			ToolNameTemplate: "{tool}_{server}_{prefix}",
+		},
+	}
+
+	serverConfig, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, mcpsr)
+	require.NoError(t, err)
+	require.Equal(t, "{tool}_docs_{prefix}", serverConfig.ToolNameTemplate)
+	require.Equal(t, "search_docs_v1", serverConfig.ServedToolName("search"))
+	upstreamName, ok := serverConfig.UpstreamToolName("search_docs_v1")
+	require.True(t, ok)
+	require.Equal(t, "search", upstreamName)
+}
+
+func TestBuildMCPServerConfig_CallTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "reports", Namespace: "team-a"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+	}).Build()
+	r := &MCPReconciler{Client: k8sClient, DirectAPIReader: k8sClient, Scheme: scheme}
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "reports", Namespace: "team-a"},
+		Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+			TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: "reports"},
+			Path:      "/mcp",
+		},
+	}
+
+	serverConfig, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, mcpsr)
+	require.NoError(t, err)
+	require.Empty(t, serverConfig.CallTimeout)
+
+	mcpsr.Spec.CallTimeout = &metav1.Duration{Duration: 2 * time.Minute}
+	serverConfig, err = r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, mcpsr)
+	require.NoError(t, err)
+	require.Equal(t, "2m0s", serverConfig.CallTimeout)
+	timeout, err := serverConfig.RequestTimeout()
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Minute, timeout)
+}
+
+func TestBuildMCPServerConfig_CreationTimestamp(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "reports", Namespace: "team-a"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+	}).Build()
+	r := &MCPReconciler{Client: k8sClient, DirectAPIReader: k8sClient, Scheme: scheme}
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "reports", Namespace: "team-a"},
+		Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+			TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: "reports"},
+			Path:      "/mcp",
+		},
+	}
+
+	// an unknown creation time is left unset rather than written as a time before the epoch
+	serverConfig, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, mcpsr)
+	require.NoError(t, err)
+	require.Zero(t, serverConfig.CreationTimestamp)
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mcpsr.CreationTimestamp = metav1.NewTime(created)
+	serverConfig, err = r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, mcpsr)
+	require.NoError(t, err)
+	require.Equal(t, created.Unix(), serverConfig.CreationTimestamp)
+}
+
+func TestTargetServiceHasReadyEndpoints(t *testing.T) {
+	endpointSlice := func(namespace string, ready *bool) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "mcp-server-abc12",
+				Namespace: namespace,
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "mcp-server"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.10"}, Conditions: discoveryv1.EndpointConditions{Ready: ready}}},
+		}
+	}
+	clusterIPService := func(namespace string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: namespace},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 8080}}},
+		}
+	}
+	httpRoute := func(namespace string, backendRef gatewayv1.BackendObjectReference) *gatewayv1.HTTPRoute {
+		return &gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: namespace},
+			Spec: gatewayv1.HTTPRouteSpec{
+				Hostnames: []gatewayv1.Hostname{"docs.mcp.local"},
+				Rules:     []gatewayv1.HTTPRouteRule{{BackendRefs: []gatewayv1.HTTPBackendRef{{BackendRef: gatewayv1.BackendRef{BackendObjectReference: backendRef}}}}},
+			},
+		}
+	}
+	serviceBackend := gatewayv1.BackendObjectReference{Name: "mcp-server", Port: ptr.To(gatewayv1.PortNumber(8080))}
+	testCases := []struct {
+		name      string
+		kind      string
+		namespace string
+		objects   []client.Object
+		expected  bool
+	}{
+		{
+			name:     "httproute with a hostname backend is not checked",
+			kind:     "HTTPRoute",
+			objects:  []client.Object{httpRoute("team-a", gatewayv1.BackendObjectReference{Group: ptr.To(gatewayv1.Group("networking.istio.io")), Kind: ptr.To(gatewayv1.Kind("Hostname")), Name: "mcp.example.com"})},
+			expected: true,
+		},
+		{
+			name:     "httproute backend service without endpoint slices",
+			kind:     "HTTPRoute",
+			objects:  []client.Object{httpRoute("team-a", serviceBackend), clusterIPService("team-a")},
+			expected: false,
+		},
+		{
+			name:     "httproute backend service with a ready endpoint",
+			kind:     "HTTPRoute",
+			objects:  []client.Object{httpRoute("team-a", serviceBackend), clusterIPService("team-a"), endpointSlice("team-a", ptr.To(true))},
+			expected: true,
+		},
+		{
+			name: "httproute backend service in another namespace",
+			kind: "HTTPRoute",
+			objects: []client.Object{
+				httpRoute("team-a", gatewayv1.BackendObjectReference{Name: "mcp-server", Namespace: ptr.To(gatewayv1.Namespace("team-b")), Port: ptr.To(gatewayv1.PortNumber(8080))}),
+				clusterIPService("team-b"), endpointSlice("team-a", ptr.To(true)),
+			},
+			expected: false,
+		},
+		{
+			name: "external name service is not checked",
+			kind: "Service",
+			objects: []client.Object{&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: "team-a"},
+				Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "mcp.example.com"},
+			}},
+			expected: true,
+		},
+		{
+			name:     "service without endpoint slices",
+			kind:     "Service",
+			objects:  []client.Object{clusterIPService("team-a")},
+			expected: false,
+		},
+		{
+			name:     "service with only unready endpoints",
+			kind:     "Service",
+			objects:  []client.Object{clusterIPService("team-a"), endpointSlice("team-a", ptr.To(false))},
+			expected: false,
+		},
+		{
+			name:     "service with a ready endpoint",
+			kind:     "Service",
+			objects:  []client.Object{clusterIPService("team-a"), endpointSlice("team-a", ptr.To(true))},
+			expected: true,
+		},
+		{
+			name:     "unknown readiness counts as ready",
+			kind:     "Service",
+			objects:  []client.Object{clusterIPService("team-a"), endpointSlice("team-a", nil)},
+			expected: true,
+		},
+		{
+			name:      "service in the target namespace",
+			kind:      "Service",
+			namespace: "team-b",
+			objects:   []client.Object{clusterIPService("team-b"), endpointSlice("team-a", ptr.To(true))},
+			expected:  false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, corev1.AddToScheme(scheme))
+			require.NoError(t, discoveryv1.AddToScheme(scheme))
+			require.NoError(t, gatewayv1.Install(scheme))
+			r := &MCPReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build(), Scheme: scheme}
+			mcpsr := &mcpv1alpha1.MCPServerRegistration{
+				ObjectMeta: metav1.ObjectMeta{Name: "docs", Namespace: "team-a"},
+				Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+					TargetRef: mcpv1alpha1.TargetReference{Kind: tc.kind, Name: "mcp-server", Namespace: tc.namespace},
+				},
+			}
+			hasEndpoints, err := r.targetServiceHasReadyEndpoints(context.Background(), mcpsr)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, hasEndpoints)
+		})
+	}
+}
+
+func TestGetTargetHTTPRoute_CrossNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, gatewayv1.Install(scheme))
+	foreignRoute := &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: "team-b"}}
+	r := &MCPReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(foreignRoute).Build(), Scheme: scheme}
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "docs", Namespace: "team-a"},
+		Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+			TargetRef: mcpv1alpha1.TargetReference{Kind: "HTTPRoute", Name: "mcp-server", Namespace: "team-b"},
+		},
+	}
+
+	_, err := r.getTargetHTTPRoute(context.Background(), mcpsr)
+	require.ErrorContains(t, err, "targeted httproute team-b/mcp-server is not in the namespace of the registration")
+
+	// without a namespace the route is looked up in the namespace of the registration
+	mcpsr.Spec.TargetRef.Namespace = ""
+	_, err = r.getTargetHTTPRoute(context.Background(), mcpsr)
+	require.True(t, apierrors.IsNotFound(err))
+}
+
+func TestFindMCPServerRegistrationsForEndpointSlice(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+	registration := func(name string, targetRef mcpv1alpha1.TargetReference) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"},
+			Spec:       mcpv1alpha1.MCPServerRegistrationSpec{TargetRef: targetRef},
+		}
+	}
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "docs-route", Namespace: "team-a"},
+		Spec: gatewayv1.HTTPRouteSpec{Rules: []gatewayv1.HTTPRouteRule{{BackendRefs: []gatewayv1.HTTPBackendRef{
+			{BackendRef: gatewayv1.BackendRef{BackendObjectReference: gatewayv1.BackendObjectReference{Name: "mcp-server", Namespace: ptr.To(gatewayv1.Namespace("team-b"))}}},
+		}}}},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&mcpv1alpha1.MCPServerRegistration{}, ServiceIndex, serviceIndexValues).
+		WithIndex(&mcpv1alpha1.MCPServerRegistration{}, HTTPRouteIndex, httpRouteIndexValues).
+		WithIndex(&gatewayv1.HTTPRoute{}, HTTPRouteBackendServiceIndex, httpRouteBackendServiceIndexValues).
+		WithObjects(
+			route,
+			registration("by-service", mcpv1alpha1.TargetReference{Kind: "Service", Name: "mcp-server", Namespace: "team-b"}),
+			registration("by-route", mcpv1alpha1.TargetReference{Kind: "HTTPRoute", Name: "docs-route"}),
+			registration("other", mcpv1alpha1.TargetReference{Kind: "Service", Name: "mcp-server"}),
+		).Build()
+	r := &MCPReconciler{Client: k8sClient, Scheme: scheme}
+
+	slice := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{
+		Name:      "mcp-server-abc12",
+		Namespace: "team-b",
+		Labels:    map[string]string{discoveryv1.LabelServiceName: "mcp-server"},
+	}}
+	var names []string
+	for _, request := range r.findMCPServerRegistrationsForEndpointSlice(context.Background(), slice) {
+		names = append(names, request.Name)
+	}
+	require.ElementsMatch(t, []string{"by-service", "by-route"}, names)
+}
+
+func TestExtensionDeletedSource(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a"},
+		Spec:       mcpv1alpha1.MCPServerRegistrationSpec{TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: "weather"}},
+	}
+	extensionDeleted := make(chan event.GenericEvent, 1)
+	r := &MCPReconciler{
+		Client:           fake.NewClientBuilder().WithScheme(scheme).WithObjects(mcpsr).Build(),
+		Scheme:           scheme,
+		ExtensionDeleted: extensionDeleted,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+	require.NoError(t, r.extensionDeletedSource().Start(ctx, queue))
+
+	extensionDeleted <- event.GenericEvent{Object: testExtension("team-a-mcp")}
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	request, _ := queue.Get()
+	require.Equal(t, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(mcpsr)}, request)
+}
+
+func TestServedToolNamePrefix(t *testing.T) {
+	registration := func(prefix, template string) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "docs", Namespace: "team-a"},
+			Spec:       mcpv1alpha1.MCPServerRegistrationSpec{ToolPrefix: prefix, ToolNameTemplate: template},
+		}
+	}
+	require.Equal(t, "docs_", servedToolNamePrefix(registration("docs_", "")))
+	require.Equal(t, "docs.", servedToolNamePrefix(registration("", "{server}.{tool}")))
+	require.Equal(t, "v1-docs-", servedToolNamePrefix(registration("v1", "{prefix}-{server}-{tool}")))
+	require.Empty(t, servedToolNamePrefix(registration("docs_", "{tool}_{server}")))
+}
+
+func TestBuildMCPServerConfig_TLS(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	certPEM, keyPEM := selfSignedCertificate(t)
+	credentialLabels := map[string]string{CredentialSecretLabel: CredentialSecretValue}
+	objects := []client.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "team-a"},
+			Spec: corev1.ServiceSpec{
+				Type:         corev1.ServiceTypeExternalName,
+				ExternalName: "mcp.example.com",
+				Ports:        []corev1.ServicePort{{Name: "https", Port: 443, AppProtocol: ptr.To("https")}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "team-a"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "mcp-ca", Namespace: "team-a", Labels: credentialLabels},
+			Data:       map[string][]byte{"ca.crt": certPEM},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "broker-client", Namespace: "team-a", Labels: credentialLabels},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "unlabelled-ca", Namespace: "team-a"},
+			Data:       map[string][]byte{"ca.crt": certPEM},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	r := &MCPReconciler{Client: k8sClient, DirectAPIReader: k8sClient, Scheme: scheme}
+	registration := func(service string, upstreamTLS *mcpv1alpha1.UpstreamTLS) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "registration", Namespace: "team-a"},
+			Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+				TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: service},
+				Path:      "/mcp",
+				TLS:       upstreamTLS,
+			},
+		}
+	}
+	caRef := &mcpv1alpha1.CASecretReference{Name: "mcp-ca", Key: "ca.crt"}
+
+	t.Run("CA only", func(t *testing.T) {
+		serverConfig, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("external", &mcpv1alpha1.UpstreamTLS{CASecretRef: caRef}))
+		require.NoError(t, err)
+		require.Equal(t, &config.TLSConfig{CACert: string(certPEM)}, serverConfig.TLS)
+	})
+
+	t.Run("mTLS", func(t *testing.T) {
+		serverConfig, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("external", &mcpv1alpha1.UpstreamTLS{
+			CASecretRef:                caRef,
+			ClientCertificateSecretRef: &mcpv1alpha1.ClientCertificateSecretReference{Name: "broker-client"},
+		}))
+		require.NoError(t, err)
+		require.Equal(t, &config.TLSConfig{CACert: string(certPEM), ClientCert: string(certPEM), ClientKey: string(keyPEM)}, serverConfig.TLS)
+	})
+
+	t.Run("no tls", func(t *testing.T) {
+		serverConfig, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("external", nil))
+		require.NoError(t, err)
+		require.Nil(t, serverConfig.TLS)
+	})
+
+	t.Run("plain http endpoint", func(t *testing.T) {
+		_, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("plain", &mcpv1alpha1.UpstreamTLS{CASecretRef: caRef}))
+		require.ErrorContains(t, err, "is not https")
+	})
+
+	t.Run("secret without the credential label", func(t *testing.T) {
+		_, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("external", &mcpv1alpha1.UpstreamTLS{
+			CASecretRef: &mcpv1alpha1.CASecretReference{Name: "unlabelled-ca", Key: "ca.crt"},
+		}))
+		require.ErrorContains(t, err, "tls secret unlabelled-ca is missing required label")
+	})
+
+	t.Run("client certificate secret missing its key", func(t *testing.T) {
+		_, err := r.buildMCPServerConfig(context.Background(), testServiceTargetRoute, registration("external", &mcpv1alpha1.UpstreamTLS{
+			ClientCertificateSecretRef: &mcpv1alpha1.ClientCertificateSecretReference{Name: "mcp-ca"},
+		}))
+		require.ErrorContains(t, err, `secret mcp-ca is missing required data entry "tls.crt"`)
+	})
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+// MCPServerRegistrationValidator rejects registrations at admission that the reconciler could never make ready,
+// such as a credential secret without the credential label or the referenced key
+type MCPServerRegistrationValidator struct {
+	// Reader reads secrets directly from the API server so the webhook does not cache every secret
+	Reader client.Reader
+	// CredentialLabel is the label credential Secrets must carry. The zero value uses DefaultCredentialLabel
+	CredentialLabel CredentialLabel
+}
+
+// SetupWebhookWithManager registers the validating webhook with the manager's webhook server
+func (v *MCPServerRegistrationValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &mcpv1alpha1.MCPServerRegistration{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate validates a new registration
+func (v *MCPServerRegistrationValidator) ValidateCreate(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (admission.Warnings, error) {
+	return v.validate(ctx, mcpsr)
+}
+
+// ValidateUpdate validates a changed registration. A registration being deleted, or keeping its credentialRef, is
+// not validated again so a Secret changed or deleted since does not block status updates or finalizer removal
+func (v *MCPServerRegistrationValidator) ValidateUpdate(ctx context.Context, oldMCPSR, mcpsr *mcpv1alpha1.MCPServerRegistration) (admission.Warnings, error) {
+	if !mcpsr.DeletionTimestamp.IsZero() || ptr.Equal(oldMCPSR.Spec.CredentialRef, mcpsr.Spec.CredentialRef) {
+		return nil, nil
+	}
+	return v.validate(ctx, mcpsr)
+}
+
+// ValidateDelete allows every delete
+func (v *MCPServerRegistrationValidator) ValidateDelete(_ context.Context, _ *mcpv1alpha1.MCPServerRegistration) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *MCPServerRegistrationValidator) validate(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	switch mcpsr.Spec.TargetRef.Kind {
+	case "HTTPRoute", "Service":
+	default:
+		errs = append(errs, field.NotSupported(specPath.Child("targetRef", "kind"), mcpsr.Spec.TargetRef.Kind, []string{"HTTPRoute", "Service"}))
+	}
+
+	if ref := mcpsr.Spec.CredentialRef; ref != nil {
+		warning, err := v.validateCredentialRef(ctx, mcpsr, ref)
+		var invalid *field.Error
+		switch {
+		case errors.As(err, &invalid):
+			errs = append(errs, invalid)
+		case err != nil:
+			return nil, err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	if len(errs) > 0 {
+		return warnings, apierrors.NewInvalid(mcpv1alpha1.GroupVersion.WithKind("MCPServerRegistration").GroupKind(), mcpsr.Name, errs)
+	}
+	return warnings, nil
+}
+
+// validateCredentialRef checks the credential secret of the registration. It returns a warning for a secret that may
+// be created or granted after the registration, and a *field.Error for a secret the reconciler would reject
+func (v *MCPServerRegistrationValidator) validateCredentialRef(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration, ref *mcpv1alpha1.SecretReference) (string, error) {
+	secretNamespace := credentialSecretNamespace(mcpsr)
+	if secretNamespace != mcpsr.Namespace {
+		permitted, err := hasReferenceGrant(ctx, v.Reader,
+			grantReference{Group: mcpv1alpha1.GroupVersion.Group, Kind: "MCPServerRegistration", Namespace: mcpsr.Namespace},
+			grantReference{Group: "", Kind: "Secret", Namespace: secretNamespace, Name: ref.Name})
+		if err != nil {
+			return "", err
+		}
+		if !permitted {
+			// the grant may be created after the registration, the reconciler reports it until then. The secret is
+			// not read so a registration learns nothing about a secret it has not been granted
+			return fmt.Sprintf("no ReferenceGrant in %s allows this registration to reference credential secret %s, the registration will not be ready until one exists", secretNamespace, ref.Name), nil
+		}
+	}
+	secret := &corev1.Secret{}
+	err := v.Reader.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: secretNamespace}, secret)
+	switch {
+	case apierrors.IsNotFound(err):
+		// the secret may be created after the registration, the reconciler reports it until then
+		return fmt.Sprintf("credential secret %s not found, the registration will not be ready until it exists", ref.Name), nil
+	case err != nil:
+		return "", fmt.Errorf("failed to get credential secret: %w", err)
+	}
+	key := ref.Key
+	if key == "" {
+		key = "token"
+	}
+	if err := validateCredentialSecret(secret, key, v.CredentialLabel); err != nil {
+		return "", field.Invalid(field.NewPath("spec", "credentialRef"), ref.Name, err.Error())
+	}
+	return "", nil
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+func TestMCPServerRegistrationValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1beta1.Install(scheme))
+	credentialLabels := map[string]string{CredentialSecretLabel: CredentialSecretValue}
+	valid := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "valid", Namespace: "test-ns", Labels: credentialLabels},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	unlabeled := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "unlabeled", Namespace: "test-ns"},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	noKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-key", Namespace: "test-ns", Labels: credentialLabels},
+		Data:       map[string][]byte{"other": []byte("secret")},
+	}
+	shared := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "shared-ns", Labels: credentialLabels},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	ungranted := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ungranted", Namespace: "other-ns", Labels: credentialLabels},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	// an ungranted secret is not read, so its missing label is not reported
+	ungrantedUnlabeled := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ungranted-unlabeled", Namespace: "other-ns"},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	grant := referenceGrant("allow-test-ns", "shared-ns",
+		gatewayv1beta1.ReferenceGrantFrom{Group: gatewayv1beta1.Group(mcpv1alpha1.GroupVersion.Group), Kind: "MCPServerRegistration", Namespace: "test-ns"},
+		gatewayv1beta1.ReferenceGrantTo{Group: "", Kind: "Secret"})
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(valid, unlabeled, noKey, shared, ungranted, ungrantedUnlabeled, grant).Build()
+	v := &MCPServerRegistrationValidator{Reader: reader}
+
+	tests := []struct {
+		name        string
+		kind        string
+		credential  *mcpv1alpha1.SecretReference
+		wantInvalid bool
+		wantWarning bool
+	}{
+		{name: "no credential", kind: "HTTPRoute"},
+		{name: "valid credential", kind: "HTTPRoute", credential: &mcpv1alpha1.SecretReference{Name: "valid", Key: "token"}},
+		{name: "key defaults to token", kind: "Service", credential: &mcpv1alpha1.SecretReference{Name: "valid"}},
+		{name: "missing label", kind: "HTTPRoute", credential: &mcpv1alpha1.SecretReference{Name: "unlabeled", Key: "token"}, wantInvalid: true},
+		{name: "missing key", kind: "HTTPRoute", credential: &mcpv1alpha1.SecretReference{Name: "no-key", Key: "token"}, wantInvalid: true},
+		{name: "missing secret warns", kind: "HTTPRoute", credential: &mcpv1alpha1.SecretReference{Name: "missing", Key: "token"}, wantWarning: true},
+		{name: "granted cross-namespace credential", kind: "HTTPRoute", credential: &mcpv1alpha1.SecretReference{Name: "shared", Key: "token", Namespace: "shared-ns"}},
+		{name: "ungranted cross-namespace credential warns", kind: "HTTPRoute", credential: &mcpv1alpha1.SecretReference{Name: "ungranted", Key: "token", Namespace: "other-ns"}, wantWarning: true},
+		{name: "ungranted cross-namespace secret is not read", kind: "HTTPRoute", credential: &mcpv1alpha1.SecretReference{Name: "ungranted-unlabeled", Key: "token", Namespace: "other-ns"}, wantWarning: true},
+		{name: "unsupported kind", kind: "Gateway", wantInvalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpsr := &mcpv1alpha1.MCPServerRegistration{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-mcpsr", Namespace: "test-ns"},
+				Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+					TargetRef:     mcpv1alpha1.TargetReference{Kind: tt.kind, Name: "target"},
+					CredentialRef: tt.credential,
+				},
+			}
+			warnings, err := v.ValidateCreate(context.Background(), mcpsr)
+			if tt.wantInvalid {
+				require.True(t, apierrors.IsInvalid(err), "expected invalid error, got %v", err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantWarning, len(warnings) > 0)
+		})
+	}
+}
+
+func TestMCPServerRegistrationValidator_ValidateUpdate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1beta1.Install(scheme))
+	unlabeled := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "unlabeled", Namespace: "test-ns"},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	v := &MCPServerRegistrationValidator{Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(unlabeled).Build()}
+	registration := func(secretName string) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-mcpsr", Namespace: "test-ns"},
+			Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+				TargetRef:     mcpv1alpha1.TargetReference{Kind: "HTTPRoute", Name: "target"},
+				CredentialRef: &mcpv1alpha1.SecretReference{Name: secretName, Key: "token"},
+			},
+		}
+	}
+
+	t.Run("deleting a registration whose secret is gone", func(t *testing.T) {
+		oldMCPSR := registration("deleted")
+		deleting := registration("deleted")
+		deleting.DeletionTimestamp = ptr.To(metav1.Now())
+		deleting.Finalizers = nil
+		warnings, err := v.ValidateUpdate(context.Background(), oldMCPSR, deleting)
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+	})
+
+	t.Run("deleting a registration whose secret is no longer valid", func(t *testing.T) {
+		deleting := registration("unlabeled")
+		deleting.DeletionTimestamp = ptr.To(metav1.Now())
+		_, err := v.ValidateUpdate(context.Background(), registration("valid"), deleting)
+		require.NoError(t, err)
+	})
+
+	t.Run("unchanged credentialRef", func(t *testing.T) {
+		updated := registration("unlabeled")
+		updated.Spec.ToolPrefix = "changed_"
+		warnings, err := v.ValidateUpdate(context.Background(), registration("unlabeled"), updated)
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+	})
+
+	t.Run("changed credentialRef", func(t *testing.T) {
+		_, err := v.ValidateUpdate(context.Background(), registration("missing"), registration("unlabeled"))
+		require.True(t, apierrors.IsInvalid(err), "expected invalid error, got %v", err)
+	})
+}
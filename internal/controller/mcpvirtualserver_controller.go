@@ -4,16 +4,24 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
 	"github.com/Kuadrant/mcp-gateway/internal/config"
@@ -31,17 +39,34 @@ type MCPVirtualServerReconciler struct {
 	Scheme             *runtime.Scheme
 	log                *slog.Logger
 	ConfigReaderWriter VirtualServerConfigReaderWriter
+	ReconcileTiming    *ReconcileTiming
 }
 
 var defaultRequeueTime = time.Second * 2
 
+const (
+	// ReasonToolsResolved is reported when every tool and server of a virtual server resolves to a Ready registration
+	ReasonToolsResolved = "ToolsResolved"
+	// ReasonToolsPartiallyResolved is reported when some but not all tools and servers of a virtual server resolve
+	ReasonToolsPartiallyResolved = "ToolsPartiallyResolved"
+	// ReasonNoToolsResolved is reported when none of the tools or servers of a virtual server resolve, so it serves no tools
+	ReasonNoToolsResolved = "NoToolsResolved"
+	// VirtualServerRegistrationIndex used to find MCPVirtualServers listing a registration in spec.servers
+	VirtualServerRegistrationIndex = "spec.servers.registration"
+	// VirtualServerToolsIndex used to find MCPVirtualServers with tools in spec.tools
+	VirtualServerToolsIndex = "spec.hasTools"
+)
+
 // +kubebuilder:rbac:groups=mcp.kagenti.com,resources=mcpvirtualservers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=mcp.kagenti.com,resources=mcpvirtualservers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=mcp.kagenti.com,resources=mcpvirtualservers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=mcp.kagenti.com,resources=mcpserverregistrations,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-func (r *MCPVirtualServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *MCPVirtualServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := startSpan(ctx, "MCPVirtualServer.Reconcile", "MCPVirtualServer", req.NamespacedName)
+	defer func() { endSpan(span, err) }()
 	logger := log.FromContext(ctx)
 
 	mcpVS := &mcpv1alpha1.MCPVirtualServer{}
@@ -91,11 +116,191 @@ func (r *MCPVirtualServerReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		}
 		return ctrl.Result{}, fmt.Errorf("mcpvirtualserver failed to write virtual server config during reconcile %w", err)
 	}
+	if err := r.updateStatus(ctx, mcpVS); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: defaultRequeueTime}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("mcpvirtualserver failed to update status %w", err)
+	}
 	logger.V(1).Info("mcpvirtualserver reconcile complete", "name", mcpVS.Name, "namespace", mcpVS.Namespace)
-	// update status of virtual server
 	return ctrl.Result{}, nil
 }
 
+// updateStatus records how many of the virtual server's tools are served by a Ready MCPServerRegistration, whether it
+// is Ready and the generation it was processed at
+func (r *MCPVirtualServerReconciler) updateStatus(ctx context.Context, mcpVS *mcpv1alpha1.MCPVirtualServer) error {
+	registrations := &mcpv1alpha1.MCPServerRegistrationList{}
+	if err := r.List(ctx, registrations); err != nil {
+		return err
+	}
+	status := resolveVirtualServerTools(mcpVS.Spec.Tools, registrations.Items)
+	status.ObservedGeneration = mcpVS.Generation
+	status.UnresolvedServers = unresolvedVirtualServerServers(mcpVS, registrations.Items)
+	if len(status.UnresolvedServers) > 0 {
+		log.FromContext(ctx).Info("mcpvirtualserver references registrations that do not exist or are not ready", "name", mcpVS.Name, "namespace", mcpVS.Namespace, "registrations", status.UnresolvedServers)
+	}
+	if len(status.UnresolvedTools) > 0 {
+		log.FromContext(ctx).Info("mcpvirtualserver references tools no ready registration serves", "name", mcpVS.Name, "namespace", mcpVS.Namespace, "tools", status.UnresolvedTools)
+	}
+	status.Conditions = slices.Clone(mcpVS.Status.Conditions)
+	conditionChanged := meta.SetStatusCondition(&status.Conditions, virtualServerReadyCondition(mcpVS, status))
+	if !conditionChanged && status.ObservedGeneration == mcpVS.Status.ObservedGeneration && status.ResolvedTools == mcpVS.Status.ResolvedTools &&
+		slices.Equal(status.UnresolvedTools, mcpVS.Status.UnresolvedTools) && slices.Equal(status.UnresolvedServers, mcpVS.Status.UnresolvedServers) {
+		return nil
+	}
+	mcpVS.Status = status
+	return r.Status().Update(ctx, mcpVS)
+}
+
+// virtualServerReadyCondition reports the virtual server Ready when at least one of its tools or servers resolves to a
+// Ready MCPServerRegistration. The message lists what does not resolve so a typo is surfaced rather than silently
+// serving fewer tools
+func virtualServerReadyCondition(mcpVS *mcpv1alpha1.MCPVirtualServer, status mcpv1alpha1.MCPVirtualServerStatus) metav1.Condition {
+	var servers []string
+	for _, server := range mcpVS.Spec.Servers {
+		if name := server.RegistrationName(mcpVS.Namespace); !slices.Contains(servers, name) {
+			servers = append(servers, name)
+		}
+	}
+	resolved := status.ResolvedTools + len(servers) - len(status.UnresolvedServers)
+	var unresolved []string
+	if len(status.UnresolvedTools) > 0 {
+		unresolved = append(unresolved, fmt.Sprintf("tools %s", strings.Join(status.UnresolvedTools, ", ")))
+	}
+	if len(status.UnresolvedServers) > 0 {
+		unresolved = append(unresolved, fmt.Sprintf("servers %s", strings.Join(status.UnresolvedServers, ", ")))
+	}
+	condition := metav1.Condition{
+		Type:               mcpv1alpha1.ConditionTypeReady,
+		ObservedGeneration: mcpVS.Generation,
+	}
+	switch {
+	case len(unresolved) == 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonToolsResolved
+		condition.Message = "all tools and servers resolve to a Ready MCPServerRegistration"
+	case resolved > 0:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonToolsPartiallyResolved
+		condition.Message = fmt.Sprintf("%s do not resolve to a Ready MCPServerRegistration", strings.Join(unresolved, " and "))
+	default:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonNoToolsResolved
+		condition.Message = fmt.Sprintf("no tools are served: %s do not resolve to a Ready MCPServerRegistration", strings.Join(unresolved, " and "))
+	}
+	return condition
+}
+
+// unresolvedVirtualServerServers returns the sorted namespace/name of the registrations in spec.servers that do not
+// exist or are not Ready
+func unresolvedVirtualServerServers(mcpVS *mcpv1alpha1.MCPVirtualServer, registrations []mcpv1alpha1.MCPServerRegistration) []string {
+	var unresolved []string
+	for _, server := range mcpVS.Spec.Servers {
+		name := server.RegistrationName(mcpVS.Namespace)
+		if slices.ContainsFunc(registrations, func(registration mcpv1alpha1.MCPServerRegistration) bool {
+			return mcpServerName(&registration) == name && registration.DeletionTimestamp == nil &&
+				meta.IsStatusConditionTrue(registration.Status.Conditions, "Ready")
+		}) {
+			continue
+		}
+		if !slices.Contains(unresolved, name) {
+			unresolved = append(unresolved, name)
+		}
+	}
+	slices.Sort(unresolved)
+	return unresolved
+}
+
+// resolveVirtualServerTools matches each tool against the prefix of the tool names served by the Ready registrations.
+// A Ready registration without a prefix, such as one without a tool prefix, may serve any tool so it resolves every tool
+func resolveVirtualServerTools(tools []string, registrations []mcpv1alpha1.MCPServerRegistration) mcpv1alpha1.MCPVirtualServerStatus {
+	var prefixes []string
+	for _, registration := range registrations {
+		if registration.DeletionTimestamp != nil || !meta.IsStatusConditionTrue(registration.Status.Conditions, "Ready") {
+			continue
+		}
+		prefixes = append(prefixes, servedToolNamePrefix(&registration))
+	}
+	status := mcpv1alpha1.MCPVirtualServerStatus{}
+	for _, tool := range tools {
+		if slices.ContainsFunc(prefixes, func(prefix string) bool { return toolMatchesPrefix(tool, prefix) }) {
+			status.ResolvedTools++
+			continue
+		}
+		status.UnresolvedTools = append(status.UnresolvedTools, tool)
+	}
+	return status
+}
+
+// toolMatchesPrefix checks if a virtual server tool, which may be a pattern such as weather_*, can match a tool served
+// with the prefix. A pattern matches when the text before its first * and the prefix agree
+func toolMatchesPrefix(tool, prefix string) bool {
+	head, _, isPattern := strings.Cut(tool, "*")
+	if !isPattern {
+		return strings.HasPrefix(tool, prefix)
+	}
+	return strings.HasPrefix(head, prefix) || strings.HasPrefix(prefix, head)
+}
+
+// findVirtualServersForRegistration enqueues the MCPVirtualServers that reference the registration, as decided by
+// virtualServerReferencesRegistration. Updates map both the old and new registration so a virtual server
+// that stops matching is also enqueued
+func (r *MCPVirtualServerReconciler) findVirtualServersForRegistration(ctx context.Context, obj client.Object) []reconcile.Request {
+	mcpsr, ok := obj.(*mcpv1alpha1.MCPServerRegistration)
+	if !ok {
+		return nil
+	}
+	byServer := &mcpv1alpha1.MCPVirtualServerList{}
+	if err := r.List(ctx, byServer, client.MatchingFields{VirtualServerRegistrationIndex: mcpServerName(mcpsr)}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list MCPVirtualServers for registration")
+		return nil
+	}
+	byTools := &mcpv1alpha1.MCPVirtualServerList{}
+	if err := r.List(ctx, byTools, client.MatchingFields{VirtualServerToolsIndex: "true"}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list MCPVirtualServers with tools")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, mcpVS := range append(byServer.Items, byTools.Items...) {
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: mcpVS.Name, Namespace: mcpVS.Namespace}}
+		if slices.Contains(requests, request) {
+			continue
+		}
+		if !virtualServerReferencesRegistration(&mcpVS, mcpsr) {
+			continue
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+func setupIndexVirtualServerToRegistration(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &mcpv1alpha1.MCPVirtualServer{}, VirtualServerRegistrationIndex, virtualServerRegistrationIndexValues); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &mcpv1alpha1.MCPVirtualServer{}, VirtualServerToolsIndex, virtualServerToolsIndexValues)
+}
+
+// virtualServerToolsIndexValues returns "true" for virtual servers with tools in spec.tools
+func virtualServerToolsIndexValues(rawObj client.Object) []string {
+	if len(rawObj.(*mcpv1alpha1.MCPVirtualServer).Spec.Tools) > 0 {
+		return []string{"true"}
+	}
+	return []string{}
+}
+
+// virtualServerRegistrationIndexValues returns the namespace/name of the registrations listed in spec.servers
+func virtualServerRegistrationIndexValues(rawObj client.Object) []string {
+	mcpVS := rawObj.(*mcpv1alpha1.MCPVirtualServer)
+	values := []string{}
+	for _, server := range mcpVS.Spec.Servers {
+		if name := server.RegistrationName(mcpVS.Namespace); !slices.Contains(values, name) {
+			values = append(values, name)
+		}
+	}
+	return values
+}
+
 func (r *MCPVirtualServerReconciler) generateVirtualServerConfig(ctx context.Context) ([]config.VirtualServerConfig, error) {
 	log := log.FromContext(ctx)
 	virtualServers := []config.VirtualServerConfig{}
@@ -110,20 +315,38 @@ func (r *MCPVirtualServerReconciler) generateVirtualServerConfig(ctx context.Con
 			continue
 		}
 		virtualServerName := fmt.Sprintf("%s/%s", mcpVirtualServer.Namespace, mcpVirtualServer.Name)
+		var servers []config.VirtualServerScope
+		for _, server := range mcpVirtualServer.Spec.Servers {
+			servers = append(servers, config.VirtualServerScope{
+				Name:  server.RegistrationName(mcpVirtualServer.Namespace),
+				Tools: server.Tools,
+			})
+		}
 		virtualServers = append(virtualServers, config.VirtualServerConfig{
-			Name:  virtualServerName,
-			Tools: mcpVirtualServer.Spec.Tools,
+			Name:    virtualServerName,
+			Tools:   mcpVirtualServer.Spec.Tools,
+			Servers: servers,
 		})
 	}
 	return virtualServers, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *MCPVirtualServerReconciler) SetupWithManager(_ context.Context, mgr ctrl.Manager) error {
+func (r *MCPVirtualServerReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	r.log = slog.New(logr.ToSlogHandler(mgr.GetLogger()))
 
+	if err := setupIndexVirtualServerToRegistration(ctx, mgr.GetFieldIndexer()); err != nil {
+		return fmt.Errorf("failed to setup required index from MCPVirtualServer to registrations %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&mcpv1alpha1.MCPVirtualServer{}).
+		// the reconciler writes the resolved tools to status, which must not trigger another reconcile
+		For(&mcpv1alpha1.MCPVirtualServer{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// registrations becoming ready, not ready or deleted change which tools and servers a virtual server resolves
+		Watches(
+			&mcpv1alpha1.MCPServerRegistration{},
+			handler.EnqueueRequestsFromMapFunc(r.findVirtualServersForRegistration),
+		).
 		Named("mcpvirtualserver").
-		Complete(r)
+		Complete(r.ReconcileTiming.Wrap("MCPVirtualServer", r))
 }
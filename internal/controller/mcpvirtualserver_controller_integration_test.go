@@ -0,0 +1,205 @@
+//go:build integration
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+)
+
+// mockVirtualServerConfigWriter is a mock for testing
+type mockVirtualServerConfigWriter struct{}
+
+func (m *mockVirtualServerConfigWriter) WriteVirtualServerConfig(_ context.Context, _ []config.VirtualServerConfig, _ types.NamespacedName) error {
+	return nil
+}
+
+// forceDeleteTestMCPVirtualServer removes finalizers and deletes
+func forceDeleteTestMCPVirtualServer(ctx context.Context, name, namespace string) {
+	resource := &mcpv1alpha1.MCPVirtualServer{}
+	if err := testK8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, resource); err != nil {
+		return
+	}
+	if controllerutil.RemoveFinalizer(resource, mcpGatewayFinalizer) {
+		Expect(testK8sClient.Update(ctx, resource)).To(Succeed())
+	}
+	Expect(client.IgnoreNotFound(testK8sClient.Delete(ctx, resource))).To(Succeed())
+}
+
+var _ = Describe("MCPVirtualServer Controller", func() {
+	Context("When a backing MCPServerRegistration becomes Ready", func() {
+		const (
+			registrationName  = "test-vs-registration"
+			virtualServerName = "test-vs"
+		)
+
+		ctx := context.Background()
+		var cancelManager context.CancelFunc
+
+		BeforeEach(func() {
+			// run the reconciler in its own manager so changes are picked up by its watches rather than manual reconciles
+			mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+				Scheme:     scheme.Scheme,
+				Metrics:    metricsserver.Options{BindAddress: "0"},
+				Controller: ctrlconfig.Controller{SkipNameValidation: ptr.To(true)},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&MCPVirtualServerReconciler{
+				Client:             mgr.GetClient(),
+				Scheme:             mgr.GetScheme(),
+				DirectAPIReader:    mgr.GetAPIReader(),
+				ConfigReaderWriter: &mockVirtualServerConfigWriter{},
+			}).SetupWithManager(ctx, mgr)).To(Succeed())
+
+			var mgrCtx context.Context
+			mgrCtx, cancelManager = context.WithCancel(ctx)
+			go func() {
+				defer GinkgoRecover()
+				Expect(mgr.Start(mgrCtx)).To(Succeed())
+			}()
+
+			Expect(testK8sClient.Create(ctx, createTestMCPServerRegistration(registrationName, "default", "unused-route", "weather_"))).To(Succeed())
+			Expect(testK8sClient.Create(ctx, &mcpv1alpha1.MCPVirtualServer{
+				ObjectMeta: metav1.ObjectMeta{Name: virtualServerName, Namespace: "default"},
+				Spec:       mcpv1alpha1.MCPVirtualServerSpec{Tools: []string{"weather_forecast"}},
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			cancelManager()
+			forceDeleteTestMCPVirtualServer(ctx, virtualServerName, "default")
+			forceDeleteTestMCPServerRegistration(ctx, registrationName, "default")
+		})
+
+		It("should update the resolved tool count without a manual reconcile", func() {
+			vsNamespacedName := types.NamespacedName{Name: virtualServerName, Namespace: "default"}
+
+			Eventually(func(g Gomega) {
+				vs := &mcpv1alpha1.MCPVirtualServer{}
+				g.Expect(testK8sClient.Get(ctx, vsNamespacedName, vs)).To(Succeed())
+				g.Expect(vs.Status.ResolvedTools).To(Equal(0))
+				g.Expect(vs.Status.UnresolvedTools).To(ConsistOf("weather_forecast"))
+				g.Expect(meta.IsStatusConditionFalse(vs.Status.Conditions, mcpv1alpha1.ConditionTypeReady)).To(BeTrue())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			registration := &mcpv1alpha1.MCPServerRegistration{}
+			Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: registrationName, Namespace: "default"}, registration)).To(Succeed())
+			meta.SetStatusCondition(&registration.Status.Conditions, metav1.Condition{
+				Type:   "Ready",
+				Status: metav1.ConditionTrue,
+				Reason: "Ready",
+			})
+			Expect(testK8sClient.Status().Update(ctx, registration)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				vs := &mcpv1alpha1.MCPVirtualServer{}
+				g.Expect(testK8sClient.Get(ctx, vsNamespacedName, vs)).To(Succeed())
+				g.Expect(vs.Status.ResolvedTools).To(Equal(1))
+				g.Expect(vs.Status.UnresolvedTools).To(BeEmpty())
+				g.Expect(meta.IsStatusConditionTrue(vs.Status.Conditions, mcpv1alpha1.ConditionTypeReady)).To(BeTrue())
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		It("should report the tools and servers of a deleted registration as unresolved", func() {
+			vsNamespacedName := types.NamespacedName{Name: virtualServerName, Namespace: "default"}
+
+			vs := &mcpv1alpha1.MCPVirtualServer{}
+			Expect(testK8sClient.Get(ctx, vsNamespacedName, vs)).To(Succeed())
+			vs.Spec.Servers = []mcpv1alpha1.MCPVirtualServerServer{{Name: registrationName}}
+			Expect(testK8sClient.Update(ctx, vs)).To(Succeed())
+
+			registration := &mcpv1alpha1.MCPServerRegistration{}
+			Expect(testK8sClient.Get(ctx, types.NamespacedName{Name: registrationName, Namespace: "default"}, registration)).To(Succeed())
+			meta.SetStatusCondition(&registration.Status.Conditions, metav1.Condition{
+				Type:   "Ready",
+				Status: metav1.ConditionTrue,
+				Reason: "Ready",
+			})
+			Expect(testK8sClient.Status().Update(ctx, registration)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				vs := &mcpv1alpha1.MCPVirtualServer{}
+				g.Expect(testK8sClient.Get(ctx, vsNamespacedName, vs)).To(Succeed())
+				g.Expect(vs.Status.ResolvedTools).To(Equal(1))
+				g.Expect(vs.Status.UnresolvedServers).To(BeEmpty())
+				g.Expect(meta.FindStatusCondition(vs.Status.Conditions, mcpv1alpha1.ConditionTypeReady)).NotTo(BeNil())
+				g.Expect(meta.FindStatusCondition(vs.Status.Conditions, mcpv1alpha1.ConditionTypeReady).Reason).To(Equal(ReasonToolsResolved))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			forceDeleteTestMCPServerRegistration(ctx, registrationName, "default")
+
+			Eventually(func(g Gomega) {
+				vs := &mcpv1alpha1.MCPVirtualServer{}
+				g.Expect(testK8sClient.Get(ctx, vsNamespacedName, vs)).To(Succeed())
+				g.Expect(vs.Status.ResolvedTools).To(Equal(0))
+				g.Expect(vs.Status.UnresolvedTools).To(ConsistOf("weather_forecast"))
+				g.Expect(vs.Status.UnresolvedServers).To(ConsistOf("default/" + registrationName))
+				g.Expect(meta.FindStatusCondition(vs.Status.Conditions, mcpv1alpha1.ConditionTypeReady)).NotTo(BeNil())
+				g.Expect(meta.FindStatusCondition(vs.Status.Conditions, mcpv1alpha1.ConditionTypeReady).Reason).To(Equal(ReasonNoToolsResolved))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+
+		It("should advance the observed generation after a spec change", func() {
+			vsNamespacedName := types.NamespacedName{Name: virtualServerName, Namespace: "default"}
+
+			Eventually(func(g Gomega) {
+				vs := &mcpv1alpha1.MCPVirtualServer{}
+				g.Expect(testK8sClient.Get(ctx, vsNamespacedName, vs)).To(Succeed())
+				g.Expect(vs.Status.ObservedGeneration).To(Equal(vs.Generation))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+
+			vs := &mcpv1alpha1.MCPVirtualServer{}
+			Expect(testK8sClient.Get(ctx, vsNamespacedName, vs)).To(Succeed())
+			previousGeneration := vs.Generation
+			vs.Spec.Tools = append(vs.Spec.Tools, "weather_alerts")
+			Expect(testK8sClient.Update(ctx, vs)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				vs := &mcpv1alpha1.MCPVirtualServer{}
+				g.Expect(testK8sClient.Get(ctx, vsNamespacedName, vs)).To(Succeed())
+				g.Expect(vs.Generation).To(BeNumerically(">", previousGeneration))
+				g.Expect(vs.Status.ObservedGeneration).To(Equal(vs.Generation))
+			}, testTimeout, testRetryInterval).Should(Succeed())
+		})
+	})
+
+	Context("When validating the spec", func() {
+		ctx := context.Background()
+
+		AfterEach(func() {
+			forceDeleteTestMCPVirtualServer(ctx, "test-vs-servers-only", "default")
+		})
+
+		It("should reject a virtual server without tools or servers", func() {
+			err := testK8sClient.Create(ctx, &mcpv1alpha1.MCPVirtualServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vs-empty", Namespace: "default"},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("at least one of tools or servers must be set"))
+		})
+
+		It("should accept a virtual server with only servers", func() {
+			Expect(testK8sClient.Create(ctx, &mcpv1alpha1.MCPVirtualServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vs-servers-only", Namespace: "default"},
+				Spec: mcpv1alpha1.MCPVirtualServerSpec{
+					Servers: []mcpv1alpha1.MCPVirtualServerServer{{Name: "weather"}},
+				},
+			})).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/config"
+)
+
+func TestResolveVirtualServerTools(t *testing.T) {
+	registration := func(prefix string, ready bool) mcpv1alpha1.MCPServerRegistration {
+		status := metav1.ConditionFalse
+		if ready {
+			status = metav1.ConditionTrue
+		}
+		return mcpv1alpha1.MCPServerRegistration{
+			Spec: mcpv1alpha1.MCPServerRegistrationSpec{ToolPrefix: prefix},
+			Status: mcpv1alpha1.MCPServerRegistrationStatus{
+				Conditions: []metav1.Condition{{Type: "Ready", Status: status}},
+			},
+		}
+	}
+	tools := []string{"weather_forecast", "news_headlines"}
+
+	testCases := []struct {
+		name          string
+		registrations []mcpv1alpha1.MCPServerRegistration
+		expected      mcpv1alpha1.MCPVirtualServerStatus
+	}{
+		{
+			name:     "no registrations",
+			expected: mcpv1alpha1.MCPVirtualServerStatus{UnresolvedTools: tools},
+		},
+		{
+			name:          "ready registration resolves matching prefix",
+			registrations: []mcpv1alpha1.MCPServerRegistration{registration("weather_", true), registration("news_", false)},
+			expected:      mcpv1alpha1.MCPVirtualServerStatus{ResolvedTools: 1, UnresolvedTools: []string{"news_headlines"}},
+		},
+		{
+			name:          "ready registration without prefix resolves every tool",
+			registrations: []mcpv1alpha1.MCPServerRegistration{registration("", true)},
+			expected:      mcpv1alpha1.MCPVirtualServerStatus{ResolvedTools: 2},
+		},
+		{
+			name: "ready registration resolves the prefix of its tool name template",
+			registrations: []mcpv1alpha1.MCPServerRegistration{func() mcpv1alpha1.MCPServerRegistration {
+				templated := registration("news", true)
+				templated.Spec.ToolNameTemplate = "{prefix}_{tool}_v1"
+				return templated
+			}()},
+			expected: mcpv1alpha1.MCPVirtualServerStatus{ResolvedTools: 1, UnresolvedTools: []string{"weather_forecast"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, resolveVirtualServerTools(tools, tc.registrations))
+		})
+	}
+}
+
+func TestToolMatchesPrefix(t *testing.T) {
+	require.True(t, toolMatchesPrefix("weather_forecast", "weather_"))
+	require.False(t, toolMatchesPrefix("news_headlines", "weather_"))
+	require.True(t, toolMatchesPrefix("weather_*", "weather_"))
+	require.True(t, toolMatchesPrefix("weather_fore*", "weather_"))
+	require.True(t, toolMatchesPrefix("*", "weather_"))
+	require.False(t, toolMatchesPrefix("news_*", "weather_"))
+}
+
+func TestUnresolvedVirtualServerServers(t *testing.T) {
+	registration := func(namespace, name string, ready bool) mcpv1alpha1.MCPServerRegistration {
+		status := metav1.ConditionFalse
+		if ready {
+			status = metav1.ConditionTrue
+		}
+		return mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status: mcpv1alpha1.MCPServerRegistrationStatus{
+				Conditions: []metav1.Condition{{Type: "Ready", Status: status}},
+			},
+		}
+	}
+	registrations := []mcpv1alpha1.MCPServerRegistration{
+		registration("team-a", "weather", true),
+		registration("team-b", "news", true),
+		registration("team-b", "sports", false),
+	}
+	mcpVS := &mcpv1alpha1.MCPVirtualServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "composed", Namespace: "team-a"},
+		Spec: mcpv1alpha1.MCPVirtualServerSpec{Servers: []mcpv1alpha1.MCPVirtualServerServer{
+			{Name: "weather"},
+			{Name: "news", Namespace: "team-b", Tools: []string{"headlines"}},
+			{Name: "sports", Namespace: "team-b"},
+			{Name: "travel"},
+		}},
+	}
+
+	require.Equal(t, []string{"team-a/travel", "team-b/sports"}, unresolvedVirtualServerServers(mcpVS, registrations))
+}
+
+func TestGenerateVirtualServerConfig_Servers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	mcpVS := &mcpv1alpha1.MCPVirtualServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "composed", Namespace: "team-a"},
+		Spec: mcpv1alpha1.MCPVirtualServerSpec{
+			Tools: []string{"travel_flights"},
+			Servers: []mcpv1alpha1.MCPVirtualServerServer{
+				{Name: "weather"},
+				{Name: "news", Namespace: "team-b", Tools: []string{"headlines", "search_*"}},
+			},
+		},
+	}
+	r := &MCPVirtualServerReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(mcpVS).Build()}
+
+	virtualServers, err := r.generateVirtualServerConfig(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []config.VirtualServerConfig{{
+		Name:  "team-a/composed",
+		Tools: []string{"travel_flights"},
+		Servers: []config.VirtualServerScope{
+			{Name: "team-a/weather"},
+			{Name: "team-b/news", Tools: []string{"headlines", "search_*"}},
+		},
+	}}, virtualServers)
+}
+
+func TestMCPVirtualServerReconciler_updateStatus_ObservedGeneration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	mcpVS := &mcpv1alpha1.MCPVirtualServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "vs", Namespace: "team-a", Generation: 1},
+		Spec:       mcpv1alpha1.MCPVirtualServerSpec{Tools: []string{"weather_forecast"}},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mcpVS).WithStatusSubresource(mcpVS).Build()
+	r := &MCPVirtualServerReconciler{Client: k8sClient}
+	current := func() *mcpv1alpha1.MCPVirtualServer {
+		fresh := &mcpv1alpha1.MCPVirtualServer{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(mcpVS), fresh))
+		return fresh
+	}
+
+	require.NoError(t, r.updateStatus(context.Background(), current()))
+	require.Equal(t, int64(1), current().Status.ObservedGeneration)
+
+	changed := current()
+	changed.Generation = 2
+	require.NoError(t, r.updateStatus(context.Background(), changed))
+	require.Equal(t, int64(2), current().Status.ObservedGeneration)
+}
+
+func TestMCPVirtualServerReconciler_updateStatus_ReadyCondition(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	registration := func(name, prefix string) *mcpv1alpha1.MCPServerRegistration {
+		return &mcpv1alpha1.MCPServerRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"},
+			Spec:       mcpv1alpha1.MCPServerRegistrationSpec{ToolPrefix: prefix},
+			Status: mcpv1alpha1.MCPServerRegistrationStatus{
+				Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		spec            mcpv1alpha1.MCPVirtualServerSpec
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:            "all tools resolve",
+			spec:            mcpv1alpha1.MCPVirtualServerSpec{Tools: []string{"weather_forecast", "news_*"}},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  ReasonToolsResolved,
+			expectedMessage: "all tools and servers resolve to a Ready MCPServerRegistration",
+		},
+		{
+			name: "some tools and servers resolve",
+			spec: mcpv1alpha1.MCPVirtualServerSpec{
+				Tools:   []string{"weather_forecast", "wether_alerts"},
+				Servers: []mcpv1alpha1.MCPVirtualServerServer{{Name: "news"}, {Name: "sports"}},
+			},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  ReasonToolsPartiallyResolved,
+			expectedMessage: "tools wether_alerts and servers team-a/sports do not resolve to a Ready MCPServerRegistration",
+		},
+		{
+			name:            "no tools resolve",
+			spec:            mcpv1alpha1.MCPVirtualServerSpec{Tools: []string{"wether_forecast", "wether_alerts"}},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  ReasonNoToolsResolved,
+			expectedMessage: "no tools are served: tools wether_forecast, wether_alerts do not resolve to a Ready MCPServerRegistration",
+		},
+		{
+			name:            "no servers resolve",
+			spec:            mcpv1alpha1.MCPVirtualServerSpec{Servers: []mcpv1alpha1.MCPVirtualServerServer{{Name: "sports"}}},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  ReasonNoToolsResolved,
+			expectedMessage: "no tools are served: servers team-a/sports do not resolve to a Ready MCPServerRegistration",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mcpVS := &mcpv1alpha1.MCPVirtualServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "vs", Namespace: "team-a", Generation: 1},
+				Spec:       tc.spec,
+			}
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(mcpVS, registration("weather", "weather_"), registration("news", "news_")).
+				WithStatusSubresource(mcpVS).
+				Build()
+			r := &MCPVirtualServerReconciler{Client: k8sClient}
+
+			require.NoError(t, r.updateStatus(context.Background(), mcpVS))
+
+			updated := &mcpv1alpha1.MCPVirtualServer{}
+			require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(mcpVS), updated))
+			ready := meta.FindStatusCondition(updated.Status.Conditions, mcpv1alpha1.ConditionTypeReady)
+			require.NotNil(t, ready)
+			require.Equal(t, tc.expectedStatus, ready.Status)
+			require.Equal(t, tc.expectedReason, ready.Reason)
+			require.Equal(t, tc.expectedMessage, ready.Message)
+			require.Equal(t, int64(1), ready.ObservedGeneration)
+		})
+	}
+}
+
+func TestFindVirtualServersForRegistration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	virtualServer := func(name string, spec mcpv1alpha1.MCPVirtualServerSpec) *mcpv1alpha1.MCPVirtualServer {
+		return &mcpv1alpha1.MCPVirtualServer{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"}, Spec: spec}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&mcpv1alpha1.MCPVirtualServer{}, VirtualServerRegistrationIndex, virtualServerRegistrationIndexValues).
+		WithIndex(&mcpv1alpha1.MCPVirtualServer{}, VirtualServerToolsIndex, virtualServerToolsIndexValues).
+		WithObjects(
+			virtualServer("by-server", mcpv1alpha1.MCPVirtualServerSpec{Servers: []mcpv1alpha1.MCPVirtualServerServer{{Name: "weather"}}}),
+			virtualServer("by-tool", mcpv1alpha1.MCPVirtualServerSpec{Tools: []string{"weather_forecast"}}),
+			virtualServer("by-pattern", mcpv1alpha1.MCPVirtualServerSpec{Tools: []string{"weather_*"}}),
+			virtualServer("both", mcpv1alpha1.MCPVirtualServerSpec{
+				Tools:   []string{"weather_alerts"},
+				Servers: []mcpv1alpha1.MCPVirtualServerServer{{Name: "weather"}},
+			}),
+			virtualServer("other-tool", mcpv1alpha1.MCPVirtualServerSpec{Tools: []string{"news_headlines"}}),
+			virtualServer("other-server", mcpv1alpha1.MCPVirtualServerSpec{Servers: []mcpv1alpha1.MCPVirtualServerServer{{Name: "news"}}}),
+		).Build()
+	r := &MCPVirtualServerReconciler{Client: k8sClient}
+	names := func(registration *mcpv1alpha1.MCPServerRegistration) []string {
+		var names []string
+		for _, request := range r.findVirtualServersForRegistration(context.Background(), registration) {
+			names = append(names, request.Name)
+		}
+		return names
+	}
+
+	weather := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "weather", Namespace: "team-a"},
+		Spec:       mcpv1alpha1.MCPServerRegistrationSpec{ToolPrefix: "weather_"},
+	}
+	require.ElementsMatch(t, []string{"by-server", "by-tool", "by-pattern", "both"}, names(weather))
+
+	// a registration without a prefix may serve any tool
+	unprefixed := &mcpv1alpha1.MCPServerRegistration{ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "team-a"}}
+	require.ElementsMatch(t, []string{"by-tool", "by-pattern", "both", "other-tool"}, names(unprefixed))
+
+	unreferenced := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "maps", Namespace: "team-a"},
+		Spec:       mcpv1alpha1.MCPServerRegistrationSpec{ToolPrefix: "maps_"},
+	}
+	require.Empty(t, names(unreferenced))
+
+	// a virtual server listing one of the registration's aliases references it
+	aliased := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "news-feed", Namespace: "team-a"},
+		Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+			ToolPrefix:  "feed_",
+			ToolAliases: map[string]string{"headlines": "news_headlines"},
+		},
+	}
+	require.Equal(t, []string{"other-tool"}, names(aliased))
+}
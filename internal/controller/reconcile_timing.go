@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcp_gateway_reconcile_duration_seconds",
+		Help:    "Duration of reconciles by resource kind",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"kind"})
+
+	registerReconcileMetricsOnce sync.Once
+)
+
+func registerReconcileMetrics() {
+	registerReconcileMetricsOnce.Do(func() {
+		metrics.Registry.MustRegister(reconcileDuration)
+	})
+}
+
+// ReconcileTiming records the duration of each reconcile and warns when a reconcile is slower than Threshold
+type ReconcileTiming struct {
+	Threshold time.Duration
+	Logger    *slog.Logger
+}
+
+// Wrap returns a reconciler that times the given reconciler. A nil ReconcileTiming returns the reconciler unchanged
+func (t *ReconcileTiming) Wrap(kind string, r reconcile.Reconciler) reconcile.Reconciler {
+	if t == nil {
+		return r
+	}
+	registerReconcileMetrics()
+	return &timedReconciler{kind: kind, reconciler: r, timing: t}
+}
+
+type timedReconciler struct {
+	kind       string
+	reconciler reconcile.Reconciler
+	timing     *ReconcileTiming
+}
+
+// Reconcile implements reconcile.Reconciler
+func (t *timedReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	start := time.Now()
+	result, err := t.reconciler.Reconcile(ctx, req)
+	elapsed := time.Since(start)
+	reconcileDuration.WithLabelValues(t.kind).Observe(elapsed.Seconds())
+	if t.timing.Threshold > 0 && elapsed > t.timing.Threshold {
+		t.timing.Logger.Warn("slow reconcile", "kind", t.kind, "name", req.Name, "namespace", req.Namespace,
+			"duration", elapsed.String(), "threshold", t.timing.Threshold.String())
+	}
+	return result, err
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type sleepReconciler struct {
+	delay time.Duration
+}
+
+func (s *sleepReconciler) Reconcile(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	time.Sleep(s.delay)
+	return reconcile.Result{RequeueAfter: time.Second}, nil
+}
+
+func TestReconcileTiming(t *testing.T) {
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "weather", Namespace: "team-a"}}
+
+	t.Run("nil timing returns the reconciler unchanged", func(t *testing.T) {
+		inner := &sleepReconciler{}
+		var timing *ReconcileTiming
+		require.Same(t, inner, timing.Wrap("MCPServerRegistration", inner))
+	})
+
+	t.Run("records duration and warns past the threshold", func(t *testing.T) {
+		var buf bytes.Buffer
+		timing := &ReconcileTiming{
+			Threshold: 10 * time.Millisecond,
+			Logger:    slog.New(slog.NewTextHandler(&buf, nil)),
+		}
+		before := testutil.CollectAndCount(reconcileDuration)
+
+		fast := timing.Wrap("FastKind", &sleepReconciler{})
+		result, err := fast.Reconcile(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, time.Second, result.RequeueAfter)
+		require.NotContains(t, buf.String(), "slow reconcile")
+
+		slow := timing.Wrap("SlowKind", &sleepReconciler{delay: 20 * time.Millisecond})
+		_, err = slow.Reconcile(context.Background(), req)
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "slow reconcile")
+		require.Contains(t, buf.String(), "kind=SlowKind")
+		require.Contains(t, buf.String(), "name=weather")
+
+		require.Equal(t, before+2, testutil.CollectAndCount(reconcileDuration))
+	})
+}
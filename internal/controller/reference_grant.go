@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// grantReference describes one side of a cross-namespace reference checked against ReferenceGrants
+type grantReference struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// hasReferenceGrant checks if any ReferenceGrant in the namespace of to allows from to reference it
+func hasReferenceGrant(ctx context.Context, reader client.Reader, from, to grantReference) (bool, error) {
+	refGrantList := &gatewayv1beta1.ReferenceGrantList{}
+	if err := reader.List(ctx, refGrantList, client.InNamespace(to.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list ReferenceGrants: %w", err)
+	}
+	for i := range refGrantList.Items {
+		if referenceGrantPermits(&refGrantList.Items[i], from, to) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// referenceGrantPermits checks if a ReferenceGrant allows from to reference to. The namespace of to is not checked as
+// a ReferenceGrant only applies to its own namespace. An empty kind or name in the grant's to matches all
+func referenceGrantPermits(rg *gatewayv1beta1.ReferenceGrant, from, to grantReference) bool {
+	fromAllowed := slices.ContainsFunc(rg.Spec.From, func(grantFrom gatewayv1beta1.ReferenceGrantFrom) bool {
+		return string(grantFrom.Group) == from.Group && string(grantFrom.Kind) == from.Kind &&
+			string(grantFrom.Namespace) == from.Namespace
+	})
+	if !fromAllowed {
+		return false
+	}
+	return slices.ContainsFunc(rg.Spec.To, func(grantTo gatewayv1beta1.ReferenceGrantTo) bool {
+		return string(grantTo.Group) == to.Group &&
+			(grantTo.Kind == "" || string(grantTo.Kind) == to.Kind) &&
+			(grantTo.Name == nil || *grantTo.Name == "" || string(*grantTo.Name) == to.Name)
+	})
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+func referenceGrant(name, namespace string, from gatewayv1beta1.ReferenceGrantFrom, to gatewayv1beta1.ReferenceGrantTo) *gatewayv1beta1.ReferenceGrant {
+	return &gatewayv1beta1.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{from},
+			To:   []gatewayv1beta1.ReferenceGrantTo{to},
+		},
+	}
+}
+
+func TestReferenceGrantPermits(t *testing.T) {
+	fromRoutes := gatewayv1beta1.ReferenceGrantFrom{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: "routes"}
+	toServices := gatewayv1beta1.ReferenceGrantTo{Group: "", Kind: "Service"}
+	route := grantReference{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: "routes"}
+	service := grantReference{Group: "", Kind: "Service", Namespace: "backend", Name: "mcp"}
+	fromExtensions := gatewayv1beta1.ReferenceGrantFrom{Group: gatewayv1beta1.Group(mcpv1alpha1.GroupVersion.Group), Kind: "MCPGatewayExtension", Namespace: "team-a"}
+	extension := grantReference{Group: mcpv1alpha1.GroupVersion.Group, Kind: "MCPGatewayExtension", Namespace: "team-a"}
+	gateway := grantReference{Group: gatewayv1.GroupName, Kind: "Gateway", Namespace: "gateway-system", Name: "mcp-gateway"}
+
+	tests := []struct {
+		name     string
+		grant    *gatewayv1beta1.ReferenceGrant
+		from, to grantReference
+		expected bool
+	}{
+		{
+			name:     "all services",
+			grant:    referenceGrant("grant", "backend", fromRoutes, toServices),
+			from:     route,
+			to:       service,
+			expected: true,
+		},
+		{
+			name:     "named service",
+			grant:    referenceGrant("grant", "backend", fromRoutes, gatewayv1beta1.ReferenceGrantTo{Group: "", Kind: "Service", Name: ptr.To(gatewayv1beta1.ObjectName("mcp"))}),
+			from:     route,
+			to:       service,
+			expected: true,
+		},
+		{
+			name:     "other named service",
+			grant:    referenceGrant("grant", "backend", fromRoutes, gatewayv1beta1.ReferenceGrantTo{Group: "", Kind: "Service", Name: ptr.To(gatewayv1beta1.ObjectName("other"))}),
+			from:     route,
+			to:       service,
+			expected: false,
+		},
+		{
+			name:     "other route namespace",
+			grant:    referenceGrant("grant", "backend", gatewayv1beta1.ReferenceGrantFrom{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: "elsewhere"}, toServices),
+			from:     route,
+			to:       service,
+			expected: false,
+		},
+		{
+			name:     "other from kind",
+			grant:    referenceGrant("grant", "backend", gatewayv1beta1.ReferenceGrantFrom{Group: gatewayv1.GroupName, Kind: "GRPCRoute", Namespace: "routes"}, toServices),
+			from:     route,
+			to:       service,
+			expected: false,
+		},
+		{
+			name:     "other to kind",
+			grant:    referenceGrant("grant", "backend", fromRoutes, gatewayv1beta1.ReferenceGrantTo{Group: "", Kind: "Secret"}),
+			from:     route,
+			to:       service,
+			expected: false,
+		},
+		{
+			name:     "wildcard gateway grant",
+			grant:    referenceGrant("grant", "gateway-system", fromExtensions, gatewayv1beta1.ReferenceGrantTo{Group: gatewayv1.GroupName, Kind: "Gateway"}),
+			from:     extension,
+			to:       gateway,
+			expected: true,
+		},
+		{
+			name:     "named gateway grant",
+			grant:    referenceGrant("grant", "gateway-system", fromExtensions, gatewayv1beta1.ReferenceGrantTo{Group: gatewayv1.GroupName, Kind: "Gateway", Name: ptr.To(gatewayv1beta1.ObjectName("mcp-gateway"))}),
+			from:     extension,
+			to:       gateway,
+			expected: true,
+		},
+		{
+			name:     "other named gateway grant",
+			grant:    referenceGrant("grant", "gateway-system", fromExtensions, gatewayv1beta1.ReferenceGrantTo{Group: gatewayv1.GroupName, Kind: "Gateway", Name: ptr.To(gatewayv1beta1.ObjectName("other"))}),
+			from:     extension,
+			to:       gateway,
+			expected: false,
+		},
+		{
+			name:     "gateway grant to services",
+			grant:    referenceGrant("grant", "gateway-system", fromExtensions, toServices),
+			from:     extension,
+			to:       gateway,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, referenceGrantPermits(tt.grant, tt.from, tt.to))
+		})
+	}
+}
+
+func TestFindValidMCPGatewayExtsForGateway(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+	require.NoError(t, gatewayv1beta1.Install(scheme))
+	gateway := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "mcp-gateway", Namespace: "gateway-system"}}
+	extension := func(namespace string) *mcpv1alpha1.MCPGatewayExtension {
+		return &mcpv1alpha1.MCPGatewayExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: "ext", Namespace: namespace},
+			Spec: mcpv1alpha1.MCPGatewayExtensionSpec{
+				TargetRef: mcpv1alpha1.MCPGatewayExtensionTargetReference{Name: gateway.Name, Namespace: gateway.Namespace},
+			},
+			Status: mcpv1alpha1.MCPGatewayExtensionStatus{
+				Conditions: []metav1.Condition{{Type: mcpv1alpha1.ConditionTypeReady, Status: metav1.ConditionTrue, Reason: "Ready"}},
+			},
+		}
+	}
+	fromExtensions := func(namespace string) gatewayv1beta1.ReferenceGrantFrom {
+		return gatewayv1beta1.ReferenceGrantFrom{Group: gatewayv1beta1.Group(mcpv1alpha1.GroupVersion.Group), Kind: "MCPGatewayExtension", Namespace: gatewayv1beta1.Namespace(namespace)}
+	}
+	toGateway := func(name string) gatewayv1beta1.ReferenceGrantTo {
+		to := gatewayv1beta1.ReferenceGrantTo{Group: gatewayv1.GroupName, Kind: "Gateway"}
+		if name != "" {
+			to.Name = ptr.To(gatewayv1beta1.ObjectName(name))
+		}
+		return to
+	}
+	notReady := extension("not-ready")
+	meta.SetStatusCondition(&notReady.Status.Conditions, metav1.Condition{Type: mcpv1alpha1.ConditionTypeReady, Status: metav1.ConditionFalse, Reason: "NotReady"})
+
+	objects := []client.Object{
+		gateway,
+		extension("gateway-system"),
+		extension("wildcard"),
+		extension("named"),
+		extension("other-name"),
+		extension("no-grant"),
+		notReady,
+		referenceGrant("wildcard", "gateway-system", fromExtensions("wildcard"), toGateway("")),
+		referenceGrant("named", "gateway-system", fromExtensions("named"), toGateway("mcp-gateway")),
+		referenceGrant("other-name", "gateway-system", fromExtensions("other-name"), toGateway("other")),
+		referenceGrant("not-ready", "gateway-system", fromExtensions("not-ready"), toGateway("")),
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+		WithIndex(&mcpv1alpha1.MCPGatewayExtension{}, gatewayIndexKey, func(obj client.Object) []string {
+			return []string{mcpExtToGatewayIndexValue(*obj.(*mcpv1alpha1.MCPGatewayExtension))}
+		}).Build()
+	validator := &MCPGatewayExtensionValidator{Client: k8sClient, DirectAPIReader: k8sClient, Logger: slog.New(slog.DiscardHandler)}
+
+	valid, err := validator.FindValidMCPGatewayExtsForGateway(context.Background(), gateway)
+	require.NoError(t, err)
+	namespaces := []string{}
+	for _, ext := range valid {
+		namespaces = append(namespaces, ext.Namespace)
+	}
+	require.ElementsMatch(t, []string{"gateway-system", "wildcard", "named"}, namespaces)
+}
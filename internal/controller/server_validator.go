@@ -2,44 +2,216 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"sync"
 	"time"
 
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
 	"github.com/Kuadrant/mcp-gateway/internal/broker"
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/buildinfo"
+	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	// DefaultValidationTimeout is the default timeout for a single broker status request
+	DefaultValidationTimeout = 10 * time.Second
+	// DefaultValidationRetryInterval is the default wait between broker status retries
+	DefaultValidationRetryInterval = time.Second
+	// DefaultValidationDeadline is the default limit on fetching broker status across every endpoint and retry
+	DefaultValidationDeadline = 30 * time.Second
+)
+
+// ErrValidationTimeout is returned when the broker status could not be fetched because the broker did not respond in time.
+// It means the registration state is unknown rather than not registered
+var ErrValidationTimeout = errors.New("timed out fetching broker status")
+
+// ErrBrokerUnreachable is returned when no broker in the namespace returned its status, so nothing is known about the
+// servers it manages. A timeout is also reported as ErrValidationTimeout
+var ErrBrokerUnreachable = errors.New("gateway broker unreachable")
+
+// errNoBrokerEndpoints is returned when there is no ready broker in the namespace
+var errNoBrokerEndpoints = errors.New("no broker endpoints available")
+
+// errServerStatusNotFound is returned when the broker has no status for a server because it has not loaded its config
+var errServerStatusNotFound = errors.New("server status not found in broker")
+
+// errNotFoundResponse is returned when a broker endpoint responds not found
+var errNotFoundResponse = errors.New("not found")
+
 // ServerValidator validates MCP servers by calling broker endpoints
 type ServerValidator struct {
-	k8sClient  client.Client
-	httpClient *http.Client
-	namespace  string
+	k8sClient     client.Client
+	httpClient    *http.Client
+	namespace     string
+	retries       int
+	retryInterval time.Duration
+	deadline      time.Duration
+}
+
+// ServerValidatorOption configures a ServerValidator
+type ServerValidatorOption func(*ServerValidator)
+
+// WithValidationTimeout sets the timeout for a single broker status request
+func WithValidationTimeout(timeout time.Duration) ServerValidatorOption {
+	return func(v *ServerValidator) {
+		if timeout > 0 {
+			v.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// WithValidationRetries sets how many times a failed broker status request is retried and the wait between attempts
+func WithValidationRetries(retries int, interval time.Duration) ServerValidatorOption {
+	return func(v *ServerValidator) {
+		v.retries = max(retries, 0)
+		if interval > 0 {
+			v.retryInterval = interval
+		}
+	}
+}
+
+// WithValidationDeadline sets the limit on fetching broker status across every endpoint and retry
+func WithValidationDeadline(deadline time.Duration) ServerValidatorOption {
+	return func(v *ServerValidator) {
+		if deadline > 0 {
+			v.deadline = deadline
+		}
+	}
 }
 
 // NewServerValidator creates a new server validator
-func NewServerValidator(k8sClient client.Client) *ServerValidator {
+func NewServerValidator(k8sClient client.Client, opts ...ServerValidatorOption) *ServerValidator {
 	namespace := os.Getenv("NAMESPACE")
 	if namespace == "" {
 		namespace = "mcp-system"
 	}
 
-	return &ServerValidator{
+	v := &ServerValidator{
 		k8sClient: k8sClient,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   DefaultValidationTimeout,
+			Transport: newBrokerStatusTransport(k8sClient),
 		},
-		namespace: namespace,
+		namespace:     namespace,
+		retryInterval: DefaultValidationRetryInterval,
+		deadline:      DefaultValidationDeadline,
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
-// ValidateServers validates MCP servers by calling the broker's /status endpoints
+// ValidateServers validates MCP servers by calling the broker's /status endpoints. It gives up once the validation
+// deadline passes. An error wrapping ErrBrokerUnreachable means no broker returned its status, rather than a problem
+// with any one server
 func (v *ServerValidator) ValidateServers(ctx context.Context, namespace string) (*broker.StatusResponse, error) {
+	ctx, cancel := v.withDeadline(withBrokerNamespace(ctx, namespace))
+	defer cancel()
+	addresses, err := v.statusEndpoints(ctx, namespace, "/status")
+	if err != nil {
+		return nil, err
+	}
+	return v.statusFromEndpoints(ctx, addresses)
+}
+
+// ValidateServer returns the status of the server with the id from the broker's /status endpoints without listing
+// every server. An error wrapping errServerStatusNotFound means the broker has not loaded the server, other errors are
+// as for ValidateServers
+func (v *ServerValidator) ValidateServer(ctx context.Context, namespace, serverID string) (*upstream.ServerValidationStatus, error) {
+	ctx, cancel := v.withDeadline(withBrokerNamespace(ctx, namespace))
+	defer cancel()
+	addresses, err := v.statusEndpoints(ctx, namespace, "/status?id="+url.QueryEscape(serverID))
+	if err != nil {
+		return nil, err
+	}
+	status, err := v.serverStatusFromEndpoints(ctx, addresses)
+	if err != nil || status.ID == serverID {
+		return status, err
+	}
+	// a broker that does not filter by id, such as one not yet upgraded, answers with the status of every server
+	all, err := v.ValidateServers(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range all.Servers {
+		if server.ID == serverID {
+			return &server, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", errServerStatusNotFound, serverID)
+}
+
+// withDeadline limits ctx to the validation deadline
+func (v *ServerValidator) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if v.deadline <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, v.deadline)
+}
+
+// statusEndpoints is brokerEndpoints for the status of servers, where no ready broker means the broker is unreachable
+func (v *ServerValidator) statusEndpoints(ctx context.Context, namespace, path string) ([]string, error) {
+	addresses, err := v.brokerEndpoints(ctx, namespace, path)
+	if errors.Is(err, errNoBrokerEndpoints) {
+		return nil, fmt.Errorf("%w: %w", ErrBrokerUnreachable, err)
+	}
+	return addresses, err
+}
+
+// DrainStatus reports whether every broker in the namespace has stopped listing the server's tools and the number of
+// tool calls in flight to it across them. Each broker tracks its own calls so every one must respond
+func (v *ServerValidator) DrainStatus(ctx context.Context, namespace, serverID string) (ServerDrainStatus, error) {
+	ctx = withBrokerNamespace(ctx, namespace)
+	addresses, err := v.brokerEndpoints(ctx, namespace, "/status")
+	if errors.Is(err, errNoBrokerEndpoints) {
+		// no broker is serving the server so there is nothing to drain
+		return ServerDrainStatus{Draining: true}, nil
+	}
+	if err != nil {
+		return ServerDrainStatus{}, err
+	}
+	drain := ServerDrainStatus{Draining: true}
+	for _, addr := range addresses {
+		status, err := v.getStatusFromEndpoint(ctx, addr)
+		if err != nil {
+			return ServerDrainStatus{}, fmt.Errorf("failed to get drain status from %s: %w", addr, err)
+		}
+		for _, server := range status.Servers {
+			if server.ID == serverID {
+				drain.Draining = drain.Draining && server.Draining
+				drain.InFlightToolCalls += server.InFlightToolCalls
+			}
+		}
+	}
+	return drain, nil
+}
+
+// BrokerVersion returns the build version reported by the broker's /version endpoint
+func (v *ServerValidator) BrokerVersion(ctx context.Context, namespace string) (string, error) {
+	ctx = withBrokerNamespace(ctx, namespace)
+	addresses, err := v.brokerEndpoints(ctx, namespace, buildinfo.Path)
+	if err != nil {
+		return "", err
+	}
+	return v.versionFromEndpoints(ctx, addresses)
+}
+
+// brokerEndpoints returns the urls for path on each ready broker endpoint in the namespace
+func (v *ServerValidator) brokerEndpoints(ctx context.Context, namespace, path string) ([]string, error) {
 	logger := log.FromContext(ctx)
 	// get endpoint slices for the broker service
 	endpointSliceList := &discoveryv1.EndpointSliceList{}
@@ -58,7 +230,8 @@ func (v *ServerValidator) ValidateServers(ctx context.Context, namespace string)
 			if endpoint.Conditions.Ready != nil && *endpoint.Conditions.Ready {
 				for _, addr := range endpoint.Addresses {
 					// use the status port
-					url := fmt.Sprintf("http://%s/status", net.JoinHostPort(addr, "8080"))
+					scheme, port := brokerPublicPort(endpointSlice)
+					url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(addr, strconv.Itoa(int(port))), path)
 					addresses = append(addresses, url)
 				}
 			}
@@ -67,42 +240,217 @@ func (v *ServerValidator) ValidateServers(ctx context.Context, namespace string)
 
 	if len(addresses) == 0 {
 		logger.Info("No broker endpoints found, skipping status validation")
-		return nil, fmt.Errorf("no broker endpoints available")
+		return nil, errNoBrokerEndpoints
 	}
+	return addresses, nil
+}
 
-	// try each endpoint until we get a successful response
-	for _, addr := range addresses {
-		status, err := v.getStatusFromEndpoint(ctx, addr)
-		if err != nil {
-			logger.Error(err, "Failed to get status from endpoint", "url", addr)
+// brokerPublicPort returns the scheme and number of the broker's public port. The scheme is https when the port is
+// named for TLS, otherwise http
+func brokerPublicPort(endpointSlice discoveryv1.EndpointSlice) (string, int32) {
+	for _, port := range endpointSlice.Ports {
+		if port.Name == nil || port.Port == nil {
 			continue
 		}
-		return status, nil
+		switch *port.Name {
+		case brokerHTTPSPortName:
+			return "https", *port.Port
+		case "http":
+			return "http", *port.Port
+		}
 	}
+	return "http", mcpv1alpha1.DefaultBrokerPort
+}
 
-	return nil, fmt.Errorf("failed to get status from any broker endpoint")
+// brokerNamespaceKey is the context key for the namespace of the broker a status request is sent to
+type brokerNamespaceKey struct{}
+
+// withBrokerNamespace records the broker namespace in ctx so a TLS connection can be verified against its CA
+func withBrokerNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, brokerNamespaceKey{}, namespace)
+}
+
+// brokerStatusTransport is used to read broker status. Brokers are reached by pod ip, so a broker serving TLS is
+// verified by its service name against the CA the MCPGatewayExtension controller publishes in its namespace
+type brokerStatusTransport struct {
+	reader client.Reader
+	plain  *http.Transport
+
+	mu sync.Mutex
+	// verified holds a transport per broker namespace, replaced when the CA ConfigMap changes
+	verified map[string]verifiedBrokerTransport
+}
+
+type verifiedBrokerTransport struct {
+	resourceVersion string
+	transport       *http.Transport
+}
+
+func newBrokerStatusTransport(reader client.Reader) *brokerStatusTransport {
+	return &brokerStatusTransport{
+		reader:   reader,
+		plain:    http.DefaultTransport.(*http.Transport).Clone(),
+		verified: map[string]verifiedBrokerTransport{},
+	}
+}
+
+// RoundTrip sends plain http requests as is and https requests over a connection verified against the broker CA
+func (t *brokerStatusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		return t.plain.RoundTrip(req)
+	}
+	namespace, _ := req.Context().Value(brokerNamespaceKey{}).(string)
+	transport, err := t.verifiedTransport(req.Context(), namespace)
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(req)
+}
+
+// verifiedTransport returns the transport trusting the broker CA in the namespace
+func (t *brokerStatusTransport) verifiedTransport(ctx context.Context, namespace string) (*http.Transport, error) {
+	if t.reader == nil || namespace == "" {
+		return nil, errors.New("broker serves tls but its namespace is unknown so it can't be verified")
+	}
+	caConfigMap := &corev1.ConfigMap{}
+	if err := t.reader.Get(ctx, client.ObjectKey{Name: brokerCAConfigMapName, Namespace: namespace}, caConfigMap); err != nil {
+		return nil, fmt.Errorf("failed to get broker ca: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cached, ok := t.verified[namespace]; ok {
+		if cached.resourceVersion == caConfigMap.ResourceVersion {
+			return cached.transport, nil
+		}
+		cached.transport.CloseIdleConnections()
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(caConfigMap.Data[brokerCAKey])) {
+		return nil, fmt.Errorf("configmap %s/%s has no PEM certificate in %q", namespace, brokerCAConfigMapName, brokerCAKey)
+	}
+	transport := t.plain.Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    roots,
+		ServerName: brokerServiceHost(namespace),
+		MinVersion: tls.VersionTLS12,
+	}
+	t.verified[namespace] = verifiedBrokerTransport{resourceVersion: caConfigMap.ResourceVersion, transport: transport}
+	return transport, nil
+}
+
+// statusFromEndpoints returns the status of every server from the first endpoint that responds
+func (v *ServerValidator) statusFromEndpoints(ctx context.Context, addresses []string) (*broker.StatusResponse, error) {
+	var status broker.StatusResponse
+	if err := v.fromEndpoints(ctx, addresses, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// serverStatusFromEndpoints returns the status of a single server from the first endpoint that responds. The broker
+// answering not found is returned as errServerStatusNotFound
+func (v *ServerValidator) serverStatusFromEndpoints(ctx context.Context, addresses []string) (*upstream.ServerValidationStatus, error) {
+	var status upstream.ServerValidationStatus
+	if err := v.fromEndpoints(ctx, addresses, &status); err != nil {
+		if errors.Is(err, errNotFoundResponse) {
+			return nil, fmt.Errorf("%w: %w", errServerStatusNotFound, err)
+		}
+		return nil, err
+	}
+	return &status, nil
+}
+
+// fromEndpoints decodes the response of the first endpoint that responds into out, retrying the whole set as
+// configured or until the context is done. A not found response is an answer so is returned without trying further.
+// Otherwise the error wraps ErrBrokerUnreachable, and ErrValidationTimeout too if every failure was a timeout
+func (v *ServerValidator) fromEndpoints(ctx context.Context, addresses []string, out any) error {
+	logger := log.FromContext(ctx)
+	timedOut := true
+	var lastErr error
+attempts:
+	for attempt := 0; attempt <= v.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				lastErr = ctx.Err()
+				timedOut = timedOut && isTimeout(lastErr)
+				break attempts
+			case <-time.After(v.retryInterval):
+			}
+		}
+		// try each endpoint until we get a successful response
+		for _, addr := range addresses {
+			err := v.getJSON(ctx, addr, out)
+			if errors.Is(err, errNotFoundResponse) {
+				return err
+			}
+			if err != nil {
+				logger.Error(err, "Failed to get status from endpoint", "url", addr, "attempt", attempt+1)
+				lastErr = err
+				timedOut = timedOut && isTimeout(err)
+				continue
+			}
+			return nil
+		}
+	}
+	if timedOut {
+		return fmt.Errorf("failed to get status from any broker endpoint: %w: %w", ErrBrokerUnreachable, ErrValidationTimeout)
+	}
+	return fmt.Errorf("failed to get status from any broker endpoint: %w: %w", ErrBrokerUnreachable, lastErr)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// versionFromEndpoints returns the version from the first endpoint that responds
+func (v *ServerValidator) versionFromEndpoints(ctx context.Context, addresses []string) (string, error) {
+	var lastErr error
+	for _, addr := range addresses {
+		var info buildinfo.Info
+		if err := v.getJSON(ctx, addr, &info); err != nil {
+			lastErr = err
+			continue
+		}
+		return info.String(), nil
+	}
+	return "", fmt.Errorf("failed to get version from any broker endpoint: %w", lastErr)
 }
 
 func (v *ServerValidator) getStatusFromEndpoint(ctx context.Context, url string) (*broker.StatusResponse, error) {
+	var status broker.StatusResponse
+	if err := v.getJSON(ctx, url, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (v *ServerValidator) getJSON(ctx context.Context, url string, out any) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := v.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("received status %d: %w", resp.StatusCode, errNotFoundResponse)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received status %d", resp.StatusCode)
+		return fmt.Errorf("received status %d", resp.StatusCode)
 	}
 
-	var status broker.StatusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	return &status, nil
+	return nil
 }
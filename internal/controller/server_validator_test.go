@@ -2,15 +2,19 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/broker"
 	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+	"github.com/Kuadrant/mcp-gateway/internal/buildinfo"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
@@ -116,7 +120,7 @@ func TestServerValidator_ValidateServers(t *testing.T) {
 		}
 
 		_, err := validator.ValidateServers(context.Background(), "test")
-		require.Error(t, err)
+		require.ErrorIs(t, err, ErrBrokerUnreachable)
 		require.Contains(t, err.Error(), "no broker endpoints available")
 	})
 
@@ -193,3 +197,320 @@ func TestNewServerValidator(t *testing.T) {
 		require.NotEmpty(t, validator.namespace)
 	})
 }
+
+func TestServerValidator_statusFromEndpoints(t *testing.T) {
+	okStatus := broker.StatusResponse{
+		OverallValid: true,
+		Servers:      []upstream.ServerValidationStatus{{Name: "server1", Ready: true}},
+	}
+
+	t.Run("slow broker is reported as a timeout", func(t *testing.T) {
+		slowBroker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			_ = json.NewEncoder(w).Encode(okStatus)
+		}))
+		defer slowBroker.Close()
+
+		validator := NewServerValidator(nil, WithValidationTimeout(20*time.Millisecond))
+		_, err := validator.statusFromEndpoints(context.Background(), []string{slowBroker.URL})
+		require.ErrorIs(t, err, ErrValidationTimeout)
+	})
+
+	t.Run("error response is not a timeout", func(t *testing.T) {
+		failingBroker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer failingBroker.Close()
+
+		validator := NewServerValidator(nil)
+		_, err := validator.statusFromEndpoints(context.Background(), []string{failingBroker.URL})
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrValidationTimeout)
+	})
+
+	t.Run("retries a transient slow response", func(t *testing.T) {
+		var calls atomic.Int32
+		flakyBroker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) == 1 {
+				time.Sleep(200 * time.Millisecond)
+			}
+			_ = json.NewEncoder(w).Encode(okStatus)
+		}))
+		defer flakyBroker.Close()
+
+		validator := NewServerValidator(nil,
+			WithValidationTimeout(50*time.Millisecond),
+			WithValidationRetries(1, 10*time.Millisecond),
+		)
+		status, err := validator.statusFromEndpoints(context.Background(), []string{flakyBroker.URL})
+		require.NoError(t, err)
+		require.Len(t, status.Servers, 1)
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("refused connection is unreachable but not a timeout", func(t *testing.T) {
+		closedBroker := httptest.NewServer(http.NotFoundHandler())
+		closedBroker.Close()
+
+		validator := NewServerValidator(nil)
+		_, err := validator.statusFromEndpoints(context.Background(), []string{closedBroker.URL})
+		require.ErrorIs(t, err, ErrBrokerUnreachable)
+		require.NotErrorIs(t, err, ErrValidationTimeout)
+	})
+}
+
+func TestServerValidator_ValidationDeadline(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, discoveryv1.AddToScheme(scheme))
+	const serverID = "team-a/weather:weather_:weather.local"
+	var calls atomic.Int32
+	slowBroker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(upstream.ServerValidationStatus{ID: serverID, Ready: true})
+	}))
+	defer slowBroker.Close()
+	closedBroker := httptest.NewServer(http.NotFoundHandler())
+	closedBroker.Close()
+
+	newValidator := func(t *testing.T, brokerURL string) *ServerValidator {
+		t.Helper()
+		calls.Store(0)
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(brokerEndpointSlice(t, "broker", "mcp-system", brokerURL)).Build()
+		return NewServerValidator(k8sClient,
+			WithValidationTimeout(20*time.Millisecond),
+			WithValidationRetries(100, 10*time.Millisecond),
+			WithValidationDeadline(100*time.Millisecond),
+		)
+	}
+
+	testCases := []struct {
+		name     string
+		validate func(v *ServerValidator) error
+	}{
+		{
+			name: "ValidateServers",
+			validate: func(v *ServerValidator) error {
+				_, err := v.ValidateServers(context.Background(), "mcp-system")
+				return err
+			},
+		},
+		{
+			name: "ValidateServer",
+			validate: func(v *ServerValidator) error {
+				_, err := v.ValidateServer(context.Background(), "mcp-system", serverID)
+				return err
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name+" stops retrying a slow broker at the deadline", func(t *testing.T) {
+			v := newValidator(t, slowBroker.URL)
+			start := time.Now()
+			err := tc.validate(v)
+			require.ErrorIs(t, err, ErrBrokerUnreachable)
+			require.ErrorIs(t, err, ErrValidationTimeout)
+			require.Less(t, time.Since(start), time.Second)
+			require.Less(t, calls.Load(), int32(100))
+		})
+
+		t.Run(tc.name+" reports a refused broker as unreachable", func(t *testing.T) {
+			err := tc.validate(newValidator(t, closedBroker.URL))
+			require.ErrorIs(t, err, ErrBrokerUnreachable)
+			require.NotErrorIs(t, err, ErrValidationTimeout)
+			require.NotErrorIs(t, err, errServerStatusNotFound)
+		})
+	}
+}
+
+func TestServerValidator_serverStatusFromEndpoints(t *testing.T) {
+	const serverID = "team-a/weather:weather_:weather.local"
+	fakeBroker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") != serverID {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(upstream.ServerValidationStatus{ID: serverID, Name: "team-a/weather", Ready: true, TotalTools: 2})
+	}))
+	defer fakeBroker.Close()
+
+	t.Run("returns the requested server", func(t *testing.T) {
+		validator := NewServerValidator(nil)
+		status, err := validator.serverStatusFromEndpoints(context.Background(),
+			[]string{fakeBroker.URL + "/status?id=" + url.QueryEscape(serverID)})
+		require.NoError(t, err)
+		require.Equal(t, serverID, status.ID)
+		require.True(t, status.Ready)
+		require.Equal(t, 2, status.TotalTools)
+	})
+
+	t.Run("server the broker has not loaded is not found without retrying", func(t *testing.T) {
+		var calls atomic.Int32
+		countingBroker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			fakeBroker.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer countingBroker.Close()
+
+		validator := NewServerValidator(nil, WithValidationRetries(2, 10*time.Millisecond))
+		_, err := validator.serverStatusFromEndpoints(context.Background(),
+			[]string{countingBroker.URL + "/status?id=" + url.QueryEscape("team-b/time:time_:time.local")})
+		require.ErrorIs(t, err, errServerStatusNotFound)
+		require.NotErrorIs(t, err, ErrBrokerUnreachable)
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("unreachable broker is not a missing server", func(t *testing.T) {
+		closedBroker := httptest.NewServer(http.NotFoundHandler())
+		closedBroker.Close()
+
+		validator := NewServerValidator(nil)
+		_, err := validator.serverStatusFromEndpoints(context.Background(), []string{closedBroker.URL + "/status?id=x"})
+		require.ErrorIs(t, err, ErrBrokerUnreachable)
+		require.NotErrorIs(t, err, errServerStatusNotFound)
+	})
+}
+
+func TestServerValidator_versionFromEndpoints(t *testing.T) {
+	fakeBroker := httptest.NewServer(buildinfo.Handler(buildinfo.Info{Version: "v0.5.0", GitSHA: "abc1234"}))
+	defer fakeBroker.Close()
+	failingBroker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer failingBroker.Close()
+
+	validator := NewServerValidator(nil)
+
+	brokerVersion, err := validator.versionFromEndpoints(context.Background(), []string{failingBroker.URL, fakeBroker.URL + buildinfo.Path})
+	require.NoError(t, err)
+	require.Equal(t, "v0.5.0 (abc1234)", brokerVersion)
+
+	_, err = validator.versionFromEndpoints(context.Background(), []string{failingBroker.URL})
+	require.Error(t, err)
+}
+
+func TestServerValidator_statusFromTLSBroker(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	certPEM, keyPEM := selfSignedCertificate(t, "mcp-gateway.mcp-system.svc")
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	fakeBroker := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(broker.StatusResponse{TotalServers: 1})
+	}))
+	fakeBroker.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	fakeBroker.StartTLS()
+	defer fakeBroker.Close()
+	otherCertPEM, _ := selfSignedCertificate(t, "mcp-gateway.mcp-system.svc")
+	caConfigMap := func(namespace string, ca []byte) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: brokerCAConfigMapName, Namespace: namespace},
+			Data:       map[string]string{brokerCAKey: string(ca)},
+		}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		caConfigMap("mcp-system", certPEM),
+		// the broker in other-ns presents a certificate from a different CA
+		caConfigMap("other-ns", otherCertPEM),
+	).Build()
+	validator := NewServerValidator(k8sClient, WithValidationRetries(0, time.Millisecond))
+
+	t.Run("verifies the broker by its service name", func(t *testing.T) {
+		ctx := withBrokerNamespace(context.Background(), "mcp-system")
+		status, err := validator.statusFromEndpoints(ctx, []string{fakeBroker.URL + "/status"})
+		require.NoError(t, err)
+		require.Equal(t, 1, status.TotalServers)
+	})
+
+	t.Run("rejects a broker the CA did not issue", func(t *testing.T) {
+		ctx := withBrokerNamespace(context.Background(), "other-ns")
+		_, err := validator.statusFromEndpoints(ctx, []string{fakeBroker.URL + "/status"})
+		require.ErrorIs(t, err, ErrBrokerUnreachable)
+	})
+
+	t.Run("rejects a broker with no published CA", func(t *testing.T) {
+		ctx := withBrokerNamespace(context.Background(), "no-ca")
+		_, err := validator.statusFromEndpoints(ctx, []string{fakeBroker.URL + "/status"})
+		require.ErrorIs(t, err, ErrBrokerUnreachable)
+	})
+}
+
+func TestBrokerPublicPort(t *testing.T) {
+	testCases := []struct {
+		name       string
+		ports      []discoveryv1.EndpointPort
+		wantScheme string
+		wantPort   int32
+	}{
+		{
+			name: "custom http port",
+			ports: []discoveryv1.EndpointPort{
+				{Name: ptr.To("grpc"), Port: ptr.To(int32(50051))},
+				{Name: ptr.To("http"), Port: ptr.To(int32(9090))},
+			},
+			wantScheme: "http",
+			wantPort:   9090,
+		},
+		{
+			name:       "custom https port",
+			ports:      []discoveryv1.EndpointPort{{Name: ptr.To("https"), Port: ptr.To(int32(8443))}},
+			wantScheme: "https",
+			wantPort:   8443,
+		},
+		{
+			name:       "defaults when the public port is not listed",
+			ports:      []discoveryv1.EndpointPort{{Name: ptr.To("grpc"), Port: ptr.To(int32(50051))}},
+			wantScheme: "http",
+			wantPort:   8080,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme, port := brokerPublicPort(discoveryv1.EndpointSlice{Ports: tc.ports})
+			require.Equal(t, tc.wantScheme, scheme)
+			require.Equal(t, tc.wantPort, port)
+		})
+	}
+}
+
+func TestServerValidator_ValidateServer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, discoveryv1.AddToScheme(scheme))
+	const serverID = "team-a/weather:weather_:weather.local"
+	all := broker.StatusResponse{Servers: []upstream.ServerValidationStatus{
+		{ID: "team-b/time:time_:time.local", Name: "team-b/time"},
+		{ID: serverID, Name: "team-a/weather", Ready: true, TotalTools: 2},
+	}}
+	validator := func(t *testing.T, handler http.HandlerFunc) *ServerValidator {
+		t.Helper()
+		fakeBroker := httptest.NewServer(handler)
+		t.Cleanup(fakeBroker.Close)
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(brokerEndpointSlice(t, "broker", "mcp-system", fakeBroker.URL)).Build()
+		return NewServerValidator(k8sClient)
+	}
+
+	t.Run("broker filtering by id", func(t *testing.T) {
+		v := validator(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, serverID, r.URL.Query().Get("id"))
+			_ = json.NewEncoder(w).Encode(all.Servers[1])
+		})
+		status, err := v.ValidateServer(context.Background(), "mcp-system", serverID)
+		require.NoError(t, err)
+		require.Equal(t, serverID, status.ID)
+		require.Equal(t, 2, status.TotalTools)
+	})
+
+	t.Run("broker ignoring the id falls back to every server", func(t *testing.T) {
+		v := validator(t, func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(all)
+		})
+		status, err := v.ValidateServer(context.Background(), "mcp-system", serverID)
+		require.NoError(t, err)
+		require.Equal(t, serverID, status.ID)
+		require.True(t, status.Ready)
+		require.Equal(t, 2, status.TotalTools)
+
+		_, err = v.ValidateServer(context.Background(), "mcp-system", "team-c/docs:docs_:docs.local")
+		require.ErrorIs(t, err, errServerStatusNotFound)
+	})
+}
@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+// mcpServerNameHeader is set by the router to the name of the server a tool call, or the initialize of its backend
+// session, is routed to
+const mcpServerNameHeader = "x-mcp-servername"
+
+// serviceTargetRouteName returns the name of the HTTPRoute managed for a registration targeting a Service
+func serviceTargetRouteName(mcpsr *mcpv1alpha1.MCPServerRegistration) string {
+	return mcpsr.Name + "-mcp-service"
+}
+
+// reconcileServiceTargetRoute creates or updates the HTTPRoute routing tool calls to the Service targeted by the
+// registration. The route is attached to the extension's listener on its public host and only matches requests the
+// router sent to the registration's server, so it doesn't take any traffic from the broker route
+func (r *MCPReconciler) reconcileServiceTargetRoute(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration, mcpExt *mcpv1alpha1.MCPGatewayExtension) (*gatewayv1.HTTPRoute, error) {
+	path, err := mcpEndpointPath(mcpsr.Spec.Path)
+	if err != nil {
+		return nil, err
+	}
+	service, err := r.getTargetService(ctx, mcpsr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := targetServicePort(service, mcpsr.Spec.TargetRef.Port)
+	if err != nil {
+		return nil, err
+	}
+	publicHost, err := r.extensionPublicHost(ctx, mcpExt)
+	if err != nil {
+		return nil, err
+	}
+	desired := buildServiceTargetRoute(mcpsr, mcpExt, service, port, path, publicHost)
+	if err := controllerutil.SetControllerReference(mcpsr, desired, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set controller reference on httproute: %w", err)
+	}
+
+	existing := &gatewayv1.HTTPRoute{}
+	if err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get httproute %s: %w", desired.Name, err)
+		}
+		logf.FromContext(ctx).Info("creating service target httproute", "name", desired.Name, "namespace", desired.Namespace)
+		if err := r.Create(ctx, desired); err != nil {
+			return nil, fmt.Errorf("failed to create httproute %s: %w", desired.Name, err)
+		}
+		return desired, nil
+	}
+	if !metav1.IsControlledBy(existing, mcpsr) {
+		return nil, fmt.Errorf("httproute %s/%s already exists and is not managed by MCPServerRegistration %s",
+			existing.Namespace, existing.Name, mcpsr.Name)
+	}
+	if needsUpdate, reason := httpRouteNeedsUpdate(desired, existing); needsUpdate {
+		logf.FromContext(ctx).Info("updating service target httproute", "name", existing.Name, "reason", reason)
+		existing.Spec = desired.Spec
+		if err := r.Update(ctx, existing); err != nil {
+			return nil, fmt.Errorf("failed to update httproute %s: %w", existing.Name, err)
+		}
+	}
+	return existing, nil
+}
+
+// deleteServiceTargetRoute deletes the HTTPRoute managed for the registration when it no longer targets a Service or
+// has no extension to attach to
+func (r *MCPReconciler) deleteServiceTargetRoute(ctx context.Context, mcpsr *mcpv1alpha1.MCPServerRegistration) error {
+	existing := &gatewayv1.HTTPRoute{}
+	if err := r.Get(ctx, types.NamespacedName{Name: serviceTargetRouteName(mcpsr), Namespace: mcpsr.Namespace}, existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get httproute %s: %w", serviceTargetRouteName(mcpsr), err)
+	}
+	if !metav1.IsControlledBy(existing, mcpsr) {
+		return nil
+	}
+	if err := r.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete httproute %s: %w", existing.Name, err)
+	}
+	return nil
+}
+
+// extensionPublicHost returns the public host of the extension, derived from its listener when not set
+func (r *MCPReconciler) extensionPublicHost(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) (string, error) {
+	if mcpExt.Spec.PublicHost != "" {
+		return derivePublicHost(nil, mcpExt.Spec.PublicHost)
+	}
+	gatewayNamespace := mcpExt.Spec.TargetRef.Namespace
+	if gatewayNamespace == "" {
+		gatewayNamespace = mcpExt.Namespace
+	}
+	gateway := &gatewayv1.Gateway{}
+	if err := r.Get(ctx, types.NamespacedName{Name: mcpExt.Spec.TargetRef.Name, Namespace: gatewayNamespace}, gateway); err != nil {
+		return "", fmt.Errorf("failed to get gateway %s/%s: %w", gatewayNamespace, mcpExt.Spec.TargetRef.Name, err)
+	}
+	listenerConfig, err := findListenerConfigByName(gateway, mcpExt.Spec.TargetRef.SectionName)
+	if err != nil {
+		return "", err
+	}
+	return derivePublicHost(listenerConfig, "")
+}
+
+// buildServiceTargetRoute builds the HTTPRoute routing tool calls to the Service of a registration. The router sets
+// the authority of a tool call to the public host and names the server in a header, so the route matches the
+// server's path and name. An exact path match takes precedence over the broker route's prefix match
+func buildServiceTargetRoute(mcpsr *mcpv1alpha1.MCPServerRegistration, mcpExt *mcpv1alpha1.MCPGatewayExtension, service *corev1.Service, port *corev1.ServicePort, path, publicHost string) *gatewayv1.HTTPRoute {
+	gatewayNamespace := mcpExt.Spec.TargetRef.Namespace
+	if gatewayNamespace == "" {
+		gatewayNamespace = mcpExt.Namespace
+	}
+	sectionName := gatewayv1.SectionName(mcpExt.Spec.TargetRef.SectionName)
+	pathType := gatewayv1.PathMatchExact
+	headerType := gatewayv1.HeaderMatchExact
+	// an ExternalName Service without ports is reached on the default port of its scheme
+	backendPort := gatewayv1.PortNumber(80)
+	if port != nil {
+		backendPort = gatewayv1.PortNumber(port.Port)
+	}
+	backendNamespace := gatewayv1.Namespace(service.Namespace)
+
+	rule := gatewayv1.HTTPRouteRule{
+		Matches: []gatewayv1.HTTPRouteMatch{
+			{
+				Path: &gatewayv1.HTTPPathMatch{
+					Type:  &pathType,
+					Value: &path,
+				},
+				Headers: []gatewayv1.HTTPHeaderMatch{
+					{
+						Type:  &headerType,
+						Name:  mcpServerNameHeader,
+						Value: mcpServerName(mcpsr),
+					},
+				},
+			},
+		},
+		BackendRefs: []gatewayv1.HTTPBackendRef{
+			{
+				BackendRef: gatewayv1.BackendRef{
+					BackendObjectReference: gatewayv1.BackendObjectReference{
+						Group:     ptr.To(gatewayv1.Group("")),
+						Kind:      ptr.To(gatewayv1.Kind("Service")),
+						Name:      gatewayv1.ObjectName(service.Name),
+						Namespace: &backendNamespace,
+						Port:      &backendPort,
+					},
+				},
+			},
+		},
+	}
+	if service.Spec.Type == corev1.ServiceTypeExternalName {
+		// an external server expects its own hostname rather than the public host
+		rule.Filters = []gatewayv1.HTTPRouteFilter{
+			{
+				Type: gatewayv1.HTTPRouteFilterURLRewrite,
+				URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+					Hostname: ptr.To(gatewayv1.PreciseHostname(service.Spec.ExternalName)),
+				},
+			},
+		}
+	}
+
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceTargetRouteName(mcpsr),
+			Namespace: mcpsr.Namespace,
+			Labels: map[string]string{
+				labelManagedBy: labelManagedByValue,
+			},
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{
+					{
+						Group:       ptr.To(gatewayv1.Group("gateway.networking.k8s.io")),
+						Kind:        ptr.To(gatewayv1.Kind("Gateway")),
+						Name:        gatewayv1.ObjectName(mcpExt.Spec.TargetRef.Name),
+						Namespace:   ptr.To(gatewayv1.Namespace(gatewayNamespace)),
+						SectionName: &sectionName,
+					},
+				},
+			},
+			Hostnames: []gatewayv1.Hostname{
+				gatewayv1.Hostname(publicHost),
+			},
+			Rules: []gatewayv1.HTTPRouteRule{rule},
+		},
+	}
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+// testServiceTargetRoute is the HTTPRoute managed for the registrations targeting a Service in tests
+var testServiceTargetRoute = &gatewayv1.HTTPRoute{
+	ObjectMeta: metav1.ObjectMeta{Name: "registration-mcp-service", Namespace: "team-a"},
+	Spec:       gatewayv1.HTTPRouteSpec{Hostnames: []gatewayv1.Hostname{"team-a.example.com"}},
+}
+
+func TestReconcileServiceTargetRoute(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, gatewayv1.Install(scheme))
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "mcp-server", Namespace: "team-a"},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{{Name: "http", Port: 9090}},
+		},
+	}
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "registration", Namespace: "team-a", UID: "registration-uid"},
+		Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+			TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: "mcp-server"},
+			Path:      "/v1/mcp",
+		},
+	}
+	mcpExt := testExtension("team-a-mcp")
+	gateway := testGateway()
+	for i := range gateway.Spec.Listeners {
+		gateway.Spec.Listeners[i].Protocol = gatewayv1.HTTPProtocolType
+	}
+	r := &MCPReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(service, mcpsr, gateway).Build(),
+		Scheme: scheme,
+	}
+
+	route, err := r.reconcileServiceTargetRoute(context.Background(), mcpsr, mcpExt)
+	require.NoError(t, err)
+	created := &gatewayv1.HTTPRoute{}
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "registration-mcp-service", Namespace: "team-a"}, created))
+	require.True(t, metav1.IsControlledBy(created, mcpsr))
+	require.Equal(t, []gatewayv1.Hostname{"team-a.example.com"}, created.Spec.Hostnames)
+	require.Equal(t, route.Spec, created.Spec)
+
+	parentRef := created.Spec.ParentRefs[0]
+	require.Equal(t, gatewayv1.ObjectName("shared-gateway"), parentRef.Name)
+	require.Equal(t, gatewayv1.Namespace("gateway-system"), *parentRef.Namespace)
+	require.Equal(t, gatewayv1.SectionName("team-a-mcp"), *parentRef.SectionName)
+
+	match := created.Spec.Rules[0].Matches[0]
+	require.Equal(t, gatewayv1.PathMatchExact, *match.Path.Type)
+	require.Equal(t, "/v1/mcp", *match.Path.Value)
+	require.Equal(t, []gatewayv1.HTTPHeaderMatch{{Type: ptr.To(gatewayv1.HeaderMatchExact), Name: "x-mcp-servername", Value: "team-a/registration"}}, match.Headers)
+	backendRef := created.Spec.Rules[0].BackendRefs[0].BackendObjectReference
+	require.Equal(t, gatewayv1.ObjectName("mcp-server"), backendRef.Name)
+	require.Equal(t, gatewayv1.Namespace("team-a"), *backendRef.Namespace)
+	require.Equal(t, gatewayv1.PortNumber(9090), *backendRef.Port)
+
+	t.Run("updates the route when the path changes", func(t *testing.T) {
+		changed := mcpsr.DeepCopy()
+		changed.Spec.Path = "/mcp"
+		_, err := r.reconcileServiceTargetRoute(context.Background(), changed, mcpExt)
+		require.NoError(t, err)
+		updated := &gatewayv1.HTTPRoute{}
+		require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "registration-mcp-service", Namespace: "team-a"}, updated))
+		require.Equal(t, "/mcp", *updated.Spec.Rules[0].Matches[0].Path.Value)
+	})
+
+	t.Run("does not take over a route it does not manage", func(t *testing.T) {
+		other := mcpsr.DeepCopy()
+		other.UID = "other-uid"
+		_, err := r.reconcileServiceTargetRoute(context.Background(), other, mcpExt)
+		require.ErrorContains(t, err, "already exists and is not managed by MCPServerRegistration registration")
+		require.NoError(t, r.deleteServiceTargetRoute(context.Background(), other))
+		require.NoError(t, r.Get(context.Background(), types.NamespacedName{Name: "registration-mcp-service", Namespace: "team-a"}, &gatewayv1.HTTPRoute{}))
+	})
+
+	t.Run("deletes the route", func(t *testing.T) {
+		require.NoError(t, r.deleteServiceTargetRoute(context.Background(), mcpsr))
+		err := r.Get(context.Background(), types.NamespacedName{Name: "registration-mcp-service", Namespace: "team-a"}, &gatewayv1.HTTPRoute{})
+		require.True(t, apierrors.IsNotFound(err))
+		require.NoError(t, r.deleteServiceTargetRoute(context.Background(), mcpsr))
+	})
+}
+
+func TestBuildServiceTargetRoute_ExternalName(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "team-b"},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: "mcp.example.com",
+		},
+	}
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "registration", Namespace: "team-a"},
+		Spec: mcpv1alpha1.MCPServerRegistrationSpec{
+			TargetRef: mcpv1alpha1.TargetReference{Kind: "Service", Name: "external", Namespace: "team-b"},
+		},
+	}
+
+	route := buildServiceTargetRoute(mcpsr, testExtension("team-a-mcp"), service, nil, "/mcp", "team-a.example.com")
+	rule := route.Spec.Rules[0]
+	require.Equal(t, gatewayv1.Namespace("team-b"), *rule.BackendRefs[0].Namespace)
+	require.Equal(t, gatewayv1.PortNumber(80), *rule.BackendRefs[0].Port)
+	require.Len(t, rule.Filters, 1)
+	require.Equal(t, gatewayv1.PreciseHostname("mcp.example.com"), *rule.Filters[0].URLRewrite.Hostname)
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+const (
+	// sessionStoreConnectionKey is the session store secret entry holding the connection string
+	sessionStoreConnectionKey = "connectionString"
+	// sessionStoreEnvVar is the broker env var the connection string is loaded into
+	sessionStoreEnvVar = "CACHE_CONNECTION_STRING"
+)
+
+// reconcileSessionStore validates that the session store secret exists so the broker pods do not fail to start
+func (r *MCPGatewayExtensionReconciler) reconcileSessionStore(ctx context.Context, mcpExt *mcpv1alpha1.MCPGatewayExtension) error {
+	sessionStore := mcpExt.Spec.SessionStore
+	if sessionStore == nil {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	// use direct reader to avoid cache and informer setup for secrets
+	if err := r.DirectAPIReader.Get(ctx, client.ObjectKey{Name: sessionStore.SecretName, Namespace: mcpExt.Namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return newValidationError(mcpv1alpha1.ConditionReasonSecretNotFound,
+				fmt.Sprintf("secret %s not found in namespace %s", sessionStore.SecretName, mcpExt.Namespace))
+		}
+		return fmt.Errorf("failed to get session store secret: %w", err)
+	}
+
+	if len(secret.Data[sessionStoreConnectionKey]) == 0 {
+		return newValidationError(mcpv1alpha1.ConditionReasonSecretInvalid,
+			fmt.Sprintf("secret %s is missing required data entry %q", sessionStore.SecretName, sessionStoreConnectionKey))
+	}
+	return nil
+}
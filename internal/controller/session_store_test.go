@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+func TestReconcileSessionStore(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	valid := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-connection", Namespace: "test-ns"},
+		Data:       map[string][]byte{sessionStoreConnectionKey: []byte("redis://redis:6379")},
+	}
+	noKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-key", Namespace: "test-ns"},
+		Data:       map[string][]byte{"url": []byte("redis://redis:6379")},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(valid, noKey).Build()
+	r := &MCPGatewayExtensionReconciler{DirectAPIReader: reader}
+
+	tests := []struct {
+		name         string
+		sessionStore *mcpv1alpha1.SessionStore
+		wantReason   string
+	}{
+		{name: "not configured"},
+		{name: "valid secret", sessionStore: &mcpv1alpha1.SessionStore{Type: mcpv1alpha1.SessionStoreRedis, SecretName: "redis-connection"}},
+		{
+			name:         "missing secret",
+			sessionStore: &mcpv1alpha1.SessionStore{Type: mcpv1alpha1.SessionStoreRedis, SecretName: "missing"},
+			wantReason:   mcpv1alpha1.ConditionReasonSecretNotFound,
+		},
+		{
+			name:         "missing connection string entry",
+			sessionStore: &mcpv1alpha1.SessionStore{Type: mcpv1alpha1.SessionStoreRedis, SecretName: "no-key"},
+			wantReason:   mcpv1alpha1.ConditionReasonSecretInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpExt := &mcpv1alpha1.MCPGatewayExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ext", Namespace: "test-ns"},
+				Spec:       mcpv1alpha1.MCPGatewayExtensionSpec{SessionStore: tt.sessionStore},
+			}
+			err := r.reconcileSessionStore(context.Background(), mcpExt)
+			if tt.wantReason == "" {
+				require.NoError(t, err)
+				return
+			}
+			var valErr *validationError
+			require.ErrorAs(t, err, &valErr)
+			require.Equal(t, tt.wantReason, valErr.reason)
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
@@ -73,6 +74,9 @@ var _ = BeforeSuite(func() {
 			filepath.Join("..", "..", "config", "crd", "istio"),
 		},
 		ErrorIfCRDPathMissing: true,
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("..", "..", "config", "webhook", "manifests.yaml")},
+		},
 	}
 
 	// Retrieve the first found binary directory to allow running tests from IDEs
@@ -91,6 +95,11 @@ var _ = BeforeSuite(func() {
 		Metrics: metricsserver.Options{
 			BindAddress: "0", // disable metrics
 		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    testEnv.WebhookInstallOptions.LocalServingHost,
+			Port:    testEnv.WebhookInstallOptions.LocalServingPort,
+			CertDir: testEnv.WebhookInstallOptions.LocalServingCertDir,
+		}),
 	})
 	Expect(err).NotTo(HaveOccurred())
 
@@ -100,6 +109,18 @@ var _ = BeforeSuite(func() {
 	err = setupIndexExtensionToReferenceGrant(ctx, testMgr.GetFieldIndexer())
 	Expect(err).NotTo(HaveOccurred())
 
+	// set up field indexes for the MCPServerRegistration controller's HTTPRoute cleanup
+	err = setupIndexMCPRegistrationToHTTPRoute(ctx, testMgr.GetFieldIndexer())
+	Expect(err).NotTo(HaveOccurred())
+	err = setupIndexProgrammedHTTPRoutes(ctx, testMgr.GetFieldIndexer())
+	Expect(err).NotTo(HaveOccurred())
+	err = setupIndexHTTPRouteToBackendService(ctx, testMgr.GetFieldIndexer())
+	Expect(err).NotTo(HaveOccurred())
+
+	// serve the MCPServerRegistration validating webhook
+	err = (&MCPServerRegistrationValidator{Reader: testMgr.GetAPIReader()}).SetupWebhookWithManager(testMgr)
+	Expect(err).NotTo(HaveOccurred())
+
 	// start the manager's cache
 	go func() {
 		err := testMgr.Start(ctx)
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+)
+
+const (
+	// EventReasonToolConflict is the event reason when the broker rejects a registration's tools because a server
+	// of equal priority serves tools with the same names
+	EventReasonToolConflict = "ToolConflict"
+	// EventReasonToolConflictResolved is the event reason when a registration's conflicting tools are shadowed by a
+	// higher priority or older server or the conflict goes away
+	EventReasonToolConflictResolved = "ToolConflictResolved"
+)
+
+const (
+	toolConflictDetected = "detected"
+	toolConflictResolved = "resolved"
+)
+
+var (
+	toolConflictsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_gateway_tool_conflicts_total",
+		Help: "Number of tool name conflicts detected or resolved for an MCPServerRegistration",
+	}, []string{"namespace", "name", "outcome"})
+
+	registerToolConflictMetricsOnce sync.Once
+)
+
+func registerToolConflictMetrics() {
+	registerToolConflictMetricsOnce.Do(func() {
+		metrics.Registry.MustRegister(toolConflictsTotal)
+	})
+}
+
+// toolConflictState summarises the conflicts reported by the broker so a change can be detected between polls
+func toolConflictState(status upstream.ServerValidationStatus) string {
+	if len(status.ConflictingTools) == 0 && len(status.ShadowedTools) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("conflicting=%s shadowed=%s servers=%s", strings.Join(status.ConflictingTools, ","),
+		strings.Join(status.ShadowedTools, ","), strings.Join(status.ConflictingServers, ","))
+}
+
+// recordToolConflicts emits an event and counts each change in the tool conflicts the broker reports for a
+// registration. Conflicts with a server of equal priority are detected, tools shadowed by a higher priority or older server and
+// conflicts that go away are resolved
+func (r *MCPReconciler) recordToolConflicts(mcpsr *mcpv1alpha1.MCPServerRegistration, status upstream.ServerValidationStatus) {
+	key := client.ObjectKeyFromObject(mcpsr)
+	state := toolConflictState(status)
+	previous, _ := r.toolConflicts.Load(key)
+	if previous == nil {
+		previous = ""
+	}
+	if state == previous {
+		return
+	}
+	if state == "" {
+		r.toolConflicts.Delete(key)
+	} else {
+		r.toolConflicts.Store(key, state)
+	}
+
+	var eventType, reason, outcome, note string
+	switch {
+	case len(status.ConflictingTools) > 0:
+		eventType, reason, outcome = corev1.EventTypeWarning, EventReasonToolConflict, toolConflictDetected
+		note = fmt.Sprintf("tools %v conflict with servers %v of equal priority", status.ConflictingTools, status.ConflictingServers)
+	case len(status.ShadowedTools) > 0:
+		eventType, reason, outcome = corev1.EventTypeNormal, EventReasonToolConflictResolved, toolConflictResolved
+		note = fmt.Sprintf("tools %v shadowed by higher priority or older servers %v", status.ShadowedTools, status.ConflictingServers)
+	default:
+		eventType, reason, outcome = corev1.EventTypeNormal, EventReasonToolConflictResolved, toolConflictResolved
+		note = "tool conflicts cleared"
+	}
+	registerToolConflictMetrics()
+	toolConflictsTotal.WithLabelValues(mcpsr.Namespace, mcpsr.Name, outcome).Inc()
+	if r.Recorder != nil {
+		r.Recorder.Eventf(mcpsr, nil, eventType, reason, "SyncTools", note)
+	}
+}
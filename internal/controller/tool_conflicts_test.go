@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
+)
+
+func TestRecordToolConflicts(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	r := &MCPReconciler{Recorder: recorder}
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{ObjectMeta: metav1.ObjectMeta{Name: "conflicts", Namespace: "team-a"}}
+	detected := toolConflictsTotal.WithLabelValues("team-a", "conflicts", toolConflictDetected)
+	resolved := toolConflictsTotal.WithLabelValues("team-a", "conflicts", toolConflictResolved)
+
+	// no conflicts produces nothing
+	r.recordToolConflicts(mcpsr, upstream.ServerValidationStatus{Ready: true})
+	require.Empty(t, recorder.Events)
+
+	conflict := upstream.ServerValidationStatus{
+		Reason:             upstream.ReasonToolConflict,
+		ConflictingTools:   []string{"search"},
+		ConflictingServers: []string{"team-b/other:search:other.local"},
+	}
+	r.recordToolConflicts(mcpsr, conflict)
+	require.Equal(t, "Warning ToolConflict tools [search] conflict with servers [team-b/other:search:other.local] of equal priority", <-recorder.Events)
+	require.Equal(t, 1.0, testutil.ToFloat64(detected))
+
+	// the same conflict reported again by the next poll is not recorded twice
+	r.recordToolConflicts(mcpsr, conflict)
+	require.Empty(t, recorder.Events)
+	require.Equal(t, 1.0, testutil.ToFloat64(detected))
+
+	// raising the other server's priority resolves the conflict by shadowing the tool
+	r.recordToolConflicts(mcpsr, upstream.ServerValidationStatus{
+		Ready:              true,
+		ShadowedTools:      []string{"search"},
+		ConflictingServers: []string{"team-b/other:search:other.local"},
+	})
+	require.Equal(t, "Normal ToolConflictResolved tools [search] shadowed by higher priority or older servers [team-b/other:search:other.local]", <-recorder.Events)
+	require.Equal(t, 1.0, testutil.ToFloat64(resolved))
+
+	r.recordToolConflicts(mcpsr, upstream.ServerValidationStatus{Ready: true})
+	require.Equal(t, "Normal ToolConflictResolved tool conflicts cleared", <-recorder.Events)
+	require.Equal(t, 2.0, testutil.ToFloat64(resolved))
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const tracerName = "mcp-gateway-controller"
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// startSpan starts a span for a reconcile phase of the named resource
+func startSpan(ctx context.Context, name, kind string, resource types.NamespacedName, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append([]attribute.KeyValue{
+		attribute.String("k8s.resource.kind", kind),
+		attribute.String("k8s.namespace.name", resource.Namespace),
+		attribute.String("k8s.resource.name", resource.Name),
+	}, attrs...)
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on the span, if any, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// statusCheckFailure returns err unless it only reports the broker has not finished with the server yet, which is
+// expected while a registration becomes ready rather than a failed status check
+func statusCheckFailure(err error) error {
+	if errors.Is(err, errServerNotPresent) || errors.Is(err, errServerBackoff) || errors.Is(err, errStatusDeferred) {
+		return nil
+	}
+	return err
+}
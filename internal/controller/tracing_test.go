@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
+)
+
+func TestReconcile_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	mcpsr := &mcpv1alpha1.MCPServerRegistration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "weather",
+			Namespace:         "team-a",
+			Finalizers:        []string{mcpGatewayFinalizer},
+			DeletionTimestamp: ptr.To(metav1.NewTime(time.Now())),
+		},
+		Spec:   mcpv1alpha1.MCPServerRegistrationSpec{DrainTimeout: &metav1.Duration{Duration: time.Minute}},
+		Status: mcpv1alpha1.MCPServerRegistrationStatus{ConfigNamespaces: []string{"mcp-system"}, ServerID: "id"},
+	}
+	r := &MCPReconciler{
+		Client:             fake.NewClientBuilder().WithScheme(scheme).WithObjects(mcpsr).Build(),
+		Scheme:             scheme,
+		ConfigReaderWriter: &recordingConfigWriter{},
+		DrainFetcher:       &fakeDrainFetcher{status: ServerDrainStatus{Draining: true, InFlightToolCalls: 1}},
+	}
+
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "weather", Namespace: "team-a"}})
+	require.NoError(t, err)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	reconcileSpan, ok := spans["MCPServerRegistration.Reconcile"]
+	require.True(t, ok, "reconcile span not recorded")
+	require.Contains(t, reconcileSpan.Attributes(), attribute.String("k8s.resource.kind", "MCPServerRegistration"))
+	require.Contains(t, reconcileSpan.Attributes(), attribute.String("k8s.namespace.name", "team-a"))
+	require.Contains(t, reconcileSpan.Attributes(), attribute.String("k8s.resource.name", "weather"))
+
+	drainSpan, ok := spans["MCPServerRegistration.drainServer"]
+	require.True(t, ok, "drain span not recorded")
+	require.Equal(t, reconcileSpan.SpanContext().SpanID(), drainSpan.Parent().SpanID())
+	require.Contains(t, drainSpan.Attributes(), attribute.String("mcp.server.id", "id"))
+}
+
+func TestReconcile_VirtualServerSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, mcpv1alpha1.AddToScheme(scheme))
+	r := &MCPVirtualServerReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "tools", Namespace: "team-a"}})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "MCPVirtualServer.Reconcile", spans[0].Name())
+	require.Contains(t, spans[0].Attributes(), attribute.String("k8s.resource.kind", "MCPVirtualServer"))
+	require.Contains(t, spans[0].Attributes(), attribute.String("k8s.resource.name", "tools"))
+}
@@ -0,0 +1,130 @@
+package mcprouter
+
+import (
+	"context"
+	"sync"
+)
+
+// FairScheduler limits the number of concurrent tool calls routed to each upstream MCP server.
+// When a server is at its limit waiting calls are queued per session and granted round robin across sessions,
+// so a single session issuing many calls cannot starve other sessions sharing the same server
+type FairScheduler struct {
+	limit   int
+	mu      sync.Mutex
+	servers map[string]*serverQueue
+}
+
+// serverQueue tracks the in flight calls and waiting sessions for a single upstream MCP server
+type serverQueue struct {
+	inFlight int
+	// sessions is the round robin order of sessions with waiting calls
+	sessions []string
+	// waiting holds the queued calls for each session in arrival order
+	waiting map[string][]chan struct{}
+}
+
+// NewFairScheduler returns a scheduler allowing up to limit concurrent tool calls per upstream MCP server
+func NewFairScheduler(limit int) *FairScheduler {
+	return &FairScheduler{
+		limit:   limit,
+		servers: map[string]*serverQueue{},
+	}
+}
+
+// Acquire blocks until a tool call from session may be sent to server. The returned func must be called once the
+// call is done to free the slot. An error is returned if ctx is done before a slot is granted
+func (f *FairScheduler) Acquire(ctx context.Context, server, session string) (func(), error) {
+	f.mu.Lock()
+	q := f.queue(server)
+	if q.inFlight < f.limit && len(q.sessions) == 0 {
+		q.inFlight++
+		f.mu.Unlock()
+		return f.releaser(server), nil
+	}
+	ready := make(chan struct{})
+	if len(q.waiting[session]) == 0 {
+		q.sessions = append(q.sessions, session)
+	}
+	q.waiting[session] = append(q.waiting[session], ready)
+	f.mu.Unlock()
+
+	select {
+	case <-ready:
+		return f.releaser(server), nil
+	case <-ctx.Done():
+		f.mu.Lock()
+		removed := q.remove(session, ready)
+		f.mu.Unlock()
+		if !removed {
+			// the slot was granted while we were giving up so hand it on
+			f.release(server)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (f *FairScheduler) queue(server string) *serverQueue {
+	q, ok := f.servers[server]
+	if !ok {
+		q = &serverQueue{waiting: map[string][]chan struct{}{}}
+		f.servers[server] = q
+	}
+	return q
+}
+
+func (f *FairScheduler) releaser(server string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { f.release(server) })
+	}
+}
+
+// release frees a slot for server and grants it to the next waiting session in round robin order
+func (f *FairScheduler) release(server string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q, ok := f.servers[server]
+	if !ok {
+		return
+	}
+	q.inFlight--
+	for q.inFlight < f.limit && len(q.sessions) > 0 {
+		session := q.sessions[0]
+		q.sessions = q.sessions[1:]
+		ready := q.waiting[session][0]
+		q.waiting[session] = q.waiting[session][1:]
+		if len(q.waiting[session]) > 0 {
+			// the session goes to the back of the line for its next call
+			q.sessions = append(q.sessions, session)
+		} else {
+			delete(q.waiting, session)
+		}
+		q.inFlight++
+		close(ready)
+	}
+	if q.inFlight == 0 && len(q.sessions) == 0 {
+		delete(f.servers, server)
+	}
+}
+
+// remove drops a queued call. It returns false if the call is no longer queued because it has been granted a slot
+func (q *serverQueue) remove(session string, ready chan struct{}) bool {
+	calls := q.waiting[session]
+	for i, call := range calls {
+		if call != ready {
+			continue
+		}
+		q.waiting[session] = append(calls[:i], calls[i+1:]...)
+		if len(q.waiting[session]) == 0 {
+			delete(q.waiting, session)
+			for j, s := range q.sessions {
+				if s == session {
+					q.sessions = append(q.sessions[:j], q.sessions[j+1:]...)
+					break
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
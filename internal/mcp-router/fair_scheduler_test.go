@@ -0,0 +1,102 @@
+package mcprouter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// queuedCalls returns the number of calls waiting for server
+func (f *FairScheduler) queuedCalls(server string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q, ok := f.servers[server]
+	if !ok {
+		return 0
+	}
+	total := 0
+	for _, calls := range q.waiting {
+		total += len(calls)
+	}
+	return total
+}
+
+func TestFairScheduler_SessionsAreNotStarved(t *testing.T) {
+	const server = "backend"
+	scheduler := NewFairScheduler(1)
+	ctx := context.Background()
+
+	// hold the only slot so every call below has to queue
+	release, err := scheduler.Acquire(ctx, server, "busy")
+	require.NoError(t, err)
+
+	granted := make(chan string)
+	queue := func(session string) {
+		queued := scheduler.queuedCalls(server)
+		go func() {
+			done, err := scheduler.Acquire(ctx, server, session)
+			if err != nil {
+				return
+			}
+			granted <- session
+			done()
+		}()
+		require.Eventually(t, func() bool { return scheduler.queuedCalls(server) == queued+1 }, time.Second, time.Millisecond)
+	}
+	// the busy session floods the backend before the quiet session issues its calls
+	for range 10 {
+		queue("busy")
+	}
+	queue("quiet")
+	queue("quiet")
+
+	release()
+	var order []string
+	for range 12 {
+		select {
+		case session := <-granted:
+			order = append(order, session)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for a slot, granted so far %v", order)
+		}
+	}
+
+	// calls alternate between sessions so both quiet calls are served before the busy session drains its queue
+	require.Equal(t, []string{"busy", "quiet", "busy", "quiet"}, order[:4])
+	require.Equal(t, 0, scheduler.queuedCalls(server))
+}
+
+func TestFairScheduler_LimitAndCancel(t *testing.T) {
+	const server = "backend"
+	scheduler := NewFairScheduler(2)
+
+	first, err := scheduler.Acquire(context.Background(), server, "a")
+	require.NoError(t, err)
+	second, err := scheduler.Acquire(context.Background(), server, "b")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = scheduler.Acquire(ctx, server, "c")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 0, scheduler.queuedCalls(server))
+
+	first()
+	// releasing twice must not free a second slot
+	first()
+	third, err := scheduler.Acquire(context.Background(), server, "c")
+	require.NoError(t, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = scheduler.Acquire(ctx, server, "d")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	second()
+	third()
+	scheduler.mu.Lock()
+	require.Empty(t, scheduler.servers)
+	scheduler.mu.Unlock()
+}
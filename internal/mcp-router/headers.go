@@ -2,6 +2,8 @@ package mcprouter
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	basepb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 )
@@ -15,6 +17,12 @@ const (
 	authorityHeader       = ":authority"
 	authorizationHeader   = "authorization"
 	mcpTarget             = "mcp-target"
+	upstreamLatencyHeader = "x-mcp-upstream-latency-ms"
+	envoyRetryOnHeader    = "x-envoy-retry-on"
+	envoyMaxRetries       = "x-envoy-max-retries"
+	envoyUpstreamTimeout  = "x-envoy-upstream-rq-timeout-ms"
+	// retryOnConnectionFailure only retries when the upstream could not be reached or reset before responding
+	retryOnConnectionFailure = "connect-failure,reset"
 	// RoutingKey is an internal header used to authenticate a request from the router
 	RoutingKey = "router-key"
 )
@@ -136,6 +144,31 @@ func (hb *HeadersBuilder) WithToolAnnotations(annotations string) *HeadersBuilde
 	return hb
 }
 
+// WithUpstreamLatency will set the x-mcp-upstream-latency-ms header
+func (hb *HeadersBuilder) WithUpstreamLatency(latency time.Duration) *HeadersBuilder {
+	hb.headers = append(hb.headers, &basepb.HeaderValueOption{
+		Header: &basepb.HeaderValue{
+			Key:      upstreamLatencyHeader,
+			RawValue: []byte(strconv.FormatInt(latency.Milliseconds(), 10)),
+		},
+	})
+	return hb
+}
+
+// WithRetryPolicy will set the envoy retry headers so a call is retried up to retries times after a connection
+// failure. A retries of 0 disables retries so the call fails fast
+func (hb *HeadersBuilder) WithRetryPolicy(retries int) *HeadersBuilder {
+	if retries > 0 {
+		hb.WithCustomHeader(envoyRetryOnHeader, retryOnConnectionFailure)
+	}
+	return hb.WithCustomHeader(envoyMaxRetries, strconv.Itoa(retries))
+}
+
+// WithUpstreamTimeout will set the envoy upstream request timeout for the call
+func (hb *HeadersBuilder) WithUpstreamTimeout(timeout time.Duration) *HeadersBuilder {
+	return hb.WithCustomHeader(envoyUpstreamTimeout, strconv.FormatInt(timeout.Milliseconds(), 10))
+}
+
 // WithCustomHeader will set key with value in the headers
 func (hb *HeadersBuilder) WithCustomHeader(key, value string) *HeadersBuilder {
 	hb.headers = append(hb.headers, &basepb.HeaderValueOption{
@@ -11,9 +11,11 @@ import (
 	"github.com/Kuadrant/mcp-gateway/internal/config"
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	eppb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/mark3labs/mcp-go/mcp"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"k8s.io/utils/ptr"
 )
 
 // ErrInvalidRequest is an error for an invalid request
@@ -75,6 +77,10 @@ type MCPRequest struct {
 	Streaming  bool              `json:"-"`
 	sessionID  string            `json:"-"`
 	serverName string            `json:"-"`
+	// serverID identifies the upstream a tool call is routed to
+	serverID config.UpstreamMCPID
+	// routedAt is when the routing decision was returned to envoy
+	routedAt time.Time
 }
 
 // GetSingleHeaderValue returns a single header value
@@ -266,7 +272,26 @@ data: {"result":{"content":[{"type":"text","text":"MCP error -32602: Tool not fo
 		attribute.String("mcp.server", serverInfo.Name),
 		attribute.String("mcp.server.hostname", serverInfo.Hostname),
 	)
-	if annotations, hasAnnotations := s.Broker.ToolAnnotations(serverInfo.ID(), toolName); hasAnnotations {
+	// the tools of an unreachable server are only still listed when its policy keeps them, so fail the call rather
+	// than wait on a backend that is down
+	if serverInfo.KeepToolsWhenUnavailable() && s.Broker.UpstreamUnavailable(serverInfo.ID()) {
+		s.Logger.DebugContext(ctx, "upstream unavailable for tool", "toolName", toolName, "server", serverInfo.Name)
+		span.SetStatus(codes.Error, "upstream unavailable")
+		span.SetAttributes(attribute.String("error.type", "upstream_unavailable"))
+		calculatedResponse.WithImmediateJSONRPCResponse(200,
+			[]*corev3.HeaderValueOption{
+				{
+					Header: &corev3.HeaderValue{
+						Key:   "mcp-session-id",
+						Value: mcpReq.GetSessionID(),
+					},
+				},
+			},
+			upstreamUnavailableEvent(mcpReq, serverInfo.Name))
+		return calculatedResponse.Build()
+	}
+	annotations, hasAnnotations := s.Broker.ToolAnnotations(serverInfo.ID(), toolName)
+	if hasAnnotations {
 		// build header value (e.g. readOnly=true,destructive=false,openWorld=true)
 		var parts []string
 		push := func(key string, val *bool) {
@@ -290,8 +315,30 @@ data: {"result":{"content":[{"type":"text","text":"MCP error -32602: Tool not fo
 
 	headers.WithMCPMethod(mcpReq.Method)
 	mcpReq.serverName = serverInfo.Name
-	upstreamToolName, _ := strings.CutPrefix(toolName, serverInfo.ToolPrefix)
+	mcpReq.serverID = serverInfo.ID()
+	upstreamToolName, _ := serverInfo.UpstreamToolName(toolName)
 	headers.WithMCPToolName(upstreamToolName)
+	// the server's call timeout replaces the router default, a timeout hint on the tool still wins
+	fallbackTimeout := s.ToolCallTimeout
+	if serverTimeout, err := serverInfo.RequestTimeout(); err != nil {
+		s.Logger.DebugContext(ctx, "ignoring invalid call timeout", "server", serverInfo.Name, "error", err)
+	} else if serverTimeout > 0 {
+		fallbackTimeout = serverTimeout
+	}
+	if timeout := s.Broker.ToolTimeout(serverInfo.ID(), toolName, fallbackTimeout); timeout > 0 {
+		if s.RouteTimeout > 0 && timeout > s.RouteTimeout {
+			s.Logger.DebugContext(ctx, "clamping tool call timeout to the route timeout", "tool", toolName, "timeout", timeout, "routeTimeout", s.RouteTimeout)
+			timeout = s.RouteTimeout
+		}
+		headers.WithUpstreamTimeout(timeout)
+	}
+	if s.ToolCallRetries > 0 {
+		if toolRetrySafe(serverInfo, upstreamToolName, annotations) {
+			headers.WithRetryPolicy(s.ToolCallRetries)
+		} else {
+			headers.WithRetryPolicy(0)
+		}
+	}
 	mcpReq.ReWriteToolName(upstreamToolName)
 	headers.WithMCPServerName(serverInfo.Name)
 
@@ -375,6 +422,19 @@ data: {"result":{"content":[{"type":"text","text":"MCP error -32602: Tool not fo
 	return calculatedResponse.Build()
 }
 
+// upstreamUnavailableEvent is the tool call result event returned when the upstream serving the tool is unreachable
+func upstreamUnavailableEvent(mcpReq *MCPRequest, serverName string) string {
+	response := mcp.JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Result:  mcp.NewToolResultError(fmt.Sprintf("upstream unavailable: MCP server %s is unreachable", serverName)),
+	}
+	if mcpReq.ID != nil {
+		response.ID = mcp.NewRequestId(*mcpReq.ID)
+	}
+	data, _ := json.Marshal(response)
+	return "\nevent: message\ndata: " + string(data)
+}
+
 // initializeMCPSeverSession will create a new session and connection with the backend MCP server
 // This connection is kept open for the life of the gateway session to ensure the backend session is not closed/invalidated.
 // TODO when we receive a 404 from a backend MCP Server we should have a way to close the connection at that point also currently when we receive a 404 we remove the session from cache and will open a new connection. They will all be closed once the gateway session expires or the client sends a delete but it is a source of potential leaks
@@ -500,3 +560,12 @@ func (s *ExtProcServer) HandleNoneToolCall(ctx context.Context, mcpReq *MCPReque
 	return response.WithRequestBodyHeadersResponse(headers.Build()).Build()
 
 }
+
+// toolRetrySafe returns true if a call to the tool can be retried after a connection failure. An idempotent tool
+// override takes precedence over the readOnlyHint and idempotentHint annotations advertised by the upstream tool
+func toolRetrySafe(serverInfo *config.MCPServer, upstreamToolName string, annotations mcp.ToolAnnotation) bool {
+	if override, ok := serverInfo.ToolOverride(upstreamToolName); ok && override.Idempotent != nil {
+		return *override.Idempotent
+	}
+	return ptr.Deref(annotations.ReadOnlyHint, false) || ptr.Deref(annotations.IdempotentHint, false)
+}
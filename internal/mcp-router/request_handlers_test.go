@@ -10,11 +10,13 @@ import (
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/config"
 	"github.com/Kuadrant/mcp-gateway/internal/session"
 	eppb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -247,6 +249,351 @@ func TestHandleRequestBody(t *testing.T) {
 		string(rb.RequestBody.Response.BodyMutation.GetBody()))
 }
 
+func TestHandleRequestBody_ToolNameTemplate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cache, err := session.NewCache(context.Background())
+	require.NoError(t, err)
+	jwtManager, err := session.NewJWTManager("test-signing-key", 0, logger, cache)
+	require.NoError(t, err)
+	validToken := jwtManager.Generate()
+	_, err = cache.AddSession(context.Background(), validToken, "dummy", "mock-upstream-session-id")
+	require.NoError(t, err)
+
+	serverConfigs := []*config.MCPServer{
+		{
+			Name:             "dummy",
+			URL:              "http://localhost:8080/mcp",
+			ToolPrefix:       "s",
+			ToolNameTemplate: "{tool}_{prefix}",
+			Enabled:          true,
+			Hostname:         "localhost",
+		},
+	}
+	server := &ExtProcServer{
+		RoutingConfig: &config.MCPServersConfig{Servers: serverConfigs},
+		JWTManager:    jwtManager,
+		Logger:        logger,
+		SessionCache:  cache,
+		Broker:        newMockBroker(serverConfigs, map[string]string{"mytool_s": "dummy"}),
+	}
+
+	resp := server.RouteMCPRequest(context.Background(), &MCPRequest{
+		ID:      ptr.To(0),
+		JSONRPC: "2.0",
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "mytool_s"},
+		Headers: &corev3.HeaderMap{
+			Headers: []*corev3.HeaderValue{{Key: "mcp-session-id", RawValue: []byte(validToken)}},
+		},
+	})
+	require.Len(t, resp, 1)
+	rb := resp[0].Response.(*eppb.ProcessingResponse_RequestBody)
+	require.Equal(t, "x-mcp-toolname", rb.RequestBody.Response.HeaderMutation.SetHeaders[1].Header.Key)
+	require.Equal(t, []uint8("mytool"), rb.RequestBody.Response.HeaderMutation.SetHeaders[1].Header.RawValue)
+	require.Equal(t,
+		`{"id":0,"jsonrpc":"2.0","method":"tools/call","params":{"name":"mytool"}}`,
+		string(rb.RequestBody.Response.BodyMutation.GetBody()))
+}
+
+func TestHandleRequestBody_RewrittenRoute(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cache, err := session.NewCache(context.Background())
+	require.NoError(t, err)
+	jwtManager, err := session.NewJWTManager("test-signing-key", 0, logger, cache)
+	require.NoError(t, err)
+	validToken := jwtManager.Generate()
+	_, err = cache.AddSession(context.Background(), validToken, "dummy", "mock-upstream-session-id")
+	require.NoError(t, err)
+
+	// the HTTPRoute rewrites /mcp to /v1/mcp, the broker connects to the rewritten URL directly
+	serverConfigs := []*config.MCPServer{
+		{
+			Name:       "dummy",
+			URL:        "http://mcp-server.team-a.svc.cluster.local:8080/mcp",
+			BrokerURL:  "http://mcp-server.team-a.svc.cluster.local:8080/v1/mcp",
+			ToolPrefix: "s_",
+			Enabled:    true,
+			Hostname:   "mcp.mcp.local",
+		},
+	}
+	server := &ExtProcServer{
+		RoutingConfig: &config.MCPServersConfig{Servers: serverConfigs},
+		JWTManager:    jwtManager,
+		Logger:        logger,
+		SessionCache:  cache,
+		Broker:        newMockBroker(serverConfigs, map[string]string{"s_mytool": "dummy"}),
+	}
+
+	resp := server.RouteMCPRequest(context.Background(), &MCPRequest{
+		ID:      ptr.To(0),
+		JSONRPC: "2.0",
+		Method:  "tools/call",
+		Params:  map[string]any{"name": "s_mytool"},
+		Headers: &corev3.HeaderMap{
+			Headers: []*corev3.HeaderValue{{Key: "mcp-session-id", RawValue: []byte(validToken)}},
+		},
+	})
+	require.Len(t, resp, 1)
+	rb := resp[0].Response.(*eppb.ProcessingResponse_RequestBody)
+	// the call is re-routed with the path and hostname the route matches so the gateway applies the rewrite
+	setHeaders := map[string]string{}
+	for _, header := range rb.RequestBody.Response.HeaderMutation.SetHeaders {
+		setHeaders[header.Header.Key] = string(header.Header.RawValue)
+	}
+	require.Equal(t, "/mcp", setHeaders[":path"])
+	require.Equal(t, "mcp.mcp.local", setHeaders[":authority"])
+}
+
+func TestHandleRequestBody_ToolCallRetries(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cache, err := session.NewCache(context.Background())
+	require.NoError(t, err)
+	jwtManager, err := session.NewJWTManager("test-signing-key", 0, logger, cache)
+	require.NoError(t, err)
+	validToken := jwtManager.Generate()
+	_, err = cache.AddSession(context.Background(), validToken, "dummy", "mock-upstream-session-id")
+	require.NoError(t, err)
+
+	serverConfigs := []*config.MCPServer{
+		{
+			Name:       "dummy",
+			URL:        "http://localhost:8080/mcp",
+			ToolPrefix: "s_",
+			Enabled:    true,
+			Hostname:   "localhost",
+			ToolOverrides: []config.ToolOverride{
+				{Name: "charge", Idempotent: ptr.To(false)},
+				{Name: "refresh", Idempotent: ptr.To(true)},
+			},
+		},
+	}
+	mockBroker := newMockBroker(serverConfigs, map[string]string{
+		"s_read":    "dummy",
+		"s_write":   "dummy",
+		"s_charge":  "dummy",
+		"s_refresh": "dummy",
+	}).(*mockBrokerImpl)
+	mockBroker.annotations = map[string]mcp.ToolAnnotation{
+		"s_read":   {ReadOnlyHint: ptr.To(true)},
+		"s_write":  {ReadOnlyHint: ptr.To(false), IdempotentHint: ptr.To(false)},
+		"s_charge": {ReadOnlyHint: ptr.To(true)},
+	}
+	server := &ExtProcServer{
+		RoutingConfig:   &config.MCPServersConfig{Servers: serverConfigs},
+		JWTManager:      jwtManager,
+		Logger:          logger,
+		SessionCache:    cache,
+		Broker:          mockBroker,
+		ToolCallRetries: 2,
+	}
+
+	testCases := []struct {
+		name            string
+		tool            string
+		expectedRetryOn string
+		expectedRetries string
+	}{
+		{
+			name:            "read only tool is retried",
+			tool:            "s_read",
+			expectedRetryOn: retryOnConnectionFailure,
+			expectedRetries: "2",
+		},
+		{
+			name:            "non idempotent tool fails fast",
+			tool:            "s_write",
+			expectedRetries: "0",
+		},
+		{
+			name:            "override marking a read only tool not idempotent fails fast",
+			tool:            "s_charge",
+			expectedRetries: "0",
+		},
+		{
+			name:            "override marks an unannotated tool idempotent",
+			tool:            "s_refresh",
+			expectedRetryOn: retryOnConnectionFailure,
+			expectedRetries: "2",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := &MCPRequest{
+				ID:      ptr.To(0),
+				JSONRPC: "2.0",
+				Method:  "tools/call",
+				Params:  map[string]any{"name": tc.tool},
+				Headers: &corev3.HeaderMap{
+					Headers: []*corev3.HeaderValue{{Key: "mcp-session-id", RawValue: []byte(validToken)}},
+				},
+			}
+			resp := server.RouteMCPRequest(context.Background(), data)
+			require.Len(t, resp, 1)
+			rb, ok := resp[0].Response.(*eppb.ProcessingResponse_RequestBody)
+			require.True(t, ok)
+			headers := map[string]string{}
+			for _, h := range rb.RequestBody.Response.HeaderMutation.SetHeaders {
+				headers[h.Header.Key] = string(h.Header.RawValue)
+			}
+			require.Equal(t, tc.expectedRetries, headers[envoyMaxRetries])
+			require.Equal(t, tc.expectedRetryOn, headers[envoyRetryOnHeader])
+		})
+	}
+}
+
+func TestHandleRequestBody_CallTimeout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cache, err := session.NewCache(context.Background())
+	require.NoError(t, err)
+	jwtManager, err := session.NewJWTManager("test-signing-key", 0, logger, cache)
+	require.NoError(t, err)
+	validToken := jwtManager.Generate()
+	_, err = cache.AddSession(context.Background(), validToken, "slow", "mock-upstream-session-id")
+	require.NoError(t, err)
+	_, err = cache.AddSession(context.Background(), validToken, "fast", "mock-upstream-session-id")
+	require.NoError(t, err)
+
+	serverConfigs := []*config.MCPServer{
+		{Name: "slow", URL: "http://localhost:8080/mcp", ToolPrefix: "slow_", Enabled: true, Hostname: "slow.local", CallTimeout: "2m"},
+		{Name: "fast", URL: "http://localhost:8081/mcp", ToolPrefix: "fast_", Enabled: true, Hostname: "fast.local"},
+	}
+	mockBroker := newMockBroker(serverConfigs, map[string]string{
+		"slow_report": "slow",
+		"slow_hinted": "slow",
+		"slow_batch":  "slow",
+		"fast_lookup": "fast",
+	}).(*mockBrokerImpl)
+	mockBroker.timeouts = map[string]time.Duration{"slow_hinted": 5 * time.Second, "slow_batch": time.Hour}
+	server := &ExtProcServer{
+		RoutingConfig:   &config.MCPServersConfig{Servers: serverConfigs},
+		JWTManager:      jwtManager,
+		Logger:          logger,
+		SessionCache:    cache,
+		Broker:          mockBroker,
+		ToolCallTimeout: 30 * time.Second,
+		RouteTimeout:    5 * time.Minute,
+	}
+
+	testCases := []struct {
+		name            string
+		tool            string
+		expectedTimeout string
+	}{
+		{
+			name:            "tool hint is clamped to the route timeout",
+			tool:            "slow_batch",
+			expectedTimeout: "300000",
+		},
+		{
+			name:            "server call timeout replaces the router default",
+			tool:            "slow_report",
+			expectedTimeout: "120000",
+		},
+		{
+			name:            "tool hint takes precedence over the server call timeout",
+			tool:            "slow_hinted",
+			expectedTimeout: "5000",
+		},
+		{
+			name:            "router default is used without a server call timeout",
+			tool:            "fast_lookup",
+			expectedTimeout: "30000",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := server.RouteMCPRequest(context.Background(), &MCPRequest{
+				ID:      ptr.To(0),
+				JSONRPC: "2.0",
+				Method:  "tools/call",
+				Params:  map[string]any{"name": tc.tool},
+				Headers: &corev3.HeaderMap{
+					Headers: []*corev3.HeaderValue{{Key: "mcp-session-id", RawValue: []byte(validToken)}},
+				},
+			})
+			require.Len(t, resp, 1)
+			rb, ok := resp[0].Response.(*eppb.ProcessingResponse_RequestBody)
+			require.True(t, ok)
+			headers := map[string]string{}
+			for _, h := range rb.RequestBody.Response.HeaderMutation.SetHeaders {
+				headers[h.Header.Key] = string(h.Header.RawValue)
+			}
+			require.Equal(t, tc.expectedTimeout, headers[envoyUpstreamTimeout])
+		})
+	}
+}
+
+func TestHandleRequestBody_UpstreamUnavailable(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cache, err := session.NewCache(context.Background())
+	require.NoError(t, err)
+	jwtManager, err := session.NewJWTManager("test-signing-key", 0, logger, cache)
+	require.NoError(t, err)
+	validToken := jwtManager.Generate()
+
+	serverConfigs := []*config.MCPServer{
+		{
+			Name:              "kept",
+			URL:               "http://localhost:8080/mcp",
+			ToolPrefix:        "k_",
+			Enabled:           true,
+			Hostname:          "kept.mcp.local",
+			UnavailablePolicy: config.UnavailablePolicyKeepTools,
+		},
+		{
+			Name:       "removed",
+			URL:        "http://localhost:8081/mcp",
+			ToolPrefix: "r_",
+			Enabled:    true,
+			Hostname:   "removed.mcp.local",
+		},
+	}
+	for _, s := range serverConfigs {
+		_, err = cache.AddSession(context.Background(), validToken, s.Name, "mock-upstream-session-id")
+		require.NoError(t, err)
+	}
+	mockBroker := newMockBroker(serverConfigs, map[string]string{
+		"k_time": "kept",
+		"r_time": "removed",
+	}).(*mockBrokerImpl)
+	mockBroker.unavailable = map[config.UpstreamMCPID]bool{
+		serverConfigs[0].ID(): true,
+		serverConfigs[1].ID(): true,
+	}
+	server := &ExtProcServer{
+		RoutingConfig: &config.MCPServersConfig{Servers: serverConfigs},
+		JWTManager:    jwtManager,
+		Logger:        logger,
+		SessionCache:  cache,
+		Broker:        mockBroker,
+	}
+	toolCall := func(tool string) *MCPRequest {
+		return &MCPRequest{
+			ID:      ptr.To(7),
+			JSONRPC: "2.0",
+			Method:  "tools/call",
+			Params:  map[string]any{"name": tool},
+			Headers: &corev3.HeaderMap{
+				Headers: []*corev3.HeaderValue{{Key: "mcp-session-id", RawValue: []byte(validToken)}},
+			},
+		}
+	}
+
+	// a server keeping its tools fails the call without routing to the backend
+	resp := server.RouteMCPRequest(context.Background(), toolCall("k_time"))
+	require.Len(t, resp, 1)
+	ir, ok := resp[0].Response.(*eppb.ProcessingResponse_ImmediateResponse)
+	require.True(t, ok)
+	require.Contains(t, string(ir.ImmediateResponse.Body), "upstream unavailable")
+	require.Contains(t, string(ir.ImmediateResponse.Body), `"isError":true`)
+
+	// a server removing its tools is routed as usual
+	resp = server.RouteMCPRequest(context.Background(), toolCall("r_time"))
+	require.Len(t, resp, 1)
+	_, ok = resp[0].Response.(*eppb.ProcessingResponse_RequestBody)
+	require.True(t, ok)
+}
+
 func TestMCPRequest_isNotificationRequest(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -2,6 +2,7 @@ package mcprouter
 
 import (
 	"context"
+	"time"
 
 	eppb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 )
@@ -20,6 +21,10 @@ func (s *ExtProcServer) HandleResponseHeaders(ctx context.Context, responseHeade
 		responseHeaderBuilder.WithMCPSession(gatewaySessionID)
 	}
 
+	if s.ExposeUpstreamLatency && req != nil && req.isToolCall() && !req.routedAt.IsZero() {
+		responseHeaderBuilder.WithUpstreamLatency(time.Since(req.routedAt))
+	}
+
 	// intercept 404 from backend MCP Server as this means the clients mcp-session-id is invalid. We remove the session. The client can re-initialize with the gateway or they could re-invoke the tool as we will then lazily acquire a new session
 	status := getSingleValueHeader(responseHeaders.Headers, ":status")
 
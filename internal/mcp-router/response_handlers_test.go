@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/broker"
 	"github.com/Kuadrant/mcp-gateway/internal/broker/upstream"
@@ -25,6 +27,15 @@ type mockBrokerImpl struct {
 
 	// Map of tool name to server name
 	tool2svr map[string]string
+
+	// Map of tool name to the annotations advertised by the upstream tool
+	annotations map[string]mcp.ToolAnnotation
+
+	// Map of tool name to the timeout hint advertised by the upstream tool
+	timeouts map[string]time.Duration
+
+	// Servers reported as unreachable
+	unavailable map[config.UpstreamMCPID]bool
 }
 
 func TestHandleResponseHeaders_ReturnsGatewaySessionID(t *testing.T) {
@@ -194,6 +205,63 @@ func TestHandleResponseHeaders_404RemovesServerSession(t *testing.T) {
 	require.Empty(t, sessions)
 }
 
+func TestHandleResponseHeaders_UpstreamLatency(t *testing.T) {
+	requestHeaders := &eppb.HttpHeaders{Headers: &corev3.HeaderMap{}}
+	responseHeaders := &eppb.HttpHeaders{
+		Headers: &corev3.HeaderMap{
+			Headers: []*corev3.HeaderValue{{Key: ":status", RawValue: []byte("200")}},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		enabled       bool
+		method        string
+		expectLatency bool
+	}{
+		{name: "enabled tool call", enabled: true, method: "tools/call", expectLatency: true},
+		{name: "disabled tool call", enabled: false, method: "tools/call", expectLatency: false},
+		{name: "enabled other method", enabled: true, method: "tools/list", expectLatency: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache, err := session.NewCache(context.Background())
+			require.NoError(t, err)
+			server := &ExtProcServer{
+				Logger:                slog.New(slog.NewTextHandler(os.Stdout, nil)),
+				SessionCache:          cache,
+				Broker:                newMockBroker(nil, map[string]string{}),
+				ExposeUpstreamLatency: tc.enabled,
+			}
+			mcpReq := &MCPRequest{
+				Method:     tc.method,
+				serverName: "test-server",
+				routedAt:   time.Now().Add(-25 * time.Millisecond),
+			}
+
+			responses, err := server.HandleResponseHeaders(context.Background(), responseHeaders, requestHeaders, mcpReq)
+			require.NoError(t, err)
+			require.Len(t, responses, 1)
+
+			rh := responses[0].Response.(*eppb.ProcessingResponse_ResponseHeaders)
+			var latency string
+			for _, h := range rh.ResponseHeaders.Response.HeaderMutation.SetHeaders {
+				if h.Header.Key == upstreamLatencyHeader {
+					latency = string(h.Header.RawValue)
+				}
+			}
+			if !tc.expectLatency {
+				require.Empty(t, latency)
+				return
+			}
+			ms, err := strconv.Atoi(latency)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, ms, 25)
+		})
+	}
+}
+
 func TestHandleResponseHeaders_404WithoutMCPRequest(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	cache, err := session.NewCache(context.Background())
@@ -409,6 +477,34 @@ func (m *mockBrokerImpl) HandleStatusRequest(_ http.ResponseWriter, _ *http.Requ
 	panic("unimplemented")
 }
 
+// HandleSessionToolsRequest implements broker.MCPBroker.
+func (m *mockBrokerImpl) HandleSessionToolsRequest(_ http.ResponseWriter, _ *http.Request) {
+	panic("unimplemented")
+}
+
+// HandleDebugServersRequest implements broker.MCPBroker.
+func (m *mockBrokerImpl) HandleDebugServersRequest(_ http.ResponseWriter, _ *http.Request) {
+	panic("unimplemented")
+}
+
+// HandleReadyRequest implements broker.MCPBroker.
+func (m *mockBrokerImpl) HandleReadyRequest(_ http.ResponseWriter, _ *http.Request) {
+	panic("unimplemented")
+}
+
+// HandleUpstreamReadinessRequest implements broker.MCPBroker.
+func (m *mockBrokerImpl) HandleUpstreamReadinessRequest(_ http.ResponseWriter, _ *http.Request) {
+	panic("unimplemented")
+}
+
+func (m *mockBrokerImpl) SessionFilters() map[string]http.Header {
+	panic("unimplemented")
+}
+
+func (m *mockBrokerImpl) RestoreSessionFilters(_ map[string]http.Header) {
+	panic("unimplemented")
+}
+
 // MCPServer implements broker.MCPBroker.
 func (m *mockBrokerImpl) MCPServer() *server.MCPServer {
 	panic("unimplemented")
@@ -419,6 +515,16 @@ func (m *mockBrokerImpl) OnConfigChange(_ context.Context, _ *config.MCPServersC
 	panic("unimplemented")
 }
 
+// UpstreamUnavailable implements broker.MCPBroker.
+func (m *mockBrokerImpl) UpstreamUnavailable(serverID config.UpstreamMCPID) bool {
+	return m.unavailable[serverID]
+}
+
+// ToolCallStarted implements broker.MCPBroker.
+func (m *mockBrokerImpl) ToolCallStarted(_ config.UpstreamMCPID) func() {
+	return func() {}
+}
+
 // RegisteredMCPServers implements broker.MCPBroker.
 func (m *mockBrokerImpl) RegisteredMCPServers() map[config.UpstreamMCPID]*upstream.MCPManager {
 	panic("unimplemented")
@@ -430,11 +536,25 @@ func (m *mockBrokerImpl) Shutdown(_ context.Context) error {
 }
 
 // ToolAnnotations implements broker.MCPBroker.
-func (m *mockBrokerImpl) ToolAnnotations(_ config.UpstreamMCPID, _ string) (mcp.ToolAnnotation, bool) {
-	return mcp.ToolAnnotation{}, false
+func (m *mockBrokerImpl) ToolAnnotations(_ config.UpstreamMCPID, tool string) (mcp.ToolAnnotation, bool) {
+	annotations, ok := m.annotations[tool]
+	return annotations, ok
+}
+
+// ToolTimeout implements broker.MCPBroker.
+func (m *mockBrokerImpl) ToolTimeout(_ config.UpstreamMCPID, tool string, fallback time.Duration) time.Duration {
+	if timeout, ok := m.timeouts[tool]; ok {
+		return timeout
+	}
+	return fallback
 }
 
 // ValidateAllServers implements broker.MCPBroker.
 func (m *mockBrokerImpl) ValidateAllServers() broker.StatusResponse {
 	panic("unimplemented")
 }
+
+// ValidateServer implements broker.MCPBroker.
+func (m *mockBrokerImpl) ValidateServer(_ config.UpstreamMCPID) (upstream.ServerValidationStatus, bool) {
+	panic("unimplemented")
+}
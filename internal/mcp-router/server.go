@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/Kuadrant/mcp-gateway/internal/broker"
 	"github.com/Kuadrant/mcp-gateway/internal/config"
@@ -39,6 +40,16 @@ type ExtProcServer struct {
 	SessionCache  SessionCache
 	//TODO this should not be needed
 	Broker broker.MCPBroker
+	// ExposeUpstreamLatency adds the time from routing a tool call to receiving the upstream response headers as a response header
+	ExposeUpstreamLatency bool
+	// ToolCallScheduler if set limits concurrent tool calls per upstream MCP server, sharing slots fairly across sessions
+	ToolCallScheduler *FairScheduler
+	// ToolCallRetries if set retries tool calls to idempotent or read only tools up to this many times after a connection failure
+	ToolCallRetries int
+	// ToolCallTimeout is the default upstream timeout for tool calls when a tool does not advertise a timeout hint. 0 leaves the route timeout in place
+	ToolCallTimeout time.Duration
+	// RouteTimeout if set is the timeout of the gateway route. Tool call timeouts are clamped to it so a timeout hint cannot extend a call past the route timeout
+	RouteTimeout time.Duration
 }
 
 // OnConfigChange is used to register the router for config changes
@@ -53,10 +64,23 @@ func (s *ExtProcServer) Process(stream extProcV3.ExternalProcessor_ProcessServer
 		requestID           string
 		streaming           = false
 		mcpRequest          *MCPRequest
+		releaseToolCall     func()
+		endToolCall         func()
 		ctx                 = stream.Context()
 	)
 	span := trace.SpanFromContext(ctx)
 	defer func() { span.End() }()
+	defer func() {
+		// the tool call slot is held until envoy ends the processing stream of the request, not only until the
+		// upstream responds
+		if releaseToolCall != nil {
+			releaseToolCall()
+		}
+		// the call is in flight for the broker until the stream ends so a draining server is not removed under it
+		if endToolCall != nil {
+			endToolCall()
+		}
+	}()
 	for {
 		req, err := stream.Recv()
 
@@ -157,6 +181,19 @@ func (s *ExtProcServer) Process(stream extProcV3.ExternalProcessor_ProcessServer
 			}
 
 			responses = s.RouteMCPRequest(ctx, mcpRequest)
+			if s.ToolCallScheduler != nil && mcpRequest.isToolCall() && mcpRequest.serverName != "" {
+				release, err := s.ToolCallScheduler.Acquire(ctx, mcpRequest.serverName, mcpRequest.GetSessionID())
+				if err != nil {
+					s.Logger.ErrorContext(ctx, "tool call abandoned waiting for upstream capacity", "server", mcpRequest.serverName, "error", err)
+					recordError(span, err, 503)
+					return err
+				}
+				releaseToolCall = release
+			}
+			if mcpRequest.isToolCall() && mcpRequest.serverID != "" {
+				endToolCall = s.Broker.ToolCallStarted(mcpRequest.serverID)
+			}
+			mcpRequest.routedAt = time.Now()
 			for _, response := range responses {
 				s.Logger.DebugContext(ctx, fmt.Sprintf("Sending MCP body routing instructions to Envoy: %+v", response))
 				if err := stream.Send(response); err != nil {
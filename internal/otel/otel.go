@@ -8,6 +8,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"k8s.io/utils/env"
 )
 
 // SetupOTelSDK initializes the OpenTelemetry SDK with tracing and logs support
@@ -51,3 +52,30 @@ func SetupOTelSDK(ctx context.Context, gitSHA, dirty, version string, logger *sl
 
 	return shutdown, loggerProvider, nil
 }
+
+// ResolveTracesEndpoint returns the endpoint SetupTracing exports to: OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, then
+// endpoint, then OTEL_EXPORTER_OTLP_ENDPOINT. Empty means tracing is disabled
+func ResolveTracesEndpoint(endpoint string) string {
+	config := NewConfig("", "", "")
+	if endpoint != "" {
+		config.Endpoint = endpoint
+	}
+	return config.TracesEndpoint()
+}
+
+// SetupTracing initializes OpenTelemetry tracing only, exporting to the endpoint ResolveTracesEndpoint returns as
+// serviceName. The OTEL_SERVICE_NAME environment variable takes precedence as it does for SetupOTelSDK
+func SetupTracing(ctx context.Context, endpoint, serviceName, gitSHA, dirty, version string) (shutdown func(context.Context) error, err error) {
+	config := NewConfig(gitSHA, dirty, version)
+	if endpoint != "" {
+		config.Endpoint = endpoint
+	}
+	config.ServiceName = env.GetString("OTEL_SERVICE_NAME", serviceName)
+
+	traceProvider, err := NewProvider(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(traceProvider.TracerProvider())
+	return traceProvider.Shutdown, nil
+}
@@ -118,3 +118,41 @@ func (c testCarrier) Keys() []string {
 	}
 	return keys
 }
+
+func TestSetupTracing(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	shutdown, err := SetupTracing(context.Background(), "http://localhost:4318", "mcp-gateway-controller", "abc123", "", "v1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown error: %v", err)
+	}
+
+	if _, err := SetupTracing(context.Background(), "ftp://localhost:4318", "mcp-gateway-controller", "", "", "v1.0.0"); err == nil {
+		t.Error("expected an error for an unsupported endpoint scheme")
+	}
+}
+
+func TestResolveTracesEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	if endpoint := ResolveTracesEndpoint(""); endpoint != "" {
+		t.Errorf("expected tracing to be disabled, got endpoint %q", endpoint)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	if endpoint := ResolveTracesEndpoint(""); endpoint != "http://collector:4318" {
+		t.Errorf("expected the generic endpoint, got %q", endpoint)
+	}
+	if endpoint := ResolveTracesEndpoint("http://flag:4318"); endpoint != "http://flag:4318" {
+		t.Errorf("expected the flag to override the generic endpoint, got %q", endpoint)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4318")
+	if endpoint := ResolveTracesEndpoint("http://flag:4318"); endpoint != "http://traces:4318" {
+		t.Errorf("expected the traces endpoint to take precedence, got %q", endpoint)
+	}
+}
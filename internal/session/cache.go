@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"sync"
+	"time"
 
 	redis "github.com/redis/go-redis/v9"
 )
@@ -11,7 +12,14 @@ import (
 type Cache struct {
 	connectionString string
 	inmemory         *sync.Map
-	extClient        *redis.Client
+	// resumable holds the resumption deadline of each in memory session
+	resumable *sync.Map
+	extClient *redis.Client
+}
+
+// resumableKey is the key marking a gateway session as resumable in the external store
+func resumableKey(key string) string {
+	return "resumable:" + key
 }
 
 // KeyExists checks if a key exists in the cache
@@ -43,15 +51,62 @@ func (c *Cache) GetSession(ctx context.Context, key string) (map[string]string,
 	return c.extClient.HGetAll(ctx, key).Result()
 }
 
-// DeleteSessions deletes sessions from the cache
+// DeleteSessions deletes sessions from the cache. Deleted sessions can no longer be resumed. In memory, the
+// resumption deadlines of other sessions that have passed are pruned too, as a client that goes away without closing
+// its session is never deleted
 func (c *Cache) DeleteSessions(ctx context.Context, key ...string) error {
 	if c.inmemory != nil {
 		for _, k := range key {
 			c.inmemory.Delete(k)
+			c.resumable.Delete(k)
 		}
+		c.pruneResumable(time.Now())
 		return nil
 	}
-	return c.extClient.Del(ctx, key...).Err()
+	keys := make([]string, 0, len(key)*2)
+	for _, k := range key {
+		keys = append(keys, k, resumableKey(k))
+	}
+	return c.extClient.Del(ctx, keys...).Err()
+}
+
+// MarkResumable allows the session under key to be resumed by a reconnecting client until window has passed
+func (c *Cache) MarkResumable(ctx context.Context, key string, window time.Duration) error {
+	if c.inmemory != nil {
+		c.resumable.Store(key, time.Now().Add(window))
+		return nil
+	}
+	return c.extClient.Set(ctx, resumableKey(key), 1, window).Err()
+}
+
+// pruneResumable removes the in memory resumption deadlines that have passed
+func (c *Cache) pruneResumable(now time.Time) {
+	c.resumable.Range(func(key, value any) bool {
+		if now.After(value.(time.Time)) {
+			c.resumable.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+// Resumable checks if the session under key was marked resumable and its window has not passed
+func (c *Cache) Resumable(ctx context.Context, key string) (bool, error) {
+	if c.inmemory != nil {
+		val, ok := c.resumable.Load(key)
+		if !ok {
+			return false, nil
+		}
+		if time.Now().After(val.(time.Time)) {
+			c.resumable.Delete(key)
+			return false, nil
+		}
+		return true, nil
+	}
+	count, err := c.extClient.Exists(ctx, resumableKey(key)).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
 }
 
 // AddSession will add a session under the key. If the key exists it will append that session
@@ -112,6 +167,7 @@ func NewCache(ctx context.Context, opts ...func(*Cache)) (*Cache, error) {
 		return c, c.extClient.Ping(ctx).Err()
 	}
 	c.inmemory = &sync.Map{}
+	c.resumable = &sync.Map{}
 	return c, nil
 }
 
@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -103,6 +104,27 @@ func TestInMemoryCache_DeleteSessions(t *testing.T) {
 	require.True(t, exists)
 }
 
+func TestInMemoryCache_DeleteSessions_PrunesResumable(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewCache(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.MarkResumable(ctx, "closed", time.Minute))
+	require.NoError(t, cache.MarkResumable(ctx, "active", time.Minute))
+	// a client that went away without closing its session
+	cache.resumable.Store("abandoned", time.Now().Add(-time.Second))
+
+	require.NoError(t, cache.DeleteSessions(ctx, "closed"))
+
+	_, ok := cache.resumable.Load("closed")
+	require.False(t, ok)
+	_, ok = cache.resumable.Load("abandoned")
+	require.False(t, ok, "passed resumption deadlines are pruned when a session is closed")
+	resumable, err := cache.Resumable(ctx, "active")
+	require.NoError(t, err)
+	require.True(t, resumable)
+}
+
 func TestInMemoryCache_UpdateExistingSession(t *testing.T) {
 	ctx := context.Background()
 	cache, err := NewCache(ctx)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
@@ -23,7 +24,14 @@ type Deleter interface {
 	DeleteSessions(ctx context.Context, key ...string) error
 }
 
+// Resumer interface for tracking which sessions a reconnecting client may resume
+type Resumer interface {
+	MarkResumable(ctx context.Context, key string, window time.Duration) error
+	Resumable(ctx context.Context, key string) (bool, error)
+}
+
 var _ server.SessionIdManager = &JWTManager{}
+var _ server.SessionIdManagerResolver = &JWTManager{}
 
 // Claims represents the claims in a session JWT
 type Claims struct {
@@ -36,6 +44,9 @@ type JWTManager struct {
 	duration       time.Duration
 	logger         *slog.Logger
 	sessionDeleter Deleter
+	// resumer and resumeWindow allow a client re-initializing with a recently active session id to keep that session
+	resumer      Resumer
+	resumeWindow time.Duration
 }
 
 // NewJWTManager creates a new JWT manager with the provided signing key
@@ -56,6 +67,75 @@ func NewJWTManager(signingKey string, sessionLength int64, logger *slog.Logger,
 	}, nil
 }
 
+// EnableResumption lets a client that re-initializes presenting a session id keep that session if the session
+// was active within window. A window of 0 disables resumption
+func (m *JWTManager) EnableResumption(resumer Resumer, window time.Duration) {
+	m.resumer = resumer
+	m.resumeWindow = window
+}
+
+func (m *JWTManager) resumptionEnabled() bool {
+	return m.resumer != nil && m.resumeWindow > 0
+}
+
+// markActive extends the window in which the session can be resumed
+func (m *JWTManager) markActive(ctx context.Context, sessionID string) {
+	if !m.resumptionEnabled() || sessionID == "" {
+		return
+	}
+	if err := m.resumer.MarkResumable(ctx, sessionID, m.resumeWindow); err != nil {
+		m.logger.Error("failed to mark session resumable", "error", err)
+	}
+}
+
+// ResolveSessionIdManager fulfils the SessionIdManagerResolver interface. When resumption is enabled and the request
+// presents a session id, the returned manager hands that id back on initialize if it is valid and still resumable
+func (m *JWTManager) ResolveSessionIdManager(r *http.Request) server.SessionIdManager {
+	if !m.resumptionEnabled() {
+		return m
+	}
+	sessionID := r.Header.Get(server.HeaderKeySessionID)
+	if sessionID == "" {
+		return m
+	}
+	return &resumableSession{JWTManager: m, ctx: r.Context(), sessionID: sessionID}
+}
+
+// resumableSession is a session id manager for a request presenting an existing session id. Generate is only
+// called on initialize so the resumption checks are deferred until then
+type resumableSession struct {
+	*JWTManager
+	ctx       context.Context
+	sessionID string
+}
+
+// Generate returns the presented session id if it can be resumed otherwise a new session id
+func (r *resumableSession) Generate() string {
+	if invalid, err := r.validate(r.sessionID); invalid || err != nil {
+		return r.generate(r.ctx)
+	}
+	resumable, err := r.resumer.Resumable(r.ctx, r.sessionID)
+	if err != nil {
+		r.logger.Error("failed to check if session is resumable", "error", err)
+	}
+	if !resumable {
+		return r.generate(r.ctx)
+	}
+	r.logger.Debug("resuming session in jwt session manager")
+	r.markActive(r.ctx, r.sessionID)
+	return r.sessionID
+}
+
+// Validate validates the session id and extends its resumption window within the context of the request
+func (r *resumableSession) Validate(tokenValue string) (bool, error) {
+	return r.validateAndMarkActive(r.ctx, tokenValue)
+}
+
+// Terminate removes the associated sessions from cache within the context of the request
+func (r *resumableSession) Terminate(sessionID string) (bool, error) {
+	return r.terminate(r.ctx, sessionID)
+}
+
 // generateSessionJWT creates a JWT token
 func (m *JWTManager) generateSessionJWT() (string, error) {
 	now := time.Now()
@@ -76,17 +156,35 @@ func (m *JWTManager) generateSessionJWT() (string, error) {
 
 // Generate returns a session id JWT to fullfil SessionIdManager interface
 func (m *JWTManager) Generate() string {
+	return m.generate(context.Background())
+}
+
+func (m *JWTManager) generate(ctx context.Context) string {
 	m.logger.Debug("generating session id in jwt session manager")
 	sessID, err := m.generateSessionJWT()
 	if err != nil {
 		m.logger.Error("failed to generate session id", "error", err)
 		return ""
 	}
+	m.markActive(ctx, sessID)
 	return sessID
 }
 
 // Validate validates a JWT token and fulfils SessionIdManager interface. returns IsInValid as a bool
 func (m *JWTManager) Validate(tokenValue string) (bool, error) {
+	return m.validateAndMarkActive(context.Background(), tokenValue)
+}
+
+// validateAndMarkActive validates the session id and, if valid, extends its resumption window
+func (m *JWTManager) validateAndMarkActive(ctx context.Context, tokenValue string) (bool, error) {
+	invalid, err := m.validate(tokenValue)
+	if !invalid && err == nil {
+		m.markActive(ctx, tokenValue)
+	}
+	return invalid, err
+}
+
+func (m *JWTManager) validate(tokenValue string) (bool, error) {
 	m.logger.Debug("validating JWT session")
 	token, err := jwt.ParseWithClaims(tokenValue, &Claims{}, func(t *jwt.Token) (interface{}, error) {
 		// verify signing method
@@ -128,10 +226,13 @@ func (m *JWTManager) GetExpiresIn(tokenValue string) (time.Time, error) {
 
 // Terminate part of the SessionIDManager interface. Will remove the associated sessions from cache
 func (m *JWTManager) Terminate(sessionID string) (isNotAllowed bool, err error) {
+	return m.terminate(context.Background(), sessionID)
+}
+
+func (m *JWTManager) terminate(ctx context.Context, sessionID string) (isNotAllowed bool, err error) {
 	m.logger.Info("terminate session id in jwt session manager", "sesssion", sessionID)
 	if m.sessionDeleter != nil {
 		// TODO(craig) this method will be invoked by the MCPBroker so we can probably do the cache deletion there rather than in this manager
-		ctx := context.TODO()
 		if err := m.sessionDeleter.DeleteSessions(ctx, sessionID); err != nil {
 			return false, fmt.Errorf("error clearing out associated sessions : %w", err)
 		}
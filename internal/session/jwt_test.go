@@ -1,12 +1,18 @@
 package session
 
 import (
+	"context"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 func testLogger() *slog.Logger {
@@ -198,3 +204,90 @@ func TestTerminate(t *testing.T) {
 		}
 	})
 }
+
+func TestSessionResumption(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewCache(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	manager, _ := NewJWTManager("test-key", 0, testLogger(), cache)
+	manager.EnableResumption(cache, 100*time.Millisecond)
+
+	srv := httptest.NewServer(server.NewStreamableHTTPServer(
+		server.NewMCPServer("test", "0.0.1"),
+		server.WithSessionIdManagerResolver(manager),
+	))
+	defer srv.Close()
+
+	initialize := func(sessionID string) string {
+		body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(server.HeaderKeySessionID, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.StatusCode)
+		}
+		return resp.Header.Get(server.HeaderKeySessionID)
+	}
+
+	sessionID := initialize("")
+	if sessionID == "" {
+		t.Fatal("expected a session id")
+	}
+	// the router records the backend session for the gateway session
+	if _, err := cache.AddSession(ctx, sessionID, "weather", "backend-session"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("reconnect within the window resumes the session", func(t *testing.T) {
+		resumed := initialize(sessionID)
+		if resumed != sessionID {
+			t.Fatalf("expected session %s to resume, got %s", sessionID, resumed)
+		}
+		backends, err := cache.GetSession(ctx, resumed)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if backends["weather"] != "backend-session" {
+			t.Errorf("expected backend session to be kept, got %v", backends)
+		}
+	})
+
+	t.Run("reconnect after the window gets a new session", func(t *testing.T) {
+		time.Sleep(150 * time.Millisecond)
+		if fresh := initialize(sessionID); fresh == sessionID || fresh == "" {
+			t.Fatalf("expected a new session id, got %q", fresh)
+		}
+	})
+
+	t.Run("terminated session is not resumed", func(t *testing.T) {
+		id := initialize("")
+		if _, err := manager.Terminate(id); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fresh := initialize(id); fresh == id {
+			t.Fatal("expected a terminated session not to resume")
+		}
+	})
+
+	t.Run("disabled resumption always generates a new session", func(t *testing.T) {
+		disabled, _ := NewJWTManager("test-key", 0, testLogger(), cache)
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.Header.Set(server.HeaderKeySessionID, sessionID)
+		if disabled.ResolveSessionIdManager(req) != disabled {
+			t.Error("expected the manager itself when resumption is disabled")
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package session
+
+import (
+	"errors"
+	"maps"
+	"time"
+)
+
+// ErrSnapshotUnsupported is returned when snapshotting a cache backed by an external store. The store is already
+// shared with the broker that replaces this one so there is nothing to hand off
+var ErrSnapshotUnsupported = errors.New("snapshot is only supported for the in memory session cache")
+
+// Snapshot is a point in time copy of the in memory session cache
+type Snapshot struct {
+	// Sessions maps each gateway session to the upstream MCP server sessions keyed by server id
+	Sessions map[string]map[string]string `json:"sessions"`
+	// Resumable holds the resumption deadline of each gateway session marked resumable
+	Resumable map[string]time.Time `json:"resumable,omitempty"`
+}
+
+// Snapshot copies the in memory sessions so they can be restored into another cache
+func (c *Cache) Snapshot() (Snapshot, error) {
+	if c.inmemory == nil {
+		return Snapshot{}, ErrSnapshotUnsupported
+	}
+	snapshot := Snapshot{Sessions: map[string]map[string]string{}, Resumable: map[string]time.Time{}}
+	c.inmemory.Range(func(key, value any) bool {
+		snapshot.Sessions[key.(string)] = maps.Clone(value.(map[string]string))
+		return true
+	})
+	c.resumable.Range(func(key, value any) bool {
+		snapshot.Resumable[key.(string)] = value.(time.Time)
+		return true
+	})
+	return snapshot, nil
+}
+
+// Restore adds the sessions from a snapshot to the in memory cache. Resumption windows that have passed are dropped
+func (c *Cache) Restore(snapshot Snapshot) error {
+	if c.inmemory == nil {
+		return ErrSnapshotUnsupported
+	}
+	for key, session := range snapshot.Sessions {
+		c.inmemory.Store(key, maps.Clone(session))
+	}
+	now := time.Now()
+	for key, deadline := range snapshot.Resumable {
+		if deadline.After(now) {
+			c.resumable.Store(key, deadline)
+		}
+	}
+	return nil
+}
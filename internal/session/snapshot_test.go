@@ -0,0 +1,54 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryCache_SnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewCache(ctx)
+	require.NoError(t, err)
+
+	_, err = cache.AddSession(ctx, "gateway-session-1", "server1", "upstream-session-1")
+	require.NoError(t, err)
+	_, err = cache.AddSession(ctx, "gateway-session-1", "server2", "upstream-session-2")
+	require.NoError(t, err)
+	require.NoError(t, cache.MarkResumable(ctx, "gateway-session-1", time.Minute))
+
+	snapshot, err := cache.Snapshot()
+	require.NoError(t, err)
+	// a resumption window that passes before the restore is dropped
+	snapshot.Resumable["gateway-session-2"] = time.Now().Add(-time.Second)
+
+	restored, err := NewCache(ctx)
+	require.NoError(t, err)
+	require.NoError(t, restored.Restore(snapshot))
+
+	sessions, err := restored.GetSession(ctx, "gateway-session-1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"server1": "upstream-session-1", "server2": "upstream-session-2"}, sessions)
+	resumable, err := restored.Resumable(ctx, "gateway-session-1")
+	require.NoError(t, err)
+	require.True(t, resumable)
+	resumable, err = restored.Resumable(ctx, "gateway-session-2")
+	require.NoError(t, err)
+	require.False(t, resumable)
+
+	// changes after the snapshot do not leak into the restored cache
+	_, err = cache.AddSession(ctx, "gateway-session-1", "server3", "upstream-session-3")
+	require.NoError(t, err)
+	sessions, err = restored.GetSession(ctx, "gateway-session-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+}
+
+func TestExternalCache_SnapshotUnsupported(t *testing.T) {
+	cache := &Cache{}
+	_, err := cache.Snapshot()
+	require.ErrorIs(t, err, ErrSnapshotUnsupported)
+	require.ErrorIs(t, cache.Restore(Snapshot{}), ErrSnapshotUnsupported)
+}
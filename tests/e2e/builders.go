@@ -25,25 +25,26 @@ import (
 
 // TestResourcesBuilder is a unified builder for creating test resources
 type TestResourcesBuilder struct {
-	k8sClient        client.Client
-	testName         string
-	namespace        string
-	hostname         string
-	serviceName      string
-	port             int32
-	toolPrefix       string
-	path             string
-	credential       *corev1.Secret
-	credentialKey    string
-	httpRoute        *gatewayapiv1.HTTPRoute
-	mcpServer        *mcpv1alpha1.MCPServerRegistration
-	serviceEntry     *istionetv1beta1.ServiceEntry
-	destinationRule  *istionetv1beta1.DestinationRule
-	isExternal       bool
-	gatewayName      string
-	gatewayNamespace string
-	backendNamespace string
-	referenceGrant   *gatewayv1beta1.ReferenceGrant
+	k8sClient         client.Client
+	testName          string
+	namespace         string
+	hostname          string
+	serviceName       string
+	port              int32
+	toolPrefix        string
+	path              string
+	credential        *corev1.Secret
+	credentialKey     string
+	httpRoute         *gatewayapiv1.HTTPRoute
+	mcpServer         *mcpv1alpha1.MCPServerRegistration
+	serviceEntry      *istionetv1beta1.ServiceEntry
+	destinationRule   *istionetv1beta1.DestinationRule
+	isExternal        bool
+	gatewayName       string
+	gatewayNamespace  string
+	backendNamespace  string
+	referenceGrant    *gatewayv1beta1.ReferenceGrant
+	unavailablePolicy mcpv1alpha1.UnavailablePolicy
 }
 
 // NewTestResources creates a new TestResourcesBuilder with defaults for internal services
@@ -137,6 +138,12 @@ func (b *TestResourcesBuilder) WithCredential(secret *corev1.Secret, key string)
 	return b
 }
 
+// WithUnavailablePolicy sets what happens to the tools when the backend is unreachable
+func (b *TestResourcesBuilder) WithUnavailablePolicy(policy mcpv1alpha1.UnavailablePolicy) *TestResourcesBuilder {
+	b.unavailablePolicy = policy
+	return b
+}
+
 // Build constructs all the resources based on configuration. Must be called before GetObjects() or Register().
 func (b *TestResourcesBuilder) Build() *TestResourcesBuilder {
 	routeName := UniqueName("e2e-route-" + b.testName)
@@ -155,8 +162,9 @@ func (b *TestResourcesBuilder) Build() *TestResourcesBuilder {
 			Labels:    map[string]string{"e2e": "test", "test": b.testName},
 		},
 		Spec: mcpv1alpha1.MCPServerRegistrationSpec{
-			ToolPrefix: b.toolPrefix,
-			Path:       b.path,
+			ToolPrefix:        b.toolPrefix,
+			Path:              b.path,
+			UnavailablePolicy: b.unavailablePolicy,
 			TargetRef: mcpv1alpha1.TargetReference{
 				Group: "gateway.networking.k8s.io",
 				Kind:  "HTTPRoute",
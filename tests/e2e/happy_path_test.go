@@ -7,17 +7,24 @@ import (
 	"fmt"
 	"strings"
 
+	mcpclient "github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcpv1alpha1 "github.com/Kuadrant/mcp-gateway/api/v1alpha1"
 )
 
 // these can be used across many tests
 var sharedMCPTestServer1 = "mcp-test-server1"
 var sharedMCPTestServer2 = "mcp-test-server2"
 
-// this should only be used by one test as the tests run in parallel.
+// this should only be used by one parallel test as the tests run in parallel. Other tests using it must be Serial.
 var scaledMCPTestServer = "mcp-test-server3"
 
 var _ = Describe("MCP Gateway Registration Happy Path", func() {
@@ -250,8 +257,112 @@ var _ = Describe("MCP Gateway Registration Happy Path", func() {
 		}
 	})
 
-	It("[Full] should deploy redis and scale up the broker and see sessions shared", func() {
-		Skip("not implemented")
+	It("[Full] should deploy redis and scale up the broker and see sessions shared", Serial, func() {
+		redisManifests := []string{"../../config/mcp-system/redis-deployment.yaml", "../../config/mcp-system/redis-service.yaml"}
+		By("Deploying redis")
+		for _, manifest := range redisManifests {
+			Expect(ApplyManifest(manifest)).To(Succeed())
+		}
+		DeferCleanup(func() {
+			for _, manifest := range redisManifests {
+				_ = DeleteManifest(manifest)
+			}
+		})
+		Eventually(func(g Gomega) {
+			g.Expect(WaitForDeploymentReady(SystemNamespace, "redis", 1)).To(Succeed())
+		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
+
+		By("Creating the session store secret")
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "redis-session-store",
+				Namespace: SystemNamespace,
+				Labels:    map[string]string{"e2e": "test"},
+			},
+			StringData: map[string]string{
+				"connectionString": fmt.Sprintf("redis://redis.%s.svc.cluster.local:6379", SystemNamespace),
+			},
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+		testResources = append(testResources, secret)
+
+		By("Configuring the session store and scaling up the broker")
+		extKey := client.ObjectKey{Name: MCPExtensionName, Namespace: SystemNamespace}
+		Eventually(func(g Gomega) {
+			ext := &mcpv1alpha1.MCPGatewayExtension{}
+			g.Expect(k8sClient.Get(ctx, extKey, ext)).To(Succeed())
+			ext.Spec.SessionStore = &mcpv1alpha1.SessionStore{Type: mcpv1alpha1.SessionStoreRedis, SecretName: secret.Name}
+			ext.Spec.Replicas = ptr.To(int32(2))
+			g.Expect(k8sClient.Update(ctx, ext)).To(Succeed())
+		}, TestTimeoutMedium, TestRetryInterval).To(Succeed())
+		DeferCleanup(func() {
+			Eventually(func(g Gomega) {
+				ext := &mcpv1alpha1.MCPGatewayExtension{}
+				g.Expect(k8sClient.Get(ctx, extKey, ext)).To(Succeed())
+				ext.Spec.SessionStore = nil
+				ext.Spec.Replicas = ptr.To(int32(1))
+				g.Expect(k8sClient.Update(ctx, ext)).To(Succeed())
+			}, TestTimeoutMedium, TestRetryInterval).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(VerifyMCPGatewayExtensionReady(ctx, k8sClient, MCPExtensionName, SystemNamespace)).To(Succeed())
+			}, TestTimeoutLong, TestRetryInterval).To(Succeed())
+		})
+
+		Eventually(func(g Gomega) {
+			deployment := &appsv1.Deployment{}
+			g.Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "mcp-gateway", Namespace: SystemNamespace}, deployment)).To(Succeed())
+			g.Expect(deployment.Status.ObservedGeneration).To(Equal(deployment.Generation))
+			g.Expect(deployment.Status.UpdatedReplicas).To(BeNumerically("==", 2))
+			g.Expect(deployment.Status.ReadyReplicas).To(BeNumerically("==", 2))
+			g.Expect(deployment.Status.Replicas).To(BeNumerically("==", 2))
+			g.Expect(VerifyMCPGatewayExtensionReady(ctx, k8sClient, MCPExtensionName, SystemNamespace)).To(Succeed())
+		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
+
+		By("Registering an MCP server")
+		registration := NewMCPServerResourcesWithDefaults("shared-sessions", k8sClient).Build()
+		testResources = append(testResources, registration.GetObjects()...)
+		registeredServer := registration.Register(ctx)
+		Eventually(func(g Gomega) {
+			g.Expect(VerifyMCPServerRegistrationReady(ctx, k8sClient, registeredServer.Name, registeredServer.Namespace)).To(BeNil())
+		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
+
+		By("Creating a client once both brokers serve the tools")
+		var mcpClient *mcpclient.Client
+		Eventually(func(g Gomega) {
+			var err error
+			mcpClient, err = NewMCPGatewayClient(ctx, gatewayURL)
+			g.Expect(err).NotTo(HaveOccurred())
+			// list several times so requests are spread over both broker replicas
+			for range 4 {
+				toolsList, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(verifyMCPServerRegistrationToolsPresent(registeredServer.Spec.ToolPrefix, toolsList)).To(BeTrueBecause("%s should exist", registeredServer.Spec.ToolPrefix))
+			}
+		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
+		defer mcpClient.Close()
+
+		By("Invoking a tool repeatedly and seeing the same backend session from every broker replica")
+		toolName := fmt.Sprintf("%s%s", registeredServer.Spec.ToolPrefix, "headers")
+		backendSession := ""
+		for range 10 {
+			res, err := mcpClient.CallTool(ctx, mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolName},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			for _, cont := range res.Content {
+				textContent, ok := cont.(mcp.TextContent)
+				Expect(ok).To(BeTrue())
+				if !strings.HasPrefix(textContent.Text, "Mcp-Session-Id") {
+					continue
+				}
+				if backendSession == "" {
+					backendSession = textContent.Text
+				}
+				Expect(textContent.Text).To(Equal(backendSession), "all broker replicas should share the backend session")
+			}
+		}
+		Expect(backendSession).NotTo(BeEmpty())
 	})
 
 	It("[Happy] should assign unique mcp-session-ids to concurrent clients and new session on reconnect", func() {
@@ -337,6 +448,30 @@ var _ = Describe("MCP Gateway Registration Happy Path", func() {
 			g.Expect(filteredTools.Tools[0].Name).To(Equal(allowedTool))
 		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
 
+		By("Adding a wildcard entry to the MCPVirtualServer")
+		wildcardTool := fmt.Sprintf("%s%s", registeredServer.Spec.ToolPrefix, "s*")
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(virtualServer), virtualServer)).To(Succeed())
+			virtualServer.Spec.Tools = []string{allowedTool, wildcardTool}
+			g.Expect(k8sClient.Update(ctx, virtualServer)).To(Succeed())
+		}, TestTimeoutMedium, TestRetryInterval).To(Succeed())
+
+		By("Verifying the tools matching the wildcard entry are returned")
+		Eventually(func(g Gomega) {
+			filteredTools, err := virtualServerClient.ListTools(ctx, mcp.ListToolsRequest{})
+			g.Expect(err).Error().NotTo(HaveOccurred())
+			g.Expect(filteredTools).NotTo(BeNil())
+			var names []string
+			for _, tool := range filteredTools.Tools {
+				names = append(names, tool.Name)
+			}
+			g.Expect(names).To(ConsistOf(
+				allowedTool,
+				registeredServer.Spec.ToolPrefix+"slow",
+				registeredServer.Spec.ToolPrefix+"set_time",
+			))
+		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
+
 		By("Verifying the original client without header still sees all tools")
 		allToolsAgain, err := mcpGatewayClient.ListTools(ctx, mcp.ListToolsRequest{})
 		Expect(err).NotTo(HaveOccurred())
@@ -497,6 +632,9 @@ var _ = Describe("MCP Gateway Registration Happy Path", func() {
 			g.Expect(err).NotTo(HaveOccurred())
 		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
 
+		By("Verifying the MCPServerRegistration is still accepted as its config was written")
+		Expect(VerifyMCPServerRegistrationAccepted(ctx, k8sClient, registeredServer.Name, registeredServer.Namespace)).To(Succeed())
+
 		By("Scaling up the MCP server3 deployment to 1")
 		Expect(ScaleDeployment(TestServerNameSpace, scaledMCPTestServer, 1)).To(Succeed())
 
@@ -575,6 +713,62 @@ var _ = Describe("MCP Gateway Registration Happy Path", func() {
 		Expect(err).NotTo(HaveOccurred(), "tool calls should work once the server is back and ready")
 	})
 
+	It("[Full] should apply the unavailable policy of each MCPServerRegistration when its backend scales to zero", Serial, func() {
+		By("Waiting for the MCP server3 deployment to be ready")
+		Eventually(func(g Gomega) {
+			g.Expect(WaitForDeploymentReady(TestServerNameSpace, scaledMCPTestServer, 1)).To(Succeed())
+		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
+
+		By("Registering an MCPServerRegistration for server3 with each unavailable policy")
+		removeRegistration := NewMCPServerResourcesWithDefaults("unavailable-remove", k8sClient).
+			WithBackendTarget(scaledMCPTestServer, 9090).
+			WithUnavailablePolicy(mcpv1alpha1.UnavailablePolicyRemoveTools).Build()
+		testResources = append(testResources, removeRegistration.GetObjects()...)
+		removeServer := removeRegistration.Register(ctx)
+		keepRegistration := NewMCPServerResourcesWithDefaults("unavailable-keep", k8sClient).
+			WithBackendTarget(scaledMCPTestServer, 9090).
+			WithUnavailablePolicy(mcpv1alpha1.UnavailablePolicyKeepTools).Build()
+		testResources = append(testResources, keepRegistration.GetObjects()...)
+		keepServer := keepRegistration.Register(ctx)
+
+		By("Verifying the tools of both registrations are present")
+		Eventually(func(g Gomega) {
+			toolsList, err := mcpGatewayClient.ListTools(ctx, mcp.ListToolsRequest{})
+			g.Expect(err).Error().NotTo(HaveOccurred())
+			g.Expect(toolsList).NotTo(BeNil())
+			g.Expect(verifyMCPServerRegistrationToolsPresent(removeServer.Spec.ToolPrefix, toolsList)).To(BeTrueBecause("%s should exist", removeServer.Spec.ToolPrefix))
+			g.Expect(verifyMCPServerRegistrationToolsPresent(keepServer.Spec.ToolPrefix, toolsList)).To(BeTrueBecause("%s should exist", keepServer.Spec.ToolPrefix))
+		}, TestTimeoutConfigSync, TestRetryInterval).To(Succeed())
+
+		By("Scaling down the MCP server3 deployment to 0")
+		Expect(ScaleDeployment(TestServerNameSpace, scaledMCPTestServer, 0)).To(Succeed())
+
+		By("Verifying only the RemoveTools registration has its tools removed")
+		Eventually(func(g Gomega) {
+			toolsList, err := mcpGatewayClient.ListTools(ctx, mcp.ListToolsRequest{})
+			g.Expect(err).Error().NotTo(HaveOccurred())
+			g.Expect(toolsList).NotTo(BeNil())
+			g.Expect(verifyMCPServerRegistrationToolsPresent(removeServer.Spec.ToolPrefix, toolsList)).To(BeFalseBecause("%s should be removed when server unavailable", removeServer.Spec.ToolPrefix))
+			g.Expect(verifyMCPServerRegistrationToolsPresent(keepServer.Spec.ToolPrefix, toolsList)).To(BeTrueBecause("%s should be kept when server unavailable", keepServer.Spec.ToolPrefix))
+		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
+
+		By("Verifying a tool call to the KeepTools registration fails with upstream unavailable")
+		Eventually(func(g Gomega) {
+			res, err := mcpGatewayClient.CallTool(ctx, mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: fmt.Sprintf("%s%s", keepServer.Spec.ToolPrefix, "time")},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(res.IsError).To(BeTrue())
+			g.Expect(res.Content).NotTo(BeEmpty())
+			text, ok := mcp.AsTextContent(res.Content[0])
+			g.Expect(ok).To(BeTrue())
+			g.Expect(text.Text).To(ContainSubstring("upstream unavailable"))
+		}, TestTimeoutMedium, TestRetryInterval).To(Succeed())
+
+		By("Scaling the MCP server deployment back up")
+		Expect(ScaleDeployment(TestServerNameSpace, scaledMCPTestServer, 1)).To(Succeed())
+	})
+
 	It("[Happy] should filter tools based on x-authorized-tools JWT header", func() {
 		if !IsTrustedHeadersEnabled() {
 			Skip("trusted headers public key not configured - skipping x-authorized-tools test")
@@ -643,6 +837,47 @@ var _ = Describe("MCP Gateway Registration Happy Path", func() {
 		}, TestTimeoutMedium, TestRetryInterval).To(Succeed())
 	})
 
+	It("[Happy] should remove and restore tools when an MCPServerRegistration is disabled and enabled", func() {
+		By("Creating and registering an MCP server")
+		registration := NewMCPServerResourcesWithDefaults("disable-server", k8sClient).WithToolPrefix("disabled_").Build()
+		testResources = append(testResources, registration.GetObjects()...)
+		registeredServer := registration.Register(ctx)
+
+		By("Verifying the server tools are present")
+		Eventually(func(g Gomega) {
+			g.Expect(VerifyMCPServerRegistrationReady(ctx, k8sClient, registeredServer.Name, registeredServer.Namespace)).To(BeNil())
+			toolsList, err := mcpGatewayClient.ListTools(ctx, mcp.ListToolsRequest{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(verifyMCPServerRegistrationToolsPresent(registeredServer.Spec.ToolPrefix, toolsList)).To(BeTrueBecause("%s tools should exist", registeredServer.Spec.ToolPrefix))
+		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
+
+		By("Disabling the MCPServerRegistration")
+		patch := client.MergeFrom(registeredServer.DeepCopy())
+		registeredServer.Spec.Enabled = ptr.To(false)
+		Expect(k8sClient.Patch(ctx, registeredServer, patch)).To(Succeed())
+
+		By("Verifying the server tools are removed and the status reports it is disabled")
+		Eventually(func(g Gomega) {
+			g.Expect(VerifyMCPServerRegistrationNotReadyWithReason(ctx, k8sClient, registeredServer.Name, registeredServer.Namespace, "server is disabled")).To(BeNil())
+			toolsList, err := mcpGatewayClient.ListTools(ctx, mcp.ListToolsRequest{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(verifyMCPServerRegistrationToolsPresent(registeredServer.Spec.ToolPrefix, toolsList)).To(BeFalseBecause("%s tools should be removed", registeredServer.Spec.ToolPrefix))
+		}, TestTimeoutConfigSync, TestRetryInterval).To(Succeed())
+
+		By("Enabling the MCPServerRegistration again")
+		patch = client.MergeFrom(registeredServer.DeepCopy())
+		registeredServer.Spec.Enabled = ptr.To(true)
+		Expect(k8sClient.Patch(ctx, registeredServer, patch)).To(Succeed())
+
+		By("Verifying the server tools are restored")
+		Eventually(func(g Gomega) {
+			g.Expect(VerifyMCPServerRegistrationReady(ctx, k8sClient, registeredServer.Name, registeredServer.Namespace)).To(BeNil())
+			toolsList, err := mcpGatewayClient.ListTools(ctx, mcp.ListToolsRequest{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(verifyMCPServerRegistrationToolsPresent(registeredServer.Spec.ToolPrefix, toolsList)).To(BeTrueBecause("%s tools should exist", registeredServer.Spec.ToolPrefix))
+		}, TestTimeoutConfigSync, TestRetryInterval).To(Succeed())
+	})
+
 	It("[Happy] should report invalid protocol version in MCPServerRegistration status", func() {
 		By("Creating an MCPServerRegistration pointing to the broken server with wrong protocol version")
 		// The broken server is already deployed with --failure-mode=protocol
@@ -658,11 +893,19 @@ var _ = Describe("MCP Gateway Registration Happy Path", func() {
 			g.Expect(err).NotTo(HaveOccurred())
 		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
 
+		By("Verifying the MCPServerRegistration is still accepted as its config was written")
+		Expect(VerifyMCPServerRegistrationAccepted(ctx, k8sClient, registeredServer.Name, registeredServer.Namespace)).To(Succeed())
+
 		By("Verifying the status message contains details about the protocol issue")
 		msg, err := GetMCPServerRegistrationStatusMessage(ctx, k8sClient, registeredServer.Name, registeredServer.Namespace)
 		Expect(err).NotTo(HaveOccurred())
 		GinkgoWriter.Println("MCPServerRegistration status message:", msg)
 		Expect(msg).To(ContainSubstring("unsupported protocol version"))
+
+		By("Verifying the MCPServerRegistration status reports the version the server advertised")
+		protocolVersion, err := GetMCPServerRegistrationProtocolVersion(ctx, k8sClient, registeredServer.Name, registeredServer.Namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(protocolVersion).To(Equal("2021-11-05"))
 	})
 
 	It("[Happy] should report tool conflicts in MCPServerRegistration status when same prefix is used", func() {
@@ -787,12 +1030,16 @@ var _ = Describe("MCP Gateway Registration Happy Path", func() {
 		testResources = append(testResources, registration2.GetObjects()...)
 		server2 := registration2.Register(ctx)
 
-		By("Verifying second server reports conflict in status")
+		By("Verifying second server reports the conflicting tools in status")
 		Eventually(func(g Gomega) {
 			msg, err := GetMCPServerRegistrationStatusMessage(ctx, k8sClient, server2.Name, server2.Namespace)
 			g.Expect(err).NotTo(HaveOccurred())
 			GinkgoWriter.Println("Server2 status:", msg)
 			g.Expect(strings.Contains(msg, "conflict")).To(BeTrue(), "expected conflict message")
+			g.Expect(msg).To(ContainSubstring("headers, slow, time"))
+			conflictingTools, err := GetMCPServerRegistrationConflictingTools(ctx, k8sClient, server2.Name, server2.Namespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(conflictingTools).To(ConsistOf("headers", "slow", "time"))
 		}, TestTimeoutLong, TestRetryInterval).To(Succeed())
 
 		By("Modifying second MCPServer to add a prefix to resolve conflict")
@@ -803,6 +1050,9 @@ var _ = Describe("MCP Gateway Registration Happy Path", func() {
 		By("Waiting for second server to become ready with new prefix")
 		Eventually(func(g Gomega) {
 			g.Expect(VerifyMCPServerRegistrationReady(ctx, k8sClient, server2.Name, server2.Namespace)).To(BeNil())
+			conflictingTools, err := GetMCPServerRegistrationConflictingTools(ctx, k8sClient, server2.Name, server2.Namespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(conflictingTools).To(BeEmpty())
 		}, TestTimeoutConfigSync, TestRetryInterval).To(Succeed())
 
 		By("Verifying both servers' tools are now available")
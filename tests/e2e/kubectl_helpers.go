@@ -40,3 +40,23 @@ func IsTrustedHeadersEnabled() bool {
 	}
 	return strings.TrimSpace(string(output)) != ""
 }
+
+// ApplyManifest applies a manifest file to the cluster
+func ApplyManifest(path string) error {
+	cmd := exec.Command("kubectl", "apply", "-f", path)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to apply %s: %s: %w", path, string(output), err)
+	}
+	return nil
+}
+
+// DeleteManifest deletes the resources in a manifest file from the cluster
+func DeleteManifest(path string) error {
+	cmd := exec.Command("kubectl", "delete", "--ignore-not-found", "-f", path)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %s: %w", path, string(output), err)
+	}
+	return nil
+}
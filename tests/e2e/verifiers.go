@@ -52,8 +52,11 @@ func (v *Verifier) getHTTPRoute(name, namespace string) (*gatewayapiv1.HTTPRoute
 	return httpRoute, nil
 }
 
-// MCPServerRegistrationReady checks if the MCPServerRegistration has Ready=True condition
+// MCPServerRegistrationReady checks if the MCPServerRegistration has Accepted=True and Ready=True conditions
 func (v *Verifier) MCPServerRegistrationReady(name, namespace string) error {
+	if err := v.MCPServerRegistrationAccepted(name, namespace); err != nil {
+		return err
+	}
 	mcpServer, err := v.getMCPServerRegistration(name, namespace)
 	if err != nil {
 		return err
@@ -67,6 +70,22 @@ func (v *Verifier) MCPServerRegistrationReady(name, namespace string) error {
 	return fmt.Errorf("MCPServerRegistration %s/%s not ready", namespace, name)
 }
 
+// MCPServerRegistrationAccepted checks if the MCPServerRegistration has Accepted=True condition, meaning its config
+// was written for the broker whether or not the broker has discovered its tools
+func (v *Verifier) MCPServerRegistrationAccepted(name, namespace string) error {
+	mcpServer, err := v.getMCPServerRegistration(name, namespace)
+	if err != nil {
+		return err
+	}
+
+	for _, condition := range mcpServer.Status.Conditions {
+		if condition.Type == "Accepted" && condition.Status == metav1.ConditionTrue {
+			return nil
+		}
+	}
+	return fmt.Errorf("MCPServerRegistration %s/%s not accepted", namespace, name)
+}
+
 // MCPServerRegistrationReadyWithToolsCount checks Ready=True and expected tool count
 func (v *Verifier) MCPServerRegistrationReadyWithToolsCount(name, namespace string, expectedCount int) error {
 	mcpServer, err := v.getMCPServerRegistration(name, namespace)
@@ -134,6 +153,24 @@ func (v *Verifier) MCPServerRegistrationStatusMessage(name, namespace string) (s
 	return "", fmt.Errorf("MCPServerRegistration %s/%s has no Ready condition", namespace, name)
 }
 
+// MCPServerRegistrationConflictingTools returns the tools the broker rejected as conflicting for the MCPServerRegistration
+func (v *Verifier) MCPServerRegistrationConflictingTools(name, namespace string) ([]string, error) {
+	mcpServer, err := v.getMCPServerRegistration(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return mcpServer.Status.ConflictingTools, nil
+}
+
+// MCPServerRegistrationProtocolVersion returns the protocol version the MCP server advertised to the broker
+func (v *Verifier) MCPServerRegistrationProtocolVersion(name, namespace string) (string, error) {
+	mcpServer, err := v.getMCPServerRegistration(name, namespace)
+	if err != nil {
+		return "", err
+	}
+	return mcpServer.Status.ProtocolVersion, nil
+}
+
 // HTTPRouteHasProgrammedCondition checks if the HTTPRoute has Programmed=True condition
 func (v *Verifier) HTTPRouteHasProgrammedCondition(name, namespace string) error {
 	httpRoute, err := v.getHTTPRoute(name, namespace)
@@ -262,6 +299,10 @@ func VerifyMCPServerRegistrationReadyWithToolsCount(ctx context.Context, k8sClie
 	return NewVerifier(ctx, k8sClient).MCPServerRegistrationReadyWithToolsCount(name, namespace, toolsCount)
 }
 
+func VerifyMCPServerRegistrationAccepted(ctx context.Context, k8sClient client.Client, name, namespace string) error {
+	return NewVerifier(ctx, k8sClient).MCPServerRegistrationAccepted(name, namespace)
+}
+
 func VerifyMCPServerRegistrationNotReadyWithReason(ctx context.Context, k8sClient client.Client, name, namespace, expectedReason string) error {
 	return NewVerifier(ctx, k8sClient).MCPServerRegistrationNotReadyWithReason(name, namespace, expectedReason)
 }
@@ -274,6 +315,14 @@ func GetMCPServerRegistrationStatusMessage(ctx context.Context, k8sClient client
 	return NewVerifier(ctx, k8sClient).MCPServerRegistrationStatusMessage(name, namespace)
 }
 
+func GetMCPServerRegistrationConflictingTools(ctx context.Context, k8sClient client.Client, name, namespace string) ([]string, error) {
+	return NewVerifier(ctx, k8sClient).MCPServerRegistrationConflictingTools(name, namespace)
+}
+
+func GetMCPServerRegistrationProtocolVersion(ctx context.Context, k8sClient client.Client, name, namespace string) (string, error) {
+	return NewVerifier(ctx, k8sClient).MCPServerRegistrationProtocolVersion(name, namespace)
+}
+
 func VerifyHTTPRouteHasProgrammedCondition(ctx context.Context, k8sClient client.Client, name, namespace string) error {
 	return NewVerifier(ctx, k8sClient).HTTPRouteHasProgrammedCondition(name, namespace)
 }